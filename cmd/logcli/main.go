@@ -410,7 +410,9 @@ func main() {
 			log.Fatalf("Unable to create log output: %s", err)
 		}
 
-		if *tail || *follow {
+		if rangeQuery.Explain || rangeQuery.Analyze {
+			rangeQuery.DoExplain(queryClient, os.Stdout, *statistics)
+		} else if *tail || *follow {
 			rangeQuery.TailQuery(time.Duration(*delayFor)*time.Second, queryClient, out)
 		} else if rangeQuery.ParallelMaxWorkers == 1 {
 			rangeQuery.DoQuery(queryClient, out, *statistics)
@@ -436,7 +438,11 @@ func main() {
 			log.Fatalf("Unable to create log output: %s", err)
 		}
 
-		instantQuery.DoQuery(queryClient, out, *statistics)
+		if instantQuery.Explain || instantQuery.Analyze {
+			instantQuery.DoExplain(queryClient, os.Stdout, *statistics)
+		} else {
+			instantQuery.DoQuery(queryClient, out, *statistics)
+		}
 	case labelsCmd.FullCommand():
 		labelsQuery.DoLabels(queryClient)
 	case seriesCmd.FullCommand():
@@ -650,6 +656,8 @@ func newQuery(instant bool, cmd *kingpin.CmdClause) *query.Query {
 	cmd.Flag("remote-schema", "Execute the current query using a remote schema retrieved from the configured -schema-store.").Default("false").BoolVar(&q.FetchSchemaFromStorage)
 	cmd.Flag("schema-store", "Store used for retrieving remote schema.").Default("").StringVar(&q.SchemaStore)
 	cmd.Flag("colored-output", "Show output with colored labels").Default("false").BoolVar(&q.ColoredOutput)
+	cmd.Flag("explain", "Print the logical and physical plan of the query instead of its results. Requires the next generation query engine to be enabled.").Default("false").BoolVar(&q.Explain)
+	cmd.Flag("analyze", "Execute the query and print its plans with the runtime statistics of each node instead of its results. Implies --explain.").Default("false").BoolVar(&q.Analyze)
 
 	return q
 }
@@ -0,0 +1,170 @@
+package columnar
+
+import "unsafe"
+
+// Array is an immutable sequence of values of a single [DataType].
+type Array interface {
+	// DataType returns the type of the values of the array.
+	DataType() DataType
+	// Len returns the number of values in the array, including NULLs.
+	Len() int
+	// NullCount returns the number of NULL values in the array.
+	NullCount() int
+	// IsNull returns true if the value at index i is NULL.
+	IsNull(i int) bool
+	// Slice returns a view of the array containing the values in the range
+	// [i, j). The returned array shares memory with the original array.
+	Slice(i, j int) Array
+
+	isArray()
+}
+
+// base holds the fields common to all arrays. Arrays track an offset and a
+// length into their buffers so they can be sliced without copying.
+type base struct {
+	// validity holds one bit per value, where an unset bit denotes a NULL
+	// value. An empty validity bitmap denotes that all values are valid.
+	validity Bitmap
+	offset   int
+	length   int
+}
+
+func (b *base) Len() int { return b.length }
+
+func (b *base) IsNull(i int) bool {
+	return b.validity.Len() > 0 && !b.validity.Get(b.offset+i)
+}
+
+func (b *base) NullCount() int {
+	if b.validity.Len() == 0 {
+		return 0
+	}
+	var n int
+	for i := range b.length {
+		if !b.validity.Get(b.offset + i) {
+			n++
+		}
+	}
+	return n
+}
+
+func (b base) slice(i, j int) base {
+	if i < 0 || j > b.length || i > j {
+		panic("columnar: slice bounds out of range")
+	}
+	return base{validity: b.validity, offset: b.offset + i, length: j - i}
+}
+
+// NullArray is an [Array] of type [DataTypeNull] where all values are NULL.
+type NullArray struct{ length int }
+
+// NewNullArray returns a [NullArray] of length n.
+func NewNullArray(n int) *NullArray { return &NullArray{length: n} }
+
+func (*NullArray) isArray()           {}
+func (*NullArray) DataType() DataType { return DataTypeNull }
+func (a *NullArray) Len() int         { return a.length }
+func (a *NullArray) NullCount() int   { return a.length }
+func (*NullArray) IsNull(_ int) bool  { return true }
+func (a *NullArray) Slice(i, j int) Array {
+	if i < 0 || j > a.length || i > j {
+		panic("columnar: slice bounds out of range")
+	}
+	return &NullArray{length: j - i}
+}
+
+// BoolArray is an [Array] of type [DataTypeBool].
+type BoolArray struct {
+	base
+	values Bitmap
+}
+
+func (*BoolArray) isArray()           {}
+func (*BoolArray) DataType() DataType { return DataTypeBool }
+
+// Value returns the value at index i. The result is undefined if the value
+// is NULL.
+func (a *BoolArray) Value(i int) bool { return a.values.Get(a.offset + i) }
+
+// Slice implements [Array].
+func (a *BoolArray) Slice(i, j int) Array {
+	return &BoolArray{base: a.slice(i, j), values: a.values}
+}
+
+// primitive is the common implementation for arrays of fixed-width values.
+type primitive[T int64 | float64] struct {
+	base
+	values []T
+}
+
+// Value returns the value at index i. The result is undefined if the value
+// is NULL.
+func (a *primitive[T]) Value(i int) T { return a.values[a.offset+i] }
+
+// Values returns the underlying values of the array. Values at the index of
+// NULLs are undefined.
+func (a *primitive[T]) Values() []T { return a.values[a.offset : a.offset+a.length] }
+
+// Int64Array is an [Array] of type [DataTypeInt64].
+type Int64Array struct{ primitive[int64] }
+
+func (*Int64Array) isArray()           {}
+func (*Int64Array) DataType() DataType { return DataTypeInt64 }
+
+// Slice implements [Array].
+func (a *Int64Array) Slice(i, j int) Array {
+	return &Int64Array{primitive[int64]{base: a.slice(i, j), values: a.values}}
+}
+
+// Float64Array is an [Array] of type [DataTypeFloat64].
+type Float64Array struct{ primitive[float64] }
+
+func (*Float64Array) isArray()           {}
+func (*Float64Array) DataType() DataType { return DataTypeFloat64 }
+
+// Slice implements [Array].
+func (a *Float64Array) Slice(i, j int) Array {
+	return &Float64Array{primitive[float64]{base: a.slice(i, j), values: a.values}}
+}
+
+// TimestampArray is an [Array] of type [DataTypeTimestamp]. Values are Unix
+// timestamps in nanoseconds.
+type TimestampArray struct{ primitive[int64] }
+
+func (*TimestampArray) isArray()           {}
+func (*TimestampArray) DataType() DataType { return DataTypeTimestamp }
+
+// Slice implements [Array].
+func (a *TimestampArray) Slice(i, j int) Array {
+	return &TimestampArray{primitive[int64]{base: a.slice(i, j), values: a.values}}
+}
+
+// StringArray is an [Array] of type [DataTypeString]. All strings are stored
+// in a single contiguous data buffer.
+type StringArray struct {
+	base
+	offsets []int32 // Offsets of the values in data; len(offsets) == len(values)+1.
+	data    []byte
+}
+
+func (*StringArray) isArray()           {}
+func (*StringArray) DataType() DataType { return DataTypeString }
+
+// Value returns the value at index i. NULL values are returned as an empty
+// string. The returned string shares memory with the array.
+func (a *StringArray) Value(i int) string {
+	b := a.Bytes(i)
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// Bytes returns the value at index i as byte slice. The returned slice shares
+// memory with the array and must not be modified.
+func (a *StringArray) Bytes(i int) []byte {
+	start, end := a.offsets[a.offset+i], a.offsets[a.offset+i+1]
+	return a.data[start:end]
+}
+
+// Slice implements [Array].
+func (a *StringArray) Slice(i, j int) Array {
+	return &StringArray{base: a.slice(i, j), offsets: a.offsets, data: a.data}
+}
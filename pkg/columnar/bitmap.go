@@ -0,0 +1,79 @@
+package columnar
+
+import "math/bits"
+
+// Bitmap is a growable sequence of bits. Bitmaps are used to track the
+// validity (non-NULL-ness) of values in an [Array] and to hold the values of
+// boolean arrays.
+type Bitmap struct {
+	words []uint64
+	len   int
+}
+
+// NewBitmap returns a new Bitmap of length n with all bits set to value.
+func NewBitmap(n int, value bool) Bitmap {
+	b := Bitmap{words: make([]uint64, (n+63)/64), len: n}
+	if value {
+		for i := range b.words {
+			b.words[i] = ^uint64(0)
+		}
+		b.clearTail()
+	}
+	return b
+}
+
+// Len returns the number of bits in the bitmap.
+func (b *Bitmap) Len() int { return b.len }
+
+// Get returns the bit at index i.
+func (b *Bitmap) Get(i int) bool {
+	return b.words[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// Set sets the bit at index i to value.
+func (b *Bitmap) Set(i int, value bool) {
+	if value {
+		b.words[i/64] |= 1 << (uint(i) % 64)
+	} else {
+		b.words[i/64] &^= 1 << (uint(i) % 64)
+	}
+}
+
+// Append appends a new bit to the end of the bitmap.
+func (b *Bitmap) Append(value bool) {
+	if b.len%64 == 0 {
+		b.words = append(b.words, 0)
+	}
+	b.len++
+	b.Set(b.len-1, value)
+}
+
+// Count returns the number of set bits in the bitmap.
+func (b *Bitmap) Count() int {
+	var n int
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Reset clears the bitmap while retaining its memory.
+func (b *Bitmap) Reset() {
+	b.words = b.words[:0]
+	b.len = 0
+}
+
+// clearTail unsets the bits of the last word that are beyond the length of
+// the bitmap, so [Bitmap.Count] only counts bits within the bitmap.
+func (b *Bitmap) clearTail() {
+	if rem := b.len % 64; rem != 0 {
+		b.words[len(b.words)-1] &= (1 << uint(rem)) - 1
+	}
+}
+
+// clone returns a copy of the bitmap that does not share memory with b.
+func (b *Bitmap) clone() Bitmap {
+	words := make([]uint64, len(b.words))
+	copy(words, b.words)
+	return Bitmap{words: words, len: b.len}
+}
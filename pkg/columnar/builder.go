@@ -0,0 +1,271 @@
+package columnar
+
+import "fmt"
+
+// Builder incrementally constructs an [Array] of a single [DataType].
+type Builder interface {
+	// DataType returns the type of the array being built.
+	DataType() DataType
+	// Len returns the number of values appended so far.
+	Len() int
+	// AppendNull appends a NULL value.
+	AppendNull()
+	// AppendFrom appends the value at index i of src. src must be of the same
+	// data type as the builder. NULL values are appended as NULL.
+	AppendFrom(src Array, i int)
+	// Build returns the array of all appended values and resets the builder.
+	Build() Array
+
+	isBuilder()
+}
+
+// NewBuilder returns a new [Builder] for arrays of the given data type.
+func NewBuilder(dt DataType) Builder {
+	switch dt {
+	case DataTypeNull:
+		return &NullBuilder{}
+	case DataTypeBool:
+		return &BoolBuilder{}
+	case DataTypeInt64:
+		return &Int64Builder{}
+	case DataTypeFloat64:
+		return &Float64Builder{}
+	case DataTypeTimestamp:
+		return &TimestampBuilder{}
+	case DataTypeString:
+		return &StringBuilder{}
+	default:
+		panic(fmt.Sprintf("columnar: unsupported data type %s", dt))
+	}
+}
+
+// validityBuilder tracks the validity of appended values. The validity bitmap
+// is only allocated once the first NULL is appended.
+type validityBuilder struct {
+	validity Bitmap
+	length   int
+}
+
+func (b *validityBuilder) Len() int { return b.length }
+
+func (b *validityBuilder) appendValid() {
+	if b.validity.Len() > 0 {
+		b.validity.Append(true)
+	}
+	b.length++
+}
+
+func (b *validityBuilder) appendNull() {
+	if b.validity.Len() == 0 {
+		b.validity = NewBitmap(b.length, true)
+	}
+	b.validity.Append(false)
+	b.length++
+}
+
+func (b *validityBuilder) build() base {
+	res := base{validity: b.validity, length: b.length}
+	b.validity = Bitmap{}
+	b.length = 0
+	return res
+}
+
+func checkType(b Builder, src Array) {
+	if b.DataType() != src.DataType() {
+		panic(fmt.Sprintf("columnar: cannot append %s value to %s builder", src.DataType(), b.DataType()))
+	}
+}
+
+// NullBuilder builds a [NullArray].
+type NullBuilder struct{ length int }
+
+func (*NullBuilder) isBuilder()              {}
+func (*NullBuilder) DataType() DataType      { return DataTypeNull }
+func (b *NullBuilder) Len() int              { return b.length }
+func (b *NullBuilder) AppendNull()           { b.length++ }
+func (b *NullBuilder) AppendFrom(Array, int) { b.length++ }
+
+// Build implements [Builder].
+func (b *NullBuilder) Build() Array {
+	arr := NewNullArray(b.length)
+	b.length = 0
+	return arr
+}
+
+// BoolBuilder builds a [BoolArray].
+type BoolBuilder struct {
+	validityBuilder
+	values Bitmap
+}
+
+func (*BoolBuilder) isBuilder()         {}
+func (*BoolBuilder) DataType() DataType { return DataTypeBool }
+
+// Append appends a value.
+func (b *BoolBuilder) Append(v bool) {
+	b.appendValid()
+	b.values.Append(v)
+}
+
+// AppendNull implements [Builder].
+func (b *BoolBuilder) AppendNull() {
+	b.appendNull()
+	b.values.Append(false)
+}
+
+// AppendFrom implements [Builder].
+func (b *BoolBuilder) AppendFrom(src Array, i int) {
+	checkType(b, src)
+	if src.IsNull(i) {
+		b.AppendNull()
+		return
+	}
+	b.Append(src.(*BoolArray).Value(i))
+}
+
+// Build implements [Builder].
+func (b *BoolBuilder) Build() Array {
+	arr := &BoolArray{base: b.build(), values: b.values}
+	b.values = Bitmap{}
+	return arr
+}
+
+// primitiveBuilder is the common implementation for builders of fixed-width
+// values.
+type primitiveBuilder[T int64 | float64] struct {
+	validityBuilder
+	values []T
+}
+
+// Append appends a value.
+func (b *primitiveBuilder[T]) Append(v T) {
+	b.appendValid()
+	b.values = append(b.values, v)
+}
+
+// AppendNull implements [Builder].
+func (b *primitiveBuilder[T]) AppendNull() {
+	b.appendNull()
+	var zero T
+	b.values = append(b.values, zero)
+}
+
+func (b *primitiveBuilder[T]) buildPrimitive() primitive[T] {
+	res := primitive[T]{base: b.build(), values: b.values}
+	b.values = nil
+	return res
+}
+
+// Int64Builder builds an [Int64Array].
+type Int64Builder struct{ primitiveBuilder[int64] }
+
+func (*Int64Builder) isBuilder()         {}
+func (*Int64Builder) DataType() DataType { return DataTypeInt64 }
+
+// AppendFrom implements [Builder].
+func (b *Int64Builder) AppendFrom(src Array, i int) {
+	checkType(b, src)
+	if src.IsNull(i) {
+		b.AppendNull()
+		return
+	}
+	b.Append(src.(*Int64Array).Value(i))
+}
+
+// Build implements [Builder].
+func (b *Int64Builder) Build() Array { return &Int64Array{b.buildPrimitive()} }
+
+// Float64Builder builds a [Float64Array].
+type Float64Builder struct{ primitiveBuilder[float64] }
+
+func (*Float64Builder) isBuilder()         {}
+func (*Float64Builder) DataType() DataType { return DataTypeFloat64 }
+
+// AppendFrom implements [Builder].
+func (b *Float64Builder) AppendFrom(src Array, i int) {
+	checkType(b, src)
+	if src.IsNull(i) {
+		b.AppendNull()
+		return
+	}
+	b.Append(src.(*Float64Array).Value(i))
+}
+
+// Build implements [Builder].
+func (b *Float64Builder) Build() Array { return &Float64Array{b.buildPrimitive()} }
+
+// TimestampBuilder builds a [TimestampArray].
+type TimestampBuilder struct{ primitiveBuilder[int64] }
+
+func (*TimestampBuilder) isBuilder()         {}
+func (*TimestampBuilder) DataType() DataType { return DataTypeTimestamp }
+
+// AppendFrom implements [Builder].
+func (b *TimestampBuilder) AppendFrom(src Array, i int) {
+	checkType(b, src)
+	if src.IsNull(i) {
+		b.AppendNull()
+		return
+	}
+	b.Append(src.(*TimestampArray).Value(i))
+}
+
+// Build implements [Builder].
+func (b *TimestampBuilder) Build() Array { return &TimestampArray{b.buildPrimitive()} }
+
+// StringBuilder builds a [StringArray].
+type StringBuilder struct {
+	validityBuilder
+	offsets []int32
+	data    []byte
+}
+
+func (*StringBuilder) isBuilder()         {}
+func (*StringBuilder) DataType() DataType { return DataTypeString }
+
+// Append appends a value.
+func (b *StringBuilder) Append(v string) {
+	b.appendValid()
+	b.data = append(b.data, v...)
+	b.appendOffset()
+}
+
+// AppendBytes appends a value from a byte slice. The bytes are copied.
+func (b *StringBuilder) AppendBytes(v []byte) {
+	b.appendValid()
+	b.data = append(b.data, v...)
+	b.appendOffset()
+}
+
+// AppendNull implements [Builder].
+func (b *StringBuilder) AppendNull() {
+	b.appendNull()
+	b.appendOffset()
+}
+
+// AppendFrom implements [Builder].
+func (b *StringBuilder) AppendFrom(src Array, i int) {
+	checkType(b, src)
+	if src.IsNull(i) {
+		b.AppendNull()
+		return
+	}
+	b.AppendBytes(src.(*StringArray).Bytes(i))
+}
+
+func (b *StringBuilder) appendOffset() {
+	if len(b.offsets) == 0 {
+		b.offsets = append(b.offsets, 0)
+	}
+	b.offsets = append(b.offsets, int32(len(b.data)))
+}
+
+// Build implements [Builder].
+func (b *StringBuilder) Build() Array {
+	if len(b.offsets) == 0 {
+		b.offsets = append(b.offsets, 0)
+	}
+	arr := &StringArray{base: b.build(), offsets: b.offsets, data: b.data}
+	b.offsets, b.data = nil, nil
+	return arr
+}
@@ -0,0 +1,136 @@
+package columnar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringBuilder(t *testing.T) {
+	var b StringBuilder
+	b.Append("foo")
+	b.AppendNull()
+	b.AppendBytes([]byte("bar"))
+	b.Append("")
+
+	arr := b.Build().(*StringArray)
+	require.Equal(t, 4, arr.Len())
+	require.Equal(t, 1, arr.NullCount())
+	require.Equal(t, "foo", arr.Value(0))
+	require.True(t, arr.IsNull(1))
+	require.Equal(t, "", arr.Value(1))
+	require.Equal(t, "bar", arr.Value(2))
+	require.False(t, arr.IsNull(3))
+	require.Equal(t, "", arr.Value(3))
+
+	sliced := arr.Slice(1, 3).(*StringArray)
+	require.Equal(t, 2, sliced.Len())
+	require.True(t, sliced.IsNull(0))
+	require.Equal(t, "bar", sliced.Value(1))
+
+	require.Equal(t, 0, b.Len(), "builder must be reset after Build")
+	require.Equal(t, 0, b.Build().Len())
+}
+
+func TestBuilder_AppendFrom(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		build func() Array
+	}{
+		{
+			name: "bool",
+			build: func() Array {
+				var b BoolBuilder
+				b.Append(true)
+				b.AppendNull()
+				b.Append(false)
+				return b.Build()
+			},
+		},
+		{
+			name: "int64",
+			build: func() Array {
+				var b Int64Builder
+				b.Append(1)
+				b.AppendNull()
+				b.Append(-3)
+				return b.Build()
+			},
+		},
+		{
+			name: "float64",
+			build: func() Array {
+				var b Float64Builder
+				b.Append(1.5)
+				b.AppendNull()
+				b.Append(2.5)
+				return b.Build()
+			},
+		},
+		{
+			name: "timestamp",
+			build: func() Array {
+				var b TimestampBuilder
+				b.Append(100)
+				b.AppendNull()
+				b.Append(200)
+				return b.Build()
+			},
+		},
+		{
+			name: "string",
+			build: func() Array {
+				var b StringBuilder
+				b.Append("a")
+				b.AppendNull()
+				b.Append("c")
+				return b.Build()
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			src := tt.build()
+
+			// Copy the values in reverse order.
+			b := NewBuilder(src.DataType())
+			for i := src.Len() - 1; i >= 0; i-- {
+				b.AppendFrom(src, i)
+			}
+			dst := b.Build()
+
+			require.Equal(t, src.Len(), dst.Len())
+			require.Equal(t, src.NullCount(), dst.NullCount())
+			for i := range src.Len() {
+				j := src.Len() - 1 - i
+				require.Equal(t, src.IsNull(i), dst.IsNull(j))
+				if !src.IsNull(i) {
+					require.Equal(t, valueAt(src, i), valueAt(dst, j))
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_AppendFromMismatchedType(t *testing.T) {
+	var src Int64Builder
+	src.Append(1)
+	arr := src.Build()
+
+	require.Panics(t, func() { NewBuilder(DataTypeString).AppendFrom(arr, 0) })
+}
+
+func valueAt(arr Array, i int) any {
+	switch arr := arr.(type) {
+	case *BoolArray:
+		return arr.Value(i)
+	case *Int64Array:
+		return arr.Value(i)
+	case *Float64Array:
+		return arr.Value(i)
+	case *TimestampArray:
+		return arr.Value(i)
+	case *StringArray:
+		return arr.Value(i)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package columnar provides an in-memory columnar data format inspired by
+// Apache Arrow.
+//
+// Data is exchanged as a [RecordBatch], which consists of a [Schema] and one
+// [Array] per field of the schema. Arrays store their values in contiguous
+// typed buffers and track NULL values in a separate validity [Bitmap], which
+// allows operating on many values at once without materializing individual
+// rows.
+//
+// Arrays are immutable once built. Use a [Builder] to construct new arrays.
+//
+// Record batches are the data interchange of the operators of the query
+// engine in pkg/engine, and are read directly from data objects by
+// pkg/dataobj. The package isn't part of pkg/engine/internal, as the readers
+// of pkg/dataobj couldn't import it from there.
+package columnar
+
+import "fmt"
+
+// DataType is the logical type of the values of an [Array].
+type DataType uint8
+
+// Supported data types.
+const (
+	DataTypeNull      DataType = iota // DataTypeNull is an array where all values are NULL.
+	DataTypeBool                      // DataTypeBool holds boolean values.
+	DataTypeInt64                     // DataTypeInt64 holds signed 64-bit integers.
+	DataTypeFloat64                   // DataTypeFloat64 holds 64-bit floating point numbers.
+	DataTypeTimestamp                 // DataTypeTimestamp holds nanosecond precision Unix timestamps.
+	DataTypeString                    // DataTypeString holds variable length strings.
+)
+
+// String returns the string representation of the data type.
+func (dt DataType) String() string {
+	switch dt {
+	case DataTypeNull:
+		return "null"
+	case DataTypeBool:
+		return "bool"
+	case DataTypeInt64:
+		return "int64"
+	case DataTypeFloat64:
+		return "float64"
+	case DataTypeTimestamp:
+		return "timestamp"
+	case DataTypeString:
+		return "string"
+	default:
+		return fmt.Sprintf("DataType(%d)", dt)
+	}
+}
@@ -0,0 +1,269 @@
+package columnar
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Encoder writes record batches to an output stream. The encoding is
+// intended for exchanging batches between processes and is not a stable
+// storage format.
+//
+// Each batch is encoded as its schema followed by its columns. Every column
+// is encoded as one byte per value that denotes whether the value is NULL,
+// followed by the non-NULL values.
+//
+// Batches are preceded by a marker byte, and the stream is terminated by an
+// end marker written by [Encoder.Close], so that a stream that is cut short
+// between two batches is detected by the [Decoder].
+type Encoder struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+// Markers that precede the batches of a stream and terminate it.
+const (
+	markerEnd   byte = 0
+	markerBatch byte = 1
+)
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes the batch to the stream of the encoder.
+func (e *Encoder) Encode(batch *RecordBatch) error {
+	e.buf = append(e.buf[:0], markerBatch)
+	e.buf = binary.AppendUvarint(e.buf, uint64(batch.NumRows()))
+	e.buf = binary.AppendUvarint(e.buf, uint64(batch.NumCols()))
+	for _, field := range batch.schema.fields {
+		e.buf = appendString(e.buf, field.Name)
+		e.buf = append(e.buf, byte(field.Type))
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(field.Metadata)))
+		for k, v := range field.Metadata {
+			e.buf = appendString(e.buf, k)
+			e.buf = appendString(e.buf, v)
+		}
+	}
+
+	for _, col := range batch.columns {
+		if col.DataType() == DataTypeNull {
+			continue
+		}
+		for i := range col.Len() {
+			if col.IsNull(i) {
+				e.buf = append(e.buf, 0)
+				continue
+			}
+			e.buf = append(e.buf, 1)
+
+			switch col := col.(type) {
+			case *BoolArray:
+				var v byte
+				if col.Value(i) {
+					v = 1
+				}
+				e.buf = append(e.buf, v)
+			case *Int64Array:
+				e.buf = binary.AppendVarint(e.buf, col.Value(i))
+			case *TimestampArray:
+				e.buf = binary.AppendVarint(e.buf, col.Value(i))
+			case *Float64Array:
+				e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(col.Value(i)))
+			case *StringArray:
+				e.buf = binary.AppendUvarint(e.buf, uint64(len(col.Bytes(i))))
+				e.buf = append(e.buf, col.Bytes(i)...)
+			default:
+				return fmt.Errorf("columnar: cannot encode array of type %s", col.DataType())
+			}
+		}
+	}
+
+	if _, err := e.w.Write(e.buf); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// Close terminates the stream of the encoder. It does not close the
+// underlying writer. No batches must be encoded after Close.
+func (e *Encoder) Close() error {
+	if err := e.w.WriteByte(markerEnd); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// Decoder reads record batches written by an [Encoder] from an input
+// stream.
+type Decoder struct {
+	r    *bufio.Reader
+	done bool
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next batch from the stream of the decoder. It returns
+// [io.EOF] once the end of the stream written by [Encoder.Close] is reached,
+// and [io.ErrUnexpectedEOF] if the input ends before that.
+func (d *Decoder) Decode() (*RecordBatch, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+	marker, err := d.r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	switch marker {
+	case markerEnd:
+		d.done = true
+		return nil, io.EOF
+	case markerBatch:
+	default:
+		return nil, fmt.Errorf("columnar: invalid marker %d", marker)
+	}
+
+	rows, err := d.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	numFields, err := d.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]Field, numFields)
+	for i := range fields {
+		if fields[i].Name, err = d.readString(); err != nil {
+			return nil, err
+		}
+		ty, err := d.r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		fields[i].Type = DataType(ty)
+
+		numMetadata, err := d.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if numMetadata > 0 {
+			fields[i].Metadata = make(map[string]string, numMetadata)
+		}
+		for range numMetadata {
+			k, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			fields[i].Metadata[k] = v
+		}
+	}
+
+	columns := make([]Array, len(fields))
+	for c, field := range fields {
+		if columns[c], err = d.readArray(field.Type, int(rows)); err != nil {
+			return nil, err
+		}
+	}
+	return NewRecordBatch(NewSchema(fields...), int(rows), columns)
+}
+
+func (d *Decoder) readArray(dt DataType, rows int) (Array, error) {
+	if dt == DataTypeNull {
+		return NewNullArray(rows), nil
+	}
+	switch dt {
+	case DataTypeBool, DataTypeInt64, DataTypeTimestamp, DataTypeFloat64, DataTypeString:
+	default:
+		return nil, fmt.Errorf("columnar: cannot decode array of type %s", dt)
+	}
+
+	builder := NewBuilder(dt)
+	for range rows {
+		valid, err := d.r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if valid == 0 {
+			builder.AppendNull()
+			continue
+		}
+
+		switch b := builder.(type) {
+		case *BoolBuilder:
+			v, err := d.r.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			b.Append(v == 1)
+		case *Int64Builder:
+			v, err := binary.ReadVarint(d.r)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			b.Append(v)
+		case *TimestampBuilder:
+			v, err := binary.ReadVarint(d.r)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			b.Append(v)
+		case *Float64Builder:
+			var v [8]byte
+			if _, err := io.ReadFull(d.r, v[:]); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			b.Append(math.Float64frombits(binary.LittleEndian.Uint64(v[:])))
+		case *StringBuilder:
+			v, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			b.Append(v)
+		}
+	}
+	return builder.Build(), nil
+}
+
+func (d *Decoder) readUvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(d.r)
+	return v, unexpectedEOF(err)
+}
+
+func (d *Decoder) readString() (string, error) {
+	n, err := d.readUvarint()
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return "", unexpectedEOF(err)
+	}
+	return string(buf), nil
+}
+
+// unexpectedEOF converts [io.EOF] into [io.ErrUnexpectedEOF], as the stream
+// must not end within a batch.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package columnar
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	label := map[string]string{"type": "label"}
+
+	a := buildRecord(t,
+		[]Field{
+			{Name: "id", Type: DataTypeInt64},
+			{Name: "app", Type: DataTypeString, Metadata: label},
+			{Name: "ok", Type: DataTypeBool},
+		},
+		[]any{int64(-1), "foo", true},
+		[]any{int64(2), nil, nil},
+		[]any{nil, "", false},
+	)
+
+	var (
+		ts     TimestampBuilder
+		values Float64Builder
+	)
+	ts.Append(1742826126000000000)
+	ts.AppendNull()
+	values.Append(math.Inf(-1))
+	values.Append(0.5)
+	b, err := NewRecordBatch(
+		NewSchema(
+			Field{Name: "ts", Type: DataTypeTimestamp},
+			Field{Name: "value", Type: DataTypeFloat64},
+			Field{Name: "empty", Type: DataTypeNull},
+		),
+		2,
+		[]Array{ts.Build(), values.Build(), NewNullArray(2)},
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	require.NoError(t, enc.Encode(a))
+	require.NoError(t, enc.Encode(b))
+	require.NoError(t, enc.Encode(a.Slice(1, 3)))
+	require.NoError(t, enc.Close())
+
+	dec := NewDecoder(&buf)
+	for _, expected := range []*RecordBatch{a, b, a.Slice(1, 3)} {
+		actual, err := dec.Decode()
+		require.NoError(t, err)
+		require.Equal(t, expected.NumCols(), actual.NumCols())
+		for c := range expected.NumCols() {
+			require.True(t, expected.Schema().Field(c).Equal(actual.Schema().Field(c)))
+		}
+		require.Equal(t, rowsOf(expected), rowsOf(actual))
+	}
+
+	_, err = dec.Decode()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestDecoder_Truncated(t *testing.T) {
+	rec := buildRecord(t, []Field{{Name: "app", Type: DataTypeString}}, []any{"foo"})
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	require.NoError(t, enc.Encode(rec))
+
+	_, err := NewDecoder(bytes.NewReader(buf.Bytes()[:buf.Len()-1])).Decode()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// A stream without the end marker is truncated as well.
+	dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+	_, err = dec.Decode()
+	require.NoError(t, err)
+	_, err = dec.Decode()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,171 @@
+package columnar
+
+import "fmt"
+
+// RecordBatch is a set of equally long arrays described by a [Schema]. The
+// array at index i holds the values of the field at index i of the schema.
+type RecordBatch struct {
+	schema  *Schema
+	columns []Array
+	rows    int
+}
+
+// NewRecordBatch returns a new RecordBatch with the given schema, number of
+// rows, and columns. It returns an error if the columns do not match the
+// schema or if any column does not have exactly rows values.
+func NewRecordBatch(schema *Schema, rows int, columns []Array) (*RecordBatch, error) {
+	if schema.NumFields() != len(columns) {
+		return nil, fmt.Errorf("schema has %d fields, but got %d columns", schema.NumFields(), len(columns))
+	}
+	for i, col := range columns {
+		field := schema.Field(i)
+		if col.DataType() != field.Type {
+			return nil, fmt.Errorf("column %d (%s) has type %s, expected %s", i, field.Name, col.DataType(), field.Type)
+		}
+		if col.Len() != rows {
+			return nil, fmt.Errorf("column %d (%s) has %d rows, expected %d", i, field.Name, col.Len(), rows)
+		}
+	}
+	return &RecordBatch{schema: schema, columns: columns, rows: rows}, nil
+}
+
+// Schema returns the schema of the batch.
+func (r *RecordBatch) Schema() *Schema { return r.schema }
+
+// NumRows returns the number of rows in the batch.
+func (r *RecordBatch) NumRows() int { return r.rows }
+
+// NumCols returns the number of columns in the batch.
+func (r *RecordBatch) NumCols() int { return len(r.columns) }
+
+// Column returns the column at index i.
+func (r *RecordBatch) Column(i int) Array { return r.columns[i] }
+
+// Slice returns a view of the batch containing the rows in the range [i, j).
+// The returned batch shares memory with the original batch.
+func (r *RecordBatch) Slice(i, j int) *RecordBatch {
+	columns := make([]Array, len(r.columns))
+	for c, col := range r.columns {
+		columns[c] = col.Slice(i, j)
+	}
+	return &RecordBatch{schema: r.schema, columns: columns, rows: j - i}
+}
+
+// Take returns a new batch that consists of the rows at the given indices of
+// r, in the order of the indices.
+func Take(r *RecordBatch, indices []int) *RecordBatch {
+	columns := make([]Array, len(r.columns))
+	for c, col := range r.columns {
+		builder := NewBuilder(col.DataType())
+		for _, i := range indices {
+			builder.AppendFrom(col, i)
+		}
+		columns[c] = builder.Build()
+	}
+	return &RecordBatch{schema: r.schema, columns: columns, rows: len(indices)}
+}
+
+// Filter returns a new batch that only consists of the rows of r for which
+// the corresponding value of mask is true. NULL values of mask are treated
+// as false.
+func Filter(r *RecordBatch, mask *BoolArray) (*RecordBatch, error) {
+	if mask.Len() != r.NumRows() {
+		return nil, fmt.Errorf("mask has %d values, but batch has %d rows", mask.Len(), r.NumRows())
+	}
+
+	indices := make([]int, 0, mask.Len())
+	for i := range mask.Len() {
+		if !mask.IsNull(i) && mask.Value(i) {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == r.NumRows() {
+		return r, nil
+	}
+	return Take(r, indices), nil
+}
+
+// RecordBuilder builds a [RecordBatch] row by row from rows of other record
+// batches. The schema of the resulting batch is the union of the schemas of
+// all source batches: fields that are missing in a source batch are filled
+// with NULLs. Fields are identified by their name and metadata.
+type RecordBuilder struct {
+	fields   []Field
+	builders []Builder
+	index    map[string]int
+	rows     int
+
+	// mapping caches the column mapping of the last source schema.
+	lastSchema *Schema
+	mapping    []int
+}
+
+// NewRecordBuilder returns a new, empty RecordBuilder.
+func NewRecordBuilder() *RecordBuilder {
+	return &RecordBuilder{index: make(map[string]int)}
+}
+
+// NumRows returns the number of rows appended so far.
+func (b *RecordBuilder) NumRows() int { return b.rows }
+
+// AppendRow appends row i of src.
+func (b *RecordBuilder) AppendRow(src *RecordBatch, i int) error {
+	if src.schema != b.lastSchema {
+		if err := b.updateMapping(src.schema); err != nil {
+			return err
+		}
+	}
+
+	appended := make([]bool, len(b.builders))
+	for c, col := range src.columns {
+		idx := b.mapping[c]
+		b.builders[idx].AppendFrom(col, i)
+		appended[idx] = true
+	}
+	for idx, ok := range appended {
+		if !ok {
+			b.builders[idx].AppendNull()
+		}
+	}
+	b.rows++
+	return nil
+}
+
+func (b *RecordBuilder) updateMapping(schema *Schema) error {
+	b.mapping = b.mapping[:0]
+	for _, field := range schema.fields {
+		key := field.key()
+		idx, ok := b.index[key]
+		if !ok {
+			idx = len(b.fields)
+			b.index[key] = idx
+			b.fields = append(b.fields, field)
+
+			builder := NewBuilder(field.Type)
+			for range b.rows {
+				builder.AppendNull()
+			}
+			b.builders = append(b.builders, builder)
+		} else if b.fields[idx].Type != field.Type {
+			return fmt.Errorf("field %s has conflicting types %s and %s", field.Name, b.fields[idx].Type, field.Type)
+		}
+		b.mapping = append(b.mapping, idx)
+	}
+	b.lastSchema = schema
+	return nil
+}
+
+// Build returns the batch of all appended rows and resets the builder.
+func (b *RecordBuilder) Build() *RecordBatch {
+	columns := make([]Array, len(b.builders))
+	for i, builder := range b.builders {
+		columns[i] = builder.Build()
+	}
+	batch := &RecordBatch{schema: NewSchema(b.fields...), columns: columns, rows: b.rows}
+
+	b.fields, b.builders = nil, nil
+	b.index = make(map[string]int)
+	b.rows = 0
+	b.lastSchema, b.mapping = nil, nil
+	return batch
+}
@@ -0,0 +1,170 @@
+package columnar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func buildRecord(t *testing.T, fields []Field, rows ...[]any) *RecordBatch {
+	t.Helper()
+
+	builders := make([]Builder, len(fields))
+	for i, f := range fields {
+		builders[i] = NewBuilder(f.Type)
+	}
+	for _, row := range rows {
+		for i, v := range row {
+			switch v := v.(type) {
+			case nil:
+				builders[i].AppendNull()
+			case int64:
+				builders[i].(*Int64Builder).Append(v)
+			case string:
+				builders[i].(*StringBuilder).Append(v)
+			case bool:
+				builders[i].(*BoolBuilder).Append(v)
+			default:
+				t.Fatalf("unsupported value type %T", v)
+			}
+		}
+	}
+
+	columns := make([]Array, len(builders))
+	for i, b := range builders {
+		columns[i] = b.Build()
+	}
+	rec, err := NewRecordBatch(NewSchema(fields...), len(rows), columns)
+	require.NoError(t, err)
+	return rec
+}
+
+// rowsOf returns the values of all rows of the batch, keyed by field name.
+func rowsOf(rec *RecordBatch) []map[string]any {
+	res := make([]map[string]any, rec.NumRows())
+	for i := range rec.NumRows() {
+		res[i] = make(map[string]any, rec.NumCols())
+		for c := range rec.NumCols() {
+			col := rec.Column(c)
+			if col.IsNull(i) {
+				res[i][rec.Schema().Field(c).Name] = nil
+				continue
+			}
+			res[i][rec.Schema().Field(c).Name] = valueAt(col, i)
+		}
+	}
+	return res
+}
+
+func TestNewRecordBatch_Validation(t *testing.T) {
+	var b Int64Builder
+	b.Append(1)
+	col := b.Build()
+
+	_, err := NewRecordBatch(NewSchema(Field{Name: "a", Type: DataTypeInt64}), 2, []Array{col})
+	require.Error(t, err, "row count mismatch")
+
+	_, err = NewRecordBatch(NewSchema(Field{Name: "a", Type: DataTypeString}), 1, []Array{col})
+	require.Error(t, err, "type mismatch")
+
+	_, err = NewRecordBatch(NewSchema(), 1, []Array{col})
+	require.Error(t, err, "column count mismatch")
+}
+
+func TestRecordBatch_SliceAndTake(t *testing.T) {
+	fields := []Field{{Name: "id", Type: DataTypeInt64}, {Name: "name", Type: DataTypeString}}
+	rec := buildRecord(t, fields,
+		[]any{int64(1), "a"},
+		[]any{int64(2), nil},
+		[]any{int64(3), "c"},
+	)
+
+	require.Equal(t, []map[string]any{
+		{"id": int64(2), "name": nil},
+		{"id": int64(3), "name": "c"},
+	}, rowsOf(rec.Slice(1, 3)))
+
+	require.Equal(t, []map[string]any{
+		{"id": int64(3), "name": "c"},
+		{"id": int64(1), "name": "a"},
+	}, rowsOf(Take(rec, []int{2, 0})))
+}
+
+func TestFilter(t *testing.T) {
+	fields := []Field{{Name: "id", Type: DataTypeInt64}}
+	rec := buildRecord(t, fields, []any{int64(1)}, []any{int64(2)}, []any{int64(3)})
+
+	var mb BoolBuilder
+	mb.Append(true)
+	mb.AppendNull()
+	mb.Append(false)
+
+	res, err := Filter(rec, mb.Build().(*BoolArray))
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"id": int64(1)}}, rowsOf(res))
+
+	_, err = Filter(rec, allTrue(t, 2))
+	require.Error(t, err)
+}
+
+func allTrue(t *testing.T, n int) *BoolArray {
+	t.Helper()
+	var b BoolBuilder
+	for range n {
+		b.Append(true)
+	}
+	return b.Build().(*BoolArray)
+}
+
+func TestRecordBuilder_SchemaUnion(t *testing.T) {
+	label := map[string]string{"type": "label"}
+	metadata := map[string]string{"type": "metadata"}
+
+	a := buildRecord(t,
+		[]Field{{Name: "ts", Type: DataTypeInt64}, {Name: "app", Type: DataTypeString, Metadata: label}},
+		[]any{int64(1), "foo"},
+		[]any{int64(2), "bar"},
+	)
+	b := buildRecord(t,
+		[]Field{{Name: "app", Type: DataTypeString, Metadata: metadata}, {Name: "ts", Type: DataTypeInt64}},
+		[]any{"baz", int64(3)},
+	)
+
+	builder := NewRecordBuilder()
+	require.NoError(t, builder.AppendRow(a, 1))
+	require.NoError(t, builder.AppendRow(b, 0))
+	require.NoError(t, builder.AppendRow(a, 0))
+	require.Equal(t, 3, builder.NumRows())
+
+	res := builder.Build()
+	require.Equal(t, 3, res.NumCols())
+	require.True(t, res.Schema().Field(1).Equal(Field{Name: "app", Type: DataTypeString, Metadata: label}))
+	require.True(t, res.Schema().Field(2).Equal(Field{Name: "app", Type: DataTypeString, Metadata: metadata}))
+
+	var got [][]any
+	for i := range res.NumRows() {
+		row := make([]any, res.NumCols())
+		for c := range res.NumCols() {
+			if !res.Column(c).IsNull(i) {
+				row[c] = valueAt(res.Column(c), i)
+			}
+		}
+		got = append(got, row)
+	}
+	require.Equal(t, [][]any{
+		{int64(2), "bar", nil},
+		{int64(3), nil, "baz"},
+		{int64(1), "foo", nil},
+	}, got)
+
+	require.Equal(t, 0, builder.NumRows(), "builder must be reset after Build")
+}
+
+func TestRecordBuilder_ConflictingTypes(t *testing.T) {
+	a := buildRecord(t, []Field{{Name: "x", Type: DataTypeInt64}}, []any{int64(1)})
+	b := buildRecord(t, []Field{{Name: "x", Type: DataTypeString}}, []any{"1"})
+
+	builder := NewRecordBuilder()
+	require.NoError(t, builder.AppendRow(a, 0))
+	require.Error(t, builder.AppendRow(b, 0))
+}
@@ -0,0 +1,62 @@
+package columnar
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Field describes a single column of a [Schema].
+type Field struct {
+	// Name of the field. Names are not required to be unique within a schema.
+	Name string
+	// Type is the data type of the values of the field.
+	Type DataType
+	// Metadata holds arbitrary key-value pairs that describe the field.
+	Metadata map[string]string
+}
+
+// Equal returns true if f and other have the same name, data type, and
+// metadata.
+func (f Field) Equal(other Field) bool {
+	return f.Name == other.Name && f.Type == other.Type && maps.Equal(f.Metadata, other.Metadata)
+}
+
+// key returns a string that uniquely identifies the name and metadata of the
+// field.
+func (f Field) key() string {
+	var sb strings.Builder
+	sb.WriteString(f.Name)
+	for _, k := range slices.Sorted(maps.Keys(f.Metadata)) {
+		sb.WriteByte(0)
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(f.Metadata[k])
+	}
+	return sb.String()
+}
+
+// Schema is the ordered list of fields of a [RecordBatch].
+type Schema struct {
+	fields []Field
+}
+
+// NewSchema returns a new Schema with the given fields.
+func NewSchema(fields ...Field) *Schema {
+	return &Schema{fields: slices.Clone(fields)}
+}
+
+// NumFields returns the number of fields in the schema.
+func (s *Schema) NumFields() int { return len(s.fields) }
+
+// Field returns the field at index i.
+func (s *Schema) Field(i int) Field { return s.fields[i] }
+
+// Fields returns a copy of the fields of the schema.
+func (s *Schema) Fields() []Field { return slices.Clone(s.fields) }
+
+// FieldIndex returns the index of the first field for which match returns
+// true, or -1 if no field matches.
+func (s *Schema) FieldIndex(match func(Field) bool) int {
+	return slices.IndexFunc(s.fields, match)
+}
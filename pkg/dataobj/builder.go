@@ -14,6 +14,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/logs"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/streams"
 	"github.com/grafana/loki/v3/pkg/logproto"
@@ -120,6 +121,8 @@ type Builder struct {
 	streams *streams.Streams
 	logs    *logs.Logs
 
+	kafkaOffsets *KafkaOffsets
+
 	state builderState
 }
 
@@ -136,6 +139,23 @@ const (
 type FlushStats struct {
 	MinTimestamp time.Time
 	MaxTimestamp time.Time
+
+	// LabelValues holds the distinct values of each stream label of the
+	// flushed object, by label name.
+	LabelValues map[string][]string
+
+	// KafkaOffsets holds the Kafka records the flushed object was built from,
+	// as set by [Builder.SetKafkaOffsets]. KafkaOffsets is nil if unset.
+	KafkaOffsets *KafkaOffsets
+}
+
+// KafkaOffsets describes the range of records of a Kafka partition a data
+// object was built from. Both offsets are inclusive.
+type KafkaOffsets struct {
+	Topic     string
+	Partition int32
+	MinOffset int64
+	MaxOffset int64
 }
 
 // NewBuilder creates a new Builder which stores data objects for the specified
@@ -217,6 +237,13 @@ func (b *Builder) Append(stream logproto.Stream) error {
 	return nil
 }
 
+// SetKafkaOffsets records the Kafka records the buffered data was built from
+// into the next flushed data object. The offsets are discarded on
+// [Builder.Reset].
+func (b *Builder) SetKafkaOffsets(offsets KafkaOffsets) {
+	b.kafkaOffsets = &offsets
+}
+
 func (b *Builder) parseLabels(labelString string) (labels.Labels, error) {
 	labels, ok := b.labelCache.Get(labelString)
 	if ok {
@@ -287,11 +314,15 @@ func (b *Builder) Flush(output *bytes.Buffer) (FlushStats, error) {
 	}
 
 	minTime, maxTime := b.streams.TimeRange()
+	labelValues := b.streams.LabelValues()
+	kafkaOffsets := b.kafkaOffsets
 
 	b.Reset()
 	return FlushStats{
 		MinTimestamp: minTime,
 		MaxTimestamp: maxTime,
+		LabelValues:  labelValues,
+		KafkaOffsets: kafkaOffsets,
 	}, nil
 }
 
@@ -305,6 +336,15 @@ func (b *Builder) buildObject(output *bytes.Buffer) error {
 	enc.Reset(output)
 	defer encoderPool.Put(enc)
 
+	if b.kafkaOffsets != nil {
+		enc.SetKafkaOffsets(&filemd.KafkaOffsets{
+			Topic:     b.kafkaOffsets.Topic,
+			Partition: b.kafkaOffsets.Partition,
+			MinOffset: b.kafkaOffsets.MinOffset,
+			MaxOffset: b.kafkaOffsets.MaxOffset,
+		})
+	}
+
 	if err := b.streams.EncodeTo(enc); err != nil {
 		return fmt.Errorf("encoding streams: %w", err)
 	} else if err := b.logs.EncodeTo(enc); err != nil {
@@ -326,6 +366,7 @@ func (b *Builder) buildObject(output *bytes.Buffer) error {
 func (b *Builder) Reset() {
 	b.logs.Reset()
 	b.streams.Reset()
+	b.kafkaOffsets = nil
 
 	b.metrics.sizeEstimate.Set(0)
 	b.currentSizeEstimate = 0
@@ -140,3 +140,42 @@ func TestBuilder_Append(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestBuilder_KafkaOffsets(t *testing.T) {
+	ctx := context.Background()
+
+	builder, err := NewBuilder(testBuilderConfig)
+	require.NoError(t, err)
+
+	stream := logproto.Stream{
+		Labels:  `{cluster="test",app="foo"}`,
+		Entries: []push.Entry{{Timestamp: time.Unix(10, 0).UTC(), Line: "hello"}},
+	}
+	offsets := KafkaOffsets{Topic: "loki", Partition: 2, MinOffset: 5, MaxOffset: 9}
+
+	require.NoError(t, builder.Append(stream))
+	builder.SetKafkaOffsets(offsets)
+
+	var buf bytes.Buffer
+	stats, err := builder.Flush(&buf)
+	require.NoError(t, err)
+	require.Equal(t, &offsets, stats.KafkaOffsets)
+
+	obj := FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	actual, err := obj.KafkaOffsets(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &offsets, actual)
+
+	// Offsets are discarded after flushing.
+	require.NoError(t, builder.Append(stream))
+
+	buf.Reset()
+	stats, err = builder.Flush(&buf)
+	require.NoError(t, err)
+	require.Nil(t, stats.KafkaOffsets)
+
+	obj = FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	actual, err = obj.KafkaOffsets(ctx)
+	require.NoError(t, err)
+	require.Nil(t, actual)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/thanos-io/objstore"
+
+	chunkcache "github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+)
+
+// NewBucket returns a bucket which caches ranges read with GetRange and the
+// attributes of objects in c. Other operations are passed through to bucket.
+//
+// Cached entries are never invalidated, so the returned bucket must only be
+// used for reading immutable objects, such as data objects. Metastore
+// objects are updated in place and must not be read through it.
+func NewBucket(bucket objstore.Bucket, c chunkcache.Cache, logger log.Logger) objstore.Bucket {
+	return &cachingBucket{Bucket: bucket, cache: c, logger: logger}
+}
+
+type cachingBucket struct {
+	objstore.Bucket
+
+	cache  chunkcache.Cache
+	logger log.Logger
+}
+
+func (b *cachingBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	if length < 0 {
+		// Reads until the end of the object can't be keyed by their range.
+		return b.Bucket.GetRange(ctx, name, off, length)
+	}
+
+	key := fmt.Sprintf("%s:%d:%d", name, off, length)
+	if buf, ok := b.fetch(ctx, key); ok {
+		return io.NopCloser(bytes.NewReader(buf)), nil
+	}
+
+	rc, err := b.Bucket.GetRange(ctx, name, off, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	buf, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	b.store(ctx, key, buf)
+	return io.NopCloser(bytes.NewReader(buf)), nil
+}
+
+func (b *cachingBucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	key := name + ":attributes"
+	if buf, ok := b.fetch(ctx, key); ok {
+		if attrs, err := decodeAttributes(buf); err == nil {
+			return attrs, nil
+		}
+	}
+
+	attrs, err := b.Bucket.Attributes(ctx, name)
+	if err != nil {
+		return attrs, err
+	}
+	b.store(ctx, key, encodeAttributes(attrs))
+	return attrs, nil
+}
+
+func (b *cachingBucket) fetch(ctx context.Context, key string) ([]byte, bool) {
+	found, bufs, _, err := b.cache.Fetch(ctx, []string{key})
+	if err != nil {
+		level.Warn(b.logger).Log("msg", "failed to fetch from cache", "key", key, "err", err)
+		return nil, false
+	}
+	if len(found) == 0 {
+		return nil, false
+	}
+	return bufs[0], true
+}
+
+func (b *cachingBucket) store(ctx context.Context, key string, buf []byte) {
+	if err := b.cache.Store(ctx, []string{key}, [][]byte{buf}); err != nil {
+		level.Warn(b.logger).Log("msg", "failed to store in cache", "key", key, "err", err)
+	}
+}
+
+// encodeAttributes encodes the size and last modification time of attrs.
+func encodeAttributes(attrs objstore.ObjectAttributes) []byte {
+	buf := binary.AppendVarint(nil, attrs.Size)
+	return binary.AppendVarint(buf, attrs.LastModified.UnixNano())
+}
+
+func decodeAttributes(buf []byte) (objstore.ObjectAttributes, error) {
+	size, n := binary.Varint(buf)
+	if n <= 0 {
+		return objstore.ObjectAttributes{}, errors.New("invalid size")
+	}
+	lastModified, m := binary.Varint(buf[n:])
+	if m <= 0 {
+		return objstore.ObjectAttributes{}, errors.New("invalid last modification time")
+	}
+	return objstore.ObjectAttributes{
+		Size:         size,
+		LastModified: time.Unix(0, lastModified).UTC(),
+	}, nil
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	chunkcache "github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+)
+
+func TestBucket(t *testing.T) {
+	ctx := context.Background()
+
+	inner := &countingBucket{Bucket: objstore.NewInMemBucket()}
+	require.NoError(t, inner.Upload(ctx, "object", bytes.NewReader([]byte("hello world"))))
+
+	c, err := New(Config{
+		Memory: chunkcache.EmbeddedCacheConfig{Enabled: true, MaxSizeMB: 1},
+		Disk:   DiskConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1},
+	}, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	defer c.Stop()
+
+	bucket := NewBucket(inner, c, log.NewNopLogger())
+
+	readRange := func(off, length int64) string {
+		rc, err := bucket.GetRange(ctx, "object", off, length)
+		require.NoError(t, err)
+		defer rc.Close()
+
+		buf, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	require.Equal(t, "world", readRange(6, 5))
+	require.Equal(t, "world", readRange(6, 5))
+	require.Equal(t, "hello", readRange(0, 5))
+	require.Equal(t, 2, inner.getRanges, "repeated ranges must be read from the cache")
+
+	// Reads until the end of the object aren't cached.
+	require.Equal(t, "world", readRange(6, -1))
+	require.Equal(t, "world", readRange(6, -1))
+	require.Equal(t, 4, inner.getRanges)
+
+	for range 2 {
+		attrs, err := bucket.Attributes(ctx, "object")
+		require.NoError(t, err)
+		require.Equal(t, int64(11), attrs.Size)
+	}
+	require.Equal(t, 1, inner.attributes)
+}
+
+func TestBucket_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := New(Config{
+		Memory: chunkcache.EmbeddedCacheConfig{Enabled: true, MaxSizeMB: 1},
+	}, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	defer c.Stop()
+
+	bucket := NewBucket(objstore.NewInMemBucket(), c, log.NewNopLogger())
+
+	// Errors are passed through and not cached.
+	_, err = bucket.GetRange(ctx, "missing", 0, 5)
+	require.True(t, bucket.IsObjNotFoundErr(err))
+	_, err = bucket.Attributes(ctx, "missing")
+	require.True(t, bucket.IsObjNotFoundErr(err))
+
+	require.NoError(t, bucket.Upload(ctx, "missing", bytes.NewReader([]byte("hello"))))
+	attrs, err := bucket.Attributes(ctx, "missing")
+	require.NoError(t, err)
+	require.Equal(t, int64(5), attrs.Size)
+}
+
+func TestNew_Disabled(t *testing.T) {
+	c, err := New(Config{}, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	require.Nil(t, c)
+}
+
+func Test_encodeAttributes(t *testing.T) {
+	attrs := objstore.ObjectAttributes{
+		Size:         1234,
+		LastModified: time.Date(2025, 1, 1, 12, 0, 0, 5, time.UTC),
+	}
+
+	actual, err := decodeAttributes(encodeAttributes(attrs))
+	require.NoError(t, err)
+	require.Equal(t, attrs, actual)
+
+	_, err = decodeAttributes(nil)
+	require.Error(t, err)
+}
+
+type countingBucket struct {
+	objstore.Bucket
+
+	getRanges, attributes int
+}
+
+func (b *countingBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	b.getRanges++
+	return b.Bucket.GetRange(ctx, name, off, length)
+}
+
+func (b *countingBucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	b.attributes++
+	return b.Bucket.Attributes(ctx, name)
+}
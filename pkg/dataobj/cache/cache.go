@@ -0,0 +1,48 @@
+// Package cache provides a read-through cache for reading data objects from
+// object storage.
+//
+// Ranges of data objects are cached in an in-memory tier and an optional
+// local-disk tier, keyed by object path and byte range. Reads missing from
+// the in-memory tier are looked up in the disk tier before they're fetched
+// from object storage.
+package cache
+
+import (
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	chunkcache "github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+)
+
+const cacheType stats.CacheType = "dataobj"
+
+// New creates a new tiered cache as configured by cfg. New returns nil if no
+// tier is enabled.
+//
+// Hits and misses of each tier are exposed through the metrics of
+// [chunkcache.Instrument], named "dataobj-memory" and "dataobj-disk".
+func New(cfg Config, reg prometheus.Registerer, logger log.Logger) (chunkcache.Cache, error) {
+	var caches []chunkcache.Cache
+
+	if cfg.Memory.IsEnabled() {
+		if c := chunkcache.NewEmbeddedCache("dataobj-memory", cfg.Memory, reg, logger, cacheType); c != nil {
+			caches = append(caches, chunkcache.Instrument("dataobj-memory", c, reg))
+		}
+	}
+
+	if cfg.Disk.Enabled {
+		c, err := newDiskCache(cfg.Disk, reg, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating disk cache: %w", err)
+		}
+		caches = append(caches, chunkcache.Instrument("dataobj-disk", c, reg))
+	}
+
+	if len(caches) == 0 {
+		return nil, nil
+	}
+	return chunkcache.NewTiered(caches), nil
+}
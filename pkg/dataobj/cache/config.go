@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	chunkcache "github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+)
+
+// Config configures the tiers of the cache for data object reads.
+type Config struct {
+	Memory chunkcache.EmbeddedCacheConfig `yaml:"memory"`
+	Disk   DiskConfig                     `yaml:"disk"`
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	// Data objects are immutable, so entries only expire to free up space.
+	cfg.Memory.RegisterFlagsWithPrefixAndDefaults(prefix+"memory.", "In-memory cache of data object reads: ", f, 24*time.Hour)
+	cfg.Disk.RegisterFlagsWithPrefix(prefix+"disk.", f)
+}
+
+func (cfg *Config) Validate() error {
+	return cfg.Disk.Validate()
+}
+
+// DiskConfig configures the local-disk tier of the cache.
+type DiskConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Directory string `yaml:"directory"`
+	MaxSizeMB int64  `yaml:"max_size_mb"`
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *DiskConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Local-disk cache of data object reads: Whether the disk cache is enabled. Reads missing from the in-memory cache are looked up on disk before fetching them from object storage.")
+	f.StringVar(&cfg.Directory, prefix+"directory", "", "Local-disk cache of data object reads: Directory to store cached reads in. Entries already in the directory are reused on startup.")
+	f.Int64Var(&cfg.MaxSizeMB, prefix+"max-size-mb", 10*1024, "Local-disk cache of data object reads: Maximum size of the cache on disk in MB.")
+}
+
+func (cfg *DiskConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Directory == "" {
+		return errors.New("directory is required when the disk cache is enabled")
+	}
+	if cfg.MaxSizeMB <= 0 {
+		return errors.New("max size of the disk cache must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+// tmpSuffix is the suffix of files which are being written. Such files are
+// left behind if the process stops while writing, and are removed on startup.
+const tmpSuffix = ".tmp"
+
+// diskCache is a [chunkcache.Cache] which stores values as files in a local
+// directory. The least recently used files are removed to keep the total size
+// of the files below the configured maximum.
+type diskCache struct {
+	dir          string
+	maxSizeBytes int64
+	logger       log.Logger
+
+	mut       sync.Mutex
+	entries   map[string]*list.Element // Entries by file name.
+	lru       *list.List               // Least recently used entries at the back.
+	sizeBytes int64
+
+	entriesCurrent prometheus.Gauge
+	diskBytes      prometheus.Gauge
+	evicted        prometheus.Counter
+}
+
+type diskEntry struct {
+	name string
+	size int64
+}
+
+func newDiskCache(cfg DiskConfig, reg prometheus.Registerer, logger log.Logger) (*diskCache, error) {
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	c := &diskCache{
+		dir:          cfg.Directory,
+		maxSizeBytes: cfg.MaxSizeMB * 1e6,
+		logger:       logger,
+
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+
+		entriesCurrent: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Subsystem: "dataobj_disk_cache",
+			Name:      "entries",
+			Help:      "Current number of entries in the disk cache of data object reads.",
+		}),
+		diskBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Subsystem: "dataobj_disk_cache",
+			Name:      "disk_bytes",
+			Help:      "Current size of the disk cache of data object reads in bytes.",
+		}),
+		evicted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "dataobj_disk_cache",
+			Name:      "evicted_total",
+			Help:      "Total number of entries evicted from the disk cache of data object reads.",
+		}),
+	}
+	if err := c.load(); err != nil {
+		return nil, fmt.Errorf("loading cache directory: %w", err)
+	}
+	return c, nil
+}
+
+// load populates the cache with the files already in the cache directory,
+// treating the most recently modified files as the most recently used.
+func (c *diskCache) load() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		diskEntry
+		modTime time.Time
+	}
+	var files []file
+
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() {
+			continue
+		}
+		if strings.HasSuffix(dirEntry.Name(), tmpSuffix) {
+			_ = os.Remove(filepath.Join(c.dir, dirEntry.Name()))
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		files = append(files, file{
+			diskEntry: diskEntry{name: dirEntry.Name(), size: info.Size()},
+			modTime:   info.ModTime(),
+		})
+	}
+
+	slices.SortFunc(files, func(a, b file) int { return a.modTime.Compare(b.modTime) })
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for _, f := range files {
+		c.add(f.diskEntry)
+	}
+	c.evict()
+	return nil
+}
+
+// Fetch implements [chunkcache.Cache].
+func (c *diskCache) Fetch(_ context.Context, keys []string) (found []string, bufs [][]byte, missing []string, err error) {
+	for _, key := range keys {
+		name := fileName(key)
+
+		buf, err := os.ReadFile(filepath.Join(c.dir, name))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				level.Warn(c.logger).Log("msg", "failed to read cache entry", "err", err)
+			}
+			missing = append(missing, key)
+			continue
+		}
+
+		c.mut.Lock()
+		if element, ok := c.entries[name]; ok {
+			c.lru.MoveToFront(element)
+		}
+		c.mut.Unlock()
+
+		found = append(found, key)
+		bufs = append(bufs, buf)
+	}
+	return found, bufs, missing, nil
+}
+
+// Store implements [chunkcache.Cache].
+func (c *diskCache) Store(_ context.Context, keys []string, bufs [][]byte) error {
+	var errs []error
+	for i, key := range keys {
+		if int64(len(bufs[i])) > c.maxSizeBytes {
+			continue
+		}
+		if err := c.store(fileName(key), bufs[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *diskCache) store(name string, buf []byte) error {
+	// Write to a temporary file first so that readers never see partially
+	// written entries.
+	f, err := os.CreateTemp(c.dir, name+"-*"+tmpSuffix)
+	if err != nil {
+		return fmt.Errorf("creating cache entry: %w", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if err := os.Rename(f.Name(), filepath.Join(c.dir, name)); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	c.add(diskEntry{name: name, size: int64(len(buf))})
+	c.evict()
+	return nil
+}
+
+// add adds or replaces the entry in the LRU. add must be called with c.mut
+// held.
+func (c *diskCache) add(entry diskEntry) {
+	if element, ok := c.entries[entry.name]; ok {
+		c.sizeBytes -= c.lru.Remove(element).(diskEntry).size
+	}
+	c.entries[entry.name] = c.lru.PushFront(entry)
+	c.sizeBytes += entry.size
+
+	c.entriesCurrent.Set(float64(len(c.entries)))
+	c.diskBytes.Set(float64(c.sizeBytes))
+}
+
+// evict removes the least recently used entries until the cache fits within
+// its maximum size. evict must be called with c.mut held.
+func (c *diskCache) evict() {
+	for c.sizeBytes > c.maxSizeBytes {
+		element := c.lru.Back()
+		if element == nil {
+			break
+		}
+
+		entry := c.lru.Remove(element).(diskEntry)
+		delete(c.entries, entry.name)
+		c.sizeBytes -= entry.size
+		c.evicted.Inc()
+
+		if err := os.Remove(filepath.Join(c.dir, entry.name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			level.Warn(c.logger).Log("msg", "failed to remove evicted cache entry", "err", err)
+		}
+	}
+
+	c.entriesCurrent.Set(float64(len(c.entries)))
+	c.diskBytes.Set(float64(c.sizeBytes))
+}
+
+// Stop implements [chunkcache.Cache]. Entries are kept on disk to be reused
+// after a restart.
+func (c *diskCache) Stop() {}
+
+// GetCacheType implements [chunkcache.Cache].
+func (c *diskCache) GetCacheType() stats.CacheType { return cacheType }
+
+// fileName returns the name of the file storing the entry for key. Keys are
+// hashed as they may contain characters which aren't valid in file names.
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
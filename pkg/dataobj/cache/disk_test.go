@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Leftovers of interrupted writes are removed on startup.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "partial"+tmpSuffix), []byte("x"), 0o644))
+
+	c, err := newDiskCache(DiskConfig{Directory: dir, MaxSizeMB: 1}, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	// Use a smaller limit than configurable to test eviction.
+	c.maxSizeBytes = 10
+
+	require.NoError(t, c.Store(ctx, []string{"a", "b"}, [][]byte{[]byte("aaaa"), []byte("bbbb")}))
+
+	found, bufs, missing, err := c.Fetch(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, found)
+	require.Equal(t, [][]byte{[]byte("aaaa"), []byte("bbbb")}, bufs)
+	require.Equal(t, []string{"c"}, missing)
+
+	// "a" was used more recently than "b", so "b" is evicted to make room for
+	// "c". Values larger than the cache are never stored.
+	_, _, _, err = c.Fetch(ctx, []string{"a"})
+	require.NoError(t, err)
+	require.NoError(t, c.Store(ctx, []string{"c", "d"}, [][]byte{[]byte("cccc"), []byte("too large to cache")}))
+
+	found, _, missing, err = c.Fetch(ctx, []string{"a", "b", "c", "d"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, found)
+	require.Equal(t, []string{"b", "d"}, missing)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, f := range files {
+		require.False(t, strings.HasSuffix(f.Name(), tmpSuffix))
+	}
+
+	// Entries are reused after a restart.
+	c, err = newDiskCache(DiskConfig{Directory: dir, MaxSizeMB: 1}, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, int64(8), c.sizeBytes)
+
+	found, bufs, _, err = c.Fetch(ctx, []string{"a", "c"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, found)
+	require.Equal(t, [][]byte{[]byte("aaaa"), []byte("cccc")}, bufs)
+}
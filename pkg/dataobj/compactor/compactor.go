@@ -0,0 +1,437 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// compactor merges the small data objects of the metastore windows of a
+// single tenant.
+type compactor struct {
+	cfg      Config
+	bucket   objstore.Bucket
+	tenantID string
+	lease    *lease
+	logger   log.Logger
+	metrics  *metrics
+
+	// rules drop logs from merged and rewritten data objects. rules is nil if
+	// retention is disabled.
+	rules *rules
+
+	builder  *dataobj.Builder
+	buf      *bytes.Buffer
+	updater  *metastore.Updater
+	uploader *uploader.Uploader
+}
+
+func newCompactor(cfg Config, bucket objstore.Bucket, tenantID string, lease *lease, rules *rules, builder *dataobj.Builder, buf *bytes.Buffer, metrics *metrics, logger log.Logger) *compactor {
+	return &compactor{
+		cfg:      cfg,
+		bucket:   bucket,
+		tenantID: tenantID,
+		lease:    lease,
+		logger:   log.With(logger, "tenant", tenantID),
+		metrics:  metrics,
+		rules:    rules,
+
+		builder:  builder,
+		buf:      buf,
+		updater:  metastore.NewUpdater(bucket, tenantID, logger),
+		uploader: uploader.New(cfg.UploaderConfig, bucket, tenantID),
+	}
+}
+
+// CompactWindows compacts every metastore window of the tenant which ended at
+// least [Config.WindowDelay] before now.
+func (c *compactor) CompactWindows(ctx context.Context, now time.Time) error {
+	windows, err := metastore.Windows(ctx, c.bucket, c.tenantID)
+	if err != nil {
+		return fmt.Errorf("listing metastore windows: %w", err)
+	}
+
+	for _, window := range windows {
+		// Windows are sorted, so all following windows are too recent as well.
+		if window.Add(metastore.WindowSize + c.cfg.WindowDelay).After(now) {
+			break
+		}
+
+		if err := c.CompactWindow(ctx, window, now); err != nil {
+			return fmt.Errorf("compacting window %s: %w", window.Format(time.RFC3339), err)
+		}
+	}
+	return nil
+}
+
+// CompactWindow merges the small data objects of window into as few data
+// objects as possible, and atomically replaces them in the metastore. The
+// merged data objects are marked for deletion after [Config.DeleteDelay].
+//
+// Nothing is done if the window holds fewer than [Config.MinInputObjects]
+// small data objects.
+func (c *compactor) CompactWindow(ctx context.Context, window, now time.Time) error {
+	inputs, err := c.selectInputs(ctx, window)
+	if err != nil {
+		return err
+	} else if len(inputs) < c.cfg.MinInputObjects {
+		return nil
+	}
+
+	level.Info(c.logger).Log("msg", "compacting data objects", "window", window.Format(time.RFC3339), "inputs", len(inputs))
+
+	outputs, err := c.merge(ctx, inputs)
+	if err != nil {
+		return fmt.Errorf("merging data objects: %w", err)
+	}
+
+	if err := c.renewLease(ctx, outputs, now); err != nil {
+		return err
+	}
+	if err := c.updater.Replace(ctx, window, inputs, outputs); err != nil {
+		return fmt.Errorf("replacing metastore entries: %w", err)
+	}
+
+	// The inputs are only marked for deletion once they have been replaced in
+	// the metastore. If marking fails, the inputs are leaked rather than
+	// deleted while still being referenced.
+	if err := markForDeletion(ctx, c.bucket, c.tenantID, inputs, now.Add(c.cfg.DeleteDelay)); err != nil {
+		return fmt.Errorf("marking data objects for deletion: %w", err)
+	}
+
+	c.metrics.compactedObjects.Add(float64(len(inputs)))
+	c.metrics.outputObjects.Add(float64(len(outputs)))
+	level.Info(c.logger).Log("msg", "compacted data objects", "window", window.Format(time.RFC3339), "inputs", len(inputs), "outputs", len(outputs))
+	return nil
+}
+
+// ApplyRules drops the logs matching the rules of the compactor from the data
+// objects of the tenant. Data objects whose logs are all past retention are
+// removed as a whole, while other data objects with logs to drop are
+// rewritten. Like compaction inputs, replaced data objects are deleted after
+// [Config.DeleteDelay].
+func (c *compactor) ApplyRules(ctx context.Context, now time.Time) error {
+	if c.rules == nil {
+		return nil
+	}
+
+	windows, err := metastore.Windows(ctx, c.bucket, c.tenantID)
+	if err != nil {
+		return fmt.Errorf("listing metastore windows: %w", err)
+	}
+
+	// Data objects spanning multiple windows are listed in each of them, but
+	// only need to be processed once.
+	seen := make(map[string]struct{})
+
+	for _, window := range windows {
+		entries, err := metastore.Entries(ctx, c.bucket, c.tenantID, window)
+		if err != nil {
+			return fmt.Errorf("listing metastore entries of window %s: %w", window.Format(time.RFC3339), err)
+		}
+
+		for _, entry := range entries {
+			if _, ok := seen[entry.Path]; ok {
+				continue
+			}
+			seen[entry.Path] = struct{}{}
+
+			if err := c.applyRules(ctx, entry, now); err != nil {
+				return fmt.Errorf("applying rules to %s: %w", entry.Path, err)
+			}
+		}
+	}
+
+	// Metastore objects are emptied rather than deleted once their data
+	// objects are removed. Past retention, no data objects are added to their
+	// window anymore, so empty ones are deleted.
+	for _, window := range windows {
+		if !c.rules.WindowExpired(window) {
+			continue
+		}
+		deleted, err := metastore.DeleteEmpty(ctx, c.bucket, c.tenantID, window)
+		if err != nil {
+			return fmt.Errorf("deleting metastore object of window %s: %w", window.Format(time.RFC3339), err)
+		} else if deleted {
+			level.Info(c.logger).Log("msg", "deleted expired metastore object", "window", window.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+func (c *compactor) applyRules(ctx context.Context, entry metastore.Entry, now time.Time) error {
+	var outputs map[string]dataobj.FlushStats
+
+	switch {
+	case c.rules.Expired(entry):
+		level.Info(c.logger).Log("msg", "removing expired data object", "path", entry.Path)
+		c.metrics.expiredObjects.Inc()
+
+	case c.rules.MayDrop(entry):
+		// Data objects are only rewritten if some of their logs are dropped, as
+		// rules may apply to none of their streams.
+		dropped, err := countDropped(ctx, dataobj.FromBucket(c.bucket, entry.Path), c.rules)
+		if err != nil {
+			return err
+		} else if dropped == 0 {
+			return nil
+		}
+
+		level.Info(c.logger).Log("msg", "rewriting data object", "path", entry.Path, "dropped", dropped)
+		outputs, err = c.merge(ctx, []string{entry.Path})
+		if err != nil {
+			return fmt.Errorf("rewriting data object: %w", err)
+		}
+		c.metrics.rewrittenObjects.Inc()
+
+	default:
+		return nil
+	}
+
+	if err := c.renewLease(ctx, outputs, now); err != nil {
+		return err
+	}
+
+	// The data object is replaced in every window it's listed in. Each window
+	// is updated atomically, but not all windows at once.
+	for window := entry.Start.Truncate(metastore.WindowSize).UTC(); !window.After(entry.End); window = window.Add(metastore.WindowSize) {
+		added := make(map[string]dataobj.FlushStats, len(outputs))
+		for path, stats := range outputs {
+			if stats.MinTimestamp.Before(window.Add(metastore.WindowSize)) && !stats.MaxTimestamp.Before(window) {
+				added[path] = stats
+			}
+		}
+
+		if err := c.updater.Replace(ctx, window, []string{entry.Path}, added); err != nil {
+			return fmt.Errorf("replacing metastore entries: %w", err)
+		}
+	}
+
+	if err := markForDeletion(ctx, c.bucket, c.tenantID, []string{entry.Path}, now.Add(c.cfg.DeleteDelay)); err != nil {
+		return fmt.Errorf("marking data object for deletion: %w", err)
+	}
+	return nil
+}
+
+// renewLease renews the lease of the tenant before the metastore is updated
+// with outputs, so that the lease doesn't expire while the outputs are being
+// listed. If the lease was lost to another replica, the outputs are never
+// listed in the metastore and are marked for deletion right away.
+func (c *compactor) renewLease(ctx context.Context, outputs map[string]dataobj.FlushStats, now time.Time) error {
+	err := c.lease.Acquire(ctx)
+	if err == nil {
+		return nil
+	}
+
+	if len(outputs) > 0 {
+		paths := slices.Sorted(maps.Keys(outputs))
+		if markErr := markForDeletion(ctx, c.bucket, c.tenantID, paths, now); markErr != nil {
+			level.Warn(c.logger).Log("msg", "failed to mark unlisted data objects for deletion", "err", markErr)
+		}
+	}
+	return fmt.Errorf("renewing tenant lease: %w", err)
+}
+
+// countDropped returns the number of logs of object dropped by r.
+func countDropped(ctx context.Context, object *dataobj.Object, r *rules) (int, error) {
+	var dropped int
+	err := forEachStream(ctx, object, func(stream logproto.Stream) error {
+		_, n, err := r.Apply(stream)
+		dropped += n
+		return err
+	})
+	return dropped, err
+}
+
+// selectInputs returns the paths of the small data objects of window which
+// can be compacted.
+func (c *compactor) selectInputs(ctx context.Context, window time.Time) ([]string, error) {
+	entries, err := metastore.Entries(ctx, c.bucket, c.tenantID, window)
+	if err != nil {
+		return nil, fmt.Errorf("listing metastore entries: %w", err)
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		// Data objects spanning multiple windows are listed in the metastore
+		// object of each window. They're left alone so that replacing them here
+		// doesn't leave dangling entries in the other windows.
+		if entry.Start.Before(window) || !entry.End.Before(window.Add(metastore.WindowSize)) {
+			continue
+		}
+		candidates = append(candidates, entry.Path)
+	}
+	if len(candidates) < c.cfg.MinInputObjects {
+		return nil, nil
+	}
+
+	var inputs []string
+	for _, path := range candidates {
+		attrs, err := c.bucket.Attributes(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("getting attributes of %s: %w", path, err)
+		}
+		if attrs.Size < int64(c.cfg.TargetObjectSize)/2 {
+			inputs = append(inputs, path)
+		}
+	}
+	return inputs, nil
+}
+
+// merge appends the logs of the inputs to new data objects and uploads them.
+// The builder sorts streams and logs, so the outputs are sorted regardless of
+// the order of the inputs. Logs dropped by the rules of the compactor are
+// skipped. merge returns the flush stats of the uploaded data objects by path,
+// which is empty if all logs were dropped.
+func (c *compactor) merge(ctx context.Context, inputs []string) (map[string]dataobj.FlushStats, error) {
+	outputs := make(map[string]dataobj.FlushStats)
+
+	c.builder.Reset()
+	defer c.builder.Reset()
+
+	flush := func() error {
+		c.buf.Reset()
+		stats, err := c.builder.Flush(c.buf)
+		if errors.Is(err, dataobj.ErrBuilderEmpty) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("flushing builder: %w", err)
+		}
+
+		path, err := c.uploader.Upload(ctx, c.buf)
+		if err != nil {
+			return err
+		}
+		outputs[path] = stats
+		return nil
+	}
+
+	appendStream := func(stream logproto.Stream) error {
+		if c.rules != nil {
+			var err error
+			if stream, _, err = c.rules.Apply(stream); err != nil {
+				return err
+			} else if len(stream.Entries) == 0 {
+				return nil
+			}
+		}
+
+		err := c.builder.Append(stream)
+		if !errors.Is(err, dataobj.ErrBuilderFull) {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		return c.builder.Append(stream)
+	}
+
+	for _, path := range inputs {
+		object := dataobj.FromBucket(c.bucket, path)
+		if err := forEachStream(ctx, object, appendStream); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// forEachStream calls f with the logs of object. Consecutive logs of the same
+// stream are passed as a single [logproto.Stream].
+func forEachStream(ctx context.Context, object *dataobj.Object, f func(logproto.Stream) error) error {
+	md, err := object.Metadata(ctx)
+	if err != nil {
+		return fmt.Errorf("resolving object metadata: %w", err)
+	}
+
+	streamLabels := make(map[int64]string)
+
+	var streamsReader dataobj.StreamsReader
+	defer streamsReader.Close()
+
+	streams := make([]dataobj.Stream, 1024)
+	for i := 0; i < md.StreamsSections; i++ {
+		streamsReader.Reset(object, i)
+		for {
+			n, err := streamsReader.Read(ctx, streams)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("reading streams: %w", err)
+			}
+			for _, stream := range streams[:n] {
+				streamLabels[stream.ID] = stream.Labels.String()
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+		}
+	}
+
+	var logsReader dataobj.LogsReader
+	defer logsReader.Close()
+
+	records := make([]dataobj.Record, 1024)
+	for i := 0; i < md.LogsSections; i++ {
+		logsReader.Reset(object, i)
+		for {
+			n, err := logsReader.Read(ctx, records)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("reading logs: %w", err)
+			}
+
+			var stream logproto.Stream
+			for j, record := range records[:n] {
+				if j > 0 && record.StreamID != records[j-1].StreamID {
+					if err := f(stream); err != nil {
+						return err
+					}
+					stream = logproto.Stream{}
+				}
+
+				lbs, ok := streamLabels[record.StreamID]
+				if !ok {
+					return fmt.Errorf("log record references unknown stream %d", record.StreamID)
+				}
+				stream.Labels = lbs
+
+				metadata := make(push.LabelsAdapter, 0, len(record.Metadata))
+				for _, l := range record.Metadata {
+					metadata = append(metadata, push.LabelAdapter{Name: l.Name, Value: l.Value})
+				}
+				stream.Entries = append(stream.Entries, logproto.Entry{
+					Timestamp:          record.Timestamp,
+					Line:               string(record.Line),
+					StructuredMetadata: metadata,
+				})
+			}
+			if len(stream.Entries) > 0 {
+				if err := f(stream); err != nil {
+					return err
+				}
+			}
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const tenantID = "test-tenant"
+
+var testBuilderConfig = dataobj.BuilderConfig{
+	TargetPageSize:          1024 * 1024,      // 1MB
+	TargetObjectSize:        10 * 1024 * 1024, // 10MB
+	TargetSectionSize:       1024 * 1024,      // 1MB
+	BufferSize:              1024 * 1024,      // 1MB
+	SectionStripeMergeLimit: 2,
+}
+
+func TestService_Compaction(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+
+	window := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Small objects of the same window, each holding logs of overlapping
+	// streams.
+	var inputs []string
+	for i := range 4 {
+		ts := window.Add(time.Duration(4-i) * time.Hour)
+		inputs = append(inputs, writeObject(t, bucket,
+			logproto.Stream{
+				Labels: `{app="foo"}`,
+				Entries: []logproto.Entry{
+					{Timestamp: ts, Line: "foo line"},
+					{Timestamp: ts.Add(time.Minute), Line: "foo line with metadata", StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: "123"}}},
+				},
+			},
+			logproto.Stream{
+				Labels:  `{app="bar"}`,
+				Entries: []logproto.Entry{{Timestamp: ts.Add(time.Second), Line: "bar line"}},
+			},
+		))
+	}
+
+	// An object spanning two windows, which is left alone.
+	spanning := writeObject(t, bucket, logproto.Stream{
+		Labels: `{app="foo"}`,
+		Entries: []logproto.Entry{
+			{Timestamp: window.Add(-time.Hour), Line: "foo line"},
+			{Timestamp: window.Add(time.Hour), Line: "foo line"},
+		},
+	})
+
+	cfg := Config{
+		BuilderConfig:      testBuilderConfig,
+		UploaderConfig:     uploader.Config{SHAPrefixSize: 2},
+		CompactionInterval: time.Minute,
+		WindowDelay:        time.Hour,
+		MinInputObjects:    2,
+		DeleteDelay:        time.Hour,
+		LeaseDuration:      time.Hour,
+	}
+	s, err := New(cfg, bucket, "compactor-1", nil, nil, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+
+	// The window hasn't been closed for long enough yet.
+	now := window.Add(metastore.WindowSize + 30*time.Minute)
+	s.now = func() time.Time { return now }
+	s.runOnce(ctx)
+
+	entries, err := metastore.Entries(ctx, bucket, tenantID, window)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	// Compact the window.
+	now = window.Add(metastore.WindowSize + 2*time.Hour)
+	s.runOnce(ctx)
+
+	entries, err = metastore.Entries(ctx, bucket, tenantID, window)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	var output string
+	for _, entry := range entries {
+		if entry.Path != spanning {
+			output = entry.Path
+			require.Equal(t, window.Add(time.Hour), entry.Start)
+			require.Equal(t, window.Add(4*time.Hour+time.Minute), entry.End)
+		}
+	}
+	require.NotEmpty(t, output)
+
+	// The output holds the logs of all inputs, sorted by stream and timestamp.
+	records := readRecords(t, dataobj.FromBucket(bucket, output))
+	require.Len(t, records, 12)
+
+	var withMetadata int
+	for _, record := range records {
+		if record.Metadata.Get("trace_id") == "123" {
+			withMetadata++
+		}
+	}
+	require.Equal(t, 4, withMetadata)
+
+	for i := 1; i < len(records); i++ {
+		prev, cur := records[i-1], records[i]
+		if prev.StreamID == cur.StreamID {
+			require.False(t, cur.Timestamp.Before(prev.Timestamp), "logs of a stream must be sorted by timestamp")
+		}
+	}
+
+	// Inputs are kept until the delete delay elapsed.
+	for _, path := range inputs {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+
+	now = now.Add(cfg.DeleteDelay)
+	s.runOnce(ctx)
+
+	for _, path := range inputs {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+	for _, path := range []string{output, spanning} {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+
+	// The window isn't compacted again.
+	entries, err = metastore.Entries(ctx, bucket, tenantID, window)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+func TestService_Lease(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+
+	window := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 2 {
+		writeObject(t, bucket, logproto.Stream{
+			Labels:  `{app="foo"}`,
+			Entries: []logproto.Entry{{Timestamp: window.Add(time.Duration(i+1) * time.Hour), Line: "foo line"}},
+		})
+	}
+
+	cfg := Config{
+		BuilderConfig:      testBuilderConfig,
+		UploaderConfig:     uploader.Config{SHAPrefixSize: 2},
+		CompactionInterval: time.Minute,
+		WindowDelay:        time.Hour,
+		MinInputObjects:    2,
+		DeleteDelay:        time.Hour,
+		LeaseDuration:      time.Hour,
+	}
+	s, err := New(cfg, bucket, "compactor-1", nil, nil, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+
+	now := window.Add(metastore.WindowSize + 2*time.Hour)
+	s.now = func() time.Time { return now }
+
+	// Another replica holds the lease of the tenant, so the window is left
+	// alone.
+	other := newLease(bucket, tenantID, "compactor-2", cfg.LeaseDuration, s.now)
+	require.NoError(t, other.Acquire(ctx))
+	s.runOnce(ctx)
+
+	entries, err := metastore.Entries(ctx, bucket, tenantID, window)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// The lease of the other replica expired.
+	now = now.Add(cfg.LeaseDuration)
+	s.runOnce(ctx)
+
+	entries, err = metastore.Entries(ctx, bucket, tenantID, window)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// The lease is released after the run, so the other replica can take it
+	// over right away.
+	require.NoError(t, other.Acquire(ctx))
+	require.ErrorIs(t, newLease(bucket, tenantID, "compactor-1", cfg.LeaseDuration, s.now).Acquire(ctx), errLeaseHeld)
+}
+
+func writeObject(t *testing.T, bucket objstore.Bucket, streams ...logproto.Stream) string {
+	t.Helper()
+
+	builder, err := dataobj.NewBuilder(testBuilderConfig)
+	require.NoError(t, err)
+	for _, stream := range streams {
+		require.NoError(t, builder.Append(stream))
+	}
+
+	var buf bytes.Buffer
+	stats, err := builder.Flush(&buf)
+	require.NoError(t, err)
+
+	path, err := uploader.New(uploader.Config{SHAPrefixSize: 2}, bucket, tenantID).Upload(context.Background(), &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, metastore.NewUpdater(bucket, tenantID, log.NewNopLogger()).Update(context.Background(), path, stats))
+	return path
+}
+
+func readRecords(t *testing.T, object *dataobj.Object) []dataobj.Record {
+	t.Helper()
+
+	md, err := object.Metadata(context.Background())
+	require.NoError(t, err)
+
+	var records []dataobj.Record
+	for i := 0; i < md.LogsSections; i++ {
+		r := dataobj.NewLogsReader(object, i)
+
+		buf := make([]dataobj.Record, 128)
+		for {
+			n, err := r.Read(context.Background(), buf)
+			if err != nil && !errors.Is(err, io.EOF) {
+				require.NoError(t, err)
+			}
+			for _, record := range buf[:n] {
+				record.Line = bytes.Clone(record.Line)
+				record.Metadata = record.Metadata.Copy()
+				records = append(records, record)
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+		}
+	}
+	return records
+}
+
+func TestService_Retention(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+
+	now := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	expired := writeObject(t, bucket, logproto.Stream{
+		Labels:  `{app="foo"}`,
+		Entries: []logproto.Entry{{Timestamp: now.Add(-40 * 24 * time.Hour), Line: "old"}},
+	})
+	shortRetention := writeObject(t, bucket,
+		logproto.Stream{
+			Labels:  `{app="short"}`,
+			Entries: []logproto.Entry{{Timestamp: now.Add(-36 * time.Hour), Line: "short"}},
+		},
+		logproto.Stream{
+			Labels:  `{app="foo"}`,
+			Entries: []logproto.Entry{{Timestamp: now.Add(-36 * time.Hour), Line: "kept"}},
+		},
+	)
+	deleted := writeObject(t, bucket, logproto.Stream{
+		Labels: `{app="foo"}`,
+		Entries: []logproto.Entry{
+			{Timestamp: now.Add(-time.Hour), Line: "secret"},
+			{Timestamp: now.Add(-time.Hour + time.Minute), Line: "kept"},
+		},
+	})
+	untouched := writeObject(t, bucket, logproto.Stream{
+		Labels:  `{app="bar"}`,
+		Entries: []logproto.Entry{{Timestamp: now.Add(-2 * time.Hour), Line: "bar"}},
+	})
+
+	limits := &fakeLimits{
+		period: 30 * 24 * time.Hour,
+		streams: []validation.StreamRetention{{
+			Period:   model.Duration(24 * time.Hour),
+			Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "short")},
+		}},
+	}
+	deletes := &fakeDeleteRequests{requests: []deletion.DeleteRequest{
+		{
+			Query:     `{app="foo"} |= "secret"`,
+			StartTime: model.TimeFromUnixNano(now.Add(-2 * time.Hour).UnixNano()),
+			EndTime:   model.TimeFromUnixNano(now.UnixNano()),
+			CreatedAt: model.TimeFromUnixNano(now.Add(-48 * time.Hour).UnixNano()),
+		},
+		{
+			// Still within the cancel period.
+			Query:     `{app="bar"}`,
+			StartTime: model.TimeFromUnixNano(now.Add(-2 * time.Hour).UnixNano()),
+			EndTime:   model.TimeFromUnixNano(now.UnixNano()),
+			CreatedAt: model.TimeFromUnixNano(now.Add(-time.Hour).UnixNano()),
+		},
+	}}
+
+	cfg := Config{
+		BuilderConfig:             testBuilderConfig,
+		UploaderConfig:            uploader.Config{SHAPrefixSize: 2},
+		CompactionInterval:        time.Minute,
+		WindowDelay:               time.Hour,
+		MinInputObjects:           2,
+		DeleteDelay:               time.Hour,
+		LeaseDuration:             time.Hour,
+		RetentionEnabled:          true,
+		DeleteRequestCancelPeriod: 24 * time.Hour,
+	}
+	s, err := New(cfg, bucket, "compactor-1", limits, deletes, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+	s.runOnce(ctx)
+
+	// The metastore object of the expired data object is deleted once it no
+	// longer lists any data objects.
+	windows, err := metastore.Windows(ctx, bucket, tenantID)
+	require.NoError(t, err)
+	require.NotContains(t, windows, now.Add(-40*24*time.Hour).Truncate(metastore.WindowSize).UTC())
+	require.NotEmpty(t, windows)
+
+	lines := func(window time.Time) map[string][]string {
+		entries, err := metastore.Entries(ctx, bucket, tenantID, window)
+		require.NoError(t, err)
+
+		res := make(map[string][]string)
+		for _, entry := range entries {
+			for _, record := range readRecords(t, dataobj.FromBucket(bucket, entry.Path)) {
+				res[entry.Path] = append(res[entry.Path], string(record.Line))
+			}
+		}
+		return res
+	}
+
+	rewritten := lines(now.Add(-36 * time.Hour))
+	require.Len(t, rewritten, 1)
+	require.NotContains(t, rewritten, shortRetention)
+	for _, l := range rewritten {
+		require.Equal(t, []string{"kept"}, l)
+	}
+
+	recent := lines(now)
+	require.Len(t, recent, 2)
+	require.NotContains(t, recent, deleted)
+	require.Equal(t, []string{"bar"}, recent[untouched])
+	for path, l := range recent {
+		if path != untouched {
+			require.Equal(t, []string{"kept"}, l)
+		}
+	}
+
+	// Replaced data objects are deleted after the delete delay.
+	now = now.Add(cfg.DeleteDelay)
+	s.runOnce(ctx)
+
+	for _, path := range []string{expired, shortRetention, deleted} {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+}
+
+type fakeLimits struct {
+	retention.Limits
+
+	period  time.Duration
+	streams []validation.StreamRetention
+}
+
+func (l *fakeLimits) RetentionPeriod(_ string) time.Duration { return l.period }
+
+func (l *fakeLimits) StreamRetention(_ string) []validation.StreamRetention { return l.streams }
+
+type fakeDeleteRequests struct {
+	deletion.DeleteRequestsClient
+
+	requests []deletion.DeleteRequest
+}
+
+func (d *fakeDeleteRequests) GetAllDeleteRequestsForUser(_ context.Context, _ string) ([]deletion.DeleteRequest, error) {
+	return d.requests, nil
+}
@@ -0,0 +1,58 @@
+package compactor
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
+)
+
+type Config struct {
+	dataobj.BuilderConfig
+	UploaderConfig uploader.Config `yaml:"uploader"`
+
+	CompactionInterval time.Duration `yaml:"compaction_interval"`
+	WindowDelay        time.Duration `yaml:"window_delay"`
+	MinInputObjects    int           `yaml:"min_input_objects"`
+	DeleteDelay        time.Duration `yaml:"delete_delay"`
+	LeaseDuration      time.Duration `yaml:"lease_duration"`
+
+	RetentionEnabled          bool          `yaml:"retention_enabled"`
+	DeleteRequestCancelPeriod time.Duration `yaml:"delete_request_cancel_period"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.CompactionInterval <= 0 {
+		return errors.New("compaction interval must be greater than 0")
+	}
+	if cfg.LeaseDuration <= 0 {
+		return errors.New("lease duration must be greater than 0")
+	}
+	if cfg.MinInputObjects < 2 {
+		return errors.New("min input objects must be at least 2")
+	}
+	if err := cfg.UploaderConfig.Validate(); err != nil {
+		return err
+	}
+
+	return cfg.BuilderConfig.Validate()
+}
+
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix("dataobj-compactor.", f)
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	cfg.BuilderConfig.RegisterFlagsWithPrefix(prefix, f)
+	cfg.UploaderConfig.RegisterFlagsWithPrefix(prefix, f)
+
+	f.DurationVar(&cfg.CompactionInterval, prefix+"compaction-interval", 10*time.Minute, "How often to look for metastore windows to compact.")
+	f.DurationVar(&cfg.WindowDelay, prefix+"window-delay", 2*time.Hour, "How long to wait after the end of a metastore window before compacting its data objects. Should be greater than the idle flush timeout of the dataobj consumer, so that no more objects are written to the window.")
+	f.IntVar(&cfg.MinInputObjects, prefix+"min-input-objects", 4, "The minimum number of small data objects in a metastore window needed to compact them. Data objects are small if they are less than half the target object size.")
+	f.DurationVar(&cfg.DeleteDelay, prefix+"delete-delay", 2*time.Hour, "How long to wait after compacting or rewriting data objects before deleting them, giving in-flight queries time to finish reading them.")
+	f.DurationVar(&cfg.LeaseDuration, prefix+"lease-duration", time.Hour, "How long a compactor replica holds the lease of a tenant without renewing it. Replicas share tenants through leases stored in the bucket, so that the data objects of a tenant are only compacted by one replica at a time. The lease is renewed before each metastore update, so it must be longer than compacting a single metastore window takes.")
+	f.BoolVar(&cfg.RetentionEnabled, prefix+"retention-enabled", false, "Apply the per-tenant and per-stream retention periods and the delete requests of tenants to data objects. Data objects past retention are removed, and data objects with logs to drop are rewritten.")
+	f.DurationVar(&cfg.DeleteRequestCancelPeriod, prefix+"delete-request-cancel-period", 24*time.Hour, "How long delete requests can be canceled before they're enforced by rewriting data objects. Until then, deleted logs are only filtered out at query time.")
+}
@@ -0,0 +1,105 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thanos-io/objstore"
+)
+
+// Compacted data objects are deleted once they are no longer referenced by the
+// metastore and in-flight queries had time to finish reading them. Rather than
+// keeping track of them in memory, the compactor writes a deletion marker per
+// compaction, listing the paths of its inputs. Markers are named after the
+// time from which the inputs can be deleted, so that they survive restarts.
+//
+// Markers are stored at tenant-<tenant>/compactor/deletes/<unix seconds>-<hash>.
+
+func deletesPrefix(tenantID string) string {
+	return fmt.Sprintf("tenant-%s/compactor/deletes/", tenantID)
+}
+
+// markForDeletion writes a deletion marker for the data objects at paths,
+// which can be deleted from deleteAt onwards.
+func markForDeletion(ctx context.Context, bucket objstore.Bucket, tenantID string, paths []string, deleteAt time.Time) error {
+	content := []byte(strings.Join(paths, "\n"))
+	sum := sha256.Sum256(content)
+
+	name := fmt.Sprintf("%s%d-%x", deletesPrefix(tenantID), deleteAt.Unix(), sum[:8])
+	return bucket.Upload(ctx, name, bytes.NewReader(content))
+}
+
+// deleteMarked deletes the data objects of the deletion markers of tenantID
+// which are due at now, along with the markers themselves. It returns the
+// number of deleted data objects.
+func deleteMarked(ctx context.Context, bucket objstore.Bucket, tenantID string, now time.Time) (int, error) {
+	var markers []string
+	err := bucket.Iter(ctx, deletesPrefix(tenantID), func(name string) error {
+		deleteAt, ok := parseMarker(strings.TrimPrefix(name, deletesPrefix(tenantID)))
+		if ok && !deleteAt.After(now) {
+			markers = append(markers, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("listing deletion markers: %w", err)
+	}
+
+	var deleted int
+	for _, marker := range markers {
+		paths, err := readMarker(ctx, bucket, marker)
+		if err != nil {
+			return deleted, fmt.Errorf("reading deletion marker %s: %w", marker, err)
+		}
+
+		for _, path := range paths {
+			// Objects may already be gone if a previous attempt failed after
+			// deleting some of them.
+			if err := bucket.Delete(ctx, path); err != nil && !bucket.IsObjNotFoundErr(err) {
+				return deleted, fmt.Errorf("deleting %s: %w", path, err)
+			}
+			deleted++
+		}
+
+		if err := bucket.Delete(ctx, marker); err != nil && !bucket.IsObjNotFoundErr(err) {
+			return deleted, fmt.Errorf("deleting deletion marker %s: %w", marker, err)
+		}
+	}
+	return deleted, nil
+}
+
+// parseMarker returns the time from which the data objects of the deletion
+// marker with the given base name can be deleted.
+func parseMarker(name string) (time.Time, bool) {
+	seconds, _, ok := strings.Cut(name, "-")
+	if !ok {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0).UTC(), true
+}
+
+func readMarker(ctx context.Context, bucket objstore.Bucket, name string) ([]string, error) {
+	rc, err := bucket.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	} else if len(content) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(content), "\n"), nil
+}
@@ -0,0 +1,111 @@
+package compactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thanos-io/objstore"
+)
+
+// Compactor replicas coordinate through a lease per tenant, so that the
+// windows of a tenant are only compacted by one replica at a time. Without it,
+// replicas would merge the same inputs and list duplicate outputs in the
+// metastore. The lease is held by a single replica until it expires, and its
+// holder renews it before each change to the metastore.
+//
+// Leases are only written with conditional writes, and are stored at
+// tenant-<tenant>/compactor/lease as "<owner>\n<expiry in unix nanoseconds>".
+
+// errLeaseHeld is returned when acquiring a lease held by another replica.
+var errLeaseHeld = errors.New("lease is held by another compactor")
+
+func leasePath(tenantID string) string {
+	return fmt.Sprintf("tenant-%s/compactor/lease", tenantID)
+}
+
+// lease is the lease of a tenant, acquired on behalf of owner.
+type lease struct {
+	bucket   objstore.Bucket
+	path     string
+	owner    string
+	duration time.Duration
+	now      func() time.Time
+}
+
+func newLease(bucket objstore.Bucket, tenantID, owner string, duration time.Duration, now func() time.Time) *lease {
+	return &lease{
+		bucket:   bucket,
+		path:     leasePath(tenantID),
+		owner:    owner,
+		duration: duration,
+		now:      now,
+	}
+}
+
+// Acquire acquires the lease, or renews it if it's already held by the owner
+// of l. Acquire returns [errLeaseHeld] if another owner holds an unexpired
+// lease.
+func (l *lease) Acquire(ctx context.Context) error {
+	return l.bucket.GetAndReplace(ctx, l.path, func(existing io.Reader) (io.Reader, error) {
+		now := l.now()
+		if err := l.checkOwner(existing, now); err != nil {
+			return nil, err
+		}
+		return strings.NewReader(formatLease(l.owner, now.Add(l.duration))), nil
+	})
+}
+
+// Release expires the lease if it's held by the owner of l, so that other
+// replicas don't need to wait for it to expire.
+func (l *lease) Release(ctx context.Context) error {
+	return l.bucket.GetAndReplace(ctx, l.path, func(existing io.Reader) (io.Reader, error) {
+		now := l.now()
+		if err := l.checkOwner(existing, now); err != nil {
+			return nil, err
+		}
+		return strings.NewReader(formatLease(l.owner, now)), nil
+	})
+}
+
+// checkOwner returns [errLeaseHeld] if the existing lease is held by another
+// owner at now.
+func (l *lease) checkOwner(existing io.Reader, now time.Time) error {
+	if existing == nil {
+		return nil
+	}
+
+	content, err := io.ReadAll(existing)
+	if err != nil {
+		return fmt.Errorf("reading lease: %w", err)
+	}
+
+	// Unparseable leases are considered to be expired, so that a corrupted
+	// lease can't block compaction forever.
+	owner, expiry, ok := parseLease(string(content))
+	if ok && owner != l.owner && expiry.After(now) {
+		return errLeaseHeld
+	}
+	return nil
+}
+
+func formatLease(owner string, expiry time.Time) string {
+	return owner + "\n" + strconv.FormatInt(expiry.UnixNano(), 10)
+}
+
+func parseLease(content string) (string, time.Time, bool) {
+	owner, expiry, ok := strings.Cut(content, "\n")
+	if !ok {
+		return "", time.Time{}, false
+	}
+
+	nanos, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return owner, time.Unix(0, nanos), true
+}
@@ -0,0 +1,49 @@
+package compactor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	runs             prometheus.Counter
+	failures         prometheus.Counter
+	compactedObjects prometheus.Counter
+	outputObjects    prometheus.Counter
+	deletedObjects   prometheus.Counter
+	expiredObjects   prometheus.Counter
+	rewrittenObjects prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		runs: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_compactor_runs_total",
+			Help: "Total number of compaction runs.",
+		}),
+		failures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_compactor_tenant_failures_total",
+			Help: "Total number of failures compacting the data objects of a tenant.",
+		}),
+		compactedObjects: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_compactor_compacted_objects_total",
+			Help: "Total number of data objects merged by the compactor.",
+		}),
+		outputObjects: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_compactor_output_objects_total",
+			Help: "Total number of data objects written by the compactor.",
+		}),
+		deletedObjects: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_compactor_deleted_objects_total",
+			Help: "Total number of replaced data objects deleted after the delete delay.",
+		}),
+		expiredObjects: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_compactor_expired_objects_total",
+			Help: "Total number of data objects removed because all of their logs were past retention.",
+		}),
+		rewrittenObjects: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_compactor_rewritten_objects_total",
+			Help: "Total number of data objects rewritten to drop logs past retention or matching delete requests.",
+		}),
+	}
+}
@@ -0,0 +1,199 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/util/filter"
+)
+
+// rules decides which logs of a tenant are dropped from its data objects,
+// either because they're past the retention period of their stream or because
+// they match a delete request.
+type rules struct {
+	now       time.Time
+	retention *retention.TenantRetentionSnapshot
+
+	// Shortest and longest retention periods of the tenant. unlimited is set
+	// if the logs of some streams are kept forever.
+	minPeriod, maxPeriod time.Duration
+	unlimited            bool
+
+	deletes []deleteRequest
+
+	// Filters of the streams seen so far, by stream labels. Filters are nil
+	// for streams from which nothing is dropped.
+	filters map[string]filter.Func
+}
+
+type deleteRequest struct {
+	start, end time.Time
+	matchers   []*labels.Matcher
+	pipeline   log.Pipeline // nil if the request has no line filters.
+}
+
+// newRules returns the rules of tenantID at now. Only delete requests older
+// than cancelPeriod are enforced, so that they can still be canceled; until
+// then, they're applied at query time.
+func newRules(ctx context.Context, limits retention.Limits, deletes deletion.DeleteRequestsClient, tenantID string, now time.Time, cancelPeriod time.Duration) (*rules, error) {
+	r := &rules{
+		now:       now,
+		retention: retention.NewTenantRetentionSnapshot(limits, tenantID),
+		filters:   make(map[string]filter.Func),
+	}
+
+	periods := []time.Duration{limits.RetentionPeriod(tenantID)}
+	for _, streamRetention := range limits.StreamRetention(tenantID) {
+		periods = append(periods, time.Duration(streamRetention.Period))
+	}
+	for _, period := range periods {
+		if period <= 0 {
+			r.unlimited = true
+			continue
+		}
+		if r.minPeriod == 0 || period < r.minPeriod {
+			r.minPeriod = period
+		}
+		r.maxPeriod = max(r.maxPeriod, period)
+	}
+
+	requests, err := deletes.GetAllDeleteRequestsForUser(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting delete requests: %w", err)
+	}
+	for _, req := range requests {
+		if req.CreatedAt.Time().Add(cancelPeriod).After(now) {
+			continue
+		}
+
+		expr, err := syntax.ParseLogSelector(req.Query, true)
+		if err != nil {
+			return nil, fmt.Errorf("parsing delete request %s: %w", req.RequestID, err)
+		}
+		d := deleteRequest{
+			start:    req.StartTime.Time(),
+			end:      req.EndTime.Time(),
+			matchers: expr.Matchers(),
+		}
+		if expr.HasFilter() {
+			if d.pipeline, err = expr.Pipeline(); err != nil {
+				return nil, fmt.Errorf("building pipeline of delete request %s: %w", req.RequestID, err)
+			}
+		}
+		r.deletes = append(r.deletes, d)
+	}
+
+	return r, nil
+}
+
+// Expired returns true if all logs of the data object of entry are past the
+// retention period of their stream, regardless of the stream.
+func (r *rules) Expired(entry metastore.Entry) bool {
+	return !r.unlimited && entry.End.Before(r.now.Add(-r.maxPeriod))
+}
+
+// WindowExpired returns true if all logs of the metastore window starting at
+// window are past retention.
+func (r *rules) WindowExpired(window time.Time) bool {
+	return !r.unlimited && window.Add(metastore.WindowSize).Before(r.now.Add(-r.maxPeriod))
+}
+
+// MayDrop returns true if some logs of the data object of entry may be
+// dropped.
+func (r *rules) MayDrop(entry metastore.Entry) bool {
+	if r.minPeriod > 0 && entry.Start.Before(r.now.Add(-r.minPeriod)) {
+		return true
+	}
+	for _, d := range r.deletes {
+		if !d.start.After(entry.End) && !d.end.Before(entry.Start) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns stream without the logs that must be dropped, along with the
+// number of dropped logs.
+func (r *rules) Apply(stream logproto.Stream) (logproto.Stream, int, error) {
+	f, ok := r.filters[stream.Labels]
+	if !ok {
+		lbs, err := syntax.ParseLabels(stream.Labels)
+		if err != nil {
+			return stream, 0, fmt.Errorf("parsing stream labels: %w", err)
+		}
+		f = r.filterFor(lbs)
+		r.filters[stream.Labels] = f
+	}
+	if f == nil {
+		return stream, 0, nil
+	}
+
+	kept := stream.Entries[:0]
+	for _, entry := range stream.Entries {
+		if f(entry.Timestamp, entry.Line, logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata)) {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	dropped := len(stream.Entries) - len(kept)
+	stream.Entries = kept
+	return stream, dropped, nil
+}
+
+// filterFor returns a filter which returns true for the logs of the stream
+// with the given labels which must be dropped. filterFor returns nil if no
+// logs of the stream can be dropped.
+func (r *rules) filterFor(lbs labels.Labels) filter.Func {
+	var filters []filter.Func
+
+	if period := r.retention.RetentionPeriodFor(lbs); period > 0 {
+		cutoff := r.now.Add(-period)
+		filters = append(filters, func(ts time.Time, _ string, _ labels.Labels) bool {
+			return ts.Before(cutoff)
+		})
+	}
+
+	for _, d := range r.deletes {
+		if !labels.Selector(d.matchers).Matches(lbs) {
+			continue
+		}
+
+		var process log.StreamPipeline
+		if d.pipeline != nil {
+			process = d.pipeline.ForStream(lbs)
+		}
+		filters = append(filters, func(ts time.Time, line string, structuredMetadata labels.Labels) bool {
+			if ts.Before(d.start) || ts.After(d.end) {
+				return false
+			} else if process == nil {
+				return true
+			}
+			_, _, matches := process.ProcessString(ts.UnixNano(), line, structuredMetadata)
+			return matches
+		})
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	}
+	return func(ts time.Time, line string, structuredMetadata labels.Labels) bool {
+		for _, f := range filters {
+			if f(ts, line, structuredMetadata) {
+				return true
+			}
+		}
+		return false
+	}
+}
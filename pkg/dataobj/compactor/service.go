@@ -0,0 +1,147 @@
+// Package compactor merges the small data objects written by the dataobj
+// consumer. Low-volume tenants flush many small data objects per metastore
+// window; the compactor merges them into larger, sorted data objects and
+// atomically swaps them in the metastore. When retention is enabled, the
+// compactor also drops logs past retention or matching delete requests from
+// data objects.
+//
+// Multiple replicas of the compactor may run at once: the tenants are shared
+// between replicas through leases stored in the bucket.
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+)
+
+type Service struct {
+	services.Service
+
+	cfg        Config
+	bucket     objstore.Bucket
+	instanceID string
+	limits     retention.Limits
+	deletes    deletion.DeleteRequestsClient
+	logger     log.Logger
+	metrics    *metrics
+
+	builder *dataobj.Builder
+	buf     *bytes.Buffer
+	now     func() time.Time
+}
+
+// New creates a new compactor Service. The instanceID identifies the replica
+// holding the lease of a tenant, and must be unique across replicas. The limits
+// and delete requests of tenants are only used when retention is enabled.
+func New(cfg Config, bucket objstore.Bucket, instanceID string, limits retention.Limits, deletes deletion.DeleteRequestsClient, reg prometheus.Registerer, logger log.Logger) (*Service, error) {
+	builder, err := dataobj.NewBuilder(cfg.BuilderConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		cfg:        cfg,
+		bucket:     bucket,
+		instanceID: instanceID,
+		limits:     limits,
+		deletes:    deletes,
+		logger:     log.With(logger, "component", "dataobj-compactor"),
+		metrics:    newMetrics(reg),
+
+		builder: builder,
+		buf:     bytes.NewBuffer(make([]byte, 0, cfg.TargetObjectSize)),
+		now:     time.Now,
+	}
+	s.Service = services.NewTimerService(cfg.CompactionInterval, nil, s.iterate, nil)
+	return s, nil
+}
+
+// iterate runs a single compaction pass over all tenants. Failures are logged
+// rather than returned, as returning an error would stop the service.
+func (s *Service) iterate(ctx context.Context) error {
+	s.runOnce(ctx)
+	return nil
+}
+
+func (s *Service) runOnce(ctx context.Context) {
+	s.metrics.runs.Inc()
+
+	tenants, err := metastore.Tenants(ctx, s.bucket)
+	if err != nil {
+		level.Error(s.logger).Log("msg", "failed to list tenants", "err", err)
+		return
+	}
+
+	for _, tenantID := range tenants {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Tenants leased by other replicas are skipped; they're compacted by the
+		// holder of their lease.
+		l := newLease(s.bucket, tenantID, s.instanceID, s.cfg.LeaseDuration, s.now)
+		if err := l.Acquire(ctx); errors.Is(err, errLeaseHeld) {
+			level.Debug(s.logger).Log("msg", "skipping tenant leased by another compactor", "tenant", tenantID)
+			continue
+		} else if err != nil {
+			level.Error(s.logger).Log("msg", "failed to acquire tenant lease", "tenant", tenantID, "err", err)
+			s.metrics.failures.Inc()
+			continue
+		}
+
+		s.compactTenant(ctx, tenantID, l)
+
+		if err := l.Release(ctx); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to release tenant lease", "tenant", tenantID, "err", err)
+		}
+	}
+}
+
+// compactTenant deletes, rewrites, and compacts the data objects of tenantID
+// while holding its lease l.
+func (s *Service) compactTenant(ctx context.Context, tenantID string, l *lease) {
+	now := s.now()
+
+	// Deletions happen first so that objects compacted by a previous run
+	// don't pile up when compaction keeps failing.
+	deleted, err := deleteMarked(ctx, s.bucket, tenantID, now)
+	s.metrics.deletedObjects.Add(float64(deleted))
+	if err != nil {
+		level.Error(s.logger).Log("msg", "failed to delete compacted data objects", "tenant", tenantID, "err", err)
+		s.metrics.failures.Inc()
+	}
+
+	var rules *rules
+	if s.cfg.RetentionEnabled {
+		rules, err = newRules(ctx, s.limits, s.deletes, tenantID, now, s.cfg.DeleteRequestCancelPeriod)
+		if err != nil {
+			level.Error(s.logger).Log("msg", "failed to get retention rules", "tenant", tenantID, "err", err)
+			s.metrics.failures.Inc()
+			return
+		}
+	}
+
+	c := newCompactor(s.cfg, s.bucket, tenantID, l, rules, s.builder, s.buf, s.metrics, s.logger)
+	if err := c.ApplyRules(ctx, now); err != nil {
+		level.Error(s.logger).Log("msg", "failed to apply retention to data objects", "tenant", tenantID, "err", err)
+		s.metrics.failures.Inc()
+		return
+	}
+	if err := c.CompactWindows(ctx, now); err != nil {
+		level.Error(s.logger).Log("msg", "failed to compact data objects", "tenant", tenantID, "err", err)
+		s.metrics.failures.Inc()
+	}
+}
@@ -3,13 +3,15 @@ package config
 import (
 	"flag"
 
+	"github.com/grafana/loki/v3/pkg/dataobj/compactor"
 	"github.com/grafana/loki/v3/pkg/dataobj/consumer"
 	"github.com/grafana/loki/v3/pkg/dataobj/querier"
 )
 
 type Config struct {
-	Consumer consumer.Config `yaml:"consumer"`
-	Querier  querier.Config  `yaml:"querier"`
+	Consumer  consumer.Config  `yaml:"consumer"`
+	Querier   querier.Config   `yaml:"querier"`
+	Compactor compactor.Config `yaml:"compactor"`
 	// StorageBucketPrefix is the prefix to use for the storage bucket.
 	StorageBucketPrefix string `yaml:"storage_bucket_prefix"`
 }
@@ -17,6 +19,7 @@ type Config struct {
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Consumer.RegisterFlags(f)
 	cfg.Querier.RegisterFlags(f)
+	cfg.Compactor.RegisterFlags(f)
 	f.StringVar(&cfg.StorageBucketPrefix, "dataobj-storage-bucket-prefix", "dataobj/", "The prefix to use for the storage bucket.")
 }
 
@@ -27,5 +30,8 @@ func (cfg *Config) Validate() error {
 	if err := cfg.Querier.Validate(); err != nil {
 		return err
 	}
+	if err := cfg.Compactor.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,122 @@
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+)
+
+// To make the write path exactly-once, each partition processor keeps a
+// checkpoint in object storage recording the last Kafka offset whose records
+// are listed in the metastore. Kafka offsets are only committed after the
+// checkpoint is updated, and records at or below the checkpointed offset are
+// skipped when they're consumed again after a restart.
+//
+// Before uploading a data object, the processor records the object as
+// pending in the checkpoint. If the processor stops before the object is
+// listed in the metastore, the next owner of the partition completes or
+// garbage-collects the pending object instead of building a duplicate one.
+//
+// A partition may briefly be owned by two processors while it's reassigned.
+// Each processor takes over the checkpoint by incrementing its epoch, and
+// checkpoints are only written if their epoch is unchanged, so a previous
+// owner can't overwrite the checkpoint of the next one.
+//
+// Checkpoints are stored at tenant-<tenant>/consumer/checkpoints/<topic>/<partition>.
+
+func checkpointPath(tenantID, topic string, partition int32) string {
+	return fmt.Sprintf("tenant-%s/consumer/checkpoints/%s/%d", tenantID, topic, partition)
+}
+
+// checkpoint is the state of a partition processor kept in object storage.
+type checkpoint struct {
+	// Epoch is incremented by each processor taking over the checkpoint.
+	Epoch int64 `json:"epoch"`
+
+	// CommittedOffset is the offset of the last record listed in the
+	// metastore, or -1 if no records have been listed yet.
+	CommittedOffset int64 `json:"committed_offset"`
+
+	// Pending is the data object being uploaded, if any.
+	Pending *pendingObject `json:"pending,omitempty"`
+}
+
+// pendingObject is a data object which may have been uploaded but isn't
+// known to be listed in the metastore yet.
+type pendingObject struct {
+	Path  string             `json:"path"`
+	Stats dataobj.FlushStats `json:"stats"`
+}
+
+// errFenced is returned when writing a checkpoint which was taken over by
+// another processor.
+var errFenced = errors.New("checkpoint was taken over by another processor")
+
+// takeOverCheckpoint reads the checkpoint at path and increments its epoch,
+// so that writes of previous owners of the checkpoint fail from then on.
+// takeOverCheckpoint returns the updated checkpoint.
+func takeOverCheckpoint(ctx context.Context, bucket objstore.Bucket, path string) (checkpoint, error) {
+	var cp checkpoint
+	err := bucket.GetAndReplace(ctx, path, func(existing io.Reader) (io.Reader, error) {
+		cp = checkpoint{CommittedOffset: -1}
+		if existing != nil {
+			var err error
+			if cp, err = decodeCheckpoint(existing); err != nil {
+				return nil, err
+			}
+		}
+		cp.Epoch++
+		return encodeCheckpoint(cp)
+	})
+	if err != nil {
+		return checkpoint{}, err
+	}
+	return cp, nil
+}
+
+// writeCheckpoint replaces the checkpoint at path with cp. writeCheckpoint
+// returns [errFenced] if the existing checkpoint doesn't have the epoch of cp.
+// A checkpoint which doesn't exist has epoch 0.
+func writeCheckpoint(ctx context.Context, bucket objstore.Bucket, path string, cp checkpoint) error {
+	return bucket.GetAndReplace(ctx, path, func(existing io.Reader) (io.Reader, error) {
+		var prev checkpoint
+		if existing != nil {
+			var err error
+			if prev, err = decodeCheckpoint(existing); err != nil {
+				return nil, err
+			}
+		}
+		if prev.Epoch != cp.Epoch {
+			return nil, errFenced
+		}
+		return encodeCheckpoint(cp)
+	})
+}
+
+func decodeCheckpoint(r io.Reader) (checkpoint, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return checkpoint{}, err
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(content, &cp); err != nil {
+		return checkpoint{}, fmt.Errorf("decoding checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+func encodeCheckpoint(cp checkpoint) (io.Reader, error) {
+	content, err := json.Marshal(cp)
+	if err != nil {
+		return nil, fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	return bytes.NewReader(content), nil
+}
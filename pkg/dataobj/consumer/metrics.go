@@ -16,6 +16,11 @@ type partitionOffsetMetrics struct {
 	commitFailures prometheus.Counter
 	appendFailures prometheus.Counter
 
+	// Exactly-once counters
+	skippedRecords   prometheus.Counter
+	recoveredObjects prometheus.Counter
+	orphanedObjects  prometheus.Counter
+
 	// Processing delay histogram
 	processingDelay prometheus.Histogram
 }
@@ -30,6 +35,18 @@ func newPartitionOffsetMetrics() *partitionOffsetMetrics {
 			Name: "loki_dataobj_consumer_append_failures_total",
 			Help: "Total number of append failures",
 		}),
+		skippedRecords: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_consumer_skipped_records_total",
+			Help: "Total number of records skipped because they were already stored in a data object",
+		}),
+		recoveredObjects: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_consumer_recovered_objects_total",
+			Help: "Total number of data objects uploaded by a previous owner of the partition which were added to the metastore",
+		}),
+		orphanedObjects: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "loki_dataobj_consumer_orphaned_objects_total",
+			Help: "Total number of orphaned data objects which were deleted",
+		}),
 		processingDelay: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Name:                            "loki_dataobj_consumer_processing_delay_seconds",
 			Help:                            "Time difference between record timestamp and processing time in seconds",
@@ -59,6 +76,9 @@ func (p *partitionOffsetMetrics) register(reg prometheus.Registerer) error {
 	collectors := []prometheus.Collector{
 		p.commitFailures,
 		p.appendFailures,
+		p.skippedRecords,
+		p.recoveredObjects,
+		p.orphanedObjects,
 		p.currentOffset,
 		p.processingDelay,
 	}
@@ -77,6 +97,9 @@ func (p *partitionOffsetMetrics) unregister(reg prometheus.Registerer) {
 	collectors := []prometheus.Collector{
 		p.commitFailures,
 		p.appendFailures,
+		p.skippedRecords,
+		p.recoveredObjects,
+		p.orphanedObjects,
 		p.currentOffset,
 		p.processingDelay,
 	}
@@ -98,6 +121,18 @@ func (p *partitionOffsetMetrics) incAppendFailures() {
 	p.appendFailures.Inc()
 }
 
+func (p *partitionOffsetMetrics) incSkippedRecords() {
+	p.skippedRecords.Inc()
+}
+
+func (p *partitionOffsetMetrics) incRecoveredObjects() {
+	p.recoveredObjects.Inc()
+}
+
+func (p *partitionOffsetMetrics) incOrphanedObjects() {
+	p.orphanedObjects.Inc()
+}
+
 func (p *partitionOffsetMetrics) observeProcessingDelay(recordTimestamp time.Time) {
 	// Convert milliseconds to seconds and calculate delay
 	if !recordTimestamp.IsZero() { // Only observe if timestamp is valid
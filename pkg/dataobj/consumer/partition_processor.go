@@ -3,6 +3,8 @@ package consumer
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
 	"sync"
 	"time"
@@ -20,12 +22,27 @@ import (
 	"github.com/grafana/loki/v3/pkg/kafka"
 )
 
+// committer commits the offsets of consumed records to Kafka.
+type committer interface {
+	CommitRecords(ctx context.Context, rs ...*kgo.Record) error
+}
+
 type partitionProcessor struct {
 	// Kafka client and topic/partition info
-	client    *kgo.Client
+	client    committer
 	topic     string
 	partition int32
 	tenantID  []byte
+
+	// Exactly-once state. epoch is the epoch of the checkpoint taken over by
+	// the processor, committedOffset is the offset of the last record listed
+	// in the metastore, and firstOffset and lastOffset are the offsets of the
+	// records buffered in the builder, or -1 if it's empty.
+	checkpointPath          string
+	epoch                   int64
+	committedOffset         int64
+	firstOffset, lastOffset int64
+
 	// Processing pipeline
 	records          chan *kgo.Record
 	builder          *dataobj.Builder
@@ -57,7 +74,7 @@ type partitionProcessor struct {
 
 func newPartitionProcessor(
 	ctx context.Context,
-	client *kgo.Client,
+	client committer,
 	builderCfg dataobj.BuilderConfig,
 	uploaderCfg uploader.Config,
 	bucket objstore.Bucket,
@@ -113,6 +130,10 @@ func newPartitionProcessor(
 		metrics:          metrics,
 		uploader:         uploader,
 		metastoreUpdater: metastoreUpdater,
+		checkpointPath:   checkpointPath(tenantID, topic, partition),
+		committedOffset:  -1,
+		firstOffset:      -1,
+		lastOffset:       -1,
 		bufPool:          bufPool,
 		idleFlushTimeout: idleFlushTimeout,
 		lastFlush:        time.Now(),
@@ -126,6 +147,11 @@ func (p *partitionProcessor) start() {
 		defer p.wg.Done()
 
 		level.Info(p.logger).Log("msg", "started partition processor")
+		if err := p.recoverCheckpoint(); err != nil {
+			level.Info(p.logger).Log("msg", "stopping partition processor before recovery", "err", err)
+			return
+		}
+
 		for {
 			select {
 			case <-p.ctx.Done():
@@ -188,26 +214,236 @@ func (p *partitionProcessor) initBuilder() error {
 	return initErr
 }
 
+// recoverCheckpoint restores the state of the partition from its checkpoint,
+// retrying until it succeeds or the processor is stopped. A pending data
+// object left behind by a previous owner of the partition is completed or
+// garbage-collected, and the committed offset is committed to Kafka.
+func (p *partitionProcessor) recoverCheckpoint() error {
+	backoff := backoff.New(p.ctx, backoff.Config{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	})
+
+	var cp checkpoint
+	for backoff.Ongoing() {
+		var err error
+		if cp, err = p.resolveCheckpoint(); err == nil {
+			break
+		} else if errors.Is(err, errFenced) {
+			// Another processor took over the partition while recovering.
+			p.cancel()
+			return err
+		}
+		level.Error(p.logger).Log("msg", "failed to recover checkpoint", "err", err)
+		backoff.Wait()
+	}
+	if err := backoff.Err(); err != nil {
+		return err
+	}
+
+	p.committedOffset = cp.CommittedOffset
+	if p.committedOffset >= 0 {
+		level.Info(p.logger).Log("msg", "recovered checkpoint", "committed_offset", p.committedOffset)
+
+		// Committing to Kafka is best effort: records consumed again are
+		// skipped based on the checkpoint.
+		if err := p.commitOffset(p.committedOffset); err != nil {
+			level.Error(p.logger).Log("msg", "failed to commit records", "err", err)
+		}
+	}
+	return nil
+}
+
+// resolveCheckpoint takes over the checkpoint of the partition and resolves
+// its pending data object, if any. resolveCheckpoint returns the checkpoint
+// without a pending object.
+func (p *partitionProcessor) resolveCheckpoint() (checkpoint, error) {
+	cp, err := takeOverCheckpoint(p.ctx, p.bucket, p.checkpointPath)
+	if err != nil {
+		return checkpoint{}, fmt.Errorf("taking over checkpoint: %w", err)
+	}
+	p.epoch = cp.Epoch
+	if cp.Pending == nil {
+		return cp, nil
+	}
+
+	committedOffset, err := p.resolvePending(cp)
+	if err != nil {
+		return checkpoint{}, err
+	}
+
+	cp = checkpoint{Epoch: p.epoch, CommittedOffset: committedOffset}
+	if err := writeCheckpoint(p.ctx, p.bucket, p.checkpointPath, cp); err != nil {
+		return checkpoint{}, fmt.Errorf("writing checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// resolvePending completes or garbage-collects the pending data object of cp,
+// and returns the resulting committed offset.
+//
+// A pending object which was uploaded and holds the records following the
+// committed offset is listed in the metastore so that its records aren't
+// stored twice. Any other uploaded pending object is an orphan: it's removed
+// from the metastore and deleted.
+//
+// A pending object which no longer exists was either never uploaded, or was
+// listed and then replaced by the compactor. The metastore tells both apart,
+// as it keeps the Kafka records of replaced data objects.
+func (p *partitionProcessor) resolvePending(cp checkpoint) (int64, error) {
+	pending := cp.Pending
+	expected := pending.Stats.KafkaOffsets
+
+	exists, err := p.bucket.Exists(p.ctx, pending.Path)
+	if err != nil {
+		return 0, fmt.Errorf("checking pending object %s: %w", pending.Path, err)
+	} else if !exists {
+		stored, err := p.storedInMetastore(pending)
+		if err != nil {
+			return 0, fmt.Errorf("looking up pending object %s: %w", pending.Path, err)
+		} else if stored {
+			level.Info(p.logger).Log("msg", "completing pending object which was already replaced", "path", pending.Path, "min_offset", expected.MinOffset, "max_offset", expected.MaxOffset)
+			p.metrics.incRecoveredObjects()
+			return max(cp.CommittedOffset, expected.MaxOffset), nil
+		}
+
+		// The object was never uploaded; its records are consumed again.
+		level.Info(p.logger).Log("msg", "discarding pending object which was never uploaded", "path", pending.Path)
+		return cp.CommittedOffset, nil
+	}
+
+	offsets, err := dataobj.FromBucket(p.bucket, pending.Path).KafkaOffsets(p.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("reading pending object %s: %w", pending.Path, err)
+	}
+
+	if offsets != nil && expected != nil && *offsets == *expected && offsets.MinOffset > cp.CommittedOffset {
+		level.Info(p.logger).Log("msg", "completing pending object", "path", pending.Path, "min_offset", offsets.MinOffset, "max_offset", offsets.MaxOffset)
+		if err := p.metastoreUpdater.Update(p.ctx, pending.Path, pending.Stats); err != nil {
+			return 0, fmt.Errorf("updating metastore: %w", err)
+		}
+		p.metrics.incRecoveredObjects()
+		return offsets.MaxOffset, nil
+	}
+
+	level.Warn(p.logger).Log("msg", "deleting orphaned object", "path", pending.Path)
+	minWindow := pending.Stats.MinTimestamp.Truncate(metastore.WindowSize)
+	for window := minWindow; !window.After(pending.Stats.MaxTimestamp); window = window.Add(metastore.WindowSize) {
+		if err := p.metastoreUpdater.Replace(p.ctx, window, []string{pending.Path}, nil); err != nil {
+			return 0, fmt.Errorf("removing orphaned object from metastore: %w", err)
+		}
+	}
+	if err := p.bucket.Delete(p.ctx, pending.Path); err != nil && !p.bucket.IsObjNotFoundErr(err) {
+		return 0, fmt.Errorf("deleting orphaned object %s: %w", pending.Path, err)
+	}
+	p.metrics.incOrphanedObjects()
+	return cp.CommittedOffset, nil
+}
+
+// storedInMetastore returns true if the records of the pending object are
+// stored in a data object listed in the metastore.
+func (p *partitionProcessor) storedInMetastore(pending *pendingObject) (bool, error) {
+	if pending.Stats.KafkaOffsets == nil {
+		return false, nil
+	}
+
+	minWindow := pending.Stats.MinTimestamp.Truncate(metastore.WindowSize)
+	for window := minWindow; !window.After(pending.Stats.MaxTimestamp); window = window.Add(metastore.WindowSize) {
+		entries, err := metastore.Entries(p.ctx, p.bucket, string(p.tenantID), window)
+		if err != nil {
+			return false, err
+		}
+		for _, entry := range entries {
+			if entry.Path == pending.Path || entry.HoldsKafkaRecords(*pending.Stats.KafkaOffsets) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 func (p *partitionProcessor) flushStream(flushBuffer *bytes.Buffer) error {
+	p.builder.SetKafkaOffsets(dataobj.KafkaOffsets{
+		Topic:     p.topic,
+		Partition: p.partition,
+		MinOffset: p.firstOffset,
+		MaxOffset: p.lastOffset,
+	})
+
 	flushedDataobjStats, err := p.builder.Flush(flushBuffer)
 	if err != nil {
 		level.Error(p.logger).Log("msg", "failed to flush builder", "err", err)
 		return err
 	}
+	p.firstOffset, p.lastOffset = -1, -1
+
+	pending := &pendingObject{
+		Path:  p.uploader.ObjectPath(flushBuffer),
+		Stats: flushedDataobjStats,
+	}
 
-	objectPath, err := p.uploader.Upload(p.ctx, flushBuffer)
+	// The builder was reset, so the flushed records would be lost if storing
+	// the object failed. Retry until it's stored or the processor is stopped;
+	// the checkpoint covers the object from here on.
+	backoff := backoff.New(p.ctx, backoff.Config{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	})
+	for backoff.Ongoing() {
+		if err = p.storeObject(flushBuffer, pending); err == nil {
+			break
+		} else if errors.Is(err, errFenced) {
+			// The partition was reassigned; its new owner consumes the records
+			// which weren't committed yet.
+			level.Warn(p.logger).Log("msg", "stopping partition processor taken over by another processor")
+			p.cancel()
+			return err
+		}
+		level.Error(p.logger).Log("msg", "failed to store object", "err", err)
+		backoff.Wait()
+	}
 	if err != nil {
-		level.Error(p.logger).Log("msg", "failed to upload object", "err", err)
 		return err
 	}
 
-	if err := p.metastoreUpdater.Update(p.ctx, objectPath, flushedDataobjStats); err != nil {
-		level.Error(p.logger).Log("msg", "failed to update metastore", "err", err)
+	p.lastFlush = time.Now()
+
+	return nil
+}
+
+// storeObject uploads the flushed object and lists it in the metastore. The
+// object is recorded as pending in the checkpoint until it's listed, after
+// which the checkpoint and Kafka offsets are advanced past its records.
+func (p *partitionProcessor) storeObject(object *bytes.Buffer, pending *pendingObject) error {
+	err := writeCheckpoint(p.ctx, p.bucket, p.checkpointPath, checkpoint{
+		Epoch:           p.epoch,
+		CommittedOffset: p.committedOffset,
+		Pending:         pending,
+	})
+	if err != nil {
+		return fmt.Errorf("writing pending checkpoint: %w", err)
+	}
+
+	objectPath, err := p.uploader.Upload(p.ctx, object)
+	if err != nil {
 		return err
 	}
 
-	p.lastFlush = time.Now()
+	if err := p.metastoreUpdater.Update(p.ctx, objectPath, pending.Stats); err != nil {
+		return fmt.Errorf("updating metastore: %w", err)
+	}
+
+	committedOffset := pending.Stats.KafkaOffsets.MaxOffset
+	if err := writeCheckpoint(p.ctx, p.bucket, p.checkpointPath, checkpoint{Epoch: p.epoch, CommittedOffset: committedOffset}); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	p.committedOffset = committedOffset
 
+	// Committing to Kafka is best effort: records consumed again are skipped
+	// based on the checkpoint.
+	if err := p.commitOffset(committedOffset); err != nil {
+		level.Error(p.logger).Log("msg", "failed to commit records", "err", err)
+	}
 	return nil
 }
 
@@ -215,6 +451,14 @@ func (p *partitionProcessor) processRecord(record *kgo.Record) {
 	// Update offset metric at the end of processing
 	defer p.metrics.updateOffset(record.Offset)
 
+	// Records up to the committed offset are already stored in data objects,
+	// but are consumed again if the processor stopped before committing them
+	// to Kafka.
+	if record.Offset <= p.committedOffset {
+		p.metrics.incSkippedRecords()
+		return
+	}
+
 	// Observe processing delay
 	p.metrics.observeProcessingDelay(record.Timestamp)
 
@@ -242,32 +486,44 @@ func (p *partitionProcessor) processRecord(record *kgo.Record) {
 			return
 		}
 
-		func() {
+		err := func() error {
 			flushBuffer := p.bufPool.Get().(*bytes.Buffer)
 			defer p.bufPool.Put(flushBuffer)
 
 			flushBuffer.Reset()
 
-			if err := p.flushStream(flushBuffer); err != nil {
-				level.Error(p.logger).Log("msg", "failed to flush stream", "err", err)
-				return
-			}
+			return p.flushStream(flushBuffer)
 		}()
-
-		if err := p.commitRecords(record); err != nil {
-			level.Error(p.logger).Log("msg", "failed to commit records", "err", err)
+		if err != nil {
+			level.Error(p.logger).Log("msg", "failed to flush stream", "err", err)
 			return
 		}
 
 		if err := p.builder.Append(stream); err != nil {
 			level.Error(p.logger).Log("msg", "failed to append stream after flushing", "err", err)
 			p.metrics.incAppendFailures()
+			return
 		}
 	}
 
+	if p.firstOffset < 0 {
+		p.firstOffset = record.Offset
+	}
+	p.lastOffset = record.Offset
 	p.lastModified = time.Now()
 }
 
+// commitOffset commits the record at offset to Kafka, so that consumption
+// resumes after it.
+func (p *partitionProcessor) commitOffset(offset int64) error {
+	return p.commitRecords(&kgo.Record{
+		Topic:       p.topic,
+		Partition:   p.partition,
+		Offset:      offset,
+		LeaderEpoch: -1,
+	})
+}
+
 func (p *partitionProcessor) commitRecords(record *kgo.Record) error {
 	backoff := backoff.New(p.ctx, backoff.Config{
 		MinBackoff: 100 * time.Millisecond,
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -17,6 +18,7 @@ import (
 	"github.com/twmb/franz-go/pkg/kgo"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
 	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
 	"github.com/grafana/loki/v3/pkg/logproto"
 
@@ -72,8 +74,16 @@ func (m *mockBucket) Name() string { return "mock" }
 func (m *mockBucket) Attributes(_ context.Context, _ string) (objstore.ObjectAttributes, error) {
 	return objstore.ObjectAttributes{}, nil
 }
-func (m *mockBucket) GetAndReplace(_ context.Context, name string, _ func(io.Reader) (io.Reader, error)) error {
-	return m.Upload(context.Background(), name, bytes.NewReader([]byte{}))
+func (m *mockBucket) GetAndReplace(ctx context.Context, name string, f func(io.Reader) (io.Reader, error)) error {
+	var existing io.Reader
+	if rc, err := m.Get(ctx, name); err == nil {
+		existing = rc
+	}
+	r, err := f(existing)
+	if err != nil {
+		return err
+	}
+	return m.Upload(ctx, name, r)
 }
 func (m *mockBucket) IsAccessDeniedErr(_ error) bool {
 	return false
@@ -91,6 +101,27 @@ func (m *mockBucket) SupportedIterOptions() []objstore.IterOptionType {
 	return nil
 }
 
+// mockCommitter records the offsets committed to Kafka.
+type mockCommitter struct {
+	mu      sync.Mutex
+	offsets []int64
+}
+
+func (m *mockCommitter) CommitRecords(_ context.Context, rs ...*kgo.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range rs {
+		m.offsets = append(m.offsets, r.Offset)
+	}
+	return nil
+}
+
+func (m *mockCommitter) committed() []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.offsets)
+}
+
 var testBuilderConfig = dataobj.BuilderConfig{
 	TargetPageSize:    2048,
 	TargetObjectSize:  4096,
@@ -154,7 +185,7 @@ func TestIdleFlush(t *testing.T) {
 			// Create processor with test configuration
 			p := newPartitionProcessor(
 				context.Background(),
-				&kgo.Client{},
+				&mockCommitter{},
 				testBuilderConfig,
 				uploader.Config{},
 				bucket,
@@ -223,7 +254,7 @@ func TestIdleFlushWithActiveProcessing(t *testing.T) {
 
 	p := newPartitionProcessor(
 		context.Background(),
-		&kgo.Client{},
+		&mockCommitter{},
 		testBuilderConfig,
 		uploader.Config{},
 		bucket,
@@ -263,11 +294,11 @@ func TestIdleFlushWithActiveProcessing(t *testing.T) {
 	// Record initial flush time
 	initialFlushTime := p.lastFlush
 
-	// Wait longer than idle timeout
-	time.Sleep(300 * time.Millisecond)
-
-	// Verify that idle flush occurred
-	require.True(t, p.lastFlush.After(initialFlushTime), "expected idle flush to occur while processor is running")
+	// Wait longer than idle timeout; the flush stores the object and
+	// checkpoints before it completes.
+	require.Eventually(t, func() bool {
+		return p.lastFlush.After(initialFlushTime)
+	}, time.Second, 10*time.Millisecond, "expected idle flush to occur while processor is running")
 }
 
 // TestIdleFlushWithEmptyData tests the idle flush behavior
@@ -284,7 +315,7 @@ func TestIdleFlushWithEmptyData(t *testing.T) {
 
 	p := newPartitionProcessor(
 		context.Background(),
-		&kgo.Client{},
+		&mockCommitter{},
 		testBuilderConfig,
 		uploader.Config{},
 		bucket,
@@ -313,3 +344,267 @@ func TestIdleFlushWithEmptyData(t *testing.T) {
 	// Verify that idle flush occurred
 	require.True(t, p.lastFlush.Equal(initialFlushTime), "expected no idle flush with empty data")
 }
+
+func newTestProcessor(t *testing.T, bucket objstore.Bucket, client committer) *partitionProcessor {
+	t.Helper()
+
+	p := newPartitionProcessor(
+		context.Background(),
+		client,
+		testBuilderConfig,
+		uploader.Config{SHAPrefixSize: 2},
+		bucket,
+		"test-tenant",
+		0,
+		"test-topic",
+		0,
+		log.NewNopLogger(),
+		prometheus.NewRegistry(),
+		&sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 1024))
+			},
+		},
+		time.Hour,
+	)
+	require.NoError(t, p.initBuilder())
+	return p
+}
+
+func testRecord(t *testing.T, offset int64) *kgo.Record {
+	t.Helper()
+
+	stream := logproto.Stream{
+		Labels: `{cluster="test",app="foo"}`,
+		Entries: []push.Entry{{
+			Timestamp: time.Unix(offset, 0).UTC(),
+			Line:      strings.Repeat("a", 1024),
+		}},
+	}
+	streamBytes, err := stream.Marshal()
+	require.NoError(t, err)
+
+	return &kgo.Record{
+		Topic:     "test-topic",
+		Partition: 0,
+		Offset:    offset,
+		Key:       []byte("test-tenant"),
+		Value:     streamBytes,
+	}
+}
+
+// dataObjects returns the paths of the data objects in bucket.
+func dataObjects(bucket *objstore.InMemBucket) []string {
+	var paths []string
+	for path := range bucket.Objects() {
+		if strings.HasPrefix(path, "tenant-test-tenant/objects/") {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// TestExactlyOnce tests that records consumed again after a restart aren't
+// stored twice.
+func TestExactlyOnce(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	client := &mockCommitter{}
+
+	p := newTestProcessor(t, bucket, client)
+	require.NoError(t, p.recoverCheckpoint())
+	for offset := range int64(10) {
+		p.processRecord(testRecord(t, offset))
+	}
+
+	// The builder flushed when it was full; the remaining records are still
+	// buffered.
+	objects := dataObjects(bucket)
+	require.NotEmpty(t, objects)
+	require.Greater(t, p.committedOffset, int64(0))
+	require.Less(t, p.committedOffset, int64(9))
+	require.Equal(t, p.committedOffset, client.committed()[len(client.committed())-1])
+
+	var nextOffset int64
+	for _, path := range objects {
+		offsets, err := dataobj.FromBucket(bucket, path).KafkaOffsets(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, offsets)
+		require.Equal(t, "test-topic", offsets.Topic)
+		require.GreaterOrEqual(t, offsets.MaxOffset, offsets.MinOffset)
+		nextOffset = max(nextOffset, offsets.MaxOffset+1)
+	}
+	require.Equal(t, p.committedOffset+1, nextOffset)
+
+	cp, err := readCheckpoint(ctx, bucket, p.checkpointPath)
+	require.NoError(t, err)
+	require.Equal(t, checkpoint{Epoch: p.epoch, CommittedOffset: p.committedOffset}, cp)
+
+	// Restart without committing the buffered records; all records are
+	// consumed again.
+	committedOffset := p.committedOffset
+	client = &mockCommitter{}
+
+	p = newTestProcessor(t, bucket, client)
+	require.NoError(t, p.recoverCheckpoint())
+	require.Equal(t, committedOffset, p.committedOffset)
+	require.Equal(t, []int64{committedOffset}, client.committed())
+
+	for offset := range int64(10) {
+		p.processRecord(testRecord(t, offset))
+	}
+	require.Equal(t, objects, dataObjects(bucket), "already stored records must be skipped")
+	require.Equal(t, committedOffset+1, p.firstOffset)
+	require.Equal(t, int64(9), p.lastOffset)
+}
+
+// TestRecoverCheckpoint tests that data objects left pending by a previous
+// owner of the partition are completed or garbage-collected.
+func TestRecoverCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		committedOffset int64
+		pendingOffsets  dataobj.KafkaOffsets
+		upload          bool
+		compacted       bool
+
+		expectCommitted int64
+		expectObject    bool
+		expectListed    bool
+	}{
+		{
+			name:            "pending object is completed",
+			committedOffset: 9,
+			pendingOffsets:  dataobj.KafkaOffsets{Topic: "test-topic", MinOffset: 10, MaxOffset: 20},
+			upload:          true,
+			expectCommitted: 20,
+			expectObject:    true,
+			expectListed:    true,
+		},
+		{
+			name:            "pending object which was never uploaded is dropped",
+			committedOffset: 9,
+			pendingOffsets:  dataobj.KafkaOffsets{Topic: "test-topic", MinOffset: 10, MaxOffset: 20},
+			upload:          false,
+			expectCommitted: 9,
+		},
+		{
+			name:            "pending object which was compacted is completed",
+			committedOffset: 9,
+			pendingOffsets:  dataobj.KafkaOffsets{Topic: "test-topic", MinOffset: 10, MaxOffset: 20},
+			compacted:       true,
+			expectCommitted: 20,
+		},
+		{
+			name:            "superseded pending object is deleted",
+			committedOffset: 15,
+			pendingOffsets:  dataobj.KafkaOffsets{Topic: "test-topic", MinOffset: 10, MaxOffset: 20},
+			upload:          true,
+			expectCommitted: 15,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bucket := objstore.NewInMemBucket()
+			client := &mockCommitter{}
+			p := newTestProcessor(t, bucket, client)
+
+			builder, err := dataobj.NewBuilder(testBuilderConfig)
+			require.NoError(t, err)
+			require.NoError(t, builder.Append(logproto.Stream{
+				Labels:  `{cluster="test",app="foo"}`,
+				Entries: []push.Entry{{Timestamp: now, Line: "hello"}},
+			}))
+			builder.SetKafkaOffsets(tc.pendingOffsets)
+
+			var buf bytes.Buffer
+			stats, err := builder.Flush(&buf)
+			require.NoError(t, err)
+
+			path := p.uploader.ObjectPath(&buf)
+			if tc.upload {
+				require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(buf.Bytes())))
+			}
+			if tc.compacted {
+				// The object was listed and then merged into another one before
+				// the checkpoint was updated.
+				updater := metastore.NewUpdater(bucket, "test-tenant", log.NewNopLogger())
+				require.NoError(t, updater.Update(ctx, path, stats))
+				require.NoError(t, updater.Replace(ctx, now, []string{path}, map[string]dataobj.FlushStats{
+					"merged": {MinTimestamp: now, MaxTimestamp: now},
+				}))
+			}
+			require.NoError(t, writeCheckpoint(ctx, bucket, p.checkpointPath, checkpoint{
+				CommittedOffset: tc.committedOffset,
+				Pending:         &pendingObject{Path: path, Stats: stats},
+			}))
+
+			require.NoError(t, p.recoverCheckpoint())
+			require.Equal(t, tc.expectCommitted, p.committedOffset)
+			require.Equal(t, []int64{tc.expectCommitted}, client.committed())
+
+			cp, err := readCheckpoint(ctx, bucket, p.checkpointPath)
+			require.NoError(t, err)
+			require.Equal(t, checkpoint{Epoch: 1, CommittedOffset: tc.expectCommitted}, cp)
+
+			exists, err := bucket.Exists(ctx, path)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectObject, exists)
+
+			entries, err := metastore.Entries(ctx, bucket, "test-tenant", now)
+			require.NoError(t, err)
+			switch {
+			case tc.compacted:
+				require.Len(t, entries, 1)
+				require.Equal(t, "merged", entries[0].Path)
+			case tc.expectListed:
+				require.Len(t, entries, 1)
+				require.Equal(t, path, entries[0].Path)
+				require.Equal(t, &tc.pendingOffsets, entries[0].KafkaOffsets)
+			default:
+				require.Empty(t, entries)
+			}
+		})
+	}
+}
+
+// TestCheckpointTakeOver tests that a processor stops storing data objects
+// once its partition was taken over by another processor.
+func TestCheckpointTakeOver(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+
+	prev := newTestProcessor(t, bucket, &mockCommitter{})
+	require.NoError(t, prev.recoverCheckpoint())
+
+	next := newTestProcessor(t, bucket, &mockCommitter{})
+	require.NoError(t, next.recoverCheckpoint())
+	require.Greater(t, next.epoch, prev.epoch)
+
+	for offset := range int64(10) {
+		prev.processRecord(testRecord(t, offset))
+	}
+	require.Empty(t, dataObjects(bucket))
+	require.ErrorIs(t, prev.ctx.Err(), context.Canceled)
+
+	cp, err := readCheckpoint(ctx, bucket, next.checkpointPath)
+	require.NoError(t, err)
+	require.Equal(t, checkpoint{Epoch: next.epoch, CommittedOffset: -1}, cp)
+}
+
+// readCheckpoint reads the checkpoint at path. readCheckpoint returns an
+// empty checkpoint if none exists.
+func readCheckpoint(ctx context.Context, bucket objstore.Bucket, path string) (checkpoint, error) {
+	rc, err := bucket.Get(ctx, path)
+	if bucket.IsObjNotFoundErr(err) {
+		return checkpoint{CommittedOffset: -1}, nil
+	} else if err != nil {
+		return checkpoint{}, err
+	}
+	defer rc.Close()
+	return decodeCheckpoint(rc)
+}
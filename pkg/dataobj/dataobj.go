@@ -34,6 +34,25 @@ type Metadata struct {
 	LogsSections    int // Number of logs sections in the Object.
 }
 
+// KafkaOffsets returns the Kafka records the Object was built from. The
+// returned offsets are nil if the Object doesn't record them, such as for
+// objects not built by the dataobj consumer. KafkaOffsets returns an error if
+// the object cannot be read.
+func (o *Object) KafkaOffsets(ctx context.Context) (*KafkaOffsets, error) {
+	offsets, err := o.dec.KafkaOffsets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata: %w", err)
+	} else if offsets == nil {
+		return nil, nil
+	}
+	return &KafkaOffsets{
+		Topic:     offsets.Topic,
+		Partition: offsets.Partition,
+		MinOffset: offsets.MinOffset,
+		MaxOffset: offsets.MaxOffset,
+	}, nil
+}
+
 // Metadata returns the metadata of the Object. Metadata returns an error if
 // the object cannot be read.
 func (o *Object) Metadata(ctx context.Context) (Metadata, error) {
@@ -0,0 +1,553 @@
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/streamsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/logs"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/streams"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+const (
+	defaultRowsLimit = 100
+	maxRowsLimit     = 1000
+)
+
+// SectionRows is a page of decoded rows from a single section of a data
+// object. Pages are requested by the index of the row to start from; Next is
+// the index to request the following page with, if HasMore is set.
+type SectionRows struct {
+	Type    string        `json:"type"`
+	Columns []RowsColumn  `json:"columns"`
+	Rows    []SectionRow  `json:"rows"`
+	Start   int           `json:"start"`
+	Next    int           `json:"next,omitempty"`
+	Limit   int           `json:"limit"`
+	HasMore bool          `json:"hasMore"`
+	Ranges  []RowRangeDTO `json:"ranges"`
+
+	TotalRows   int `json:"totalRows"`
+	PagesTotal  int `json:"pagesTotal"`
+	PagesPruned int `json:"pagesPruned"`
+}
+
+// RowsColumn describes a column of a [SectionRows] response, including which
+// of its pages were pruned by the predicate.
+type RowsColumn struct {
+	Name      string          `json:"name,omitempty"`
+	Type      string          `json:"type"`
+	ValueType string          `json:"value_type"`
+	Pages     []PagePruneInfo `json:"pages"`
+}
+
+// PagePruneInfo reports whether a page was skipped while reading rows.
+type PagePruneInfo struct {
+	FirstRow uint64 `json:"first_row"`
+	LastRow  uint64 `json:"last_row"`
+	Pruned   bool   `json:"pruned"`
+}
+
+// RowRangeDTO is an inclusive range of rows which may pass the predicate.
+type RowRangeDTO struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// SectionRow is a single decoded row. Values are ordered by column; NULL
+// values are encoded as null.
+type SectionRow struct {
+	Index  int       `json:"index"`
+	Values []*string `json:"values"`
+}
+
+// rowsRequest holds the parameters of a request to browse the rows of a
+// section.
+type rowsRequest struct {
+	File     string
+	Section  int
+	Start    int // Index of the first row to read.
+	Limit    int
+	Selector []*labels.Matcher
+	Metadata []*labels.Matcher
+}
+
+// errBadRowsRequest wraps errors caused by invalid request parameters.
+var errBadRowsRequest = errors.New("bad request")
+
+func (s *Service) handleRows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := parseRowsRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := readRows(r.Context(), encoding.BucketDecoder(s.bucket, req.File), req)
+	if errors.Is(err, errBadRowsRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read rows: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rows); err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseRowsRequest(r *http.Request) (rowsRequest, error) {
+	query := r.URL.Query()
+
+	req := rowsRequest{
+		File:  query.Get("file"),
+		Limit: defaultRowsLimit,
+	}
+	if req.File == "" {
+		return req, fmt.Errorf("file parameter is required")
+	}
+
+	var err error
+	if req.Section, err = strconv.Atoi(query.Get("section")); err != nil || req.Section < 0 {
+		return req, fmt.Errorf("section parameter must be a non-negative integer")
+	}
+	if v := query.Get("start"); v != "" {
+		if req.Start, err = strconv.Atoi(v); err != nil || req.Start < 0 {
+			return req, fmt.Errorf("start parameter must be a non-negative integer")
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if req.Limit, err = strconv.Atoi(v); err != nil || req.Limit <= 0 {
+			return req, fmt.Errorf("limit parameter must be a positive integer")
+		}
+		req.Limit = min(req.Limit, maxRowsLimit)
+	}
+
+	if v := query.Get("selector"); v != "" {
+		if req.Selector, err = syntax.ParseMatchers(v, false); err != nil {
+			return req, fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+	if v := query.Get("metadata"); v != "" {
+		if req.Metadata, err = syntax.ParseMatchers(v, false); err != nil {
+			return req, fmt.Errorf("invalid metadata predicate: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// readRows reads the rows of the section in req which pass the predicates
+// of req, starting from the row at index req.Start. Rows before req.Start are
+// skipped without being read, so that any page can be requested at the same
+// cost.
+func readRows(ctx context.Context, dec encoding.Decoder, req rowsRequest) (SectionRows, error) {
+	sections, err := dec.Sections(ctx)
+	if err != nil {
+		return SectionRows{}, fmt.Errorf("reading sections: %w", err)
+	} else if req.Section >= len(sections) {
+		return SectionRows{}, fmt.Errorf("%w: section %d not found, object has %d sections", errBadRowsRequest, req.Section, len(sections))
+	}
+	section := sections[req.Section]
+
+	var (
+		dset       dataset.Dataset
+		info       []RowsColumn
+		formatters []valueFormatter
+		predicate  func(columns []dataset.Column) (dataset.Predicate, error)
+	)
+
+	switch section.Type {
+	case filemd.SECTION_TYPE_STREAMS:
+		if len(req.Metadata) > 0 {
+			return SectionRows{}, fmt.Errorf("%w: metadata predicates are only supported for logs sections", errBadRowsRequest)
+		}
+
+		descs, err := dec.StreamsDecoder().Columns(ctx, section)
+		if err != nil {
+			return SectionRows{}, fmt.Errorf("reading columns: %w", err)
+		}
+		dset = encoding.StreamsDataset(dec.StreamsDecoder(), section)
+		info, formatters = streamsColumns(descs)
+		predicate = func(columns []dataset.Column) (dataset.Predicate, error) {
+			return streamsPredicate(columns, descs, req.Selector), nil
+		}
+
+	case filemd.SECTION_TYPE_LOGS:
+		descs, err := dec.LogsDecoder().Columns(ctx, section)
+		if err != nil {
+			return SectionRows{}, fmt.Errorf("reading columns: %w", err)
+		}
+		dset = encoding.LogsDataset(dec.LogsDecoder(), section)
+		info, formatters = logsColumns(descs)
+		predicate = func(columns []dataset.Column) (dataset.Predicate, error) {
+			return logsPredicate(ctx, dec, columns, descs, req.Selector, req.Metadata)
+		}
+
+	default:
+		return SectionRows{}, fmt.Errorf("%w: unsupported section type %s", errBadRowsRequest, section.Type)
+	}
+
+	columns, err := result.Collect(dset.ListColumns(ctx))
+	if err != nil {
+		return SectionRows{}, fmt.Errorf("listing columns: %w", err)
+	}
+	p, err := predicate(columns)
+	if err != nil {
+		return SectionRows{}, err
+	}
+
+	res := SectionRows{
+		Type:    section.Type.String(),
+		Columns: info,
+		Rows:    []SectionRow{},
+		Start:   req.Start,
+		Limit:   req.Limit,
+	}
+
+	reader := dataset.NewReader(dataset.ReaderOptions{
+		Dataset:   dset,
+		Columns:   columns,
+		Predicate: p,
+	})
+	defer reader.Close()
+
+	ranges, err := reader.Ranges(ctx)
+	if err != nil {
+		return SectionRows{}, err
+	}
+	res.Ranges = make([]RowRangeDTO, 0, len(ranges))
+	for _, rr := range ranges {
+		res.Ranges = append(res.Ranges, RowRangeDTO{Start: rr.Start, End: rr.End})
+	}
+
+	if err := fillPrunedPages(ctx, &res, columns, ranges); err != nil {
+		return SectionRows{}, err
+	} else if req.Start >= res.TotalRows {
+		return res, nil
+	}
+
+	// The ranges above describe the whole section, so rows are read by a
+	// separate reader which excludes the rows before the start of the page.
+	if req.Start > 0 {
+		startPredicate := dataset.RowRangesPredicate{
+			Ranges: []dataset.RowRange{{Start: uint64(req.Start), End: uint64(res.TotalRows - 1)}},
+		}
+		reader.Reset(dataset.ReaderOptions{
+			Dataset:   dset,
+			Columns:   columns,
+			Predicate: andPredicates([]dataset.Predicate{startPredicate, p}),
+		})
+	}
+
+	// Rows are read until we know whether there's at least one more matching
+	// row after the requested page.
+	batch := make([]dataset.Row, min(req.Limit+1, 128))
+	for len(res.Rows) <= req.Limit {
+		n, err := reader.Read(ctx, batch)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return SectionRows{}, err
+		}
+
+		for _, row := range batch[:n] {
+			if len(res.Rows) == req.Limit {
+				res.HasMore = true
+				res.Next = row.Index
+				return res, nil
+			}
+			res.Rows = append(res.Rows, formatRow(row, formatters))
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// fillPrunedPages reports which pages of columns don't overlap with any of
+// ranges, and therefore are never read.
+func fillPrunedPages(ctx context.Context, res *SectionRows, columns []dataset.Column, ranges []dataset.RowRange) error {
+	for i, column := range columns {
+		res.TotalRows = max(res.TotalRows, column.ColumnInfo().RowsCount)
+
+		pages, err := result.Collect(column.ListPages(ctx))
+		if err != nil {
+			return fmt.Errorf("listing pages: %w", err)
+		}
+
+		var startRow uint64
+		for _, page := range pages {
+			rowCount := uint64(page.PageInfo().RowCount)
+			if rowCount == 0 {
+				continue
+			}
+			pageRange := dataset.RowRange{Start: startRow, End: startRow + rowCount - 1}
+			startRow += rowCount
+
+			pruned := true
+			for _, rr := range ranges {
+				if rr.Overlaps(pageRange) {
+					pruned = false
+					break
+				}
+			}
+
+			res.Columns[i].Pages = append(res.Columns[i].Pages, PagePruneInfo{
+				FirstRow: pageRange.Start,
+				LastRow:  pageRange.End,
+				Pruned:   pruned,
+			})
+			res.PagesTotal++
+			if pruned {
+				res.PagesPruned++
+			}
+		}
+	}
+	return nil
+}
+
+// valueFormatter formats a non-NULL value of a column for display.
+type valueFormatter func(dataset.Value) (string, error)
+
+func formatRow(row dataset.Row, formatters []valueFormatter) SectionRow {
+	res := SectionRow{
+		Index:  row.Index,
+		Values: make([]*string, len(row.Values)),
+	}
+	for i, value := range row.Values {
+		if value.IsNil() {
+			continue
+		}
+
+		s, err := formatters[i](value)
+		if err != nil {
+			s = fmt.Sprintf("<%v>", err)
+		}
+		res.Values[i] = &s
+	}
+	return res
+}
+
+func formatValue(value dataset.Value) (string, error) {
+	switch value.Type() {
+	case datasetmd.VALUE_TYPE_INT64:
+		return strconv.FormatInt(value.Int64(), 10), nil
+	case datasetmd.VALUE_TYPE_UINT64:
+		return strconv.FormatUint(value.Uint64(), 10), nil
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		return strconv.FormatFloat(value.Float64(), 'f', -1, 64), nil
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		return time.Unix(0, value.Timestamp()).UTC().Format(time.RFC3339Nano), nil
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		return string(value.ByteArray()), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %s", getValueTypeName(value.Type()))
+	}
+}
+
+// formatTimestamp formats an INT64 value holding nanoseconds since the Unix
+// epoch.
+func formatTimestamp(value dataset.Value) (string, error) {
+	if value.Type() != datasetmd.VALUE_TYPE_INT64 {
+		return formatValue(value)
+	}
+	return time.Unix(0, value.Int64()).UTC().Format(time.RFC3339Nano), nil
+}
+
+func formatMetadata(value dataset.Value) (string, error) {
+	s, err := logs.AppendMetadataValue(nil, value)
+	return string(s), err
+}
+
+func streamsColumns(descs []*streamsmd.ColumnDesc) ([]RowsColumn, []valueFormatter) {
+	var (
+		columns    = make([]RowsColumn, 0, len(descs))
+		formatters = make([]valueFormatter, 0, len(descs))
+	)
+	for _, desc := range descs {
+		columns = append(columns, RowsColumn{
+			Name:      desc.Info.Name,
+			Type:      desc.Type.String(),
+			ValueType: getValueTypeName(desc.Info.ValueType),
+		})
+
+		switch desc.Type {
+		case streamsmd.COLUMN_TYPE_MIN_TIMESTAMP, streamsmd.COLUMN_TYPE_MAX_TIMESTAMP:
+			formatters = append(formatters, formatTimestamp)
+		default:
+			formatters = append(formatters, formatValue)
+		}
+	}
+	return columns, formatters
+}
+
+func logsColumns(descs []*logsmd.ColumnDesc) ([]RowsColumn, []valueFormatter) {
+	var (
+		columns    = make([]RowsColumn, 0, len(descs))
+		formatters = make([]valueFormatter, 0, len(descs))
+	)
+	for _, desc := range descs {
+		columns = append(columns, RowsColumn{
+			Name:      desc.Info.Name,
+			Type:      desc.Type.String(),
+			ValueType: getValueTypeName(desc.Info.ValueType),
+		})
+
+		switch desc.Type {
+		case logsmd.COLUMN_TYPE_TIMESTAMP:
+			formatters = append(formatters, formatTimestamp)
+		case logsmd.COLUMN_TYPE_METADATA:
+			formatters = append(formatters, formatMetadata)
+		default:
+			formatters = append(formatters, formatValue)
+		}
+	}
+	return columns, formatters
+}
+
+// streamsPredicate converts the label matchers of a stream selector into a
+// predicate over the label columns of a streams section.
+func streamsPredicate(columns []dataset.Column, descs []*streamsmd.ColumnDesc, matchers []*labels.Matcher) dataset.Predicate {
+	var predicates []dataset.Predicate
+	for _, m := range matchers {
+		column := findColumn(columns, descs, func(desc *streamsmd.ColumnDesc) bool {
+			return desc.Type == streamsmd.COLUMN_TYPE_LABEL && desc.Info.Name == m.Name
+		})
+		predicates = append(predicates, matcherPredicate(column, m, formatValue))
+	}
+	return andPredicates(predicates)
+}
+
+// logsPredicate builds a predicate for a logs section. Stream selectors are
+// resolved against the streams sections of the object into the set of
+// matching stream IDs, while metadata matchers are applied to the metadata
+// columns of the section.
+func logsPredicate(ctx context.Context, dec encoding.Decoder, columns []dataset.Column, descs []*logsmd.ColumnDesc, selector, metadata []*labels.Matcher) (dataset.Predicate, error) {
+	var predicates []dataset.Predicate
+
+	if len(selector) > 0 {
+		streamIDColumn := findColumn(columns, descs, func(desc *logsmd.ColumnDesc) bool {
+			return desc.Type == logsmd.COLUMN_TYPE_STREAM_ID
+		})
+
+		var ids []dataset.Value
+		for res := range streams.Iter(ctx, dec) {
+			stream, err := res.Value()
+			if err != nil {
+				return nil, fmt.Errorf("reading streams: %w", err)
+			}
+			if matchesLabels(selector, stream.Labels) {
+				ids = append(ids, dataset.Int64Value(stream.ID))
+			}
+		}
+
+		if streamIDColumn == nil || len(ids) == 0 {
+			return dataset.FalsePredicate{}, nil
+		}
+		predicates = append(predicates, dataset.InPredicate{Column: streamIDColumn, Values: ids})
+	}
+
+	for _, m := range metadata {
+		column := findColumn(columns, descs, func(desc *logsmd.ColumnDesc) bool {
+			return desc.Type == logsmd.COLUMN_TYPE_METADATA && desc.Info.Name == m.Name
+		})
+		predicates = append(predicates, matcherPredicate(column, m, formatMetadata))
+	}
+
+	return andPredicates(predicates), nil
+}
+
+func matchesLabels(matchers []*labels.Matcher, lbls labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matcherPredicate converts m into a predicate over column, where format
+// returns the string representation of values of column. A nil column is
+// treated as a column of NULL values, which match m if m matches the empty
+// string.
+func matcherPredicate(column dataset.Column, m *labels.Matcher, format valueFormatter) dataset.Predicate {
+	if column == nil {
+		if m.Matches("") {
+			return nil
+		}
+		return dataset.FalsePredicate{}
+	}
+
+	// Equality matchers are converted into an EqualPredicate so that pages can
+	// be pruned based on their statistics.
+	if m.Type == labels.MatchEqual && m.Value != "" {
+		value := dataset.ByteArrayValue([]byte(m.Value))
+		if ty := column.ColumnInfo().Type; ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+			typed, ok := logs.ParseMetadataValue(ty, []byte(m.Value))
+			if !ok || typed.IsNil() {
+				return dataset.FalsePredicate{}
+			}
+			value = typed
+		}
+		return dataset.EqualPredicate{Column: column, Value: value}
+	}
+
+	return dataset.FuncPredicate{
+		Column: column,
+		Keep: func(_ dataset.Column, value dataset.Value) bool {
+			if value.IsNil() {
+				return m.Matches("")
+			}
+			s, err := format(value)
+			return err == nil && m.Matches(s)
+		},
+	}
+}
+
+func andPredicates(predicates []dataset.Predicate) dataset.Predicate {
+	var res dataset.Predicate
+	for _, p := range predicates {
+		switch {
+		case p == nil:
+			continue
+		case res == nil:
+			res = p
+		default:
+			res = dataset.AndPredicate{Left: res, Right: p}
+		}
+	}
+	return res
+}
+
+func findColumn[Desc any](columns []dataset.Column, descs []Desc, check func(Desc) bool) dataset.Column {
+	for i, desc := range descs {
+		if check(desc) {
+			return columns[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package explorer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/loki/pkg/push"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+func TestHandleRows(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	uploadTestObject(t, bucket, "test-object")
+
+	svc, err := New(bucket, log.NewNopLogger())
+	require.NoError(t, err)
+	_, handler := svc.Handler()
+
+	tt := []struct {
+		name     string
+		query    url.Values
+		wantCode int
+		wantRows int
+		wantMore bool
+	}{
+		{
+			name:     "streams section",
+			query:    url.Values{"section": {"0"}},
+			wantCode: http.StatusOK,
+			wantRows: 2,
+		},
+		{
+			name:     "streams section with selector",
+			query:    url.Values{"section": {"0"}, "selector": {`{app="foo"}`}},
+			wantCode: http.StatusOK,
+			wantRows: 1,
+		},
+		{
+			name:     "logs section",
+			query:    url.Values{"section": {"1"}},
+			wantCode: http.StatusOK,
+			wantRows: 5,
+		},
+		{
+			name:     "logs section with limit",
+			query:    url.Values{"section": {"1"}, "limit": {"2"}},
+			wantCode: http.StatusOK,
+			wantRows: 2,
+			wantMore: true,
+		},
+		{
+			name:     "logs section with start",
+			query:    url.Values{"section": {"1"}, "start": {"4"}},
+			wantCode: http.StatusOK,
+			wantRows: 1,
+		},
+		{
+			name:     "logs section with start past the last row",
+			query:    url.Values{"section": {"1"}, "start": {"100"}},
+			wantCode: http.StatusOK,
+			wantRows: 0,
+		},
+		{
+			name:     "logs section with selector",
+			query:    url.Values{"section": {"1"}, "selector": {`{app="bar"}`}},
+			wantCode: http.StatusOK,
+			wantRows: 2,
+		},
+		{
+			name:     "logs section with unknown stream",
+			query:    url.Values{"section": {"1"}, "selector": {`{app="baz"}`}},
+			wantCode: http.StatusOK,
+			wantRows: 0,
+		},
+		{
+			name:     "logs section with metadata",
+			query:    url.Values{"section": {"1"}, "metadata": {`{trace_id=~"12.*"}`}},
+			wantCode: http.StatusOK,
+			wantRows: 2,
+		},
+		{
+			name:     "logs section with missing metadata",
+			query:    url.Values{"section": {"1"}, "metadata": {`{trace_id=""}`}},
+			wantCode: http.StatusOK,
+			wantRows: 2,
+		},
+		{
+			name:     "metadata on streams section",
+			query:    url.Values{"section": {"0"}, "metadata": {`{trace_id="123"}`}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid selector",
+			query:    url.Values{"section": {"1"}, "selector": {`{app=`}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing section",
+			query:    url.Values{"section": {"5"}},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.query.Set("file", "test-object")
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dataobj/api/v1/rows?"+tc.query.Encode(), nil))
+			require.Equal(t, tc.wantCode, rec.Code, rec.Body.String())
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+
+			var res SectionRows
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+			require.Len(t, res.Rows, tc.wantRows)
+			require.Equal(t, tc.wantMore, res.HasMore)
+			for _, row := range res.Rows {
+				require.Len(t, row.Values, len(res.Columns))
+			}
+		})
+	}
+}
+
+func TestReadRows(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	uploadTestObject(t, bucket, "test-object")
+
+	matchers, err := syntax.ParseMatchers(`{trace_id="123"}`, false)
+	require.NoError(t, err)
+
+	res, err := readRows(context.Background(), encoding.BucketDecoder(bucket, "test-object"), rowsRequest{
+		Section:  1,
+		Limit:    defaultRowsLimit,
+		Metadata: matchers,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Rows, 1)
+
+	values := make(map[string]*string)
+	for i, column := range res.Columns {
+		key := column.Type
+		if column.Name != "" {
+			key = column.Name
+		}
+		values[key] = res.Rows[0].Values[i]
+	}
+
+	require.Equal(t, "1970-01-01T00:00:02Z", *values["COLUMN_TYPE_TIMESTAMP"])
+	require.Equal(t, "foo 2", *values["COLUMN_TYPE_MESSAGE"])
+	require.Equal(t, "123", *values["trace_id"])
+}
+
+func TestReadRows_Pages(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	uploadTestObject(t, bucket, "test-object")
+
+	matchers, err := syntax.ParseMatchers(`{app="foo"}`, false)
+	require.NoError(t, err)
+
+	req := rowsRequest{Section: 1, Limit: 2, Selector: matchers}
+
+	var indices []int
+	for {
+		res, err := readRows(context.Background(), encoding.BucketDecoder(bucket, "test-object"), req)
+		require.NoError(t, err)
+		require.Equal(t, req.Start, res.Start)
+		require.Equal(t, []RowRangeDTO{{Start: 0, End: 4}}, res.Ranges, "ranges must cover the whole section")
+
+		for _, row := range res.Rows {
+			indices = append(indices, row.Index)
+		}
+		if !res.HasMore {
+			break
+		}
+		require.Greater(t, res.Next, res.Rows[len(res.Rows)-1].Index)
+		req.Start = res.Next
+	}
+
+	require.Equal(t, []int{0, 1, 2}, indices)
+}
+
+func uploadTestObject(t *testing.T, bucket objstore.Bucket, path string) {
+	t.Helper()
+
+	builder, err := dataobj.NewBuilder(dataobj.BuilderConfig{
+		TargetPageSize:          2048,
+		TargetObjectSize:        1 << 20,
+		TargetSectionSize:       1 << 20,
+		BufferSize:              2048 * 8,
+		SectionStripeMergeLimit: 2,
+	})
+	require.NoError(t, err)
+
+	for _, stream := range []logproto.Stream{
+		{
+			Labels: `{app="foo"}`,
+			Entries: []push.Entry{
+				{Timestamp: time.Unix(1, 0), Line: "foo 1"},
+				{Timestamp: time.Unix(2, 0), Line: "foo 2", StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: "123"}}},
+				{Timestamp: time.Unix(3, 0), Line: "foo 3", StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: "124"}}},
+			},
+		},
+		{
+			Labels: `{app="bar"}`,
+			Entries: []push.Entry{
+				{Timestamp: time.Unix(1, 0), Line: "bar 1", StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: "456"}}},
+				{Timestamp: time.Unix(2, 0), Line: "bar 2"},
+			},
+		},
+	} {
+		require.NoError(t, builder.Append(stream))
+	}
+
+	var buf bytes.Buffer
+	_, err = builder.Flush(&buf)
+	require.NoError(t, err)
+	require.NoError(t, bucket.Upload(context.Background(), path, &buf))
+}
@@ -42,6 +42,7 @@ func (s *Service) Handler() (string, http.Handler) {
 	mux.HandleFunc("/dataobj/api/v1/inspect", s.handleInspect)
 	mux.HandleFunc("/dataobj/api/v1/download", s.handleDownload)
 	mux.HandleFunc("/dataobj/api/v1/provider", s.handleProvider)
+	mux.HandleFunc("/dataobj/api/v1/rows", s.handleRows)
 
 	return "/dataobj", mux
 }
@@ -0,0 +1,139 @@
+package dataset
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/willf/bloom"
+)
+
+// bloomFalsePositiveRate is the false positive rate of the bloom filters of
+// columns and pages.
+const bloomFalsePositiveRate = 0.01
+
+// maxBloomKeys is the maximum number of distinct values buffered by a
+// [bloomBuilder] before it adds values to a bloom filter as they're appended.
+const maxBloomKeys = 1024
+
+// bloomBuilder builds the bloom filter of a column or page.
+//
+// The distinct values are buffered until there are more than maxBloomKeys of
+// them, so that the bloom filters of low cardinality columns and pages are
+// sized to their values. Past that, the values are added to a bloom filter as
+// they're appended, so memory is bounded for high cardinality columns. The
+// filter is sized for the estimated number of values of a page, which is
+// derived from the page size: pages are cut once their values fill it.
+type bloomBuilder struct {
+	// pageSizeHint is used to estimate the number of values of a page. If
+	// it's zero, no filter is built once there are too many distinct values.
+	pageSizeHint int
+
+	keys     map[string]struct{}
+	filter   *bloom.BloomFilter
+	overflow bool // Set if keys overflowed and no filter is built.
+}
+
+// newBloomBuilder returns a bloomBuilder for pages of the given size hint.
+// Builders created with a zero pageSizeHint, such as those of columns, omit
+// the bloom filter of high cardinality values.
+func newBloomBuilder(pageSizeHint int) *bloomBuilder {
+	return &bloomBuilder{pageSizeHint: pageSizeHint}
+}
+
+// Add adds a non-NULL value to b.
+func (b *bloomBuilder) Add(value Value) {
+	if b.overflow {
+		return
+	}
+
+	key, err := value.MarshalBinary()
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal value for bloom filter of type %s: %s", value.Type(), err))
+	}
+	if b.filter != nil {
+		b.filter.Add(key)
+		return
+	}
+
+	if b.keys == nil {
+		b.keys = make(map[string]struct{})
+	}
+	b.keys[string(key)] = struct{}{}
+	if len(b.keys) <= maxBloomKeys {
+		return
+	}
+
+	if b.pageSizeHint <= 0 {
+		b.overflow = true
+		clear(b.keys)
+		return
+	}
+	estimate := max(b.pageSizeHint/max(valueSize(value), 1), 2*maxBloomKeys)
+	b.filter = bloom.NewWithEstimates(uint(estimate), bloomFalsePositiveRate)
+	for key := range b.keys {
+		b.filter.AddString(key)
+	}
+	clear(b.keys)
+}
+
+// Encode returns the binary encoding of the bloom filter of the values of b.
+// It returns nil if the bloom filter was omitted.
+func (b *bloomBuilder) Encode() []byte {
+	if b.overflow {
+		return nil
+	}
+
+	filter := b.filter
+	if filter == nil {
+		filter = bloom.NewWithEstimates(uint(max(len(b.keys), 1)), bloomFalsePositiveRate)
+		for key := range b.keys {
+			filter.AddString(key)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := filter.WriteTo(&buf); err != nil {
+		// Writing to a bytes.Buffer does not fail.
+		panic(fmt.Sprintf("failed to encode bloom filter: %s", err))
+	}
+	return buf.Bytes()
+}
+
+// Reset resets b to a fresh state, allowing it to be reused.
+func (b *bloomBuilder) Reset() {
+	clear(b.keys)
+	b.filter = nil
+	b.overflow = false
+}
+
+// bloomMayContain returns false if the encoded bloom filter can't contain any
+// of the values. False positives are possible, false negatives are not.
+//
+// bloomMayContain returns true if the filter is empty, as columns and pages
+// without bloom filters may contain any value, or if any of the values is
+// NULL.
+func bloomMayContain(encoded []byte, values ...Value) (bool, error) {
+	if len(encoded) == 0 {
+		return true, nil
+	}
+
+	var filter bloom.BloomFilter
+	if _, err := filter.ReadFrom(bytes.NewReader(encoded)); err != nil {
+		return false, fmt.Errorf("reading bloom filter: %w", err)
+	}
+
+	for _, value := range values {
+		if value.IsNil() || value.IsZero() {
+			// NULL values aren't added to bloom filters.
+			return true, nil
+		}
+
+		key, err := value.MarshalBinary()
+		if err != nil {
+			return false, err
+		} else if filter.Test(key) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
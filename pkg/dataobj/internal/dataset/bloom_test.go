@@ -0,0 +1,68 @@
+package dataset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bloomBuilder(t *testing.T) {
+	values := func(n int) []Value {
+		var values []Value
+		for i := range n {
+			values = append(values, ByteArrayValue([]byte(fmt.Sprintf("trace-%06d", i))))
+		}
+		return values
+	}
+
+	t.Run("low cardinality", func(t *testing.T) {
+		b := newBloomBuilder(0)
+		for _, v := range values(10) {
+			b.Add(v)
+			b.Add(v)
+		}
+		require.Len(t, b.keys, 10)
+
+		encoded := b.Encode()
+		for _, v := range values(10) {
+			ok, err := bloomMayContain(encoded, v)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("high cardinality page", func(t *testing.T) {
+		b := newBloomBuilder(1 << 20)
+		for _, v := range values(10 * maxBloomKeys) {
+			b.Add(v)
+		}
+		// Values are added to the filter as they're appended instead of being
+		// buffered.
+		require.Empty(t, b.keys)
+		require.NotNil(t, b.filter)
+
+		encoded := b.Encode()
+		for _, v := range values(10 * maxBloomKeys) {
+			ok, err := bloomMayContain(encoded, v)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+		ok, err := bloomMayContain(encoded, ByteArrayValue([]byte("span-000000")))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("high cardinality column", func(t *testing.T) {
+		b := newBloomBuilder(0)
+		for _, v := range values(maxBloomKeys + 1) {
+			b.Add(v)
+		}
+		require.Empty(t, b.keys)
+		require.Nil(t, b.Encode())
+
+		b.Reset()
+		b.Add(values(1)[0])
+		require.NotNil(t, b.Encode())
+	})
+}
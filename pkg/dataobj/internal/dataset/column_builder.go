@@ -19,6 +19,11 @@ type BuilderOptions struct {
 	Value datasetmd.ValueType
 
 	// Encoding is the encoding algorithm to use for values.
+	//
+	// [datasetmd.ENCODING_TYPE_DICTIONARY] is only used while the observed
+	// cardinality of the column is low enough for a dictionary to pay off.
+	// Otherwise, [ColumnBuilder] falls back to [datasetmd.ENCODING_TYPE_PLAIN]
+	// for the remaining pages of the column.
 	Encoding datasetmd.EncodingType
 
 	// Compression is the compression algorithm to use for values.
@@ -40,6 +45,12 @@ type StatisticsOptions struct {
 	// StoreCardinalityStats indicates whether to store cardinality estimations,
 	// facilitated by hyperloglog
 	StoreCardinalityStats bool
+
+	// StoreBloomFilter indicates whether to store bloom filters of the values
+	// of the column and pages. Bloom filters allow readers to skip pages when
+	// checking for equality against values of high cardinality columns, where
+	// range stats don't help.
+	StoreBloomFilter bool
 }
 
 // CompressionOptions customizes the compressor used when building pages.
@@ -208,6 +219,15 @@ func (cb *ColumnBuilder) flushPage() {
 		panic(fmt.Sprintf("failed to flush page: %s", err))
 	}
 	cb.pages = append(cb.pages, page)
+
+	// A page which fell back from dictionary encoding had too many distinct
+	// values; following pages of the column are likely to have as many, so we
+	// encode them with the fallback encoding directly.
+	if page.Info.Encoding != cb.pageBuilder.Encoding() {
+		if err := cb.pageBuilder.SetEncoding(page.Info.Encoding); err != nil {
+			panic(fmt.Sprintf("failed to set page encoding: %s", err))
+		}
+	}
 }
 
 // Reset clears all data in cb and resets it to a fresh state.
@@ -215,4 +235,9 @@ func (cb *ColumnBuilder) Reset() {
 	cb.rows = 0
 	cb.pages = nil
 	cb.pageBuilder.Reset()
+
+	// The options were validated by NewColumnBuilder, so this can't fail.
+	if err := cb.pageBuilder.SetEncoding(cb.opts.Encoding); err != nil {
+		panic(fmt.Sprintf("failed to reset page encoding: %s", err))
+	}
 }
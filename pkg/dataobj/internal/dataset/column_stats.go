@@ -31,6 +31,9 @@ type columnStatsBuilder struct {
 
 	// for cardinality
 	hll *hyperloglog.Sketch
+
+	// for bloom filters
+	bloom *bloomBuilder
 }
 
 // ColumnStatsBuilder is for column-level statistics
@@ -39,6 +42,13 @@ func newColumnStatsBuilder(opts StatisticsOptions) (*columnStatsBuilder, error)
 		opts: opts,
 	}
 
+	if opts.StoreBloomFilter {
+		// The number of values of a column is unknown until it's flushed, so
+		// the column-level filter is omitted for high cardinality columns,
+		// whose pages are pruned by their own filters.
+		result.bloom = newBloomBuilder(0)
+	}
+
 	if opts.StoreCardinalityStats {
 		var err error
 		if result.hll, err = newHyperLogLog(); err != nil {
@@ -63,6 +73,10 @@ func (csb *columnStatsBuilder) Append(value Value) {
 		// into an intermediate buffer.
 		csb.hll.Insert(buf)
 	}
+
+	if csb.opts.StoreBloomFilter && !value.IsNil() && !value.IsZero() {
+		csb.bloom.Add(value)
+	}
 }
 
 // Flush builds the column-level stats both from the given pages and any internal
@@ -75,19 +89,31 @@ func (csb *columnStatsBuilder) Flush(pages []*MemPage) *datasetmd.Statistics {
 	if csb.opts.StoreRangeStats {
 		csb.buildRangeStats(pages, &dst)
 	}
+	if csb.opts.StoreBloomFilter {
+		dst.BloomFilter = csb.bloom.Encode()
+		csb.bloom.Reset()
+	}
 
 	return &dst
 }
 
 func (csb *columnStatsBuilder) buildRangeStats(pages []*MemPage, dst *datasetmd.Statistics) {
-	var minValue, maxValue Value
+	var (
+		minValue, maxValue Value
+		found              bool
+	)
 
-	for i, page := range pages {
+	for _, page := range pages {
 		if page.Info.Stats == nil {
 			// This should never hit; if cb.opts.StoreRangeStats is true, then
 			// page.Info.Stats will be populated.
 			panic("ColumnStatsBuilder.buildStats: page missing stats")
 		}
+		if page.Info.ValuesCount == 0 {
+			// Pages with only NULL values have no range; including them would
+			// always make NULL the minimum of the column.
+			continue
+		}
 
 		var pageMin, pageMax Value
 
@@ -97,12 +123,13 @@ func (csb *columnStatsBuilder) buildRangeStats(pages []*MemPage, dst *datasetmd.
 			panic(fmt.Sprintf("ColumnStatsBuilder.buildStats: failed to unmarshal max value: %s", err))
 		}
 
-		if i == 0 || CompareValues(pageMin, minValue) < 0 {
+		if !found || CompareValues(pageMin, minValue) < 0 {
 			minValue = pageMin
 		}
-		if i == 0 || CompareValues(pageMax, maxValue) > 0 {
+		if !found || CompareValues(pageMax, maxValue) > 0 {
 			maxValue = pageMax
 		}
+		found = true
 	}
 
 	var err error
@@ -3,7 +3,9 @@ package dataset
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"testing"
 
@@ -77,6 +79,76 @@ func TestColumnBuilder_ReadWrite(t *testing.T) {
 	require.Equal(t, in, actual)
 }
 
+func TestColumnBuilder_DictionaryFallback(t *testing.T) {
+	var in []string
+	for i := range 20_000 {
+		in = append(in, []string{"debug", "info", "warn"}[i%3])
+	}
+	for i := range 1000 {
+		in = append(in, fmt.Sprintf("value-%d", i))
+	}
+	for i := range 500 {
+		in = append(in, []string{"debug", "info", "warn"}[i%3])
+	}
+
+	opts := BuilderOptions{
+		PageSizeHint: 4096,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		Encoding:     datasetmd.ENCODING_TYPE_DICTIONARY,
+	}
+	b, err := NewColumnBuilder("", opts)
+	require.NoError(t, err)
+
+	for i, s := range in {
+		require.NoError(t, b.Append(i, ByteArrayValue([]byte(s))))
+	}
+
+	col, err := b.Flush()
+	require.NoError(t, err)
+	require.Equal(t, len(in), col.Info.RowsCount)
+
+	// Pages of low cardinality values are dictionary-encoded until the values
+	// get too distinct; all following pages are plain-encoded.
+	var encodings []datasetmd.EncodingType
+	for _, page := range col.Pages {
+		encodings = append(encodings, page.Info.Encoding)
+	}
+	fallback := slices.Index(encodings, datasetmd.ENCODING_TYPE_PLAIN)
+	require.Greater(t, fallback, 1, "expected dictionary-encoded pages before falling back")
+	for i, encoding := range encodings {
+		if i < fallback {
+			require.Equal(t, datasetmd.ENCODING_TYPE_DICTIONARY, encoding, "page %d", i)
+		} else {
+			require.Equal(t, datasetmd.ENCODING_TYPE_PLAIN, encoding, "page %d", i)
+		}
+	}
+
+	var actual []string
+
+	r := newColumnReader(col)
+	for {
+		var values [64]Value
+		n, err := r.Read(context.Background(), values[:])
+		if err != nil && !errors.Is(err, io.EOF) {
+			require.NoError(t, err)
+		}
+		for _, val := range values[:n] {
+			actual = append(actual, string(val.ByteArray()))
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	require.Equal(t, in, actual)
+
+	// Resetting the builder tries dictionary encoding again.
+	require.NoError(t, b.Append(0, ByteArrayValue([]byte("info"))))
+	col, err = b.Flush()
+	require.NoError(t, err)
+	require.Equal(t, datasetmd.ENCODING_TYPE_DICTIONARY, col.Pages[0].Info.Encoding)
+}
+
 func TestColumnBuilder_MinMax(t *testing.T) {
 	var (
 		// We include the null string in the test to ensure that it's never
@@ -148,6 +220,71 @@ func TestColumnBuilder_MinMax(t *testing.T) {
 	require.Equal(t, fString, string(page1Max.ByteArray()))
 }
 
+func TestColumnBuilder_MinMax_Float64(t *testing.T) {
+	opts := BuilderOptions{
+		PageSizeHint: 24, // Three values per page.
+		Value:        datasetmd.VALUE_TYPE_FLOAT64,
+		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+
+		Statistics: StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	}
+	b, err := NewColumnBuilder("", opts)
+	require.NoError(t, err)
+
+	for i, f := range []float64{2.5, -1, 0.125, 1e9, 3, 7.75} {
+		require.NoError(t, b.Append(i, Float64Value(f)))
+	}
+
+	col, err := b.Flush()
+	require.NoError(t, err)
+	require.Equal(t, datasetmd.VALUE_TYPE_FLOAT64, col.Info.Type)
+
+	columnMin, columnMax := getMinMax(t, col.Info.Statistics)
+	require.Equal(t, -1.0, columnMin.Float64())
+	require.Equal(t, 1e9, columnMax.Float64())
+
+	require.Len(t, col.Pages, 2)
+	page0Min, page0Max := getMinMax(t, col.Pages[0].Info.Stats)
+	require.Equal(t, -1.0, page0Min.Float64())
+	require.Equal(t, 2.5, page0Max.Float64())
+
+	page1Min, page1Max := getMinMax(t, col.Pages[1].Info.Stats)
+	require.Equal(t, 3.0, page1Min.Float64())
+	require.Equal(t, 1e9, page1Max.Float64())
+}
+
+func TestColumnBuilder_MinMax_NullPages(t *testing.T) {
+	opts := BuilderOptions{
+		PageSizeHint: 1, // Cut a page for every row.
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+
+		Statistics: StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	}
+	b, err := NewColumnBuilder("", opts)
+	require.NoError(t, err)
+
+	// Rows after the values are NULL and are cut into their own page.
+	require.NoError(t, b.Append(0, ByteArrayValue([]byte("b"))))
+	require.NoError(t, b.Append(1, ByteArrayValue([]byte("a"))))
+	b.Backfill(10)
+
+	col, err := b.Flush()
+	require.NoError(t, err)
+	require.True(t, slices.ContainsFunc(col.Pages, func(p *MemPage) bool { return p.Info.ValuesCount == 0 }))
+
+	// Pages without values must not make NULL the minimum of the column.
+	columnMin, columnMax := getMinMax(t, col.Info.Statistics)
+	require.Equal(t, "a", string(columnMin.ByteArray()))
+	require.Equal(t, "b", string(columnMax.ByteArray()))
+}
+
 func TestColumnBuilder_Cardinality(t *testing.T) {
 	var (
 		// We include the null string in the test to ensure that it's never
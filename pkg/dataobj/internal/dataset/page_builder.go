@@ -44,12 +44,23 @@ type pageBuilder struct {
 	presenceEnc *bitmapEncoder
 	valuesEnc   valueEncoder
 
+	// encoding is the encoding used for the values of new pages. It differs
+	// from opts.Encoding once a column falls back from dictionary encoding to
+	// plain encoding. Encoders are cached by encoding type so they can be
+	// reused across pages.
+	encoding datasetmd.EncodingType
+	encoders map[datasetmd.EncodingType]valueEncoder
+
 	rows   int // Number of rows appended to the builder.
 	values int // Number of non-NULL values appended to the builder.
 
 	// minValue and maxValue track the minimum and maximum values appended to the
 	// page. These are used to compute statistics for the page if requested.
 	minValue, maxValue Value
+
+	// bloom builds the bloom filter of the values appended to the page if
+	// requested.
+	bloom *bloomBuilder
 }
 
 // newPageBuilder creates a new pageBuilder that stores a sequence of [Value]s.
@@ -69,6 +80,11 @@ func newPageBuilder(opts BuilderOptions) (*pageBuilder, error) {
 		return nil, fmt.Errorf("no encoder available for %s/%s", opts.Value, opts.Encoding)
 	}
 
+	var bloom *bloomBuilder
+	if opts.Statistics.StoreBloomFilter {
+		bloom = newBloomBuilder(opts.PageSizeHint)
+	}
+
 	return &pageBuilder{
 		opts: opts,
 
@@ -79,6 +95,11 @@ func newPageBuilder(opts BuilderOptions) (*pageBuilder, error) {
 
 		presenceEnc: presenceEnc,
 		valuesEnc:   valuesEnc,
+
+		encoding: opts.Encoding,
+		encoders: map[datasetmd.EncodingType]valueEncoder{opts.Encoding: valuesEnc},
+
+		bloom: bloom,
 	}, nil
 }
 
@@ -115,9 +136,86 @@ func (b *pageBuilder) Append(value Value) bool {
 
 	b.rows++
 	b.values++
+
+	if dict, ok := b.valuesEnc.(*dictionaryEncoder); ok && !dictionaryEfficient(dict.Cardinality(), b.values) {
+		if err := b.fallbackToPlain(dict); err != nil {
+			panic(fmt.Sprintf("pageBuilder.Append: falling back to plain encoding: %v", err))
+		}
+	}
 	return true
 }
 
+const (
+	// maxDictionaryCardinality is the maximum number of distinct values of a
+	// dictionary-encoded page.
+	maxDictionaryCardinality = 1 << 16
+
+	// minDictionaryValues is the number of values a dictionary-encoded page
+	// must hold before its cardinality is compared to its number of values.
+	minDictionaryValues = 64
+)
+
+// dictionaryEfficient returns true if dictionary encoding is expected to pay
+// off for a page with the given number of distinct values and values. Values
+// must repeat at least twice on average for the dictionary to be smaller than
+// the values themselves.
+func dictionaryEfficient(cardinality, values int) bool {
+	if cardinality > maxDictionaryCardinality {
+		return false
+	}
+	return values < minDictionaryValues || cardinality*2 <= values
+}
+
+// fallbackToPlain re-encodes the values buffered in dict with plain encoding,
+// and uses plain encoding for the remaining values of the page.
+func (b *pageBuilder) fallbackToPlain(dict *dictionaryEncoder) error {
+	plain, err := b.encoderFor(datasetmd.ENCODING_TYPE_PLAIN)
+	if err != nil {
+		return err
+	}
+	plain.Reset(b.valuesWriter)
+
+	if err := dict.EncodeTo(plain); err != nil {
+		return err
+	}
+	b.valuesEnc = plain
+	return nil
+}
+
+// encoderFor returns the cached encoder for the given encoding type, creating
+// it if needed.
+func (b *pageBuilder) encoderFor(encoding datasetmd.EncodingType) (valueEncoder, error) {
+	if enc, ok := b.encoders[encoding]; ok {
+		return enc, nil
+	}
+
+	enc, ok := newValueEncoder(b.opts.Value, encoding, b.valuesWriter)
+	if !ok {
+		return nil, fmt.Errorf("no encoder available for %s/%s", b.opts.Value, encoding)
+	}
+	b.encoders[encoding] = enc
+	return enc, nil
+}
+
+// Encoding returns the encoding used for the values of new pages.
+func (b *pageBuilder) Encoding() datasetmd.EncodingType { return b.encoding }
+
+// SetEncoding sets the encoding used for the values of new pages. The encoding
+// of the current page is only changed if it is empty.
+func (b *pageBuilder) SetEncoding(encoding datasetmd.EncodingType) error {
+	enc, err := b.encoderFor(encoding)
+	if err != nil {
+		return err
+	}
+
+	b.encoding = encoding
+	if b.rows == 0 {
+		enc.Reset(b.valuesWriter)
+		b.valuesEnc = enc
+	}
+	return nil
+}
+
 // AppendNull appends a NULL value to the Builder. AppendNull returns true if
 // the NULL was appended, or false if the Builder is full.
 func (b *pageBuilder) AppendNull() bool {
@@ -147,6 +245,9 @@ func (b *pageBuilder) accumulateStatistics(value Value) {
 	if b.opts.Statistics.StoreRangeStats {
 		b.updateMinMax(value)
 	}
+	if b.opts.Statistics.StoreBloomFilter {
+		b.bloom.Add(value)
+	}
 }
 
 func (b *pageBuilder) updateMinMax(value Value) {
@@ -171,6 +272,14 @@ func valueSize(v Value) int {
 		// Assuming that uint64s are written as uvarints.
 		return streamio.UvarintSize(v.Uint64())
 
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		// float64s are written as 8 bytes.
+		return 8
+
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		// Assuming that timestamps are written as varints.
+		return streamio.VarintSize(v.Timestamp())
+
 	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
 		arr := v.ByteArray()
 		return binary.Size(len(arr)) + len(arr)
@@ -185,7 +294,14 @@ func (b *pageBuilder) EstimatedSize() int {
 	// This estimate doesn't account for any values in encoders which haven't
 	// been flushed yet. However, encoder buffers are usually small enough that
 	// we wouldn't massively overshoot our estimate.
-	return b.presenceBuffer.Len() + b.valuesWriter.BytesWritten()
+	//
+	// The exception is dictionary encoding, which buffers all values of the
+	// page until it is flushed.
+	size := b.presenceBuffer.Len() + b.valuesWriter.BytesWritten()
+	if dict, ok := b.valuesEnc.(*dictionaryEncoder); ok {
+		size += dict.BufferedSize()
+	}
+	return size
 }
 
 // Rows returns the number of rows appended to the pageBuilder.
@@ -248,7 +364,7 @@ func (b *pageBuilder) Flush() (*MemPage, error) {
 			RowCount:         b.rows,
 			ValuesCount:      b.values,
 
-			Encoding: b.opts.Encoding,
+			Encoding: b.valuesEnc.EncodingType(),
 			Stats:    b.buildStats(),
 		},
 
@@ -260,12 +376,18 @@ func (b *pageBuilder) Flush() (*MemPage, error) {
 }
 
 func (b *pageBuilder) buildStats() *datasetmd.Statistics {
+	if !b.opts.Statistics.StoreRangeStats && !b.opts.Statistics.StoreBloomFilter {
+		return nil
+	}
+
 	var stats datasetmd.Statistics
 	if b.opts.Statistics.StoreRangeStats {
 		b.buildRangeStats(&stats)
-		return &stats
 	}
-	return nil
+	if b.opts.Statistics.StoreBloomFilter {
+		stats.BloomFilter = b.bloom.Encode()
+	}
+	return &stats
 }
 
 func (b *pageBuilder) buildRangeStats(dst *datasetmd.Statistics) {
@@ -289,9 +411,13 @@ func (b *pageBuilder) Reset() {
 	b.valuesBuffer.Reset()
 	b.valuesWriter.Reset(b.valuesBuffer)
 	b.presenceBuffer.Reset()
+	b.valuesEnc = b.encoders[b.encoding]
 	b.valuesEnc.Reset(b.valuesWriter)
 	b.rows = 0
 	b.values = 0
 	b.minValue = Value{}
 	b.maxValue = Value{}
+	if b.bloom != nil {
+		b.bloom.Reset()
+	}
 }
@@ -65,6 +65,15 @@ type (
 		// If Keep returns true, the row is kept.
 		Keep func(column Column, value Value) bool
 	}
+
+	// A RowRangesPredicate is a [Predicate] which asserts that a row may only be
+	// included if its index is inside one of the Ranges. RowRangesPredicate
+	// permits narrowing down the rows to read using an external index.
+	RowRangesPredicate struct {
+		// Ranges of rows to include. Ranges must be sorted by their Start row and
+		// must not overlap.
+		Ranges []RowRange
+	}
 )
 
 func (AndPredicate) isPredicate()         {}
@@ -76,6 +85,7 @@ func (InPredicate) isPredicate()          {}
 func (GreaterThanPredicate) isPredicate() {}
 func (LessThanPredicate) isPredicate()    {}
 func (FuncPredicate) isPredicate()        {}
+func (RowRangesPredicate) isPredicate()   {}
 
 // WalkPredicate traverses a predicate in depth-first order: it starts by
 // calling fn(p). If fn(p) returns true, WalkPredicate is invoked recursively
@@ -104,6 +114,7 @@ func WalkPredicate(p Predicate, fn func(p Predicate) bool) {
 	case GreaterThanPredicate: // No children.
 	case LessThanPredicate: // No children.
 	case FuncPredicate: // No children.
+	case RowRangesPredicate: // No children.
 
 	default:
 		panic(fmt.Sprintf("dataset.WalkPredicate: unsupported predicate type %T", p))
@@ -1,11 +1,13 @@
 package dataset
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
+	"slices"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/bitmask"
@@ -263,6 +265,11 @@ func checkPredicate(p Predicate, lookup map[Column]int, row Row) bool {
 		}
 		return p.Keep(p.Column, row.Values[columnIndex])
 
+	case RowRangesPredicate:
+		ranges := rowRanges(p.Ranges)
+		_, ok := ranges.Range(uint64(row.Index))
+		return ok
+
 	default:
 		panic(fmt.Sprintf("unsupported predicate type %T", p))
 	}
@@ -308,6 +315,21 @@ func buildMask(full rowRange, s []Row) iter.Seq[rowRange] {
 	}
 }
 
+// Ranges returns the ranges of rows which may pass the predicate, sorted by
+// their Start row. Pages which don't overlap with any of the returned ranges
+// are never read. Ranges initializes the Reader if it hasn't read any rows yet.
+//
+// The returned slice must not be modified.
+func (r *Reader) Ranges(ctx context.Context) ([]RowRange, error) {
+	if !r.ready {
+		err := r.init(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("initializing reader: %w", err)
+		}
+	}
+	return r.ranges, nil
+}
+
 // Close closes the Reader. Closed Readers can be reused by calling
 // [Reader.Reset].
 func (r *Reader) Close() error {
@@ -393,7 +415,7 @@ func (r *Reader) validatePredicate() error {
 			err = process(p.Column)
 		case FuncPredicate:
 			err = process(p.Column)
-		case AndPredicate, OrPredicate, NotPredicate, FalsePredicate, nil:
+		case AndPredicate, OrPredicate, NotPredicate, FalsePredicate, RowRangesPredicate, nil:
 			// No columns to process.
 		default:
 			panic(fmt.Sprintf("dataset.Reader.validatePredicate: unsupported predicate type %T", p))
@@ -439,10 +461,30 @@ func (r *Reader) initDownloader(ctx context.Context) error {
 	statistics := stats.FromContext(ctx)
 	statistics.AddTotalRowsAvailable(int64(rowsCount))
 
+	if r.opts.Predicate != nil {
+		// Pages of primary columns that don't overlap with any of the ranges
+		// are never read.
+		var pruned int64
+		for _, column := range r.dl.PrimaryColumns() {
+			for result := range column.ListPages(ctx) {
+				page, err := result.Value()
+				if err != nil {
+					return err
+				}
+				if !ranges.Overlaps(page.(*readerPage).rows) {
+					pruned++
+				}
+			}
+		}
+		statistics.AddPagesPruned(pruned)
+	}
+
 	return nil
 }
 
 func (r *Reader) fillPrimaryMask(mask *bitmask.Mask) {
+	var primaryColumns int
+
 	process := func(c Column) {
 		idx, ok := r.origColumnLookup[c]
 		if !ok {
@@ -451,6 +493,7 @@ func (r *Reader) fillPrimaryMask(mask *bitmask.Mask) {
 			panic("fillPrimaryMask: column not found")
 		}
 		mask.Set(idx)
+		primaryColumns++
 	}
 
 	// If there's no predicate, all columns are primary.
@@ -461,6 +504,18 @@ func (r *Reader) fillPrimaryMask(mask *bitmask.Mask) {
 		return
 	}
 
+	// If the predicate doesn't use any columns (such as a sole
+	// RowRangesPredicate), all columns are primary, as rows can't be read
+	// without any primary columns.
+	defer func() {
+		if primaryColumns > 0 {
+			return
+		}
+		for _, c := range r.opts.Columns {
+			process(c)
+		}
+	}()
+
 	// If there is a predicate, primary columns are those used in the predicate.
 	WalkPredicate(r.opts.Predicate, func(p Predicate) bool {
 		switch p := p.(type) {
@@ -474,7 +529,7 @@ func (r *Reader) fillPrimaryMask(mask *bitmask.Mask) {
 			process(p.Column)
 		case FuncPredicate:
 			process(p.Column)
-		case AndPredicate, OrPredicate, NotPredicate, FalsePredicate, nil:
+		case AndPredicate, OrPredicate, NotPredicate, FalsePredicate, RowRangesPredicate, nil:
 			// No columns to process.
 		default:
 			panic(fmt.Sprintf("dataset.Reader.fillPrimaryMask: unsupported predicate type %T", p))
@@ -546,9 +601,15 @@ func (r *Reader) buildPredicateRanges(ctx context.Context, p Predicate) (rowRang
 	case LessThanPredicate:
 		return r.buildColumnPredicateRanges(ctx, p.Column, p)
 
-	case FuncPredicate, nil:
-		// These predicates (and nil) don't support any filtering, so it maps to
-		// the full range being valid.
+	case FuncPredicate:
+		return r.buildColumnPredicateRanges(ctx, p.Column, p)
+
+	case RowRangesPredicate:
+		return slices.Clone(rowRanges(p.Ranges)), nil
+
+	case nil:
+		// A nil predicate doesn't support any filtering, so it maps to the full
+		// range being valid.
 		//
 		// We use r.dl.AllColumns instead of r.opts.Columns because the downloader
 		// will cache metadata.
@@ -623,15 +684,25 @@ func simplifyNotPredicate(p NotPredicate) (Predicate, error) {
 	case FuncPredicate:
 		return nil, fmt.Errorf("can't simplify FuncPredicate")
 
+	case RowRangesPredicate:
+		return nil, fmt.Errorf("can't simplify RowRangesPredicate")
+
 	default:
 		panic(fmt.Sprintf("unsupported predicate type %T", inner))
 	}
 }
 
 // buildColumnPredicateRanges returns a set of rowRanges that are valid based
-// on whether EqualPredicate, InPredicate, GreaterThanPredicate, or LessThanPredicate may be
-// true for each page in a column.
+// on whether EqualPredicate, InPredicate, GreaterThanPredicate, LessThanPredicate,
+// or FuncPredicate may be true for each page in a column.
+//
+// Pages with bloom filters are skipped if they can't contain the values of an
+// EqualPredicate or InPredicate. Pages with dictionary encoding are narrowed
+// down further to the rows for which p is true, by evaluating p against the
+// dictionary of the page.
 func (r *Reader) buildColumnPredicateRanges(ctx context.Context, c Column, p Predicate) (rowRanges, error) {
+	predicateColumn := c
+
 	// Get the wrapped column so that the result of c.ListPages can be cached.
 	if idx, ok := r.origColumnLookup[c]; ok {
 		c = r.dl.AllColumns()[idx]
@@ -639,6 +710,13 @@ func (r *Reader) buildColumnPredicateRanges(ctx context.Context, c Column, p Pre
 		return nil, fmt.Errorf("column %v not found in Reader columns", c)
 	}
 
+	// No page needs to be checked if the column can't contain the values.
+	if include, err := bloomMayMatch(p, c.ColumnInfo().Statistics); err != nil {
+		return nil, fmt.Errorf("failed to read column bloom filter: %w", err)
+	} else if !include {
+		return nil, nil
+	}
+
 	var ranges rowRanges
 
 	var (
@@ -664,36 +742,201 @@ func (r *Reader) buildColumnPredicateRanges(ctx context.Context, c Column, p Pre
 		minValue, maxValue, err := readMinMax(pageInfo.Stats)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read page stats: %w", err)
-		} else if minValue.IsNil() || maxValue.IsNil() {
-			// No stats, so we add the whole range.
-			ranges.Add(pageRange)
-			continue
 		}
 
-		var include bool
+		include := true
 
 		switch p := p.(type) {
-		case EqualPredicate: // EqualPredicate may be true if p.Value is inside the range of the page.
-			include = CompareValues(p.Value, minValue) >= 0 && CompareValues(p.Value, maxValue) <= 0
-		case GreaterThanPredicate: // GreaterThanPredicate may be true if maxValue of a page is greater than p.Value
-			include = CompareValues(maxValue, p.Value) > 0
-		case LessThanPredicate: // LessThanPredicate may be true if minValue of a page is less than p.Value
-			include = CompareValues(minValue, p.Value) < 0
-		case InPredicate:
-			// Check if any value falls within the page's range
-			for _, v := range p.Values {
-				if CompareValues(v, minValue) >= 0 && CompareValues(v, maxValue) <= 0 {
-					include = true
-					break
-				}
+		case FuncPredicate:
+			// FuncPredicate can't be checked against page stats; pages can only be
+			// filtered by evaluating it against their dictionary.
+		case EqualPredicate, GreaterThanPredicate, LessThanPredicate, InPredicate:
+			if minValue.IsNil() || maxValue.IsNil() {
+				// No stats, so we consider the whole range.
+				break
 			}
+			include = pageMayMatch(p, minValue, maxValue)
 		default:
 			panic(fmt.Sprintf("unsupported predicate type %T", p))
 		}
 
 		if include {
+			include, err = bloomMayMatch(p, pageInfo.Stats)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read page bloom filter: %w", err)
+			}
+		}
+
+		if !include {
+			continue
+		} else if pageInfo.Encoding != datasetmd.ENCODING_TYPE_DICTIONARY {
 			ranges.Add(pageRange)
+			continue
 		}
+
+		pageRanges, err := dictionaryPageRanges(ctx, c, page, pageRange, predicateColumn, p)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating predicate on dictionary: %w", err)
+		}
+		for _, rr := range pageRanges {
+			ranges.Add(rr)
+		}
+	}
+
+	return ranges, nil
+}
+
+// bloomMayMatch returns false if the bloom filter of stats shows that an
+// EqualPredicate or InPredicate can't be true for any value. It returns true
+// for other predicates, or if stats has no bloom filter.
+func bloomMayMatch(p Predicate, stats *datasetmd.Statistics) (bool, error) {
+	if stats == nil || len(stats.BloomFilter) == 0 {
+		return true, nil
+	}
+
+	switch p := p.(type) {
+	case EqualPredicate:
+		return bloomMayContain(stats.BloomFilter, p.Value)
+	case InPredicate:
+		return bloomMayContain(stats.BloomFilter, p.Values...)
+	default:
+		return true, nil
+	}
+}
+
+// pageMayMatch returns true if p may be true for a page with the provided
+// minimum and maximum values.
+func pageMayMatch(p Predicate, minValue, maxValue Value) bool {
+	var include bool
+
+	switch p := p.(type) {
+	case EqualPredicate: // EqualPredicate may be true if p.Value is inside the range of the page.
+		include = CompareValues(p.Value, minValue) >= 0 && CompareValues(p.Value, maxValue) <= 0
+	case GreaterThanPredicate: // GreaterThanPredicate may be true if maxValue of a page is greater than p.Value
+		include = CompareValues(maxValue, p.Value) > 0
+	case LessThanPredicate: // LessThanPredicate may be true if minValue of a page is less than p.Value
+		include = CompareValues(minValue, p.Value) < 0
+	case InPredicate:
+		// Check if any value falls within the page's range
+		for _, v := range p.Values {
+			if CompareValues(v, minValue) >= 0 && CompareValues(v, maxValue) <= 0 {
+				include = true
+				break
+			}
+		}
+	default:
+		panic(fmt.Sprintf("unsupported predicate type %T", p))
+	}
+
+	return include
+}
+
+// dictionaryPageRanges returns the ranges of rows of a dictionary-encoded page
+// for which p is true. Rather than decoding every value of the page, p is
+// evaluated once for each entry of the dictionary of the page, and rows are
+// then matched by their dictionary code.
+//
+// pageRange is the range of rows of page in c, and predicateColumn is the
+// column p refers to.
+func dictionaryPageRanges(ctx context.Context, c Column, page Page, pageRange rowRange, predicateColumn Column, p Predicate) (rowRanges, error) {
+	data, err := page.ReadPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	memPage := &MemPage{Info: *page.PageInfo(), Data: data}
+
+	presenceReader, valuesReader, err := memPage.reader(c.ColumnInfo().Compression)
+	if err != nil {
+		return nil, fmt.Errorf("opening page for reading: %w", err)
+	}
+	defer valuesReader.Close()
+
+	var (
+		presenceDec = newBitmapDecoder(bufio.NewReader(presenceReader))
+		valuesDec   = newDictionaryDecoder(bufio.NewReader(valuesReader))
+	)
+
+	dict, err := valuesDec.Dictionary()
+	if err != nil {
+		return nil, err
+	}
+
+	// p is evaluated with checkPredicate against rows holding a single value,
+	// so it has the same semantics as during reading.
+	var (
+		lookup = map[Column]int{predicateColumn: 0}
+		row    = Row{Values: make([]Value, 1)}
+
+		matches    = make([]bool, len(dict))
+		anyMatches bool
+	)
+	for code, entry := range dict {
+		row.Values[0] = ByteArrayValue(entry)
+		matches[code] = checkPredicate(p, lookup, row)
+		anyMatches = anyMatches || matches[code]
+	}
+	row.Values[0] = Value{}
+	nullMatches := checkPredicate(p, lookup, row)
+
+	switch {
+	case !anyMatches && !nullMatches:
+		return nil, nil
+	case nullMatches && !slices.Contains(matches, false):
+		return rowRanges{pageRange}, nil
+	}
+
+	var (
+		ranges rowRanges
+
+		presence = make([]Value, 1024)
+		codes    = make([]Value, 1024)
+
+		rowNumber  = pageRange.Start
+		matchStart uint64
+		matching   bool
+	)
+	for rowNumber <= pageRange.End {
+		count, err := presenceDec.Decode(presence[:min(len(presence), int(pageRange.End-rowNumber+1))])
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		} else if count == 0 {
+			break
+		}
+
+		var presentCount int
+		for _, v := range presence[:count] {
+			if v.Uint64() == 1 {
+				presentCount++
+			}
+		}
+		if presentCount > 0 {
+			if n, err := valuesDec.DecodeCodes(codes[:presentCount]); err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			} else if n != presentCount {
+				return nil, fmt.Errorf("unexpected number of values: %d", n)
+			}
+		}
+
+		var codeIndex int
+		for _, v := range presence[:count] {
+			match := nullMatches
+			if v.Uint64() == 1 {
+				match = matches[codes[codeIndex].Uint64()]
+				codeIndex++
+			}
+
+			switch {
+			case match && !matching:
+				matchStart, matching = rowNumber, true
+			case !match && matching:
+				ranges.Add(rowRange{Start: matchStart, End: rowNumber - 1})
+				matching = false
+			}
+			rowNumber++
+		}
+	}
+	if matching {
+		ranges.Add(rowRange{Start: matchStart, End: rowNumber - 1})
 	}
 
 	return ranges, nil
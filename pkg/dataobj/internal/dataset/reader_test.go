@@ -3,6 +3,7 @@ package dataset
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"slices"
 	"testing"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 )
 
 func Test_Reader_ReadAll(t *testing.T) {
@@ -84,6 +86,111 @@ func Test_Reader_ReadWithPageFiltering(t *testing.T) {
 	require.Equal(t, expected, convertToTestPersons(actualRows))
 }
 
+func Test_Reader_ReadWithRowRangesPredicate(t *testing.T) {
+	dset, columns := buildTestDataset(t)
+
+	ranges := []RowRange{
+		{Start: 1, End: 2},
+		{Start: 5, End: 5},
+	}
+
+	tt := []struct {
+		name      string
+		predicate Predicate
+	}{
+		{
+			name:      "only row ranges",
+			predicate: RowRangesPredicate{Ranges: ranges},
+		},
+		{
+			name: "row ranges with column predicate",
+			predicate: AndPredicate{
+				Left: RowRangesPredicate{Ranges: ranges},
+				Right: GreaterThanPredicate{
+					Column: columns[3], // birth_year column
+					Value:  Int64Value(0),
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewReader(ReaderOptions{
+				Dataset:   dset,
+				Columns:   columns,
+				Predicate: tc.predicate,
+			})
+			defer r.Close()
+
+			actualRows, err := readDataset(r, 3)
+			require.NoError(t, err)
+
+			expected := []testPerson{
+				basicReaderTestData[1],
+				basicReaderTestData[2],
+				basicReaderTestData[5],
+			}
+			require.Equal(t, expected, convertToTestPersons(actualRows))
+		})
+	}
+}
+
+func Test_Reader_PagesPruned(t *testing.T) {
+	dset, columns := buildTestDataset(t)
+
+	r := NewReader(ReaderOptions{
+		Dataset: dset,
+		Columns: columns,
+		Predicate: EqualPredicate{
+			Column: columns[0], // first_name column
+			Value:  ByteArrayValue([]byte("Henry")),
+		},
+	})
+	defer r.Close()
+
+	statistics, ctx := stats.NewContext(context.Background())
+	batch := make([]Row, len(basicReaderTestData))
+	for {
+		_, err := r.Read(ctx, batch)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	// Henry is only in range of the first and last page of the first_name
+	// column.
+	pages, err := result.Collect(columns[0].ListPages(ctx))
+	require.NoError(t, err)
+	require.Greater(t, len(pages), 2)
+	require.Equal(t, int64(len(pages)-2), statistics.PagesPruned())
+}
+
+func Test_Reader_Ranges(t *testing.T) {
+	dset, columns := buildTestDataset(t)
+
+	r := NewReader(ReaderOptions{
+		Dataset:   dset,
+		Columns:   columns,
+		Predicate: RowRangesPredicate{Ranges: []RowRange{{Start: 1, End: 2}, {Start: 5, End: 5}}},
+	})
+	defer r.Close()
+
+	ranges, err := r.Ranges(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []RowRange{{Start: 1, End: 2}, {Start: 5, End: 5}}, ranges)
+
+	// Calling Ranges before reading must not affect the rows that are read.
+	actualRows, err := readDataset(r, 3)
+	require.NoError(t, err)
+	require.Equal(t, []testPerson{
+		basicReaderTestData[1],
+		basicReaderTestData[2],
+		basicReaderTestData[5],
+	}, convertToTestPersons(actualRows))
+}
+
 func Test_Reader_ReadWithPredicate_NoSecondary(t *testing.T) {
 	dset, columns := buildTestDataset(t)
 
@@ -414,3 +521,177 @@ func encodeInt64Value(t *testing.T, v int64) []byte {
 	require.NoError(t, err)
 	return data
 }
+
+func Test_BuildPredicateRanges_Dictionary(t *testing.T) {
+	levels := []string{"info", "info", "warn", "", "error", "info", "warn", "warn", "", "info"}
+
+	b, err := NewColumnBuilder("level", BuilderOptions{
+		PageSizeHint: 1 << 20,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+		Encoding:     datasetmd.ENCODING_TYPE_DICTIONARY,
+		Statistics:   StatisticsOptions{StoreRangeStats: true},
+	})
+	require.NoError(t, err)
+	for i, level := range levels {
+		require.NoError(t, b.Append(i, ByteArrayValue([]byte(level))))
+	}
+	col, err := b.Flush()
+	require.NoError(t, err)
+	require.Len(t, col.Pages, 1)
+	require.Equal(t, datasetmd.ENCODING_TYPE_DICTIONARY, col.Pages[0].Info.Encoding)
+
+	ds := FromMemory([]*MemColumn{col})
+	cols, err := result.Collect(ds.ListColumns(context.Background()))
+	require.NoError(t, err)
+
+	tt := []struct {
+		name      string
+		predicate Predicate
+		want      rowRanges
+	}{
+		{
+			name:      "equal predicate",
+			predicate: EqualPredicate{Column: cols[0], Value: ByteArrayValue([]byte("warn"))},
+			want:      rowRanges{{Start: 2, End: 2}, {Start: 6, End: 7}},
+		},
+		{
+			name:      "equal predicate not in dictionary",
+			predicate: EqualPredicate{Column: cols[0], Value: ByteArrayValue([]byte("fatal"))},
+			want:      nil,
+		},
+		{
+			name: "in predicate",
+			predicate: InPredicate{Column: cols[0], Values: []Value{
+				ByteArrayValue([]byte("error")),
+				ByteArrayValue([]byte("warn")),
+			}},
+			want: rowRanges{{Start: 2, End: 2}, {Start: 4, End: 4}, {Start: 6, End: 7}},
+		},
+		{
+			name: "func predicate",
+			predicate: FuncPredicate{Column: cols[0], Keep: func(_ Column, value Value) bool {
+				return value.IsNil() || string(value.ByteArray()) == "info"
+			}},
+			want: rowRanges{{Start: 0, End: 1}, {Start: 3, End: 3}, {Start: 5, End: 5}, {Start: 8, End: 9}},
+		},
+		{
+			name: "func predicate matching all values",
+			predicate: FuncPredicate{Column: cols[0], Keep: func(_ Column, _ Value) bool {
+				return true
+			}},
+			want: rowRanges{{Start: 0, End: 9}},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewReader(ReaderOptions{
+				Dataset:   ds,
+				Columns:   cols,
+				Predicate: tc.predicate,
+			})
+			defer r.Close()
+
+			require.NoError(t, r.initDownloader(ctx))
+
+			got, err := r.buildPredicateRanges(ctx, tc.predicate)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+
+			// Reading with the predicate must return the rows of the ranges.
+			rows, err := readDataset(r, 3)
+			require.NoError(t, err)
+
+			var rowCount uint64
+			for _, rr := range tc.want {
+				rowCount += rr.End - rr.Start + 1
+			}
+			require.Len(t, rows, int(rowCount))
+			for _, row := range rows {
+				require.True(t, tc.want.Includes(uint64(row.Index)), "row %d", row.Index)
+			}
+		})
+	}
+}
+
+func Test_Reader_BloomFilterPruning(t *testing.T) {
+	// Trace IDs are spread over pages so that every page covers most of the
+	// range of values, and range stats can't prune pages.
+	var traceIDs []string
+	for i := range 1000 {
+		traceIDs = append(traceIDs, fmt.Sprintf("trace-%04d", (i*7919)%1000))
+	}
+
+	b, err := NewColumnBuilder("trace_id", BuilderOptions{
+		PageSizeHint: 1024,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+		Statistics: StatisticsOptions{
+			StoreRangeStats:  true,
+			StoreBloomFilter: true,
+		},
+	})
+	require.NoError(t, err)
+	for i, traceID := range traceIDs {
+		require.NoError(t, b.Append(i, ByteArrayValue([]byte(traceID))))
+	}
+	col, err := b.Flush()
+	require.NoError(t, err)
+	require.Greater(t, len(col.Pages), 2)
+	require.NotEmpty(t, col.Info.Statistics.BloomFilter)
+
+	dset := FromMemory([]*MemColumn{col})
+	columns, err := result.Collect(dset.ListColumns(context.Background()))
+	require.NoError(t, err)
+
+	t.Run("value in one page", func(t *testing.T) {
+		r := NewReader(ReaderOptions{
+			Dataset:   dset,
+			Columns:   columns,
+			Predicate: EqualPredicate{Column: columns[0], Value: ByteArrayValue([]byte(traceIDs[500]))},
+		})
+		defer r.Close()
+
+		statistics, ctx := stats.NewContext(context.Background())
+
+		var rows []Row
+		batch := make([]Row, 10)
+		for {
+			n, err := r.Read(ctx, batch)
+			rows = append(rows, batch[:n]...)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		require.Len(t, rows, 1)
+		require.Equal(t, 500, rows[0].Index)
+
+		// Bloom filters may have false positives, so we only check that most
+		// pages were pruned.
+		require.GreaterOrEqual(t, statistics.PagesPruned(), int64(len(col.Pages)-2))
+	})
+
+	t.Run("value not in column", func(t *testing.T) {
+		predicate := InPredicate{Column: columns[0], Values: []Value{
+			ByteArrayValue([]byte("trace-1001")),
+			ByteArrayValue([]byte("trace-1002")),
+		}}
+		r := NewReader(ReaderOptions{
+			Dataset:   dset,
+			Columns:   columns,
+			Predicate: predicate,
+		})
+		defer r.Close()
+
+		ctx := context.Background()
+		require.NoError(t, r.initDownloader(ctx))
+
+		got, err := r.buildPredicateRanges(ctx, predicate)
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+}
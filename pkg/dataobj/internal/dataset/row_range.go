@@ -9,6 +9,10 @@ type rowRange struct {
 	Start, End uint64
 }
 
+// RowRange is an inclusive range of rows [Start, End], exposed for callers
+// outside of the package such as external row indexes.
+type RowRange = rowRange
+
 // String prints out the row range in a human-readable format
 // "[rr.Start,rr.End]".
 func (rr rowRange) String() string {
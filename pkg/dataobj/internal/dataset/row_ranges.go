@@ -219,6 +219,13 @@ func intersectRanges(dst rowRanges, a, b rowRanges) rowRanges {
 	return dst
 }
 
+// IntersectRowRanges returns the ranges of rows that are in both a and b. a
+// and b must be sorted by their Start row and must not have overlapping
+// ranges.
+func IntersectRowRanges(a, b []RowRange) []RowRange {
+	return intersectRanges(nil, a, b)
+}
+
 // unionRanges appends the union of two sets of ranges into dst,
 // returning the result.
 //
@@ -5,6 +5,7 @@ import (
 	"cmp"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"unsafe"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
@@ -66,6 +67,23 @@ func Uint64Value(v uint64) Value {
 	}
 }
 
+// Float64Value returns a [Value] for a float64.
+func Float64Value(v float64) Value {
+	return Value{
+		num: math.Float64bits(v),
+		any: datasetmd.VALUE_TYPE_FLOAT64,
+	}
+}
+
+// TimestampValue returns a [Value] for a timestamp, expressed as the number of
+// nanoseconds since the Unix epoch.
+func TimestampValue(v int64) Value {
+	return Value{
+		num: uint64(v),
+		any: datasetmd.VALUE_TYPE_TIMESTAMP,
+	}
+}
+
 // ByteArrayValue returns a [Value] for a byte slice representing a string.
 func ByteArrayValue(v []byte) Value {
 	return Value{
@@ -122,6 +140,24 @@ func (v *Value) Uint64() uint64 {
 	return v.num
 }
 
+// Float64 returns v's value as a float64. It panics if v is not a
+// [datasetmd.VALUE_TYPE_FLOAT64].
+func (v *Value) Float64() float64 {
+	if expect, actual := datasetmd.VALUE_TYPE_FLOAT64, v.Type(); expect != actual {
+		panic(fmt.Sprintf("dataset.Value type is %s, not %s", actual, expect))
+	}
+	return math.Float64frombits(v.num)
+}
+
+// Timestamp returns v's value as the number of nanoseconds since the Unix
+// epoch. It panics if v is not a [datasetmd.VALUE_TYPE_TIMESTAMP].
+func (v *Value) Timestamp() int64 {
+	if expect, actual := datasetmd.VALUE_TYPE_TIMESTAMP, v.Type(); expect != actual {
+		panic(fmt.Sprintf("dataset.Value type is %s, not %s", actual, expect))
+	}
+	return int64(v.num)
+}
+
 // ByteSlice returns v's value as a byte slice. If v is not a string,
 // ByteSlice returns a byte slice of the form "VALUE_TYPE_T", where T is the
 // underlying type of v.
@@ -192,6 +228,9 @@ func (v *Value) Zero() {
 //
 //   - [datasetmd.VALUE_TYPE_INT64] encodes as a varint.
 //   - [datasetmd.VALUE_TYPE_UINT64] encodes as a uvarint.
+//   - [datasetmd.VALUE_TYPE_FLOAT64] encodes as 8 little-endian bytes of its
+//     IEEE 754 representation.
+//   - [datasetmd.VALUE_TYPE_TIMESTAMP] encodes as a varint.
 //   - [datasetmd.VALUE_TYPE_STRING] encodes the string as a sequence of bytes.
 //
 // NULL values encode as nil.
@@ -207,6 +246,10 @@ func (v Value) MarshalBinary() (data []byte, err error) {
 		buf = binary.AppendVarint(buf, v.Int64())
 	case datasetmd.VALUE_TYPE_UINT64:
 		buf = binary.AppendUvarint(buf, v.Uint64())
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float64()))
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		buf = binary.AppendVarint(buf, v.Timestamp())
 	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
 		buf = append(buf, v.ByteArray()...)
 	default:
@@ -242,6 +285,17 @@ func (v *Value) UnmarshalBinary(data []byte) error {
 			return fmt.Errorf("dataset.Value.UnmarshalBinary: invalid uint64 value")
 		}
 		*v = Uint64Value(val)
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		if len(data[n:]) != 8 {
+			return fmt.Errorf("dataset.Value.UnmarshalBinary: invalid float64 value")
+		}
+		*v = Float64Value(math.Float64frombits(binary.LittleEndian.Uint64(data[n:])))
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		val, n := binary.Varint(data[n:])
+		if n <= 0 {
+			return fmt.Errorf("dataset.Value.UnmarshalBinary: invalid timestamp value")
+		}
+		*v = TimestampValue(val)
 	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
 		*v = ByteArrayValue(data[n:])
 	default:
@@ -277,6 +331,10 @@ func CompareValues(a, b Value) int {
 		return cmp.Compare(a.Int64(), b.Int64())
 	case datasetmd.VALUE_TYPE_UINT64:
 		return cmp.Compare(a.Uint64(), b.Uint64())
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		return cmp.Compare(a.Float64(), b.Float64())
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		return cmp.Compare(a.Timestamp(), b.Timestamp())
 	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
 		return bytes.Compare(a.ByteArray(), b.ByteArray())
 	default:
@@ -290,6 +348,10 @@ func (v Value) Size() int {
 		return int(unsafe.Sizeof(int64(0)))
 	case datasetmd.VALUE_TYPE_UINT64:
 		return int(unsafe.Sizeof(uint64(0)))
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		return int(unsafe.Sizeof(float64(0)))
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		return int(unsafe.Sizeof(int64(0)))
 	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
 		return int(v.num)
 	case datasetmd.VALUE_TYPE_UNSPECIFIED:
@@ -17,11 +17,21 @@ func init() {
 		func(w streamio.Writer) valueEncoder { return newDeltaEncoder(w) },
 		func(r streamio.Reader) valueDecoder { return newDeltaDecoder(r) },
 	)
+
+	// Timestamps are encoded the same way as int64s.
+	registerValueEncoding(
+		datasetmd.VALUE_TYPE_TIMESTAMP,
+		datasetmd.ENCODING_TYPE_DELTA,
+		func(w streamio.Writer) valueEncoder { return newTimestampDeltaEncoder(w) },
+		func(r streamio.Reader) valueDecoder { return newTimestampDeltaDecoder(r) },
+	)
 }
 
-// deltaEncoder encodes delta-encoded int64s. Values are encoded as varint,
-// with each subsequent value being the delta from the previous value.
+// deltaEncoder encodes delta-encoded int64s or timestamps. Values are encoded
+// as varint, with each subsequent value being the delta from the previous
+// value.
 type deltaEncoder struct {
+	typ  datasetmd.ValueType
 	w    streamio.Writer
 	prev int64
 }
@@ -30,14 +40,23 @@ var _ valueEncoder = (*deltaEncoder)(nil)
 
 // newDeltaEncoder creates a deltaEncoder that writes encoded numbers to w.
 func newDeltaEncoder(w streamio.Writer) *deltaEncoder {
-	var enc deltaEncoder
+	enc := deltaEncoder{typ: datasetmd.VALUE_TYPE_INT64}
 	enc.Reset(w)
 	return &enc
 }
 
-// ValueType returns [datasetmd.VALUE_TYPE_INT64].
+// newTimestampDeltaEncoder creates a deltaEncoder that writes encoded
+// timestamps to w.
+func newTimestampDeltaEncoder(w streamio.Writer) *deltaEncoder {
+	enc := deltaEncoder{typ: datasetmd.VALUE_TYPE_TIMESTAMP}
+	enc.Reset(w)
+	return &enc
+}
+
+// ValueType returns [datasetmd.VALUE_TYPE_INT64] or
+// [datasetmd.VALUE_TYPE_TIMESTAMP].
 func (enc *deltaEncoder) ValueType() datasetmd.ValueType {
-	return datasetmd.VALUE_TYPE_INT64
+	return enc.typ
 }
 
 // EncodingType returns [datasetmd.ENCODING_TYPE_DELTA].
@@ -47,10 +66,15 @@ func (enc *deltaEncoder) EncodingType() datasetmd.EncodingType {
 
 // Encode encodes a new value.
 func (enc *deltaEncoder) Encode(v Value) error {
-	if v.Type() != datasetmd.VALUE_TYPE_INT64 {
-		return fmt.Errorf("delta: invalid value type %v", v.Type())
+	var iv int64
+	switch ty := v.Type(); {
+	case ty != enc.typ:
+		return fmt.Errorf("delta: invalid value type %v", ty)
+	case ty == datasetmd.VALUE_TYPE_TIMESTAMP:
+		iv = v.Timestamp()
+	default:
+		iv = v.Int64()
 	}
-	iv := v.Int64()
 
 	delta := iv - enc.prev
 	enc.prev = iv
@@ -68,9 +92,11 @@ func (enc *deltaEncoder) Reset(w streamio.Writer) {
 	enc.w = w
 }
 
-// deltaDecoder decodes delta-encoded numbers. Values are decoded as varint,
-// with each subsequent value being the delta from the previous value.
+// deltaDecoder decodes delta-encoded numbers or timestamps. Values are
+// decoded as varint, with each subsequent value being the delta from the
+// previous value.
 type deltaDecoder struct {
+	typ  datasetmd.ValueType
 	r    streamio.Reader
 	prev int64
 }
@@ -79,14 +105,23 @@ var _ valueDecoder = (*deltaDecoder)(nil)
 
 // newDeltaDecoder creates a deltaDecoder that reads encoded numbers from r.
 func newDeltaDecoder(r streamio.Reader) *deltaDecoder {
-	var dec deltaDecoder
+	dec := deltaDecoder{typ: datasetmd.VALUE_TYPE_INT64}
+	dec.Reset(r)
+	return &dec
+}
+
+// newTimestampDeltaDecoder creates a deltaDecoder that reads encoded
+// timestamps from r.
+func newTimestampDeltaDecoder(r streamio.Reader) *deltaDecoder {
+	dec := deltaDecoder{typ: datasetmd.VALUE_TYPE_TIMESTAMP}
 	dec.Reset(r)
 	return &dec
 }
 
-// ValueType returns [datasetmd.VALUE_TYPE_INT64].
+// ValueType returns [datasetmd.VALUE_TYPE_INT64] or
+// [datasetmd.VALUE_TYPE_TIMESTAMP].
 func (dec *deltaDecoder) ValueType() datasetmd.ValueType {
-	return datasetmd.VALUE_TYPE_INT64
+	return dec.typ
 }
 
 // Type returns [datasetmd.ENCODING_TYPE_DELTA].
@@ -120,15 +155,17 @@ func (dec *deltaDecoder) Decode(s []Value) (int, error) {
 	return len(s), nil
 }
 
-// decode reads the next uint64 value from the stream.
+// decode reads the next value from the stream.
 func (dec *deltaDecoder) decode() (Value, error) {
 	delta, err := streamio.ReadVarint(dec.r)
-	if err != nil {
-		return Int64Value(dec.prev), err
+	if err == nil {
+		dec.prev += delta
 	}
 
-	dec.prev += delta
-	return Int64Value(dec.prev), nil
+	if dec.typ == datasetmd.VALUE_TYPE_TIMESTAMP {
+		return TimestampValue(dec.prev), err
+	}
+	return Int64Value(dec.prev), err
 }
 
 // Reset resets the deltaDecoder to its initial state.
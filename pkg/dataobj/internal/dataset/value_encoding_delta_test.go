@@ -48,6 +48,41 @@ func Test_delta(t *testing.T) {
 	require.Equal(t, numbers, actual)
 }
 
+func Test_delta_timestamps(t *testing.T) {
+	timestamps := []int64{
+		1735732800000000000,
+		1735732800000000500,
+		1735732799000000000,
+	}
+
+	var buf bytes.Buffer
+
+	var (
+		enc    = newTimestampDeltaEncoder(&buf)
+		dec    = newTimestampDeltaDecoder(&buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, ts := range timestamps {
+		require.NoError(t, enc.Encode(TimestampValue(ts)))
+	}
+	require.Error(t, enc.Encode(Int64Value(1)), "int64 values can't be written to timestamp columns")
+
+	var actual []int64
+	for {
+		n, err := dec.Decode(decBuf[:batchSize])
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		for _, v := range decBuf[:n] {
+			actual = append(actual, v.Timestamp())
+		}
+	}
+
+	require.Equal(t, timestamps, actual)
+}
+
 func Fuzz_delta(f *testing.F) {
 	f.Add(int64(775972800), 10)
 	f.Add(int64(758350800), 25)
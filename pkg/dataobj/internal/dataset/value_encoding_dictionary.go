@@ -0,0 +1,304 @@
+package dataset
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"slices"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/slicegrow"
+)
+
+func init() {
+	// Register the encoding so instances of it can be dynamically created.
+	registerValueEncoding(
+		datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		datasetmd.ENCODING_TYPE_DICTIONARY,
+		func(w streamio.Writer) valueEncoder { return newDictionaryEncoder(w) },
+		func(r streamio.Reader) valueDecoder { return newDictionaryDecoder(r) },
+	)
+}
+
+// A dictionaryEncoder encodes byte array values to an [streamio.Writer] with
+// dictionary encoding. Dictionary encoding is efficient for sequences of
+// values with a low cardinality, such as log levels or namespaces.
+//
+// # Format
+//
+// The distinct values are written once, in order of first appearance, and are
+// followed by the bitmap-encoded index of each value in the dictionary (its
+// code). The EBNF grammar is as follows:
+//
+//	dictionary_data = dictionary codes;
+//	dictionary      = dictionary_len entry*;
+//	dictionary_len  = (* uvarint(number of entries) *)
+//	entry           = entry_len entry_value;
+//	entry_len       = (* uvarint(len(entry_value)) *)
+//	codes           = (* bitmap of the code of each value *)
+//
+// Because the dictionary is written before the codes, values are buffered in
+// memory until [dictionaryEncoder.Flush] is called.
+type dictionaryEncoder struct {
+	w        streamio.Writer
+	codesEnc *bitmapEncoder
+
+	lookup map[string]uint64 // Code of each entry in dict.
+	dict   []string          // Distinct values in order of first appearance.
+	codes  []uint64          // Code of each encoded value.
+
+	dictSize int // Encoded size of dict in bytes.
+}
+
+var _ valueEncoder = (*dictionaryEncoder)(nil)
+
+// newDictionaryEncoder creates a dictionaryEncoder that writes encoded byte
+// arrays to w.
+func newDictionaryEncoder(w streamio.Writer) *dictionaryEncoder {
+	return &dictionaryEncoder{
+		w:        w,
+		codesEnc: newBitmapEncoder(w),
+		lookup:   make(map[string]uint64),
+	}
+}
+
+// ValueType returns [datasetmd.VALUE_TYPE_BYTE_ARRAY].
+func (enc *dictionaryEncoder) ValueType() datasetmd.ValueType {
+	return datasetmd.VALUE_TYPE_BYTE_ARRAY
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_DICTIONARY].
+func (enc *dictionaryEncoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_DICTIONARY
+}
+
+// Encode encodes an individual byte array value.
+func (enc *dictionaryEncoder) Encode(v Value) error {
+	if v.Type() != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+		return fmt.Errorf("dictionary: invalid value type %v", v.Type())
+	}
+	arr := v.ByteArray()
+
+	code, ok := enc.lookup[string(arr)]
+	if !ok {
+		code = uint64(len(enc.dict))
+
+		entry := string(arr)
+		enc.lookup[entry] = code
+		enc.dict = append(enc.dict, entry)
+		enc.dictSize += streamio.UvarintSize(uint64(len(entry))) + len(entry)
+	}
+	enc.codes = append(enc.codes, code)
+	return nil
+}
+
+// Cardinality returns the number of distinct values buffered in enc.
+func (enc *dictionaryEncoder) Cardinality() int { return len(enc.dict) }
+
+// BufferedSize returns the estimated size in bytes of the values buffered in
+// enc.
+func (enc *dictionaryEncoder) BufferedSize() int {
+	if len(enc.codes) == 0 {
+		return 0
+	}
+	width := max(1, bits.Len64(uint64(len(enc.dict)-1)))
+	return streamio.UvarintSize(uint64(len(enc.dict))) + enc.dictSize + (len(enc.codes)*width+7)/8
+}
+
+// EncodeTo encodes the values buffered in enc with another encoder, and
+// discards them from enc.
+func (enc *dictionaryEncoder) EncodeTo(other valueEncoder) error {
+	for _, code := range enc.codes {
+		if err := other.Encode(ByteArrayValue([]byte(enc.dict[code]))); err != nil {
+			return err
+		}
+	}
+	enc.reset()
+	return nil
+}
+
+// Flush writes the dictionary and the codes of the buffered values to the
+// underlying [streamio.Writer].
+func (enc *dictionaryEncoder) Flush() error {
+	if len(enc.codes) == 0 {
+		return nil
+	}
+
+	if err := streamio.WriteUvarint(enc.w, uint64(len(enc.dict))); err != nil {
+		return err
+	}
+	for _, entry := range enc.dict {
+		if err := streamio.WriteUvarint(enc.w, uint64(len(entry))); err != nil {
+			return err
+		}
+		if n, err := io.WriteString(enc.w, entry); err != nil {
+			return err
+		} else if n != len(entry) {
+			return fmt.Errorf("short write; expected %d bytes, wrote %d", len(entry), n)
+		}
+	}
+
+	for _, code := range enc.codes {
+		if err := enc.codesEnc.Encode(Uint64Value(code)); err != nil {
+			return err
+		}
+	}
+	if err := enc.codesEnc.Flush(); err != nil {
+		return err
+	}
+
+	enc.reset()
+	return nil
+}
+
+// Reset implements [valueEncoder]. It resets the encoder to write to w.
+func (enc *dictionaryEncoder) Reset(w streamio.Writer) {
+	enc.w = w
+	enc.codesEnc.Reset(w)
+	enc.reset()
+}
+
+func (enc *dictionaryEncoder) reset() {
+	clear(enc.lookup)
+	enc.dict = enc.dict[:0]
+	enc.codes = enc.codes[:0]
+	enc.dictSize = 0
+}
+
+// dictionaryDecoder decodes dictionary-encoded byte arrays from an
+// [streamio.Reader].
+type dictionaryDecoder struct {
+	r        streamio.Reader
+	codesDec *bitmapDecoder
+
+	ready bool     // Whether the dictionary has been read.
+	dict  [][]byte // Entries of the dictionary, by code.
+	buf   []byte   // Memory backing the entries of dict.
+
+	codes []Value
+}
+
+var _ valueDecoder = (*dictionaryDecoder)(nil)
+
+// newDictionaryDecoder creates a dictionaryDecoder that reads
+// dictionary-encoded byte arrays from r.
+func newDictionaryDecoder(r streamio.Reader) *dictionaryDecoder {
+	return &dictionaryDecoder{
+		r:        r,
+		codesDec: newBitmapDecoder(r),
+	}
+}
+
+// ValueType returns [datasetmd.VALUE_TYPE_BYTE_ARRAY].
+func (dec *dictionaryDecoder) ValueType() datasetmd.ValueType {
+	return datasetmd.VALUE_TYPE_BYTE_ARRAY
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_DICTIONARY].
+func (dec *dictionaryDecoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_DICTIONARY
+}
+
+// Decode decodes up to len(s) values, storing the results into s. The
+// number of decoded values is returned, followed by an error (if any).
+// At the end of the stream, Decode returns 0, [io.EOF].
+func (dec *dictionaryDecoder) Decode(s []Value) (int, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	dec.codes = slicegrow.GrowToCap(dec.codes, len(s))
+	dec.codes = dec.codes[:len(s)]
+
+	n, err := dec.DecodeCodes(dec.codes)
+	for i, code := range dec.codes[:n] {
+		entry := dec.dict[code.Uint64()]
+
+		dst := s[i].Buffer(len(entry))
+		dst = dst[:len(entry)]
+		copy(dst, entry)
+		s[i].SetByteArrayValue(dst)
+	}
+	return n, err
+}
+
+// Dictionary returns the entries of the dictionary, indexed by code. The
+// returned slices must not be modified.
+func (dec *dictionaryDecoder) Dictionary() ([][]byte, error) {
+	if !dec.ready {
+		if err := dec.readDictionary(); err != nil {
+			return nil, err
+		}
+	}
+	return dec.dict, nil
+}
+
+// DecodeCodes decodes the codes of up to len(s) values as
+// [datasetmd.VALUE_TYPE_UINT64] values, without looking up their entry in the
+// dictionary. The number of decoded codes is returned, followed by an error
+// (if any). At the end of the stream, DecodeCodes returns 0, [io.EOF].
+func (dec *dictionaryDecoder) DecodeCodes(s []Value) (int, error) {
+	dict, err := dec.Dictionary()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := dec.codesDec.Decode(s)
+	for i := range s[:n] {
+		if code := s[i].Uint64(); code >= uint64(len(dict)) {
+			return i, fmt.Errorf("dictionary: code %d out of range for %d entries", code, len(dict))
+		}
+	}
+	return n, err
+}
+
+func (dec *dictionaryDecoder) readDictionary() error {
+	count, err := binary.ReadUvarint(dec.r)
+	if errors.Is(err, io.EOF) {
+		// Pages without values have an empty dictionary.
+		dec.ready = true
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading dictionary size: %w", err)
+	}
+
+	dec.buf = dec.buf[:0]
+	offsets := make([]int, 0, min(count, 1024)+1)
+	for range count {
+		sz, err := binary.ReadUvarint(dec.r)
+		if err != nil {
+			return fmt.Errorf("reading dictionary entry size: %w", err)
+		}
+
+		offsets = append(offsets, len(dec.buf))
+		dec.buf = slices.Grow(dec.buf, int(sz))
+		entry := dec.buf[len(dec.buf) : len(dec.buf)+int(sz)]
+		if _, err := io.ReadFull(dec.r, entry); err != nil {
+			return fmt.Errorf("reading dictionary entry: %w", err)
+		}
+		dec.buf = dec.buf[:len(dec.buf)+int(sz)]
+	}
+	offsets = append(offsets, len(dec.buf))
+
+	// Entries are only sliced once all of them were read, as dec.buf may be
+	// reallocated while reading.
+	dec.dict = slicegrow.GrowToCap(dec.dict, int(count))
+	dec.dict = dec.dict[:count]
+	for i := range dec.dict {
+		dec.dict[i] = dec.buf[offsets[i]:offsets[i+1]:offsets[i+1]]
+	}
+
+	dec.ready = true
+	return nil
+}
+
+// Reset implements [valueDecoder]. It resets the decoder to read from r.
+func (dec *dictionaryDecoder) Reset(r streamio.Reader) {
+	dec.r = r
+	dec.codesDec.Reset(r)
+	dec.ready = false
+	dec.dict = dec.dict[:0]
+}
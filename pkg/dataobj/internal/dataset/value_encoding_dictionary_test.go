@@ -0,0 +1,229 @@
+package dataset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
+)
+
+var testDictionaryStrings = []string{
+	"debug",
+	"info",
+	"info",
+	"warn",
+	"info",
+	"error",
+	"debug",
+	"info",
+}
+
+func Test_dictionaryEncoder(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.Equal(t, 4, enc.Cardinality())
+	require.NoError(t, enc.Flush())
+
+	var out []string
+
+	for {
+		n, err := dec.Decode(decBuf[:batchSize])
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, string(v.ByteArray()))
+		}
+	}
+
+	require.Equal(t, testDictionaryStrings, out)
+}
+
+func Test_dictionaryEncoder_partialRead(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&oneByteReader{&buf})
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(t, enc.Flush())
+
+	var out []string
+
+	for {
+		n, err := dec.Decode(decBuf[:batchSize])
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, string(v.ByteArray()))
+		}
+	}
+
+	require.Equal(t, testDictionaryStrings, out)
+}
+
+func Test_dictionaryEncoder_reusingValues(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(t, enc.Flush())
+
+	for i := range decBuf {
+		decBuf[i] = ByteArrayValue(make([]byte, 64))
+	}
+
+	var out []string
+
+	for {
+		n, err := dec.Decode(decBuf[:batchSize])
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, string(v.ByteArray()))
+		}
+	}
+
+	require.Equal(t, testDictionaryStrings, out)
+}
+
+func Test_dictionaryDecoder_DecodeCodes(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(t, enc.Flush())
+
+	dict, err := dec.Dictionary()
+	require.NoError(t, err)
+
+	var entries []string
+	for _, entry := range dict {
+		entries = append(entries, string(entry))
+	}
+	require.Equal(t, []string{"debug", "info", "warn", "error"}, entries)
+
+	n, err := dec.DecodeCodes(decBuf[:batchSize])
+	if err != nil && !errors.Is(err, io.EOF) {
+		t.Fatal(err)
+	}
+
+	var codes []uint64
+	for _, v := range decBuf[:n] {
+		codes = append(codes, v.Uint64())
+	}
+	require.Equal(t, []uint64{0, 1, 1, 2, 1, 3, 0, 1}, codes)
+}
+
+func Test_dictionaryEncoder_EncodeTo(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(streamio.Discard)
+		plain  = newPlainBytesEncoder(&buf)
+		dec    = newPlainBytesDecoder(&buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(t, enc.EncodeTo(plain))
+	require.Equal(t, 0, enc.Cardinality())
+	require.Equal(t, 0, enc.BufferedSize())
+
+	var out []string
+
+	for {
+		n, err := dec.Decode(decBuf[:batchSize])
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, string(v.ByteArray()))
+		}
+	}
+
+	require.Equal(t, testDictionaryStrings, out)
+}
+
+func Benchmark_dictionaryEncoder_Append(b *testing.B) {
+	enc := newDictionaryEncoder(streamio.Discard)
+
+	for i := 0; i < b.N; i++ {
+		for _, v := range testDictionaryStrings {
+			_ = enc.Encode(ByteArrayValue([]byte(v)))
+		}
+		_ = enc.Flush()
+	}
+}
+
+func Benchmark_dictionaryDecoder_Decode(b *testing.B) {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024)) // Large enough to avoid reallocations.
+
+	var (
+		enc    = newDictionaryEncoder(buf)
+		dec    = newDictionaryDecoder(buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(b, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(b, enc.Flush())
+
+	var err error
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for {
+			_, err = dec.Decode(decBuf[:batchSize])
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
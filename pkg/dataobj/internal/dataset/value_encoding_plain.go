@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
@@ -18,6 +19,12 @@ func init() {
 		func(w streamio.Writer) valueEncoder { return newPlainBytesEncoder(w) },
 		func(r streamio.Reader) valueDecoder { return newPlainBytesDecoder(r) },
 	)
+	registerValueEncoding(
+		datasetmd.VALUE_TYPE_FLOAT64,
+		datasetmd.ENCODING_TYPE_PLAIN,
+		func(w streamio.Writer) valueEncoder { return newPlainFloat64Encoder(w) },
+		func(r streamio.Reader) valueDecoder { return newPlainFloat64Decoder(r) },
+	)
 }
 
 // A plainBytesEncoder encodes byte array values to an [streamio.Writer].
@@ -137,3 +144,100 @@ func (dec *plainBytesDecoder) decode(v *Value) error {
 func (dec *plainBytesDecoder) Reset(r streamio.Reader) {
 	dec.r = r
 }
+
+// A plainFloat64Encoder encodes float64 values to an [streamio.Writer]. Each
+// value is written as the 8 little-endian bytes of its IEEE 754
+// representation.
+type plainFloat64Encoder struct {
+	w   streamio.Writer
+	buf [8]byte
+}
+
+var _ valueEncoder = (*plainFloat64Encoder)(nil)
+
+// newPlainFloat64Encoder creates a plainFloat64Encoder that writes encoded
+// floats to w.
+func newPlainFloat64Encoder(w streamio.Writer) *plainFloat64Encoder {
+	return &plainFloat64Encoder{w: w}
+}
+
+// ValueType returns [datasetmd.VALUE_TYPE_FLOAT64].
+func (enc *plainFloat64Encoder) ValueType() datasetmd.ValueType {
+	return datasetmd.VALUE_TYPE_FLOAT64
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_PLAIN].
+func (enc *plainFloat64Encoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_PLAIN
+}
+
+// Encode encodes an individual float64 value.
+func (enc *plainFloat64Encoder) Encode(v Value) error {
+	if v.Type() != datasetmd.VALUE_TYPE_FLOAT64 {
+		return fmt.Errorf("plain: invalid value type %v", v.Type())
+	}
+
+	binary.LittleEndian.PutUint64(enc.buf[:], math.Float64bits(v.Float64()))
+	n, err := enc.w.Write(enc.buf[:])
+	if err == nil && n != len(enc.buf) {
+		return fmt.Errorf("short write; expected %d bytes, wrote %d", len(enc.buf), n)
+	}
+	return err
+}
+
+// Flush implements [valueEncoder]. It is a no-op for plainFloat64Encoder.
+func (enc *plainFloat64Encoder) Flush() error {
+	return nil
+}
+
+// Reset implements [valueEncoder]. It resets the encoder to write to w.
+func (enc *plainFloat64Encoder) Reset(w streamio.Writer) {
+	enc.w = w
+}
+
+// plainFloat64Decoder decodes float64 values from an [streamio.Reader].
+type plainFloat64Decoder struct {
+	r   streamio.Reader
+	buf [8]byte
+}
+
+var _ valueDecoder = (*plainFloat64Decoder)(nil)
+
+// newPlainFloat64Decoder creates a plainFloat64Decoder that reads encoded
+// floats from r.
+func newPlainFloat64Decoder(r streamio.Reader) *plainFloat64Decoder {
+	return &plainFloat64Decoder{r: r}
+}
+
+// ValueType returns [datasetmd.VALUE_TYPE_FLOAT64].
+func (dec *plainFloat64Decoder) ValueType() datasetmd.ValueType {
+	return datasetmd.VALUE_TYPE_FLOAT64
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_PLAIN].
+func (dec *plainFloat64Decoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_PLAIN
+}
+
+// Decode decodes up to len(s) values, storing the results into s. The
+// number of decoded values is returned, followed by an error (if any).
+// At the end of the stream, Decode returns 0, [io.EOF].
+func (dec *plainFloat64Decoder) Decode(s []Value) (int, error) {
+	for i := range s {
+		if _, err := io.ReadFull(dec.r, dec.buf[:]); errors.Is(err, io.EOF) {
+			if i == 0 {
+				return 0, io.EOF
+			}
+			return i, nil
+		} else if err != nil {
+			return i, err
+		}
+		s[i] = Float64Value(math.Float64frombits(binary.LittleEndian.Uint64(dec.buf[:])))
+	}
+	return len(s), nil
+}
+
+// Reset implements [valueDecoder]. It resets the decoder to read from r.
+func (dec *plainFloat64Decoder) Reset(r streamio.Reader) {
+	dec.r = r
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -51,6 +52,39 @@ func Test_plainBytesEncoder(t *testing.T) {
 	require.Equal(t, testStrings, out)
 }
 
+func Test_plainFloat64Encoder(t *testing.T) {
+	numbers := []float64{1.5, -0.25, 1e300, 42, math.SmallestNonzeroFloat64}
+
+	var buf bytes.Buffer
+
+	var (
+		enc    = newPlainFloat64Encoder(&buf)
+		dec    = newPlainFloat64Decoder(&oneByteReader{&buf})
+		decBuf = make([]Value, 2)
+	)
+
+	for _, num := range numbers {
+		require.NoError(t, enc.Encode(Float64Value(num)))
+	}
+	require.Error(t, enc.Encode(Int64Value(1)))
+
+	var out []float64
+
+	for {
+		n, err := dec.Decode(decBuf)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, v.Float64())
+		}
+	}
+
+	require.Equal(t, numbers, out)
+}
+
 func Test_plainBytesEncoder_partialRead(t *testing.T) {
 	var buf bytes.Buffer
 
@@ -48,6 +48,32 @@ func TestValue_MarshalBinary(t *testing.T) {
 		require.Equal(t, expect.Uint64(), actual.Uint64())
 	})
 
+	t.Run("Float64Value", func(t *testing.T) {
+		expect := dataset.Float64Value(-12.75)
+		require.Equal(t, datasetmd.VALUE_TYPE_FLOAT64, expect.Type())
+
+		b, err := expect.MarshalBinary()
+		require.NoError(t, err)
+
+		var actual dataset.Value
+		require.NoError(t, actual.UnmarshalBinary(b))
+		require.Equal(t, datasetmd.VALUE_TYPE_FLOAT64, actual.Type())
+		require.Equal(t, expect.Float64(), actual.Float64())
+	})
+
+	t.Run("TimestampValue", func(t *testing.T) {
+		expect := dataset.TimestampValue(1735732800123456789)
+		require.Equal(t, datasetmd.VALUE_TYPE_TIMESTAMP, expect.Type())
+
+		b, err := expect.MarshalBinary()
+		require.NoError(t, err)
+
+		var actual dataset.Value
+		require.NoError(t, actual.UnmarshalBinary(b))
+		require.Equal(t, datasetmd.VALUE_TYPE_TIMESTAMP, actual.Type())
+		require.Equal(t, expect.Timestamp(), actual.Timestamp())
+	})
+
 	t.Run("ByteArrayValue", func(t *testing.T) {
 		t.Run("Empty", func(t *testing.T) {
 			expect := dataset.ByteArrayValue([]byte{})
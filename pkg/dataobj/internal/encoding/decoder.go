@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/streamsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
 )
 
@@ -19,11 +20,18 @@ type (
 		// Sections returns the list of sections within a data object.
 		Sections(ctx context.Context) ([]*filemd.SectionInfo, error)
 
+		// KafkaOffsets returns the Kafka records the data object was built
+		// from, or nil if the data object doesn't record them.
+		KafkaOffsets(ctx context.Context) (*filemd.KafkaOffsets, error)
+
 		// StreamsDecoder returns a decoder for streams sections.
 		StreamsDecoder() StreamsDecoder
 
 		// LogsDecoder returns a decoder for logs sections.
 		LogsDecoder() LogsDecoder
+
+		// TokensDecoder returns a decoder for tokens sections.
+		TokensDecoder() TokensDecoder
 	}
 
 	// StreamsDecoder supports decoding data within a streams section.
@@ -59,4 +67,21 @@ type (
 		// pages, an error is emitted and iteration stops.
 		ReadPages(ctx context.Context, pages []*logsmd.PageDesc) result.Seq[dataset.PageData]
 	}
+
+	// TokensDecoder supports decoding data within a tokens section.
+	TokensDecoder interface {
+		// Columns describes the set of columns in the provided section.
+		Columns(ctx context.Context, section *filemd.SectionInfo) ([]*tokensmd.ColumnDesc, error)
+
+		// Pages retrieves the set of pages for the provided columns. The order of
+		// page lists emitted by the sequence matches the order of columns
+		// provided: the first page list corresponds to the first column, and so
+		// on.
+		Pages(ctx context.Context, columns []*tokensmd.ColumnDesc) result.Seq[[]*tokensmd.PageDesc]
+
+		// ReadPages reads the provided set of pages, iterating over their data
+		// matching the argument order. If an error is encountered while retrieving
+		// pages, an error is emitted and iteration stops.
+		ReadPages(ctx context.Context, pages []*tokensmd.PageDesc) result.Seq[dataset.PageData]
+	}
 )
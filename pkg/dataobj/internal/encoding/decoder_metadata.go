@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/streamsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/bufpool"
 )
@@ -99,6 +100,31 @@ func decodeLogsColumnMetadata(r streamio.Reader) (*logsmd.ColumnMetadata, error)
 	return &metadata, nil
 }
 
+// decodeTokensMetadata decodes tokens section metadata from r.
+func decodeTokensMetadata(r streamio.Reader) (*tokensmd.Metadata, error) {
+	gotVersion, err := streamio.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("read tokens section format version: %w", err)
+	} else if gotVersion != tokensFormatVersion {
+		return nil, fmt.Errorf("unexpected tokens section format version: got=%d want=%d", gotVersion, tokensFormatVersion)
+	}
+
+	var md tokensmd.Metadata
+	if err := decodeProto(r, &md); err != nil {
+		return nil, fmt.Errorf("tokens section metadata: %w", err)
+	}
+	return &md, nil
+}
+
+// decodeTokensColumnMetadata decodes tokens column metadata from r.
+func decodeTokensColumnMetadata(r streamio.Reader) (*tokensmd.ColumnMetadata, error) {
+	var metadata tokensmd.ColumnMetadata
+	if err := decodeProto(r, &metadata); err != nil {
+		return nil, fmt.Errorf("tokens column metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
 // decodeProto decodes a proto message from r and stores it in pb. Proto
 // messages are expected to be encoded with their size, followed by the proto
 // bytes.
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/streamsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
 )
 
@@ -38,6 +39,22 @@ type rangeDecoder struct {
 }
 
 func (rd *rangeDecoder) Sections(ctx context.Context) ([]*filemd.SectionInfo, error) {
+	md, err := rd.fileMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return md.Sections, nil
+}
+
+func (rd *rangeDecoder) KafkaOffsets(ctx context.Context) (*filemd.KafkaOffsets, error) {
+	md, err := rd.fileMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return md.KafkaOffsets, nil
+}
+
+func (rd *rangeDecoder) fileMetadata(ctx context.Context) (*filemd.Metadata, error) {
 	tailer, err := rd.tailer(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("reading tailer: %w", err)
@@ -52,11 +69,7 @@ func (rd *rangeDecoder) Sections(ctx context.Context) ([]*filemd.SectionInfo, er
 	br, release := getBufioReader(rc)
 	defer release()
 
-	md, err := decodeFileMetadata(br)
-	if err != nil {
-		return nil, err
-	}
-	return md.Sections, nil
+	return decodeFileMetadata(br)
 }
 
 type tailer struct {
@@ -99,6 +112,10 @@ func (rd *rangeDecoder) LogsDecoder() LogsDecoder {
 	return &rangeLogsDecoder{rr: rd.r}
 }
 
+func (rd *rangeDecoder) TokensDecoder() TokensDecoder {
+	return &rangeTokensDecoder{rr: rd.r}
+}
+
 type rangeStreamsDecoder struct {
 	rr rangeReader
 }
@@ -376,3 +393,134 @@ func (rd *rangeLogsDecoder) ReadPages(ctx context.Context, pages []*logsmd.PageD
 		return nil
 	})
 }
+
+type rangeTokensDecoder struct {
+	rr rangeReader
+}
+
+func (rd *rangeTokensDecoder) Columns(ctx context.Context, section *filemd.SectionInfo) ([]*tokensmd.ColumnDesc, error) {
+	if got, want := section.Type, filemd.SECTION_TYPE_TOKENS; got != want {
+		return nil, fmt.Errorf("unexpected section type: got=%s want=%s", got, want)
+	}
+	rc, err := rd.rr.ReadRange(ctx, int64(section.MetadataOffset), int64(section.MetadataSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading tokens section metadata: %w", err)
+	}
+	defer rc.Close()
+
+	br, release := getBufioReader(rc)
+	defer release()
+
+	md, err := decodeTokensMetadata(br)
+	if err != nil {
+		return nil, err
+	}
+	return md.Columns, nil
+}
+
+func (rd *rangeTokensDecoder) Pages(ctx context.Context, columns []*tokensmd.ColumnDesc) result.Seq[[]*tokensmd.PageDesc] {
+	return result.Iter(func(yield func([]*tokensmd.PageDesc) bool) error {
+		results := make([][]*tokensmd.PageDesc, len(columns))
+
+		columnInfo := func(c *tokensmd.ColumnDesc) (uint64, uint64) {
+			return c.GetInfo().MetadataOffset, c.GetInfo().MetadataSize
+		}
+
+		for window := range iterWindows(columns, columnInfo, windowSize) {
+			if len(window) == 0 {
+				continue
+			}
+
+			var (
+				windowOffset = window.Start().GetInfo().MetadataOffset
+				windowSize   = (window.End().GetInfo().MetadataOffset + window.End().GetInfo().MetadataSize) - windowOffset
+			)
+
+			rc, err := rd.rr.ReadRange(ctx, int64(windowOffset), int64(windowSize))
+			if err != nil {
+				return fmt.Errorf("reading column data: %w", err)
+			}
+			data, err := readAndClose(rc, windowSize)
+			if err != nil {
+				return fmt.Errorf("read page data: %w", err)
+			}
+
+			for _, wp := range window {
+				// Find the slice in the data for this column.
+				var (
+					columnOffset = wp.Data.GetInfo().MetadataOffset
+					dataOffset   = columnOffset - windowOffset
+				)
+
+				r := bytes.NewReader(data[dataOffset : dataOffset+wp.Data.GetInfo().MetadataSize])
+
+				md, err := decodeTokensColumnMetadata(r)
+				if err != nil {
+					return err
+				}
+
+				// wp.Position is the position of the column in the original pages
+				// slice; this retains the proper order of data in results.
+				results[wp.Position] = md.Pages
+			}
+		}
+
+		for _, data := range results {
+			if !yield(data) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func (rd *rangeTokensDecoder) ReadPages(ctx context.Context, pages []*tokensmd.PageDesc) result.Seq[dataset.PageData] {
+	return result.Iter(func(yield func(dataset.PageData) bool) error {
+		results := make([]dataset.PageData, len(pages))
+
+		pageInfo := func(p *tokensmd.PageDesc) (uint64, uint64) {
+			return p.GetInfo().DataOffset, p.GetInfo().DataSize
+		}
+
+		for window := range iterWindows(pages, pageInfo, windowSize) {
+			if len(window) == 0 {
+				continue
+			}
+
+			var (
+				windowOffset = window.Start().GetInfo().DataOffset
+				windowSize   = (window.End().GetInfo().DataOffset + window.End().GetInfo().DataSize) - windowOffset
+			)
+
+			rc, err := rd.rr.ReadRange(ctx, int64(windowOffset), int64(windowSize))
+			if err != nil {
+				return fmt.Errorf("reading page data: %w", err)
+			}
+			data, err := readAndClose(rc, windowSize)
+			if err != nil {
+				return fmt.Errorf("read page data: %w", err)
+			}
+
+			for _, wp := range window {
+				// Find the slice in the data for this page.
+				var (
+					pageOffset = wp.Data.GetInfo().DataOffset
+					dataOffset = pageOffset - windowOffset
+				)
+
+				// wp.Position is the position of the page in the original pages slice;
+				// this retains the proper order of data in results.
+				results[wp.Position] = dataset.PageData(data[dataOffset : dataOffset+wp.Data.GetInfo().DataSize])
+			}
+		}
+
+		for _, data := range results {
+			if !yield(data) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
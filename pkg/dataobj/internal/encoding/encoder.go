@@ -46,6 +46,8 @@ type Encoder struct {
 	sections   []*filemd.SectionInfo
 	curSection *filemd.SectionInfo
 
+	kafkaOffsets *filemd.KafkaOffsets
+
 	data *bytes.Buffer
 }
 
@@ -105,6 +107,31 @@ func (enc *Encoder) OpenLogs() (*LogsEncoder, error) {
 	), nil
 }
 
+// OpenTokens opens a [TokensEncoder]. OpenTokens fails if there is another
+// open section.
+func (enc *Encoder) OpenTokens() (*TokensEncoder, error) {
+	if enc.curSection != nil {
+		return nil, ErrElementExist
+	}
+
+	enc.curSection = &filemd.SectionInfo{
+		Type:           filemd.SECTION_TYPE_TOKENS,
+		MetadataOffset: math.MaxUint32,
+		MetadataSize:   math.MaxUint32,
+	}
+
+	return newTokensEncoder(
+		enc,
+		enc.startOffset+enc.data.Len(),
+	), nil
+}
+
+// SetKafkaOffsets records the Kafka records the data object is built from in
+// the file metadata. SetKafkaOffsets may be called at any time before Flush.
+func (enc *Encoder) SetKafkaOffsets(offsets *filemd.KafkaOffsets) {
+	enc.kafkaOffsets = offsets
+}
+
 // MetadataSize returns an estimate of the current size of the metadata for the
 // data object. MetadataSize does not include the size of data appended. The
 // estimate includes the currently open element.
@@ -115,7 +142,7 @@ func (enc *Encoder) metadata() proto.Message {
 	if enc.curSection != nil {
 		sections = append(sections, enc.curSection)
 	}
-	return &filemd.Metadata{Sections: sections}
+	return &filemd.Metadata{Sections: sections, KafkaOffsets: enc.kafkaOffsets}
 }
 
 // Flush flushes any buffered data to the underlying writer. After flushing,
@@ -163,6 +190,7 @@ func (enc *Encoder) Flush() error {
 	}
 
 	enc.sections = nil
+	enc.kafkaOffsets = nil
 	enc.data.Reset()
 	return nil
 }
@@ -171,6 +199,7 @@ func (enc *Encoder) Reset(w streamio.Writer) {
 	enc.data.Reset()
 	enc.sections = nil
 	enc.curSection = nil
+	enc.kafkaOffsets = nil
 	enc.w = w
 	enc.startOffset = len(magic)
 }
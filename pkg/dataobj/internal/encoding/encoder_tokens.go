@@ -0,0 +1,275 @@
+package encoding
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
+)
+
+// TokensEncoder encodes an individual tokens section in a data object.
+// TokensEncoders are created by [Encoder]s.
+type TokensEncoder struct {
+	parent *Encoder
+
+	startOffset int  // Byte offset in the file where the column starts.
+	closed      bool // true if TokensEncoder has been closed.
+
+	data      *bytes.Buffer
+	columns   []*tokensmd.ColumnDesc // closed columns.
+	curColumn *tokensmd.ColumnDesc   // curColumn is the currently open column.
+}
+
+func newTokensEncoder(parent *Encoder, offset int) *TokensEncoder {
+	buf := bytesBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return &TokensEncoder{
+		parent:      parent,
+		startOffset: offset,
+
+		data: buf,
+	}
+}
+
+// OpenColumn opens a new column in the tokens section. OpenColumn fails if there
+// is another open column or if the TokensEncoder has been closed.
+func (enc *TokensEncoder) OpenColumn(columnType tokensmd.ColumnType, info *dataset.ColumnInfo) (*TokensColumnEncoder, error) {
+	if enc.curColumn != nil {
+		return nil, ErrElementExist
+	} else if enc.closed {
+		return nil, ErrClosed
+	}
+
+	// MetadataOffset and MetadataSize aren't available until the column is
+	// closed. We temporarily set these fields to the maximum values so they're
+	// accounted for in the MetadataSize estimate.
+	enc.curColumn = &tokensmd.ColumnDesc{
+		Type: columnType,
+		Info: &datasetmd.ColumnInfo{
+			Name:             info.Name,
+			ValueType:        info.Type,
+			RowsCount:        uint64(info.RowsCount),
+			ValuesCount:      uint64(info.ValuesCount),
+			Compression:      info.Compression,
+			UncompressedSize: uint64(info.UncompressedSize),
+			CompressedSize:   uint64(info.CompressedSize),
+			Statistics:       info.Statistics,
+
+			MetadataOffset: math.MaxUint32,
+			MetadataSize:   math.MaxUint32,
+		},
+	}
+
+	return newTokensColumnEncoder(
+		enc,
+		enc.startOffset+enc.data.Len(),
+	), nil
+}
+
+// MetadataSize returns an estimate of the current size of the metadata for the
+// section. MetadataSize includes an estimate for the currently open element.
+func (enc *TokensEncoder) MetadataSize() int { return elementMetadataSize(enc) }
+
+func (enc *TokensEncoder) metadata() proto.Message {
+	columns := enc.columns[:len(enc.columns):cap(enc.columns)]
+	if enc.curColumn != nil {
+		columns = append(columns, enc.curColumn)
+	}
+	return &tokensmd.Metadata{Columns: columns}
+}
+
+// Commit closes the section, flushing all data to the parent element. After
+// Commit is called, the TokensEncoder can no longer be modified.
+//
+// Commit fails if there is an open column.
+func (enc *TokensEncoder) Commit() error {
+	if enc.closed {
+		return ErrClosed
+	} else if enc.curColumn != nil {
+		return ErrElementExist
+	}
+	enc.closed = true
+
+	defer bytesBufferPool.Put(enc.data)
+
+	if len(enc.columns) == 0 {
+		// No data was written; discard.
+		return enc.parent.append(nil, nil)
+	}
+
+	metadataBuffer := bytesBufferPool.Get().(*bytes.Buffer)
+	metadataBuffer.Reset()
+	defer bytesBufferPool.Put(metadataBuffer)
+
+	// The section metadata should start with its version.
+	if err := streamio.WriteUvarint(metadataBuffer, tokensFormatVersion); err != nil {
+		return err
+	} else if err := elementMetadataWrite(enc, metadataBuffer); err != nil {
+		return err
+	}
+	return enc.parent.append(enc.data.Bytes(), metadataBuffer.Bytes())
+}
+
+// Discard discards the section, discarding any data written to it. After
+// Discard is called, the TokensEncoder can no longer be modified.
+//
+// Discard fails if there is an open column.
+func (enc *TokensEncoder) Discard() error {
+	if enc.closed {
+		return ErrClosed
+	} else if enc.curColumn != nil {
+		return ErrElementExist
+	}
+	enc.closed = true
+
+	defer bytesBufferPool.Put(enc.data)
+
+	return enc.parent.append(nil, nil)
+}
+
+// append adds data and metadata to enc. append must only be called from child
+// elements on Close and Discard. Discard calls must pass nil for both data and
+// metadata to denote a discard.
+func (enc *TokensEncoder) append(data, metadata []byte) error {
+	if enc.closed {
+		return ErrClosed
+	} else if enc.curColumn == nil {
+		return errElementNoExist
+	}
+
+	if len(data) == 0 && len(metadata) == 0 {
+		// Column was discarded.
+		enc.curColumn = nil
+		return nil
+	}
+
+	enc.curColumn.Info.MetadataOffset = uint64(enc.startOffset + enc.data.Len() + len(data))
+	enc.curColumn.Info.MetadataSize = uint64(len(metadata))
+
+	// bytes.Buffer.Write never fails.
+	enc.data.Grow(len(data) + len(metadata))
+	_, _ = enc.data.Write(data)
+	_, _ = enc.data.Write(metadata)
+
+	enc.columns = append(enc.columns, enc.curColumn)
+	enc.curColumn = nil
+	return nil
+}
+
+// TokensColumnEncoder encodes an individual column in a tokens section.
+// TokensColumnEncoder are created by [TokensEncoder].
+type TokensColumnEncoder struct {
+	parent *TokensEncoder
+
+	startOffset int  // Byte offset in the file where the column starts.
+	closed      bool // true if TokensColumnEncoder has been closed.
+
+	data        *bytes.Buffer // All page data.
+	pageHeaders []*tokensmd.PageDesc
+
+	memPages      []*dataset.MemPage // Pages to write.
+	totalPageSize int                // Size of bytes across all pages.
+}
+
+func newTokensColumnEncoder(parent *TokensEncoder, offset int) *TokensColumnEncoder {
+	buf := bytesBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return &TokensColumnEncoder{
+		parent:      parent,
+		startOffset: offset,
+
+		data: buf,
+	}
+}
+
+// AppendPage appens a new [dataset.MemPage] to the column. AppendPage fails if
+// the column has been closed.
+func (enc *TokensColumnEncoder) AppendPage(page *dataset.MemPage) error {
+	if enc.closed {
+		return ErrClosed
+	}
+
+	// It's possible the caller can pass an incorrect value for UncompressedSize
+	// and CompressedSize, but those fields are purely for stats so we don't
+	// check it.
+	enc.pageHeaders = append(enc.pageHeaders, &tokensmd.PageDesc{
+		Info: &datasetmd.PageInfo{
+			UncompressedSize: uint64(page.Info.UncompressedSize),
+			CompressedSize:   uint64(page.Info.CompressedSize),
+			Crc32:            page.Info.CRC32,
+			RowsCount:        uint64(page.Info.RowCount),
+			ValuesCount:      uint64(page.Info.ValuesCount),
+			Encoding:         page.Info.Encoding,
+
+			DataOffset: uint64(enc.startOffset + enc.totalPageSize),
+			DataSize:   uint64(len(page.Data)),
+
+			Statistics: page.Info.Stats,
+		},
+	})
+
+	enc.memPages = append(enc.memPages, page)
+	enc.totalPageSize += len(page.Data)
+	return nil
+}
+
+// MetadataSize returns an estimate of the current size of the metadata for the
+// column. MetadataSize does not include the size of data appended.
+func (enc *TokensColumnEncoder) MetadataSize() int { return elementMetadataSize(enc) }
+
+func (enc *TokensColumnEncoder) metadata() proto.Message {
+	return &tokensmd.ColumnMetadata{Pages: enc.pageHeaders}
+}
+
+// Commit closes the column, flushing all data to the parent element. After
+// Commit is called, the TokensColumnEncoder can no longer be modified.
+func (enc *TokensColumnEncoder) Commit() error {
+	if enc.closed {
+		return ErrClosed
+	}
+	enc.closed = true
+
+	defer bytesBufferPool.Put(enc.data)
+
+	if len(enc.pageHeaders) == 0 {
+		// No data was written; discard.
+		return enc.parent.append(nil, nil)
+	}
+
+	// Write all pages. To avoid costly reallocations, we grow our buffer to fit
+	// all data first.
+	enc.data.Grow(enc.totalPageSize)
+	for _, p := range enc.memPages {
+		_, _ = enc.data.Write(p.Data) // bytes.Buffer.Write never fails.
+	}
+
+	metadataBuffer := bytesBufferPool.Get().(*bytes.Buffer)
+	metadataBuffer.Reset()
+	defer bytesBufferPool.Put(metadataBuffer)
+
+	if err := elementMetadataWrite(enc, metadataBuffer); err != nil {
+		return err
+	}
+
+	return enc.parent.append(enc.data.Bytes(), metadataBuffer.Bytes())
+}
+
+// Discard discards the column, discarding any data written to it. After
+// Discard is called, the TokensColumnEncoder can no longer be modified.
+func (enc *TokensColumnEncoder) Discard() error {
+	if enc.closed {
+		return ErrClosed
+	}
+	enc.closed = true
+
+	defer bytesBufferPool.Put(enc.data)
+
+	return enc.parent.append(nil, nil) // Notify parent of discard.
+}
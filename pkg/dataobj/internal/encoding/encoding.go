@@ -11,6 +11,7 @@ const (
 	fileFormatVersion    = 0x1
 	streamsFormatVersion = 0x1
 	logsFormatVersion    = 0x1
+	tokensFormatVersion  = 0x1
 )
 
 var (
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/streamsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
 )
 
@@ -247,6 +248,7 @@ func (m *Metrics) Observe(ctx context.Context, dec Decoder) error {
 	var (
 		streamsDecoder = dec.StreamsDecoder()
 		logsDecoder    = dec.LogsDecoder()
+		tokensDecoder  = dec.TokensDecoder()
 	)
 
 	var errs []error
@@ -257,6 +259,8 @@ func (m *Metrics) Observe(ctx context.Context, dec Decoder) error {
 			errs = append(errs, m.observeStreamsSection(ctx, section, streamsDecoder))
 		case filemd.SECTION_TYPE_LOGS:
 			errs = append(errs, m.observeLogsSection(ctx, section, logsDecoder))
+		case filemd.SECTION_TYPE_TOKENS:
+			errs = append(errs, m.observeTokensSection(ctx, section, tokensDecoder))
 		default:
 			errs = append(errs, fmt.Errorf("unknown section type %q", section.Type.String()))
 		}
@@ -376,3 +380,59 @@ func (m *Metrics) observeLogsSection(ctx context.Context, section *filemd.Sectio
 
 	return nil
 }
+
+func (m *Metrics) observeTokensSection(ctx context.Context, section *filemd.SectionInfo, dec TokensDecoder) error {
+	sectionType := section.Type.String()
+
+	columns, err := dec.Columns(ctx, section)
+	if err != nil {
+		return err
+	}
+	m.datasetColumnCount.WithLabelValues(sectionType).Observe(float64(len(columns)))
+
+	columnPages, err := result.Collect(dec.Pages(ctx, columns))
+	if err != nil {
+		return err
+	} else if len(columnPages) != len(columns) {
+		return fmt.Errorf("expected %d page lists, got %d", len(columns), len(columnPages))
+	}
+
+	// Count metadata sizes across columns.
+	{
+		var totalColumnMetadataSize int
+		for i := range columns {
+			columnMetadataSize := proto.Size(&tokensmd.ColumnMetadata{Pages: columnPages[i]})
+			m.datasetColumnMetadataSize.WithLabelValues(sectionType).Observe(float64(columnMetadataSize))
+			totalColumnMetadataSize += columnMetadataSize
+		}
+		m.datasetColumnMetadataTotalSize.WithLabelValues(sectionType).Observe(float64(totalColumnMetadataSize))
+	}
+
+	for i, column := range columns {
+		columnType := column.Type.String()
+		pages := columnPages[i]
+		compression := column.Info.Compression
+
+		m.datasetColumnCompressedBytes.WithLabelValues(sectionType, columnType).Observe(float64(column.Info.CompressedSize))
+		m.datasetColumnUncompressedBytes.WithLabelValues(sectionType, columnType).Observe(float64(column.Info.UncompressedSize))
+		if compression != datasetmd.COMPRESSION_TYPE_NONE {
+			m.datasetColumnCompressionRatio.WithLabelValues(sectionType, columnType, compression.String()).Observe(float64(column.Info.UncompressedSize) / float64(column.Info.CompressedSize))
+		}
+		m.datasetColumnRows.WithLabelValues(sectionType, columnType).Observe(float64(column.Info.RowsCount))
+		m.datasetColumnValues.WithLabelValues(sectionType, columnType).Observe(float64(column.Info.ValuesCount))
+
+		m.datasetPageCount.WithLabelValues(sectionType, columnType).Observe(float64(len(pages)))
+
+		for _, page := range pages {
+			m.datasetPageCompressedBytes.WithLabelValues(sectionType, columnType).Observe(float64(page.Info.CompressedSize))
+			m.datasetPageUncompressedBytes.WithLabelValues(sectionType, columnType).Observe(float64(page.Info.UncompressedSize))
+			if compression != datasetmd.COMPRESSION_TYPE_NONE {
+				m.datasetPageCompressionRatio.WithLabelValues(sectionType, columnType, compression.String()).Observe(float64(page.Info.UncompressedSize) / float64(page.Info.CompressedSize))
+			}
+			m.datasetPageRows.WithLabelValues(sectionType, columnType).Observe(float64(page.Info.RowsCount))
+			m.datasetPageValues.WithLabelValues(sectionType, columnType).Observe(float64(page.Info.ValuesCount))
+		}
+	}
+
+	return nil
+}
@@ -38,6 +38,11 @@ const (
 	VALUE_TYPE_UINT64 ValueType = 2
 	// VALUE_TYPE_BYTE_ARRAY is a column containing bytes with no specific type.
 	VALUE_TYPE_BYTE_ARRAY ValueType = 4
+	// VALUE_TYPE_FLOAT64 is a column containing 64-bit floating point values.
+	VALUE_TYPE_FLOAT64 ValueType = 5
+	// VALUE_TYPE_TIMESTAMP is a column containing timestamps, stored as the
+	// number of nanoseconds since the Unix epoch.
+	VALUE_TYPE_TIMESTAMP ValueType = 6
 )
 
 var ValueType_name = map[int32]string{
@@ -45,6 +50,8 @@ var ValueType_name = map[int32]string{
 	1: "VALUE_TYPE_INT64",
 	2: "VALUE_TYPE_UINT64",
 	4: "VALUE_TYPE_BYTE_ARRAY",
+	5: "VALUE_TYPE_FLOAT64",
+	6: "VALUE_TYPE_TIMESTAMP",
 }
 
 var ValueType_value = map[string]int32{
@@ -52,6 +59,8 @@ var ValueType_value = map[string]int32{
 	"VALUE_TYPE_INT64":       1,
 	"VALUE_TYPE_UINT64":      2,
 	"VALUE_TYPE_BYTE_ARRAY":  4,
+	"VALUE_TYPE_FLOAT64":     5,
+	"VALUE_TYPE_TIMESTAMP":   6,
 }
 
 func (ValueType) EnumDescriptor() ([]byte, []int) {
@@ -106,6 +115,10 @@ const (
 	// Bitmap encoding. Bitmaps effiently store repeating sequences of unsigned
 	// integers using a combination of run-length encoding and bitpacking.
 	ENCODING_TYPE_BITMAP EncodingType = 3
+	// Dictionary encoding. The distinct values of the page are stored once in
+	// a dictionary, followed by the bitmap-encoded dictionary index of each
+	// value.
+	ENCODING_TYPE_DICTIONARY EncodingType = 4
 )
 
 var EncodingType_name = map[int32]string{
@@ -113,6 +126,7 @@ var EncodingType_name = map[int32]string{
 	1: "ENCODING_TYPE_PLAIN",
 	2: "ENCODING_TYPE_DELTA",
 	3: "ENCODING_TYPE_BITMAP",
+	4: "ENCODING_TYPE_DICTIONARY",
 }
 
 var EncodingType_value = map[string]int32{
@@ -120,6 +134,7 @@ var EncodingType_value = map[string]int32{
 	"ENCODING_TYPE_PLAIN":       1,
 	"ENCODING_TYPE_DELTA":       2,
 	"ENCODING_TYPE_BITMAP":      3,
+	"ENCODING_TYPE_DICTIONARY":  4,
 }
 
 func (EncodingType) EnumDescriptor() ([]byte, []int) {
@@ -273,6 +288,13 @@ type Statistics struct {
 	// Applications must not assume that an unset cardinality_count means that
 	// the column has no distinct values; check for values_count == 0 instead.
 	CardinalityCount uint64 `protobuf:"varint,3,opt,name=cardinality_count,json=cardinalityCount,proto3" json:"cardinality_count,omitempty"`
+	// Bloom filter of the non-NULL values of the column or page, used to skip
+	// pages that can't contain a value when checking for equality. The filter is
+	// encoded with the binary format of github.com/willf/bloom.
+	//
+	// Applications must not assume that an unset bloom_filter means that the
+	// column or page has no values.
+	BloomFilter []byte `protobuf:"bytes,4,opt,name=bloom_filter,json=bloomFilter,proto3" json:"bloom_filter,omitempty"`
 }
 
 func (m *Statistics) Reset()      { *m = Statistics{} }
@@ -328,6 +350,13 @@ func (m *Statistics) GetCardinalityCount() uint64 {
 	return 0
 }
 
+func (m *Statistics) GetBloomFilter() []byte {
+	if m != nil {
+		return m.BloomFilter
+	}
+	return nil
+}
+
 // Page describes an individual page within a column.
 type PageInfo struct {
 	// Uncompressed size of the page within the data object.
@@ -460,53 +489,57 @@ func init() {
 }
 
 var fileDescriptor_7ab9d5b21b743868 = []byte{
-	// 728 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0x4f, 0x53, 0xda, 0x5e,
-	0x14, 0xe5, 0x01, 0xfa, 0x23, 0x17, 0xd4, 0xf8, 0x7e, 0x5a, 0x63, 0xa9, 0x29, 0xb5, 0x33, 0x95,
-	0x6a, 0x07, 0xa6, 0xd8, 0x69, 0xd7, 0xfc, 0x49, 0x9d, 0xcc, 0x68, 0xc8, 0x04, 0x74, 0x06, 0x37,
-	0x99, 0x67, 0x08, 0x34, 0x95, 0x24, 0x0c, 0x09, 0x54, 0x5c, 0x75, 0xe5, 0xba, 0x1f, 0xa3, 0x1f,
-	0xa5, 0x4b, 0x97, 0x2e, 0x2b, 0xce, 0x74, 0xba, 0xf4, 0x23, 0x74, 0x78, 0xe1, 0x4f, 0x04, 0xca,
-	0xb8, 0xe8, 0xee, 0x71, 0xce, 0xb9, 0xef, 0x5e, 0xee, 0x39, 0x0f, 0xe0, 0x43, 0xf3, 0xbc, 0x9e,
-	0xae, 0x12, 0x97, 0xd8, 0x67, 0x9f, 0xd3, 0x86, 0xe5, 0xea, 0x2d, 0x8b, 0x34, 0xd2, 0xa6, 0xee,
-	0x92, 0x3e, 0x48, 0x19, 0x47, 0x77, 0xcd, 0xea, 0xf8, 0x94, 0x6a, 0xb6, 0x6c, 0xd7, 0xc6, 0xf1,
-	0x41, 0x51, 0x6a, 0xa8, 0x4d, 0x0d, 0x14, 0xa9, 0xce, 0xdb, 0xed, 0x5f, 0x21, 0x80, 0xbc, 0xdd,
-	0x68, 0x9b, 0x96, 0x68, 0xd5, 0x6c, 0x8c, 0x21, 0x6c, 0x11, 0x53, 0xe7, 0x50, 0x02, 0x25, 0x19,
-	0x85, 0x9e, 0xb1, 0x00, 0xd0, 0x21, 0x8d, 0xb6, 0xae, 0xba, 0xdd, 0xa6, 0xce, 0x05, 0x13, 0x28,
-	0xb9, 0x9c, 0x79, 0x95, 0x9a, 0x73, 0x69, 0xea, 0xa4, 0x2f, 0x2f, 0x77, 0x9b, 0xba, 0xc2, 0x74,
-	0x86, 0x47, 0xbc, 0x05, 0xd0, 0xb2, 0xbf, 0x38, 0xaa, 0x66, 0xb7, 0x2d, 0x97, 0x0b, 0x25, 0x50,
-	0x32, 0xac, 0x30, 0x7d, 0x24, 0xdf, 0x07, 0xb0, 0x04, 0x51, 0xcd, 0x36, 0x9b, 0x2d, 0xdd, 0x71,
-	0x0c, 0xdb, 0xe2, 0xc2, 0xb4, 0xcd, 0x9b, 0xb9, 0x6d, 0xf2, 0x63, 0x3d, 0x6d, 0xe6, 0xbf, 0x00,
-	0xef, 0xc1, 0x6a, 0xdb, 0x1a, 0x02, 0x7a, 0x55, 0x75, 0x8c, 0x4b, 0x9d, 0x5b, 0xa0, 0x5d, 0x59,
-	0x3f, 0x51, 0x32, 0x2e, 0x75, 0xbc, 0x03, 0x2b, 0x93, 0xd2, 0x45, 0x2a, 0x5d, 0x9e, 0x16, 0x0e,
-	0x27, 0x51, 0xed, 0x5a, 0xcd, 0xd1, 0x5d, 0xee, 0x3f, 0x4f, 0x38, 0x84, 0x8b, 0x14, 0xc5, 0x2f,
-	0x61, 0x69, 0x24, 0xa4, 0xf7, 0x45, 0xa8, 0x2c, 0x36, 0x04, 0xe9, 0x6d, 0x07, 0x00, 0x8e, 0x4b,
-	0x5c, 0xc3, 0x71, 0x0d, 0xcd, 0xe1, 0x98, 0x04, 0x4a, 0x46, 0x33, 0x3b, 0x73, 0xbf, 0x72, 0x69,
-	0x24, 0x57, 0x7c, 0xa5, 0xf8, 0x05, 0xc4, 0xe8, 0xa2, 0x87, 0xdb, 0x05, 0xda, 0x2c, 0xea, 0x61,
-	0x74, 0xbf, 0xdb, 0x0e, 0xc0, 0xb8, 0x18, 0xc7, 0x81, 0x31, 0x0d, 0x4b, 0xa5, 0x02, 0x6a, 0x76,
-	0x4c, 0x89, 0x98, 0x86, 0x45, 0x8d, 0xa3, 0x24, 0xb9, 0x18, 0x90, 0xc1, 0x01, 0x49, 0x2e, 0x3c,
-	0x72, 0x0f, 0x56, 0x35, 0xd2, 0xaa, 0x1a, 0x16, 0x69, 0x18, 0x6e, 0xf7, 0x81, 0x9b, 0xac, 0x8f,
-	0xf0, 0x9a, 0x5e, 0x85, 0x20, 0x22, 0x93, 0xba, 0x4e, 0xb3, 0x35, 0xd3, 0x11, 0xf4, 0x78, 0x47,
-	0x82, 0x33, 0x1d, 0x59, 0x83, 0x05, 0xad, 0xa5, 0xed, 0x67, 0xe8, 0x0c, 0x4b, 0x8a, 0xf7, 0x61,
-	0x22, 0x6c, 0xe1, 0xc9, 0xb0, 0x09, 0x10, 0xd1, 0x2d, 0xcd, 0xae, 0x1a, 0x56, 0x9d, 0x66, 0x62,
-	0x39, 0xf3, 0x7a, 0xee, 0xda, 0x85, 0x81, 0x98, 0xc6, 0x6c, 0x54, 0x8a, 0x9f, 0x43, 0xd4, 0x9f,
-	0x04, 0x2f, 0x32, 0xe0, 0x4b, 0x41, 0x1c, 0x98, 0x71, 0x02, 0xbc, 0xa0, 0x44, 0xfe, 0xe2, 0x7e,
-	0xe4, 0xdf, 0xb9, 0xcf, 0x4c, 0xb9, 0xbf, 0xdb, 0x06, 0x66, 0xf4, 0x28, 0xf1, 0x53, 0x78, 0x72,
-	0x92, 0x3d, 0x3c, 0x16, 0xd4, 0x72, 0x45, 0x16, 0xd4, 0x63, 0xa9, 0x24, 0x0b, 0x79, 0xf1, 0xa3,
-	0x28, 0x14, 0xd8, 0x00, 0x5e, 0x03, 0xd6, 0xc7, 0x89, 0x52, 0xf9, 0xfd, 0x3b, 0x16, 0xe1, 0x75,
-	0x58, 0xf5, 0x57, 0x78, 0x70, 0x10, 0x6f, 0xc2, 0xba, 0x0f, 0xce, 0x55, 0xca, 0x82, 0x9a, 0x55,
-	0x94, 0x6c, 0x85, 0x0d, 0x6f, 0x87, 0x23, 0x21, 0x36, 0xb4, 0x7b, 0x85, 0x60, 0x65, 0xe2, 0x95,
-	0xe2, 0x04, 0x3c, 0xcb, 0x17, 0x8f, 0x64, 0x45, 0x28, 0x95, 0xc4, 0xa2, 0x34, 0x6b, 0x86, 0x4d,
-	0x58, 0x9f, 0x52, 0x48, 0x45, 0x49, 0x60, 0x11, 0x8e, 0xc3, 0xc6, 0x14, 0x55, 0x92, 0xb2, 0xb2,
-	0x5c, 0xf1, 0xc6, 0x99, 0x22, 0x4f, 0x4b, 0xe5, 0x02, 0x1b, 0xda, 0xed, 0x42, 0xcc, 0xef, 0x21,
-	0xde, 0x82, 0x4d, 0x41, 0xca, 0x17, 0x0b, 0xa2, 0x74, 0x30, 0x6b, 0x82, 0x0d, 0xf8, 0xff, 0x21,
-	0x2d, 0x1f, 0x66, 0x45, 0x89, 0x45, 0xd3, 0x44, 0x41, 0x38, 0x2c, 0x67, 0xd9, 0x20, 0xe6, 0x60,
-	0xed, 0x21, 0x91, 0x13, 0xcb, 0x47, 0x59, 0x99, 0x0d, 0xe5, 0x2e, 0xae, 0x6f, 0xf9, 0xc0, 0xcd,
-	0x2d, 0x1f, 0xb8, 0xbf, 0xe5, 0xd1, 0xd7, 0x1e, 0x8f, 0xbe, 0xf7, 0x78, 0xf4, 0xa3, 0xc7, 0xa3,
-	0xeb, 0x1e, 0x8f, 0x7e, 0xf6, 0x78, 0xf4, 0xbb, 0xc7, 0x07, 0xee, 0x7b, 0x3c, 0xfa, 0x76, 0xc7,
-	0x07, 0xae, 0xef, 0xf8, 0xc0, 0xcd, 0x1d, 0x1f, 0x38, 0xcd, 0xd5, 0x0d, 0xf7, 0x53, 0xfb, 0x2c,
-	0xa5, 0xd9, 0x66, 0xba, 0xde, 0x22, 0x35, 0x62, 0x91, 0x74, 0xc3, 0x3e, 0x37, 0xd2, 0x9d, 0xfd,
-	0xf4, 0x23, 0xff, 0x08, 0xce, 0x16, 0xe9, 0xef, 0xff, 0xfe, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0xaa, 0x6b, 0x9a, 0xcf, 0x3a, 0x06, 0x00, 0x00,
+	// 791 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0x4d, 0x6f, 0xe2, 0x56,
+	0x14, 0xe5, 0x01, 0x49, 0xf1, 0x85, 0xc9, 0x38, 0xaf, 0xc9, 0x8c, 0x53, 0x66, 0x5c, 0x86, 0x4a,
+	0x1d, 0x9a, 0x54, 0xa0, 0x92, 0xaa, 0x5d, 0x1b, 0x70, 0x22, 0x4b, 0x60, 0x2c, 0xdb, 0x89, 0x44,
+	0x36, 0x96, 0x31, 0x86, 0xba, 0xc1, 0x36, 0xb2, 0x0d, 0x4d, 0xb2, 0xea, 0x2a, 0xeb, 0xae, 0xda,
+	0x9f, 0xd0, 0xfe, 0x94, 0x2e, 0xb3, 0xcc, 0xb2, 0x21, 0x52, 0xd5, 0x65, 0x7e, 0x42, 0xc5, 0x33,
+	0x1f, 0x0e, 0x50, 0x94, 0x45, 0x77, 0xcf, 0xe7, 0x9c, 0xfb, 0xee, 0xf5, 0x3d, 0xc7, 0x32, 0x7c,
+	0x3f, 0xb8, 0xec, 0x95, 0x3a, 0x7a, 0xa0, 0xbb, 0xed, 0x1f, 0x4b, 0x96, 0x13, 0x98, 0x9e, 0xa3,
+	0xf7, 0x4b, 0xb6, 0x19, 0xe8, 0x13, 0x90, 0x30, 0xbe, 0x19, 0xd8, 0x9d, 0xc5, 0xa9, 0x38, 0xf0,
+	0xdc, 0xc0, 0xc5, 0xd9, 0x69, 0x51, 0x71, 0xa6, 0x2d, 0x4e, 0x15, 0xc5, 0xd1, 0x37, 0xf9, 0xbf,
+	0x13, 0x00, 0x55, 0xb7, 0x3f, 0xb4, 0x1d, 0xc1, 0xe9, 0xba, 0x18, 0x43, 0xd2, 0xd1, 0x6d, 0x93,
+	0x41, 0x39, 0x54, 0xa0, 0x64, 0x72, 0xc6, 0x3c, 0xc0, 0x48, 0xef, 0x0f, 0x4d, 0x2d, 0xb8, 0x1e,
+	0x98, 0x4c, 0x3c, 0x87, 0x0a, 0x3b, 0xe5, 0x2f, 0x8b, 0x1b, 0x2e, 0x2d, 0x9e, 0x4f, 0xe4, 0xea,
+	0xf5, 0xc0, 0x94, 0xa9, 0xd1, 0xec, 0x88, 0xdf, 0x03, 0x78, 0xee, 0x4f, 0xbe, 0x66, 0xb8, 0x43,
+	0x27, 0x60, 0x12, 0x39, 0x54, 0x48, 0xca, 0xd4, 0x04, 0xa9, 0x4e, 0x00, 0x2c, 0x42, 0xda, 0x70,
+	0xed, 0x81, 0x67, 0xfa, 0xbe, 0xe5, 0x3a, 0x4c, 0x92, 0xb4, 0xf9, 0x7a, 0x63, 0x9b, 0xea, 0x42,
+	0x4f, 0x9a, 0x45, 0x2f, 0xc0, 0x47, 0xb0, 0x3b, 0x74, 0x66, 0x80, 0xd9, 0xd1, 0x7c, 0xeb, 0xc6,
+	0x64, 0xb6, 0x48, 0x57, 0x3a, 0x4a, 0x28, 0xd6, 0x8d, 0x89, 0x3f, 0xc2, 0xeb, 0x65, 0xe9, 0x36,
+	0x91, 0xee, 0xac, 0x0a, 0x67, 0x93, 0x68, 0x6e, 0xb7, 0xeb, 0x9b, 0x01, 0xf3, 0x49, 0x28, 0x9c,
+	0xc1, 0x4d, 0x82, 0xe2, 0x2f, 0xe0, 0xd5, 0x5c, 0x48, 0xee, 0x4b, 0x11, 0x59, 0x66, 0x06, 0x92,
+	0xdb, 0x4e, 0x01, 0xfc, 0x40, 0x0f, 0x2c, 0x3f, 0xb0, 0x0c, 0x9f, 0xa1, 0x72, 0xa8, 0x90, 0x2e,
+	0x7f, 0xdc, 0xf8, 0xca, 0xca, 0x5c, 0x2e, 0x47, 0x4a, 0xf1, 0x07, 0xc8, 0x90, 0x45, 0xcf, 0xb6,
+	0x0b, 0xa4, 0x59, 0x3a, 0xc4, 0xc8, 0x7e, 0xf3, 0xbf, 0x22, 0x80, 0x45, 0x35, 0xce, 0x02, 0x65,
+	0x5b, 0x8e, 0x46, 0x14, 0xc4, 0xed, 0x8c, 0x9c, 0xb2, 0x2d, 0x87, 0x38, 0x47, 0x48, 0xfd, 0x6a,
+	0x4a, 0xc6, 0xa7, 0xa4, 0x7e, 0x15, 0x92, 0x47, 0xb0, 0x6b, 0xe8, 0x5e, 0xc7, 0x72, 0xf4, 0xbe,
+	0x15, 0x5c, 0x3f, 0xb3, 0x93, 0x8e, 0x10, 0xa1, 0xab, 0x1f, 0x20, 0xd3, 0xee, 0xbb, 0xae, 0xad,
+	0x75, 0xad, 0x7e, 0x60, 0x7a, 0xc4, 0xd6, 0x8c, 0x9c, 0x26, 0xd8, 0x09, 0x81, 0xf2, 0xb7, 0x09,
+	0x48, 0x49, 0x7a, 0xcf, 0x24, 0xf9, 0x5b, 0xeb, 0x1a, 0x7a, 0xb9, 0x6b, 0xf1, 0xb5, 0xae, 0xed,
+	0xc1, 0x96, 0xe1, 0x19, 0xc7, 0x65, 0x32, 0xe6, 0x2b, 0x39, 0x7c, 0x58, 0x0a, 0x64, 0x72, 0x39,
+	0x90, 0x3c, 0xa4, 0x4c, 0xc7, 0x70, 0x3b, 0x96, 0xd3, 0x23, 0xb9, 0xd9, 0x29, 0x7f, 0xb5, 0xd1,
+	0x1a, 0x7e, 0x2a, 0x26, 0x51, 0x9c, 0x97, 0xe2, 0xcf, 0x21, 0x1d, 0x4d, 0x4b, 0x18, 0x2b, 0x88,
+	0x24, 0x25, 0x0b, 0xd4, 0x22, 0x25, 0x61, 0x98, 0x52, 0xff, 0x91, 0x90, 0xd4, 0xff, 0x97, 0x10,
+	0x6a, 0x25, 0x21, 0x87, 0xbf, 0x23, 0xa0, 0xe6, 0x5f, 0x2e, 0xfe, 0x0c, 0xde, 0x9c, 0x73, 0xf5,
+	0x33, 0x5e, 0x53, 0x5b, 0x12, 0xaf, 0x9d, 0x89, 0x8a, 0xc4, 0x57, 0x85, 0x13, 0x81, 0xaf, 0xd1,
+	0x31, 0xbc, 0x07, 0x74, 0x84, 0x13, 0x44, 0xf5, 0xbb, 0x6f, 0x69, 0x84, 0xf7, 0x61, 0x37, 0x5a,
+	0x11, 0xc2, 0x71, 0x7c, 0x00, 0xfb, 0x11, 0xb8, 0xd2, 0x52, 0x79, 0x8d, 0x93, 0x65, 0xae, 0x45,
+	0x27, 0xf1, 0x1b, 0xc0, 0x11, 0xea, 0xa4, 0xde, 0xe4, 0x26, 0x25, 0x5b, 0x98, 0x81, 0xbd, 0x08,
+	0xae, 0x0a, 0x0d, 0x5e, 0x51, 0xb9, 0x86, 0x44, 0x6f, 0xe7, 0x93, 0xa9, 0x04, 0x9d, 0x38, 0xbc,
+	0x45, 0xf0, 0x7a, 0xe9, 0xe3, 0xc7, 0x39, 0x78, 0x57, 0x6d, 0x36, 0x24, 0x99, 0x57, 0x14, 0xa1,
+	0x29, 0xae, 0x9b, 0xfa, 0x00, 0xf6, 0x57, 0x14, 0x62, 0x53, 0xe4, 0x69, 0x84, 0xb3, 0xf0, 0x76,
+	0x85, 0x52, 0x44, 0x4e, 0x92, 0x5a, 0xe1, 0x0b, 0xac, 0x90, 0x17, 0x8a, 0x5a, 0xa3, 0x13, 0x87,
+	0xbf, 0x21, 0xc8, 0x44, 0x7d, 0xc7, 0xef, 0xe1, 0x80, 0x17, 0xab, 0xcd, 0x9a, 0x20, 0x9e, 0xae,
+	0x1b, 0xe1, 0x2d, 0x7c, 0xfa, 0x9c, 0x96, 0xea, 0x9c, 0x20, 0xd2, 0x68, 0x95, 0xa8, 0xf1, 0x75,
+	0x95, 0xa3, 0xe3, 0x93, 0x55, 0x3c, 0x27, 0x2a, 0x82, 0xda, 0xe0, 0x24, 0x3a, 0x81, 0xdf, 0x01,
+	0xb3, 0x54, 0x22, 0x54, 0x55, 0xa1, 0x29, 0x72, 0x72, 0x8b, 0x4e, 0x56, 0xae, 0xee, 0x1e, 0xd8,
+	0xd8, 0xfd, 0x03, 0x1b, 0x7b, 0x7a, 0x60, 0xd1, 0xcf, 0x63, 0x16, 0xfd, 0x31, 0x66, 0xd1, 0x9f,
+	0x63, 0x16, 0xdd, 0x8d, 0x59, 0xf4, 0xd7, 0x98, 0x45, 0xff, 0x8c, 0xd9, 0xd8, 0xd3, 0x98, 0x45,
+	0xbf, 0x3c, 0xb2, 0xb1, 0xbb, 0x47, 0x36, 0x76, 0xff, 0xc8, 0xc6, 0x2e, 0x2a, 0x3d, 0x2b, 0xf8,
+	0x61, 0xd8, 0x2e, 0x1a, 0xae, 0x5d, 0xea, 0x79, 0x7a, 0x57, 0x77, 0xf4, 0x52, 0xdf, 0xbd, 0xb4,
+	0x4a, 0xa3, 0xe3, 0xd2, 0x0b, 0x7f, 0x3f, 0xed, 0x6d, 0xf2, 0xd7, 0x39, 0xfe, 0x77, 0x00, 0xd7,
+	0x60, 0x09, 0x8f, 0xb0, 0x06, 0x00, 0x00,
 }
 
 func (x ValueType) String() string {
@@ -609,6 +642,9 @@ func (this *Statistics) Equal(that interface{}) bool {
 	if this.CardinalityCount != that1.CardinalityCount {
 		return false
 	}
+	if !bytes.Equal(this.BloomFilter, that1.BloomFilter) {
+		return false
+	}
 	return true
 }
 func (this *PageInfo) Equal(that interface{}) bool {
@@ -684,11 +720,12 @@ func (this *Statistics) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&datasetmd.Statistics{")
 	s = append(s, "MinValue: "+fmt.Sprintf("%#v", this.MinValue)+",\n")
 	s = append(s, "MaxValue: "+fmt.Sprintf("%#v", this.MaxValue)+",\n")
 	s = append(s, "CardinalityCount: "+fmt.Sprintf("%#v", this.CardinalityCount)+",\n")
+	s = append(s, "BloomFilter: "+fmt.Sprintf("%#v", this.BloomFilter)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -822,6 +859,13 @@ func (m *Statistics) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.BloomFilter) > 0 {
+		i -= len(m.BloomFilter)
+		copy(dAtA[i:], m.BloomFilter)
+		i = encodeVarintDatasetmd(dAtA, i, uint64(len(m.BloomFilter)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if m.CardinalityCount != 0 {
 		i = encodeVarintDatasetmd(dAtA, i, uint64(m.CardinalityCount))
 		i--
@@ -988,6 +1032,10 @@ func (m *Statistics) Size() (n int) {
 	if m.CardinalityCount != 0 {
 		n += 1 + sovDatasetmd(uint64(m.CardinalityCount))
 	}
+	l = len(m.BloomFilter)
+	if l > 0 {
+		n += 1 + l + sovDatasetmd(uint64(l))
+	}
 	return n
 }
 
@@ -1061,6 +1109,7 @@ func (this *Statistics) String() string {
 		`MinValue:` + fmt.Sprintf("%v", this.MinValue) + `,`,
 		`MaxValue:` + fmt.Sprintf("%v", this.MaxValue) + `,`,
 		`CardinalityCount:` + fmt.Sprintf("%v", this.CardinalityCount) + `,`,
+		`BloomFilter:` + fmt.Sprintf("%v", this.BloomFilter) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1480,6 +1529,40 @@ func (m *Statistics) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BloomFilter", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatasetmd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDatasetmd
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatasetmd
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BloomFilter = append(m.BloomFilter[:0], dAtA[iNdEx:postIndex]...)
+			if m.BloomFilter == nil {
+				m.BloomFilter = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipDatasetmd(dAtA[iNdEx:])
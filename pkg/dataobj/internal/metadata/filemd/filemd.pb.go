@@ -38,18 +38,24 @@ const (
 	// streams. Each log record contains a stream ID which refers to a stream
 	// from SECTION_TYPE_STREAMS.
 	SECTION_TYPE_LOGS SectionType = 2
+	// SECTION_TYPE_TOKENS is a section containing an inverted index of the
+	// tokens found in the log messages of the SECTION_TYPE_LOGS section which
+	// precedes it.
+	SECTION_TYPE_TOKENS SectionType = 3
 )
 
 var SectionType_name = map[int32]string{
 	0: "SECTION_TYPE_UNSPECIFIED",
 	1: "SECTION_TYPE_STREAMS",
 	2: "SECTION_TYPE_LOGS",
+	3: "SECTION_TYPE_TOKENS",
 }
 
 var SectionType_value = map[string]int32{
 	"SECTION_TYPE_UNSPECIFIED": 0,
 	"SECTION_TYPE_STREAMS":     1,
 	"SECTION_TYPE_LOGS":        2,
+	"SECTION_TYPE_TOKENS":      3,
 }
 
 func (SectionType) EnumDescriptor() ([]byte, []int) {
@@ -60,6 +66,9 @@ func (SectionType) EnumDescriptor() ([]byte, []int) {
 type Metadata struct {
 	// Sections within the data object.
 	Sections []*SectionInfo `protobuf:"bytes,1,rep,name=sections,proto3" json:"sections,omitempty"`
+	// Kafka records the data object was built from. Unset for data objects
+	// not built by the dataobj consumer, such as compacted data objects.
+	KafkaOffsets *KafkaOffsets `protobuf:"bytes,2,opt,name=kafka_offsets,json=kafkaOffsets,proto3" json:"kafka_offsets,omitempty"`
 }
 
 func (m *Metadata) Reset()      { *m = Metadata{} }
@@ -101,6 +110,13 @@ func (m *Metadata) GetSections() []*SectionInfo {
 	return nil
 }
 
+func (m *Metadata) GetKafkaOffsets() *KafkaOffsets {
+	if m != nil {
+		return m.KafkaOffsets
+	}
+	return nil
+}
+
 // SectionInfo describes a section within the data object.
 type SectionInfo struct {
 	// Type of the section within the data object.
@@ -164,10 +180,83 @@ func (m *SectionInfo) GetMetadataSize() uint64 {
 	return 0
 }
 
+// KafkaOffsets describes a range of records of a Kafka partition.
+type KafkaOffsets struct {
+	// Topic of the records.
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// Partition of the records within the topic.
+	Partition int32 `protobuf:"varint,2,opt,name=partition,proto3" json:"partition,omitempty"`
+	// Offset of the first record.
+	MinOffset int64 `protobuf:"varint,3,opt,name=min_offset,json=minOffset,proto3" json:"min_offset,omitempty"`
+	// Offset of the last record.
+	MaxOffset int64 `protobuf:"varint,4,opt,name=max_offset,json=maxOffset,proto3" json:"max_offset,omitempty"`
+}
+
+func (m *KafkaOffsets) Reset()      { *m = KafkaOffsets{} }
+func (*KafkaOffsets) ProtoMessage() {}
+func (*KafkaOffsets) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be80f52d1e05bad9, []int{2}
+}
+func (m *KafkaOffsets) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *KafkaOffsets) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_KafkaOffsets.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *KafkaOffsets) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KafkaOffsets.Merge(m, src)
+}
+func (m *KafkaOffsets) XXX_Size() int {
+	return m.Size()
+}
+func (m *KafkaOffsets) XXX_DiscardUnknown() {
+	xxx_messageInfo_KafkaOffsets.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KafkaOffsets proto.InternalMessageInfo
+
+func (m *KafkaOffsets) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *KafkaOffsets) GetPartition() int32 {
+	if m != nil {
+		return m.Partition
+	}
+	return 0
+}
+
+func (m *KafkaOffsets) GetMinOffset() int64 {
+	if m != nil {
+		return m.MinOffset
+	}
+	return 0
+}
+
+func (m *KafkaOffsets) GetMaxOffset() int64 {
+	if m != nil {
+		return m.MaxOffset
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("dataobj.metadata.file.v1.SectionType", SectionType_name, SectionType_value)
 	proto.RegisterType((*Metadata)(nil), "dataobj.metadata.file.v1.Metadata")
 	proto.RegisterType((*SectionInfo)(nil), "dataobj.metadata.file.v1.SectionInfo")
+	proto.RegisterType((*KafkaOffsets)(nil), "dataobj.metadata.file.v1.KafkaOffsets")
 }
 
 func init() {
@@ -175,30 +264,36 @@ func init() {
 }
 
 var fileDescriptor_be80f52d1e05bad9 = []byte{
-	// 356 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x32, 0x2c, 0xc8, 0x4e, 0xd7,
-	0x4f, 0x49, 0x2c, 0x49, 0xcc, 0x4f, 0xca, 0xd2, 0xcf, 0xcc, 0x2b, 0x49, 0x2d, 0xca, 0x4b, 0xcc,
-	0xd1, 0xcf, 0x4d, 0x2d, 0x49, 0x04, 0x09, 0xea, 0xa7, 0x65, 0xe6, 0xa4, 0xe6, 0xa6, 0x40, 0x29,
-	0xbd, 0x82, 0xa2, 0xfc, 0x92, 0x7c, 0x21, 0x09, 0xa8, 0x72, 0x3d, 0x98, 0x2a, 0x3d, 0x90, 0xb4,
-	0x5e, 0x99, 0xa1, 0x92, 0x2f, 0x17, 0x87, 0x2f, 0x54, 0x4c, 0xc8, 0x91, 0x8b, 0xa3, 0x38, 0x35,
-	0xb9, 0x24, 0x33, 0x3f, 0xaf, 0x58, 0x82, 0x51, 0x81, 0x59, 0x83, 0xdb, 0x48, 0x55, 0x0f, 0x97,
-	0x46, 0xbd, 0x60, 0x88, 0x4a, 0xcf, 0xbc, 0xb4, 0xfc, 0x20, 0xb8, 0x36, 0xa5, 0x69, 0x8c, 0x5c,
-	0xdc, 0x48, 0x32, 0x42, 0x96, 0x5c, 0x2c, 0x25, 0x95, 0x05, 0xa9, 0x12, 0x8c, 0x0a, 0x8c, 0x1a,
-	0x7c, 0x44, 0x18, 0x17, 0x52, 0x59, 0x90, 0x1a, 0x04, 0xd6, 0x22, 0xa4, 0xce, 0xc5, 0x0f, 0x53,
-	0x15, 0x9f, 0x9f, 0x96, 0x56, 0x9c, 0x5a, 0x22, 0xc1, 0xa4, 0xc0, 0xa8, 0xc1, 0x12, 0xc4, 0x07,
-	0x13, 0xf6, 0x07, 0x8b, 0x0a, 0x29, 0x73, 0xf1, 0xc2, 0x15, 0x16, 0x67, 0x56, 0xa5, 0x4a, 0x30,
-	0x83, 0x95, 0xf1, 0xc0, 0x04, 0x83, 0x33, 0xab, 0x52, 0xb5, 0x62, 0xe0, 0xee, 0x02, 0x59, 0x21,
-	0x24, 0xc3, 0x25, 0x11, 0xec, 0xea, 0x1c, 0xe2, 0xe9, 0xef, 0x17, 0x1f, 0x12, 0x19, 0xe0, 0x1a,
-	0x1f, 0xea, 0x17, 0x1c, 0xe0, 0xea, 0xec, 0xe9, 0xe6, 0xe9, 0xea, 0x22, 0xc0, 0x20, 0x24, 0xc1,
-	0x25, 0x82, 0x22, 0x1b, 0x1c, 0x12, 0xe4, 0xea, 0xe8, 0x1b, 0x2c, 0xc0, 0x28, 0x24, 0xca, 0x25,
-	0x88, 0x22, 0xe3, 0xe3, 0xef, 0x1e, 0x2c, 0xc0, 0xe4, 0x54, 0x7a, 0xe1, 0xa1, 0x1c, 0xc3, 0x8d,
-	0x87, 0x72, 0x0c, 0x1f, 0x1e, 0xca, 0x31, 0x36, 0x3c, 0x92, 0x63, 0x5c, 0xf1, 0x48, 0x8e, 0xf1,
-	0xc4, 0x23, 0x39, 0xc6, 0x0b, 0x8f, 0xe4, 0x18, 0x1f, 0x3c, 0x92, 0x63, 0x7c, 0xf1, 0x48, 0x8e,
-	0xe1, 0xc3, 0x23, 0x39, 0xc6, 0x09, 0x8f, 0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8, 0xf1, 0x58,
-	0x8e, 0x21, 0xca, 0x3e, 0x3d, 0xb3, 0x24, 0xa3, 0x34, 0x49, 0x2f, 0x39, 0x3f, 0x57, 0x3f, 0xbd,
-	0x28, 0x31, 0x2d, 0x31, 0x2f, 0x51, 0x3f, 0x27, 0x3f, 0x3b, 0x53, 0xbf, 0xcc, 0x58, 0x9f, 0x98,
-	0x38, 0x4e, 0x62, 0x03, 0xc7, 0xae, 0x31, 0x20, 0x00, 0x00, 0xff, 0xff, 0x18, 0x88, 0x8a, 0x94,
-	0x12, 0x02, 0x00, 0x00,
+	// 461 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0x3f, 0x6f, 0xd3, 0x40,
+	0x18, 0xc6, 0x7d, 0x4d, 0x8a, 0x9a, 0x37, 0x69, 0x09, 0x47, 0x11, 0x1e, 0xca, 0x29, 0x0a, 0x02,
+	0x22, 0x06, 0x5b, 0x6d, 0x27, 0x26, 0x54, 0x8a, 0x41, 0x51, 0x68, 0x52, 0xf9, 0xcc, 0x00, 0x4b,
+	0x74, 0x49, 0xcf, 0xe1, 0x70, 0xec, 0xb3, 0xe2, 0x4b, 0xd5, 0x76, 0xea, 0x47, 0x60, 0x62, 0xe0,
+	0x13, 0xf0, 0x51, 0x18, 0x33, 0x76, 0x24, 0xce, 0xc2, 0xd8, 0x8f, 0x80, 0xfc, 0xaf, 0x4d, 0x86,
+	0x4a, 0x9d, 0xac, 0x7b, 0x9e, 0xdf, 0xeb, 0xf7, 0x77, 0xd2, 0xc1, 0x6e, 0xe8, 0x8d, 0xcc, 0x13,
+	0xa6, 0x98, 0x1c, 0x7c, 0x37, 0x45, 0xa0, 0xf8, 0x24, 0x60, 0x63, 0xd3, 0xe7, 0x8a, 0x25, 0xa1,
+	0xe9, 0x8a, 0x31, 0xf7, 0x4f, 0xf2, 0x8f, 0x11, 0x4e, 0xa4, 0x92, 0x58, 0xcf, 0x71, 0xa3, 0xa0,
+	0x8c, 0xa4, 0x36, 0x4e, 0x77, 0x9b, 0xbf, 0x10, 0x6c, 0x1c, 0xe5, 0x21, 0x3e, 0x80, 0x8d, 0x88,
+	0x0f, 0x95, 0x90, 0x41, 0xa4, 0xa3, 0x46, 0xa9, 0x55, 0xdd, 0x7b, 0x61, 0xdc, 0x35, 0x69, 0xd0,
+	0x8c, 0x6c, 0x07, 0xae, 0xb4, 0x6f, 0xc6, 0x70, 0x07, 0x36, 0x3d, 0xe6, 0x7a, 0xac, 0x2f, 0x5d,
+	0x37, 0xe2, 0x2a, 0xd2, 0xd7, 0x1a, 0xa8, 0x55, 0xdd, 0x7b, 0x79, 0xf7, 0x7f, 0x3a, 0x09, 0xde,
+	0xcb, 0x68, 0xbb, 0xe6, 0x2d, 0x9d, 0x9a, 0x3f, 0x11, 0x54, 0x97, 0xd6, 0xe0, 0x37, 0x50, 0x56,
+	0xe7, 0x21, 0xd7, 0x51, 0x03, 0xb5, 0xb6, 0xee, 0xe1, 0xe6, 0x9c, 0x87, 0xdc, 0x4e, 0x47, 0xf0,
+	0x2b, 0x78, 0x58, 0x50, 0xb9, 0x5a, 0x6a, 0x56, 0xb6, 0xb7, 0x8a, 0x38, 0x5b, 0x8a, 0x9f, 0xc3,
+	0xe6, 0x0d, 0x18, 0x89, 0x0b, 0xae, 0x97, 0x52, 0xac, 0x56, 0x84, 0x54, 0x5c, 0xf0, 0xe6, 0x25,
+	0x82, 0xda, 0xb2, 0x37, 0xde, 0x86, 0x75, 0x25, 0x43, 0x31, 0x4c, 0xd5, 0x2a, 0x76, 0x76, 0xc0,
+	0x3b, 0x50, 0x09, 0xd9, 0x44, 0x89, 0xc4, 0x25, 0x5d, 0xb7, 0x6e, 0xdf, 0x06, 0xf8, 0x19, 0x80,
+	0x2f, 0x82, 0xc2, 0x26, 0x59, 0x53, 0xb2, 0x2b, 0xbe, 0x08, 0x72, 0x91, 0xa4, 0x66, 0x67, 0x45,
+	0x5d, 0xce, 0x6b, 0x76, 0x96, 0xd5, 0xaf, 0xa7, 0x50, 0x5d, 0xba, 0x25, 0xde, 0x01, 0x9d, 0x5a,
+	0x87, 0x4e, 0xbb, 0xd7, 0xed, 0x3b, 0x5f, 0x8e, 0xad, 0xfe, 0xe7, 0x2e, 0x3d, 0xb6, 0x0e, 0xdb,
+	0x1f, 0xda, 0xd6, 0xfb, 0xba, 0x86, 0x75, 0xd8, 0x5e, 0x69, 0xa9, 0x63, 0x5b, 0x07, 0x47, 0xb4,
+	0x8e, 0xf0, 0x13, 0x78, 0xb4, 0xd2, 0x7c, 0xea, 0x7d, 0xa4, 0xf5, 0x35, 0xfc, 0x14, 0x1e, 0xaf,
+	0xc4, 0x4e, 0xaf, 0x63, 0x75, 0x69, 0xbd, 0xf4, 0x6e, 0x3a, 0x9b, 0x13, 0xed, 0x6a, 0x4e, 0xb4,
+	0xeb, 0x39, 0x41, 0x97, 0x31, 0x41, 0xbf, 0x63, 0x82, 0xfe, 0xc4, 0x04, 0xcd, 0x62, 0x82, 0xfe,
+	0xc6, 0x04, 0xfd, 0x8b, 0x89, 0x76, 0x1d, 0x13, 0xf4, 0x63, 0x41, 0xb4, 0xd9, 0x82, 0x68, 0x57,
+	0x0b, 0xa2, 0x7d, 0x7d, 0x3b, 0x12, 0xea, 0xdb, 0x74, 0x60, 0x0c, 0xa5, 0x6f, 0x8e, 0x26, 0xcc,
+	0x65, 0x01, 0x33, 0xc7, 0xd2, 0x13, 0xe6, 0xe9, 0xbe, 0x79, 0x9f, 0xd7, 0x3c, 0x78, 0x90, 0xbe,
+	0xe3, 0xfd, 0xff, 0x03, 0x00, 0xa4, 0x64, 0x0f, 0x55, 0xfc, 0x02, 0x00, 0x00,
 }
 
 func (x SectionType) String() string {
@@ -235,6 +330,9 @@ func (this *Metadata) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if !this.KafkaOffsets.Equal(that1.KafkaOffsets) {
+		return false
+	}
 	return true
 }
 func (this *SectionInfo) Equal(that interface{}) bool {
@@ -267,15 +365,51 @@ func (this *SectionInfo) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *KafkaOffsets) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*KafkaOffsets)
+	if !ok {
+		that2, ok := that.(KafkaOffsets)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Topic != that1.Topic {
+		return false
+	}
+	if this.Partition != that1.Partition {
+		return false
+	}
+	if this.MinOffset != that1.MinOffset {
+		return false
+	}
+	if this.MaxOffset != that1.MaxOffset {
+		return false
+	}
+	return true
+}
 func (this *Metadata) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&filemd.Metadata{")
 	if this.Sections != nil {
 		s = append(s, "Sections: "+fmt.Sprintf("%#v", this.Sections)+",\n")
 	}
+	if this.KafkaOffsets != nil {
+		s = append(s, "KafkaOffsets: "+fmt.Sprintf("%#v", this.KafkaOffsets)+",\n")
+	}
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -291,6 +425,19 @@ func (this *SectionInfo) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *KafkaOffsets) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&filemd.KafkaOffsets{")
+	s = append(s, "Topic: "+fmt.Sprintf("%#v", this.Topic)+",\n")
+	s = append(s, "Partition: "+fmt.Sprintf("%#v", this.Partition)+",\n")
+	s = append(s, "MinOffset: "+fmt.Sprintf("%#v", this.MinOffset)+",\n")
+	s = append(s, "MaxOffset: "+fmt.Sprintf("%#v", this.MaxOffset)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringFilemd(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -319,6 +466,18 @@ func (m *Metadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.KafkaOffsets != nil {
+		{
+			size, err := m.KafkaOffsets.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintFilemd(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.Sections) > 0 {
 		for iNdEx := len(m.Sections) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -374,6 +533,51 @@ func (m *SectionInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *KafkaOffsets) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KafkaOffsets) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *KafkaOffsets) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.MaxOffset != 0 {
+		i = encodeVarintFilemd(dAtA, i, uint64(m.MaxOffset))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.MinOffset != 0 {
+		i = encodeVarintFilemd(dAtA, i, uint64(m.MinOffset))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Partition != 0 {
+		i = encodeVarintFilemd(dAtA, i, uint64(m.Partition))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Topic) > 0 {
+		i -= len(m.Topic)
+		copy(dAtA[i:], m.Topic)
+		i = encodeVarintFilemd(dAtA, i, uint64(len(m.Topic)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintFilemd(dAtA []byte, offset int, v uint64) int {
 	offset -= sovFilemd(v)
 	base := offset
@@ -397,6 +601,10 @@ func (m *Metadata) Size() (n int) {
 			n += 1 + l + sovFilemd(uint64(l))
 		}
 	}
+	if m.KafkaOffsets != nil {
+		l = m.KafkaOffsets.Size()
+		n += 1 + l + sovFilemd(uint64(l))
+	}
 	return n
 }
 
@@ -418,6 +626,28 @@ func (m *SectionInfo) Size() (n int) {
 	return n
 }
 
+func (m *KafkaOffsets) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Topic)
+	if l > 0 {
+		n += 1 + l + sovFilemd(uint64(l))
+	}
+	if m.Partition != 0 {
+		n += 1 + sovFilemd(uint64(m.Partition))
+	}
+	if m.MinOffset != 0 {
+		n += 1 + sovFilemd(uint64(m.MinOffset))
+	}
+	if m.MaxOffset != 0 {
+		n += 1 + sovFilemd(uint64(m.MaxOffset))
+	}
+	return n
+}
+
 func sovFilemd(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -435,6 +665,7 @@ func (this *Metadata) String() string {
 	repeatedStringForSections += "}"
 	s := strings.Join([]string{`&Metadata{`,
 		`Sections:` + repeatedStringForSections + `,`,
+		`KafkaOffsets:` + strings.Replace(this.KafkaOffsets.String(), "KafkaOffsets", "KafkaOffsets", 1) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -451,6 +682,19 @@ func (this *SectionInfo) String() string {
 	}, "")
 	return s
 }
+func (this *KafkaOffsets) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&KafkaOffsets{`,
+		`Topic:` + fmt.Sprintf("%v", this.Topic) + `,`,
+		`Partition:` + fmt.Sprintf("%v", this.Partition) + `,`,
+		`MinOffset:` + fmt.Sprintf("%v", this.MinOffset) + `,`,
+		`MaxOffset:` + fmt.Sprintf("%v", this.MaxOffset) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringFilemd(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -522,6 +766,42 @@ func (m *Metadata) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KafkaOffsets", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFilemd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFilemd
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthFilemd
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.KafkaOffsets == nil {
+				m.KafkaOffsets = &KafkaOffsets{}
+			}
+			if err := m.KafkaOffsets.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipFilemd(dAtA[iNdEx:])
@@ -656,6 +936,148 @@ func (m *SectionInfo) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *KafkaOffsets) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFilemd
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: KafkaOffsets: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: KafkaOffsets: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Topic", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFilemd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFilemd
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthFilemd
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Topic = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Partition", wireType)
+			}
+			m.Partition = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFilemd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Partition |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinOffset", wireType)
+			}
+			m.MinOffset = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFilemd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinOffset |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxOffset", wireType)
+			}
+			m.MaxOffset = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFilemd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxOffset |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFilemd(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthFilemd
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthFilemd
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipFilemd(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -120,13 +120,13 @@ func Decode(columns []*logsmd.ColumnDesc, row dataset.Row, record *Record) error
 			record.Timestamp = time.Unix(0, columnValue.Int64())
 
 		case logsmd.COLUMN_TYPE_METADATA:
-			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
-				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			// Typed metadata values are formatted back into their original
+			// string, reusing the existing buffer of the record.
+			target, err := AppendMetadataValue(record.Metadata[nextMetadataIdx].Value[:0], columnValue)
+			if err != nil {
+				return fmt.Errorf("invalid type %s for %s", columnValue.Type(), column.Type)
 			}
 
-			// Convert the target pointer to a byte slice and grow it if necessary.
-			target := slicegrow.Copy(record.Metadata[nextMetadataIdx].Value, columnValue.ByteArray())
-
 			record.Metadata[nextMetadataIdx].Name = column.Info.Name
 			record.Metadata[nextMetadataIdx].Value = target
 			nextMetadataIdx++
@@ -91,6 +91,28 @@ func TestDecode(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "typed metadata",
+			columns: []*logsmd.ColumnDesc{
+				{Type: logsmd.COLUMN_TYPE_METADATA, Info: &datasetmd.ColumnInfo{Name: "duration"}},
+				{Type: logsmd.COLUMN_TYPE_METADATA, Info: &datasetmd.ColumnInfo{Name: "size"}},
+				{Type: logsmd.COLUMN_TYPE_METADATA, Info: &datasetmd.ColumnInfo{Name: "start"}},
+			},
+			row: dataset.Row{
+				Values: []dataset.Value{
+					dataset.Int64Value(int64(1500 * time.Millisecond)),
+					dataset.Float64Value(2.5),
+					dataset.TimestampValue(1234567890000000000),
+				},
+			},
+			expected: Record{
+				Metadata: []RecordMetadata{
+					{Name: "duration", Value: []byte("1.5s")},
+					{Name: "size", Value: []byte("2.5")},
+					{Name: "start", Value: []byte("2009-02-13T23:31:30Z")},
+				},
+			},
+		},
 		{
 			name: "invalid metadata type",
 			columns: []*logsmd.ColumnDesc{
@@ -98,7 +120,7 @@ func TestDecode(t *testing.T) {
 			},
 			row: dataset.Row{
 				Values: []dataset.Value{
-					dataset.Int64Value(123),
+					dataset.Uint64Value(123),
 				},
 			},
 			wantErr: true,
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/klauspost/compress/zstd"
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/tokens"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/sliceclear"
 )
 
@@ -82,6 +84,8 @@ type Logs struct {
 
 	sections      []*table // Completed sections.
 	sectionBuffer tableBuffer
+
+	tokens *tokens.Index // Token index of the section being encoded.
 }
 
 // Nwe creates a new Logs section. The pageSize argument specifies how large
@@ -94,6 +98,8 @@ func New(metrics *Metrics, opts Options) *Logs {
 	return &Logs{
 		metrics: metrics,
 		opts:    opts,
+
+		tokens: tokens.New(opts.PageSizeHint),
 	}
 }
 
@@ -185,7 +191,8 @@ func (l *Logs) EstimatedSize() int {
 // log records are sorted by StreamID and Timestamp.
 //
 // EncodeTo may generate multiple sections if the list of log records is too
-// big to fit into a single section.
+// big to fit into a single section. Each logs section is followed by a tokens
+// section which indexes its log messages.
 //
 // [Logs.Reset] is invoked after encoding, even if encoding fails.
 func (l *Logs) EncodeTo(enc *encoding.Encoder) error {
@@ -209,6 +216,8 @@ func (l *Logs) EncodeTo(enc *encoding.Encoder) error {
 	for _, section := range l.sections {
 		if err := l.encodeSection(enc, section); err != nil {
 			return fmt.Errorf("encoding section: %w", err)
+		} else if err := l.encodeTokens(enc, section); err != nil {
+			return fmt.Errorf("encoding tokens section: %w", err)
 		}
 	}
 
@@ -242,6 +251,50 @@ func (l *Logs) encodeSection(enc *encoding.Encoder, section *table) error {
 	return logsEnc.Commit()
 }
 
+// encodeTokens encodes a tokens section holding the index of the log messages
+// of section. The tokens section must be encoded right after its logs section.
+func (l *Logs) encodeTokens(enc *encoding.Encoder, section *table) error {
+	r := dataset.NewReader(dataset.ReaderOptions{
+		Dataset: section,
+		Columns: []dataset.Column{section.StreamID, section.Message},
+	})
+	defer r.Close()
+
+	// Our table is in memory, so we don't need a "real" context in the calls
+	// below.
+	var (
+		rows [128]dataset.Row
+		row  int
+	)
+	for {
+		n, err := r.Read(context.Background(), rows[:])
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("reading section: %w", err)
+		}
+
+		for _, record := range rows[:n] {
+			var (
+				streamID int64
+				line     []byte
+			)
+			if !record.Values[0].IsNil() && !record.Values[0].IsZero() {
+				streamID = record.Values[0].Int64()
+			}
+			if !record.Values[1].IsNil() {
+				line = record.Values[1].ByteArray()
+			}
+			l.tokens.Append(row, streamID, line)
+			row++
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	return l.tokens.EncodeTo(enc)
+}
+
 func encodeColumn(enc *encoding.LogsEncoder, columnType logsmd.ColumnType, column dataset.Column) error {
 	columnEnc, err := enc.OpenColumn(columnType, column.ColumnInfo())
 	if err != nil {
@@ -291,4 +344,6 @@ func (l *Logs) Reset() {
 
 	l.sections = sliceclear.Clear(l.sections)
 	l.sectionBuffer.Reset()
+
+	l.tokens.Reset()
 }
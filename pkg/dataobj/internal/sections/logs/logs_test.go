@@ -3,6 +3,7 @@ package logs_test
 import (
 	"bytes"
 	"context"
+	"slices"
 	"testing"
 	"time"
 
@@ -83,6 +84,52 @@ func Test(t *testing.T) {
 	}
 }
 
+func Test_typedMetadata(t *testing.T) {
+	records := []logs.Record{
+		{
+			StreamID:  1,
+			Timestamp: time.Unix(1, 0),
+			Metadata:  []logs.RecordMetadata{{Name: "duration", Value: []byte("1.5s")}, {Name: "status", Value: []byte("200")}},
+			Line:      []byte("fast"),
+		},
+		{
+			StreamID:  1,
+			Timestamp: time.Unix(2, 0),
+			Metadata:  []logs.RecordMetadata{{Name: "duration", Value: []byte("2m0.5s")}, {Name: "status", Value: []byte("503")}},
+			Line:      []byte("slow"),
+		},
+	}
+
+	tracker := logs.New(nil, logs.Options{
+		PageSizeHint:     1024,
+		BufferSize:       256,
+		SectionSize:      4096,
+		StripeMergeLimit: 2,
+	})
+	for _, record := range records {
+		tracker.Append(record)
+	}
+
+	buf, err := buildObject(tracker)
+	require.NoError(t, err)
+
+	dec := encoding.ReaderAtDecoder(bytes.NewReader(buf), int64(len(buf)))
+
+	var actual []logs.Record
+	for result := range logs.Iter(context.Background(), dec) {
+		record, err := result.Value()
+		require.NoError(t, err)
+
+		record.Line = slices.Clone(record.Line)
+		record.Metadata = slices.Clone(record.Metadata)
+		for i := range record.Metadata {
+			record.Metadata[i].Value = slices.Clone(record.Metadata[i].Value)
+		}
+		actual = append(actual, record)
+	}
+	require.Equal(t, records, actual)
+}
+
 func buildObject(lt *logs.Logs) ([]byte, error) {
 	var buf bytes.Buffer
 	enc := encoding.NewEncoder(&buf)
@@ -0,0 +1,142 @@
+package logs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+)
+
+// Metadata values are received as strings, but often hold numbers, durations,
+// or timestamps. Storing these as typed values allows readers to filter them
+// by range using the min/max statistics of pages.
+//
+// The type of a metadata column is inferred from all of its values when the
+// column is built:
+//
+//   - [datasetmd.VALUE_TYPE_FLOAT64] for numbers, such as "200" or "0.25".
+//   - [datasetmd.VALUE_TYPE_INT64] for durations in nanoseconds, such as "1.5s".
+//   - [datasetmd.VALUE_TYPE_TIMESTAMP] for RFC3339 timestamps in UTC.
+//   - [datasetmd.VALUE_TYPE_BYTE_ARRAY] for everything else.
+//
+// A value only counts as typed if formatting the typed value gives back the
+// original string, so that metadata is always returned exactly as it was
+// received. Values which parse as zero are never typed, as zero values are
+// stored as NULL.
+
+// metadataTypes accumulates the inferred types of metadata columns by key.
+type metadataTypes map[string]datasetmd.ValueType
+
+// Add updates the inferred type of the column for key with value.
+func (t metadataTypes) Add(key string, value []byte) {
+	if len(value) == 0 {
+		// Empty values are stored as NULL regardless of the column type.
+		if _, ok := t[key]; !ok {
+			t[key] = datasetmd.VALUE_TYPE_UNSPECIFIED
+		}
+		return
+	}
+
+	existing, ok := t[key]
+	switch {
+	case existing == datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		// Once a column holds untyped values, it can't become typed again.
+	case !ok || existing == datasetmd.VALUE_TYPE_UNSPECIFIED:
+		t[key] = inferMetadataType(value)
+	case !metadataValueIs(existing, value):
+		t[key] = datasetmd.VALUE_TYPE_BYTE_ARRAY
+	}
+}
+
+// Get returns the inferred type of the column for key.
+func (t metadataTypes) Get(key string) datasetmd.ValueType {
+	if ty := t[key]; ty != datasetmd.VALUE_TYPE_UNSPECIFIED {
+		return ty
+	}
+	return datasetmd.VALUE_TYPE_BYTE_ARRAY
+}
+
+// inferMetadataType returns the most specific type which can hold value.
+func inferMetadataType(value []byte) datasetmd.ValueType {
+	for _, ty := range []datasetmd.ValueType{
+		datasetmd.VALUE_TYPE_FLOAT64,
+		datasetmd.VALUE_TYPE_INT64,
+		datasetmd.VALUE_TYPE_TIMESTAMP,
+	} {
+		if metadataValueIs(ty, value) {
+			return ty
+		}
+	}
+	return datasetmd.VALUE_TYPE_BYTE_ARRAY
+}
+
+func metadataValueIs(ty datasetmd.ValueType, value []byte) bool {
+	_, ok := ParseMetadataValue(ty, value)
+	return ok
+}
+
+// ParseMetadataValue parses a metadata value into a [dataset.Value] of type
+// ty. ParseMetadataValue returns false if value can't be stored losslessly
+// as ty. Empty values are parsed as NULL.
+func ParseMetadataValue(ty datasetmd.ValueType, value []byte) (dataset.Value, bool) {
+	if len(value) == 0 {
+		return dataset.Value{}, true
+	}
+
+	switch ty {
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		return dataset.ByteArrayValue(value), true
+
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		f, err := strconv.ParseFloat(string(value), 64)
+		if err != nil || f == 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+			return dataset.Value{}, false
+		} else if string(strconv.AppendFloat(nil, f, 'f', -1, 64)) != string(value) {
+			return dataset.Value{}, false
+		}
+		return dataset.Float64Value(f), true
+
+	case datasetmd.VALUE_TYPE_INT64:
+		d, err := time.ParseDuration(string(value))
+		if err != nil || d == 0 || d.String() != string(value) {
+			return dataset.Value{}, false
+		}
+		return dataset.Int64Value(int64(d)), true
+
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		t, err := time.Parse(time.RFC3339Nano, string(value))
+		if err != nil {
+			return dataset.Value{}, false
+		}
+
+		// Formatting the timestamp back also catches timestamps that don't fit
+		// in an int64 of nanoseconds.
+		ts := t.UnixNano()
+		if ts == 0 || time.Unix(0, ts).UTC().Format(time.RFC3339Nano) != string(value) {
+			return dataset.Value{}, false
+		}
+		return dataset.TimestampValue(ts), true
+	}
+
+	return dataset.Value{}, false
+}
+
+// AppendMetadataValue appends the string representation of a non-NULL value
+// of a metadata column to dst and returns the extended buffer.
+func AppendMetadataValue(dst []byte, value dataset.Value) ([]byte, error) {
+	switch ty := value.Type(); ty {
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		return append(dst, value.ByteArray()...), nil
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		return strconv.AppendFloat(dst, value.Float64(), 'f', -1, 64), nil
+	case datasetmd.VALUE_TYPE_INT64:
+		return append(dst, time.Duration(value.Int64()).String()...), nil
+	case datasetmd.VALUE_TYPE_TIMESTAMP:
+		return time.Unix(0, value.Timestamp()).UTC().AppendFormat(dst, time.RFC3339Nano), nil
+	default:
+		return dst, fmt.Errorf("invalid metadata value type %s", ty)
+	}
+}
@@ -0,0 +1,128 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+)
+
+func Test_inferMetadataType(t *testing.T) {
+	tt := []struct {
+		value  string
+		expect datasetmd.ValueType
+	}{
+		{"200", datasetmd.VALUE_TYPE_FLOAT64},
+		{"-0.25", datasetmd.VALUE_TYPE_FLOAT64},
+		{"1.50", datasetmd.VALUE_TYPE_BYTE_ARRAY}, // Would be formatted as 1.5.
+		{"1e5", datasetmd.VALUE_TYPE_BYTE_ARRAY},
+		{"0", datasetmd.VALUE_TYPE_BYTE_ARRAY}, // Zero values are stored as NULL.
+		{"12345678901234567890", datasetmd.VALUE_TYPE_BYTE_ARRAY},
+		{"NaN", datasetmd.VALUE_TYPE_BYTE_ARRAY},
+
+		{"1.5s", datasetmd.VALUE_TYPE_INT64},
+		{"1m30s", datasetmd.VALUE_TYPE_INT64},
+		{"90s", datasetmd.VALUE_TYPE_BYTE_ARRAY}, // Would be formatted as 1m30s.
+		{"0s", datasetmd.VALUE_TYPE_BYTE_ARRAY},
+
+		{"2025-01-01T12:00:00.5Z", datasetmd.VALUE_TYPE_TIMESTAMP},
+		{"2025-01-01T12:00:00+01:00", datasetmd.VALUE_TYPE_BYTE_ARRAY},
+		{"2025-01-01", datasetmd.VALUE_TYPE_BYTE_ARRAY},
+
+		{"4bf92f3577b34da6a3ce929d0e0e4736", datasetmd.VALUE_TYPE_BYTE_ARRAY},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.value, func(t *testing.T) {
+			actual := inferMetadataType([]byte(tc.value))
+			require.Equal(t, tc.expect, actual)
+
+			value, ok := ParseMetadataValue(actual, []byte(tc.value))
+			require.True(t, ok)
+
+			formatted, err := AppendMetadataValue(nil, value)
+			require.NoError(t, err)
+			require.Equal(t, tc.value, string(formatted), "typed values must format back to the original value")
+		})
+	}
+}
+
+func Test_metadataTypes(t *testing.T) {
+	types := make(metadataTypes)
+
+	for _, v := range []string{"", "200", "404"} {
+		types.Add("status", []byte(v))
+	}
+	for _, v := range []string{"150ms", "2s"} {
+		types.Add("duration", []byte(v))
+	}
+	for _, v := range []string{"1.5", "1.5s", "2"} {
+		types.Add("mixed", []byte(v))
+	}
+	types.Add("empty", nil)
+
+	require.Equal(t, datasetmd.VALUE_TYPE_FLOAT64, types.Get("status"))
+	require.Equal(t, datasetmd.VALUE_TYPE_INT64, types.Get("duration"))
+	require.Equal(t, datasetmd.VALUE_TYPE_BYTE_ARRAY, types.Get("mixed"))
+	require.Equal(t, datasetmd.VALUE_TYPE_BYTE_ARRAY, types.Get("empty"))
+}
+
+func Test_mergeTables_metadataTypes(t *testing.T) {
+	var buf tableBuffer
+
+	var (
+		tableA = buildTable(&buf, 1024, dataset.CompressionOptions{}, []Record{
+			{StreamID: 1, Timestamp: time.Unix(1, 0), Line: []byte("a"), Metadata: []RecordMetadata{
+				{Name: "latency", Value: []byte("1.5")},
+				{Name: "status", Value: []byte("200")},
+			}},
+		})
+		tableB = buildTable(&buf, 1024, dataset.CompressionOptions{}, []Record{
+			{StreamID: 1, Timestamp: time.Unix(2, 0), Line: []byte("b"), Metadata: []RecordMetadata{
+				{Name: "latency", Value: []byte("slow")},
+				{Name: "status", Value: []byte("500")},
+			}},
+		})
+	)
+	require.Equal(t, datasetmd.VALUE_TYPE_FLOAT64, tableA.Metadatas[0].Info.Type)
+	require.Equal(t, datasetmd.VALUE_TYPE_BYTE_ARRAY, tableB.Metadatas[0].Info.Type)
+
+	merged, err := mergeTables(&buf, 1024, dataset.CompressionOptions{}, []*table{tableA, tableB})
+	require.NoError(t, err)
+	require.Len(t, merged.Metadatas, 2)
+
+	// Columns with conflicting types fall back to byte arrays, while columns
+	// of the same type keep it.
+	require.Equal(t, "latency", merged.Metadatas[0].Info.Name)
+	require.Equal(t, datasetmd.VALUE_TYPE_BYTE_ARRAY, merged.Metadatas[0].Info.Type)
+	require.Equal(t, "status", merged.Metadatas[1].Info.Name)
+	require.Equal(t, datasetmd.VALUE_TYPE_FLOAT64, merged.Metadatas[1].Info.Type)
+
+	r := dataset.NewReader(dataset.ReaderOptions{
+		Dataset: merged,
+		Columns: []dataset.Column{merged.Metadatas[0]},
+	})
+	defer r.Close()
+
+	var latencies []string
+	rows := make([]dataset.Row, 8)
+	for {
+		n, err := r.Read(context.Background(), rows)
+		if err != nil && !errors.Is(err, io.EOF) {
+			require.NoError(t, err)
+		}
+		for _, row := range rows[:n] {
+			latencies = append(latencies, string(row.Values[0].ByteArray()))
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	require.Equal(t, []string{"1.5", "slow"}, latencies)
+}
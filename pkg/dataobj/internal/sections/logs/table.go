@@ -103,8 +103,8 @@ type tableBuffer struct {
 	timestamp *dataset.ColumnBuilder
 
 	metadatas      []*dataset.ColumnBuilder
-	metadataLookup map[string]int                    // map of metadata key to index in metadatas
-	usedMetadatas  map[*dataset.ColumnBuilder]string // metadata with its name.
+	metadataLookup map[metadataColumnKey]int                    // map of metadata key to index in metadatas
+	usedMetadatas  map[*dataset.ColumnBuilder]metadataColumnKey // metadata with its key.
 
 	message *dataset.ColumnBuilder
 }
@@ -161,29 +161,52 @@ func (b *tableBuffer) Timestamp(pageSize int) *dataset.ColumnBuilder {
 	return col
 }
 
-// Metadata gets or creates a metadata column for the buffer. To remove created
-// metadata columns, call [tableBuffer.CleanupMetadatas].
-func (b *tableBuffer) Metadata(key string, pageSize int, compressionOpts dataset.CompressionOptions) *dataset.ColumnBuilder {
+// metadataColumnKey identifies a metadata column of a tableBuffer. The same
+// metadata key may be stored with different value types across tables.
+type metadataColumnKey struct {
+	Name string
+	Type datasetmd.ValueType
+}
+
+// Metadata gets or creates a metadata column for the buffer, holding values
+// of the given type. Only one metadata column per key may be used between
+// flushes.
+func (b *tableBuffer) Metadata(key string, valueType datasetmd.ValueType, pageSize int, compressionOpts dataset.CompressionOptions) *dataset.ColumnBuilder {
 	if b.usedMetadatas == nil {
-		b.usedMetadatas = make(map[*dataset.ColumnBuilder]string)
+		b.usedMetadatas = make(map[*dataset.ColumnBuilder]metadataColumnKey)
 	}
 
-	index, ok := b.metadataLookup[key]
+	columnKey := metadataColumnKey{Name: key, Type: valueType}
+	index, ok := b.metadataLookup[columnKey]
 	if ok {
 		builder := b.metadatas[index]
-		b.usedMetadatas[builder] = key
+		b.usedMetadatas[builder] = columnKey
 		return builder
 	}
 
+	// Dictionary encoding only applies to byte arrays; numbers and timestamps
+	// are rarely repeated enough for it to pay off.
+	encoding := datasetmd.ENCODING_TYPE_DICTIONARY
+	switch valueType {
+	case datasetmd.VALUE_TYPE_FLOAT64:
+		encoding = datasetmd.ENCODING_TYPE_PLAIN
+	case datasetmd.VALUE_TYPE_INT64, datasetmd.VALUE_TYPE_TIMESTAMP:
+		encoding = datasetmd.ENCODING_TYPE_DELTA
+	}
+
 	col, err := dataset.NewColumnBuilder(key, dataset.BuilderOptions{
 		PageSizeHint:       pageSize,
-		Value:              datasetmd.VALUE_TYPE_BYTE_ARRAY,
-		Encoding:           datasetmd.ENCODING_TYPE_PLAIN,
+		Value:              valueType,
+		Encoding:           encoding,
 		Compression:        datasetmd.COMPRESSION_TYPE_ZSTD,
 		CompressionOptions: compressionOpts,
 		Statistics: dataset.StatisticsOptions{
 			StoreRangeStats:       true,
 			StoreCardinalityStats: true,
+
+			// Metadata often holds high cardinality values such as trace IDs,
+			// for which range stats can't prune pages on equality.
+			StoreBloomFilter: true,
 		},
 	})
 	if err != nil {
@@ -196,10 +219,10 @@ func (b *tableBuffer) Metadata(key string, pageSize int, compressionOpts dataset
 	b.metadatas = append(b.metadatas, col)
 
 	if b.metadataLookup == nil {
-		b.metadataLookup = make(map[string]int)
+		b.metadataLookup = make(map[metadataColumnKey]int)
 	}
-	b.metadataLookup[key] = len(b.metadatas) - 1
-	b.usedMetadatas[col] = key
+	b.metadataLookup[columnKey] = len(b.metadatas) - 1
+	b.usedMetadatas[col] = columnKey
 	return col
 }
 
@@ -254,7 +277,7 @@ func (b *tableBuffer) Reset() {
 	// retain the columns that were used in the last Flush.
 	var (
 		newMetadatas      = make([]*dataset.ColumnBuilder, 0, len(b.metadatas))
-		newMetadataLookup = make(map[string]int, len(b.metadatas))
+		newMetadataLookup = make(map[metadataColumnKey]int, len(b.metadatas))
 	)
 	for _, md := range b.metadatas {
 		if b.usedMetadatas == nil {
@@ -9,11 +9,21 @@ import (
 
 // buildTable builds a table from the set of provided records. The records are
 // sorted with [sortRecords] prior to building the table.
+//
+// The value type of each metadata column is inferred from the values of the
+// records; see [metadataTypes].
 func buildTable(buf *tableBuffer, pageSize int, compressionOpts dataset.CompressionOptions, records []Record) *table {
 	sortRecords(records)
 
 	buf.Reset()
 
+	types := make(metadataTypes)
+	for _, record := range records {
+		for _, md := range record.Metadata {
+			types.Add(md.Name, md.Value)
+		}
+	}
+
 	var (
 		streamIDBuilder  = buf.StreamID(pageSize)
 		timestampBuilder = buf.Timestamp(pageSize)
@@ -30,8 +40,13 @@ func buildTable(buf *tableBuffer, pageSize int, compressionOpts dataset.Compress
 		_ = messageBuilder.Append(i, dataset.ByteArrayValue(record.Line))
 
 		for _, md := range record.Metadata {
-			metadataBuilder := buf.Metadata(md.Name, pageSize, compressionOpts)
-			_ = metadataBuilder.Append(i, dataset.ByteArrayValue(md.Value))
+			valueType := types.Get(md.Name)
+
+			// Values always parse as the inferred type of their column.
+			value, _ := ParseMetadataValue(valueType, md.Value)
+
+			metadataBuilder := buf.Metadata(md.Name, valueType, pageSize, compressionOpts)
+			_ = metadataBuilder.Append(i, value)
 		}
 	}
 
@@ -9,6 +9,7 @@ import (
 	"math"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
 	"github.com/grafana/loki/v3/pkg/util/loser"
@@ -61,6 +62,19 @@ func mergeTables(buf *tableBuffer, pageSize int, compressionOpts dataset.Compres
 		messageBuilder   = buf.Message(pageSize, compressionOpts)
 	)
 
+	// Tables may have inferred different types for the same metadata key, in
+	// which case the merged column falls back to byte arrays.
+	types := make(map[string]datasetmd.ValueType)
+	for _, t := range tables {
+		for _, column := range t.Metadatas {
+			name, valueType := column.Info.Name, column.Info.Type
+			if existing, ok := types[name]; ok && existing != valueType {
+				valueType = datasetmd.VALUE_TYPE_BYTE_ARRAY
+			}
+			types[name] = valueType
+		}
+	}
+
 	var (
 		tableSequences = make([]*tableSequence, 0, len(tables))
 	)
@@ -118,7 +132,19 @@ func mergeTables(buf *tableBuffer, pageSize int, compressionOpts dataset.Compres
 			case logsmd.COLUMN_TYPE_TIMESTAMP:
 				_ = timestampBuilder.Append(rows, value)
 			case logsmd.COLUMN_TYPE_METADATA:
-				columnBuilder := buf.Metadata(column.Info.Name, pageSize, compressionOpts)
+				valueType := types[column.Info.Name]
+				if !value.IsNil() && value.Type() != valueType {
+					// The value is typed but the merged column isn't. Values are
+					// appended without being copied, so each converted value needs
+					// its own buffer.
+					converted, err := AppendMetadataValue(nil, value)
+					if err != nil {
+						return nil, err
+					}
+					value = dataset.ByteArrayValue(converted)
+				}
+
+				columnBuilder := buf.Metadata(column.Info.Name, valueType, pageSize, compressionOpts)
 				_ = columnBuilder.Append(rows, value)
 			case logsmd.COLUMN_TYPE_MESSAGE:
 				_ = messageBuilder.Append(rows, value)
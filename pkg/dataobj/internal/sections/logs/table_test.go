@@ -19,15 +19,15 @@ func Test_table_metadataCleanup(t *testing.T) {
 	var buf tableBuffer
 	initBuffer(&buf)
 
-	_ = buf.Metadata("foo", 1024, dataset.CompressionOptions{})
-	_ = buf.Metadata("bar", 1024, dataset.CompressionOptions{})
+	_ = buf.Metadata("foo", datasetmd.VALUE_TYPE_BYTE_ARRAY, 1024, dataset.CompressionOptions{})
+	_ = buf.Metadata("bar", datasetmd.VALUE_TYPE_BYTE_ARRAY, 1024, dataset.CompressionOptions{})
 
 	table, err := buf.Flush()
 	require.NoError(t, err)
 	require.Equal(t, 2, len(table.Metadatas))
 
 	initBuffer(&buf)
-	_ = buf.Metadata("bar", 1024, dataset.CompressionOptions{})
+	_ = buf.Metadata("bar", datasetmd.VALUE_TYPE_BYTE_ARRAY, 1024, dataset.CompressionOptions{})
 
 	table, err = buf.Flush()
 	require.NoError(t, err)
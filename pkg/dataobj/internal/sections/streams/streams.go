@@ -5,6 +5,7 @@ package streams
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -114,6 +115,21 @@ func (s *Streams) Record(streamLabels labels.Labels, ts time.Time, recordSize in
 	return stream.ID
 }
 
+// LabelValues returns the distinct values of each label across all streams,
+// by label name. Values are sorted.
+func (s *Streams) LabelValues() map[string][]string {
+	res := make(map[string][]string)
+	for _, stream := range s.ordered {
+		for _, l := range stream.Labels {
+			values := res[l.Name]
+			if i, found := slices.BinarySearch(values, l.Value); !found {
+				res[l.Name] = slices.Insert(values, i, l.Value)
+			}
+		}
+	}
+	return res
+}
+
 func (s *Streams) observeRecord(ts time.Time) {
 	s.metrics.recordsTotal.Inc()
 
@@ -264,7 +280,7 @@ func (s *Streams) EncodeTo(enc *encoding.Encoder) error {
 		builder, err := dataset.NewColumnBuilder(name, dataset.BuilderOptions{
 			PageSizeHint: s.pageSize,
 			Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
-			Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+			Encoding:     datasetmd.ENCODING_TYPE_DICTIONARY,
 			Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
 			Statistics: dataset.StatisticsOptions{
 				StoreRangeStats: true,
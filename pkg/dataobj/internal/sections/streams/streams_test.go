@@ -90,3 +90,16 @@ func buildObject(st *streams.Streams) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+func TestStreams_LabelValues(t *testing.T) {
+	tracker := streams.New(nil, 1024)
+	tracker.Record(labels.FromStrings("cluster", "test", "app", "foo"), time.Unix(10, 0), 10)
+	tracker.Record(labels.FromStrings("cluster", "test", "app", "bar"), time.Unix(20, 0), 10)
+	tracker.Record(labels.FromStrings("cluster", "test", "app", "foo", "env", "prod"), time.Unix(30, 0), 10)
+
+	require.Equal(t, map[string][]string{
+		"app":     {"bar", "foo"},
+		"cluster": {"test"},
+		"env":     {"prod"},
+	}, tracker.LabelValues())
+}
@@ -0,0 +1,138 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
+)
+
+// Tokens returns the distinct tokens of s, in the order they first appear. No
+// tokens are returned if s is shorter than [TokenSize].
+func Tokens(s []byte) [][]byte {
+	var (
+		seen   = make(map[token]struct{})
+		tokens [][]byte
+	)
+
+	for i := 0; i+TokenSize <= len(s); i++ {
+		tok := token(s[i : i+TokenSize])
+		if _, ok := seen[tok]; ok {
+			continue
+		}
+		seen[tok] = struct{}{}
+		tokens = append(tokens, s[i:i+TokenSize])
+	}
+
+	return tokens
+}
+
+// Lookup returns the ranges of rows of the logs section indexed by the tokens
+// section which may contain all of the provided tokens. Returned ranges are
+// sorted and don't overlap. Rows outside of the returned ranges are
+// guaranteed to not contain all of the tokens.
+//
+// Lookup must be called with at least one token.
+func Lookup(ctx context.Context, dec encoding.TokensDecoder, section *filemd.SectionInfo, tokens [][]byte) ([]dataset.RowRange, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("no tokens to look up")
+	}
+
+	// We need to pull the columns twice: once from the dataset implementation
+	// and once for the metadata to retrieve column type.
+	columnDescs, err := dec.Columns(ctx, section)
+	if err != nil {
+		return nil, err
+	}
+
+	dset := encoding.TokensDataset(dec, section)
+
+	columns, err := result.Collect(dset.ListColumns(ctx))
+	if err != nil {
+		return nil, err
+	} else if len(columns) != len(columnDescs) {
+		return nil, fmt.Errorf("expected %d columns, got %d", len(columnDescs), len(columns))
+	}
+
+	var tokenColumn, rowStartColumn, rowEndColumn dataset.Column
+	for i, desc := range columnDescs {
+		switch desc.Type {
+		case tokensmd.COLUMN_TYPE_TOKEN:
+			tokenColumn = columns[i]
+		case tokensmd.COLUMN_TYPE_ROW_START:
+			rowStartColumn = columns[i]
+		case tokensmd.COLUMN_TYPE_ROW_END:
+			rowEndColumn = columns[i]
+		}
+	}
+	if tokenColumn == nil || rowStartColumn == nil || rowEndColumn == nil {
+		return nil, errors.New("tokens section is missing columns")
+	}
+
+	values := make([]dataset.Value, len(tokens))
+	for i, tok := range tokens {
+		values[i] = dataset.ByteArrayValue(tok)
+	}
+
+	r := dataset.NewReader(dataset.ReaderOptions{
+		Dataset:   dset,
+		Columns:   []dataset.Column{tokenColumn, rowStartColumn, rowEndColumn},
+		Predicate: dataset.InPredicate{Column: tokenColumn, Values: values},
+	})
+	defer r.Close()
+
+	// Rows are sorted by token and then by row start, so the ranges of each
+	// token are sorted.
+	found := make(map[string][]dataset.RowRange, len(tokens))
+
+	buf := make([]dataset.Row, 128)
+	for {
+		n, err := r.Read(ctx, buf)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		for _, row := range buf[:n] {
+			tok := string(row.Values[0].ByteArray())
+			found[tok] = append(found[tok], dataset.RowRange{
+				Start: uint64(int64Value(row.Values[1])),
+				End:   uint64(int64Value(row.Values[2])),
+			})
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	var ranges []dataset.RowRange
+	for i, tok := range tokens {
+		tokenRanges, ok := found[string(tok)]
+		if !ok {
+			// None of the rows contain this token.
+			return nil, nil
+		}
+
+		if i == 0 {
+			ranges = tokenRanges
+			continue
+		}
+		ranges = dataset.IntersectRowRanges(ranges, tokenRanges)
+	}
+	return ranges, nil
+}
+
+// int64Value returns the integer held by v. Zero integers are stored as NULL
+// values.
+func int64Value(v dataset.Value) int64 {
+	if v.IsNil() || v.IsZero() {
+		return 0
+	}
+	return v.Int64()
+}
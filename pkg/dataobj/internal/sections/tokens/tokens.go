@@ -0,0 +1,244 @@
+// Package tokens defines types used for the data object tokens section. The
+// tokens section holds an inverted index of the tokens found in the log
+// messages of a logs section, mapping each token to the ranges of rows it may
+// be found in.
+package tokens
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
+)
+
+// TokenSize is the size of tokens in bytes. Tokens are the overlapping
+// trigrams of log messages.
+const TokenSize = 3
+
+// Rows of the same stream are grouped into granules, which are the smallest
+// unit of rows the index can point to. Granules are closed when the stream
+// changes or once they exceed either limit below.
+const (
+	maxGranuleRows  = 1024
+	maxGranuleBytes = 256 << 10
+)
+
+type token [TokenSize]byte
+
+// A posting is a range of rows of a single stream in which a token may be
+// found.
+type posting struct {
+	StreamID   int64
+	Start, End int
+}
+
+// An Index accumulates the tokens of the log messages of a single logs
+// section.
+type Index struct {
+	pageSize int
+
+	postings map[token][]posting
+
+	granule        map[token]struct{} // Tokens of the current granule.
+	granuleStream  int64
+	granuleStart   int
+	granuleEnd     int
+	granuleRows    int
+	granuleBytes   int
+	granuleStarted bool
+}
+
+// New creates a new Index. The pageSize argument specifies how large pages
+// should be.
+func New(pageSize int) *Index {
+	return &Index{
+		pageSize: pageSize,
+
+		postings: make(map[token][]posting),
+		granule:  make(map[token]struct{}),
+	}
+}
+
+// Append indexes the tokens of the log message at the given row of the logs
+// section. Rows must be appended in ascending order.
+func (idx *Index) Append(row int, streamID int64, line []byte) {
+	if idx.granuleStarted && (streamID != idx.granuleStream || idx.granuleRows >= maxGranuleRows || idx.granuleBytes >= maxGranuleBytes) {
+		idx.flushGranule()
+	}
+
+	if !idx.granuleStarted {
+		idx.granuleStarted = true
+		idx.granuleStream = streamID
+		idx.granuleStart = row
+	}
+
+	for i := 0; i+TokenSize <= len(line); i++ {
+		idx.granule[token(line[i:i+TokenSize])] = struct{}{}
+	}
+
+	idx.granuleEnd = row
+	idx.granuleRows++
+	idx.granuleBytes += len(line)
+}
+
+// flushGranule adds the tokens of the current granule to the postings.
+// Postings of a token are extended rather than appended when the token was
+// also found in the previous granule of the same stream.
+func (idx *Index) flushGranule() {
+	if !idx.granuleStarted {
+		return
+	}
+
+	for tok := range idx.granule {
+		list := idx.postings[tok]
+		if n := len(list); n > 0 && list[n-1].StreamID == idx.granuleStream && list[n-1].End+1 == idx.granuleStart {
+			list[n-1].End = idx.granuleEnd
+			continue
+		}
+
+		idx.postings[tok] = append(list, posting{
+			StreamID: idx.granuleStream,
+			Start:    idx.granuleStart,
+			End:      idx.granuleEnd,
+		})
+	}
+
+	clear(idx.granule)
+	idx.granuleStarted = false
+	idx.granuleRows = 0
+	idx.granuleBytes = 0
+}
+
+// EncodeTo encodes the index to the provided encoder as a single tokens
+// section. Nothing is encoded if no tokens were indexed.
+//
+// [Index.Reset] is invoked after encoding, even if encoding fails.
+func (idx *Index) EncodeTo(enc *encoding.Encoder) error {
+	defer idx.Reset()
+
+	idx.flushGranule()
+	if len(idx.postings) == 0 {
+		return nil
+	}
+
+	tokenBuilder, err := dataset.NewColumnBuilder("", dataset.BuilderOptions{
+		PageSizeHint: idx.pageSize,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+		Statistics: dataset.StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating token column: %w", err)
+	}
+	streamIDBuilder, err := numberColumnBuilder(idx.pageSize)
+	if err != nil {
+		return fmt.Errorf("creating stream ID column: %w", err)
+	}
+	rowStartBuilder, err := numberColumnBuilder(idx.pageSize)
+	if err != nil {
+		return fmt.Errorf("creating row start column: %w", err)
+	}
+	rowEndBuilder, err := numberColumnBuilder(idx.pageSize)
+	if err != nil {
+		return fmt.Errorf("creating row end column: %w", err)
+	}
+
+	// Rows are sorted by token so that the range statistics of the token column
+	// can be used to skip pages when looking up tokens.
+	tokens := make([]token, 0, len(idx.postings))
+	for tok := range idx.postings {
+		tokens = append(tokens, tok)
+	}
+	slices.SortFunc(tokens, func(a, b token) int { return bytes.Compare(a[:], b[:]) })
+
+	var row int
+	for _, tok := range tokens {
+		for _, p := range idx.postings[tok] {
+			// Append only fails if the rows are out-of-order, which can't happen
+			// here.
+			_ = tokenBuilder.Append(row, dataset.ByteArrayValue(tok[:]))
+			_ = streamIDBuilder.Append(row, dataset.Int64Value(p.StreamID))
+			_ = rowStartBuilder.Append(row, dataset.Int64Value(int64(p.Start)))
+			_ = rowEndBuilder.Append(row, dataset.Int64Value(int64(p.End)))
+			row++
+		}
+	}
+
+	tokensEnc, err := enc.OpenTokens()
+	if err != nil {
+		return fmt.Errorf("opening tokens section: %w", err)
+	}
+	defer func() {
+		// Discard on defer for safety. This will return an error if we
+		// successfully committed.
+		_ = tokensEnc.Discard()
+	}()
+
+	{
+		var errs []error
+		errs = append(errs, encodeColumn(tokensEnc, tokensmd.COLUMN_TYPE_TOKEN, tokenBuilder))
+		errs = append(errs, encodeColumn(tokensEnc, tokensmd.COLUMN_TYPE_STREAM_ID, streamIDBuilder))
+		errs = append(errs, encodeColumn(tokensEnc, tokensmd.COLUMN_TYPE_ROW_START, rowStartBuilder))
+		errs = append(errs, encodeColumn(tokensEnc, tokensmd.COLUMN_TYPE_ROW_END, rowEndBuilder))
+		if err := errors.Join(errs...); err != nil {
+			return fmt.Errorf("encoding columns: %w", err)
+		}
+	}
+
+	return tokensEnc.Commit()
+}
+
+func numberColumnBuilder(pageSize int) (*dataset.ColumnBuilder, error) {
+	return dataset.NewColumnBuilder("", dataset.BuilderOptions{
+		PageSizeHint: pageSize,
+		Value:        datasetmd.VALUE_TYPE_INT64,
+		Encoding:     datasetmd.ENCODING_TYPE_DELTA,
+		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		Statistics: dataset.StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	})
+}
+
+func encodeColumn(enc *encoding.TokensEncoder, columnType tokensmd.ColumnType, builder *dataset.ColumnBuilder) error {
+	column, err := builder.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing %s column: %w", columnType, err)
+	}
+
+	columnEnc, err := enc.OpenColumn(columnType, &column.Info)
+	if err != nil {
+		return fmt.Errorf("opening %s column encoder: %w", columnType, err)
+	}
+	defer func() {
+		// Discard on defer for safety. This will return an error if we
+		// successfully committed.
+		_ = columnEnc.Discard()
+	}()
+
+	for _, page := range column.Pages {
+		err := columnEnc.AppendPage(page)
+		if err != nil {
+			return fmt.Errorf("appending %s page: %w", columnType, err)
+		}
+	}
+
+	return columnEnc.Commit()
+}
+
+// Reset resets all state, allowing the Index to be reused.
+func (idx *Index) Reset() {
+	clear(idx.postings)
+	clear(idx.granule)
+	idx.granuleStarted = false
+	idx.granuleRows = 0
+	idx.granuleBytes = 0
+}
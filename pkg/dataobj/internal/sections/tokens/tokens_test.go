@@ -0,0 +1,124 @@
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
+)
+
+func TestTokens(t *testing.T) {
+	var actual []string
+	for _, tok := range Tokens([]byte("errors error")) {
+		actual = append(actual, string(tok))
+	}
+	require.Equal(t, []string{"err", "rro", "ror", "ors", "rs ", "s e", " er"}, actual)
+
+	require.Empty(t, Tokens([]byte("ab")))
+}
+
+func TestIndex(t *testing.T) {
+	type line struct {
+		streamID int64
+		line     string
+	}
+
+	lines := []line{
+		{1, "level=info msg=started"},
+		{1, "level=error msg=failed"},
+		{1, "level=info msg=stopped"},
+		{2, "level=warn msg=retrying"},
+		{2, "level=error msg=failed again"},
+		{3, "ok"},
+	}
+
+	idx := New(1024)
+	for row, l := range lines {
+		idx.Append(row, l.streamID, []byte(l.line))
+	}
+
+	dec, section := encodeIndex(t, idx)
+
+	tt := []struct {
+		name   string
+		substr string
+		expect []dataset.RowRange
+	}{
+		{
+			name:   "present in all streams",
+			substr: "level=",
+			expect: []dataset.RowRange{{Start: 0, End: 2}, {Start: 3, End: 4}},
+		},
+		{
+			name:   "present in a single stream",
+			substr: "again",
+			expect: []dataset.RowRange{{Start: 3, End: 4}},
+		},
+		{
+			name:   "tokens spread across streams",
+			substr: "info msg=retrying",
+			expect: nil,
+		},
+		{
+			name:   "absent token",
+			substr: "debug",
+			expect: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Lookup(context.Background(), dec, section, Tokens([]byte(tc.substr)))
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+func TestIndex_Granules(t *testing.T) {
+	idx := New(1024)
+
+	// The first granule of the stream only contains "foo", while the remaining
+	// granules only contain "bar".
+	rows := maxGranuleRows * 3
+	for row := range rows {
+		line := "bar"
+		if row < maxGranuleRows {
+			line = "foo"
+		}
+		idx.Append(row, 1, []byte(line))
+	}
+
+	dec, section := encodeIndex(t, idx)
+
+	actual, err := Lookup(context.Background(), dec, section, Tokens([]byte("foo")))
+	require.NoError(t, err)
+	require.Equal(t, []dataset.RowRange{{Start: 0, End: maxGranuleRows - 1}}, actual)
+
+	// Postings of adjacent granules are merged.
+	actual, err = Lookup(context.Background(), dec, section, Tokens([]byte("bar")))
+	require.NoError(t, err)
+	require.Equal(t, []dataset.RowRange{{Start: maxGranuleRows, End: uint64(rows) - 1}}, actual)
+}
+
+func encodeIndex(t *testing.T, idx *Index) (encoding.TokensDecoder, *filemd.SectionInfo) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := encoding.NewEncoder(&buf)
+	require.NoError(t, idx.EncodeTo(enc))
+	require.NoError(t, enc.Flush())
+
+	dec := encoding.ReaderAtDecoder(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	sections, err := dec.Sections(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sections, 1)
+	require.Equal(t, filemd.SECTION_TYPE_TOKENS, sections[0].Type)
+
+	return dec.TokensDecoder(), sections[0]
+}
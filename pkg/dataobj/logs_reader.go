@@ -17,6 +17,7 @@ import (
 
 	"github.com/grafana/loki/pkg/push"
 
+	"github.com/grafana/loki/v3/pkg/columnar"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
@@ -24,6 +25,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/logs"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/tokens"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/slicegrow"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/symbolizer"
 )
@@ -36,6 +38,18 @@ type Record struct {
 	Line      []byte        // Line of the log record.
 }
 
+// Field metadata of the record batches returned by [LogsReader.ReadBatch].
+// Each field of a batch holds the [LogsColumnTypeKey] metadata key, whose
+// value identifies the kind of column the field was read from.
+const (
+	LogsColumnTypeKey = "dataobj.logs.column_type"
+
+	LogsColumnTypeStreamID  = "stream_id" // Int64 column of stream IDs.
+	LogsColumnTypeTimestamp = "timestamp" // Timestamp column of log record timestamps.
+	LogsColumnTypeMetadata  = "metadata"  // String column of a single metadata key.
+	LogsColumnTypeMessage   = "message"   // String column of log lines.
+)
+
 // LogsReader reads the set of logs from an [Object].
 type LogsReader struct {
 	obj   *Object
@@ -45,6 +59,9 @@ type LogsReader struct {
 	matchIDs  map[int64]struct{}
 	predicate LogsPredicate
 
+	projected    bool                // Whether metadata columns are projected.
+	metadataKeys map[string]struct{} // Projected metadata keys.
+
 	buf    []dataset.Row
 	record logs.Record
 
@@ -53,6 +70,8 @@ type LogsReader struct {
 	columnDesc []*logsmd.ColumnDesc
 
 	symbols *symbolizer.Symbolizer
+
+	schema *columnar.Schema // Schema of batches returned by ReadBatch.
 }
 
 // NewLogsReader creates a new LogsReader that reads from the logs section of
@@ -98,6 +117,33 @@ func (r *LogsReader) SetPredicate(p LogsPredicate) error {
 	return nil
 }
 
+// ProjectMetadata restricts the metadata columns read by the reader to the
+// given keys. Metadata columns of other keys are never decoded and are
+// omitted from the results of [LogsReader.Read] and [LogsReader.ReadBatch],
+// unless they are referenced by the predicate of the reader. Stream ID,
+// timestamp, and log line columns are always read.
+//
+// ProjectMetadata may be called multiple times to project additional keys.
+// Calling ProjectMetadata without keys excludes all metadata columns that
+// are not referenced by the predicate.
+//
+// ProjectMetadata may only be called before reading begins or after a call
+// to [LogsReader.Reset].
+func (r *LogsReader) ProjectMetadata(keys ...string) error {
+	if r.ready {
+		return fmt.Errorf("cannot change projected metadata after reading has started")
+	}
+
+	if r.metadataKeys == nil {
+		r.metadataKeys = make(map[string]struct{})
+	}
+	for _, key := range keys {
+		r.metadataKeys[key] = struct{}{}
+	}
+	r.projected = true
+	return nil
+}
+
 // Read reads up to the next len(s) records from the reader and stores them
 // into s. It returns the number of records read and any error encountered. At
 // the end of the logs section, Read returns 0, io.EOF.
@@ -146,6 +192,151 @@ func (r *LogsReader) Read(ctx context.Context, s []Record) (int, error) {
 	return n, nil
 }
 
+// ReadBatch reads up to the next n records from the reader and returns them
+// as a [columnar.RecordBatch]. The batch holds one column for the stream IDs,
+// one for the timestamps, and one for the log lines, plus one column per
+// metadata key of the section. Metadata columns are NULL for records without
+// that key. At the end of the logs section, ReadBatch returns nil, io.EOF.
+//
+// Rows are read from the section the same as by [LogsReader.Read], but
+// their values are appended to the columns of the batch instead of being
+// decoded into records, so no records or per-row metadata slices are
+// allocated. The returned batch does not share memory with the reader.
+func (r *LogsReader) ReadBatch(ctx context.Context, n int) (*columnar.RecordBatch, error) {
+	if r.obj == nil {
+		return nil, io.EOF
+	} else if r.idx < 0 {
+		return nil, fmt.Errorf("invalid section index %d", r.idx)
+	} else if n <= 0 {
+		return nil, fmt.Errorf("invalid batch size %d", n)
+	}
+
+	if !r.ready {
+		err := r.initReader(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.buf = slicegrow.GrowToCap(r.buf, n)
+	r.buf = r.buf[:n]
+
+	count, err := r.reader.Read(ctx, r.buf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading rows: %w", err)
+	} else if count == 0 && errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+
+	if r.schema == nil {
+		r.schema = logsBatchSchema(r.columnDesc)
+	}
+
+	builders := make([]columnar.Builder, len(r.columnDesc))
+	for i := range r.columnDesc {
+		builders[i] = columnar.NewBuilder(r.schema.Field(i).Type)
+	}
+
+	for _, row := range r.buf[:count] {
+		for i, desc := range r.columnDesc {
+			if err := appendLogsValue(builders[i], desc, row.Values[i]); err != nil {
+				return nil, fmt.Errorf("decoding row %d: %w", row.Index, err)
+			}
+		}
+	}
+
+	columns := make([]columnar.Array, len(builders))
+	for i, b := range builders {
+		columns[i] = b.Build()
+	}
+	return columnar.NewRecordBatch(r.schema, count, columns)
+}
+
+// logsBatchSchema returns the schema of record batches for a logs section
+// with the given columns. Fields are in the same order as the columns.
+func logsBatchSchema(columns []*logsmd.ColumnDesc) *columnar.Schema {
+	fields := make([]columnar.Field, len(columns))
+	for i, desc := range columns {
+		field := columnar.Field{Type: columnar.DataTypeNull}
+
+		switch desc.Type {
+		case logsmd.COLUMN_TYPE_STREAM_ID:
+			field.Name, field.Type = LogsColumnTypeStreamID, columnar.DataTypeInt64
+			field.Metadata = map[string]string{LogsColumnTypeKey: LogsColumnTypeStreamID}
+		case logsmd.COLUMN_TYPE_TIMESTAMP:
+			field.Name, field.Type = LogsColumnTypeTimestamp, columnar.DataTypeTimestamp
+			field.Metadata = map[string]string{LogsColumnTypeKey: LogsColumnTypeTimestamp}
+		case logsmd.COLUMN_TYPE_METADATA:
+			field.Name, field.Type = desc.Info.Name, columnar.DataTypeString
+			field.Metadata = map[string]string{LogsColumnTypeKey: LogsColumnTypeMetadata}
+		case logsmd.COLUMN_TYPE_MESSAGE:
+			field.Name, field.Type = LogsColumnTypeMessage, columnar.DataTypeString
+			field.Metadata = map[string]string{LogsColumnTypeKey: LogsColumnTypeMessage}
+		default:
+			// Columns of unknown types are exposed as NULL columns so that the
+			// fields of the schema keep matching the columns of the section.
+			field.Name = desc.Type.String()
+		}
+
+		fields[i] = field
+	}
+	return columnar.NewSchema(fields...)
+}
+
+// appendLogsValue appends the value of a logs column to the builder.
+// Missing metadata values are appended as NULL, while missing stream IDs,
+// timestamps, and log lines are appended as zero values.
+func appendLogsValue(b columnar.Builder, desc *logsmd.ColumnDesc, value dataset.Value) error {
+	switch desc.Type {
+	case logsmd.COLUMN_TYPE_STREAM_ID, logsmd.COLUMN_TYPE_TIMESTAMP:
+		var v int64
+		if !value.IsNil() {
+			if ty := value.Type(); ty != datasetmd.VALUE_TYPE_INT64 {
+				return fmt.Errorf("invalid type %s for %s", ty, desc.Type)
+			}
+			v = value.Int64()
+		}
+		switch b := b.(type) {
+		case *columnar.Int64Builder:
+			b.Append(v)
+		case *columnar.TimestampBuilder:
+			b.Append(v)
+		}
+
+	case logsmd.COLUMN_TYPE_METADATA:
+		if value.IsNil() || value.IsZero() {
+			b.AppendNull()
+			return nil
+		}
+		if value.Type() == datasetmd.VALUE_TYPE_BYTE_ARRAY {
+			b.(*columnar.StringBuilder).AppendBytes(value.ByteArray())
+			return nil
+		}
+
+		// Typed metadata values are returned as the strings they were received
+		// as.
+		formatted, err := logs.AppendMetadataValue(nil, value)
+		if err != nil {
+			return fmt.Errorf("invalid type %s for %s", value.Type(), desc.Type)
+		}
+		b.(*columnar.StringBuilder).AppendBytes(formatted)
+
+	case logsmd.COLUMN_TYPE_MESSAGE:
+		if value.IsNil() || value.IsZero() {
+			b.(*columnar.StringBuilder).Append("")
+			return nil
+		}
+		if ty := value.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+			return fmt.Errorf("invalid type %s for %s", ty, desc.Type)
+		}
+		b.(*columnar.StringBuilder).AppendBytes(value.ByteArray())
+
+	default:
+		b.AppendNull()
+	}
+	return nil
+}
+
 func unsafeSlice(data string, capacity int) []byte {
 	if capacity <= 0 {
 		capacity = len(data)
@@ -159,7 +350,7 @@ func unsafeString(data []byte) string {
 
 func (r *LogsReader) initReader(ctx context.Context) error {
 	dec := r.obj.dec.LogsDecoder()
-	sec, err := r.findSection(ctx)
+	sec, tokensSec, err := r.findSection(ctx)
 	if err != nil {
 		return fmt.Errorf("finding section: %w", err)
 	}
@@ -179,12 +370,21 @@ func (r *LogsReader) initReader(ctx context.Context) error {
 	// that as a separate predicate and AND them together.
 	predicate := streamIDPredicate(maps.Keys(r.matchIDs), columns, columnDescs)
 	if r.predicate != nil {
+		containsRanges, err := r.lookupTokens(ctx, tokensSec)
+		if err != nil {
+			return fmt.Errorf("looking up tokens: %w", err)
+		}
+
 		predicate = dataset.AndPredicate{
 			Left:  predicate,
-			Right: translateLogsPredicate(r.predicate, columns, columnDescs),
+			Right: translateLogsPredicate(r.predicate, columns, columnDescs, containsRanges),
 		}
 	}
 
+	if r.projected {
+		columns, columnDescs = r.projectColumns(columns, columnDescs)
+	}
+
 	readerOpts := dataset.ReaderOptions{
 		Dataset:   dset,
 		Columns:   columns,
@@ -211,24 +411,132 @@ func (r *LogsReader) initReader(ctx context.Context) error {
 	return nil
 }
 
-func (r *LogsReader) findSection(ctx context.Context) (*filemd.SectionInfo, error) {
+// projectColumns returns the subset of columns that are read when metadata
+// columns are projected. Predicates are translated before projecting, so the
+// metadata columns they reference are kept as well.
+func (r *LogsReader) projectColumns(columns []dataset.Column, columnDescs []*logsmd.ColumnDesc) ([]dataset.Column, []*logsmd.ColumnDesc) {
+	keep := maps.Clone(r.metadataKeys)
+	if keep == nil {
+		keep = make(map[string]struct{})
+	}
+	predicateMetadataKeys(r.predicate, keep)
+
+	var (
+		projectedColumns = make([]dataset.Column, 0, len(columns))
+		projectedDescs   = make([]*logsmd.ColumnDesc, 0, len(columnDescs))
+	)
+	for i, desc := range columnDescs {
+		if desc.Type == logsmd.COLUMN_TYPE_METADATA {
+			if _, ok := keep[desc.Info.Name]; !ok {
+				continue
+			}
+		}
+		projectedColumns = append(projectedColumns, columns[i])
+		projectedDescs = append(projectedDescs, desc)
+	}
+	return projectedColumns, projectedDescs
+}
+
+// predicateMetadataKeys adds the metadata keys referenced by p to keys.
+func predicateMetadataKeys(p LogsPredicate, keys map[string]struct{}) {
+	switch p := p.(type) {
+	case AndPredicate[LogsPredicate]:
+		predicateMetadataKeys(p.Left, keys)
+		predicateMetadataKeys(p.Right, keys)
+	case OrPredicate[LogsPredicate]:
+		predicateMetadataKeys(p.Left, keys)
+		predicateMetadataKeys(p.Right, keys)
+	case NotPredicate[LogsPredicate]:
+		predicateMetadataKeys(p.Inner, keys)
+	case MetadataMatcherPredicate:
+		keys[p.Key] = struct{}{}
+	case MetadataFilterPredicate:
+		keys[p.Key] = struct{}{}
+	case MetadataRangePredicate[float64]:
+		keys[p.Key] = struct{}{}
+	case MetadataRangePredicate[time.Duration]:
+		keys[p.Key] = struct{}{}
+	}
+}
+
+// lookupTokens returns the ranges of rows of the logs section which may
+// contain each substring required by a [LogMessageFilterPredicate] in the
+// predicate of r. Substrings which can't be looked up are omitted from the
+// result.
+//
+// lookupTokens returns nil if tokensSec is nil, as is the case for objects
+// built before token indexes were introduced.
+func (r *LogsReader) lookupTokens(ctx context.Context, tokensSec *filemd.SectionInfo) (map[string][]dataset.RowRange, error) {
+	if tokensSec == nil {
+		return nil, nil
+	}
+
+	contains := make(map[string]struct{})
+	predicateContains(r.predicate, contains)
+
+	var (
+		dec    = r.obj.dec.TokensDecoder()
+		ranges = make(map[string][]dataset.RowRange, len(contains))
+	)
+	for substr := range contains {
+		if len(substr) < tokens.TokenSize {
+			continue
+		}
+
+		substrRanges, err := tokens.Lookup(ctx, dec, tokensSec, tokens.Tokens([]byte(substr)))
+		if err != nil {
+			return nil, err
+		}
+		ranges[substr] = substrRanges
+	}
+	return ranges, nil
+}
+
+// predicateContains adds the substrings required by the
+// [LogMessageFilterPredicate]s of p to contains.
+func predicateContains(p LogsPredicate, contains map[string]struct{}) {
+	switch p := p.(type) {
+	case AndPredicate[LogsPredicate]:
+		predicateContains(p.Left, contains)
+		predicateContains(p.Right, contains)
+	case OrPredicate[LogsPredicate]:
+		predicateContains(p.Left, contains)
+		predicateContains(p.Right, contains)
+	case NotPredicate[LogsPredicate]:
+		predicateContains(p.Inner, contains)
+	case LogMessageFilterPredicate:
+		for _, substr := range p.Contains {
+			contains[substr] = struct{}{}
+		}
+	}
+}
+
+// findSection returns the logs section read by r, along with the tokens
+// section indexing it. The tokens section is nil if the logs section isn't
+// indexed.
+func (r *LogsReader) findSection(ctx context.Context) (logsSec, tokensSec *filemd.SectionInfo, err error) {
 	si, err := r.obj.dec.Sections(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("reading sections: %w", err)
+		return nil, nil, fmt.Errorf("reading sections: %w", err)
 	}
 
 	var n int
 
-	for _, s := range si {
+	for i, s := range si {
 		if s.Type == filemd.SECTION_TYPE_LOGS {
 			if n == r.idx {
-				return s, nil
+				// Tokens sections are written right after the logs section they
+				// index.
+				if i+1 < len(si) && si[i+1].Type == filemd.SECTION_TYPE_TOKENS {
+					tokensSec = si[i+1]
+				}
+				return s, tokensSec, nil
 			}
 			n++
 		}
 	}
 
-	return nil, fmt.Errorf("section index %d not found", r.idx)
+	return nil, nil, fmt.Errorf("section index %d not found", r.idx)
 }
 
 func convertMetadata(md push.LabelsAdapter) []logs.RecordMetadata {
@@ -248,7 +556,8 @@ func convertMetadata(md push.LabelsAdapter) []logs.RecordMetadata {
 // Reset resets the LogsReader with a new object and section index to read
 // from. Reset allows reusing a LogsReader without allocating a new one.
 //
-// Any set predicate is cleared when Reset is called.
+// Any set predicate and projected metadata keys are cleared when Reset is
+// called.
 //
 // Reset may be called with a nil object and a negative section index to clear
 // the LogsReader without needing a new object.
@@ -259,9 +568,12 @@ func (r *LogsReader) Reset(obj *Object, sectionIndex int) {
 
 	clear(r.matchIDs)
 	r.predicate = nil
+	clear(r.metadataKeys)
+	r.projected = false
 
 	r.columns = nil
 	r.columnDesc = nil
+	r.schema = nil
 
 	if r.symbols != nil {
 		r.symbols.Reset()
@@ -303,7 +615,11 @@ func streamIDPredicate(ids iter.Seq[int64], columns []dataset.Column, columnDesc
 	}
 }
 
-func translateLogsPredicate(p LogsPredicate, columns []dataset.Column, columnDesc []*logsmd.ColumnDesc) dataset.Predicate {
+// translateLogsPredicate translates p into a [dataset.Predicate]. The
+// containsRanges argument holds the ranges of rows which may contain the
+// substrings of [LogMessageFilterPredicate]s, as returned by
+// [LogsReader.lookupTokens].
+func translateLogsPredicate(p LogsPredicate, columns []dataset.Column, columnDesc []*logsmd.ColumnDesc, containsRanges map[string][]dataset.RowRange) dataset.Predicate {
 	if p == nil {
 		return nil
 	}
@@ -311,19 +627,19 @@ func translateLogsPredicate(p LogsPredicate, columns []dataset.Column, columnDes
 	switch p := p.(type) {
 	case AndPredicate[LogsPredicate]:
 		return dataset.AndPredicate{
-			Left:  translateLogsPredicate(p.Left, columns, columnDesc),
-			Right: translateLogsPredicate(p.Right, columns, columnDesc),
+			Left:  translateLogsPredicate(p.Left, columns, columnDesc, containsRanges),
+			Right: translateLogsPredicate(p.Right, columns, columnDesc, containsRanges),
 		}
 
 	case OrPredicate[LogsPredicate]:
 		return dataset.OrPredicate{
-			Left:  translateLogsPredicate(p.Left, columns, columnDesc),
-			Right: translateLogsPredicate(p.Right, columns, columnDesc),
+			Left:  translateLogsPredicate(p.Left, columns, columnDesc, containsRanges),
+			Right: translateLogsPredicate(p.Right, columns, columnDesc, containsRanges),
 		}
 
 	case NotPredicate[LogsPredicate]:
 		return dataset.NotPredicate{
-			Inner: translateLogsPredicate(p.Inner, columns, columnDesc),
+			Inner: translateLogsPredicate(p.Inner, columns, columnDesc, containsRanges),
 		}
 
 	case TimeRangePredicate[LogsPredicate]:
@@ -343,7 +659,7 @@ func translateLogsPredicate(p LogsPredicate, columns []dataset.Column, columnDes
 			return dataset.FalsePredicate{}
 		}
 
-		return dataset.FuncPredicate{
+		keep := dataset.FuncPredicate{
 			Column: messageColumn,
 			Keep: func(_ dataset.Column, value dataset.Value) bool {
 				if value.Type() == datasetmd.VALUE_TYPE_BYTE_ARRAY {
@@ -355,6 +671,30 @@ func translateLogsPredicate(p LogsPredicate, columns []dataset.Column, columnDes
 			},
 		}
 
+		// Rows outside of the ranges of the token index can't contain the
+		// required substrings, so they're skipped before reading log messages.
+		var (
+			ranges  []dataset.RowRange
+			indexed bool
+		)
+		for _, substr := range p.Contains {
+			substrRanges, ok := containsRanges[substr]
+			if !ok {
+				continue
+			} else if !indexed {
+				ranges, indexed = substrRanges, true
+				continue
+			}
+			ranges = dataset.IntersectRowRanges(ranges, substrRanges)
+		}
+		if !indexed {
+			return keep
+		}
+		return dataset.AndPredicate{
+			Left:  dataset.RowRangesPredicate{Ranges: ranges},
+			Right: keep,
+		}
+
 	case MetadataMatcherPredicate:
 		metadataColumn := findColumnFromDesc(columns, columnDesc, func(desc *logsmd.ColumnDesc) bool {
 			return desc.Type == logsmd.COLUMN_TYPE_METADATA && desc.Info.Name == p.Key
@@ -362,9 +702,21 @@ func translateLogsPredicate(p LogsPredicate, columns []dataset.Column, columnDes
 		if metadataColumn == nil {
 			return dataset.FalsePredicate{}
 		}
+
+		value := dataset.ByteArrayValue(unsafeSlice(p.Value, 0))
+		if ty := metadataColumn.ColumnInfo().Type; ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+			// Typed columns only hold values which format back to the string
+			// they were parsed from, so values which don't parse losslessly
+			// can't be equal to any value of the column.
+			typed, ok := logs.ParseMetadataValue(ty, []byte(p.Value))
+			if !ok || typed.IsNil() {
+				return dataset.FalsePredicate{}
+			}
+			value = typed
+		}
 		return dataset.EqualPredicate{
 			Column: metadataColumn,
-			Value:  dataset.ByteArrayValue(unsafeSlice(p.Value, 0)),
+			Value:  value,
 		}
 
 	case MetadataFilterPredicate:
@@ -377,10 +729,27 @@ func translateLogsPredicate(p LogsPredicate, columns []dataset.Column, columnDes
 		return dataset.FuncPredicate{
 			Column: metadataColumn,
 			Keep: func(_ dataset.Column, value dataset.Value) bool {
-				return p.Keep(p.Key, valueToString(value))
+				return p.Keep(p.Key, metadataValueString(value))
 			},
 		}
 
+	case MetadataRangePredicate[float64]:
+		metadataColumn := findMetadataColumn(columns, columnDesc, p.Key)
+		if metadataColumn == nil {
+			return dataset.FalsePredicate{}
+		}
+		return convertMetadataRangePredicate(p, metadataColumn, datasetmd.VALUE_TYPE_FLOAT64, dataset.Float64Value, func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		})
+
+	case MetadataRangePredicate[time.Duration]:
+		metadataColumn := findMetadataColumn(columns, columnDesc, p.Key)
+		if metadataColumn == nil {
+			return dataset.FalsePredicate{}
+		}
+		toValue := func(d time.Duration) dataset.Value { return dataset.Int64Value(int64(d)) }
+		return convertMetadataRangePredicate(p, metadataColumn, datasetmd.VALUE_TYPE_INT64, toValue, time.ParseDuration)
+
 	default:
 		panic(fmt.Sprintf("unsupported predicate type %T", p))
 	}
@@ -421,6 +790,59 @@ func convertLogsTimePredicate(p TimeRangePredicate[LogsPredicate], column datase
 	}
 }
 
+// convertMetadataRangePredicate converts p into a [dataset.Predicate] for the
+// metadata column of its key. Columns storing values of valueType are
+// compared by range, which permits page filtering. Values of other columns
+// are converted to strings and parsed with parse.
+func convertMetadataRangePredicate[T float64 | time.Duration](
+	p MetadataRangePredicate[T],
+	column dataset.Column,
+	valueType datasetmd.ValueType,
+	toValue func(T) dataset.Value,
+	parse func(string) (T, error),
+) dataset.Predicate {
+	if column.ColumnInfo().Type != valueType {
+		return dataset.FuncPredicate{
+			Column: column,
+			Keep: func(_ dataset.Column, value dataset.Value) bool {
+				if value.IsNil() || value.IsZero() {
+					return false
+				}
+				v, err := parse(metadataValueString(value))
+				if err != nil {
+					return true
+				}
+				return (v > p.Start || (p.IncludeStart && v == p.Start)) &&
+					(v < p.End || (p.IncludeEnd && v == p.End))
+			},
+		}
+	}
+
+	bound := func(p dataset.Predicate, inclusive bool, value dataset.Value) dataset.Predicate {
+		if !inclusive {
+			return p
+		}
+		return dataset.OrPredicate{
+			Left:  p,
+			Right: dataset.EqualPredicate{Column: column, Value: value},
+		}
+	}
+
+	start, end := toValue(p.Start), toValue(p.End)
+	return dataset.AndPredicate{
+		Left:  bound(dataset.GreaterThanPredicate{Column: column, Value: start}, p.IncludeStart, start),
+		Right: bound(dataset.LessThanPredicate{Column: column, Value: end}, p.IncludeEnd, end),
+	}
+}
+
+// findMetadataColumn returns the metadata column of key, or nil if there is
+// none.
+func findMetadataColumn(columns []dataset.Column, columnDesc []*logsmd.ColumnDesc, key string) dataset.Column {
+	return findColumnFromDesc(columns, columnDesc, func(desc *logsmd.ColumnDesc) bool {
+		return desc.Type == logsmd.COLUMN_TYPE_METADATA && desc.Info.Name == key
+	})
+}
+
 func findColumnFromDesc[Desc any](columns []dataset.Column, descs []Desc, check func(Desc) bool) dataset.Column {
 	for i, desc := range descs {
 		if check(desc) {
@@ -430,6 +852,20 @@ func findColumnFromDesc[Desc any](columns []dataset.Column, descs []Desc, check
 	return nil
 }
 
+// metadataValueString returns the string representation of a value of a
+// metadata column. NULL values are returned as empty strings.
+func metadataValueString(value dataset.Value) string {
+	if value.IsNil() || value.Type() == datasetmd.VALUE_TYPE_BYTE_ARRAY {
+		return valueToString(value)
+	}
+
+	formatted, err := logs.AppendMetadataValue(nil, value)
+	if err != nil {
+		return valueToString(value)
+	}
+	return unsafeString(formatted)
+}
+
 func valueToString(value dataset.Value) string {
 	switch value.Type() {
 	case datasetmd.VALUE_TYPE_UNSPECIFIED:
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"math"
 	"slices"
 	"strconv"
 	"strings"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/grafana/loki/pkg/push"
 
+	"github.com/grafana/loki/v3/pkg/columnar"
 	"github.com/grafana/loki/v3/pkg/dataobj"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/encoding"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/logs"
@@ -153,6 +155,208 @@ func TestLogsReader_AddMetadataFilter(t *testing.T) {
 	require.Equal(t, expect, actual)
 }
 
+func TestLogsReader_MetadataRangePredicate(t *testing.T) {
+	// Build with many pages but one section.
+	obj := buildLogsObject(t, logs.Options{
+		PageSizeHint:     1,
+		BufferSize:       1,
+		SectionSize:      1024,
+		StripeMergeLimit: 2,
+	})
+
+	t.Run("typed column", func(t *testing.T) {
+		r := dataobj.NewLogsReader(obj, 0)
+		require.NoError(t, r.SetPredicate(dataobj.MetadataRangePredicate[float64]{
+			Key:          "user",
+			Start:        12,
+			End:          math.Inf(1),
+			IncludeStart: false,
+			IncludeEnd:   true,
+		}))
+
+		actual, err := readAllRecords(context.Background(), r)
+		require.NoError(t, err)
+		require.Equal(t, []dataobj.Record{
+			{3, unixTime(25), labels.FromStrings("user", "14"), []byte("hello one more time")},
+		}, actual)
+	})
+
+	t.Run("mismatched column", func(t *testing.T) {
+		// trace_id doesn't hold durations, so its values can't be parsed and
+		// pass the predicate. Records without trace_id are dropped.
+		r := dataobj.NewLogsReader(obj, 0)
+		require.NoError(t, r.SetPredicate(dataobj.MetadataRangePredicate[time.Duration]{
+			Key:          "trace_id",
+			Start:        time.Second,
+			End:          time.Minute,
+			IncludeStart: true,
+			IncludeEnd:   true,
+		}))
+
+		actual, err := readAllRecords(context.Background(), r)
+		require.NoError(t, err)
+		require.Equal(t, []dataobj.Record{
+			{1, unixTime(15), labels.FromStrings("trace_id", "123"), []byte("world")},
+			{3, unixTime(30), labels.FromStrings("trace_id", "123"), []byte("world one more time")},
+		}, actual)
+	})
+}
+
+func TestLogsReader_LogMessageFilter(t *testing.T) {
+	expect := []dataobj.Record{
+		{2, unixTime(5), labels.FromStrings(), []byte("hello again")},
+		{2, unixTime(20), labels.FromStrings("user", "12"), []byte("world again")},
+	}
+
+	// Build with many pages but one section.
+	obj := buildLogsObject(t, logs.Options{
+		PageSizeHint:     1,
+		BufferSize:       1,
+		SectionSize:      1024,
+		StripeMergeLimit: 2,
+	})
+
+	// Only the lines of stream 2 contain "again", so the token index permits
+	// skipping the log messages of all other streams.
+	var checked []string
+
+	r := dataobj.NewLogsReader(obj, 0)
+	err := r.SetPredicate(dataobj.LogMessageFilterPredicate{
+		Keep: func(line []byte) bool {
+			checked = append(checked, string(line))
+			return bytes.Contains(line, []byte("again"))
+		},
+		Contains: []string{"again"},
+	})
+	require.NoError(t, err)
+
+	actual, err := readAllRecords(context.Background(), r)
+	require.NoError(t, err)
+	require.Equal(t, expect, actual)
+	require.Equal(t, []string{"hello again", "world again"}, checked)
+}
+
+func TestLogsReader_ReadBatch(t *testing.T) {
+	type row struct {
+		StreamID  int64
+		Timestamp time.Time
+		Metadata  map[string]string
+		Line      string
+	}
+	expect := []row{
+		{1, unixTime(10), map[string]string{}, "hello"},
+		{1, unixTime(15), map[string]string{"trace_id": "123"}, "world"},
+		{3, unixTime(25), map[string]string{"user": "14"}, "hello one more time"},
+		{3, unixTime(30), map[string]string{"trace_id": "123"}, "world one more time"},
+	}
+
+	// Build with many pages but one section.
+	obj := buildLogsObject(t, logs.Options{
+		PageSizeHint:     1,
+		BufferSize:       1,
+		SectionSize:      1024,
+		StripeMergeLimit: 2,
+	})
+
+	r := dataobj.NewLogsReader(obj, 0)
+	require.NoError(t, r.MatchStreams(slices.Values([]int64{1, 3})))
+
+	var actual []row
+	for {
+		batch, err := r.ReadBatch(context.Background(), 3)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.LessOrEqual(t, batch.NumRows(), 3)
+
+		for i := range batch.NumRows() {
+			res := row{Metadata: map[string]string{}}
+			for c := range batch.NumCols() {
+				field, col := batch.Schema().Field(c), batch.Column(c)
+				switch field.Metadata[dataobj.LogsColumnTypeKey] {
+				case dataobj.LogsColumnTypeStreamID:
+					res.StreamID = col.(*columnar.Int64Array).Value(i)
+				case dataobj.LogsColumnTypeTimestamp:
+					res.Timestamp = time.Unix(0, col.(*columnar.TimestampArray).Value(i))
+				case dataobj.LogsColumnTypeMetadata:
+					if !col.IsNull(i) {
+						res.Metadata[field.Name] = col.(*columnar.StringArray).Value(i)
+					}
+				case dataobj.LogsColumnTypeMessage:
+					res.Line = col.(*columnar.StringArray).Value(i)
+				}
+			}
+			actual = append(actual, res)
+		}
+	}
+	require.Equal(t, expect, actual)
+}
+
+func TestLogsReader_ProjectMetadata(t *testing.T) {
+	// Build with many pages but one section.
+	obj := buildLogsObject(t, logs.Options{
+		PageSizeHint:     1,
+		BufferSize:       1,
+		SectionSize:      1024,
+		StripeMergeLimit: 2,
+	})
+
+	t.Run("projected keys", func(t *testing.T) {
+		expect := []dataobj.Record{
+			{1, unixTime(10), labels.FromStrings(), []byte("hello")},
+			{1, unixTime(15), labels.FromStrings(), []byte("world")},
+			{2, unixTime(5), labels.FromStrings(), []byte("hello again")},
+			{2, unixTime(20), labels.FromStrings("user", "12"), []byte("world again")},
+			{3, unixTime(25), labels.FromStrings("user", "14"), []byte("hello one more time")},
+			{3, unixTime(30), labels.FromStrings(), []byte("world one more time")},
+		}
+
+		r := dataobj.NewLogsReader(obj, 0)
+		require.NoError(t, r.ProjectMetadata("user"))
+
+		actual, err := readAllRecords(context.Background(), r)
+		require.NoError(t, err)
+		require.Equal(t, expect, actual)
+	})
+
+	t.Run("predicate keys", func(t *testing.T) {
+		expect := []dataobj.Record{
+			{1, unixTime(15), labels.FromStrings("trace_id", "123"), []byte("world")},
+			{3, unixTime(30), labels.FromStrings("trace_id", "123"), []byte("world one more time")},
+		}
+
+		r := dataobj.NewLogsReader(obj, 0)
+		require.NoError(t, r.ProjectMetadata())
+		require.NoError(t, r.SetPredicate(dataobj.MetadataMatcherPredicate{"trace_id", "123"}))
+
+		actual, err := readAllRecords(context.Background(), r)
+		require.NoError(t, err)
+		require.Equal(t, expect, actual)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		r := dataobj.NewLogsReader(obj, 0)
+		require.NoError(t, r.ProjectMetadata())
+		_, err := readAllRecords(context.Background(), r)
+		require.NoError(t, err)
+		require.Error(t, r.ProjectMetadata("user"))
+
+		r.Reset(obj, 0)
+		batch, err := r.ReadBatch(context.Background(), 10)
+		require.NoError(t, err)
+
+		var metadataColumns []string
+		for i := range batch.NumCols() {
+			field := batch.Schema().Field(i)
+			if field.Metadata[dataobj.LogsColumnTypeKey] == dataobj.LogsColumnTypeMetadata {
+				metadataColumns = append(metadataColumns, field.Name)
+			}
+		}
+		require.ElementsMatch(t, []string{"trace_id", "user"}, metadataColumns)
+	})
+}
+
 func buildLogsObject(t *testing.T, opts logs.Options) *dataobj.Object {
 	t.Helper()
 
@@ -11,8 +11,8 @@ type Metastore interface {
 	// Streams returns all streams corresponding to the given matchers between [start,end]
 	Streams(ctx context.Context, start, end time.Time, matchers ...*labels.Matcher) ([]*labels.Labels, error)
 
-	// DataObjects returns paths to all matching the given matchers between [start,end]
-	// TODO(chaudum); The comment is not correct, because the implementation does not filter by matchers, only by [start, end].
+	// DataObjects returns paths to all data objects between [start,end] that can contain streams matching the given matchers.
+	// The returned objects may not contain any matching stream.
 	DataObjects(ctx context.Context, start, end time.Time, matchers ...*labels.Matcher) ([]string, error)
 
 	// StreamsIDs returns object store paths and stream IDs for all matching objects for the given matchers between [start,end]
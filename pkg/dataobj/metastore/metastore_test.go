@@ -2,6 +2,8 @@ package metastore
 
 import (
 	"context"
+	"errors"
+	"io"
 	"strconv"
 	"testing"
 	"time"
@@ -309,6 +311,187 @@ func TestDataObjectsPaths(t *testing.T) {
 	})
 }
 
+func TestReplace(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	tenantID := "test-tenant"
+
+	m := NewUpdater(bucket, tenantID, log.NewNopLogger())
+
+	// Set limits for the test
+	m.backoff = backoff.New(context.TODO(), backoff.Config{
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 100 * time.Millisecond,
+		MaxRetries: 3,
+	})
+
+	now := time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)
+	window := now.Truncate(WindowSize)
+
+	for _, path := range []string{"path1", "path2", "path3"} {
+		err := m.Update(ctx, path, dataobj.FlushStats{
+			MinTimestamp: now.Add(-1 * time.Hour),
+			MaxTimestamp: now,
+		})
+		require.NoError(t, err)
+	}
+	err := m.Update(ctx, "previous", dataobj.FlushStats{
+		MinTimestamp: now.Add(-13 * time.Hour),
+		MaxTimestamp: now.Add(-12 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	windows, err := Windows(ctx, bucket, tenantID)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{window.Add(-WindowSize), window}, windows)
+
+	err = m.Replace(ctx, window, []string{"path1", "path2"}, map[string]dataobj.FlushStats{
+		"merged": {MinTimestamp: now.Add(-1 * time.Hour), MaxTimestamp: now},
+	})
+	require.NoError(t, err)
+
+	entries, err := Entries(ctx, bucket, tenantID, window)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Entry{
+		{Path: "path3", Start: now.Add(-1 * time.Hour), End: now},
+		{Path: "merged", Start: now.Add(-1 * time.Hour), End: now},
+	}, entries)
+
+	// Other windows are left untouched.
+	entries, err = Entries(ctx, bucket, tenantID, window.Add(-WindowSize))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{Path: "previous", Start: now.Add(-13 * time.Hour), End: now.Add(-12 * time.Hour)}}, entries)
+
+	tenants, err := Tenants(ctx, bucket)
+	require.NoError(t, err)
+	require.Equal(t, []string{tenantID}, tenants)
+
+	// Removing the last entries of a window keeps its metastore object, which
+	// can be appended to again.
+	err = m.Replace(ctx, window.Add(-WindowSize), []string{"previous"}, nil)
+	require.NoError(t, err)
+
+	windows, err = Windows(ctx, bucket, tenantID)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{window.Add(-WindowSize), window}, windows)
+
+	entries, err = Entries(ctx, bucket, tenantID, window.Add(-WindowSize))
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	ctx = user.InjectOrgID(ctx, tenantID)
+	paths, err := NewObjectMetastore(bucket).DataObjects(ctx, now.Add(-13*time.Hour), now.Add(-12*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	err = m.Update(ctx, "late", dataobj.FlushStats{
+		MinTimestamp: now.Add(-13 * time.Hour),
+		MaxTimestamp: now.Add(-12 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	entries, err = Entries(ctx, bucket, tenantID, window.Add(-WindowSize))
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{Path: "late", Start: now.Add(-13 * time.Hour), End: now.Add(-12 * time.Hour)}}, entries)
+}
+
+func TestUpdate_KafkaOffsets(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	tenantID := "test-tenant"
+
+	m := NewUpdater(bucket, tenantID, log.NewNopLogger())
+
+	// Set limits for the test
+	m.backoff = backoff.New(context.TODO(), backoff.Config{
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 100 * time.Millisecond,
+		MaxRetries: 3,
+	})
+
+	now := time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)
+	stats := dataobj.FlushStats{
+		MinTimestamp: now.Add(-1 * time.Hour),
+		MaxTimestamp: now,
+		KafkaOffsets: &dataobj.KafkaOffsets{Topic: "loki.test", Partition: 3, MinOffset: 10, MaxOffset: 20},
+	}
+
+	// Retrying an update doesn't list the data object twice.
+	for range 2 {
+		require.NoError(t, m.Update(ctx, "path", stats))
+	}
+
+	entries, err := Entries(ctx, bucket, tenantID, now)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{
+		Path:         "path",
+		Start:        now.Add(-1 * time.Hour),
+		End:          now,
+		KafkaOffsets: &dataobj.KafkaOffsets{Topic: "loki.test", Partition: 3, MinOffset: 10, MaxOffset: 20},
+	}}, entries)
+
+	// Replacing data objects records their Kafka records in the entries of
+	// the data objects replacing them.
+	require.NoError(t, m.Update(ctx, "next", dataobj.FlushStats{
+		MinTimestamp: now.Add(-1 * time.Hour),
+		MaxTimestamp: now,
+		KafkaOffsets: &dataobj.KafkaOffsets{Topic: "loki.test", Partition: 3, MinOffset: 21, MaxOffset: 30},
+	}))
+	require.NoError(t, m.Replace(ctx, now, []string{"path", "next"}, map[string]dataobj.FlushStats{
+		"merged": {MinTimestamp: now.Add(-1 * time.Hour), MaxTimestamp: now},
+	}))
+
+	entries, err = Entries(ctx, bucket, tenantID, now)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{{
+		Path:                 "merged",
+		Start:                now.Add(-1 * time.Hour),
+		End:                  now,
+		ReplacedKafkaOffsets: []dataobj.KafkaOffsets{{Topic: "loki.test", Partition: 3, MinOffset: 10, MaxOffset: 30}},
+	}}, entries)
+	require.True(t, entries[0].HoldsKafkaRecords(*stats.KafkaOffsets))
+	require.False(t, entries[0].HoldsKafkaRecords(dataobj.KafkaOffsets{Topic: "loki.test", Partition: 3, MinOffset: 25, MaxOffset: 35}))
+}
+
+// failingBucket fails to replace the object at path.
+type failingBucket struct {
+	objstore.Bucket
+	path string
+}
+
+func (b *failingBucket) GetAndReplace(ctx context.Context, name string, f func(io.Reader) (io.Reader, error)) error {
+	if name == b.path {
+		return errors.New("injected failure")
+	}
+	return b.Bucket.GetAndReplace(ctx, name, f)
+}
+
+func TestUpdate_FailedWindow(t *testing.T) {
+	ctx := context.Background()
+	tenantID := "test-tenant"
+
+	now := time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)
+	bucket := &failingBucket{
+		Bucket: objstore.NewInMemBucket(),
+		path:   metastorePath(tenantID, now.Add(-WindowSize).Truncate(WindowSize)),
+	}
+
+	m := NewUpdater(bucket, tenantID, log.NewNopLogger())
+	m.backoff = backoff.New(context.TODO(), backoff.Config{
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 100 * time.Millisecond,
+		MaxRetries: 3,
+	})
+
+	// The update fails if any window can't be updated, even if later windows
+	// could be.
+	err := m.Update(ctx, "path", dataobj.FlushStats{
+		MinTimestamp: now.Add(-WindowSize),
+		MaxTimestamp: now,
+	})
+	require.Error(t, err)
+}
+
 func TestObjectOverlapsRange(t *testing.T) {
 	testPath := "test/path"
 
@@ -10,6 +10,7 @@ import (
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,9 +22,9 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj"
 )
 
-const (
-	metastoreWindowSize = 12 * time.Hour
-)
+// WindowSize is the time range covered by each metastore object. Windows are
+// aligned to multiples of WindowSize since the Unix epoch.
+const WindowSize = 12 * time.Hour
 
 type ObjectMetastore struct {
 	bucket      objstore.Bucket
@@ -35,11 +36,11 @@ func metastorePath(tenantID string, window time.Time) string {
 }
 
 func iterStorePaths(tenantID string, start, end time.Time) iter.Seq[string] {
-	minMetastoreWindow := start.Truncate(metastoreWindowSize).UTC()
-	maxMetastoreWindow := end.Truncate(metastoreWindowSize).UTC()
+	minMetastoreWindow := start.Truncate(WindowSize).UTC()
+	maxMetastoreWindow := end.Truncate(WindowSize).UTC()
 
 	return func(yield func(t string) bool) {
-		for metastoreWindow := minMetastoreWindow; !metastoreWindow.After(maxMetastoreWindow); metastoreWindow = metastoreWindow.Add(metastoreWindowSize) {
+		for metastoreWindow := minMetastoreWindow; !metastoreWindow.After(maxMetastoreWindow); metastoreWindow = metastoreWindow.Add(WindowSize) {
 			if !yield(metastorePath(tenantID, metastoreWindow)) {
 				return
 			}
@@ -47,6 +48,136 @@ func iterStorePaths(tenantID string, start, end time.Time) iter.Seq[string] {
 	}
 }
 
+// An Entry is a data object listed in a metastore object.
+type Entry struct {
+	Path       string    // Path of the data object in the bucket.
+	Start, End time.Time // Time range of the logs in the data object.
+
+	// KafkaOffsets holds the Kafka records the data object was built from, or
+	// nil if the data object wasn't built by the dataobj consumer.
+	KafkaOffsets *dataobj.KafkaOffsets
+
+	// ReplacedKafkaOffsets holds the Kafka records of the data objects the
+	// data object replaced, such as the inputs of a compaction.
+	ReplacedKafkaOffsets []dataobj.KafkaOffsets
+}
+
+// HoldsKafkaRecords returns true if the records of offsets are stored in the
+// data object of e, either because it was built from them or because it
+// replaced data objects built from them.
+func (e Entry) HoldsKafkaRecords(offsets dataobj.KafkaOffsets) bool {
+	contains := func(o dataobj.KafkaOffsets) bool {
+		return o.Topic == offsets.Topic && o.Partition == offsets.Partition &&
+			o.MinOffset <= offsets.MinOffset && offsets.MaxOffset <= o.MaxOffset
+	}
+
+	if e.KafkaOffsets != nil && contains(*e.KafkaOffsets) {
+		return true
+	}
+	return slices.ContainsFunc(e.ReplacedKafkaOffsets, contains)
+}
+
+// Tenants returns the IDs of the tenants with data objects or metastore
+// objects in bucket.
+func Tenants(ctx context.Context, bucket objstore.Bucket) ([]string, error) {
+	var tenants []string
+	err := bucket.Iter(ctx, "", func(name string) error {
+		if tenantID, ok := strings.CutPrefix(strings.TrimSuffix(name, "/"), "tenant-"); ok {
+			tenants = append(tenants, tenantID)
+		}
+		return nil
+	})
+	return tenants, err
+}
+
+// Windows returns the start of the windows of the metastore objects of
+// tenantID in bucket, in ascending order.
+func Windows(ctx context.Context, bucket objstore.Bucket, tenantID string) ([]time.Time, error) {
+	var windows []time.Time
+	prefix := fmt.Sprintf("tenant-%s/metastore/", tenantID)
+	err := bucket.Iter(ctx, prefix, func(name string) error {
+		base, ok := strings.CutSuffix(strings.TrimPrefix(name, prefix), ".store")
+		if !ok {
+			return nil
+		}
+		window, err := time.Parse(time.RFC3339, base)
+		if err != nil {
+			return fmt.Errorf("parsing metastore window %s: %w", name, err)
+		}
+		windows = append(windows, window.UTC())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(windows, time.Time.Compare)
+	return windows, nil
+}
+
+// DeleteEmpty deletes the metastore object of the window starting at window
+// for tenantID if it has no entries, and returns whether it was deleted.
+//
+// Unlike the updates of metastore objects, the delete isn't conditional, and
+// entries added to the window concurrently are lost. DeleteEmpty must only be
+// called for windows to which no entries are added anymore, such as windows
+// past retention.
+func DeleteEmpty(ctx context.Context, bucket objstore.Bucket, tenantID string, window time.Time) (bool, error) {
+	path := metastorePath(tenantID, window.Truncate(WindowSize).UTC())
+	objectReader, err := bucket.Get(ctx, path)
+	if bucket.IsObjNotFoundErr(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	n, err := io.ReadFull(objectReader, make([]byte, 1))
+	objectReader.Close()
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("reading metastore object: %w", err)
+	} else if n > 0 {
+		return false, nil
+	}
+
+	if err := bucket.Delete(ctx, path); err != nil && !bucket.IsObjNotFoundErr(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// Entries returns the entries of the metastore object of the window starting
+// at window for tenantID. Entries returns an empty slice if the metastore
+// object doesn't exist or is empty.
+func Entries(ctx context.Context, bucket objstore.Bucket, tenantID string, window time.Time) ([]Entry, error) {
+	objectReader, err := bucket.Get(ctx, metastorePath(tenantID, window.Truncate(WindowSize).UTC()))
+	if bucket.IsObjNotFoundErr(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer objectReader.Close()
+
+	var buf bytes.Buffer
+	n, err := buf.ReadFrom(objectReader)
+	if err != nil {
+		return nil, fmt.Errorf("reading metastore object: %w", err)
+	} else if n == 0 {
+		// Metastore objects are emptied rather than deleted once all of their
+		// entries are removed, see [DeleteEmpty].
+		return nil, nil
+	}
+	object := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), n)
+
+	var entries []Entry
+	err = forEachStream(ctx, object, nil, func(stream dataobj.Stream) {
+		if entry, ok := parseEntry(stream.Labels); ok {
+			entries = append(entries, entry)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 func NewObjectMetastore(bucket objstore.Bucket) *ObjectMetastore {
 	return &ObjectMetastore{
 		bucket:      bucket,
@@ -66,7 +197,7 @@ func (m *ObjectMetastore) Streams(ctx context.Context, start, end time.Time, mat
 	}
 
 	// List objects from all stores concurrently
-	paths, err := m.listObjectsFromStores(ctx, storePaths, start, end)
+	paths, err := m.listObjectsFromStores(ctx, storePaths, start, end, matchers...)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +220,7 @@ func (m *ObjectMetastore) StreamIDs(ctx context.Context, start, end time.Time, m
 	}
 
 	// List objects from all stores concurrently
-	paths, err := m.listObjectsFromStores(ctx, storePaths, start, end)
+	paths, err := m.listObjectsFromStores(ctx, storePaths, start, end, matchers...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -110,7 +241,7 @@ func (m *ObjectMetastore) StreamIDs(ctx context.Context, start, end time.Time, m
 	return paths, streamIDs, err
 }
 
-func (m *ObjectMetastore) DataObjects(ctx context.Context, start, end time.Time, _ ...*labels.Matcher) ([]string, error) {
+func (m *ObjectMetastore) DataObjects(ctx context.Context, start, end time.Time, matchers ...*labels.Matcher) ([]string, error) {
 	tenantID, err := tenant.TenantID(ctx)
 	if err != nil {
 		return nil, err
@@ -123,7 +254,7 @@ func (m *ObjectMetastore) DataObjects(ctx context.Context, start, end time.Time,
 	}
 
 	// List objects from all stores concurrently
-	return m.listObjectsFromStores(ctx, storePaths, start, end)
+	return m.listObjectsFromStores(ctx, storePaths, start, end, matchers...)
 }
 
 func (m *ObjectMetastore) Labels(ctx context.Context, start, end time.Time, matchers ...*labels.Matcher) ([]string, error) {
@@ -233,14 +364,15 @@ func predicateFromMatchers(start, end time.Time, matchers ...*labels.Matcher) da
 }
 
 // listObjectsFromStores concurrently lists objects from multiple metastore files
-func (m *ObjectMetastore) listObjectsFromStores(ctx context.Context, storePaths []string, start, end time.Time) ([]string, error) {
+// that overlap with [start, end] and can contain streams matching the matchers.
+func (m *ObjectMetastore) listObjectsFromStores(ctx context.Context, storePaths []string, start, end time.Time, matchers ...*labels.Matcher) ([]string, error) {
 	objects := make([][]string, len(storePaths))
 	g, ctx := errgroup.WithContext(ctx)
 
 	for i, path := range storePaths {
 		g.Go(func() error {
 			var err error
-			objects[i], err = m.listObjects(ctx, path, start, end)
+			objects[i], err = m.listObjects(ctx, path, start, end, matchers...)
 			// If the metastore object is not found, it means it's outside of any existing window
 			// and we can safely ignore it.
 			if err != nil && !m.bucket.IsObjNotFoundErr(err) {
@@ -332,7 +464,7 @@ func addLabels(mtx *sync.Mutex, streams map[uint64][]*labels.Labels, newLabels *
 	streams[key] = append(streams[key], newLabels)
 }
 
-func (m *ObjectMetastore) listObjects(ctx context.Context, path string, start, end time.Time) ([]string, error) {
+func (m *ObjectMetastore) listObjects(ctx context.Context, path string, start, end time.Time, matchers ...*labels.Matcher) ([]string, error) {
 	var buf bytes.Buffer
 	objectReader, err := m.bucket.Get(ctx, path)
 	if err != nil {
@@ -341,13 +473,15 @@ func (m *ObjectMetastore) listObjects(ctx context.Context, path string, start, e
 	n, err := buf.ReadFrom(objectReader)
 	if err != nil {
 		return nil, fmt.Errorf("reading metastore object: %w", err)
+	} else if n == 0 {
+		return nil, nil
 	}
 	object := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), n)
 	var objectPaths []string
 
 	err = forEachStream(ctx, object, nil, func(stream dataobj.Stream) {
 		ok, objPath := objectOverlapsRange(stream.Labels, start, end)
-		if ok {
+		if ok && objectMatches(stream.Labels, matchers) {
 			objectPaths = append(objectPaths, objPath)
 		}
 	})
@@ -410,34 +544,69 @@ func dedupeAndSort(objects [][]string) []string {
 
 // objectOverlapsRange checks if an object's time range overlaps with the query range
 func objectOverlapsRange(lbs labels.Labels, start, end time.Time) (bool, string) {
-	var (
-		objStart, objEnd time.Time
-		objPath          string
-	)
+	entry, ok := parseEntry(lbs)
+	if !ok {
+		return false, ""
+	}
+	if entry.End.Before(start) || entry.Start.After(end) {
+		return false, ""
+	}
+	return true, entry.Path
+}
+
+// parseEntry parses the labels of a metastore stream into an [Entry]. It
+// returns false if the labels don't hold the time range of the data object.
+func parseEntry(lbs labels.Labels) (Entry, bool) {
+	var entry Entry
 	for _, lb := range lbs {
 		if lb.Name == labelNameStart {
 			tsNano, err := strconv.ParseInt(lb.Value, 10, 64)
 			if err != nil {
 				panic(err)
 			}
-			objStart = time.Unix(0, tsNano).UTC()
+			entry.Start = time.Unix(0, tsNano).UTC()
 		}
 		if lb.Name == labelNameEnd {
 			tsNano, err := strconv.ParseInt(lb.Value, 10, 64)
 			if err != nil {
 				panic(err)
 			}
-			objEnd = time.Unix(0, tsNano).UTC()
+			entry.End = time.Unix(0, tsNano).UTC()
 		}
 		if lb.Name == labelNamePath {
-			objPath = lb.Value
+			entry.Path = lb.Value
+		}
+		if lb.Name == labelNameKafkaOffsets {
+			if offsets, ok := parseKafkaOffsets(lb.Value); ok {
+				entry.KafkaOffsets = &offsets
+			}
+		}
+		if lb.Name == labelNameReplacedKafkaOffsets {
+			if offsets, ok := parseKafkaOffsetsList(lb.Value); ok {
+				entry.ReplacedKafkaOffsets = offsets
+			}
 		}
 	}
-	if objStart.IsZero() || objEnd.IsZero() {
-		return false, ""
+	if entry.Start.IsZero() || entry.End.IsZero() {
+		return Entry{}, false
 	}
-	if objEnd.Before(start) || objStart.After(end) {
-		return false, ""
+	return entry, true
+}
+
+// objectMatches checks if an object can contain streams matching all of the
+// matchers, based on its labels summary. Objects without a valid labels
+// summary can contain any stream.
+func objectMatches(lbs labels.Labels, matchers []*labels.Matcher) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+	value := lbs.Get(labelNameLabels)
+	if value == "" {
+		return true
+	}
+	summary, err := decodeLabelsSummary(value)
+	if err != nil {
+		return true
 	}
-	return true, objPath
+	return summary.Matches(matchers...)
 }
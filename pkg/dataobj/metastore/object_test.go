@@ -102,6 +102,35 @@ func TestStreamIDs(t *testing.T) {
 	})
 }
 
+func TestDataObjects(t *testing.T) {
+	queryMetastore(t, tenantID, func(ctx context.Context, start, end time.Time, mstore Metastore) {
+		all, err := mstore.DataObjects(ctx, start, end)
+		require.NoError(t, err)
+		require.Len(t, all, 5)
+
+		// Each object contains a single stream, so only the objects of the
+		// matching streams are returned.
+		paths, err := mstore.DataObjects(ctx, start, end, labels.MustNewMatcher(labels.MatchEqual, "app", "bar"))
+		require.NoError(t, err)
+		require.Len(t, paths, 2)
+
+		paths, err = mstore.DataObjects(ctx, start, end,
+			labels.MustNewMatcher(labels.MatchEqual, "app", "baz"),
+			labels.MustNewMatcher(labels.MatchEqual, "team", "a"),
+		)
+		require.NoError(t, err)
+		require.Len(t, paths, 1)
+
+		paths, err = mstore.DataObjects(ctx, start, end, labels.MustNewMatcher(labels.MatchEqual, "app", "invalid"))
+		require.NoError(t, err)
+		require.Empty(t, paths)
+
+		paths, err = mstore.DataObjects(ctx, start, end, labels.MustNewMatcher(labels.MatchNotEqual, "app", "bar"))
+		require.NoError(t, err)
+		require.Len(t, paths, 5)
+	})
+}
+
 func TestLabels(t *testing.T) {
 	matchers := []*labels.Matcher{
 		labels.MustNewMatcher(labels.MatchEqual, "app", "foo"),
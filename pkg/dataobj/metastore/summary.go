@@ -0,0 +1,114 @@
+package metastore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/willf/bloom"
+)
+
+// summaryFalsePositiveRate is the false positive rate of the bloom filter of
+// a labels summary.
+const summaryFalsePositiveRate = 0.01
+
+// labelsSummary summarizes the stream labels of a data object. It is used to
+// find the data objects that can contain streams matching a set of label
+// matchers without reading their streams sections.
+//
+// The summary is a bloom filter of the names of the labels, and of each pair
+// of label name and value of the streams of the object.
+type labelsSummary struct {
+	filter *bloom.BloomFilter
+}
+
+// newLabelsSummary returns a labels summary of the given distinct values of
+// each label, by label name.
+func newLabelsSummary(labelValues map[string][]string) *labelsSummary {
+	var n int
+	for _, values := range labelValues {
+		n += 1 + len(values)
+	}
+
+	filter := bloom.NewWithEstimates(uint(max(n, 1)), summaryFalsePositiveRate)
+	for name, values := range labelValues {
+		filter.AddString(name)
+		for _, value := range values {
+			filter.AddString(summaryValueKey(name, value))
+		}
+	}
+	return &labelsSummary{filter: filter}
+}
+
+// decodeLabelsSummary decodes a labels summary encoded with
+// [labelsSummary.String].
+func decodeLabelsSummary(s string) (*labelsSummary, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decoding labels summary: %w", err)
+	}
+	var filter bloom.BloomFilter
+	if _, err := filter.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("reading labels summary: %w", err)
+	}
+	return &labelsSummary{filter: &filter}, nil
+}
+
+// String encodes the labels summary so it can be stored as a label value.
+func (s *labelsSummary) String() string {
+	var buf bytes.Buffer
+	if _, err := s.filter.WriteTo(&buf); err != nil {
+		// Writing to a bytes.Buffer does not fail.
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// Matches returns false if no stream summarized by s can match all of the
+// given matchers. False positives are possible, false negatives are not.
+func (s *labelsSummary) Matches(matchers ...*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !s.matches(m) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *labelsSummary) matches(m *labels.Matcher) bool {
+	// Streams without the label have an empty value for it, which is
+	// matched by the matcher if it matches the empty value.
+	if m.Matches("") {
+		return true
+	}
+	if !s.filter.TestString(m.Name) {
+		return false
+	}
+
+	switch m.Type {
+	case labels.MatchEqual:
+		return s.filter.TestString(summaryValueKey(m.Name, m.Value))
+	case labels.MatchRegexp:
+		// Regular expressions of a set of alternatives, such as "a|b", only
+		// match one of their values.
+		values := m.SetMatches()
+		if len(values) == 0 {
+			return true
+		}
+		for _, value := range values {
+			if s.filter.TestString(summaryValueKey(m.Name, value)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// summaryValueKey returns the key of a pair of label name and value. The
+// separator is not valid UTF-8, so the key cannot collide with a label name.
+func summaryValueKey(name, value string) string {
+	return name + "\xff" + value
+}
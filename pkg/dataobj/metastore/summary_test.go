@@ -0,0 +1,42 @@
+package metastore
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelsSummary(t *testing.T) {
+	summary := newLabelsSummary(map[string][]string{
+		"app": {"bar", "foo"},
+		"env": {"prod"},
+	})
+
+	// The summary is stored as a label value, so it must survive encoding.
+	decoded, err := decodeLabelsSummary(summary.String())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		matcher *labels.Matcher
+		want    bool
+	}{
+		{name: "equal", matcher: labels.MustNewMatcher(labels.MatchEqual, "app", "foo"), want: true},
+		{name: "equal missing value", matcher: labels.MustNewMatcher(labels.MatchEqual, "app", "baz"), want: false},
+		{name: "equal missing label", matcher: labels.MustNewMatcher(labels.MatchEqual, "team", "a"), want: false},
+		{name: "equal empty value", matcher: labels.MustNewMatcher(labels.MatchEqual, "team", ""), want: true},
+		{name: "not equal", matcher: labels.MustNewMatcher(labels.MatchNotEqual, "app", "foo"), want: true},
+		{name: "not equal empty value", matcher: labels.MustNewMatcher(labels.MatchNotEqual, "team", ""), want: false},
+		{name: "regexp alternatives", matcher: labels.MustNewMatcher(labels.MatchRegexp, "app", "baz|foo"), want: true},
+		{name: "regexp missing alternatives", matcher: labels.MustNewMatcher(labels.MatchRegexp, "app", "baz|qux"), want: false},
+		{name: "regexp", matcher: labels.MustNewMatcher(labels.MatchRegexp, "app", "b.+"), want: true},
+		{name: "regexp missing label", matcher: labels.MustNewMatcher(labels.MatchRegexp, "team", ".+"), want: false},
+		{name: "not regexp", matcher: labels.MustNewMatcher(labels.MatchNotRegexp, "app", "foo"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, decoded.Matches(tt.matcher))
+		})
+	}
+}
@@ -2,9 +2,14 @@ package metastore
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"fmt"
 	"io"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,6 +29,19 @@ const (
 	labelNameStart = "__start__"
 	labelNameEnd   = "__end__"
 	labelNamePath  = "__path__"
+
+	// labelNameLabels is the name of the label holding the encoded
+	// [labelsSummary] of the stream labels of a data object.
+	labelNameLabels = "__labels__"
+
+	// labelNameKafkaOffsets is the name of the label holding the Kafka records
+	// a data object was built from, encoded by [formatKafkaOffsets].
+	labelNameKafkaOffsets = "__kafka_offsets__"
+
+	// labelNameReplacedKafkaOffsets is the name of the label holding the Kafka
+	// records of the data objects a data object replaced, encoded as a comma
+	// separated list of [formatKafkaOffsets].
+	labelNameReplacedKafkaOffsets = "__replaced_kafka_offsets__"
 )
 
 // Define our own builder config because metastore objects are significantly smaller.
@@ -87,8 +105,11 @@ func (m *Updater) initBuilder() error {
 }
 
 // Update adds provided dataobj path to the metastore. Flush stats are used to determine the stored metadata about this dataobj.
+//
+// Update is idempotent: updating the same path again replaces its existing
+// entries, so that retrying an interrupted update never lists a data object
+// twice.
 func (m *Updater) Update(ctx context.Context, dataobjPath string, flushStats dataobj.FlushStats) error {
-	var err error
 	processingTime := prometheus.NewTimer(m.metrics.metastoreProcessingTime)
 	defer processingTime.ObserveDuration()
 
@@ -97,43 +118,214 @@ func (m *Updater) Update(ctx context.Context, dataobjPath string, flushStats dat
 		return err
 	}
 
-	minTimestamp, maxTimestamp := flushStats.MinTimestamp, flushStats.MaxTimestamp
+	ls := objectLabels(dataobjPath, flushStats)
+	removed := map[string]struct{}{dataobjPath: {}}
 
 	// Work our way through the metastore objects window by window, updating & creating them as needed.
 	// Each one handles its own retries in order to keep making progress in the event of a failure.
-	for metastorePath := range iterStorePaths(m.tenantID, minTimestamp, maxTimestamp) {
-		m.backoff.Reset()
-		for m.backoff.Ongoing() {
-			err = m.bucket.GetAndReplace(ctx, metastorePath, func(existing io.Reader) (io.Reader, error) {
-				m.buf.Reset()
-				if existing != nil {
-					level.Debug(m.logger).Log("msg", "found existing metastore, updating", "path", metastorePath)
-					_, err := io.Copy(m.buf, existing)
-					if err != nil {
-						return nil, errors.Wrap(err, "copying to local buffer")
-					}
-				} else {
-					level.Debug(m.logger).Log("msg", "no existing metastore found, creating new one", "path", metastorePath)
+	// The update stops at the first window which can't be updated, so that callers don't consider the
+	// data object listed; as updates are idempotent, retrying the update lists it in all windows.
+	for metastorePath := range iterStorePaths(m.tenantID, flushStats.MinTimestamp, flushStats.MaxTimestamp) {
+		if err := m.updateStore(ctx, metastorePath, removed, []labels.Labels{ls}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replace atomically replaces the entries of the data objects at the removed
+// paths with entries for the added data objects in the metastore object of
+// window. Added data objects are expected to be within window.
+//
+// Replace is used to swap the inputs of a compaction for its outputs, so that
+// readers of the metastore never see both or neither of them. The Kafka
+// records of the removed data objects are recorded in the entries of the added
+// data objects, so that the dataobj consumer can tell which records are stored
+// after compaction.
+func (m *Updater) Replace(ctx context.Context, window time.Time, removed []string, added map[string]dataobj.FlushStats) error {
+	processingTime := prometheus.NewTimer(m.metrics.metastoreProcessingTime)
+	defer processingTime.ObserveDuration()
+
+	if err := m.initBuilder(); err != nil {
+		return err
+	}
+
+	removedPaths := make(map[string]struct{}, len(removed))
+	for _, path := range removed {
+		removedPaths[path] = struct{}{}
+	}
+
+	addedLabels := make([]labels.Labels, 0, len(added))
+	for _, path := range slices.Sorted(maps.Keys(added)) {
+		addedLabels = append(addedLabels, objectLabels(path, added[path]))
+	}
+
+	return m.updateStore(ctx, metastorePath(m.tenantID, window.Truncate(WindowSize).UTC()), removedPaths, addedLabels)
+}
+
+// objectLabels returns the labels of the metastore entry of the data object
+// at path.
+func objectLabels(path string, flushStats dataobj.FlushStats) labels.Labels {
+	ls := labels.New(
+		labels.Label{Name: labelNameStart, Value: strconv.FormatInt(flushStats.MinTimestamp.UnixNano(), 10)},
+		labels.Label{Name: labelNameEnd, Value: strconv.FormatInt(flushStats.MaxTimestamp.UnixNano(), 10)},
+		labels.Label{Name: labelNamePath, Value: path},
+	)
+	if len(flushStats.LabelValues) == 0 && flushStats.KafkaOffsets == nil {
+		return ls
+	}
+
+	builder := labels.NewBuilder(ls)
+	if len(flushStats.LabelValues) > 0 {
+		// Objects without a labels summary are always considered to contain
+		// matching streams.
+		builder.Set(labelNameLabels, newLabelsSummary(flushStats.LabelValues).String())
+	}
+	if flushStats.KafkaOffsets != nil {
+		builder.Set(labelNameKafkaOffsets, formatKafkaOffsets(*flushStats.KafkaOffsets))
+	}
+	return builder.Labels()
+}
+
+// formatKafkaOffsets encodes offsets as <topic>:<partition>:<min>:<max>.
+// Kafka topic names can't contain colons.
+func formatKafkaOffsets(offsets dataobj.KafkaOffsets) string {
+	return fmt.Sprintf("%s:%d:%d:%d", offsets.Topic, offsets.Partition, offsets.MinOffset, offsets.MaxOffset)
+}
+
+// parseKafkaOffsets decodes offsets encoded by [formatKafkaOffsets].
+func parseKafkaOffsets(value string) (dataobj.KafkaOffsets, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 {
+		return dataobj.KafkaOffsets{}, false
+	}
+
+	partition, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return dataobj.KafkaOffsets{}, false
+	}
+	minOffset, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return dataobj.KafkaOffsets{}, false
+	}
+	maxOffset, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return dataobj.KafkaOffsets{}, false
+	}
+	return dataobj.KafkaOffsets{
+		Topic:     parts[0],
+		Partition: int32(partition),
+		MinOffset: minOffset,
+		MaxOffset: maxOffset,
+	}, true
+}
+
+// formatKafkaOffsetsList encodes offsets as a comma separated list of
+// [formatKafkaOffsets]. Kafka topic names can't contain commas.
+func formatKafkaOffsetsList(offsets []dataobj.KafkaOffsets) string {
+	values := make([]string, 0, len(offsets))
+	for _, o := range offsets {
+		values = append(values, formatKafkaOffsets(o))
+	}
+	return strings.Join(values, ",")
+}
+
+// parseKafkaOffsetsList decodes offsets encoded by [formatKafkaOffsetsList].
+func parseKafkaOffsetsList(value string) ([]dataobj.KafkaOffsets, bool) {
+	var offsets []dataobj.KafkaOffsets
+	for _, v := range strings.Split(value, ",") {
+		o, ok := parseKafkaOffsets(v)
+		if !ok {
+			return nil, false
+		}
+		offsets = append(offsets, o)
+	}
+	return offsets, true
+}
+
+// mergeKafkaOffsets sorts offsets and merges the overlapping or adjacent
+// records of the same partition, so that the Kafka records of data objects
+// replaced over and over again don't grow without bounds.
+func mergeKafkaOffsets(offsets []dataobj.KafkaOffsets) []dataobj.KafkaOffsets {
+	slices.SortFunc(offsets, func(a, b dataobj.KafkaOffsets) int {
+		if c := strings.Compare(a.Topic, b.Topic); c != 0 {
+			return c
+		} else if c := cmp.Compare(a.Partition, b.Partition); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.MinOffset, b.MinOffset)
+	})
+
+	var merged []dataobj.KafkaOffsets
+	for _, o := range offsets {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.Topic == o.Topic && last.Partition == o.Partition && o.MinOffset <= last.MaxOffset+1 {
+				last.MaxOffset = max(last.MaxOffset, o.MaxOffset)
+				continue
+			}
+		}
+		merged = append(merged, o)
+	}
+	return merged
+}
+
+// updateStore rewrites the metastore object at metastorePath, dropping the
+// entries of the removed data object paths and appending the added entries.
+// The Kafka records of the removed entries are recorded in the added entries,
+// unless the same data object is added again.
+//
+// The metastore object is created if it doesn't exist. If no entries remain,
+// the metastore object is kept empty rather than deleted, as a delete can't be
+// made conditional on the object being unchanged and could drop entries added
+// concurrently. Empty metastore objects are deleted with [DeleteEmpty] once no
+// more entries are added to their window. updateStore retries until it
+// succeeds or ctx is canceled.
+func (m *Updater) updateStore(ctx context.Context, metastorePath string, removed map[string]struct{}, added []labels.Labels) error {
+	var err error
+
+	m.backoff.Reset()
+	for m.backoff.Ongoing() {
+		err = m.bucket.GetAndReplace(ctx, metastorePath, func(existing io.Reader) (io.Reader, error) {
+			m.buf.Reset()
+			if existing != nil {
+				level.Debug(m.logger).Log("msg", "found existing metastore, updating", "path", metastorePath)
+				_, err := io.Copy(m.buf, existing)
+				if err != nil {
+					return nil, errors.Wrap(err, "copying to local buffer")
 				}
+			} else {
+				level.Debug(m.logger).Log("msg", "no existing metastore found, creating new one", "path", metastorePath)
+			}
 
-				m.metastoreBuilder.Reset()
+			m.metastoreBuilder.Reset()
 
-				if m.buf.Len() > 0 {
-					replayDuration := prometheus.NewTimer(m.metrics.metastoreReplayTime)
-					object := dataobj.FromReaderAt(bytes.NewReader(m.buf.Bytes()), int64(m.buf.Len()))
-					if err := m.readFromExisting(ctx, object); err != nil {
-						return nil, errors.Wrap(err, "reading existing metastore version")
-					}
-					replayDuration.ObserveDuration()
+			var (
+				kept     int
+				replaced []dataobj.KafkaOffsets
+			)
+			if m.buf.Len() > 0 {
+				replayDuration := prometheus.NewTimer(m.metrics.metastoreReplayTime)
+				object := dataobj.FromReaderAt(bytes.NewReader(m.buf.Bytes()), int64(m.buf.Len()))
+				n, removedEntries, err := m.readFromExisting(ctx, object, removed)
+				if err != nil {
+					return nil, errors.Wrap(err, "reading existing metastore version")
 				}
+				kept = n
+				replaced = replacedKafkaOffsets(removedEntries, added)
+				replayDuration.ObserveDuration()
+			}
+			if kept+len(added) == 0 {
+				// Readers treat empty metastore objects as having no entries.
+				return bytes.NewReader(nil), nil
+			}
 
-				encodingDuration := prometheus.NewTimer(m.metrics.metastoreEncodingTime)
+			encodingDuration := prometheus.NewTimer(m.metrics.metastoreEncodingTime)
 
-				ls := labels.New(
-					labels.Label{Name: labelNameStart, Value: strconv.FormatInt(minTimestamp.UnixNano(), 10)},
-					labels.Label{Name: labelNameEnd, Value: strconv.FormatInt(maxTimestamp.UnixNano(), 10)},
-					labels.Label{Name: labelNamePath, Value: dataobjPath},
-				)
+			for _, ls := range added {
+				if len(replaced) > 0 {
+					ls = labels.NewBuilder(ls).Set(labelNameReplacedKafkaOffsets, formatKafkaOffsetsList(replaced)).Labels()
+				}
 				err := m.metastoreBuilder.Append(logproto.Stream{
 					Labels:  ls.String(),
 					Entries: []logproto.Entry{{Line: ""}},
@@ -141,59 +333,96 @@ func (m *Updater) Update(ctx context.Context, dataobjPath string, flushStats dat
 				if err != nil {
 					return nil, errors.Wrap(err, "appending internal metadata stream")
 				}
+			}
 
-				m.buf.Reset()
-				_, err = m.metastoreBuilder.Flush(m.buf)
-				if err != nil {
-					return nil, errors.Wrap(err, "flushing metastore builder")
-				}
-				encodingDuration.ObserveDuration()
-				return m.buf, nil
-			})
-			if err == nil {
-				level.Info(m.logger).Log("msg", "successfully merged & updated metastore", "metastore", metastorePath)
-				m.metrics.incMetastoreWrites(statusSuccess)
-				break
+			m.buf.Reset()
+			_, err := m.metastoreBuilder.Flush(m.buf)
+			if err != nil {
+				return nil, errors.Wrap(err, "flushing metastore builder")
 			}
-			level.Error(m.logger).Log("msg", "failed to get and replace metastore object", "err", err, "metastore", metastorePath)
-			m.metrics.incMetastoreWrites(statusFailure)
-			m.backoff.Wait()
+			encodingDuration.ObserveDuration()
+			return m.buf, nil
+		})
+		if err == nil {
+			level.Info(m.logger).Log("msg", "successfully merged & updated metastore", "metastore", metastorePath)
+			m.metrics.incMetastoreWrites(statusSuccess)
+			break
 		}
-		// Reset at the end too so we don't leave our memory hanging around between calls.
-		m.metastoreBuilder.Reset()
+		level.Error(m.logger).Log("msg", "failed to get and replace metastore object", "err", err, "metastore", metastorePath)
+		m.metrics.incMetastoreWrites(statusFailure)
+		m.backoff.Wait()
 	}
+	// Reset at the end too so we don't leave our memory hanging around between calls.
+	m.metastoreBuilder.Reset()
 	return err
 }
 
 // readFromExisting reads the provided metastore object and appends the streams to the builder so it can be later modified.
-func (m *Updater) readFromExisting(ctx context.Context, object *dataobj.Object) error {
+// Streams of the data objects at the removed paths are skipped. readFromExisting returns the number of appended streams
+// and the entries of the skipped streams.
+func (m *Updater) readFromExisting(ctx context.Context, object *dataobj.Object, removed map[string]struct{}) (int, []Entry, error) {
 	// Fetch sections
 	si, err := object.Metadata(ctx)
 	if err != nil {
-		return errors.Wrap(err, "resolving object metadata")
+		return 0, nil, errors.Wrap(err, "resolving object metadata")
 	}
 
 	var streamsReader dataobj.StreamsReader
 	defer streamsReader.Close()
 
 	// Read streams from existing metastore object and write them to the builder for the new object
+	var (
+		appended       int
+		removedEntries []Entry
+	)
 	streams := make([]dataobj.Stream, 100)
 	for i := 0; i < si.StreamsSections; i++ {
 		streamsReader.Reset(object, i)
 		for n, err := streamsReader.Read(ctx, streams); n > 0; n, err = streamsReader.Read(ctx, streams) {
 			if err != nil && err != io.EOF {
-				return errors.Wrap(err, "reading streams")
+				return appended, removedEntries, errors.Wrap(err, "reading streams")
 			}
 			for _, stream := range streams[:n] {
+				if _, ok := removed[stream.Labels.Get(labelNamePath)]; ok {
+					if entry, ok := parseEntry(stream.Labels); ok {
+						removedEntries = append(removedEntries, entry)
+					}
+					continue
+				}
 				err = m.metastoreBuilder.Append(logproto.Stream{
 					Labels:  stream.Labels.String(),
 					Entries: []logproto.Entry{{Line: ""}},
 				})
 				if err != nil {
-					return errors.Wrap(err, "appending streams")
+					return appended, removedEntries, errors.Wrap(err, "appending streams")
 				}
+				appended++
 			}
 		}
 	}
-	return nil
+	return appended, removedEntries, nil
+}
+
+// replacedKafkaOffsets returns the Kafka records of the removed entries whose
+// data objects aren't added again.
+func replacedKafkaOffsets(removed []Entry, added []labels.Labels) []dataobj.KafkaOffsets {
+	addedPaths := make(map[string]struct{}, len(added))
+	for _, ls := range added {
+		addedPaths[ls.Get(labelNamePath)] = struct{}{}
+	}
+
+	var offsets []dataobj.KafkaOffsets
+	for _, entry := range removed {
+		if _, ok := addedPaths[entry.Path]; ok {
+			continue
+		}
+		if entry.KafkaOffsets != nil {
+			offsets = append(offsets, *entry.KafkaOffsets)
+		}
+		offsets = append(offsets, entry.ReplacedKafkaOffsets...)
+	}
+	if len(offsets) == 0 {
+		return nil
+	}
+	return mergeKafkaOffsets(offsets)
 }
@@ -67,8 +67,14 @@ type (
 
 	// A LogMessageFilterPredicate is a [LogsPredicate] that requires the log message
 	// of the entry to pass a Keep function.
+	//
+	// Contains optionally lists substrings that every log message passing Keep
+	// is guaranteed to contain. When set, the token index of the data object is
+	// used to skip rows which can't contain the substrings before reading log
+	// messages.
 	LogMessageFilterPredicate struct {
-		Keep func(line []byte) bool
+		Keep     func(line []byte) bool
+		Contains []string
 	}
 
 	// A MetadataMatcherPredicate is a [LogsPredicate] that requires a metadata
@@ -88,6 +94,20 @@ type (
 		Key  string
 		Keep func(key, value string) bool
 	}
+
+	// A MetadataRangePredicate is a [LogsPredicate] that requires metadata with
+	// the provided key to hold a number (for float64) or a duration (for
+	// [time.Duration]) within the range of Start and End. Metadata values which
+	// can't be parsed as T also pass the predicate.
+	//
+	// MetadataRangePredicate is eligible for page filtering when the metadata
+	// column of the key stores values of type T.
+	MetadataRangePredicate[T float64 | time.Duration] struct {
+		Key          string
+		Start, End   T
+		IncludeStart bool // Whether Start is inclusive.
+		IncludeEnd   bool // Whether End is inclusive.
+	}
 )
 
 func (AndPredicate[P]) isPredicate()           {}
@@ -99,6 +119,7 @@ func (LabelFilterPredicate) isPredicate()      {}
 func (MetadataMatcherPredicate) isPredicate()  {}
 func (MetadataFilterPredicate) isPredicate()   {}
 func (LogMessageFilterPredicate) isPredicate() {}
+func (MetadataRangePredicate[T]) isPredicate() {}
 
 func (AndPredicate[P]) predicateKind(P)                       {}
 func (OrPredicate[P]) predicateKind(P)                        {}
@@ -109,3 +130,4 @@ func (LabelFilterPredicate) predicateKind(StreamsPredicate)   {}
 func (MetadataMatcherPredicate) predicateKind(LogsPredicate)  {}
 func (MetadataFilterPredicate) predicateKind(LogsPredicate)   {}
 func (LogMessageFilterPredicate) predicateKind(LogsPredicate) {}
+func (MetadataRangePredicate[T]) predicateKind(LogsPredicate) {}
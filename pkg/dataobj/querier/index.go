@@ -0,0 +1,214 @@
+package querier
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
+	"github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/sharding"
+	"github.com/grafana/loki/v3/pkg/util"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// Stats implements querier.Store
+//
+// Each stream of a data object counts as a chunk. The bytes and entries of a
+// stream are prorated by the overlap of its time range with the requested time
+// range.
+func (s *Store) Stats(ctx context.Context, _ string, from, through model.Time, matchers ...*labels.Matcher) (*stats.Stats, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "dataobj.Stats")
+	defer span.Finish()
+
+	logger := util_log.WithContext(ctx, s.logger)
+	start, end := from.Time(), through.Time()
+	matchers = withoutMatchAll(matchers)
+
+	objects, err := s.objectsForTimeRange(ctx, start, end, logger, matchers...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mtx     sync.Mutex
+		res     stats.Stats
+		streams = make(map[uint64]struct{})
+	)
+
+	processor := newStreamProcessor(start, end, matchers, objects, noShard, logger)
+	err = processor.ProcessAllParallel(ctx, func(h uint64, stream dataobj.Stream) {
+		bytes, entries := streamStats(stream, from, through)
+		if entries == 0 {
+			return
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		if _, ok := streams[h]; !ok {
+			streams[h] = struct{}{}
+			res.Streams++
+		}
+		res.Chunks++
+		res.Bytes += bytes
+		res.Entries += entries
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Volume implements querier.Store
+//
+// Volumes are aggregated either by series or by label name, following the
+// semantics of the TSDB index: by default series are aggregated into the labels
+// of the given matchers, or into the given target labels if any.
+func (s *Store) Volume(ctx context.Context, _ string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "dataobj.Volume")
+	defer span.Finish()
+
+	logger := util_log.WithContext(ctx, s.logger)
+	start, end := from.Time(), through.Time()
+
+	labelsToMatch, matchers, includeAll := util.PrepareLabelsAndMatchers(targetLabels, matchers)
+	matchers = withoutMatchAll(matchers)
+	// Without target labels, an empty selector aggregates by whole series.
+	includeAll = includeAll || len(labelsToMatch) == 0
+	aggregateBySeries := seriesvolume.AggregateBySeries(aggregateBy) || aggregateBy == ""
+
+	objects, err := s.objectsForTimeRange(ctx, start, end, logger, matchers...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mtx     sync.Mutex
+		volumes = make(map[string]uint64)
+	)
+	addVolume := func(name string, bytes uint64) {
+		if _, ok := volumes[name]; !ok {
+			// Labels of streams are reused by the readers.
+			name = strings.Clone(name)
+		}
+		volumes[name] += bytes
+	}
+
+	processor := newStreamProcessor(start, end, matchers, objects, noShard, logger)
+	err = processor.ProcessAllParallel(ctx, func(_ uint64, stream dataobj.Stream) {
+		bytes, entries := streamStats(stream, from, through)
+		if entries == 0 {
+			return
+		}
+
+		if aggregateBySeries {
+			series := make(labels.Labels, 0, len(stream.Labels))
+			for _, l := range stream.Labels {
+				if _, ok := labelsToMatch[l.Name]; includeAll || ok {
+					series = append(series, l)
+				}
+			}
+			name := series.String()
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			addVolume(name, bytes)
+			return
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, l := range stream.Labels {
+			if _, ok := labelsToMatch[l.Name]; len(targetLabels) == 0 || ok {
+				addVolume(l.Name, bytes)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seriesvolume.MapToVolumeResponse(volumes, int(limit)), nil
+}
+
+// GetShards implements querier.Store
+//
+// Shards are bounded by stream fingerprints, so that the uncompressed bytes of
+// each shard are close to targetBytesPerShard.
+func (s *Store) GetShards(ctx context.Context, _ string, from, through model.Time, targetBytesPerShard uint64, predicate chunk.Predicate) (*logproto.ShardsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "dataobj.GetShards")
+	defer span.Finish()
+
+	logger := util_log.WithContext(ctx, s.logger)
+	start, end := from.Time(), through.Time()
+	matchers := withoutMatchAll(predicate.Matchers)
+
+	objects, err := s.objectsForTimeRange(ctx, start, end, logger, matchers...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mtx   sync.Mutex
+		resp  = &logproto.ShardsResponse{}
+		sizes = make(map[model.Fingerprint]stats.Stats)
+	)
+
+	processor := newStreamProcessor(start, end, matchers, objects, noShard, logger)
+	err = processor.ProcessAllParallel(ctx, func(_ uint64, stream dataobj.Stream) {
+		bytes, entries := streamStats(stream, from, through)
+		if entries == 0 {
+			return
+		}
+		fp := model.Fingerprint(stream.Labels.Hash())
+
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		size := sizes[fp]
+		size.Chunks++
+		size.Bytes += bytes
+		size.Entries += entries
+		sizes[fp] = size
+		resp.Statistics.Index.TotalChunks++
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(sharding.SizedFPs, 0, len(sizes))
+	for fp, size := range sizes {
+		series = append(series, sharding.SizedFP{Fp: fp, Stats: size})
+	}
+	sort.Sort(series)
+	resp.Shards = series.ShardsFor(targetBytesPerShard)
+
+	return resp, nil
+}
+
+// streamStats returns the uncompressed bytes and the entries of a stream,
+// prorated by the overlap of the time range of the stream with [from, through].
+func streamStats(stream dataobj.Stream, from, through model.Time) (bytes, entries uint64) {
+	factor := util.GetFactorOfTime(from.UnixNano(), through.UnixNano(), stream.MinTime.UnixNano(), stream.MaxTime.UnixNano())
+	return uint64(float64(stream.UncompressedSize) * factor), uint64(float64(stream.Rows) * factor)
+}
+
+// withoutMatchAll returns the matchers without the matchers of an empty label
+// name, which are used to select all streams.
+func withoutMatchAll(matchers []*labels.Matcher) []*labels.Matcher {
+	res := make([]*labels.Matcher, 0, len(matchers))
+	for _, m := range matchers {
+		if m.Name != "" {
+			res = append(res, m)
+		}
+	}
+	return res
+}
@@ -0,0 +1,228 @@
+package querier
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
+)
+
+func TestStore_Stats(t *testing.T) {
+	const testTenant = "test-tenant"
+	builder := newTestDataBuilder(t, testTenant)
+	defer builder.close()
+
+	now := setupTestData(t, builder)
+	meta := metastore.NewObjectMetastore(builder.bucket)
+	store := NewStore(builder.bucket, log.NewNopLogger(), meta)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	tests := []struct {
+		name     string
+		matchers []*labels.Matcher
+		from     time.Time
+		through  time.Time
+		want     *stats.Stats
+	}{
+		{
+			name:     "all streams in range",
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "app", ".+")},
+			from:     now,
+			through:  now.Add(time.Hour),
+			want:     &stats.Stats{Streams: 5, Chunks: 5, Bytes: 72, Entries: 18},
+		},
+		{
+			name:     "streams matching equality matcher",
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "foo")},
+			from:     now,
+			through:  now.Add(time.Hour),
+			want:     &stats.Stats{Streams: 2, Chunks: 2, Bytes: 28, Entries: 7},
+		},
+		{
+			name:     "stream present in multiple objects",
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "env", "prod"), labels.MustNewMatcher(labels.MatchEqual, "app", "foo")},
+			from:     now.Add(-3 * time.Hour),
+			through:  now.Add(time.Hour),
+			want:     &stats.Stats{Streams: 1, Chunks: 2, Bytes: 49, Entries: 7},
+		},
+		{
+			name:     "stream partially in range",
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "baz")},
+			from:     now.Add(27 * time.Second),
+			through:  now.Add(time.Hour),
+			want:     &stats.Stats{Streams: 1, Chunks: 1, Bytes: 8, Entries: 2},
+		},
+		{
+			name:     "no matching streams",
+			matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "qux")},
+			from:     now,
+			through:  now.Add(time.Hour),
+			want:     &stats.Stats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Stats(ctx, testTenant, model.TimeFromUnixNano(tt.from.UnixNano()), model.TimeFromUnixNano(tt.through.UnixNano()), tt.matchers...)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStore_Volume(t *testing.T) {
+	const testTenant = "test-tenant"
+	builder := newTestDataBuilder(t, testTenant)
+	defer builder.close()
+
+	now := setupTestData(t, builder)
+	meta := metastore.NewObjectMetastore(builder.bucket)
+	store := NewStore(builder.bucket, log.NewNopLogger(), meta)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	from, through := model.TimeFromUnixNano(now.UnixNano()), model.TimeFromUnixNano(now.Add(time.Hour).UnixNano())
+
+	tests := []struct {
+		name         string
+		matchers     []*labels.Matcher
+		targetLabels []string
+		aggregateBy  string
+		limit        int32
+		want         []logproto.Volume
+	}{
+		{
+			name:        "aggregate by matcher labels",
+			matchers:    []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "app", ".+")},
+			aggregateBy: "series",
+			limit:       10,
+			want: []logproto.Volume{
+				{Name: `{app="bar"}`, Volume: 28},
+				{Name: `{app="foo"}`, Volume: 28},
+				{Name: `{app="baz"}`, Volume: 16},
+			},
+		},
+		{
+			name:        "aggregate by series without matchers",
+			aggregateBy: "series",
+			limit:       2,
+			want: []logproto.Volume{
+				{Name: `{app="bar", env="prod"}`, Volume: 16},
+				{Name: `{app="baz", env="prod", team="a"}`, Volume: 16},
+			},
+		},
+		{
+			name:         "aggregate by target labels",
+			matchers:     []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "foo")},
+			targetLabels: []string{"env"},
+			aggregateBy:  "series",
+			limit:        10,
+			want: []logproto.Volume{
+				{Name: `{env="prod"}`, Volume: 16},
+				{Name: `{env="dev"}`, Volume: 12},
+			},
+		},
+		{
+			name:        "aggregate by labels",
+			matchers:    []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "app", ".+")},
+			aggregateBy: "labels",
+			limit:       10,
+			want: []logproto.Volume{
+				{Name: "app", Volume: 72},
+				{Name: "env", Volume: 72},
+				{Name: "team", Volume: 16},
+			},
+		},
+		{
+			name:         "aggregate by target labels names",
+			matchers:     []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "app", ".+")},
+			targetLabels: []string{"team"},
+			aggregateBy:  "labels",
+			limit:        10,
+			want: []logproto.Volume{
+				{Name: "team", Volume: 16},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Volume(ctx, testTenant, from, through, tt.limit, tt.targetLabels, tt.aggregateBy, tt.matchers...)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Volumes)
+			require.Equal(t, tt.limit, got.Limit)
+		})
+	}
+}
+
+func TestStore_GetShards(t *testing.T) {
+	const testTenant = "test-tenant"
+	builder := newTestDataBuilder(t, testTenant)
+	defer builder.close()
+
+	now := setupTestData(t, builder)
+	meta := metastore.NewObjectMetastore(builder.bucket)
+	store := NewStore(builder.bucket, log.NewNopLogger(), meta)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	from, through := model.TimeFromUnixNano(now.UnixNano()), model.TimeFromUnixNano(now.Add(time.Hour).UnixNano())
+	predicate := chunk.NewPredicate([]*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "app", ".+")}, nil)
+
+	t.Run("single shard", func(t *testing.T) {
+		resp, err := store.GetShards(ctx, testTenant, from, through, 1<<20, predicate)
+		require.NoError(t, err)
+		require.Len(t, resp.Shards, 1)
+		require.Equal(t, &stats.Stats{Streams: 5, Chunks: 5, Bytes: 72, Entries: 18}, resp.Shards[0].Stats)
+		require.Equal(t, logproto.FPBounds{Min: 0, Max: math.MaxUint64}, resp.Shards[0].Bounds)
+		require.Equal(t, int64(5), resp.Statistics.Index.TotalChunks)
+	})
+
+	t.Run("byte balanced shards", func(t *testing.T) {
+		resp, err := store.GetShards(ctx, testTenant, from, through, 30, predicate)
+		require.NoError(t, err)
+		require.Greater(t, len(resp.Shards), 1)
+
+		var (
+			total   stats.Stats
+			entries int
+			next    model.Fingerprint
+		)
+		for i, shard := range resp.Shards {
+			require.Equal(t, next, shard.Bounds.Min, "shard %d", i)
+			next = shard.Bounds.Max + 1
+			total = stats.MergeStats(&total, shard.Stats)
+
+			// Every entry is read from exactly one shard.
+			it, err := store.SelectLogs(ctx, logql.SelectLogParams{
+				QueryRequest: &logproto.QueryRequest{
+					Start:     now,
+					End:       now.Add(time.Hour),
+					Plan:      planFromString(`{app=~".+"}`),
+					Selector:  `{app=~".+"}`,
+					Shards:    logql.Shards{logql.NewBoundedShard(shard)}.Encode(),
+					Limit:     100,
+					Direction: logproto.FORWARD,
+				},
+			})
+			require.NoError(t, err)
+			shardEntries, err := readAllEntries(it)
+			require.NoError(t, err)
+			entries += len(shardEntries)
+			require.Equal(t, int(shard.Stats.Entries), len(shardEntries), "shard %d", i)
+		}
+		require.Equal(t, model.Fingerprint(math.MaxUint64), resp.Shards[len(resp.Shards)-1].Bounds.Max)
+		require.Equal(t, stats.Stats{Streams: 5, Chunks: 5, Bytes: 72, Entries: 18}, total)
+		require.Equal(t, 18, entries)
+	})
+}
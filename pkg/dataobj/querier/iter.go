@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/deletion"
 )
 
 var (
@@ -65,6 +66,12 @@ func newEntryIterator(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	// Lines matching pending delete requests are masked until the data
+	// objects holding them are rewritten by the compactor.
+	pipeline, err = deletion.SetupPipeline(req, pipeline)
+	if err != nil {
+		return nil, err
+	}
 
 	var (
 		prevStreamID    int64 = -1
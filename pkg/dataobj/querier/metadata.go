@@ -180,6 +180,16 @@ func newStreamProcessor(start, end time.Time, matchers []*labels.Matcher, object
 // ProcessParallel processes series from multiple readers in parallel
 // dataobj.Stream objects returned to onNewStream may be reused and must be deep copied for further use, including the stream.Labels keys and values.
 func (sp *streamProcessor) ProcessParallel(ctx context.Context, onNewStream func(uint64, dataobj.Stream)) error {
+	return sp.processParallel(ctx, true, onNewStream)
+}
+
+// ProcessAllParallel processes series from multiple readers in parallel like [streamProcessor.ProcessParallel],
+// but passes a stream to onStream once for every data object containing it instead of only once.
+func (sp *streamProcessor) ProcessAllParallel(ctx context.Context, onStream func(uint64, dataobj.Stream)) error {
+	return sp.processParallel(ctx, false, onStream)
+}
+
+func (sp *streamProcessor) processParallel(ctx context.Context, unique bool, onNewStream func(uint64, dataobj.Stream)) error {
 	readers, err := shardStreamReaders(ctx, sp.objects, sp.shard)
 	if err != nil {
 		return err
@@ -211,7 +221,7 @@ func (sp *streamProcessor) ProcessParallel(ctx context.Context, onNewStream func
 		g.Go(func() error {
 			span, ctx := opentracing.StartSpanFromContext(ctx, "streamProcessor.processSingleReader")
 			defer span.Finish()
-			n, err := sp.processSingleReader(ctx, reader, onNewStream)
+			n, err := sp.processSingleReader(ctx, reader, unique, onNewStream)
 			if err != nil {
 				return err
 			}
@@ -236,7 +246,7 @@ func (sp *streamProcessor) ProcessParallel(ctx context.Context, onNewStream func
 	return nil
 }
 
-func (sp *streamProcessor) processSingleReader(ctx context.Context, reader *dataobj.StreamsReader, onNewStream func(uint64, dataobj.Stream)) (int64, error) {
+func (sp *streamProcessor) processSingleReader(ctx context.Context, reader *dataobj.StreamsReader, unique bool, onNewStream func(uint64, dataobj.Stream)) (int64, error) {
 	var (
 		streamsPtr = streamsPool.Get().(*[]dataobj.Stream)
 		streams    = *streamsPtr
@@ -256,11 +266,16 @@ func (sp *streamProcessor) processSingleReader(ctx context.Context, reader *data
 			break
 		}
 		for _, stream := range streams[:n] {
-			h, buf = stream.Labels.HashWithoutLabels(buf, []string(nil)...)
-			// Try to claim this hash first
-			if _, seen := sp.seenSeries.LoadOrStore(h, nil); seen {
+			if !matchesShard(sp.shard, stream.Labels) {
 				continue
 			}
+			h, buf = stream.Labels.HashWithoutLabels(buf, []string(nil)...)
+			if unique {
+				// Try to claim this hash first
+				if _, seen := sp.seenSeries.LoadOrStore(h, nil); seen {
+					continue
+				}
+			}
 			onNewStream(h, stream)
 			processed++
 		}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"math"
 	"slices"
 	"strings"
 	"sync"
@@ -19,17 +20,17 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
+	dataobjcache "github.com/grafana/loki/v3/pkg/dataobj/cache"
 	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
 	"github.com/grafana/loki/v3/pkg/iter"
-	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
+	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/querier"
-	"github.com/grafana/loki/v3/pkg/storage/chunk"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	storageconfig "github.com/grafana/loki/v3/pkg/storage/config"
-	"github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+	"github.com/grafana/loki/v3/pkg/util/deletion"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
 
@@ -68,18 +69,26 @@ type Config struct {
 	Enabled     bool                  `yaml:"enabled" doc:"description=Enable the dataobj querier."`
 	From        storageconfig.DayTime `yaml:"from" doc:"description=The date of the first day of when the dataobj querier should start querying from. In YYYY-MM-DD format, for example: 2018-04-15."`
 	ShardFactor int                   `yaml:"shard_factor" doc:"description=The number of shards to use for the dataobj querier."`
+
+	// Cache configures the cache of data object reads, which is shared by the
+	// dataobj querier and the v2 query engine.
+	Cache dataobjcache.Config `yaml:"cache"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&c.Enabled, "dataobj-querier-enabled", false, "Enable the dataobj querier.")
 	f.Var(&c.From, "dataobj-querier-from", "The start time to query from.")
 	f.IntVar(&c.ShardFactor, "dataobj-querier-shard-factor", 32, "The number of shards to use for the dataobj querier.")
+	c.Cache.RegisterFlagsWithPrefix("dataobj-querier-cache.", f)
 }
 
 func (c *Config) Validate() error {
 	if c.Enabled && c.From.ModelTime().Time().IsZero() {
 		return fmt.Errorf("from is required when dataobj querier is enabled")
 	}
+	if err := c.Cache.Validate(); err != nil {
+		return fmt.Errorf("invalid cache config: %w", err)
+	}
 	return nil
 }
 
@@ -152,25 +161,7 @@ func (s *Store) SelectSamples(ctx context.Context, req logql.SelectSampleParams)
 		return nil, err
 	}
 
-	return selectSamples(ctx, objects, shard, expr, req.Start, req.End, logger)
-}
-
-// Stats implements querier.Store
-func (s *Store) Stats(_ context.Context, _ string, _ model.Time, _ model.Time, _ ...*labels.Matcher) (*stats.Stats, error) {
-	// TODO: Implement
-	return &stats.Stats{}, nil
-}
-
-// Volume implements querier.Store
-func (s *Store) Volume(_ context.Context, _ string, _ model.Time, _ model.Time, _ int32, _ []string, _ string, _ ...*labels.Matcher) (*logproto.VolumeResponse, error) {
-	// TODO: Implement
-	return &logproto.VolumeResponse{}, nil
-}
-
-// GetShards implements querier.Store
-func (s *Store) GetShards(_ context.Context, _ string, _ model.Time, _ model.Time, _ uint64, _ chunk.Predicate) (*logproto.ShardsResponse, error) {
-	// TODO: Implement
-	return &logproto.ShardsResponse{}, nil
+	return selectSamples(ctx, objects, shard, expr, req, logger)
 }
 
 type object struct {
@@ -178,15 +169,16 @@ type object struct {
 	path string
 }
 
-// objectsForTimeRange returns data objects for the given time range.
-func (s *Store) objectsForTimeRange(ctx context.Context, from, through time.Time, logger log.Logger) ([]object, error) {
+// objectsForTimeRange returns data objects for the given time range that can
+// contain streams matching the given matchers.
+func (s *Store) objectsForTimeRange(ctx context.Context, from, through time.Time, logger log.Logger, matchers ...*labels.Matcher) ([]object, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "objectsForTimeRange")
 	defer span.Finish()
 
 	span.SetTag("from", from)
 	span.SetTag("through", through)
 
-	files, err := s.metastore.DataObjects(ctx, from, through)
+	files, err := s.metastore.DataObjects(ctx, from, through, matchers...)
 	if err != nil {
 		return nil, err
 	}
@@ -269,7 +261,7 @@ func selectLogs(ctx context.Context, objects []object, shard logql.Shard, req lo
 	return iter.NewSortEntryIterator(iterators, req.Direction), nil
 }
 
-func selectSamples(ctx context.Context, objects []object, shard logql.Shard, expr syntax.SampleExpr, start, end time.Time, logger log.Logger) (iter.SampleIterator, error) {
+func selectSamples(ctx context.Context, objects []object, shard logql.Shard, expr syntax.SampleExpr, req logql.SelectSampleParams, logger log.Logger) (iter.SampleIterator, error) {
 	shardedObjects, err := shardObjects(ctx, objects, shard, logger)
 	if err != nil {
 		return nil, err
@@ -285,11 +277,11 @@ func selectSamples(ctx context.Context, objects []object, shard logql.Shard, exp
 		return nil, err
 	}
 
-	streamsPredicate := streamPredicate(selector.Matchers(), start, end)
+	streamsPredicate := streamPredicate(selector.Matchers(), req.Start, req.End)
 	// TODO: support more predicates and combine with log.Pipeline.
 	var logsPredicate dataobj.LogsPredicate = dataobj.TimeRangePredicate[dataobj.LogsPredicate]{
-		StartTime:    start,
-		EndTime:      end,
+		StartTime:    req.Start,
+		EndTime:      req.End,
 		IncludeStart: true,
 		IncludeEnd:   false,
 	}
@@ -313,7 +305,7 @@ func selectSamples(ctx context.Context, objects []object, shard logql.Shard, exp
 			span.SetTag("object", obj.object.path)
 			span.SetTag("sections", len(obj.logReaders))
 
-			iterator, err := obj.selectSamples(ctx, streamsPredicate, logsPredicate, expr)
+			iterator, err := obj.selectSamples(ctx, streamsPredicate, logsPredicate, expr, req)
 			if err != nil {
 				return err
 			}
@@ -330,6 +322,7 @@ func selectSamples(ctx context.Context, objects []object, shard logql.Shard, exp
 
 type shardedObject struct {
 	object       object
+	shard        logql.Shard
 	streamReader *dataobj.StreamsReader
 	logReaders   []*dataobj.LogsReader
 
@@ -409,6 +402,7 @@ func shardObjects(
 		reader := shardedObjectsPool.Get().(*shardedObject)
 		reader.streamReader = streamReaderPool.Get().(*dataobj.StreamsReader)
 		reader.object = objects[i]
+		reader.shard = shard
 		reader.streamReader.Reset(objects[i].Object, 0)
 
 		for _, section := range sections {
@@ -453,17 +447,20 @@ func (s *shardedObject) reset() {
 	s.logReaders = s.logReaders[:0]
 	s.streamsIDs = s.streamsIDs[:0]
 	s.object = object{}
+	s.shard = logql.Shard{}
 	clear(s.streams)
 }
 
 func (s *shardedObject) selectLogs(ctx context.Context, streamsPredicate dataobj.StreamsPredicate, logsPredicate dataobj.LogsPredicate, req logql.SelectLogParams) (iter.EntryIterator, error) {
-	if err := s.setPredicate(streamsPredicate, logsPredicate); err != nil {
+	if err := s.streamReader.SetPredicate(streamsPredicate); err != nil {
 		return nil, err
 	}
-
 	if err := s.matchStreams(ctx); err != nil {
 		return nil, err
 	}
+	if err := s.setLogsPredicate(logsPredicate); err != nil {
+		return nil, err
+	}
 	iterators := make([]iter.EntryIterator, len(s.logReaders))
 	g, ctx := errgroup.WithContext(ctx)
 
@@ -488,14 +485,16 @@ func (s *shardedObject) selectLogs(ctx context.Context, streamsPredicate dataobj
 	return iter.NewSortEntryIterator(iterators, req.Direction), nil
 }
 
-func (s *shardedObject) selectSamples(ctx context.Context, streamsPredicate dataobj.StreamsPredicate, logsPredicate dataobj.LogsPredicate, expr syntax.SampleExpr) (iter.SampleIterator, error) {
-	if err := s.setPredicate(streamsPredicate, logsPredicate); err != nil {
+func (s *shardedObject) selectSamples(ctx context.Context, streamsPredicate dataobj.StreamsPredicate, logsPredicate dataobj.LogsPredicate, expr syntax.SampleExpr, req logql.QueryParams) (iter.SampleIterator, error) {
+	if err := s.streamReader.SetPredicate(streamsPredicate); err != nil {
 		return nil, err
 	}
-
 	if err := s.matchStreams(ctx); err != nil {
 		return nil, err
 	}
+	if err := s.setLogsPredicate(logsPredicate); err != nil {
+		return nil, err
+	}
 
 	iterators := make([]iter.SampleIterator, len(s.logReaders))
 	g, ctx := errgroup.WithContext(ctx)
@@ -511,6 +510,11 @@ func (s *shardedObject) selectSamples(ctx context.Context, streamsPredicate data
 			if err != nil {
 				return err
 			}
+			for i := range extractors {
+				if extractors[i], err = deletion.SetupExtractor(req, extractors[i]); err != nil {
+					return err
+				}
+			}
 			iter, err := newSampleIterator(ctx, s.streams, extractors, reader)
 			if err != nil {
 				return err
@@ -527,10 +531,11 @@ func (s *shardedObject) selectSamples(ctx context.Context, streamsPredicate data
 	return iter.NewSortSampleIterator(iterators), nil
 }
 
-func (s *shardedObject) setPredicate(streamsPredicate dataobj.StreamsPredicate, logsPredicate dataobj.LogsPredicate) error {
-	if err := s.streamReader.SetPredicate(streamsPredicate); err != nil {
-		return err
-	}
+// setLogsPredicate sets the logs predicate on the log readers. It must be
+// called after matchStreams, as range predicates on metadata shadowed by the
+// labels of matched streams are removed.
+func (s *shardedObject) setLogsPredicate(logsPredicate dataobj.LogsPredicate) error {
+	logsPredicate = removeShadowedRangePredicates(logsPredicate, s.streams)
 	for _, reader := range s.logReaders {
 		if err := reader.SetPredicate(logsPredicate); err != nil {
 			return err
@@ -558,6 +563,9 @@ func (s *shardedObject) matchStreams(ctx context.Context) error {
 		}
 
 		for _, stream := range streams[:n] {
+			if !matchesShard(s.shard, stream.Labels) {
+				continue
+			}
 			s.streams[stream.ID] = stream
 			s.streamsIDs = append(s.streamsIDs, stream.ID)
 		}
@@ -571,6 +579,43 @@ func (s *shardedObject) matchStreams(ctx context.Context) error {
 	return nil
 }
 
+// removeShadowedRangePredicates removes metadata range predicates from p
+// whose key is also a label of any of the streams. Label filters apply to
+// stream labels instead of metadata of the same name, so such predicates
+// would filter out logs that the label filter keeps.
+func removeShadowedRangePredicates(p dataobj.LogsPredicate, streams map[int64]dataobj.Stream) dataobj.LogsPredicate {
+	shadowed := func(key string) bool {
+		for _, stream := range streams {
+			if stream.Labels.Has(key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch p := p.(type) {
+	case dataobj.AndPredicate[dataobj.LogsPredicate]:
+		left := removeShadowedRangePredicates(p.Left, streams)
+		right := removeShadowedRangePredicates(p.Right, streams)
+		switch {
+		case left == nil:
+			return right
+		case right == nil:
+			return left
+		}
+		return dataobj.AndPredicate[dataobj.LogsPredicate]{Left: left, Right: right}
+	case dataobj.MetadataRangePredicate[float64]:
+		if shadowed(p.Key) {
+			return nil
+		}
+	case dataobj.MetadataRangePredicate[time.Duration]:
+		if shadowed(p.Key) {
+			return nil
+		}
+	}
+	return p
+}
+
 // fetchMetadatas fetches metadata of objects in parallel
 func fetchMetadatas(ctx context.Context, objects []object) ([]dataobj.Metadata, error) {
 	if sp := opentracing.SpanFromContext(ctx); sp != nil {
@@ -636,6 +681,13 @@ func matchersToPredicate(matchers []*labels.Matcher) dataobj.StreamsPredicate {
 	return left
 }
 
+// matchesShard returns whether a stream with the given labels belongs to the
+// shard. Power of two shards are applied to whole sections, so only bounded
+// shards filter streams by fingerprint.
+func matchesShard(shard logql.Shard, lbs labels.Labels) bool {
+	return shard.Bounded == nil || shard.Match(model.Fingerprint(lbs.Hash()))
+}
+
 func parseShards(shards []string) (logql.Shard, error) {
 	if len(shards) == 0 {
 		return noShard, nil
@@ -647,9 +699,6 @@ func parseShards(shards []string) (logql.Shard, error) {
 	if len(parsed) == 0 {
 		return noShard, nil
 	}
-	if parsed[0].Variant() != logql.PowerOfTwoVersion {
-		return noShard, fmt.Errorf("unsupported shard variant: %s", parsed[0].Variant())
-	}
 	return parsed[0], nil
 }
 
@@ -696,6 +745,10 @@ func buildLogsPredicateFromPipeline(expr syntax.LogSelectorExpr) (dataobj.LogsPr
 		}
 	)
 
+	// Label filters are only pushed down until a stage modifies the labels
+	// they apply to.
+	var labelsModified bool
+
 Outer:
 	for i, stage := range pipelineExpr.MultiStages {
 		switch s := stage.(type) {
@@ -717,9 +770,22 @@ Outer:
 				Keep: func(line []byte) bool {
 					return f.Filter(line)
 				},
+				Contains: lineFilterContains(s),
 			})
 
+		case *syntax.LabelFilterExpr:
+			// Label filters also apply to stream labels and set an error label
+			// for values which can't be parsed, so the stage is kept and the
+			// predicates only skip logs which can't pass it.
+			if !labelsModified {
+				for _, p := range labelFilterPredicates(s.LabelFilterer) {
+					appendPredicate(p)
+				}
+			}
+			remainingStages = append(remainingStages, s)
+
 		default:
+			labelsModified = true
 			remainingStages = append(remainingStages, s)
 		}
 	}
@@ -731,3 +797,81 @@ Outer:
 
 	return predicate, pipelineExpr
 }
+
+// labelFilterPredicates returns range predicates for the numeric and duration
+// comparisons of f which every log passing f must satisfy.
+func labelFilterPredicates(f logqllog.LabelFilterer) []dataobj.LogsPredicate {
+	switch f := f.(type) {
+	case *logqllog.BinaryLabelFilter:
+		if !f.And {
+			return nil
+		}
+		return append(labelFilterPredicates(f.Left), labelFilterPredicates(f.Right)...)
+
+	case *logqllog.NumericLabelFilter:
+		if pushableLabelName(f.Name) {
+			if p, ok := rangePredicate(f.Name, f.Type, f.Value, math.Inf(-1), math.Inf(1)); ok {
+				return []dataobj.LogsPredicate{p}
+			}
+		}
+
+	case *logqllog.DurationLabelFilter:
+		if pushableLabelName(f.Name) {
+			if p, ok := rangePredicate(f.Name, f.Type, f.Value, math.MinInt64, math.MaxInt64); ok {
+				return []dataobj.LogsPredicate{p}
+			}
+		}
+	}
+	return nil
+}
+
+// pushableLabelName reports whether label filters on name can be pushed down
+// to metadata of the same name. Labels with the duplicate suffix may refer to
+// metadata of another name, which collided with a stream label.
+func pushableLabelName(name string) bool {
+	return !strings.HasSuffix(name, "_extracted")
+}
+
+// rangePredicate converts a comparison against value into a range predicate
+// with the open bounds minValue and maxValue. Inequality can't be represented
+// as a single range and isn't converted.
+func rangePredicate[T float64 | time.Duration](key string, ty logqllog.LabelFilterType, value, minValue, maxValue T) (dataobj.LogsPredicate, bool) {
+	p := dataobj.MetadataRangePredicate[T]{
+		Key:          key,
+		Start:        minValue,
+		End:          maxValue,
+		IncludeStart: true,
+		IncludeEnd:   true,
+	}
+
+	switch ty {
+	case logqllog.LabelFilterEqual:
+		p.Start, p.End = value, value
+	case logqllog.LabelFilterGreaterThan:
+		p.Start, p.IncludeStart = value, false
+	case logqllog.LabelFilterGreaterThanOrEqual:
+		p.Start = value
+	case logqllog.LabelFilterLesserThan:
+		p.End, p.IncludeEnd = value, false
+	case logqllog.LabelFilterLesserThanOrEqual:
+		p.End = value
+	default:
+		return nil, false
+	}
+	return p, true
+}
+
+// lineFilterContains returns the substrings that log lines must contain to
+// pass the line filter expression. Only plain |= filters are taken into
+// account; filters with alternatives are skipped as they don't require any
+// single substring.
+func lineFilterContains(e *syntax.LineFilterExpr) []string {
+	var contains []string
+	for curr := e; curr != nil; curr = curr.Left {
+		if curr.Or != nil || curr.Op != "" || curr.Ty != logqllog.LineMatchEqual || curr.Match == "" {
+			continue
+		}
+		contains = append(contains, curr.Match)
+	}
+	return contains
+}
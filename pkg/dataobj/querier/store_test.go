@@ -3,6 +3,8 @@ package querier
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
@@ -16,9 +18,10 @@ import (
 	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/objstore/providers/filesystem"
 
+	"github.com/grafana/loki/pkg/push"
+
 	"github.com/grafana/loki/v3/pkg/dataobj"
 	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
-	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
 	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
@@ -49,6 +52,7 @@ func TestStore_SelectSamples(t *testing.T) {
 		start    time.Time
 		end      time.Time
 		shards   []string
+		deletes  []*logproto.Delete
 		want     []sampleWithLabels
 	}{
 		{
@@ -125,17 +129,17 @@ func TestStore_SelectSamples(t *testing.T) {
 			end:      now.Add(time.Hour),
 			shards:   []string{"0_of_2"},
 			want: []sampleWithLabels{
-				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(5 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="bar", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(8 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(10 * time.Second).UnixNano(), Value: 1}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Samples: logproto.Sample{Timestamp: now.Add(12 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(15 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="bar", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(18 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(20 * time.Second).UnixNano(), Value: 1}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Samples: logproto.Sample{Timestamp: now.Add(22 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(25 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(30 * time.Second).UnixNano(), Value: 1}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Samples: logproto.Sample{Timestamp: now.Add(32 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="bar", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(38 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(40 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(35 * time.Second).UnixNano(), Value: 1}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Samples: logproto.Sample{Timestamp: now.Add(42 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(45 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(50 * time.Second).UnixNano(), Value: 1}},
 			},
 		},
 		{
@@ -145,13 +149,13 @@ func TestStore_SelectSamples(t *testing.T) {
 			end:      now.Add(time.Hour),
 			shards:   []string{"1_of_2"},
 			want: []sampleWithLabels{
-				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.UnixNano(), Value: 1}},
-				{Labels: `{app="foo", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(10 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="foo", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(20 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(30 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="foo", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(35 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(45 * time.Second).UnixNano(), Value: 1}},
-				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(50 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(5 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="bar", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(8 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(15 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="bar", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(18 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(25 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="bar", env="dev"}`, Samples: logproto.Sample{Timestamp: now.Add(38 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="bar", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(40 * time.Second).UnixNano(), Value: 1}},
 			},
 		},
 		{
@@ -175,6 +179,21 @@ func TestStore_SelectSamples(t *testing.T) {
 				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(50 * time.Second).UnixNano(), Value: 1}},
 			},
 		},
+		{
+			name:     "select with delete request",
+			selector: `rate({app="foo"}[1h])`,
+			start:    now,
+			end:      now.Add(time.Hour),
+			deletes: []*logproto.Delete{
+				{Selector: `{env="dev"}`, Start: now.UnixNano(), End: now.Add(time.Hour).UnixNano()},
+				{Selector: `{env="prod"} |= "foo3"`, Start: now.UnixNano(), End: now.Add(time.Hour).UnixNano()},
+			},
+			want: []sampleWithLabels{
+				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(30 * time.Second).UnixNano(), Value: 1}},
+				{Labels: `{app="foo", env="prod"}`, Samples: logproto.Sample{Timestamp: now.Add(50 * time.Second).UnixNano(), Value: 1}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -186,6 +205,7 @@ func TestStore_SelectSamples(t *testing.T) {
 					Plan:     planFromString(tt.selector),
 					Selector: tt.selector,
 					Shards:   tt.shards,
+					Deletes:  tt.deletes,
 				},
 			})
 			require.NoError(t, err)
@@ -217,6 +237,7 @@ func TestStore_SelectLogs(t *testing.T) {
 		shards    []string
 		limit     uint32
 		direction logproto.Direction
+		deletes   []*logproto.Delete
 		want      []entryWithLabels
 	}{
 		{
@@ -285,17 +306,17 @@ func TestStore_SelectLogs(t *testing.T) {
 			limit:     100,
 			direction: logproto.FORWARD,
 			want: []entryWithLabels{
-				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(5 * time.Second), Line: "bar1"}},
-				{Labels: `{app="bar", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(8 * time.Second), Line: "bar5"}},
+				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now, Line: "foo1"}},
+				{Labels: `{app="foo", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(10 * time.Second), Line: "foo5"}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Entry: logproto.Entry{Timestamp: now.Add(12 * time.Second), Line: "baz1"}},
-				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(15 * time.Second), Line: "bar2"}},
-				{Labels: `{app="bar", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(18 * time.Second), Line: "bar6"}},
+				{Labels: `{app="foo", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(20 * time.Second), Line: "foo6"}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Entry: logproto.Entry{Timestamp: now.Add(22 * time.Second), Line: "baz2"}},
-				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(25 * time.Second), Line: "bar3"}},
+				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(30 * time.Second), Line: "foo2"}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Entry: logproto.Entry{Timestamp: now.Add(32 * time.Second), Line: "baz3"}},
-				{Labels: `{app="bar", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(38 * time.Second), Line: "bar7"}},
-				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(40 * time.Second), Line: "bar4"}},
+				{Labels: `{app="foo", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(35 * time.Second), Line: "foo7"}},
 				{Labels: `{app="baz", env="prod", team="a"}`, Entry: logproto.Entry{Timestamp: now.Add(42 * time.Second), Line: "baz4"}},
+				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(45 * time.Second), Line: "foo3"}},
+				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(50 * time.Second), Line: "foo4"}},
 			},
 		},
 		{
@@ -307,13 +328,13 @@ func TestStore_SelectLogs(t *testing.T) {
 			limit:     100,
 			direction: logproto.FORWARD,
 			want: []entryWithLabels{
-				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now, Line: "foo1"}},
-				{Labels: `{app="foo", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(10 * time.Second), Line: "foo5"}},
-				{Labels: `{app="foo", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(20 * time.Second), Line: "foo6"}},
-				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(30 * time.Second), Line: "foo2"}},
-				{Labels: `{app="foo", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(35 * time.Second), Line: "foo7"}},
-				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(45 * time.Second), Line: "foo3"}},
-				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(50 * time.Second), Line: "foo4"}},
+				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(5 * time.Second), Line: "bar1"}},
+				{Labels: `{app="bar", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(8 * time.Second), Line: "bar5"}},
+				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(15 * time.Second), Line: "bar2"}},
+				{Labels: `{app="bar", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(18 * time.Second), Line: "bar6"}},
+				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(25 * time.Second), Line: "bar3"}},
+				{Labels: `{app="bar", env="dev"}`, Entry: logproto.Entry{Timestamp: now.Add(38 * time.Second), Line: "bar7"}},
+				{Labels: `{app="bar", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(40 * time.Second), Line: "bar4"}},
 			},
 		},
 		{
@@ -341,6 +362,23 @@ func TestStore_SelectLogs(t *testing.T) {
 				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(50 * time.Second), Line: "foo4"}},
 			},
 		},
+		{
+			name:      "select with delete request",
+			selector:  `{app="foo"}`,
+			start:     now,
+			end:       now.Add(time.Hour),
+			limit:     100,
+			direction: logproto.FORWARD,
+			deletes: []*logproto.Delete{
+				{Selector: `{env="dev"}`, Start: now.UnixNano(), End: now.Add(time.Hour).UnixNano()},
+				{Selector: `{env="prod"} |= "foo3"`, Start: now.UnixNano(), End: now.Add(time.Hour).UnixNano()},
+			},
+			want: []entryWithLabels{
+				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now, Line: "foo1"}},
+				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(30 * time.Second), Line: "foo2"}},
+				{Labels: `{app="foo", env="prod"}`, Entry: logproto.Entry{Timestamp: now.Add(50 * time.Second), Line: "foo4"}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -354,6 +392,7 @@ func TestStore_SelectLogs(t *testing.T) {
 					Shards:    tt.shards,
 					Limit:     tt.limit,
 					Direction: tt.direction,
+					Deletes:   tt.deletes,
 				},
 			})
 			require.NoError(t, err)
@@ -366,6 +405,60 @@ func TestStore_SelectLogs(t *testing.T) {
 	}
 }
 
+func TestStore_SelectLogs_MetadataRangeFilter(t *testing.T) {
+	const testTenant = "test-tenant"
+	builder := newTestDataBuilder(t, testTenant)
+	defer builder.close()
+
+	now := time.Unix(0, int64(time.Hour)).UTC()
+	builder.addStream(`{app="foo"}`,
+		logproto.Entry{Timestamp: now, Line: "fast", StructuredMetadata: push.LabelsAdapter{{Name: "duration", Value: "150ms"}}},
+		logproto.Entry{Timestamp: now.Add(time.Second), Line: "slow", StructuredMetadata: push.LabelsAdapter{{Name: "duration", Value: "3s"}}},
+		logproto.Entry{Timestamp: now.Add(2 * time.Second), Line: "no duration"},
+	)
+	builder.addStream(`{app="bar", duration="5s"}`,
+		logproto.Entry{Timestamp: now.Add(3 * time.Second), Line: "shadowed", StructuredMetadata: push.LabelsAdapter{{Name: "duration", Value: "1ms"}}},
+	)
+	builder.flush()
+
+	meta := metastore.NewObjectMetastore(builder.bucket)
+	store := NewStore(builder.bucket, log.NewNopLogger(), meta)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	for _, tt := range []struct {
+		selector string
+		want     []string
+	}{
+		{selector: `{app="foo"} | duration > 2s`, want: []string{"slow"}},
+		{selector: `{app="foo"} | duration <= 1s`, want: []string{"fast"}},
+		// Label filters apply to stream labels rather than metadata of the
+		// same name.
+		{selector: `{app=~"foo|bar"} | duration > 2s`, want: []string{"slow", "shadowed"}},
+	} {
+		t.Run(tt.selector, func(t *testing.T) {
+			it, err := store.SelectLogs(ctx, logql.SelectLogParams{
+				QueryRequest: &logproto.QueryRequest{
+					Start:     now,
+					End:       now.Add(time.Hour),
+					Plan:      planFromString(tt.selector),
+					Selector:  tt.selector,
+					Limit:     100,
+					Direction: logproto.FORWARD,
+				},
+			})
+			require.NoError(t, err)
+			entries, err := readAllEntries(it)
+			require.NoError(t, err)
+
+			var lines []string
+			for _, entry := range entries {
+				lines = append(lines, entry.Entry.Line)
+			}
+			require.Equal(t, tt.want, lines)
+		})
+	}
+}
+
 func setupTestData(t *testing.T, builder *testDataBuilder) time.Time {
 	t.Helper()
 	now := time.Unix(0, int64(time.Hour)).UTC()
@@ -453,7 +546,7 @@ type testDataBuilder struct {
 	tenantID string
 	builder  *dataobj.Builder
 	meta     *metastore.Updater
-	uploader *uploader.Uploader
+	objects  int
 }
 
 func newTestDataBuilder(t *testing.T, tenantID string) *testDataBuilder {
@@ -478,9 +571,6 @@ func newTestDataBuilder(t *testing.T, tenantID string) *testDataBuilder {
 	meta := metastore.NewUpdater(bucket, tenantID, log.NewNopLogger())
 	require.NoError(t, meta.RegisterMetrics(prometheus.NewRegistry()))
 
-	uploader := uploader.New(uploader.Config{SHAPrefixSize: 2}, bucket, tenantID)
-	require.NoError(t, uploader.RegisterMetrics(prometheus.NewRegistry()))
-
 	return &testDataBuilder{
 		t:        t,
 		bucket:   bucket,
@@ -488,7 +578,6 @@ func newTestDataBuilder(t *testing.T, tenantID string) *testDataBuilder {
 		tenantID: tenantID,
 		builder:  builder,
 		meta:     meta,
-		uploader: uploader,
 	}
 }
 
@@ -505,9 +594,13 @@ func (b *testDataBuilder) flush() {
 	stats, err := b.builder.Flush(buf)
 	require.NoError(b.t, err)
 
-	// Upload the data object using the uploader
-	path, err := b.uploader.Upload(context.Background(), buf)
-	require.NoError(b.t, err)
+	// Data objects are listed by the metastore in the order of their paths.
+	// The uploader names objects by their checksum, so the objects are stored
+	// at paths in the order of their flushes instead, which keeps the
+	// sections assigned to shards independent of the encoding of objects.
+	path := fmt.Sprintf("tenant-%s/objects/%04d", b.tenantID, b.objects)
+	b.objects++
+	require.NoError(b.t, b.bucket.Upload(context.Background(), path, buf))
 
 	// Update metastore with the new data object
 	err = b.meta.Update(context.Background(), path, stats)
@@ -866,6 +959,8 @@ func TestBuildLogsPredicateFromPipeline(t *testing.T) {
 				require.NotNil(t, pred)
 				require.IsType(t, dataobj.LogMessageFilterPredicate{}, pred)
 
+				require.Equal(t, []string{"error"}, pred.(dataobj.LogMessageFilterPredicate).Contains)
+
 				// Verify the predicate works correctly
 				expected := []bool{true, true, false, false}
 				testPredicate(t, pred, testData, expected)
@@ -878,6 +973,7 @@ func TestBuildLogsPredicateFromPipeline(t *testing.T) {
 			testFunc: func(t *testing.T, pred dataobj.Predicate) {
 				require.NotNil(t, pred)
 				require.IsType(t, dataobj.LogMessageFilterPredicate{}, pred)
+				require.Empty(t, pred.(dataobj.LogMessageFilterPredicate).Contains)
 
 				// Verify the predicate works correctly
 				expected := []bool{false, false, true, true}
@@ -893,6 +989,7 @@ func TestBuildLogsPredicateFromPipeline(t *testing.T) {
 				// we might expect AND predicate here, but the original expression
 				// only contains a single Filterer stage with chained OR filters
 				require.IsType(t, dataobj.LogMessageFilterPredicate{}, pred)
+				require.ElementsMatch(t, []string{"error", "critical"}, pred.(dataobj.LogMessageFilterPredicate).Contains)
 
 				// The result should match logs containing both "error" and "critical"
 				expected := []bool{false, true, false, false}
@@ -940,6 +1037,37 @@ func TestBuildLogsPredicateFromPipeline(t *testing.T) {
 				testPredicate(t, pred, testData, expected)
 			},
 		},
+		{
+			name:         "numeric and duration label filters",
+			query:        mustParseLogSelector(t, `{app="foo"} | status >= 500 | duration > 2s`),
+			expectedExpr: mustParseLogSelector(t, `{app="foo"} | status >= 500 | duration > 2s`), // Label filters are kept
+			testFunc: func(t *testing.T, pred dataobj.Predicate) {
+				require.Equal(t, dataobj.AndPredicate[dataobj.LogsPredicate]{
+					Left: dataobj.MetadataRangePredicate[float64]{
+						Key: "status", Start: 500, End: math.Inf(1), IncludeStart: true, IncludeEnd: true,
+					},
+					Right: dataobj.MetadataRangePredicate[time.Duration]{
+						Key: "duration", Start: 2 * time.Second, End: math.MaxInt64, IncludeEnd: true,
+					},
+				}, pred)
+			},
+		},
+		{
+			name:         "label filters with or and not equal",
+			query:        mustParseLogSelector(t, `{app="foo"} | status > 500 or duration > 2s | status != 404`),
+			expectedExpr: mustParseLogSelector(t, `{app="foo"} | status > 500 or duration > 2s | status != 404`),
+			testFunc: func(t *testing.T, pred dataobj.Predicate) {
+				require.Nil(t, pred, "expected nil predicate for label filters without a single range")
+			},
+		},
+		{
+			name:         "label filter after parser",
+			query:        mustParseLogSelector(t, `{app="foo"} | logfmt | duration > 2s`),
+			expectedExpr: mustParseLogSelector(t, `{app="foo"} | logfmt | duration > 2s`),
+			testFunc: func(t *testing.T, pred dataobj.Predicate) {
+				require.Nil(t, pred, "expected nil predicate for label filter on parsed labels")
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			p, actualExpr := buildLogsPredicateFromPipeline(tt.query)
@@ -0,0 +1,122 @@
+package dataobj
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/filemd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/logsmd"
+)
+
+// LogsSectionStatistics holds the statistics of a single logs section of an
+// [Object], as recorded in the metadata of its columns.
+type LogsSectionStatistics struct {
+	Rows uint64 // Number of log records in the section.
+
+	MinTime time.Time // Timestamp of the oldest log record in the section.
+	MaxTime time.Time // Timestamp of the newest log record in the section.
+
+	MinStreamID int64 // Smallest stream ID in the section.
+	MaxStreamID int64 // Largest stream ID in the section.
+
+	// Metadata holds the statistics of the metadata columns of the section by
+	// metadata key. Keys without a column do not have values in the section.
+	Metadata map[string]ColumnStatistics
+}
+
+// ColumnStatistics holds the statistics of a single column of a section.
+type ColumnStatistics struct {
+	Values      uint64 // Number of non-NULL values in the column.
+	Cardinality uint64 // Estimated number of distinct values; 0 if unknown.
+
+	// Min and Max are the smallest and largest values of the column. They are
+	// only set if HasRange is true. Columns storing numbers, durations, or
+	// timestamps have no range, as their values don't sort as strings.
+	Min, Max string
+	HasRange bool
+}
+
+// LogsStatistics returns the statistics of each logs section of the Object,
+// in the order of the sections. Only the metadata of the sections is read.
+func (o *Object) LogsStatistics(ctx context.Context) ([]LogsSectionStatistics, error) {
+	si, err := o.dec.Sections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading sections: %w", err)
+	}
+
+	dec := o.dec.LogsDecoder()
+
+	var res []LogsSectionStatistics
+	for _, s := range si {
+		if s.Type != filemd.SECTION_TYPE_LOGS {
+			continue
+		}
+
+		columns, err := dec.Columns(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("reading columns: %w", err)
+		}
+		stats, err := logsSectionStatistics(columns)
+		if err != nil {
+			return nil, fmt.Errorf("reading statistics of logs section %d: %w", len(res), err)
+		}
+		res = append(res, stats)
+	}
+	return res, nil
+}
+
+func logsSectionStatistics(columns []*logsmd.ColumnDesc) (LogsSectionStatistics, error) {
+	res := LogsSectionStatistics{Metadata: make(map[string]ColumnStatistics)}
+
+	for _, column := range columns {
+		info := column.Info
+		res.Rows = max(res.Rows, info.RowsCount)
+
+		minValue, maxValue, hasRange, err := columnRange(info.Statistics)
+		if err != nil {
+			return res, err
+		}
+
+		switch column.Type {
+		case logsmd.COLUMN_TYPE_TIMESTAMP:
+			if hasRange {
+				res.MinTime = time.Unix(0, minValue.Int64()).UTC()
+				res.MaxTime = time.Unix(0, maxValue.Int64()).UTC()
+			}
+		case logsmd.COLUMN_TYPE_STREAM_ID:
+			if hasRange {
+				res.MinStreamID = minValue.Int64()
+				res.MaxStreamID = maxValue.Int64()
+			}
+		case logsmd.COLUMN_TYPE_METADATA:
+			stats := ColumnStatistics{Values: info.ValuesCount}
+			if info.Statistics != nil {
+				stats.Cardinality = info.Statistics.CardinalityCount
+			}
+			if hasRange && minValue.Type() == datasetmd.VALUE_TYPE_BYTE_ARRAY && maxValue.Type() == datasetmd.VALUE_TYPE_BYTE_ARRAY {
+				stats.Min, stats.Max = string(minValue.ByteArray()), string(maxValue.ByteArray())
+				stats.HasRange = true
+			}
+			res.Metadata[info.Name] = stats
+		}
+	}
+	return res, nil
+}
+
+// columnRange returns the range of values of a column. It returns false if
+// the column does not have range statistics.
+func columnRange(stats *datasetmd.Statistics) (minValue, maxValue dataset.Value, ok bool, err error) {
+	if stats == nil || len(stats.MinValue) == 0 || len(stats.MaxValue) == 0 {
+		return minValue, maxValue, false, nil
+	}
+	if err := minValue.UnmarshalBinary(stats.MinValue); err != nil {
+		return minValue, maxValue, false, fmt.Errorf("unmarshalling min value: %w", err)
+	}
+	if err := maxValue.UnmarshalBinary(stats.MaxValue); err != nil {
+		return minValue, maxValue, false, fmt.Errorf("unmarshalling max value: %w", err)
+	}
+	return minValue, maxValue, !minValue.IsNil() && !maxValue.IsNil(), nil
+}
@@ -0,0 +1,42 @@
+package dataobj_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/sections/logs"
+)
+
+func TestObject_LogsStatistics(t *testing.T) {
+	obj := buildLogsObject(t, logs.Options{
+		PageSizeHint:     1,
+		BufferSize:       1,
+		SectionSize:      1024,
+		StripeMergeLimit: 2,
+	})
+
+	stats, err := obj.LogsStatistics(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+
+	section := stats[0]
+	require.Equal(t, uint64(6), section.Rows)
+	require.Equal(t, unixTime(5).UTC(), section.MinTime)
+	require.Equal(t, unixTime(30).UTC(), section.MaxTime)
+	require.Equal(t, int64(1), section.MinStreamID)
+	require.Equal(t, int64(3), section.MaxStreamID)
+
+	// Both metadata columns only hold numbers, which have no string range.
+	require.Len(t, section.Metadata, 2)
+	require.Equal(t, dataobj.ColumnStatistics{
+		Values:      2,
+		Cardinality: 1,
+	}, section.Metadata["trace_id"])
+	require.Equal(t, dataobj.ColumnStatistics{
+		Values:      2,
+		Cardinality: 2,
+	}, section.Metadata["user"])
+}
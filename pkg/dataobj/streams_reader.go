@@ -32,6 +32,9 @@ type Stream struct {
 	// UncompressedSize is the total size of all the log lines and structured metadata values in the stream
 	UncompressedSize int64
 
+	// Rows is the number of log records of the stream.
+	Rows int
+
 	// Labels of the stream.
 	Labels labels.Labels
 }
@@ -115,6 +118,7 @@ func (r *StreamsReader) Read(ctx context.Context, s []Stream) (int, error) {
 		s[i].MinTime = r.stream.MinTimestamp
 		s[i].MaxTime = r.stream.MaxTimestamp
 		s[i].UncompressedSize = r.stream.UncompressedSize
+		s[i].Rows = r.stream.Rows
 		s[i].Labels = slicegrow.GrowToCap(s[i].Labels, len(r.stream.Labels))
 		s[i].Labels = s[i].Labels[:len(r.stream.Labels)]
 		for j := range r.stream.Labels {
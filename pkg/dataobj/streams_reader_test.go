@@ -32,9 +32,9 @@ var streamsTestdata = []struct {
 
 func TestStreamsReader(t *testing.T) {
 	expect := []dataobj.Stream{
-		{1, unixTime(10), unixTime(15), 25, labels.FromStrings("cluster", "test", "app", "foo")},
-		{2, unixTime(5), unixTime(20), 45, labels.FromStrings("cluster", "test", "app", "bar")},
-		{3, unixTime(25), unixTime(30), 35, labels.FromStrings("cluster", "test", "app", "baz")},
+		{1, unixTime(10), unixTime(15), 25, 2, labels.FromStrings("cluster", "test", "app", "foo")},
+		{2, unixTime(5), unixTime(20), 45, 2, labels.FromStrings("cluster", "test", "app", "bar")},
+		{3, unixTime(25), unixTime(30), 35, 2, labels.FromStrings("cluster", "test", "app", "baz")},
 	}
 
 	obj := buildStreamsObject(t, 1) // Many pages
@@ -50,7 +50,7 @@ func TestStreamsReader(t *testing.T) {
 
 func TestStreamsReader_AddLabelMatcher(t *testing.T) {
 	expect := []dataobj.Stream{
-		{2, unixTime(5), unixTime(20), 45, labels.FromStrings("cluster", "test", "app", "bar")},
+		{2, unixTime(5), unixTime(20), 45, 2, labels.FromStrings("cluster", "test", "app", "bar")},
 	}
 
 	obj := buildStreamsObject(t, 1) // Many pages
@@ -68,8 +68,8 @@ func TestStreamsReader_AddLabelMatcher(t *testing.T) {
 
 func TestStreamsReader_AddLabelFilter(t *testing.T) {
 	expect := []dataobj.Stream{
-		{2, unixTime(5), unixTime(20), 45, labels.FromStrings("cluster", "test", "app", "bar")},
-		{3, unixTime(25), unixTime(30), 35, labels.FromStrings("cluster", "test", "app", "baz")},
+		{2, unixTime(5), unixTime(20), 45, 2, labels.FromStrings("cluster", "test", "app", "bar")},
+		{3, unixTime(25), unixTime(30), 35, 2, labels.FromStrings("cluster", "test", "app", "baz")},
 	}
 
 	obj := buildStreamsObject(t, 1) // Many pages
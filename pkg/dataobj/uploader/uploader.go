@@ -57,8 +57,10 @@ func (d *Uploader) UnregisterMetrics(reg prometheus.Registerer) {
 	d.metrics.unregister(reg)
 }
 
-// getKey determines the key in object storage to upload the object to, based on our path scheme.
-func (d *Uploader) getKey(object *bytes.Buffer) string {
+// ObjectPath determines the key in object storage to upload the object to,
+// based on our path scheme. Keys are derived from the contents of object, so
+// uploading the same object again always uses the same key.
+func (d *Uploader) ObjectPath(object *bytes.Buffer) string {
 	sum := sha256.Sum224(object.Bytes())
 	sumStr := hex.EncodeToString(sum[:])
 
@@ -70,7 +72,7 @@ func (d *Uploader) Upload(ctx context.Context, object *bytes.Buffer) (string, er
 	timer := prometheus.NewTimer(d.metrics.uploadTime)
 	defer timer.ObserveDuration()
 
-	objectPath := d.getKey(object)
+	objectPath := d.ObjectPath(object)
 
 	backoff := backoff.New(ctx, backoff.Config{
 		MinBackoff: 100 * time.Millisecond,
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/user"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/executor"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	utillog "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// FragmentPath is the path of the endpoint of queriers that executes the
+// fragments of physical plans. Requests are sent to it by the query engine
+// if distributed execution is enabled.
+const FragmentPath = "/loki/api/v2/fragment"
+
+// maxInflightFragments is the maximum number of fragments of a single query
+// that are executed by queriers at the same time.
+const maxInflightFragments = 32
+
+// maxFragmentResponseSize is the maximum size of the encoded rows of a
+// fragment. Responses are sent as a single httpgrpc message, so they are
+// held in memory by the querier and the engine, and fragments returning
+// more rows fail.
+const maxFragmentResponseSize = 100 << 20
+
+var errFragmentResponseTooLarge = fmt.Errorf("fragment response exceeds %d bytes", maxFragmentResponseSize)
+
+// RoundTripper sends HTTP requests wrapped in protobuf messages to queriers,
+// e.g. through the query-scheduler. It is implemented by the query-frontend.
+type RoundTripper interface {
+	RoundTripGRPC(context.Context, *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, error)
+}
+
+// EnableDistributedExecution enables the distributed execution of queries.
+// The physical plan of each query is split into fragments, which are sent
+// to queriers using rt, while the nodes merging the results of the
+// fragments are executed by the engine itself. The queriers execute the
+// fragments with the handler returned by [QueryEngine.FragmentHandler].
+func (e *QueryEngine) EnableDistributedExecution(rt RoundTripper) {
+	e.fragments = &remoteFragmentRunner{
+		rt:       rt,
+		inflight: make(chan struct{}, maxInflightFragments),
+	}
+}
+
+// FragmentHandler returns a [http.Handler] that executes the plan fragments
+// sent to [FragmentPath] by queriers and query-frontends with distributed
+// execution enabled. The rows of a fragment are returned in the encoding of
+// [executor.WriteBatches], up to [maxFragmentResponseSize] bytes.
+//
+// Fragments are only executed if all data objects they read belong to the
+// tenant of the request.
+func (e *QueryEngine) FragmentHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID, ctx, err := user.ExtractOrgIDFromHTTPRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		logger := utillog.WithContext(ctx, e.logger)
+
+		var fragment physical.Fragment
+		if err := json.NewDecoder(r.Body).Decode(&fragment); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode fragment: %s", err), http.StatusBadRequest)
+			return
+		}
+		if err := checkFragmentTenant(&fragment, orgID); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		cfg := executor.Config{
+			BatchSize: batchSize,
+			Bucket:    e.bucket,
+		}
+		pipeline := executor.Run(ctx, cfg, fragment.Plan)
+		defer pipeline.Close()
+
+		rw := &fragmentResponseWriter{w: w}
+		if err := executor.WriteBatches(rw, pipeline); err != nil {
+			level.Error(logger).Log("msg", "failed to execute fragment", "fragment", fragment.ID, "err", err)
+			// Once batches have been written the status cannot be changed
+			// anymore, but the stream is not terminated, which fails the
+			// reader of the fragment.
+			if !rw.written {
+				http.Error(w, fmt.Sprintf("failed to execute fragment: %s", err), http.StatusInternalServerError)
+			}
+		}
+	})
+}
+
+// checkFragmentTenant returns an error if the fragment reads data objects
+// outside of the objects of tenant. Data objects are stored under the prefix
+// of their tenant by the data object consumer.
+func checkFragmentTenant(fragment *physical.Fragment, tenant string) error {
+	prefix := fmt.Sprintf("tenant-%s/", tenant)
+	for _, location := range fragment.Locations() {
+		if !strings.HasPrefix(string(location), prefix) {
+			return fmt.Errorf("fragment %d reads data object %s of another tenant", fragment.ID, location)
+		}
+	}
+	return nil
+}
+
+// fragmentResponseWriter writes the batches of a fragment to the response.
+// The headers of the response are sent with the first batch.
+type fragmentResponseWriter struct {
+	w       http.ResponseWriter
+	written bool
+	size    int
+}
+
+func (w *fragmentResponseWriter) Write(p []byte) (int, error) {
+	w.size += len(p)
+	if w.size > maxFragmentResponseSize {
+		return 0, errFragmentResponseTooLarge
+	}
+	if !w.written {
+		w.w.Header().Set("Content-Type", "application/octet-stream")
+		w.w.WriteHeader(http.StatusOK)
+		w.written = true
+	}
+	return w.w.Write(p)
+}
+
+// remoteFragmentRunner is an [executor.FragmentRunner] that executes
+// fragments on queriers.
+type remoteFragmentRunner struct {
+	rt RoundTripper
+	// inflight limits the number of fragments executed at the same time.
+	inflight chan struct{}
+}
+
+// RunFragment implements [executor.FragmentRunner]. The fragment is sent
+// immediately, so that all fragments of a plan are executed concurrently,
+// and the returned pipeline waits for its response.
+func (r *remoteFragmentRunner) RunFragment(ctx context.Context, fragment *physical.Fragment) executor.Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &remotePipeline{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+
+		select {
+		case r.inflight <- struct{}{}:
+			defer func() { <-r.inflight }()
+		case <-ctx.Done():
+			p.err = ctx.Err()
+			return
+		}
+		p.body, p.err = r.roundTrip(ctx, fragment)
+	}()
+	return p
+}
+
+func (r *remoteFragmentRunner) roundTrip(ctx context.Context, fragment *physical.Fragment) ([]byte, error) {
+	body, err := json.Marshal(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fragment %d: %w", fragment.ID, err)
+	}
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &httpgrpc.HTTPRequest{
+		Method: http.MethodPost,
+		Url:    FragmentPath,
+		Body:   body,
+		Headers: []*httpgrpc.Header{
+			{Key: "Content-Type", Values: []string{"application/json"}},
+			{Key: user.OrgIDHeaderName, Values: []string{orgID}},
+		},
+	}
+	resp, err := r.rt.RoundTripGRPC(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute fragment %d: %w", fragment.ID, err)
+	}
+	if resp.Code/100 != 2 {
+		return nil, fmt.Errorf("failed to execute fragment %d: %w", fragment.ID, httpgrpc.ErrorFromHTTPResponse(resp))
+	}
+	if len(resp.Body) > maxFragmentResponseSize {
+		return nil, fmt.Errorf("failed to execute fragment %d: %w", fragment.ID, errFragmentResponseTooLarge)
+	}
+	return resp.Body, nil
+}
+
+// remotePipeline is an [executor.Pipeline] that returns the rows of a
+// fragment executed by a querier.
+type remotePipeline struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// body and err are set once done is closed. The body is the complete
+	// response of the querier, as [RoundTripper] doesn't stream responses;
+	// its batches are decoded as they are read.
+	body []byte
+	err  error
+
+	rows executor.Pipeline
+}
+
+// Read implements [executor.Pipeline].
+func (p *remotePipeline) Read() error {
+	<-p.done
+	if p.err != nil {
+		return p.err
+	}
+	if p.rows == nil {
+		p.rows = executor.NewDecodePipeline(io.NopCloser(bytes.NewReader(p.body)))
+	}
+	return p.rows.Read()
+}
+
+// Value implements [executor.Pipeline].
+func (p *remotePipeline) Value() (*columnar.RecordBatch, error) {
+	if p.rows == nil {
+		return nil, p.err
+	}
+	return p.rows.Value()
+}
+
+// Close implements [executor.Pipeline].
+func (p *remotePipeline) Close() {
+	p.cancel()
+	if p.rows != nil {
+		p.rows.Close()
+	}
+}
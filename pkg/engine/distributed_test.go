@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/httpgrpc/server"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+)
+
+// roundTripperFunc is a [RoundTripper] that sends requests to queriers that
+// are emulated by the function.
+type roundTripperFunc func(context.Context, *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, error)
+
+func (f roundTripperFunc) RoundTripGRPC(ctx context.Context, req *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, error) {
+	return f(ctx, req)
+}
+
+// querierRoundTripper returns a [RoundTripper] that executes fragments with
+// the handler of the querier engine, the same as a querier worker, and
+// counts the executed fragments.
+func querierRoundTripper(t *testing.T, querier *QueryEngine, requests *atomic.Int64) RoundTripper {
+	srv := server.NewServer(querier.FragmentHandler())
+	return roundTripperFunc(func(_ context.Context, req *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, error) {
+		requests.Inc()
+		require.Equal(t, FragmentPath, req.Url)
+		// Queriers do not share the context of the query-frontend, so the
+		// request is handled in a new context.
+		return srv.Handle(context.Background(), req)
+	})
+}
+
+func TestQueryEngine_Execute_Distributed(t *testing.T) {
+	now := time.Unix(0, 0).UTC().Add(time.Hour)
+	entry := func(offset time.Duration, line string) logproto.Entry {
+		return logproto.Entry{Timestamp: now.Add(offset), Line: line}
+	}
+
+	var objects [][]logproto.Stream
+	for _, app := range []string{"foo", "bar", "baz"} {
+		objects = append(objects, []logproto.Stream{
+			{
+				Labels: `{app="` + app + `", env="prod"}`,
+				Entries: []logproto.Entry{
+					entry(1*time.Second, app+"1 level=info"),
+					entry(2*time.Second, app+"2 level=error"),
+				},
+			},
+		})
+	}
+	bucket := buildTestObjects(t, objects...)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	var requests atomic.Int64
+	local := newTestEngine(bucket)
+	distributed := newTestEngine(bucket)
+	distributed.EnableDistributedExecution(querierRoundTripper(t, newTestEngine(bucket), &requests))
+
+	for _, tt := range []struct {
+		name  string
+		query string
+		start time.Time
+		step  time.Duration
+	}{
+		{
+			name:  "log query",
+			query: `{env="prod"} | logfmt | level="error"`,
+			start: now,
+		},
+		{
+			name:  "metric query",
+			query: `sum by (app) (count_over_time({env="prod"} |= "level=" [10s]))`,
+			start: now.Add(10 * time.Second),
+			step:  10 * time.Second,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+
+			params, err := logql.NewLiteralParams(tt.query, tt.start, now.Add(time.Minute), tt.step, 0, logproto.BACKWARD, 100, nil, nil)
+			require.NoError(t, err)
+
+			expected, err := local.Execute(ctx, params)
+			require.NoError(t, err)
+			actual, err := distributed.Execute(ctx, params)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.Data, actual.Data)
+			// Every data object is scanned by a separate fragment.
+			require.Equal(t, int64(len(objects)), requests.Load())
+		})
+	}
+}
+
+func TestQueryEngine_Execute_DistributedError(t *testing.T) {
+	bucket := buildTestObjects(t, []logproto.Stream{
+		{
+			Labels:  `{app="foo"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0), Line: "foo"}},
+		},
+	})
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	engine := newTestEngine(bucket)
+	engine.EnableDistributedExecution(roundTripperFunc(func(context.Context, *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, error) {
+		return &httpgrpc.HTTPResponse{Code: http.StatusInternalServerError, Body: []byte("querier unavailable")}, nil
+	}))
+
+	params, err := logql.NewLiteralParams(`{app="foo"}`, time.Unix(0, 0), time.Unix(60, 0), 0, 0, logproto.FORWARD, 100, nil, nil)
+	require.NoError(t, err)
+
+	_, err = engine.Execute(ctx, params)
+	require.ErrorContains(t, err, "querier unavailable")
+}
+
+func TestQueryEngine_FragmentHandler_InvalidFragment(t *testing.T) {
+	srv := server.NewServer(newTestEngine(buildTestObjects(t)).FragmentHandler())
+
+	resp, err := srv.Handle(context.Background(), &httpgrpc.HTTPRequest{
+		Method: http.MethodPost,
+		Url:    FragmentPath,
+		Body:   []byte(`{"id": 1, "nodes": []}`),
+		Headers: []*httpgrpc.Header{
+			{Key: user.OrgIDHeaderName, Values: []string{testTenant}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	require.Contains(t, string(resp.Body), "fragment 1 has no nodes")
+}
+
+func TestQueryEngine_FragmentHandler_Tenant(t *testing.T) {
+	srv := server.NewServer(newTestEngine(buildTestObjects(t)).FragmentHandler())
+	body := []byte(`{"id": 1, "nodes": [{"type": 0, "location": "tenant-other/objects/00/11"}]}`)
+
+	for _, tt := range []struct {
+		name    string
+		headers []*httpgrpc.Header
+		code    int
+		msg     string
+	}{
+		{
+			name: "missing tenant",
+			code: http.StatusUnauthorized,
+			msg:  "no org id",
+		},
+		{
+			name:    "data object of another tenant",
+			headers: []*httpgrpc.Header{{Key: user.OrgIDHeaderName, Values: []string{testTenant}}},
+			code:    http.StatusForbidden,
+			msg:     "fragment 1 reads data object tenant-other/objects/00/11 of another tenant",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.Handle(context.Background(), &httpgrpc.HTTPRequest{
+				Method:  http.MethodPost,
+				Url:     FragmentPath,
+				Body:    body,
+				Headers: tt.headers,
+			})
+			require.NoError(t, err)
+			require.Equal(t, int32(tt.code), resp.Code)
+			require.Contains(t, string(resp.Body), tt.msg)
+		})
+	}
+}
@@ -2,16 +2,21 @@ package engine
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/thanos-io/objstore"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/engine/executor"
 	"github.com/grafana/loki/v3/pkg/engine/planner/logical"
 	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 	utillog "github.com/grafana/loki/v3/pkg/util/log"
 )
 
@@ -19,12 +24,18 @@ var (
 	ErrNotSupported = errors.New("feature not supported in new query engine")
 )
 
+// batchSize is the maximum number of rows of a single batch passed between
+// the pipelines of the executor.
+const batchSize = 100
+
 // New creates a new instance of the query engine that implements the [logql.Engine] interface.
-func New(opts logql.EngineOpts, metastore metastore.Metastore, limits logql.Limits, logger log.Logger) *QueryEngine {
+// The bucket is used by the executor to read the data objects resolved via the metastore.
+func New(opts logql.EngineOpts, metastore metastore.Metastore, bucket objstore.Bucket, limits logql.Limits, logger log.Logger) *QueryEngine {
 	return &QueryEngine{
 		logger:    logger,
 		limits:    limits,
 		metastore: metastore,
+		bucket:    bucket,
 		opts:      opts,
 	}
 }
@@ -34,7 +45,12 @@ type QueryEngine struct {
 	logger    log.Logger
 	limits    logql.Limits
 	metastore metastore.Metastore
+	bucket    objstore.Bucket
 	opts      logql.EngineOpts
+
+	// fragments executes the fragments of plans if distributed execution is
+	// enabled, see [QueryEngine.EnableDistributedExecution].
+	fragments executor.FragmentRunner
 }
 
 // Query implements [logql.Engine].
@@ -50,37 +66,120 @@ func (e *QueryEngine) Query(params logql.Params) logql.Query {
 //  1. Create a logical plan from the provided query parameters.
 //  2. Create a physical plan from the logical plan using information from the catalog.
 //  3. Evaluate the physical plan with the executor.
+//
+// If distributed execution is enabled, the physical plan is split into
+// fragments before it is evaluated, see [physical.SplitFragments].
 func (e *QueryEngine) Execute(ctx context.Context, params logql.Params) (logqlmodel.Result, error) {
 	var result logqlmodel.Result
+	start := time.Now()
 	logger := utillog.WithContext(ctx, e.logger)
 	logger = log.With(logger, "query", params.QueryString(), "engine", "v2")
 
+	_, plan, fragments, err := e.buildPlans(ctx, params, logger)
+	if err != nil {
+		return result, err
+	}
+	if e.fragments != nil {
+		logger = log.With(logger, "fragments", len(fragments))
+	}
+
+	level.Info(logger).Log("msg", "execute query with new engine", "query", params.QueryString())
+
+	statsCtx, ctx := stats.NewContext(ctx)
+	cfg := executor.Config{
+		BatchSize: batchSize,
+		Bucket:    e.bucket,
+		Fragments: e.fragments,
+	}
+	pipeline := executor.Run(ctx, cfg, plan)
+	defer pipeline.Close()
+
+	var builder resultBuilder = newStreamsResultBuilder()
+	if _, ok := params.GetExpression().(syntax.SampleExpr); ok {
+		builder = newSamplesResultBuilder(logql.GetRangeType(params) == logql.InstantType)
+	}
+	for {
+		if err := pipeline.Read(); err != nil {
+			if errors.Is(err, executor.EOF) {
+				break
+			}
+			level.Error(logger).Log("msg", "failed to execute physical plan", "err", err)
+			return result, errors.Wrap(err, "failed to execute physical plan")
+		}
+		batch, err := pipeline.Value()
+		if err != nil {
+			return result, errors.Wrap(err, "failed to read batch")
+		}
+		if err := builder.collectBatch(batch); err != nil {
+			return result, errors.Wrap(err, "failed to collect batch")
+		}
+	}
+
+	result.Data = builder.Build()
+	result.Statistics = statsCtx.Result(time.Since(start), 0, builder.Len())
+	return result, nil
+}
+
+// buildPlans creates the logical plan and the optimized physical plan of the
+// query. If distributed execution is enabled, the physical plan is split into
+// fragments, which are returned as well. It returns [ErrNotSupported] if the
+// query cannot be planned.
+func (e *QueryEngine) buildPlans(ctx context.Context, params logql.Params, logger log.Logger) (*logical.Plan, *physical.Plan, []*physical.Fragment, error) {
 	logicalPlan, err := logical.BuildPlan(params)
 	if err != nil {
 		level.Warn(logger).Log("msg", "failed to create logical plan", "err", err)
-		return result, ErrNotSupported
+		return nil, nil, nil, ErrNotSupported
 	}
 
-	executionContext := physical.NewContext(ctx, e.metastore, params.Start(), params.End())
+	from, through := queryTimeRange(params)
+	executionContext := physical.NewContext(ctx, e.metastore, e.bucket, from, through)
 	planner := physical.NewPlanner(executionContext)
 	plan, err := planner.Build(logicalPlan)
 	if err != nil {
 		level.Warn(logger).Log("msg", "failed to create physical plan", "err", err)
-		return result, ErrNotSupported
+		return nil, nil, nil, ErrNotSupported
 	}
-	_, err = planner.Optimize(plan)
+	plan, err = planner.Optimize(plan)
 	if err != nil {
 		level.Warn(logger).Log("msg", "failed to optimize physical plan", "err", err)
-		return result, ErrNotSupported
+		return nil, nil, nil, ErrNotSupported
 	}
 
-	// TODO(chaudum): Replace the return values with the actual return values from the execution.
-	level.Info(logger).Log("msg", "execute query with new engine", "query", params.QueryString())
-	return result, ErrNotSupported
+	var fragments []*physical.Fragment
+	if e.fragments != nil {
+		fragments, err = physical.SplitFragments(plan)
+		if err != nil {
+			level.Warn(logger).Log("msg", "failed to split physical plan into fragments", "err", err)
+			return nil, nil, nil, ErrNotSupported
+		}
+	}
+	return logicalPlan, plan, fragments, nil
 }
 
 var _ logql.Engine = (*QueryEngine)(nil)
 
+// queryTimeRange returns the time range of data that needs to be read to
+// evaluate the query. The range of metric queries is extended into the past
+// by the largest range interval of the query, so that the first step has a
+// complete range of data.
+func queryTimeRange(params logql.Params) (from, through time.Time) {
+	from, through = params.Start(), params.End()
+
+	expr, ok := params.GetExpression().(syntax.SampleExpr)
+	if !ok {
+		return from, through
+	}
+
+	var interval time.Duration
+	expr.Walk(func(e syntax.Expr) bool {
+		if e, ok := e.(*syntax.LogRangeExpr); ok {
+			interval = max(interval, e.Interval)
+		}
+		return true
+	})
+	return from.Add(-interval), through
+}
+
 // queryAdapter dispatches query execution to the wrapped engine.
 type queryAdapter struct {
 	params logql.Params
@@ -1 +1,397 @@
 package engine
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/objstore/providers/filesystem"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+
+	"github.com/grafana/loki/pkg/push"
+)
+
+const testTenant = "test-tenant"
+
+// buildTestObjects writes each list of streams into a separate data object
+// and registers the objects in the metastore of the returned bucket.
+func buildTestObjects(t *testing.T, objects ...[]logproto.Stream) objstore.Bucket {
+	t.Helper()
+
+	dir := t.TempDir()
+	bucket, err := filesystem.NewBucket(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = bucket.Close() })
+
+	// The filesystem bucket requires the metastore directory to exist.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tenant-"+testTenant, "metastore"), 0o755))
+
+	builder, err := dataobj.NewBuilder(dataobj.BuilderConfig{
+		TargetPageSize:          1024 * 1024,
+		TargetObjectSize:        10 * 1024 * 1024,
+		TargetSectionSize:       1024 * 1024,
+		BufferSize:              1024 * 1024,
+		SectionStripeMergeLimit: 2,
+	})
+	require.NoError(t, err)
+
+	updater := metastore.NewUpdater(bucket, testTenant, log.NewNopLogger())
+	require.NoError(t, updater.RegisterMetrics(prometheus.NewRegistry()))
+
+	up := uploader.New(uploader.Config{SHAPrefixSize: 2}, bucket, testTenant)
+	require.NoError(t, up.RegisterMetrics(prometheus.NewRegistry()))
+
+	for _, streams := range objects {
+		for _, stream := range streams {
+			require.NoError(t, builder.Append(stream))
+		}
+
+		buf := bytes.NewBuffer(nil)
+		stats, err := builder.Flush(buf)
+		require.NoError(t, err)
+
+		path, err := up.Upload(context.Background(), buf)
+		require.NoError(t, err)
+		require.NoError(t, updater.Update(context.Background(), path, stats))
+
+		builder.Reset()
+	}
+
+	return bucket
+}
+
+func newTestEngine(bucket objstore.Bucket) *QueryEngine {
+	return New(logql.EngineOpts{}, metastore.NewObjectMetastore(bucket), bucket, nil, log.NewNopLogger())
+}
+
+func TestQueryEngine_Execute_LogQuery(t *testing.T) {
+	now := time.Unix(0, 0).UTC().Add(time.Hour)
+	entry := func(offset time.Duration, line string, md ...push.LabelAdapter) logproto.Entry {
+		return logproto.Entry{Timestamp: now.Add(offset), Line: line, StructuredMetadata: md}
+	}
+
+	bucket := buildTestObjects(t,
+		[]logproto.Stream{
+			{
+				Labels: `{app="foo", env="prod"}`,
+				Entries: []logproto.Entry{
+					entry(1*time.Second, "foo1 level=info"),
+					entry(3*time.Second, "foo2 level=error", push.LabelAdapter{Name: "trace_id", Value: "abc"}),
+					entry(5*time.Second, "foo3 level=info"),
+				},
+			},
+			{
+				Labels: `{app="bar", env="prod"}`,
+				Entries: []logproto.Entry{
+					entry(2*time.Second, "bar1 level=error"),
+					entry(4*time.Second, "bar2 level=info"),
+				},
+			},
+		},
+		[]logproto.Stream{
+			{
+				Labels: `{app="foo", env="dev"}`,
+				Entries: []logproto.Entry{
+					entry(6*time.Second, "foo4 level=error"),
+				},
+			},
+		},
+	)
+	engine := newTestEngine(bucket)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	for _, tt := range []struct {
+		name      string
+		query     string
+		direction logproto.Direction
+		limit     uint32
+		expected  map[string][]string
+	}{
+		{
+			name:      "stream selector forward",
+			query:     `{app="foo"}`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{app="foo", env="prod"}`:                 {"foo1 level=info", "foo3 level=info"},
+				`{app="foo", env="prod", trace_id="abc"}`: {"foo2 level=error"},
+				`{app="foo", env="dev"}`:                  {"foo4 level=error"},
+			},
+		},
+		{
+			name:      "line filter with limit backwards",
+			query:     `{env="prod"} |= "level=error"`,
+			direction: logproto.BACKWARD,
+			limit:     1,
+			expected: map[string][]string{
+				`{app="foo", env="prod", trace_id="abc"}`: {"foo2 level=error"},
+			},
+		},
+		{
+			name:      "limit across objects",
+			query:     `{app=~"foo|bar"}`,
+			direction: logproto.BACKWARD,
+			limit:     2,
+			expected: map[string][]string{
+				`{app="foo", env="prod"}`: {"foo3 level=info"},
+				`{app="foo", env="dev"}`:  {"foo4 level=error"},
+			},
+		},
+		{
+			name:      "label filter on metadata",
+			query:     `{app="foo"} | trace_id="abc"`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{app="foo", env="prod", trace_id="abc"}`: {"foo2 level=error"},
+			},
+		},
+		{
+			name:      "parser with label filter",
+			query:     `{env="prod"} | logfmt | level="error"`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{app="foo", env="prod", level="error", trace_id="abc"}`: {"foo2 level=error"},
+				`{app="bar", env="prod", level="error"}`:                 {"bar1 level=error"},
+			},
+		},
+		{
+			name:      "parser with limit",
+			query:     `{app=~"foo|bar"} | logfmt | level="info"`,
+			direction: logproto.BACKWARD,
+			limit:     2,
+			expected: map[string][]string{
+				`{app="foo", env="prod", level="info"}`: {"foo3 level=info"},
+				`{app="bar", env="prod", level="info"}`: {"bar2 level=info"},
+			},
+		},
+		{
+			name:      "line_format",
+			query:     `{app="bar"} | logfmt | line_format "{{.level}}: {{.app}}"`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{app="bar", env="prod", level="error"}`: {"error: bar"},
+				`{app="bar", env="prod", level="info"}`:  {"info: bar"},
+			},
+		},
+		{
+			name:      "line filter after line_format",
+			query:     `{app="bar"} | line_format "{{.env}}" |= "prod"`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{app="bar", env="prod"}`: {"prod", "prod"},
+			},
+		},
+		{
+			name:      "label_format",
+			query:     `{app="foo", env="prod"} | label_format service=app, env="{{.env | upper}}"`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{env="PROD", service="foo"}`:                 {"foo1 level=info", "foo3 level=info"},
+				`{env="PROD", service="foo", trace_id="abc"}`: {"foo2 level=error"},
+			},
+		},
+		{
+			name:      "keep",
+			query:     `{app="foo"} | keep app, trace_id`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{app="foo"}`:                 {"foo1 level=info", "foo3 level=info", "foo4 level=error"},
+				`{app="foo", trace_id="abc"}`: {"foo2 level=error"},
+			},
+		},
+		{
+			name:      "keep with label filter",
+			query:     `{app="foo"} | env="prod" | keep app`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{app="foo"}`: {"foo1 level=info", "foo2 level=error", "foo3 level=info"},
+			},
+		},
+		{
+			name:      "drop",
+			query:     `{env="prod"} | drop app, trace_id`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected: map[string][]string{
+				`{env="prod"}`: {"foo1 level=info", "bar1 level=error", "foo2 level=error", "bar2 level=info", "foo3 level=info"},
+			},
+		},
+		{
+			name:      "no matching streams",
+			query:     `{app="baz"}`,
+			direction: logproto.FORWARD,
+			limit:     100,
+			expected:  map[string][]string{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := logql.NewLiteralParams(tt.query, now, now.Add(time.Minute), 0, 0, tt.direction, tt.limit, nil, nil)
+			require.NoError(t, err)
+
+			result, err := engine.Execute(ctx, params)
+			require.NoError(t, err)
+
+			streams, ok := result.Data.(logqlmodel.Streams)
+			require.True(t, ok, "expected streams result, got %T", result.Data)
+
+			actual := make(map[string][]string, len(streams))
+			for _, stream := range streams {
+				for _, e := range stream.Entries {
+					actual[stream.Labels] = append(actual[stream.Labels], e.Line)
+				}
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestQueryEngine_Execute_MetricQuery(t *testing.T) {
+	now := time.Unix(0, 0).UTC().Add(time.Hour)
+	entry := func(offset time.Duration, line string) logproto.Entry {
+		return logproto.Entry{Timestamp: now.Add(offset), Line: line}
+	}
+
+	bucket := buildTestObjects(t,
+		[]logproto.Stream{
+			{
+				Labels: `{app="foo", env="prod"}`,
+				Entries: []logproto.Entry{
+					entry(5*time.Second, "foo1 level=info"),
+					entry(15*time.Second, "foo2 level=error"),
+					entry(25*time.Second, "foo3 level=info"),
+				},
+			},
+			{
+				Labels: `{app="bar", env="prod"}`,
+				Entries: []logproto.Entry{
+					entry(12*time.Second, "bar1 level=error"),
+				},
+			},
+		},
+		[]logproto.Stream{
+			{
+				Labels: `{app="foo", env="dev"}`,
+				Entries: []logproto.Entry{
+					entry(18*time.Second, "foo4 level=error"),
+				},
+			},
+		},
+	)
+	engine := newTestEngine(bucket)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	t.Run("range query", func(t *testing.T) {
+		params, err := logql.NewLiteralParams(`sum by (app) (count_over_time({env=~"prod|dev"} |= "level=" [10s]))`, now.Add(10*time.Second), now.Add(30*time.Second), 10*time.Second, 0, logproto.FORWARD, 100, nil, nil)
+		require.NoError(t, err)
+
+		result, err := engine.Execute(ctx, params)
+		require.NoError(t, err)
+
+		matrix, ok := result.Data.(promql.Matrix)
+		require.True(t, ok, "expected matrix result, got %T", result.Data)
+
+		actual := make(map[string][]float64, len(matrix))
+		for _, series := range matrix {
+			for _, p := range series.Floats {
+				actual[series.Metric.String()] = append(actual[series.Metric.String()], p.F)
+			}
+		}
+		require.Equal(t, map[string][]float64{
+			`{app="bar"}`: {1},
+			`{app="foo"}`: {1, 2, 1},
+		}, actual)
+	})
+
+	t.Run("parser", func(t *testing.T) {
+		params, err := logql.NewLiteralParams(`sum by (level) (count_over_time({env=~"prod|dev"} | logfmt [30s]))`, now.Add(30*time.Second), now.Add(30*time.Second), 0, 0, logproto.FORWARD, 100, nil, nil)
+		require.NoError(t, err)
+
+		result, err := engine.Execute(ctx, params)
+		require.NoError(t, err)
+
+		vector, ok := result.Data.(promql.Vector)
+		require.True(t, ok, "expected vector result, got %T", result.Data)
+
+		actual := make(map[string]float64, len(vector))
+		for _, sample := range vector {
+			actual[sample.Metric.String()] = sample.F
+		}
+		require.Equal(t, map[string]float64{
+			`{level="error"}`: 3,
+			`{level="info"}`:  2,
+		}, actual)
+	})
+
+	t.Run("keep", func(t *testing.T) {
+		params, err := logql.NewLiteralParams(`count_over_time({env=~"prod|dev"} | keep env [30s])`, now.Add(30*time.Second), now.Add(30*time.Second), 0, 0, logproto.FORWARD, 100, nil, nil)
+		require.NoError(t, err)
+
+		result, err := engine.Execute(ctx, params)
+		require.NoError(t, err)
+
+		vector, ok := result.Data.(promql.Vector)
+		require.True(t, ok, "expected vector result, got %T", result.Data)
+
+		actual := make(map[string]float64, len(vector))
+		for _, sample := range vector {
+			actual[sample.Metric.String()] = sample.F
+		}
+		require.Equal(t, map[string]float64{
+			`{env="dev"}`:  1,
+			`{env="prod"}`: 4,
+		}, actual)
+	})
+
+	t.Run("instant query", func(t *testing.T) {
+		params, err := logql.NewLiteralParams(`count_over_time({env="prod"} |= "level=error" [30s])`, now.Add(30*time.Second), now.Add(30*time.Second), 0, 0, logproto.FORWARD, 100, nil, nil)
+		require.NoError(t, err)
+
+		result, err := engine.Execute(ctx, params)
+		require.NoError(t, err)
+
+		vector, ok := result.Data.(promql.Vector)
+		require.True(t, ok, "expected vector result, got %T", result.Data)
+
+		actual := make(map[string]float64, len(vector))
+		for _, sample := range vector {
+			actual[sample.Metric.String()] = sample.F
+		}
+		require.Equal(t, map[string]float64{
+			`{app="bar", env="prod"}`: 1,
+			`{app="foo", env="prod"}`: 1,
+		}, actual)
+	})
+}
+
+func TestQueryEngine_Execute_NotSupported(t *testing.T) {
+	engine := newTestEngine(buildTestObjects(t))
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+
+	params, err := logql.NewLiteralParams(`topk(10, count_over_time({app="foo"}[1m]))`, time.Unix(0, 0), time.Unix(3600, 0), time.Minute, 0, logproto.FORWARD, 100, nil, nil)
+	require.NoError(t, err)
+
+	_, err = engine.Execute(ctx, params)
+	require.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+var (
+	timestampField = newField(types.ColumnNameBuiltinTimestamp, types.ColumnTypeBuiltin, columnar.DataTypeTimestamp)
+	logField       = newField(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin, columnar.DataTypeString)
+	valueField     = newField(types.ColumnNameBuiltinValue, types.ColumnTypeBuiltin, columnar.DataTypeFloat64)
+)
+
+// newField returns a field of the given column type, which is stored in the
+// metadata of the field.
+func newField(name string, ty types.ColumnType, dt columnar.DataType) columnar.Field {
+	return columnar.Field{Name: name, Type: dt, Metadata: types.ColumnMetadata(ty)}
+}
+
+// findColumn returns the index of the column of the batch with the given
+// name and column type, or -1 if the batch has no such column.
+func findColumn(batch *columnar.RecordBatch, name string, ty types.ColumnType) int {
+	return batch.Schema().FieldIndex(func(f columnar.Field) bool {
+		return f.Name == name && types.ColumnTypeOf(f) == ty
+	})
+}
+
+// timestampColumn returns the builtin timestamp column of the batch, or nil
+// if the batch has no timestamp column.
+func timestampColumn(batch *columnar.RecordBatch) *columnar.TimestampArray {
+	idx := findColumn(batch, types.ColumnNameBuiltinTimestamp, types.ColumnTypeBuiltin)
+	if idx < 0 {
+		return nil
+	}
+	arr, _ := batch.Column(idx).(*columnar.TimestampArray)
+	return arr
+}
+
+// valueColumn returns the builtin value column of the batch, or nil if the
+// batch has no value column.
+func valueColumn(batch *columnar.RecordBatch) *columnar.Float64Array {
+	idx := findColumn(batch, types.ColumnNameBuiltinValue, types.ColumnTypeBuiltin)
+	if idx < 0 {
+		return nil
+	}
+	arr, _ := batch.Column(idx).(*columnar.Float64Array)
+	return arr
+}
+
+// rowLabelsFunc returns a function that returns the labels of a row of the
+// batch. The labels of a row are made up of all its non-empty label,
+// metadata, and parsed columns. Parsed labels take precedence over metadata,
+// which takes precedence over a stream label of the same name.
+func rowLabelsFunc(batch *columnar.RecordBatch) func(i int) labels.Labels {
+	type column struct {
+		name   string
+		values *columnar.StringArray
+	}
+
+	var labelCols, metadataCols, parsedCols []column
+	for i := range batch.NumCols() {
+		field := batch.Schema().Field(i)
+		values, ok := batch.Column(i).(*columnar.StringArray)
+		if !ok {
+			continue
+		}
+		switch types.ColumnTypeOf(field) {
+		case types.ColumnTypeLabel:
+			labelCols = append(labelCols, column{name: field.Name, values: values})
+		case types.ColumnTypeMetadata:
+			metadataCols = append(metadataCols, column{name: field.Name, values: values})
+		case types.ColumnTypeParsed:
+			parsedCols = append(parsedCols, column{name: field.Name, values: values})
+		}
+	}
+
+	builder := labels.NewBuilder(labels.EmptyLabels())
+	return func(i int) labels.Labels {
+		builder.Reset(labels.EmptyLabels())
+		for _, cols := range [][]column{labelCols, metadataCols, parsedCols} {
+			for _, col := range cols {
+				if col.values.IsNull(i) || col.values.Value(i) == "" {
+					continue
+				}
+				builder.Set(col.name, col.values.Value(i))
+			}
+		}
+		return builder.Labels()
+	}
+}
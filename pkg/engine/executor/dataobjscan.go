@@ -0,0 +1,390 @@
+package executor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+// dataObjScanOptions holds the options of a [dataObjScan] pipeline.
+type dataObjScanOptions struct {
+	// Object is the data object that is read.
+	Object *dataobj.Object
+	// StreamIDs is the set of streams of the object to read. All streams are
+	// read if the set is empty.
+	StreamIDs []int64
+	// Sections is the set of logs sections of the object to read. All
+	// sections are read if Sections is nil.
+	Sections []int
+	// Predicate is used to filter the log records of the object. It may be
+	// nil.
+	Predicate dataobj.LogsPredicate
+	// Projections limits the label and metadata columns returned by the
+	// scan. Ambiguous columns project both label and metadata columns of the
+	// same name. All columns are returned if Projections is empty.
+	Projections []types.ColumnRef
+	// Direction defines the order in which rows are returned.
+	Direction physical.Direction
+	// Limit is the maximum number of rows returned. No limit is applied if
+	// Limit is 0.
+	Limit uint32
+	// BatchSize is the maximum number of rows in a single batch.
+	BatchSize int64
+	// Stats records the bytes and pages read by the scan if set.
+	Stats *NodeStats
+}
+
+// dataObjScan is a [Pipeline] that reads the log records of the matching
+// streams of a single data object. Rows are returned sorted by timestamp in
+// the requested direction.
+//
+// Batches returned by the scan have a builtin timestamp and log column, one
+// label column per stream label, and one metadata column per metadata key.
+type dataObjScan struct {
+	ctx  context.Context
+	opts dataObjScanOptions
+
+	initialized bool
+	streams     map[int64]labels.Labels
+	labelNames  []string
+
+	pending     []*columnar.RecordBatch // Batches read from the object.
+	pendingRows int
+
+	result *columnar.RecordBatch // Sorted rows of the object.
+	offset int
+
+	state state
+}
+
+var _ Pipeline = (*dataObjScan)(nil)
+
+func newDataObjScanPipeline(ctx context.Context, opts dataObjScanOptions) *dataObjScan {
+	return &dataObjScan{ctx: ctx, opts: opts}
+}
+
+// Read implements [Pipeline].
+func (s *dataObjScan) Read() error {
+	if !s.initialized {
+		if err := s.init(); err != nil {
+			s.state = failureState(err)
+			return err
+		}
+		s.initialized = true
+	}
+
+	if s.result == nil || s.offset >= s.result.NumRows() {
+		s.state = exhausted
+		return EOF
+	}
+
+	end := s.result.NumRows()
+	if s.opts.BatchSize > 0 {
+		end = min(end, s.offset+int(s.opts.BatchSize))
+	}
+	s.state = successState(s.result.Slice(s.offset, end))
+	s.offset = end
+	return nil
+}
+
+// init reads all matching records of the data object into memory and sorts
+// them by timestamp.
+func (s *dataObjScan) init() error {
+	// streamsRows is the number of rows of the streams sections, which are
+	// not counted as rows read by the scan.
+	var streamsRows int64
+	if s.opts.Stats != nil {
+		// The reads of the scan are collected in a separate statistics
+		// context to report them per node, and are joined with the
+		// statistics of the query afterwards.
+		parent := s.ctx
+		scanStats, ctx := stats.NewContext(parent)
+		s.ctx = ctx
+		defer func() {
+			s.ctx = parent
+
+			store := scanStats.Store()
+			s.opts.Stats.RowsRead += store.Dataobj.TotalRowsAvailable - streamsRows
+			s.opts.Stats.BytesRead += store.Dataobj.PagesDownloadedBytes
+			s.opts.Stats.PagesScanned += store.Dataobj.PagesScanned
+			s.opts.Stats.PagesPruned += scanStats.PagesPruned()
+
+			stats.JoinResults(parent, stats.Result{Querier: stats.Querier{Store: store}})
+			stats.FromContext(parent).AddPagesPruned(scanStats.PagesPruned())
+		}()
+	}
+
+	if s.opts.Sections != nil && len(s.opts.Sections) == 0 {
+		return nil
+	}
+
+	md, err := s.opts.Object.Metadata(s.ctx)
+	if err != nil {
+		return fmt.Errorf("reading metadata: %w", err)
+	}
+
+	if err := s.readStreams(md.StreamsSections); err != nil {
+		return err
+	}
+	if s.opts.Stats != nil {
+		streamsRows = stats.FromContext(s.ctx).Store().Dataobj.TotalRowsAvailable
+	}
+	if len(s.streams) == 0 {
+		return nil
+	}
+
+	streamIDs := make([]int64, 0, len(s.streams))
+	for id := range s.streams {
+		streamIDs = append(streamIDs, id)
+	}
+
+	reader := dataobj.NewLogsReader(s.opts.Object, 0)
+	defer reader.Close()
+
+	for _, section := range s.logsSections(md.LogsSections) {
+		reader.Reset(s.opts.Object, section)
+		if err := reader.MatchStreams(slices.Values(streamIDs)); err != nil {
+			return err
+		}
+		if s.opts.Predicate != nil {
+			if err := reader.SetPredicate(s.opts.Predicate); err != nil {
+				return err
+			}
+		}
+		if len(s.opts.Projections) > 0 {
+			if err := reader.ProjectMetadata(s.projectedNames(types.ColumnTypeMetadata)...); err != nil {
+				return err
+			}
+		}
+
+		for {
+			batch, err := reader.ReadBatch(s.ctx, 1024)
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return fmt.Errorf("reading logs section %d: %w", section, err)
+			}
+
+			converted, err := s.convertBatch(batch)
+			if err != nil {
+				return fmt.Errorf("reading logs section %d: %w", section, err)
+			}
+			s.pending = append(s.pending, converted)
+			s.pendingRows += converted.NumRows()
+
+			// Bound the memory of the scan by discarding rows that can never
+			// be returned because of the limit.
+			if s.opts.Limit > 0 && s.pendingRows > 2*int(s.opts.Limit) {
+				if err := s.sortAndTruncate(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := s.sortAndTruncate(); err != nil {
+		return err
+	}
+	if len(s.pending) > 0 {
+		s.result = s.pending[0]
+	}
+	s.pending = nil
+	return nil
+}
+
+// logsSections returns the indexes of the logs sections to read, given the
+// number of logs sections of the object.
+func (s *dataObjScan) logsSections(count int) []int {
+	if s.opts.Sections == nil {
+		sections := make([]int, count)
+		for i := range sections {
+			sections[i] = i
+		}
+		return sections
+	}
+	sections := make([]int, 0, len(s.opts.Sections))
+	for _, section := range s.opts.Sections {
+		if section >= 0 && section < count {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// readStreams reads the labels of all streams of the object that are part
+// of the configured set of stream IDs.
+func (s *dataObjScan) readStreams(sections int) error {
+	matchIDs := make(map[int64]struct{}, len(s.opts.StreamIDs))
+	for _, id := range s.opts.StreamIDs {
+		matchIDs[id] = struct{}{}
+	}
+
+	s.streams = make(map[int64]labels.Labels)
+	names := make(map[string]struct{})
+
+	reader := dataobj.NewStreamsReader(s.opts.Object, 0)
+	defer reader.Close()
+
+	buf := make([]dataobj.Stream, 512)
+	for section := 0; section < sections; section++ {
+		reader.Reset(s.opts.Object, section)
+
+		for {
+			n, err := reader.Read(s.ctx, buf)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("reading streams section %d: %w", section, err)
+			} else if n == 0 && errors.Is(err, io.EOF) {
+				break
+			}
+
+			for _, stream := range buf[:n] {
+				if _, ok := matchIDs[stream.ID]; len(matchIDs) > 0 && !ok {
+					continue
+				}
+				s.streams[stream.ID] = stream.Labels.Copy()
+				stream.Labels.Range(func(l labels.Label) { names[l.Name] = struct{}{} })
+			}
+		}
+	}
+
+	if len(s.opts.Projections) > 0 {
+		projected := make(map[string]struct{})
+		for _, name := range s.projectedNames(types.ColumnTypeLabel) {
+			if _, ok := names[name]; ok {
+				projected[name] = struct{}{}
+			}
+		}
+		names = projected
+	}
+
+	s.labelNames = make([]string, 0, len(names))
+	for name := range names {
+		s.labelNames = append(s.labelNames, name)
+	}
+	sort.Strings(s.labelNames)
+	return nil
+}
+
+// projectedNames returns the names of the projected columns of the given
+// column type, including ambiguous columns.
+func (s *dataObjScan) projectedNames(ty types.ColumnType) []string {
+	var names []string
+	for _, ref := range s.opts.Projections {
+		if ref.Type == ty || ref.Type == types.ColumnTypeAmbiguous {
+			names = append(names, ref.Column)
+		}
+	}
+	return names
+}
+
+// convertBatch converts a batch read from a logs section into a batch of the
+// engine. The stream ID column is replaced by one column per stream label.
+// Timestamp, log line, and metadata columns are reused without copying.
+func (s *dataObjScan) convertBatch(src *columnar.RecordBatch) (*columnar.RecordBatch, error) {
+	var (
+		streamIDs *columnar.Int64Array
+
+		fields  = []columnar.Field{timestampField, logField}
+		columns = make([]columnar.Array, 2)
+
+		metadataFields  []columnar.Field
+		metadataColumns []columnar.Array
+	)
+
+	for i := range src.NumCols() {
+		field, col := src.Schema().Field(i), src.Column(i)
+
+		switch field.Metadata[dataobj.LogsColumnTypeKey] {
+		case dataobj.LogsColumnTypeStreamID:
+			streamIDs, _ = col.(*columnar.Int64Array)
+		case dataobj.LogsColumnTypeTimestamp:
+			columns[0] = col
+		case dataobj.LogsColumnTypeMessage:
+			columns[1] = col
+		case dataobj.LogsColumnTypeMetadata:
+			metadataFields = append(metadataFields, newField(field.Name, types.ColumnTypeMetadata, columnar.DataTypeString))
+			metadataColumns = append(metadataColumns, col)
+		}
+	}
+	if streamIDs == nil || columns[0] == nil || columns[1] == nil {
+		return nil, errors.New("logs section is missing the stream ID, timestamp, or message column")
+	}
+
+	for _, name := range s.labelNames {
+		var builder columnar.StringBuilder
+		for i := range streamIDs.Len() {
+			if value := s.streams[streamIDs.Value(i)].Get(name); value != "" {
+				builder.Append(value)
+				continue
+			}
+			builder.AppendNull()
+		}
+		fields = append(fields, newField(name, types.ColumnTypeLabel, columnar.DataTypeString))
+		columns = append(columns, builder.Build())
+	}
+
+	fields = append(fields, metadataFields...)
+	columns = append(columns, metadataColumns...)
+	return columnar.NewRecordBatch(columnar.NewSchema(fields...), src.NumRows(), columns)
+}
+
+// sortAndTruncate merges all pending batches into a single batch that is
+// sorted by timestamp and holds at most Limit rows.
+func (s *dataObjScan) sortAndTruncate() error {
+	type rowRef struct {
+		batch, row int
+		ts         int64
+	}
+
+	refs := make([]rowRef, 0, s.pendingRows)
+	for b, batch := range s.pending {
+		timestamps := timestampColumn(batch)
+		for i := range batch.NumRows() {
+			refs = append(refs, rowRef{batch: b, row: i, ts: timestamps.Value(i)})
+		}
+	}
+
+	slices.SortStableFunc(refs, func(a, b rowRef) int {
+		if s.opts.Direction == physical.Backwards {
+			return cmp.Compare(b.ts, a.ts)
+		}
+		return cmp.Compare(a.ts, b.ts)
+	})
+	if s.opts.Limit > 0 && len(refs) > int(s.opts.Limit) {
+		refs = refs[:s.opts.Limit]
+	}
+
+	builder := columnar.NewRecordBuilder()
+	for _, ref := range refs {
+		if err := builder.AppendRow(s.pending[ref.batch], ref.row); err != nil {
+			return err
+		}
+	}
+
+	s.pending = append(s.pending[:0], builder.Build())
+	s.pendingRows = len(refs)
+	return nil
+}
+
+// Value implements [Pipeline].
+func (s *dataObjScan) Value() (*columnar.RecordBatch, error) {
+	return s.state.Value()
+}
+
+// Close implements [Pipeline].
+func (s *dataObjScan) Close() {
+	s.pending = nil
+	s.result = nil
+}
@@ -0,0 +1,180 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+var (
+	minTime = time.Unix(0, math.MinInt64).UTC()
+	maxTime = time.Unix(0, math.MaxInt64).UTC()
+)
+
+// buildLogsPredicate converts the predicates of a [physical.DataObjScan] into
+// a single [dataobj.LogsPredicate]. The predicates are combined with a logical
+// AND. It returns nil if there are no predicates.
+func buildLogsPredicate(exprs []physical.Expression) (dataobj.LogsPredicate, error) {
+	var result dataobj.LogsPredicate
+	for _, expr := range exprs {
+		p, err := convertLogsPredicate(expr)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = p
+			continue
+		}
+		result = dataobj.AndPredicate[dataobj.LogsPredicate]{Left: result, Right: p}
+	}
+	return result, nil
+}
+
+func convertLogsPredicate(expr physical.Expression) (dataobj.LogsPredicate, error) {
+	switch expr := expr.(type) {
+	case *physical.UnaryExpr:
+		if expr.Op != types.UnaryOpNot {
+			return nil, fmt.Errorf("unsupported unary operator %s in predicate", expr.Op)
+		}
+		inner, err := convertLogsPredicate(expr.Left)
+		if err != nil {
+			return nil, err
+		}
+		return dataobj.NotPredicate[dataobj.LogsPredicate]{Inner: inner}, nil
+
+	case *physical.BinaryExpr:
+		switch expr.Op {
+		case types.BinaryOpAnd, types.BinaryOpOr:
+			left, err := convertLogsPredicate(expr.Left)
+			if err != nil {
+				return nil, err
+			}
+			right, err := convertLogsPredicate(expr.Right)
+			if err != nil {
+				return nil, err
+			}
+			if expr.Op == types.BinaryOpAnd {
+				return dataobj.AndPredicate[dataobj.LogsPredicate]{Left: left, Right: right}, nil
+			}
+			return dataobj.OrPredicate[dataobj.LogsPredicate]{Left: left, Right: right}, nil
+		}
+		return convertComparison(expr)
+	}
+
+	return nil, fmt.Errorf("unsupported predicate expression %s", expr)
+}
+
+// convertComparison converts a binary expression with a column on the left
+// and a literal on the right side into a predicate.
+func convertComparison(expr *physical.BinaryExpr) (dataobj.LogsPredicate, error) {
+	col, ok := expr.Left.(*physical.ColumnExpr)
+	if !ok {
+		return nil, fmt.Errorf("expected column expression on the left side of %s", expr)
+	}
+	lit, ok := expr.Right.(*physical.LiteralExpr)
+	if !ok {
+		return nil, fmt.Errorf("expected literal expression on the right side of %s", expr)
+	}
+
+	switch {
+	case col.Ref.Type == types.ColumnTypeBuiltin && col.Ref.Column == types.ColumnNameBuiltinTimestamp:
+		return convertTimestampComparison(expr.Op, lit)
+	case col.Ref.Type == types.ColumnTypeBuiltin && col.Ref.Column == types.ColumnNameBuiltinLog:
+		return convertLineComparison(expr.Op, lit)
+	case col.Ref.Type == types.ColumnTypeMetadata:
+		return convertMetadataComparison(col.Ref.Column, expr.Op, lit)
+	}
+
+	return nil, fmt.Errorf("unsupported column %s in predicate", col.Ref.String())
+}
+
+func convertTimestampComparison(op types.BinaryOp, lit *physical.LiteralExpr) (dataobj.LogsPredicate, error) {
+	if lit.ValueType() != types.ValueTypeTimestamp {
+		return nil, fmt.Errorf("expected timestamp literal, got %s", lit.ValueType())
+	}
+	ts := time.Unix(0, int64(lit.Value.Timestamp())).UTC()
+
+	switch op {
+	case types.BinaryOpEq:
+		return dataobj.TimeRangePredicate[dataobj.LogsPredicate]{StartTime: ts, EndTime: ts, IncludeStart: true, IncludeEnd: true}, nil
+	case types.BinaryOpGt:
+		return dataobj.TimeRangePredicate[dataobj.LogsPredicate]{StartTime: ts, EndTime: maxTime, IncludeStart: false, IncludeEnd: true}, nil
+	case types.BinaryOpGte:
+		return dataobj.TimeRangePredicate[dataobj.LogsPredicate]{StartTime: ts, EndTime: maxTime, IncludeStart: true, IncludeEnd: true}, nil
+	case types.BinaryOpLt:
+		return dataobj.TimeRangePredicate[dataobj.LogsPredicate]{StartTime: minTime, EndTime: ts, IncludeStart: true, IncludeEnd: false}, nil
+	case types.BinaryOpLte:
+		return dataobj.TimeRangePredicate[dataobj.LogsPredicate]{StartTime: minTime, EndTime: ts, IncludeStart: true, IncludeEnd: true}, nil
+	case types.BinaryOpNeq:
+		return dataobj.NotPredicate[dataobj.LogsPredicate]{
+			Inner: dataobj.TimeRangePredicate[dataobj.LogsPredicate]{StartTime: ts, EndTime: ts, IncludeStart: true, IncludeEnd: true},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s for timestamp predicate", op)
+}
+
+func convertLineComparison(op types.BinaryOp, lit *physical.LiteralExpr) (dataobj.LogsPredicate, error) {
+	if lit.ValueType() != types.ValueTypeStr {
+		return nil, fmt.Errorf("expected string literal, got %s", lit.ValueType())
+	}
+	filter, err := newLineFilter(op, lit.Value.Str())
+	if err != nil {
+		return nil, err
+	}
+	predicate := dataobj.LogMessageFilterPredicate{
+		Keep: func(line []byte) bool { return filter.Filter(line) },
+	}
+	if op == types.BinaryOpMatchSubstr && lit.Value.Str() != "" {
+		predicate.Contains = []string{lit.Value.Str()}
+	}
+	return predicate, nil
+}
+
+func convertMetadataComparison(key string, op types.BinaryOp, lit *physical.LiteralExpr) (dataobj.LogsPredicate, error) {
+	if lit.ValueType() != types.ValueTypeStr {
+		return nil, fmt.Errorf("expected string literal, got %s", lit.ValueType())
+	}
+	value := lit.Value.Str()
+
+	// Negated operators are expressed as the negation of their positive
+	// counterpart, so rows without the metadata key still match.
+	switch op {
+	case types.BinaryOpEq:
+		return dataobj.MetadataMatcherPredicate{Key: key, Value: value}, nil
+	case types.BinaryOpNeq:
+		return dataobj.NotPredicate[dataobj.LogsPredicate]{
+			Inner: dataobj.MetadataMatcherPredicate{Key: key, Value: value},
+		}, nil
+	case types.BinaryOpMatchSubstr:
+		return metadataFilter(key, func(v string) bool { return strings.Contains(v, value) }), nil
+	case types.BinaryOpNotMatchSubstr:
+		return dataobj.NotPredicate[dataobj.LogsPredicate]{
+			Inner: metadataFilter(key, func(v string) bool { return strings.Contains(v, value) }),
+		}, nil
+	case types.BinaryOpMatchRe, types.BinaryOpNotMatchRe:
+		matcher, err := labels.NewMatcher(labels.MatchRegexp, key, value)
+		if err != nil {
+			return nil, err
+		}
+		var p dataobj.LogsPredicate = metadataFilter(key, matcher.Matches)
+		if op == types.BinaryOpNotMatchRe {
+			p = dataobj.NotPredicate[dataobj.LogsPredicate]{Inner: p}
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s for metadata predicate", op)
+}
+
+func metadataFilter(key string, keep func(string) bool) dataobj.MetadataFilterPredicate {
+	return dataobj.MetadataFilterPredicate{
+		Key:  key,
+		Keep: func(_, value string) bool { return keep(value) },
+	}
+}
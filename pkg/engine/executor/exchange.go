@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// FragmentRunner executes the fragments of a plan that are consumed by
+// [physical.Exchange] nodes, e.g. by sending them to other processes.
+type FragmentRunner interface {
+	// RunFragment returns a [Pipeline] that produces the rows of the
+	// fragment. Errors that occur while executing the fragment are returned
+	// by the Read method of the pipeline.
+	RunFragment(ctx context.Context, fragment *physical.Fragment) Pipeline
+}
+
+func (e *executor) executeExchange(ctx context.Context, node *physical.Exchange, inputs []Pipeline) Pipeline {
+	if len(inputs) > 0 {
+		return errorPipeline(fmt.Errorf("exchange expects no inputs, got %d", len(inputs)))
+	}
+	if node.Fragment == nil || node.Fragment.Plan == nil {
+		return errorPipeline(fmt.Errorf("exchange %s has no fragment", node.ID()))
+	}
+
+	if e.fragments == nil {
+		return e.execute(ctx, node.Fragment.Plan)
+	}
+	return e.fragments.RunFragment(ctx, node.Fragment)
+}
+
+// WriteBatches reads all batches of the pipeline and writes them to w in
+// the encoding of [columnar.Encoder]. Each batch is written as soon as it is
+// read. The stream is only terminated if all batches were written, so that
+// readers of the batches using [NewDecodePipeline] fail if the pipeline
+// fails.
+func WriteBatches(w io.Writer, pipeline Pipeline) error {
+	enc := columnar.NewEncoder(w)
+	if err := readAll(pipeline, enc.Encode); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// NewDecodePipeline returns a [Pipeline] that reads the batches written by
+// [WriteBatches] from r. Closing the pipeline closes r.
+func NewDecodePipeline(r io.ReadCloser) Pipeline {
+	dec := columnar.NewDecoder(r)
+	return &decodePipeline{
+		r: r,
+		read: func() state {
+			batch, err := dec.Decode()
+			if errors.Is(err, io.EOF) {
+				return exhausted
+			} else if err != nil {
+				return failureState(fmt.Errorf("decoding batch: %w", err))
+			}
+			return successState(batch)
+		},
+	}
+}
+
+// decodePipeline is a [Pipeline] that decodes batches from a reader.
+type decodePipeline struct {
+	r     io.ReadCloser
+	read  func() state
+	state state
+}
+
+// Read implements [Pipeline].
+func (p *decodePipeline) Read() error {
+	p.state = p.read()
+	return p.state.err
+}
+
+// Value implements [Pipeline].
+func (p *decodePipeline) Value() (*columnar.RecordBatch, error) {
+	return p.state.Value()
+}
+
+// Close implements [Pipeline].
+func (p *decodePipeline) Close() {
+	_ = p.r.Close()
+}
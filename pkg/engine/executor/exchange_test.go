@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+type fragmentRunnerFunc func(ctx context.Context, fragment *physical.Fragment) Pipeline
+
+func (f fragmentRunnerFunc) RunFragment(ctx context.Context, fragment *physical.Fragment) Pipeline {
+	return f(ctx, fragment)
+}
+
+func TestExchange(t *testing.T) {
+	node := &physical.Exchange{Fragment: &physical.Fragment{ID: 3, Plan: &physical.Plan{}}}
+
+	t.Run("fragment runner", func(t *testing.T) {
+		var ran []int
+		e := &executor{fragments: fragmentRunnerFunc(func(_ context.Context, fragment *physical.Fragment) Pipeline {
+			ran = append(ran, fragment.ID)
+			return newBufferedPipeline(batch(row(1, "a"), row(2, "b")))
+		})}
+
+		pipeline := e.executeExchange(context.Background(), node, nil)
+		defer pipeline.Close()
+		require.Equal(t, []string{"a", "b"}, collect(t, pipeline))
+		require.Equal(t, []int{3}, ran)
+	})
+
+	t.Run("local", func(t *testing.T) {
+		e := &executor{}
+
+		// Fragments are executed as a plan by the executor itself, which
+		// requires a single root node.
+		pipeline := e.executeExchange(context.Background(), node, nil)
+		defer pipeline.Close()
+		require.ErrorContains(t, pipeline.Read(), "plan must have exactly one root node")
+	})
+
+	t.Run("inputs", func(t *testing.T) {
+		e := &executor{}
+		pipeline := e.executeExchange(context.Background(), node, []Pipeline{emptyPipeline()})
+		defer pipeline.Close()
+		require.ErrorContains(t, pipeline.Read(), "exchange expects no inputs")
+	})
+}
+
+func TestWriteBatches(t *testing.T) {
+	input := newBufferedPipeline(
+		batch(row(1, "a", "app", "foo"), row(2, "b", "app", "bar")),
+		batch(row(3, "c", "env", "prod")),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBatches(&buf, input))
+
+	pipeline := NewDecodePipeline(io.NopCloser(&buf))
+	defer pipeline.Close()
+	require.Equal(t, []string{
+		`{app="foo"}`,
+		`{app="bar"}`,
+		`{env="prod"}`,
+	}, collectLabels(t, pipeline))
+}
@@ -0,0 +1,262 @@
+// Package executor evaluates physical query plans. Each node of a
+// [physical.Plan] is converted into a [Pipeline], which produces batches of
+// rows that are consumed by the pipeline of its parent node.
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// Config holds the configuration of the executor.
+type Config struct {
+	// BatchSize is the maximum number of rows in a single batch returned by
+	// a pipeline.
+	BatchSize int64
+	// Bucket is the object storage bucket used to read data objects from.
+	Bucket objstore.Bucket
+	// Fragments executes the fragments consumed by [physical.Exchange] nodes.
+	// If nil, fragments are executed locally as part of the plan.
+	Fragments FragmentRunner
+	// Stats collects the runtime statistics of the nodes of the plan if set.
+	Stats *Stats
+}
+
+// Run converts the physical plan into a [Pipeline] that can be read to obtain
+// the results of the plan. The plan is required to have exactly one root node.
+func Run(ctx context.Context, cfg Config, plan *physical.Plan) Pipeline {
+	executor := &executor{
+		batchSize: cfg.BatchSize,
+		bucket:    cfg.Bucket,
+		fragments: cfg.Fragments,
+		stats:     cfg.Stats,
+	}
+	return executor.execute(ctx, plan)
+}
+
+// executor converts the nodes of a physical plan into pipelines.
+type executor struct {
+	batchSize int64
+	bucket    objstore.Bucket
+	fragments FragmentRunner
+	stats     *Stats
+}
+
+func (e *executor) execute(ctx context.Context, plan *physical.Plan) Pipeline {
+	if plan == nil {
+		return errorPipeline(errors.New("failed to execute pipeline: plan is nil"))
+	}
+	roots := plan.Roots()
+	if len(roots) != 1 {
+		return errorPipeline(fmt.Errorf("failed to execute pipeline: plan must have exactly one root node, got %d", len(roots)))
+	}
+	return e.executeNode(ctx, plan, roots[0])
+}
+
+func (e *executor) executeNode(ctx context.Context, plan *physical.Plan, node physical.Node) Pipeline {
+	children := plan.Children(node)
+	inputs := make([]Pipeline, 0, len(children))
+	for _, child := range children {
+		inputs = append(inputs, e.executeNode(ctx, plan, child))
+	}
+
+	pipeline := e.executeNodeWithInputs(ctx, plan, node, inputs)
+	if e.stats != nil {
+		pipeline = newInstrumentedPipeline(pipeline, e.stats.forNode(node))
+	}
+	return pipeline
+}
+
+func (e *executor) executeNodeWithInputs(ctx context.Context, plan *physical.Plan, node physical.Node, inputs []Pipeline) Pipeline {
+	switch n := node.(type) {
+	case *physical.DataObjScan:
+		return e.executeDataObjScan(ctx, n)
+	case *physical.SortMerge:
+		return e.executeSortMerge(ctx, n, plan.Children(n), inputs)
+	case *physical.Limit:
+		return e.executeLimit(ctx, n, inputs)
+	case *physical.Filter:
+		return e.executeFilter(ctx, n, inputs)
+	case *physical.Projection:
+		return e.executeProjection(ctx, n, inputs)
+	case *physical.Parse:
+		return e.executeParse(ctx, n, inputs)
+	case *physical.Transform:
+		return e.executeTransform(ctx, n, inputs)
+	case *physical.RangeAggregation:
+		return e.executeRangeAggregation(ctx, n, inputs)
+	case *physical.VectorAggregation:
+		return e.executeVectorAggregation(ctx, n, inputs)
+	case *physical.Exchange:
+		return e.executeExchange(ctx, n, inputs)
+	default:
+		return errorPipeline(fmt.Errorf("invalid node type: %T", node))
+	}
+}
+
+func (e *executor) executeDataObjScan(ctx context.Context, node *physical.DataObjScan) Pipeline {
+	if e.bucket == nil {
+		return errorPipeline(errors.New("no object store bucket configured"))
+	}
+
+	predicate, err := buildLogsPredicate(node.Predicates)
+	if err != nil {
+		return errorPipeline(fmt.Errorf("converting predicates of %s: %w", node.ID(), err))
+	}
+	projections, err := columnRefs(node.Projections)
+	if err != nil {
+		return errorPipeline(fmt.Errorf("converting projections of %s: %w", node.ID(), err))
+	}
+
+	var stats *NodeStats
+	if e.stats != nil {
+		stats = e.stats.forNode(node)
+	}
+
+	return newDataObjScanPipeline(ctx, dataObjScanOptions{
+		Object:      dataobj.FromBucket(e.bucket, string(node.Location)),
+		StreamIDs:   node.StreamIDs,
+		Sections:    node.Sections,
+		Predicate:   predicate,
+		Projections: projections,
+		Direction:   node.Direction,
+		Limit:       node.Limit,
+		BatchSize:   e.batchSize,
+		Stats:       stats,
+	})
+}
+
+func (e *executor) executeSortMerge(_ context.Context, node *physical.SortMerge, children []physical.Node, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	pipeline, err := newSortMergePipeline(inputs, node.Order, node.Column, e.batchSize)
+	if err != nil {
+		return errorPipeline(err)
+	}
+
+	// The time ranges of the scans allow the sort merge to defer reading
+	// data objects until their rows are needed, so that data objects with
+	// rows beyond the limit of a query are never read.
+	bounds := make([]time.Time, len(children))
+	for i, child := range children {
+		scan, ok := child.(*physical.DataObjScan)
+		if !ok || scan.TimeRange.IsZero() {
+			continue
+		}
+		if node.Order == physical.DESC {
+			bounds[i] = scan.TimeRange.End
+		} else {
+			bounds[i] = scan.TimeRange.Start
+		}
+	}
+	return pipeline.withBounds(bounds)
+}
+
+func (e *executor) executeLimit(_ context.Context, node *physical.Limit, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("limit expects exactly one input, got %d", len(inputs)))
+	}
+
+	return newLimitPipeline(inputs[0], node.Skip, node.Fetch)
+}
+
+func (e *executor) executeFilter(_ context.Context, node *physical.Filter, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("filter expects exactly one input, got %d", len(inputs)))
+	}
+
+	return newFilterPipeline(inputs[0], node.Predicates)
+}
+
+func (e *executor) executeProjection(_ context.Context, node *physical.Projection, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("projection expects exactly one input, got %d", len(inputs)))
+	}
+
+	if len(node.Columns) == 0 {
+		return errorPipeline(fmt.Errorf("projection expects at least one column, got 0"))
+	}
+
+	return newProjectPipeline(inputs[0], node.Columns)
+}
+
+func (e *executor) executeParse(_ context.Context, node *physical.Parse, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("parse expects exactly one input, got %d", len(inputs)))
+	}
+
+	pipeline, err := newParsePipeline(inputs[0], node)
+	if err != nil {
+		return errorPipeline(err)
+	}
+	return pipeline
+}
+
+func (e *executor) executeTransform(_ context.Context, node *physical.Transform, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("transform expects exactly one input, got %d", len(inputs)))
+	}
+
+	pipeline, err := newTransformPipeline(inputs[0], node)
+	if err != nil {
+		return errorPipeline(err)
+	}
+	return pipeline
+}
+
+func (e *executor) executeRangeAggregation(_ context.Context, node *physical.RangeAggregation, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	pipeline, err := newRangeAggregationPipeline(inputs, node, e.batchSize)
+	if err != nil {
+		return errorPipeline(err)
+	}
+	return pipeline
+}
+
+func (e *executor) executeVectorAggregation(_ context.Context, node *physical.VectorAggregation, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("vector aggregation expects exactly one input, got %d", len(inputs)))
+	}
+
+	pipeline, err := newVectorAggregationPipeline(inputs[0], node, e.batchSize)
+	if err != nil {
+		return errorPipeline(err)
+	}
+	return pipeline
+}
@@ -0,0 +1,433 @@
+package executor
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// expressionEvaluator evaluates physical expressions against record batches.
+// Each expression evaluates to an array with one value per row of the batch.
+// Compiled matchers of binary expressions are cached, so the same evaluator
+// should be used for evaluating an expression against many batches.
+type expressionEvaluator struct {
+	lineFilters   map[*physical.BinaryExpr]log.Filterer
+	labelMatchers map[*physical.BinaryExpr]*labels.Matcher
+}
+
+func newExpressionEvaluator() *expressionEvaluator {
+	return &expressionEvaluator{
+		lineFilters:   make(map[*physical.BinaryExpr]log.Filterer),
+		labelMatchers: make(map[*physical.BinaryExpr]*labels.Matcher),
+	}
+}
+
+// eval evaluates the expression against all rows of the given batch.
+func (e *expressionEvaluator) eval(expr physical.Expression, batch *columnar.RecordBatch) (columnar.Array, error) {
+	switch expr := expr.(type) {
+	case *physical.LiteralExpr:
+		return literalArray(expr.Value, batch.NumRows())
+
+	case *physical.ColumnExpr:
+		return columnArray(expr.Ref, batch)
+
+	case *physical.UnaryExpr:
+		val, err := e.eval(expr.Left, batch)
+		if err != nil {
+			return nil, err
+		}
+		switch expr.Op {
+		case types.UnaryOpNot:
+			arr, ok := val.(*columnar.BoolArray)
+			if !ok {
+				return nil, fmt.Errorf("operator %s requires a boolean operand, got %s", expr.Op, val.DataType())
+			}
+			var b columnar.BoolBuilder
+			for i := range arr.Len() {
+				if arr.IsNull(i) {
+					b.AppendNull()
+					continue
+				}
+				b.Append(!arr.Value(i))
+			}
+			return b.Build(), nil
+		default:
+			return nil, fmt.Errorf("unsupported unary operator %s", expr.Op)
+		}
+
+	case *physical.BinaryExpr:
+		return e.evalBinary(expr, batch)
+	}
+
+	return nil, fmt.Errorf("unsupported expression: %v", expr)
+}
+
+func (e *expressionEvaluator) evalBinary(expr *physical.BinaryExpr, batch *columnar.RecordBatch) (columnar.Array, error) {
+	switch expr.Op {
+	case types.BinaryOpMatchSubstr, types.BinaryOpNotMatchSubstr,
+		types.BinaryOpMatchRe, types.BinaryOpNotMatchRe,
+		types.BinaryOpMatchPattern, types.BinaryOpNotMatchPattern:
+		return e.evalMatch(expr, batch)
+	}
+
+	lhs, err := e.eval(expr.Left, batch)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := e.eval(expr.Right, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.Op {
+	case types.BinaryOpAnd, types.BinaryOpOr:
+		l, lok := lhs.(*columnar.BoolArray)
+		r, rok := rhs.(*columnar.BoolArray)
+		if !lok || !rok {
+			return nil, fmt.Errorf("operator %s requires boolean operands, got %s and %s", expr.Op, lhs.DataType(), rhs.DataType())
+		}
+
+		// NULL values are treated as false.
+		var b columnar.BoolBuilder
+		for i := range l.Len() {
+			x := !l.IsNull(i) && l.Value(i)
+			y := !r.IsNull(i) && r.Value(i)
+			if expr.Op == types.BinaryOpAnd {
+				b.Append(x && y)
+			} else {
+				b.Append(x || y)
+			}
+		}
+		return b.Build(), nil
+
+	case types.BinaryOpEq, types.BinaryOpNeq, types.BinaryOpGt, types.BinaryOpGte, types.BinaryOpLt, types.BinaryOpLte:
+		res, err := compareArrays(expr.Op, lhs, rhs)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating %s: %w", expr, err)
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("unsupported binary operator %s", expr.Op)
+}
+
+// evalMatch evaluates string matching operations. Matching against the log
+// line follows the semantics of line filters, whereas matching against any
+// other column follows the semantics of label matchers. The right side of
+// the expression must be a string literal.
+func (e *expressionEvaluator) evalMatch(expr *physical.BinaryExpr, batch *columnar.RecordBatch) (columnar.Array, error) {
+	lit, ok := expr.Right.(*physical.LiteralExpr)
+	if !ok || lit.ValueType() != types.ValueTypeStr {
+		return nil, fmt.Errorf("operator %s requires a string literal on the right side", expr.Op)
+	}
+	match := lit.Value.Str()
+
+	lhs, err := e.eval(expr.Left, batch)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := stringValues(lhs)
+	if !ok {
+		return nil, fmt.Errorf("operator %s requires string operands, got %s", expr.Op, lhs.DataType())
+	}
+
+	var keep func(string) bool
+	switch {
+	case isLogColumn(expr.Left):
+		filter, ok := e.lineFilters[expr]
+		if !ok {
+			filter, err = newLineFilter(expr.Op, match)
+			if err != nil {
+				return nil, err
+			}
+			e.lineFilters[expr] = filter
+		}
+		keep = func(s string) bool { return filter.Filter(unsafeBytes(s)) }
+
+	case expr.Op == types.BinaryOpMatchSubstr:
+		keep = func(s string) bool { return strings.Contains(s, match) }
+	case expr.Op == types.BinaryOpNotMatchSubstr:
+		keep = func(s string) bool { return !strings.Contains(s, match) }
+
+	default:
+		matcher, ok := e.labelMatchers[expr]
+		if !ok {
+			ty, err := matchTypeForOp(expr.Op)
+			if err != nil {
+				return nil, err
+			}
+			matcher, err = labels.NewMatcher(ty, "", match)
+			if err != nil {
+				return nil, err
+			}
+			e.labelMatchers[expr] = matcher
+		}
+		keep = matcher.Matches
+	}
+
+	var b columnar.BoolBuilder
+	for i := range lhs.Len() {
+		b.Append(keep(value(i)))
+	}
+	return b.Build(), nil
+}
+
+func isLogColumn(expr physical.Expression) bool {
+	col, ok := expr.(*physical.ColumnExpr)
+	if !ok {
+		return false
+	}
+	return col.Ref.Type == types.ColumnTypeBuiltin && col.Ref.Column == types.ColumnNameBuiltinLog
+}
+
+func newLineFilter(op types.BinaryOp, match string) (log.Filterer, error) {
+	switch op {
+	case types.BinaryOpMatchSubstr:
+		return log.NewFilter(match, log.LineMatchEqual)
+	case types.BinaryOpNotMatchSubstr:
+		return log.NewFilter(match, log.LineMatchNotEqual)
+	case types.BinaryOpMatchRe:
+		return log.NewFilter(match, log.LineMatchRegexp)
+	case types.BinaryOpNotMatchRe:
+		return log.NewFilter(match, log.LineMatchNotRegexp)
+	case types.BinaryOpMatchPattern:
+		return log.NewFilter(match, log.LineMatchPattern)
+	case types.BinaryOpNotMatchPattern:
+		return log.NewFilter(match, log.LineMatchNotPattern)
+	default:
+		return nil, fmt.Errorf("unsupported line filter operator %s", op)
+	}
+}
+
+func matchTypeForOp(op types.BinaryOp) (labels.MatchType, error) {
+	switch op {
+	case types.BinaryOpMatchRe:
+		return labels.MatchRegexp, nil
+	case types.BinaryOpNotMatchRe:
+		return labels.MatchNotRegexp, nil
+	default:
+		return -1, fmt.Errorf("unsupported label matcher operator %s", op)
+	}
+}
+
+// columnArray returns the values of the referenced column of the batch.
+// Stream labels and metadata that do not exist in the batch resolve to an
+// array of NULLs.
+func columnArray(ref types.ColumnRef, batch *columnar.RecordBatch) (columnar.Array, error) {
+	switch ref.Type {
+	case types.ColumnTypeBuiltin:
+		idx := findColumn(batch, ref.Column, types.ColumnTypeBuiltin)
+		if idx < 0 {
+			return nil, fmt.Errorf("unknown builtin column %s", ref.Column)
+		}
+		return batch.Column(idx), nil
+
+	case types.ColumnTypeLabel, types.ColumnTypeMetadata, types.ColumnTypeParsed:
+		if idx := findColumn(batch, ref.Column, ref.Type); idx >= 0 {
+			return batch.Column(idx), nil
+		}
+		return columnar.NewNullArray(batch.NumRows()), nil
+
+	case types.ColumnTypeAmbiguous:
+		// Parsed labels take precedence over structured metadata, which
+		// takes precedence over stream labels, the same way as in the label
+		// builder of the classic engine.
+		var arrays []columnar.Array
+		for _, ty := range []types.ColumnType{types.ColumnTypeParsed, types.ColumnTypeMetadata, types.ColumnTypeLabel} {
+			if idx := findColumn(batch, ref.Column, ty); idx >= 0 {
+				arrays = append(arrays, batch.Column(idx))
+			}
+		}
+		switch len(arrays) {
+		case 0:
+			return columnar.NewNullArray(batch.NumRows()), nil
+		case 1:
+			return arrays[0], nil
+		}
+		return coalesceStrings(arrays...)
+	}
+	return nil, fmt.Errorf("unsupported column type %s", ref.Type)
+}
+
+// coalesceStrings returns an array with the first value of the given arrays
+// that is neither NULL nor empty for each row. If all values of a row are NULL
+// or empty, the row holds the value of the last array.
+func coalesceStrings(arrays ...columnar.Array) (columnar.Array, error) {
+	strs := make([]*columnar.StringArray, len(arrays))
+	for i, arr := range arrays {
+		s, ok := arr.(*columnar.StringArray)
+		if !ok {
+			return nil, fmt.Errorf("cannot coalesce %s into string", arr.DataType())
+		}
+		strs[i] = s
+	}
+
+	var (
+		b    columnar.StringBuilder
+		last = strs[len(strs)-1]
+	)
+rows:
+	for i := range last.Len() {
+		for _, s := range strs[:len(strs)-1] {
+			if !s.IsNull(i) && s.Value(i) != "" {
+				b.Append(s.Value(i))
+				continue rows
+			}
+		}
+		if last.IsNull(i) {
+			b.AppendNull()
+			continue
+		}
+		b.Append(last.Value(i))
+	}
+	return b.Build(), nil
+}
+
+// literalArray returns an array that holds the literal n times.
+func literalArray(lit types.Literal, n int) (columnar.Array, error) {
+	switch v := lit.Value.(type) {
+	case nil:
+		return columnar.NewNullArray(n), nil
+	case bool:
+		var b columnar.BoolBuilder
+		for range n {
+			b.Append(v)
+		}
+		return b.Build(), nil
+	case string:
+		var b columnar.StringBuilder
+		for range n {
+			b.Append(v)
+		}
+		return b.Build(), nil
+	case []byte:
+		var b columnar.StringBuilder
+		for range n {
+			b.AppendBytes(v)
+		}
+		return b.Build(), nil
+	case int64:
+		var b columnar.Int64Builder
+		for range n {
+			b.Append(v)
+		}
+		return b.Build(), nil
+	case float64:
+		var b columnar.Float64Builder
+		for range n {
+			b.Append(v)
+		}
+		return b.Build(), nil
+	case uint64:
+		var b columnar.TimestampBuilder
+		for range n {
+			b.Append(int64(v))
+		}
+		return b.Build(), nil
+	}
+	return nil, fmt.Errorf("unsupported literal %s", lit.String())
+}
+
+// stringValues returns an accessor for the values of a string array. NULL
+// values resolve to an empty string, mirroring the semantics of LogQL label
+// matchers. An array of type [columnar.DataTypeNull] is treated as an array
+// of empty strings. It returns false if arr is not a string or NULL array.
+func stringValues(arr columnar.Array) (func(int) string, bool) {
+	switch arr := arr.(type) {
+	case *columnar.StringArray:
+		return arr.Value, true
+	case *columnar.NullArray:
+		return func(int) string { return "" }, true
+	}
+	return nil, false
+}
+
+// compareArrays compares the values of two arrays row by row. Rows where
+// either value is NULL evaluate to NULL, except for strings, where NULL
+// values are compared as empty strings.
+func compareArrays(op types.BinaryOp, lhs, rhs columnar.Array) (columnar.Array, error) {
+	if lhs.Len() != rhs.Len() {
+		return nil, fmt.Errorf("cannot compare arrays of length %d and %d", lhs.Len(), rhs.Len())
+	}
+
+	if l, ok := stringValues(lhs); ok {
+		if r, ok := stringValues(rhs); ok {
+			var b columnar.BoolBuilder
+			for i := range lhs.Len() {
+				b.Append(compareResult(op, strings.Compare(l(i), r(i))))
+			}
+			return b.Build(), nil
+		}
+	}
+
+	if lhs.DataType() != rhs.DataType() {
+		return nil, fmt.Errorf("cannot compare %s with %s", lhs.DataType(), rhs.DataType())
+	}
+
+	var compare func(i int) int
+	switch l := lhs.(type) {
+	case *columnar.TimestampArray:
+		r := rhs.(*columnar.TimestampArray)
+		compare = func(i int) int { return cmp.Compare(l.Value(i), r.Value(i)) }
+	case *columnar.Int64Array:
+		r := rhs.(*columnar.Int64Array)
+		compare = func(i int) int { return cmp.Compare(l.Value(i), r.Value(i)) }
+	case *columnar.Float64Array:
+		r := rhs.(*columnar.Float64Array)
+		compare = func(i int) int { return cmp.Compare(l.Value(i), r.Value(i)) }
+	case *columnar.BoolArray:
+		r := rhs.(*columnar.BoolArray)
+		compare = func(i int) int { return compareBools(l.Value(i), r.Value(i)) }
+	default:
+		return nil, fmt.Errorf("cannot compare values of type %s", lhs.DataType())
+	}
+
+	var b columnar.BoolBuilder
+	for i := range lhs.Len() {
+		if lhs.IsNull(i) || rhs.IsNull(i) {
+			b.AppendNull()
+			continue
+		}
+		b.Append(compareResult(op, compare(i)))
+	}
+	return b.Build(), nil
+}
+
+func compareBools(x, y bool) int {
+	if x == y {
+		return 0
+	} else if !x {
+		return -1
+	}
+	return 1
+}
+
+func compareResult(op types.BinaryOp, res int) bool {
+	switch op {
+	case types.BinaryOpEq:
+		return res == 0
+	case types.BinaryOpNeq:
+		return res != 0
+	case types.BinaryOpGt:
+		return res > 0
+	case types.BinaryOpGte:
+		return res >= 0
+	case types.BinaryOpLt:
+		return res < 0
+	case types.BinaryOpLte:
+		return res <= 0
+	}
+	return false
+}
+
+func unsafeBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
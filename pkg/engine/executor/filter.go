@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// newFilterPipeline returns a [Pipeline] that only returns the rows of its
+// input for which all predicates evaluate to true. Batches that do not
+// contain any matching rows are skipped.
+func newFilterPipeline(input Pipeline, predicates []physical.Expression) *GenericPipeline {
+	evaluator := newExpressionEvaluator()
+
+	return newGenericPipeline(func(inputs []Pipeline) state {
+		for {
+			if err := inputs[0].Read(); err != nil {
+				return failureState(err)
+			}
+			batch, err := inputs[0].Value()
+			if err != nil {
+				return failureState(err)
+			}
+
+			for _, predicate := range predicates {
+				if batch.NumRows() == 0 {
+					break
+				}
+				mask, err := evalPredicate(evaluator, predicate, batch)
+				if err != nil {
+					return failureState(err)
+				}
+				if batch, err = columnar.Filter(batch, mask); err != nil {
+					return failureState(err)
+				}
+			}
+
+			if batch.NumRows() > 0 {
+				return successState(batch)
+			}
+		}
+	}, input)
+}
+
+func evalPredicate(evaluator *expressionEvaluator, predicate physical.Expression, batch *columnar.RecordBatch) (*columnar.BoolArray, error) {
+	res, err := evaluator.eval(predicate, batch)
+	if err != nil {
+		return nil, err
+	}
+	mask, ok := res.(*columnar.BoolArray)
+	if !ok {
+		return nil, fmt.Errorf("predicate %s does not evaluate to a boolean", predicate)
+	}
+	return mask, nil
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+func TestFilter(t *testing.T) {
+	input := func() Pipeline {
+		r := row(4, "level=error msg=timeout", "app", "foo")
+		r.metadata = labels.FromStrings("trace_id", "123")
+		return newBufferedPipeline(
+			batch(row(1, "level=info msg=ok", "app", "foo"), row(2, "level=error msg=failed", "app", "bar")),
+			batch(row(3, "level=info msg=slow", "app", "bar")),
+			batch(r),
+		)
+	}
+
+	column := func(name string, ty types.ColumnType) *physical.ColumnExpr {
+		return &physical.ColumnExpr{Ref: types.ColumnRef{Column: name, Type: ty}}
+	}
+
+	for _, tt := range []struct {
+		name       string
+		predicates []physical.Expression
+		expected   []string
+	}{
+		{
+			name: "line contains",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{Left: column(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin), Right: physical.NewLiteral("error"), Op: types.BinaryOpMatchSubstr},
+			},
+			expected: []string{"level=error msg=failed", "level=error msg=timeout"},
+		},
+		{
+			name: "line regex is not anchored",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{Left: column(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin), Right: physical.NewLiteral("msg=(ok|slow)"), Op: types.BinaryOpMatchRe},
+			},
+			expected: []string{"level=info msg=ok", "level=info msg=slow"},
+		},
+		{
+			name: "label regex is anchored",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{Left: column("app", types.ColumnTypeLabel), Right: physical.NewLiteral("ba"), Op: types.BinaryOpMatchRe},
+			},
+			expected: nil,
+		},
+		{
+			name: "ambiguous column resolves metadata",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{Left: column("trace_id", types.ColumnTypeAmbiguous), Right: physical.NewLiteral("123"), Op: types.BinaryOpEq},
+			},
+			expected: []string{"level=error msg=timeout"},
+		},
+		{
+			name: "multiple predicates",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{Left: column("app", types.ColumnTypeAmbiguous), Right: physical.NewLiteral("bar"), Op: types.BinaryOpEq},
+				&physical.BinaryExpr{Left: column(types.ColumnNameBuiltinTimestamp, types.ColumnTypeBuiltin), Right: physical.NewLiteral(uint64(3_000_000_000)), Op: types.BinaryOpLt},
+			},
+			expected: []string{"level=error msg=failed"},
+		},
+		{
+			name: "logical or",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{
+					Left:  &physical.BinaryExpr{Left: column("app", types.ColumnTypeLabel), Right: physical.NewLiteral("foo"), Op: types.BinaryOpEq},
+					Right: &physical.BinaryExpr{Left: column(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin), Right: physical.NewLiteral("slow"), Op: types.BinaryOpMatchSubstr},
+					Op:    types.BinaryOpOr,
+				},
+			},
+			expected: []string{"level=info msg=ok", "level=info msg=slow", "level=error msg=timeout"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := newFilterPipeline(input(), tt.predicates)
+			defer pipeline.Close()
+
+			require.Equal(t, tt.expected, collect(t, pipeline))
+		})
+	}
+}
+
+func TestFilter_NonBooleanPredicate(t *testing.T) {
+	pipeline := newFilterPipeline(newBufferedPipeline(batch(row(1, "a"))), []physical.Expression{physical.NewLiteral("foo")})
+	defer pipeline.Close()
+
+	err := pipeline.Read()
+	require.ErrorContains(t, err, "does not evaluate to a boolean")
+}
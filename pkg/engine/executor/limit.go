@@ -0,0 +1,42 @@
+package executor
+
+// newLimitPipeline returns a [Pipeline] that skips the first skip rows of its
+// input and returns at most fetch rows afterwards. A fetch of 0 returns all
+// remaining rows.
+func newLimitPipeline(input Pipeline, skip, fetch uint32) *GenericPipeline {
+	var (
+		skipped  uint32
+		returned uint32
+	)
+
+	return newGenericPipeline(func(inputs []Pipeline) state {
+		for {
+			if fetch > 0 && returned >= fetch {
+				return exhausted
+			}
+
+			if err := inputs[0].Read(); err != nil {
+				return failureState(err)
+			}
+			batch, err := inputs[0].Value()
+			if err != nil {
+				return failureState(err)
+			}
+
+			start, end := uint32(0), uint32(batch.NumRows())
+			if remaining := skip - skipped; remaining > 0 {
+				start = min(end, remaining)
+				skipped += start
+			}
+			if fetch > 0 {
+				end = min(end, start+fetch-returned)
+			}
+			if start == end {
+				continue
+			}
+
+			returned += end - start
+			return successState(batch.Slice(int(start), int(end)))
+		}
+	}, input)
+}
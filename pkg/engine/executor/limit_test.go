@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+)
+
+func TestLimit(t *testing.T) {
+	batches := func() []*columnar.RecordBatch {
+		return []*columnar.RecordBatch{
+			batch(row(1, "a"), row(2, "b"), row(3, "c")),
+			batch(row(4, "d"), row(5, "e")),
+			batch(row(6, "f")),
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		skip, fetch uint32
+		expected    []string
+	}{
+		{name: "no skip, no fetch", skip: 0, fetch: 0, expected: []string{"a", "b", "c", "d", "e", "f"}},
+		{name: "fetch within first batch", skip: 0, fetch: 2, expected: []string{"a", "b"}},
+		{name: "fetch across batches", skip: 0, fetch: 4, expected: []string{"a", "b", "c", "d"}},
+		{name: "skip across batches", skip: 4, fetch: 0, expected: []string{"e", "f"}},
+		{name: "skip and fetch", skip: 2, fetch: 3, expected: []string{"c", "d", "e"}},
+		{name: "skip all", skip: 10, fetch: 3, expected: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			input := newBufferedPipeline(batches()...)
+			pipeline := newLimitPipeline(input, tt.skip, tt.fetch)
+			defer pipeline.Close()
+
+			require.Equal(t, tt.expected, collect(t, pipeline))
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// newParsePipeline returns a [Pipeline] that extracts labels from the log
+// line of each row of its input using the parsers of the classic engine.
+// Extracted labels, including the error labels set by a failing parser, are
+// returned as parsed columns. Parsed columns of the input are passed to the
+// parser as well, so they are retained or overwritten the same way as in the
+// classic engine.
+func newParsePipeline(input Pipeline, node *physical.Parse) (*GenericPipeline, error) {
+	stage, err := newParserStage(node)
+	if err != nil {
+		return nil, err
+	}
+	parser := &rowParser{
+		stage:        stage,
+		baseBuilder:  log.NewBaseLabelsBuilder(),
+		replaceLines: node.Kind == types.ParserTypeUnpack,
+	}
+
+	return newGenericPipeline(func(inputs []Pipeline) state {
+		if err := inputs[0].Read(); err != nil {
+			return failureState(err)
+		}
+		batch, err := inputs[0].Value()
+		if err != nil {
+			return failureState(err)
+		}
+
+		parsed, err := parser.parse(batch)
+		if err != nil {
+			return failureState(err)
+		}
+		return successState(parsed)
+	}, input), nil
+}
+
+// newParserStage returns the stage of the classic engine that implements the
+// parser of the node.
+func newParserStage(node *physical.Parse) (log.Stage, error) {
+	switch node.Kind {
+	case types.ParserTypeJSON:
+		if len(node.Extractions) > 0 {
+			return log.NewJSONExpressionParser(node.Extractions)
+		}
+		return log.NewJSONParser(false), nil
+	case types.ParserTypeLogfmt:
+		if len(node.Extractions) > 0 {
+			return log.NewLogfmtExpressionParser(node.Extractions, node.Strict)
+		}
+		return log.NewLogfmtParser(node.Strict, node.KeepEmpty), nil
+	case types.ParserTypeRegexp:
+		return log.NewRegexpParser(node.Expression)
+	case types.ParserTypePattern:
+		return log.NewPatternParser(node.Expression)
+	case types.ParserTypeUnpack:
+		return log.NewUnpackParser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported parser %s", node.Kind)
+	}
+}
+
+// rowParser applies a parser stage on the rows of batches.
+type rowParser struct {
+	stage        log.Stage
+	baseBuilder  *log.BaseLabelsBuilder
+	replaceLines bool // Whether the parser may modify the log line, e.g. unpack.
+}
+
+// parse returns a copy of the batch with all parsed columns replaced by the
+// labels extracted from each row.
+func (p *rowParser) parse(batch *columnar.RecordBatch) (*columnar.RecordBatch, error) {
+	timestamps := timestampColumn(batch)
+	if timestamps == nil {
+		return nil, errors.New("parse requires a timestamp column")
+	}
+	logIdx := findColumn(batch, types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin)
+	if logIdx < 0 {
+		return nil, errors.New("parse requires a log column")
+	}
+	lines, ok := batch.Column(logIdx).(*columnar.StringArray)
+	if !ok {
+		return nil, fmt.Errorf("invalid log column type %s", batch.Column(logIdx).DataType())
+	}
+
+	var (
+		streamLabels   = categoryLabelsFunc(batch, types.ColumnTypeLabel)
+		metadataLabels = categoryLabelsFunc(batch, types.ColumnTypeMetadata)
+		parsedLabels   = categoryLabelsFunc(batch, types.ColumnTypeParsed)
+
+		columns   = newDynamicColumns()
+		lineCol   columnar.StringBuilder
+		parsedBuf labels.Labels
+	)
+
+	for i := range batch.NumRows() {
+		lbs := streamLabels(i)
+		p.baseBuilder.Reset()
+		builder := p.baseBuilder.ForLabels(lbs, lbs.Hash())
+		builder.Add(log.StructuredMetadataLabel, metadataLabels(i))
+		builder.Add(log.ParsedLabel, parsedLabels(i))
+
+		line, _ := p.stage.Process(timestamps.Value(i), lines.Bytes(i), builder)
+		if p.replaceLines {
+			lineCol.AppendBytes(line)
+		}
+
+		parsedBuf = builder.UnsortedLabels(parsedBuf, log.ParsedLabel)
+		for _, l := range parsedBuf {
+			columns.set(i, l.Name, l.Value)
+		}
+		columns.endRow(i)
+	}
+
+	var (
+		fields  []columnar.Field
+		arrays  []columnar.Array
+		schema  = batch.Schema()
+		numRows = batch.NumRows()
+	)
+	for i := range batch.NumCols() {
+		field := schema.Field(i)
+		if types.ColumnTypeOf(field) == types.ColumnTypeParsed {
+			continue
+		}
+		col := batch.Column(i)
+		if i == logIdx && p.replaceLines {
+			col = lineCol.Build()
+		}
+		fields = append(fields, field)
+		arrays = append(arrays, col)
+	}
+	for _, name := range columns.names() {
+		fields = append(fields, newField(name, types.ColumnTypeParsed, columnar.DataTypeString))
+		arrays = append(arrays, columns.build(name))
+	}
+	return columnar.NewRecordBatch(columnar.NewSchema(fields...), numRows, arrays)
+}
+
+// categoryLabelsFunc returns a function that returns the non-empty values of
+// all columns of the given column type of a row as labels.
+func categoryLabelsFunc(batch *columnar.RecordBatch, ty types.ColumnType) func(i int) labels.Labels {
+	var (
+		names  []string
+		values []*columnar.StringArray
+	)
+	for i := range batch.NumCols() {
+		field := batch.Schema().Field(i)
+		if types.ColumnTypeOf(field) != ty {
+			continue
+		}
+		if col, ok := batch.Column(i).(*columnar.StringArray); ok {
+			names = append(names, field.Name)
+			values = append(values, col)
+		}
+	}
+
+	builder := labels.NewScratchBuilder(len(names))
+	return func(i int) labels.Labels {
+		builder.Reset()
+		for c, col := range values {
+			if !col.IsNull(i) && col.Value(i) != "" {
+				builder.Add(names[c], col.Value(i))
+			}
+		}
+		builder.Sort()
+		return builder.Labels()
+	}
+}
+
+// dynamicColumns builds string columns whose names are only known once the
+// rows are processed. Rows without a value for a column are NULL.
+type dynamicColumns struct {
+	index    map[string]int
+	builders []*columnar.StringBuilder
+}
+
+func newDynamicColumns() *dynamicColumns {
+	return &dynamicColumns{index: make(map[string]int)}
+}
+
+// set sets the value of the column name for row i.
+func (c *dynamicColumns) set(i int, name, value string) {
+	idx, ok := c.index[name]
+	if !ok {
+		idx = len(c.builders)
+		c.index[name] = idx
+		builder := &columnar.StringBuilder{}
+		for range i {
+			builder.AppendNull()
+		}
+		c.builders = append(c.builders, builder)
+	}
+	if c.builders[idx].Len() > i {
+		return // The first value of a row wins.
+	}
+	c.builders[idx].Append(value)
+}
+
+// endRow appends NULLs to all columns without a value for row i.
+func (c *dynamicColumns) endRow(i int) {
+	for _, builder := range c.builders {
+		if builder.Len() <= i {
+			builder.AppendNull()
+		}
+	}
+}
+
+// names returns the sorted names of all columns.
+func (c *dynamicColumns) names() []string {
+	names := make([]string, 0, len(c.index))
+	for name := range c.index {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// build returns the column with the given name.
+func (c *dynamicColumns) build(name string) columnar.Array {
+	return c.builders[c.index[name]].Build()
+}
@@ -0,0 +1,150 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		node     *physical.Parse
+		input    *columnar.RecordBatch
+		expected []string
+	}{
+		{
+			name: "json",
+			node: &physical.Parse{Kind: types.ParserTypeJSON},
+			input: batch(
+				row(1, `{"level":"info","app":"api","req":{"status":200}}`, "app", "foo"),
+				row(2, `{"level":"error"}`, "app", "foo"),
+			),
+			expected: []string{
+				`{app="foo", app_extracted="api", level="info", req_status="200"}`,
+				`{app="foo", level="error"}`,
+			},
+		},
+		{
+			name: "json with extractions",
+			node: &physical.Parse{
+				Kind:        types.ParserTypeJSON,
+				Extractions: []log.LabelExtractionExpr{{Identifier: "status", Expression: "req.status"}},
+			},
+			input: batch(
+				row(1, `{"level":"info","req":{"status":200}}`, "app", "foo"),
+			),
+			expected: []string{
+				`{app="foo", status="200"}`,
+			},
+		},
+		{
+			name: "logfmt with parse error",
+			node: &physical.Parse{Kind: types.ParserTypeLogfmt, Strict: true},
+			input: batch(
+				row(1, `level=info msg="hello world"`, "app", "foo"),
+				row(2, `level=warn msg="unterminated`, "app", "foo"),
+			),
+			expected: []string{
+				`{app="foo", level="info", msg="hello world"}`,
+				`{__error__="LogfmtParserErr", __error_details__="logfmt syntax error at pos 29 : unterminated quoted value", app="foo", level="warn"}`,
+			},
+		},
+		{
+			name: "regexp",
+			node: &physical.Parse{Kind: types.ParserTypeRegexp, Expression: `took (?P<duration>\S+)`},
+			input: batch(
+				row(1, `request took 5ms`, "app", "foo"),
+				row(2, `no match`, "app", "foo"),
+			),
+			expected: []string{
+				`{app="foo", duration="5ms"}`,
+				`{app="foo"}`,
+			},
+		},
+		{
+			name: "pattern",
+			node: &physical.Parse{Kind: types.ParserTypePattern, Expression: `<method> <path> <_>`},
+			input: batch(
+				row(1, `GET /api 200`, "app", "foo"),
+			),
+			expected: []string{
+				`{app="foo", method="GET", path="/api"}`,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := newParsePipeline(newBufferedPipeline(tt.input), tt.node)
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			require.Equal(t, tt.expected, collectLabels(t, pipeline))
+		})
+	}
+}
+
+func TestParse_Chained(t *testing.T) {
+	input := newBufferedPipeline(batch(
+		row(1, `{"level":"info","msg":"status=200 path=/api"}`, "app", "foo"),
+	))
+
+	first, err := newParsePipeline(input, &physical.Parse{Kind: types.ParserTypeJSON})
+	require.NoError(t, err)
+	second, err := newParsePipeline(first, &physical.Parse{Kind: types.ParserTypeLogfmt})
+	require.NoError(t, err)
+	defer second.Close()
+
+	// The labels extracted by the json parser are retained by the logfmt
+	// parser, which extracts nothing from the JSON line.
+	require.Equal(t, []string{
+		`{app="foo", level="info", msg="status=200 path=/api"}`,
+	}, collectLabels(t, second))
+}
+
+func TestParse_Unpack(t *testing.T) {
+	input := newBufferedPipeline(batch(
+		row(1, `{"_entry":"original line","pod":"a"}`, "app", "foo"),
+	))
+
+	pipeline, err := newParsePipeline(input, &physical.Parse{Kind: types.ParserTypeUnpack})
+	require.NoError(t, err)
+	defer pipeline.Close()
+
+	require.NoError(t, pipeline.Read())
+	batch, err := pipeline.Value()
+	require.NoError(t, err)
+
+	lines := batch.Column(findColumn(batch, types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin)).(*columnar.StringArray)
+	require.Equal(t, "original line", lines.Value(0))
+	require.Equal(t, `{app="foo", pod="a"}`, rowLabelsFunc(batch)(0).String())
+	require.ErrorIs(t, pipeline.Read(), EOF)
+}
+
+// collectLabels reads all batches of the pipeline and returns the labels of
+// all their rows.
+func collectLabels(t *testing.T, p Pipeline) []string {
+	t.Helper()
+
+	var result []string
+	for {
+		err := p.Read()
+		if errors.Is(err, EOF) {
+			return result
+		}
+		require.NoError(t, err)
+
+		batch, err := p.Value()
+		require.NoError(t, err)
+
+		lbs := rowLabelsFunc(batch)
+		for i := range batch.NumRows() {
+			result = append(result, lbs(i).String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"errors"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+)
+
+// EOF is returned by [Pipeline.Read] once a pipeline is exhausted and has no
+// more batches to return.
+var EOF = errors.New("pipeline exhausted") //nolint:revive,staticcheck
+
+// Pipeline represents a data processing pipeline that can read batches of
+// rows. A pipeline is pull-based: calling Read on the root pipeline pulls
+// data from its inputs until the root pipeline can produce a new batch.
+//
+// Batches are [columnar.RecordBatch] values. The column type (builtin, label,
+// or metadata) of each field of a batch is stored in its field metadata.
+type Pipeline interface {
+	// Read reads the next batch into the state of the pipeline. It returns
+	// [EOF] once the pipeline is exhausted, or any other error that occurred
+	// while reading.
+	Read() error
+	// Value returns the current batch of the pipeline. The batch is only
+	// valid until the next call to Read.
+	Value() (*columnar.RecordBatch, error)
+	// Close closes the pipeline and its inputs and releases any resources
+	// held by it.
+	Close()
+}
+
+// state holds the current value of a pipeline.
+type state struct {
+	batch *columnar.RecordBatch
+	err   error
+}
+
+func (s state) Value() (*columnar.RecordBatch, error) {
+	return s.batch, s.err
+}
+
+func failureState(err error) state {
+	return state{err: err}
+}
+
+func successState(batch *columnar.RecordBatch) state {
+	return state{batch: batch}
+}
+
+var exhausted = failureState(EOF)
+
+// GenericPipeline is a [Pipeline] that delegates reading to a read function,
+// which is called with the inputs of the pipeline.
+type GenericPipeline struct {
+	inputs []Pipeline
+	read   func([]Pipeline) state
+	state  state
+}
+
+func newGenericPipeline(read func([]Pipeline) state, inputs ...Pipeline) *GenericPipeline {
+	return &GenericPipeline{
+		inputs: inputs,
+		read:   read,
+	}
+}
+
+var _ Pipeline = (*GenericPipeline)(nil)
+
+// Read implements [Pipeline].
+func (p *GenericPipeline) Read() error {
+	if p.read == nil {
+		return EOF
+	}
+	p.state = p.read(p.inputs)
+	return p.state.err
+}
+
+// Value implements [Pipeline].
+func (p *GenericPipeline) Value() (*columnar.RecordBatch, error) {
+	return p.state.Value()
+}
+
+// Close implements [Pipeline].
+func (p *GenericPipeline) Close() {
+	for _, inp := range p.inputs {
+		inp.Close()
+	}
+}
+
+func errorPipeline(err error) Pipeline {
+	return newGenericPipeline(func(_ []Pipeline) state {
+		return failureState(err)
+	})
+}
+
+func emptyPipeline() Pipeline {
+	return newGenericPipeline(func(_ []Pipeline) state {
+		return exhausted
+	})
+}
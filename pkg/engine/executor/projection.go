@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// newProjectPipeline returns a [Pipeline] that removes all label and
+// metadata columns from the batches of its input that are not referenced by
+// the given columns. Builtin columns are always retained.
+func newProjectPipeline(input Pipeline, columns []physical.ColumnExpression) *GenericPipeline {
+	keep := make(map[types.ColumnRef]struct{})
+	for _, col := range columns {
+		expr, ok := col.(*physical.ColumnExpr)
+		if !ok {
+			continue
+		}
+		switch expr.Ref.Type {
+		case types.ColumnTypeAmbiguous:
+			keep[types.ColumnRef{Column: expr.Ref.Column, Type: types.ColumnTypeLabel}] = struct{}{}
+			keep[types.ColumnRef{Column: expr.Ref.Column, Type: types.ColumnTypeMetadata}] = struct{}{}
+			keep[types.ColumnRef{Column: expr.Ref.Column, Type: types.ColumnTypeParsed}] = struct{}{}
+		default:
+			keep[expr.Ref] = struct{}{}
+		}
+	}
+
+	return newGenericPipeline(func(inputs []Pipeline) state {
+		if err := inputs[0].Read(); err != nil {
+			return failureState(err)
+		}
+		batch, err := inputs[0].Value()
+		if err != nil {
+			return failureState(err)
+		}
+
+		var (
+			fields  []columnar.Field
+			arrays  []columnar.Array
+			schema  = batch.Schema()
+			removed bool
+		)
+		for i := range batch.NumCols() {
+			field := schema.Field(i)
+			ty := types.ColumnTypeOf(field)
+			if _, ok := keep[types.ColumnRef{Column: field.Name, Type: ty}]; !ok && ty != types.ColumnTypeBuiltin {
+				removed = true
+				continue
+			}
+			fields = append(fields, field)
+			arrays = append(arrays, batch.Column(i))
+		}
+		if !removed {
+			return successState(batch)
+		}
+
+		projected, err := columnar.NewRecordBatch(columnar.NewSchema(fields...), batch.NumRows(), arrays)
+		if err != nil {
+			return failureState(err)
+		}
+		return successState(projected)
+	}, input)
+}
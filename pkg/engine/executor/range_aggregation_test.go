@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+func TestRangeAggregation(t *testing.T) {
+	inputs := func() []Pipeline {
+		return []Pipeline{
+			newBufferedPipeline(
+				batch(row(1, "a", "app", "foo"), row(5, "bb", "app", "foo")),
+				batch(row(10, "ccc", "app", "foo")),
+			),
+			newBufferedPipeline(
+				batch(row(3, "dddd", "app", "bar", "env", "prod"), row(11, "e", "app", "bar", "env", "prod")),
+			),
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		operation   types.RangeAggregationType
+		partitionBy []physical.ColumnExpression
+		start, end  int64 // Timestamps in seconds.
+		step        time.Duration
+		expected    []string
+	}{
+		{
+			name:      "count over time",
+			operation: types.RangeAggregationTypeCount,
+			start:     5, end: 15, step: 5 * time.Second,
+			expected: []string{
+				`{app="bar", env="prod"} @5 = 1`,
+				`{app="bar", env="prod"} @15 = 1`,
+				`{app="foo"} @5 = 2`,
+				`{app="foo"} @10 = 1`,
+			},
+		},
+		{
+			name:      "bytes rate",
+			operation: types.RangeAggregationTypeBytesRate,
+			start:     5, end: 15, step: 5 * time.Second,
+			expected: []string{
+				`{app="bar", env="prod"} @5 = 0.8`,
+				`{app="bar", env="prod"} @15 = 0.2`,
+				`{app="foo"} @5 = 0.6`,
+				`{app="foo"} @10 = 0.6`,
+			},
+		},
+		{
+			name:        "partition by",
+			operation:   types.RangeAggregationTypeRate,
+			partitionBy: []physical.ColumnExpression{&physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}}},
+			start:       5, end: 15, step: 5 * time.Second,
+			expected: []string{
+				`{} @5 = 0.4`,
+				`{} @10 = 0.2`,
+				`{env="prod"} @5 = 0.2`,
+				`{env="prod"} @15 = 0.2`,
+			},
+		},
+		{
+			name:      "instant query",
+			operation: types.RangeAggregationTypeCount,
+			start:     10, end: 10,
+			expected: []string{
+				`{app="foo"} @10 = 1`,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &physical.RangeAggregation{
+				Operation:   tt.operation,
+				PartitionBy: tt.partitionBy,
+				Start:       time.Unix(tt.start, 0),
+				End:         time.Unix(tt.end, 0),
+				Step:        tt.step,
+				Range:       5 * time.Second,
+			}
+			pipeline, err := newRangeAggregationPipeline(inputs(), node, 2)
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			require.Equal(t, tt.expected, collectSamples(t, pipeline))
+		})
+	}
+}
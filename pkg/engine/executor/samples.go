@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// seriesSet accumulates values of type T per series and timestamp.
+type seriesSet[T any] struct {
+	series map[string]*series[T]
+}
+
+// series holds the accumulated values of a single series, keyed by
+// timestamp in nanoseconds.
+type series[T any] struct {
+	labels labels.Labels
+	values map[int64]*T
+}
+
+func newSeriesSet[T any]() *seriesSet[T] {
+	return &seriesSet[T]{series: make(map[string]*series[T])}
+}
+
+// get returns the accumulator of the series with the given labels at
+// timestamp ts, creating it if it does not exist yet.
+func (s *seriesSet[T]) get(lbs labels.Labels, ts int64) *T {
+	key := lbs.String()
+	ser, ok := s.series[key]
+	if !ok {
+		ser = &series[T]{labels: lbs, values: make(map[int64]*T)}
+		s.series[key] = ser
+	}
+	acc, ok := ser.values[ts]
+	if !ok {
+		acc = new(T)
+		ser.values[ts] = acc
+	}
+	return acc
+}
+
+// samplesBatch converts the series of the set into a batch of samples. The
+// batch has a builtin timestamp and value column, and one label column per
+// label name of any of the series. Rows are sorted by series labels and
+// timestamp. The value of each sample is computed by calling value with its
+// accumulator.
+func samplesBatch[T any](set *seriesSet[T], value func(*T) float64) (*columnar.RecordBatch, error) {
+	all := make([]*series[T], 0, len(set.series))
+	names := make(map[string]struct{})
+	for _, ser := range set.series {
+		all = append(all, ser)
+		ser.labels.Range(func(l labels.Label) { names[l.Name] = struct{}{} })
+	}
+	slices.SortFunc(all, func(a, b *series[T]) int { return labels.Compare(a.labels, b.labels) })
+
+	labelNames := make([]string, 0, len(names))
+	for name := range names {
+		labelNames = append(labelNames, name)
+	}
+	slices.Sort(labelNames)
+
+	var (
+		timestamps columnar.TimestampBuilder
+		values     columnar.Float64Builder
+		labelCols  = make([]columnar.StringBuilder, len(labelNames))
+		rows       int
+	)
+	for _, ser := range all {
+		steps := make([]int64, 0, len(ser.values))
+		for ts := range ser.values {
+			steps = append(steps, ts)
+		}
+		slices.Sort(steps)
+
+		for _, ts := range steps {
+			timestamps.Append(ts)
+			values.Append(value(ser.values[ts]))
+			for i, name := range labelNames {
+				if v := ser.labels.Get(name); v != "" {
+					labelCols[i].Append(v)
+					continue
+				}
+				labelCols[i].AppendNull()
+			}
+			rows++
+		}
+	}
+
+	fields := []columnar.Field{timestampField, valueField}
+	columns := []columnar.Array{timestamps.Build(), values.Build()}
+	for i, name := range labelNames {
+		fields = append(fields, newField(name, types.ColumnTypeLabel, columnar.DataTypeString))
+		columns = append(columns, labelCols[i].Build())
+	}
+	return columnar.NewRecordBatch(columnar.NewSchema(fields...), rows, columns)
+}
+
+// newSliceReader returns a read function for a [GenericPipeline] that calls
+// compute once to obtain its result and returns the result in batches of at
+// most batchSize rows.
+func newSliceReader(compute func([]Pipeline) (*columnar.RecordBatch, error), batchSize int64) func([]Pipeline) state {
+	var (
+		result *columnar.RecordBatch
+		offset int
+	)
+
+	return func(inputs []Pipeline) state {
+		if result == nil {
+			var err error
+			if result, err = compute(inputs); err != nil {
+				return failureState(err)
+			}
+		}
+		if offset >= result.NumRows() {
+			return exhausted
+		}
+
+		end := result.NumRows()
+		if batchSize > 0 {
+			end = min(end, offset+int(batchSize))
+		}
+		batch := result.Slice(offset, end)
+		offset = end
+		return successState(batch)
+	}
+}
+
+// readAll reads all batches of the input and calls fn for each of them.
+func readAll(input Pipeline, fn func(*columnar.RecordBatch) error) error {
+	for {
+		if err := input.Read(); err != nil {
+			if errors.Is(err, EOF) {
+				return nil
+			}
+			return err
+		}
+		batch, err := input.Value()
+		if err != nil {
+			return err
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// SortMerge is a [Pipeline] that performs a k-way merge of its inputs. Each
+// input is required to return its rows already sorted by the sort column
+// in the requested order.
+//
+// Inputs with a known bound are only read once the next row of the merge
+// cannot precede their first row.
+type SortMerge struct {
+	inputs    []Pipeline
+	order     physical.SortOrder
+	batchSize int64
+	bounds    []time.Time // timestamp of the first row of each input; zero if unknown
+
+	initialized bool
+	sequence    []int                      // order in which inputs are visited
+	opened      []bool                     // whether an input has been read from
+	batches     []*columnar.RecordBatch    // current batch of each input
+	timestamps  []*columnar.TimestampArray // timestamp column of the current batch of each input
+	offsets     []int                      // offset of the next row in the current batch of each input
+	exhausted   []bool                     // whether an input is exhausted
+
+	state state
+}
+
+var _ Pipeline = (*SortMerge)(nil)
+
+func newSortMergePipeline(inputs []Pipeline, order physical.SortOrder, column physical.ColumnExpression, batchSize int64) (*SortMerge, error) {
+	expr, ok := column.(*physical.ColumnExpr)
+	if !ok {
+		return nil, fmt.Errorf("invalid column expression type %T", column)
+	}
+	if expr.Ref.Type != types.ColumnTypeBuiltin || expr.Ref.Column != types.ColumnNameBuiltinTimestamp {
+		return nil, fmt.Errorf("sort merge is only supported on the timestamp column, got %s", expr.Ref.String())
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	return &SortMerge{
+		inputs:    inputs,
+		order:     order,
+		batchSize: batchSize,
+	}, nil
+}
+
+// withBounds sets the timestamp of the first row of each input in the sort
+// order. The first row of inputs with a zero bound is unknown.
+func (p *SortMerge) withBounds(bounds []time.Time) *SortMerge {
+	p.bounds = bounds
+	return p
+}
+
+func (p *SortMerge) init() {
+	// Inputs are visited in the order of their bounds, starting with the
+	// inputs without a bound.
+	p.sequence = make([]int, len(p.inputs))
+	for i := range p.sequence {
+		p.sequence[i] = i
+	}
+	slices.SortStableFunc(p.sequence, func(a, b int) int {
+		boundA, boundB := p.bound(a), p.bound(b)
+		switch {
+		case boundA.IsZero() && boundB.IsZero():
+			return 0
+		case boundA.IsZero():
+			return -1
+		case boundB.IsZero():
+			return 1
+		case p.order == physical.DESC:
+			return boundB.Compare(boundA)
+		default:
+			return boundA.Compare(boundB)
+		}
+	})
+
+	p.opened = make([]bool, len(p.inputs))
+	p.batches = make([]*columnar.RecordBatch, len(p.inputs))
+	p.timestamps = make([]*columnar.TimestampArray, len(p.inputs))
+	p.offsets = make([]int, len(p.inputs))
+	p.exhausted = make([]bool, len(p.inputs))
+	p.initialized = true
+}
+
+// Read implements [Pipeline].
+func (p *SortMerge) Read() error {
+	if !p.initialized {
+		p.init()
+	}
+	p.state = p.read()
+	return p.state.err
+}
+
+func (p *SortMerge) read() state {
+	builder := columnar.NewRecordBuilder()
+
+	for int64(builder.NumRows()) < p.batchSize {
+		next := -1
+		for _, i := range p.sequence {
+			// Skip inputs whose rows cannot precede the current candidate.
+			// Since inputs are visited in the order of their bounds, this
+			// defers reading them until their rows are needed.
+			if !p.opened[i] && next >= 0 && !p.bound(i).IsZero() &&
+				p.less(p.timestamps[next].Value(p.offsets[next]), p.bound(i).UnixNano()) {
+				continue
+			}
+			p.opened[i] = true
+			if err := p.fill(i); err != nil {
+				return failureState(err)
+			}
+			if p.exhausted[i] {
+				continue
+			}
+			if next < 0 || p.less(p.timestamps[i].Value(p.offsets[i]), p.timestamps[next].Value(p.offsets[next])) {
+				next = i
+			}
+		}
+
+		// All inputs are exhausted.
+		if next < 0 {
+			break
+		}
+
+		if err := builder.AppendRow(p.batches[next], p.offsets[next]); err != nil {
+			return failureState(err)
+		}
+		p.offsets[next]++
+	}
+
+	if builder.NumRows() == 0 {
+		return exhausted
+	}
+	return successState(builder.Build())
+}
+
+// fill makes sure that input i has a row available, unless it is exhausted.
+func (p *SortMerge) fill(i int) error {
+	for !p.exhausted[i] && (p.batches[i] == nil || p.offsets[i] >= p.batches[i].NumRows()) {
+		if err := p.inputs[i].Read(); err != nil {
+			if errors.Is(err, EOF) {
+				p.exhausted[i] = true
+				p.batches[i], p.timestamps[i] = nil, nil
+				return nil
+			}
+			return err
+		}
+		batch, err := p.inputs[i].Value()
+		if err != nil {
+			return err
+		}
+		timestamps := timestampColumn(batch)
+		if timestamps == nil {
+			return errors.New("sort merge input is missing the timestamp column")
+		}
+		p.batches[i], p.timestamps[i] = batch, timestamps
+		p.offsets[i] = 0
+	}
+	return nil
+}
+
+// bound returns the bound of input i, or the zero time if it is unknown.
+func (p *SortMerge) bound(i int) time.Time {
+	if i >= len(p.bounds) {
+		return time.Time{}
+	}
+	return p.bounds[i]
+}
+
+func (p *SortMerge) less(a, b int64) bool {
+	if p.order == physical.DESC {
+		return a > b
+	}
+	return a < b
+}
+
+// Value implements [Pipeline].
+func (p *SortMerge) Value() (*columnar.RecordBatch, error) {
+	return p.state.Value()
+}
+
+// Close implements [Pipeline].
+func (p *SortMerge) Close() {
+	for _, input := range p.inputs {
+		input.Close()
+	}
+}
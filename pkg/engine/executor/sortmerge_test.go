@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+func TestSortMerge(t *testing.T) {
+	timestamp := &physical.ColumnExpr{Ref: types.ColumnRef{Column: types.ColumnNameBuiltinTimestamp, Type: types.ColumnTypeBuiltin}}
+
+	t.Run("ascending", func(t *testing.T) {
+		inputs := []Pipeline{
+			newBufferedPipeline(batch(row(1, "a"), row(4, "d")), batch(row(7, "g"))),
+			newBufferedPipeline(batch(row(2, "b")), batch(row(5, "e"), row(6, "f"))),
+			newBufferedPipeline(batch(row(3, "c"))),
+			newBufferedPipeline(),
+		}
+		pipeline, err := newSortMergePipeline(inputs, physical.ASC, timestamp, 2)
+		require.NoError(t, err)
+		defer pipeline.Close()
+
+		require.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, collect(t, pipeline))
+	})
+
+	t.Run("descending", func(t *testing.T) {
+		inputs := []Pipeline{
+			newBufferedPipeline(batch(row(7, "g"), row(4, "d")), batch(row(1, "a"))),
+			newBufferedPipeline(batch(row(6, "f"), row(5, "e"), row(2, "b"))),
+			newBufferedPipeline(batch(row(3, "c"))),
+		}
+		pipeline, err := newSortMergePipeline(inputs, physical.DESC, timestamp, 3)
+		require.NoError(t, err)
+		defer pipeline.Close()
+
+		require.Equal(t, []string{"g", "f", "e", "d", "c", "b", "a"}, collect(t, pipeline))
+	})
+
+	t.Run("bounded inputs", func(t *testing.T) {
+		late := newBufferedPipeline(batch(row(5, "e"), row(6, "f")))
+		early := newBufferedPipeline(batch(row(1, "a"), row(2, "b")))
+		unbounded := newBufferedPipeline(batch(row(3, "c")))
+
+		pipeline, err := newSortMergePipeline([]Pipeline{late, early, unbounded}, physical.ASC, timestamp, 2)
+		require.NoError(t, err)
+		pipeline.withBounds([]time.Time{time.Unix(5, 0), time.Unix(1, 0), {}})
+		defer pipeline.Close()
+
+		// The input with the later bound is not read until its rows are
+		// needed.
+		require.NoError(t, pipeline.Read())
+		require.Len(t, late.batches, 1)
+
+		require.Equal(t, []string{"c", "e", "f"}, collect(t, pipeline))
+	})
+
+	t.Run("bounded inputs descending", func(t *testing.T) {
+		late := newBufferedPipeline(batch(row(2, "b"), row(1, "a")))
+		early := newBufferedPipeline(batch(row(6, "f"), row(5, "e")))
+
+		pipeline, err := newSortMergePipeline([]Pipeline{late, early}, physical.DESC, timestamp, 2)
+		require.NoError(t, err)
+		pipeline.withBounds([]time.Time{time.Unix(2, 0), time.Unix(6, 0)})
+		defer pipeline.Close()
+
+		require.NoError(t, pipeline.Read())
+		require.Len(t, late.batches, 1)
+		require.Equal(t, []string{"b", "a"}, collect(t, pipeline))
+	})
+
+	t.Run("unsupported column", func(t *testing.T) {
+		column := &physical.ColumnExpr{Ref: types.ColumnRef{Column: "app", Type: types.ColumnTypeLabel}}
+		_, err := newSortMergePipeline(nil, physical.ASC, column, 2)
+		require.Error(t, err)
+	})
+}
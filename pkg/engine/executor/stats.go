@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// NodeStats holds the runtime statistics of a single node of a physical plan.
+type NodeStats struct {
+	// RowsOut is the number of rows returned by the node.
+	RowsOut int64
+	// Batches is the number of batches returned by the node.
+	Batches int64
+	// WallTime is the time spent reading from the node, which includes the
+	// time spent reading from its inputs.
+	WallTime time.Duration
+
+	// RowsRead is the number of rows of the sections read by a
+	// [physical.DataObjScan] node, before applying its predicates.
+	RowsRead int64
+	// BytesRead is the number of bytes of pages downloaded by a
+	// [physical.DataObjScan] node.
+	BytesRead int64
+	// PagesScanned is the number of pages read by a [physical.DataObjScan]
+	// node.
+	PagesScanned int64
+	// PagesPruned is the number of pages skipped by a [physical.DataObjScan]
+	// node because of its predicates.
+	PagesPruned int64
+}
+
+// Stats collects the runtime statistics of the nodes of executed plans. The
+// statistics of the nodes of fragments executed by a [FragmentRunner] are
+// not collected.
+type Stats struct {
+	mtx   sync.Mutex
+	nodes map[physical.Node]*NodeStats
+}
+
+// NewStats returns a new, empty Stats.
+func NewStats() *Stats {
+	return &Stats{nodes: make(map[physical.Node]*NodeStats)}
+}
+
+// Node returns the statistics of node n. It returns false if n was not
+// executed. Node must only be called once the pipeline of the plan is closed.
+func (s *Stats) Node(n physical.Node) (NodeStats, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	stats, ok := s.nodes[n]
+	if !ok {
+		return NodeStats{}, false
+	}
+	return *stats, true
+}
+
+func (s *Stats) forNode(n physical.Node) *NodeStats {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	stats, ok := s.nodes[n]
+	if !ok {
+		stats = &NodeStats{}
+		s.nodes[n] = stats
+	}
+	return stats
+}
+
+// instrumentedPipeline is a [Pipeline] that records the rows, batches, and
+// wall time of the wrapped pipeline.
+type instrumentedPipeline struct {
+	Pipeline
+	stats *NodeStats
+}
+
+var _ Pipeline = (*instrumentedPipeline)(nil)
+
+func newInstrumentedPipeline(p Pipeline, stats *NodeStats) *instrumentedPipeline {
+	return &instrumentedPipeline{Pipeline: p, stats: stats}
+}
+
+// Read implements [Pipeline].
+func (p *instrumentedPipeline) Read() error {
+	start := time.Now()
+	err := p.Pipeline.Read()
+	p.stats.WallTime += time.Since(start)
+	if err != nil {
+		return err
+	}
+
+	if batch, _ := p.Pipeline.Value(); batch != nil {
+		p.stats.Batches++
+		p.stats.RowsOut += int64(batch.NumRows())
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+func TestStats(t *testing.T) {
+	stats := NewStats()
+	limit := &physical.Limit{Fetch: 3}
+	scan := &physical.DataObjScan{}
+
+	input := newInstrumentedPipeline(newBufferedPipeline(
+		batch(row(1, "a"), row(2, "b")),
+		batch(row(3, "c"), row(4, "d")),
+		batch(row(5, "e")),
+	), stats.forNode(scan))
+	pipeline := newInstrumentedPipeline(newLimitPipeline(input, 0, limit.Fetch), stats.forNode(limit))
+	require.Equal(t, []string{"a", "b", "c"}, collect(t, pipeline))
+	pipeline.Close()
+
+	limitStats, ok := stats.Node(limit)
+	require.True(t, ok)
+	require.Equal(t, int64(3), limitStats.RowsOut)
+	require.Positive(t, limitStats.WallTime)
+
+	scanStats, ok := stats.Node(scan)
+	require.True(t, ok)
+	require.Equal(t, int64(4), scanStats.RowsOut)
+	require.Equal(t, int64(2), scanStats.Batches)
+	require.LessOrEqual(t, scanStats.WallTime, limitStats.WallTime)
+
+	_, ok = stats.Node(&physical.Filter{})
+	require.False(t, ok)
+}
@@ -0,0 +1,180 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// newTransformPipeline returns a [Pipeline] that computes the log line or
+// labels of each row of its input using the formatting stages of the classic
+// engine, or removes labels from it. The label, metadata, and parsed columns
+// of the input are replaced by the labels of each category after the
+// transformation.
+func newTransformPipeline(input Pipeline, node *physical.Transform) (*GenericPipeline, error) {
+	stage, err := newTransformStage(node)
+	if err != nil {
+		return nil, err
+	}
+	transformer := &rowTransformer{
+		stage:        stage,
+		baseBuilder:  log.NewBaseLabelsBuilder(),
+		replaceLines: node.Kind == types.TransformTypeLineFormat,
+	}
+
+	return newGenericPipeline(func(inputs []Pipeline) state {
+		if err := inputs[0].Read(); err != nil {
+			return failureState(err)
+		}
+		batch, err := inputs[0].Value()
+		if err != nil {
+			return failureState(err)
+		}
+
+		transformed, err := transformer.transform(batch)
+		if err != nil {
+			return failureState(err)
+		}
+		return successState(transformed)
+	}, input), nil
+}
+
+// newTransformStage returns the stage of the classic engine that implements
+// the transformation of the node.
+func newTransformStage(node *physical.Transform) (log.Stage, error) {
+	switch node.Kind {
+	case types.TransformTypeLineFormat:
+		return log.NewFormatter(node.Template)
+	case types.TransformTypeLabelFormat:
+		return log.NewLabelsFormatter(node.LabelFormats)
+	case types.TransformTypeKeep:
+		return log.NewKeepLabels(node.Labels), nil
+	case types.TransformTypeDrop:
+		return log.NewDropLabels(node.Labels), nil
+	default:
+		return nil, fmt.Errorf("unsupported transformation %s", node.Kind)
+	}
+}
+
+// rowTransformer applies a formatting stage on the rows of batches.
+type rowTransformer struct {
+	stage        log.Stage
+	baseBuilder  *log.BaseLabelsBuilder
+	replaceLines bool // Whether the stage modifies the log line, e.g. line_format.
+}
+
+// labelCategories maps the label categories of the classic engine to the
+// column types holding them.
+var labelCategories = []struct {
+	category log.LabelCategory
+	ty       types.ColumnType
+}{
+	{log.StreamLabel, types.ColumnTypeLabel},
+	{log.StructuredMetadataLabel, types.ColumnTypeMetadata},
+	{log.ParsedLabel, types.ColumnTypeParsed},
+}
+
+// transform returns a copy of the batch with the log line replaced by the
+// formatted log line of each row, if the stage modifies the log line, and
+// all label, metadata, and parsed columns replaced by the labels of each row
+// after applying the stage.
+func (t *rowTransformer) transform(batch *columnar.RecordBatch) (*columnar.RecordBatch, error) {
+	timestamps := timestampColumn(batch)
+	if timestamps == nil {
+		return nil, errors.New("transform requires a timestamp column")
+	}
+	logIdx := findColumn(batch, types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin)
+	if logIdx < 0 {
+		return nil, errors.New("transform requires a log column")
+	}
+	lines, ok := batch.Column(logIdx).(*columnar.StringArray)
+	if !ok {
+		return nil, fmt.Errorf("invalid log column type %s", batch.Column(logIdx).DataType())
+	}
+
+	var (
+		inputLabels = make([]func(int) labels.Labels, len(labelCategories))
+		columns     = make([]*dynamicColumns, len(labelCategories))
+
+		lineCol columnar.StringBuilder
+		buf     labels.Labels
+	)
+	for c, lc := range labelCategories {
+		inputLabels[c] = categoryLabelsFunc(batch, lc.ty)
+		columns[c] = newDynamicColumns()
+	}
+
+	for i := range batch.NumRows() {
+		lbs := inputLabels[0](i)
+		t.baseBuilder.Reset()
+		builder := t.baseBuilder.ForLabels(lbs, lbs.Hash())
+		for c, lc := range labelCategories[1:] {
+			setLabels(builder, lc.category, inputLabels[c+1](i))
+		}
+
+		// None of the transformations remove rows, so the result of the
+		// stage is ignored.
+		line, _ := t.stage.Process(timestamps.Value(i), lines.Bytes(i), builder)
+		if t.replaceLines {
+			lineCol.AppendBytes(line)
+		}
+
+		for c, lc := range labelCategories {
+			buf = builder.UnsortedLabels(buf, lc.category)
+			for _, l := range buf {
+				columns[c].set(i, l.Name, l.Value)
+			}
+			columns[c].endRow(i)
+		}
+	}
+
+	var (
+		fields  []columnar.Field
+		arrays  []columnar.Array
+		schema  = batch.Schema()
+		numRows = batch.NumRows()
+	)
+	for i := range batch.NumCols() {
+		field := schema.Field(i)
+		switch types.ColumnTypeOf(field) {
+		case types.ColumnTypeLabel, types.ColumnTypeMetadata, types.ColumnTypeParsed:
+			continue
+		}
+		col := batch.Column(i)
+		if i == logIdx && t.replaceLines {
+			col = lineCol.Build()
+		}
+		fields = append(fields, field)
+		arrays = append(arrays, col)
+	}
+	for c, lc := range labelCategories {
+		for _, name := range columns[c].names() {
+			fields = append(fields, newField(name, lc.ty, columnar.DataTypeString))
+			arrays = append(arrays, columns[c].build(name))
+		}
+	}
+	return columnar.NewRecordBatch(columnar.NewSchema(fields...), numRows, arrays)
+}
+
+// setLabels sets the labels of the given category on the builder. Error
+// labels are set as errors of the builder, so that stages handle them the
+// same way as in the classic engine.
+func setLabels(builder *log.LabelsBuilder, category log.LabelCategory, lbs labels.Labels) {
+	lbs.Range(func(l labels.Label) {
+		switch l.Name {
+		case logqlmodel.ErrorLabel:
+			builder.SetErr(l.Value)
+		case logqlmodel.ErrorDetailsLabel:
+			builder.SetErrorDetails(l.Value)
+		default:
+			builder.Set(category, l.Name, l.Value)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+func TestTransform(t *testing.T) {
+	input := func() *columnar.RecordBatch {
+		return batch(
+			testRow{
+				ts:       1,
+				line:     "hello",
+				labels:   labels.FromStrings("app", "foo", "env", "prod"),
+				metadata: labels.FromStrings("trace_id", "123"),
+			},
+			testRow{
+				ts:       2,
+				line:     "world",
+				labels:   labels.FromStrings("app", "bar", "env", "dev"),
+				metadata: labels.FromStrings("level", "error"),
+			},
+		)
+	}
+
+	for _, tt := range []struct {
+		name           string
+		node           *physical.Transform
+		expectedLines  []string
+		expectedLabels []string
+	}{
+		{
+			name: "line_format",
+			node: &physical.Transform{Kind: types.TransformTypeLineFormat, Template: `{{.app}}: {{__line__}}`},
+			expectedLines: []string{
+				"foo: hello",
+				"bar: world",
+			},
+			expectedLabels: []string{
+				`{app="foo", env="prod", trace_id="123"}`,
+				`{app="bar", env="dev", level="error"}`,
+			},
+		},
+		{
+			name: "label_format",
+			node: &physical.Transform{
+				Kind: types.TransformTypeLabelFormat,
+				LabelFormats: []log.LabelFmt{
+					log.NewRenameLabelFmt("service", "app"),
+					log.NewTemplateLabelFmt("env", "{{.env | upper}}"),
+				},
+			},
+			expectedLines: []string{"hello", "world"},
+			expectedLabels: []string{
+				`{env="PROD", service="foo", trace_id="123"}`,
+				`{env="DEV", level="error", service="bar"}`,
+			},
+		},
+		{
+			name: "keep",
+			node: &physical.Transform{
+				Kind: types.TransformTypeKeep,
+				Labels: []log.NamedLabelMatcher{
+					{Name: "app"},
+					{Matcher: labels.MustNewMatcher(labels.MatchEqual, "level", "error")},
+				},
+			},
+			expectedLines: []string{"hello", "world"},
+			expectedLabels: []string{
+				`{app="foo"}`,
+				`{app="bar", level="error"}`,
+			},
+		},
+		{
+			name: "drop",
+			node: &physical.Transform{
+				Kind: types.TransformTypeDrop,
+				Labels: []log.NamedLabelMatcher{
+					{Name: "trace_id"},
+					{Matcher: labels.MustNewMatcher(labels.MatchEqual, "env", "dev")},
+				},
+			},
+			expectedLines: []string{"hello", "world"},
+			expectedLabels: []string{
+				`{app="foo", env="prod"}`,
+				`{app="bar", level="error"}`,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := newTransformPipeline(newBufferedPipeline(input()), tt.node)
+			require.NoError(t, err)
+			defer pipeline.Close()
+			require.Equal(t, tt.expectedLines, collect(t, pipeline))
+
+			pipeline, err = newTransformPipeline(newBufferedPipeline(input()), tt.node)
+			require.NoError(t, err)
+			defer pipeline.Close()
+			require.Equal(t, tt.expectedLabels, collectLabels(t, pipeline))
+		})
+	}
+}
+
+func TestTransform_ColumnTypes(t *testing.T) {
+	input := newBufferedPipeline(batch(
+		testRow{
+			ts:       1,
+			line:     `level=error`,
+			labels:   labels.FromStrings("app", "foo", "pod", "a"),
+			metadata: labels.FromStrings("trace_id", "123"),
+		},
+	))
+
+	parse, err := newParsePipeline(input, &physical.Parse{Kind: types.ParserTypeLogfmt})
+	require.NoError(t, err)
+	format, err := newTransformPipeline(parse, &physical.Transform{
+		Kind:         types.TransformTypeLabelFormat,
+		LabelFormats: []log.LabelFmt{log.NewRenameLabelFmt("service", "app")},
+	})
+	require.NoError(t, err)
+	drop, err := newTransformPipeline(format, &physical.Transform{
+		Kind:   types.TransformTypeDrop,
+		Labels: []log.NamedLabelMatcher{{Name: "pod"}},
+	})
+	require.NoError(t, err)
+	defer drop.Close()
+
+	require.NoError(t, drop.Read())
+	batch, err := drop.Value()
+	require.NoError(t, err)
+
+	// Labels keep the column type of their category, while labels set by
+	// label_format become parsed columns.
+	columns := make(map[string]types.ColumnType)
+	for i := range batch.NumCols() {
+		field := batch.Schema().Field(i)
+		columns[field.Name] = types.ColumnTypeOf(field)
+	}
+	require.Equal(t, map[string]types.ColumnType{
+		types.ColumnNameBuiltinTimestamp: types.ColumnTypeBuiltin,
+		types.ColumnNameBuiltinLog:       types.ColumnTypeBuiltin,
+		"trace_id":                       types.ColumnTypeMetadata,
+		"level":                          types.ColumnTypeParsed,
+		"service":                        types.ColumnTypeParsed,
+	}, columns)
+	require.ErrorIs(t, drop.Read(), EOF)
+}
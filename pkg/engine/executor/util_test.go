@@ -0,0 +1,136 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// bufferedPipeline is a [Pipeline] that returns a predefined list of batches.
+type bufferedPipeline struct {
+	batches []*columnar.RecordBatch
+	state   state
+	closed  bool
+}
+
+func newBufferedPipeline(batches ...*columnar.RecordBatch) *bufferedPipeline {
+	return &bufferedPipeline{batches: batches}
+}
+
+func (p *bufferedPipeline) Read() error {
+	if len(p.batches) == 0 {
+		p.state = exhausted
+		return EOF
+	}
+	p.state = successState(p.batches[0])
+	p.batches = p.batches[1:]
+	return nil
+}
+
+func (p *bufferedPipeline) Value() (*columnar.RecordBatch, error) { return p.state.Value() }
+func (p *bufferedPipeline) Close()                                { p.closed = true }
+
+// testRow describes a single row of a batch built by [batch].
+type testRow struct {
+	ts       int64 // Timestamp in seconds.
+	line     string
+	labels   labels.Labels
+	metadata labels.Labels
+}
+
+func row(ts int64, line string, lbs ...string) testRow {
+	return testRow{ts: ts, line: line, labels: labels.FromStrings(lbs...), metadata: labels.EmptyLabels()}
+}
+
+// batch builds a record batch from the given rows. The batch has a column
+// for each label and metadata key of any of the rows.
+func batch(rows ...testRow) *columnar.RecordBatch {
+	builder := columnar.NewRecordBuilder()
+	for _, r := range rows {
+		fields := []columnar.Field{timestampField, logField}
+
+		var ts columnar.TimestampBuilder
+		ts.Append(r.ts * 1e9)
+		var line columnar.StringBuilder
+		line.Append(r.line)
+		columns := []columnar.Array{ts.Build(), line.Build()}
+
+		add := func(ty types.ColumnType) func(labels.Label) {
+			return func(l labels.Label) {
+				var b columnar.StringBuilder
+				b.Append(l.Value)
+				fields = append(fields, newField(l.Name, ty, columnar.DataTypeString))
+				columns = append(columns, b.Build())
+			}
+		}
+		r.labels.Range(add(types.ColumnTypeLabel))
+		r.metadata.Range(add(types.ColumnTypeMetadata))
+
+		src, err := columnar.NewRecordBatch(columnar.NewSchema(fields...), 1, columns)
+		if err != nil {
+			panic(err)
+		}
+		if err := builder.AppendRow(src, 0); err != nil {
+			panic(err)
+		}
+	}
+	return builder.Build()
+}
+
+// collect reads all batches of the pipeline and returns the log lines of
+// all their rows.
+func collect(t *testing.T, p Pipeline) []string {
+	t.Helper()
+
+	var lines []string
+	for {
+		err := p.Read()
+		if errors.Is(err, EOF) {
+			return lines
+		}
+		require.NoError(t, err)
+
+		batch, err := p.Value()
+		require.NoError(t, err)
+
+		idx := findColumn(batch, types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin)
+		require.GreaterOrEqual(t, idx, 0, "batch is missing the log column")
+		col := batch.Column(idx).(*columnar.StringArray)
+		for i := range col.Len() {
+			lines = append(lines, col.Value(i))
+		}
+	}
+}
+
+// collectSamples reads all batches of the pipeline, which are required to
+// have a timestamp and value column, and returns each row formatted as
+// "<labels> @<ts in seconds> = <value>".
+func collectSamples(t *testing.T, p Pipeline) []string {
+	t.Helper()
+
+	var samples []string
+	for {
+		err := p.Read()
+		if errors.Is(err, EOF) {
+			return samples
+		}
+		require.NoError(t, err)
+
+		batch, err := p.Value()
+		require.NoError(t, err)
+
+		timestamps, values := timestampColumn(batch), valueColumn(batch)
+		require.NotNil(t, timestamps, "batch is missing the timestamp column")
+		require.NotNil(t, values, "batch is missing the value column")
+		lbs := rowLabelsFunc(batch)
+		for i := range batch.NumRows() {
+			samples = append(samples, fmt.Sprintf("%s @%d = %g", lbs(i), timestamps.Value(i)/1e9, values.Value(i)))
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// vectorAccumulator holds the intermediate state of a single group at a
+// single timestamp.
+type vectorAccumulator struct {
+	sum, min, max float64
+	count         float64
+}
+
+// add adds a sample value to the accumulator.
+func (acc *vectorAccumulator) add(v float64) {
+	if acc.count == 0 {
+		acc.min, acc.max = v, v
+	}
+	acc.sum += v
+	acc.min = math.Min(acc.min, v)
+	acc.max = math.Max(acc.max, v)
+	acc.count++
+}
+
+// newVectorAggregationPipeline returns a [Pipeline] that aggregates the
+// samples of its input, which are required to have a builtin timestamp and
+// value column, into one sample per group and timestamp.
+//
+// The input is consumed before the first batch is returned.
+func newVectorAggregationPipeline(input Pipeline, node *physical.VectorAggregation, batchSize int64) (*GenericPipeline, error) {
+	var value func(*vectorAccumulator) float64
+	switch node.Operation {
+	case types.VectorAggregationTypeSum:
+		value = func(acc *vectorAccumulator) float64 { return acc.sum }
+	case types.VectorAggregationTypeMin:
+		value = func(acc *vectorAccumulator) float64 { return acc.min }
+	case types.VectorAggregationTypeMax:
+		value = func(acc *vectorAccumulator) float64 { return acc.max }
+	case types.VectorAggregationTypeCount:
+		value = func(acc *vectorAccumulator) float64 { return acc.count }
+	case types.VectorAggregationTypeAvg:
+		value = func(acc *vectorAccumulator) float64 { return acc.sum / acc.count }
+	default:
+		return nil, fmt.Errorf("unsupported vector aggregation %s", node.Operation)
+	}
+
+	groupBy, err := columnRefs(node.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// groupLabels returns a function that returns the group labels of a row.
+	groupLabels := func(batch *columnar.RecordBatch) (func(int) labels.Labels, error) {
+		if !node.Without {
+			if len(groupBy) == 0 {
+				return func(int) labels.Labels { return labels.EmptyLabels() }, nil
+			}
+			return seriesLabelsFunc(batch, groupBy)
+		}
+
+		names := make([]string, 0, len(groupBy))
+		for _, ref := range groupBy {
+			names = append(names, ref.Column)
+		}
+		rowLabels := rowLabelsFunc(batch)
+		builder := labels.NewBuilder(labels.EmptyLabels())
+		return func(i int) labels.Labels {
+			builder.Reset(rowLabels(i))
+			builder.Del(names...)
+			return builder.Labels()
+		}, nil
+	}
+
+	compute := func(inputs []Pipeline) (*columnar.RecordBatch, error) {
+		set := newSeriesSet[vectorAccumulator]()
+
+		err := readAll(inputs[0], func(batch *columnar.RecordBatch) error {
+			timestamps, values := timestampColumn(batch), valueColumn(batch)
+			if timestamps == nil || values == nil {
+				return errors.New("vector aggregation requires a timestamp and value column")
+			}
+			lbs, err := groupLabels(batch)
+			if err != nil {
+				return err
+			}
+
+			for i := range batch.NumRows() {
+				if values.IsNull(i) {
+					continue
+				}
+				set.get(lbs(i), timestamps.Value(i)).add(values.Value(i))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return samplesBatch(set, value)
+	}
+
+	return newGenericPipeline(newSliceReader(compute, batchSize), input), nil
+}
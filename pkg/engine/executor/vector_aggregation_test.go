@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+func TestVectorAggregation(t *testing.T) {
+	// Samples of count_over_time with a range of 10s at steps 10 and 20.
+	input := func() Pipeline {
+		node := &physical.RangeAggregation{
+			Operation: types.RangeAggregationTypeCount,
+			Start:     time.Unix(10, 0),
+			End:       time.Unix(20, 0),
+			Step:      10 * time.Second,
+			Range:     10 * time.Second,
+		}
+		rows := newBufferedPipeline(batch(
+			row(1, "a", "app", "foo", "pod", "a"),
+			row(2, "b", "app", "foo", "pod", "a"),
+			row(3, "c", "app", "foo", "pod", "b"),
+			row(4, "d", "app", "bar", "pod", "c"),
+			row(15, "e", "app", "foo", "pod", "b"),
+		))
+		pipeline, err := newRangeAggregationPipeline([]Pipeline{rows}, node, 0)
+		require.NoError(t, err)
+		return pipeline
+	}
+
+	app := []physical.ColumnExpression{&physical.ColumnExpr{Ref: types.ColumnRef{Column: "app", Type: types.ColumnTypeAmbiguous}}}
+	pod := []physical.ColumnExpression{&physical.ColumnExpr{Ref: types.ColumnRef{Column: "pod", Type: types.ColumnTypeAmbiguous}}}
+
+	for _, tt := range []struct {
+		name      string
+		operation types.VectorAggregationType
+		groupBy   []physical.ColumnExpression
+		without   bool
+		expected  []string
+	}{
+		{
+			name:      "sum",
+			operation: types.VectorAggregationTypeSum,
+			expected:  []string{`{} @10 = 4`, `{} @20 = 1`},
+		},
+		{
+			name:      "sum by",
+			operation: types.VectorAggregationTypeSum,
+			groupBy:   app,
+			expected:  []string{`{app="bar"} @10 = 1`, `{app="foo"} @10 = 3`, `{app="foo"} @20 = 1`},
+		},
+		{
+			name:      "max without",
+			operation: types.VectorAggregationTypeMax,
+			groupBy:   pod,
+			without:   true,
+			expected:  []string{`{app="bar"} @10 = 1`, `{app="foo"} @10 = 2`, `{app="foo"} @20 = 1`},
+		},
+		{
+			name:      "min",
+			operation: types.VectorAggregationTypeMin,
+			expected:  []string{`{} @10 = 1`, `{} @20 = 1`},
+		},
+		{
+			name:      "count",
+			operation: types.VectorAggregationTypeCount,
+			groupBy:   app,
+			expected:  []string{`{app="bar"} @10 = 1`, `{app="foo"} @10 = 2`, `{app="foo"} @20 = 1`},
+		},
+		{
+			name:      "avg",
+			operation: types.VectorAggregationTypeAvg,
+			expected:  []string{`{} @10 = 1.3333333333333333`, `{} @20 = 1`},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &physical.VectorAggregation{
+				Operation: tt.operation,
+				GroupBy:   tt.groupBy,
+				Without:   tt.without,
+			}
+			pipeline, err := newVectorAggregationPipeline(input(), node, 0)
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			require.Equal(t, tt.expected, collectSamples(t, pipeline))
+		})
+	}
+}
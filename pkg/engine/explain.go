@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/engine/executor"
+	"github.com/grafana/loki/v3/pkg/engine/planner/logical"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	utillog "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// Explanation describes how a query is planned and, if it was analyzed,
+// executed by the engine. It is returned by [QueryEngine.Explain].
+type Explanation struct {
+	// LogicalPlan is the logical plan of the query.
+	LogicalPlan string `json:"logicalPlan"`
+	// PhysicalPlan is the optimized physical plan of the query. The nodes of
+	// the plan hold their runtime statistics if the query was analyzed.
+	PhysicalPlan string `json:"physicalPlan"`
+	// Analyzed is true if the query was executed to collect runtime
+	// statistics.
+	Analyzed bool `json:"analyzed"`
+	// Nodes holds the runtime statistics of the executed nodes of the
+	// physical plan in pre-order.
+	Nodes []NodeStats `json:"nodes,omitempty"`
+	// Statistics are the statistics of the query if it was analyzed.
+	Statistics *stats.Result `json:"statistics,omitempty"`
+}
+
+// NodeStats holds the runtime statistics of a single node of a physical plan.
+type NodeStats struct {
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	RowsIn       int64         `json:"rowsIn"`
+	RowsOut      int64         `json:"rowsOut"`
+	Batches      int64         `json:"batches"`
+	WallTime     time.Duration `json:"wallTime"`
+	BytesRead    int64         `json:"bytesRead,omitempty"`
+	PagesScanned int64         `json:"pagesScanned,omitempty"`
+	PagesPruned  int64         `json:"pagesPruned,omitempty"`
+}
+
+// String returns the plans of the explanation in a human-readable format.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	sb.WriteString("Logical plan:\n")
+	sb.WriteString(e.LogicalPlan)
+	sb.WriteString("\nPhysical plan:\n")
+	sb.WriteString(e.PhysicalPlan)
+	if e.Statistics != nil {
+		fmt.Fprintf(&sb, "\nExecution time: %s\n", time.Duration(e.Statistics.Summary.ExecTime*float64(time.Second)))
+	}
+	return sb.String()
+}
+
+// Explain returns the logical and the optimized physical plan of a query.
+// If analyze is true, the query is executed and the physical plan is
+// annotated with the runtime statistics of each node, like rows in and out,
+// bytes read, pages pruned, and wall time. The results of the query are
+// discarded.
+//
+// The nodes of fragments that are executed by queriers do not have runtime
+// statistics, as only the Exchange nodes consuming them are executed by the
+// engine itself.
+func (e *QueryEngine) Explain(ctx context.Context, params logql.Params, analyze bool) (*Explanation, error) {
+	start := time.Now()
+	logger := utillog.WithContext(ctx, e.logger)
+	logger = log.With(logger, "query", params.QueryString(), "engine", "v2")
+
+	logicalPlan, plan, _, err := e.buildPlans(ctx, params, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	logical.PrintTree(&sb, logicalPlan.Value())
+	explanation := &Explanation{LogicalPlan: sb.String()}
+	if !analyze {
+		explanation.PhysicalPlan = physical.PrintAsTree(plan)
+		return explanation, nil
+	}
+
+	level.Info(logger).Log("msg", "analyze query with new engine")
+
+	statsCtx, ctx := stats.NewContext(ctx)
+	nodeStats := executor.NewStats()
+	cfg := executor.Config{
+		BatchSize: batchSize,
+		Bucket:    e.bucket,
+		Fragments: e.fragments,
+		Stats:     nodeStats,
+	}
+	pipeline := executor.Run(ctx, cfg, plan)
+
+	var rows int64
+	for {
+		if err := pipeline.Read(); err != nil {
+			if errors.Is(err, executor.EOF) {
+				break
+			}
+			pipeline.Close()
+			return nil, errors.Wrap(err, "failed to execute physical plan")
+		}
+		if batch, _ := pipeline.Value(); batch != nil {
+			rows += int64(batch.NumRows())
+		}
+	}
+	pipeline.Close()
+
+	explanation.Analyzed = true
+	explanation.PhysicalPlan = physical.PrintAsTreeAnnotated(plan, func(p *physical.Plan, n physical.Node) []physical.Annotation {
+		s, ok := collectNodeStats(nodeStats, p, n)
+		if !ok {
+			return nil
+		}
+		explanation.Nodes = append(explanation.Nodes, s)
+
+		annotations := []physical.Annotation{
+			{Key: "rows_in", Value: s.RowsIn},
+			{Key: "rows_out", Value: s.RowsOut},
+			{Key: "wall_time", Value: s.WallTime},
+		}
+		if _, ok := n.(*physical.DataObjScan); ok {
+			annotations = append(annotations,
+				physical.Annotation{Key: "bytes_read", Value: humanize.Bytes(uint64(s.BytesRead))},
+				physical.Annotation{Key: "pages_scanned", Value: s.PagesScanned},
+				physical.Annotation{Key: "pages_pruned", Value: s.PagesPruned},
+			)
+		}
+		return annotations
+	})
+
+	result := statsCtx.Result(time.Since(start), 0, int(rows))
+	explanation.Statistics = &result
+	return explanation, nil
+}
+
+// collectNodeStats returns the runtime statistics of node n of plan p. The
+// rows in of a node are the rows out of its children, or the rows read from
+// the data object for DataObjScan nodes.
+func collectNodeStats(nodeStats *executor.Stats, p *physical.Plan, n physical.Node) (NodeStats, bool) {
+	s, ok := nodeStats.Node(n)
+	if !ok {
+		return NodeStats{}, false
+	}
+
+	var rowsIn int64
+	switch n.(type) {
+	case *physical.DataObjScan:
+		rowsIn = s.RowsRead
+	case *physical.Exchange:
+		// Exchange nodes return the rows of their fragment as is, which may
+		// have been executed by a querier.
+		rowsIn = s.RowsOut
+	default:
+		for _, child := range p.Children(n) {
+			if childStats, ok := nodeStats.Node(child); ok {
+				rowsIn += childStats.RowsOut
+			}
+		}
+	}
+
+	return NodeStats{
+		ID:           n.ID(),
+		Type:         n.Type().String(),
+		RowsIn:       rowsIn,
+		RowsOut:      s.RowsOut,
+		Batches:      s.Batches,
+		WallTime:     s.WallTime,
+		BytesRead:    s.BytesRead,
+		PagesScanned: s.PagesScanned,
+		PagesPruned:  s.PagesPruned,
+	}, true
+}
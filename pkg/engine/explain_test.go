@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+)
+
+func TestQueryEngine_Explain(t *testing.T) {
+	now := time.Unix(0, 0).UTC().Add(time.Hour)
+	entry := func(offset time.Duration, line string) logproto.Entry {
+		return logproto.Entry{Timestamp: now.Add(offset), Line: line}
+	}
+
+	bucket := buildTestObjects(t,
+		[]logproto.Stream{
+			{
+				Labels: `{app="foo", env="prod"}`,
+				Entries: []logproto.Entry{
+					entry(1*time.Second, "foo1 level=info"),
+					entry(2*time.Second, "foo2 level=error"),
+					entry(3*time.Second, "foo3 level=info"),
+				},
+			},
+		},
+		[]logproto.Stream{
+			{
+				Labels: `{app="bar", env="prod"}`,
+				Entries: []logproto.Entry{
+					entry(4*time.Second, "bar1 level=error"),
+				},
+			},
+		},
+	)
+	ctx := user.InjectOrgID(context.Background(), testTenant)
+	engine := newTestEngine(bucket)
+
+	params, err := logql.NewLiteralParams(`{env="prod"} | logfmt | level="error"`, now, now.Add(time.Minute), 0, 0, logproto.FORWARD, 100, nil, nil)
+	require.NoError(t, err)
+
+	t.Run("explain", func(t *testing.T) {
+		explanation, err := engine.Explain(ctx, params, false)
+		require.NoError(t, err)
+		require.False(t, explanation.Analyzed)
+		require.Contains(t, explanation.LogicalPlan, "MAKETABLE")
+		require.Contains(t, explanation.PhysicalPlan, "DataObjScan")
+		require.NotContains(t, explanation.PhysicalPlan, "rows_out=")
+		require.Empty(t, explanation.Nodes)
+		require.Nil(t, explanation.Statistics)
+	})
+
+	t.Run("analyze", func(t *testing.T) {
+		explanation, err := engine.Explain(ctx, params, true)
+		require.NoError(t, err)
+		require.True(t, explanation.Analyzed)
+		t.Log("\n" + explanation.String())
+		require.Contains(t, explanation.PhysicalPlan, "rows_out=")
+		require.Contains(t, explanation.PhysicalPlan, "pages_pruned=")
+		require.NotNil(t, explanation.Statistics)
+
+		// The root node returns the result of the query, and the nodes are
+		// listed in pre-order.
+		require.NotEmpty(t, explanation.Nodes)
+		require.Equal(t, int64(2), explanation.Nodes[0].RowsOut)
+
+		var scans int
+		for _, node := range explanation.Nodes {
+			require.GreaterOrEqual(t, node.RowsIn, node.RowsOut, node.Type)
+			require.Positive(t, node.WallTime, node.Type)
+			if node.Type != "DataObjScan" {
+				continue
+			}
+			scans++
+			require.Positive(t, node.RowsIn)
+			require.Positive(t, node.BytesRead)
+		}
+		require.Equal(t, 2, scans)
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		params, err := logql.NewLiteralParams(`topk(10, count_over_time({env="prod"}[1m]))`, now, now.Add(time.Minute), time.Minute, 0, logproto.FORWARD, 100, nil, nil)
+		require.NoError(t, err)
+
+		_, err = engine.Explain(ctx, params, false)
+		require.ErrorIs(t, err, ErrNotSupported)
+	})
+}
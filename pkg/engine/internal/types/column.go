@@ -1,6 +1,10 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+)
 
 // ColumnType denotes the column type for a [ColumnRef].
 type ColumnType int
@@ -21,6 +25,7 @@ const (
 const (
 	ColumnNameBuiltinTimestamp = "timestamp"
 	ColumnNameBuiltinLog       = "log"
+	ColumnNameBuiltinValue     = "value" // Value of a sample produced by an aggregation.
 )
 
 // String returns a human-readable representation of the column type.
@@ -54,3 +59,31 @@ type ColumnRef struct {
 func (c *ColumnRef) String() string {
 	return fmt.Sprintf("%s.%s", c.Type, c.Column)
 }
+
+// MetadataKeyColumnType is the key of the [columnar.Field] metadata that
+// holds the [ColumnType] of a column of a record batch.
+const MetadataKeyColumnType = "column_type"
+
+// ColumnMetadata returns the field metadata for a column of type ct.
+func ColumnMetadata(ct ColumnType) map[string]string {
+	return map[string]string{MetadataKeyColumnType: ct.String()}
+}
+
+// ColumnTypeOf returns the [ColumnType] stored in the metadata of the field.
+// It returns [ColumnTypeInvalid] if the field does not have a column type.
+func ColumnTypeOf(field columnar.Field) ColumnType {
+	switch field.Metadata[MetadataKeyColumnType] {
+	case "builtin":
+		return ColumnTypeBuiltin
+	case "label":
+		return ColumnTypeLabel
+	case "metadata":
+		return ColumnTypeMetadata
+	case "parsed":
+		return ColumnTypeParsed
+	case "ambiguous":
+		return ColumnTypeAmbiguous
+	default:
+		return ColumnTypeInvalid
+	}
+}
@@ -108,3 +108,139 @@ func (t BinaryOp) String() string {
 		panic(fmt.Sprintf("unknown binary operator %d", t))
 	}
 }
+
+// RangeAggregationType denotes the kind of range aggregation to perform.
+type RangeAggregationType uint32
+
+// Recognized values of [RangeAggregationType].
+const (
+	// RangeAggregationTypeInvalid indicates an invalid range aggregation.
+	RangeAggregationTypeInvalid RangeAggregationType = iota
+
+	RangeAggregationTypeCount     // Number of log lines within a range (count_over_time).
+	RangeAggregationTypeRate      // Number of log lines per second within a range (rate).
+	RangeAggregationTypeBytes     // Number of bytes of log lines within a range (bytes_over_time).
+	RangeAggregationTypeBytesRate // Number of bytes of log lines per second within a range (bytes_rate).
+)
+
+// String returns the string representation of the RangeAggregationType.
+func (t RangeAggregationType) String() string {
+	switch t {
+	case RangeAggregationTypeInvalid:
+		return typeInvalid
+	case RangeAggregationTypeCount:
+		return "count"
+	case RangeAggregationTypeRate:
+		return "rate"
+	case RangeAggregationTypeBytes:
+		return "bytes"
+	case RangeAggregationTypeBytesRate:
+		return "bytes_rate"
+	default:
+		panic(fmt.Sprintf("unknown range aggregation type %d", t))
+	}
+}
+
+// VectorAggregationType denotes the kind of vector aggregation to perform.
+type VectorAggregationType uint32
+
+// Recognized values of [VectorAggregationType].
+const (
+	// VectorAggregationTypeInvalid indicates an invalid vector aggregation.
+	VectorAggregationTypeInvalid VectorAggregationType = iota
+
+	VectorAggregationTypeSum   // Sum of the values of a group (sum).
+	VectorAggregationTypeMin   // Minimum of the values of a group (min).
+	VectorAggregationTypeMax   // Maximum of the values of a group (max).
+	VectorAggregationTypeCount // Number of values of a group (count).
+	VectorAggregationTypeAvg   // Average of the values of a group (avg).
+)
+
+// String returns the string representation of the VectorAggregationType.
+func (t VectorAggregationType) String() string {
+	switch t {
+	case VectorAggregationTypeInvalid:
+		return typeInvalid
+	case VectorAggregationTypeSum:
+		return "sum"
+	case VectorAggregationTypeMin:
+		return "min"
+	case VectorAggregationTypeMax:
+		return "max"
+	case VectorAggregationTypeCount:
+		return "count"
+	case VectorAggregationTypeAvg:
+		return "avg"
+	default:
+		panic(fmt.Sprintf("unknown vector aggregation type %d", t))
+	}
+}
+
+// ParserType denotes the kind of parser used to extract labels from log
+// lines.
+type ParserType uint32
+
+// Recognized values of [ParserType].
+const (
+	// ParserTypeInvalid indicates an invalid parser.
+	ParserTypeInvalid ParserType = iota
+
+	ParserTypeJSON    // Extracts the fields of JSON log lines (json).
+	ParserTypeLogfmt  // Extracts the keys of logfmt log lines (logfmt).
+	ParserTypeRegexp  // Extracts the named capture groups of a regular expression (regexp).
+	ParserTypePattern // Extracts the named captures of a pattern (pattern).
+	ParserTypeUnpack  // Unpacks log lines packed by the pack stage of Promtail (unpack).
+)
+
+// String returns the string representation of the ParserType.
+func (t ParserType) String() string {
+	switch t {
+	case ParserTypeInvalid:
+		return typeInvalid
+	case ParserTypeJSON:
+		return "json"
+	case ParserTypeLogfmt:
+		return "logfmt"
+	case ParserTypeRegexp:
+		return "regexp"
+	case ParserTypePattern:
+		return "pattern"
+	case ParserTypeUnpack:
+		return "unpack"
+	default:
+		panic(fmt.Sprintf("unknown parser type %d", t))
+	}
+}
+
+// TransformType denotes the kind of transformation applied to the log line
+// and labels of rows.
+type TransformType uint32
+
+// Recognized values of [TransformType].
+const (
+	// TransformTypeInvalid indicates an invalid transformation.
+	TransformTypeInvalid TransformType = iota
+
+	TransformTypeLineFormat  // Rewrites the log line from a template (line_format).
+	TransformTypeLabelFormat // Renames labels or sets them from templates (label_format).
+	TransformTypeKeep        // Removes all labels except the given ones (keep).
+	TransformTypeDrop        // Removes the given labels (drop).
+)
+
+// String returns the string representation of the TransformType.
+func (t TransformType) String() string {
+	switch t {
+	case TransformTypeInvalid:
+		return typeInvalid
+	case TransformTypeLineFormat:
+		return "line_format"
+	case TransformTypeLabelFormat:
+		return "label_format"
+	case TransformTypeKeep:
+		return "keep"
+	case TransformTypeDrop:
+		return "drop"
+	default:
+		panic(fmt.Sprintf("unknown transform type %d", t))
+	}
+}
@@ -1,6 +1,9 @@
 package logical
 
 import (
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
 )
 
@@ -25,6 +28,20 @@ func (b *Builder) Select(predicate Value) *Builder {
 	}
 }
 
+// Parse applies a [Parse] operation to the Builder. The Table of the given
+// Parse is replaced with the current value of the Builder.
+func (b *Builder) Parse(parse Parse) *Builder {
+	parse.Table = b.val
+	return &Builder{val: &parse}
+}
+
+// Transform applies a [Transform] operation to the Builder. The Table of the
+// given Transform is replaced with the current value of the Builder.
+func (b *Builder) Transform(transform Transform) *Builder {
+	transform.Table = b.val
+	return &Builder{val: &transform}
+}
+
 // Limit applies a [Limit] operation to the Builder.
 func (b *Builder) Limit(skip uint32, fetch uint32) *Builder {
 	return &Builder{
@@ -49,6 +66,40 @@ func (b *Builder) Sort(column ColumnRef, ascending, nullsFirst bool) *Builder {
 	}
 }
 
+// RangeAggregation applies a [RangeAggregation] operation to the Builder.
+func (b *Builder) RangeAggregation(
+	operation types.RangeAggregationType,
+	partitionBy []ColumnRef,
+	start, end time.Time,
+	step, rangeInterval time.Duration,
+) *Builder {
+	return &Builder{
+		val: &RangeAggregation{
+			Table: b.val,
+
+			Operation:     operation,
+			PartitionBy:   partitionBy,
+			Start:         start,
+			End:           end,
+			Step:          step,
+			RangeInterval: rangeInterval,
+		},
+	}
+}
+
+// VectorAggregation applies a [VectorAggregation] operation to the Builder.
+func (b *Builder) VectorAggregation(operation types.VectorAggregationType, groupBy []ColumnRef, without bool) *Builder {
+	return &Builder{
+		val: &VectorAggregation{
+			Table: b.val,
+
+			Operation: operation,
+			GroupBy:   groupBy,
+			Without:   without,
+		},
+	}
+}
+
 // Schema returns the schema of the data that will be produced by this Builder.
 func (b *Builder) Schema() *schema.Schema {
 	return b.val.Schema()
@@ -41,6 +41,14 @@ func (b *ssaBuilder) process(value Value) (Value, error) {
 		return b.processLimitPlan(value)
 	case *Sort:
 		return b.processSortPlan(value)
+	case *Parse:
+		return b.processParse(value)
+	case *Transform:
+		return b.processTransform(value)
+	case *RangeAggregation:
+		return b.processRangeAggregation(value)
+	case *VectorAggregation:
+		return b.processVectorAggregation(value)
 
 	case *UnaryOp:
 		return b.processUnaryOp(value)
@@ -100,6 +108,46 @@ func (b *ssaBuilder) processSortPlan(plan *Sort) (Value, error) {
 	return plan, nil
 }
 
+func (b *ssaBuilder) processParse(plan *Parse) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
+func (b *ssaBuilder) processTransform(plan *Transform) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
+func (b *ssaBuilder) processRangeAggregation(plan *RangeAggregation) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
+func (b *ssaBuilder) processVectorAggregation(plan *VectorAggregation) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
 func (b *ssaBuilder) processUnaryOp(value *UnaryOp) (Value, error) {
 	if _, err := b.process(value.Value); err != nil {
 		return nil, err
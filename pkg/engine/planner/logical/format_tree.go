@@ -28,6 +28,14 @@ func (t *treeFormatter) convert(value Value) *tree.Node {
 		return t.convertLimit(value)
 	case *Sort:
 		return t.convertSort(value)
+	case *Parse:
+		return t.convertParse(value)
+	case *Transform:
+		return t.convertTransform(value)
+	case *RangeAggregation:
+		return t.convertRangeAggregation(value)
+	case *VectorAggregation:
+		return t.convertVectorAggregation(value)
 
 	case *UnaryOp:
 		return t.convertUnaryOp(value)
@@ -93,6 +101,86 @@ func (t *treeFormatter) convertSort(ast *Sort) *tree.Node {
 	return node
 }
 
+func (t *treeFormatter) convertParse(ast *Parse) *tree.Node {
+	extractions := make([]any, len(ast.Extractions))
+	for i, e := range ast.Extractions {
+		extractions[i] = e.Identifier + "=" + e.Expression
+	}
+
+	node := tree.NewNode("PARSE", ast.Name(),
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("kind", false, ast.Kind),
+		tree.NewProperty("expression", false, ast.Expression),
+		tree.NewProperty("extractions", true, extractions...),
+		tree.NewProperty("strict", false, ast.Strict),
+		tree.NewProperty("keep_empty", false, ast.KeepEmpty),
+	)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
+func (t *treeFormatter) convertTransform(ast *Transform) *tree.Node {
+	formats := make([]any, len(ast.LabelFormats))
+	for i, f := range ast.LabelFormats {
+		formats[i] = f.Name + "=" + f.Value
+	}
+	matchers := make([]any, len(ast.Labels))
+	for i, m := range ast.Labels {
+		if m.Matcher != nil {
+			matchers[i] = m.Matcher.String()
+			continue
+		}
+		matchers[i] = m.Name
+	}
+
+	node := tree.NewNode("TRANSFORM", ast.Name(),
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("kind", false, ast.Kind),
+		tree.NewProperty("template", false, ast.Template),
+		tree.NewProperty("formats", true, formats...),
+		tree.NewProperty("labels", true, matchers...),
+	)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
+func (t *treeFormatter) convertRangeAggregation(ast *RangeAggregation) *tree.Node {
+	node := tree.NewNode("RANGE_AGGREGATION", ast.Name(),
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("operation", false, ast.Operation),
+		tree.NewProperty("start", false, ast.Start.UnixNano()),
+		tree.NewProperty("end", false, ast.End.UnixNano()),
+		tree.NewProperty("step", false, ast.Step),
+		tree.NewProperty("range", false, ast.RangeInterval),
+		tree.NewProperty("partition_by", true, columnRefNames(ast.PartitionBy)...),
+	)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
+func (t *treeFormatter) convertVectorAggregation(ast *VectorAggregation) *tree.Node {
+	grouping := "group_by"
+	if ast.Without {
+		grouping = "group_without"
+	}
+
+	node := tree.NewNode("VECTOR_AGGREGATION", ast.Name(),
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("operation", false, ast.Operation),
+		tree.NewProperty(grouping, true, columnRefNames(ast.GroupBy)...),
+	)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
+func columnRefNames(refs []ColumnRef) []any {
+	names := make([]any, len(refs))
+	for i := range refs {
+		names[i] = refs[i].Name()
+	}
+	return names
+}
+
 func (t *treeFormatter) convertUnaryOp(expr *UnaryOp) *tree.Node {
 	node := tree.NewNode("UnaryOp", expr.Name(),
 		tree.NewProperty("op", false, expr.Op.String()),
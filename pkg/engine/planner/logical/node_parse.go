@@ -0,0 +1,83 @@
+package logical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// The Parse instruction extracts labels from the log line of each row of a
+// table relation and adds them as parsed columns. Parse implements both
+// [Instruction] and [Value].
+type Parse struct {
+	id string
+
+	Table Value // The table relation to parse.
+
+	Kind types.ParserType // The parser used to extract labels.
+
+	// Expression is the regular expression of the regexp parser or the
+	// pattern of the pattern parser.
+	Expression string
+
+	// Extractions restricts the json and logfmt parsers to extract only the
+	// given labels. If empty, all labels are extracted.
+	Extractions []log.LabelExtractionExpr
+
+	Strict    bool // Stop parsing logfmt lines on the first error.
+	KeepEmpty bool // Keep logfmt keys with empty values.
+}
+
+var (
+	_ Value       = (*Parse)(nil)
+	_ Instruction = (*Parse)(nil)
+)
+
+// Name returns an identifier for the Parse operation.
+func (p *Parse) Name() string {
+	if p.id != "" {
+		return p.id
+	}
+	return fmt.Sprintf("%p", p)
+}
+
+// String returns the disassembled SSA form of the Parse instruction.
+func (p *Parse) String() string {
+	props := fmt.Sprintf("kind=%s", p.Kind)
+	if p.Expression != "" {
+		props += fmt.Sprintf(", expression=%s", strconv.Quote(p.Expression))
+	}
+	if len(p.Extractions) > 0 {
+		props += fmt.Sprintf(", extractions=(%s)", extractionsString(p.Extractions))
+	}
+	if p.Strict {
+		props += ", strict=true"
+	}
+	if p.KeepEmpty {
+		props += ", keep_empty=true"
+	}
+	return fmt.Sprintf("PARSE %s [%s]", p.Table.Name(), props)
+}
+
+// Schema returns the schema of the Parse plan.
+func (p *Parse) Schema() *schema.Schema {
+	// The parsed columns depend on the content of the log lines and are not
+	// known at planning time, so only the columns of the input table relation
+	// are known.
+	return p.Table.Schema()
+}
+
+func (p *Parse) isInstruction() {}
+func (p *Parse) isValue()       {}
+
+func extractionsString(extractions []log.LabelExtractionExpr) string {
+	parts := make([]string, len(extractions))
+	for i, e := range extractions {
+		parts[i] = e.Identifier + "=" + strconv.Quote(e.Expression)
+	}
+	return strings.Join(parts, ", ")
+}
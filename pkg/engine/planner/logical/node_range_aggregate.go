@@ -0,0 +1,76 @@
+package logical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+)
+
+// The RangeAggregation instruction aggregates the rows of a table relation
+// into samples. For each step between Start and End, all rows with a
+// timestamp within the left-open range (step-RangeInterval, step] are
+// aggregated. RangeAggregation implements both [Instruction] and [Value].
+type RangeAggregation struct {
+	id string
+
+	Table Value // The table relation to aggregate.
+
+	Operation types.RangeAggregationType // The aggregation to perform.
+
+	// PartitionBy is the set of columns by which rows are partitioned into
+	// series. If empty, rows are partitioned by all of their stream labels
+	// and structured metadata.
+	PartitionBy []ColumnRef
+
+	Start         time.Time     // Timestamp of the first step.
+	End           time.Time     // Timestamp of the last step.
+	Step          time.Duration // Duration between steps. A Step of 0 produces a single step at End.
+	RangeInterval time.Duration // Length of the range of each step.
+}
+
+var (
+	_ Value       = (*RangeAggregation)(nil)
+	_ Instruction = (*RangeAggregation)(nil)
+)
+
+// Name returns an identifier for the RangeAggregation operation.
+func (r *RangeAggregation) Name() string {
+	if r.id != "" {
+		return r.id
+	}
+	return fmt.Sprintf("%p", r)
+}
+
+// String returns the disassembled SSA form of the RangeAggregation
+// instruction.
+func (r *RangeAggregation) String() string {
+	props := fmt.Sprintf(
+		"operation=%s, start_ts=%d, end_ts=%d, step=%s, range=%s",
+		r.Operation, r.Start.UnixNano(), r.End.UnixNano(), r.Step, r.RangeInterval,
+	)
+	if len(r.PartitionBy) > 0 {
+		props += fmt.Sprintf(", partition_by=(%s)", columnRefsString(r.PartitionBy))
+	}
+	return fmt.Sprintf("RANGE_AGGREGATION %s [%s]", r.Table.Name(), props)
+}
+
+// Schema returns the schema of the range aggregation.
+func (r *RangeAggregation) Schema() *schema.Schema {
+	// TODO: The schema depends on the labels of the aggregated rows, which
+	// are not known at planning time.
+	return nil
+}
+
+func (r *RangeAggregation) isInstruction() {}
+func (r *RangeAggregation) isValue()       {}
+
+func columnRefsString(refs []ColumnRef) string {
+	names := make([]string, len(refs))
+	for i := range refs {
+		names[i] = refs[i].String()
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,97 @@
+package logical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// The Transform instruction computes the log line or labels of each row of a
+// table relation from templates, or removes labels from it. Transform
+// implements both [Instruction] and [Value].
+type Transform struct {
+	id string
+
+	Table Value // The table relation to transform.
+
+	Kind types.TransformType // The kind of transformation.
+
+	// Template is the template of the line_format transformation used to
+	// compute the new log line.
+	Template string
+
+	// LabelFormats are the renames and templates of the label_format
+	// transformation.
+	LabelFormats []log.LabelFmt
+
+	// Labels are the labels kept by the keep transformation or removed by
+	// the drop transformation. Labels with a matcher are only kept or removed
+	// if their value matches.
+	Labels []log.NamedLabelMatcher
+}
+
+var (
+	_ Value       = (*Transform)(nil)
+	_ Instruction = (*Transform)(nil)
+)
+
+// Name returns an identifier for the Transform operation.
+func (t *Transform) Name() string {
+	if t.id != "" {
+		return t.id
+	}
+	return fmt.Sprintf("%p", t)
+}
+
+// String returns the disassembled SSA form of the Transform instruction.
+func (t *Transform) String() string {
+	props := fmt.Sprintf("kind=%s", t.Kind)
+	if t.Template != "" {
+		props += fmt.Sprintf(", template=%s", strconv.Quote(t.Template))
+	}
+	if len(t.LabelFormats) > 0 {
+		props += fmt.Sprintf(", formats=(%s)", labelFormatsString(t.LabelFormats))
+	}
+	if len(t.Labels) > 0 {
+		props += fmt.Sprintf(", labels=(%s)", namedMatchersString(t.Labels))
+	}
+	return fmt.Sprintf("TRANSFORM %s [%s]", t.Table.Name(), props)
+}
+
+// Schema returns the schema of the Transform plan.
+func (t *Transform) Schema() *schema.Schema {
+	// Like parsed columns, the columns created or removed by a Transform
+	// depend on the labels of each row and are not known at planning time.
+	return t.Table.Schema()
+}
+
+func (t *Transform) isInstruction() {}
+func (t *Transform) isValue()       {}
+
+func labelFormatsString(formats []log.LabelFmt) string {
+	parts := make([]string, len(formats))
+	for i, f := range formats {
+		if f.Rename {
+			parts[i] = f.Name + "=" + f.Value
+			continue
+		}
+		parts[i] = f.Name + "=" + strconv.Quote(f.Value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func namedMatchersString(matchers []log.NamedLabelMatcher) string {
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		if m.Matcher != nil {
+			parts[i] = m.Matcher.String()
+			continue
+		}
+		parts[i] = m.Name
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,61 @@
+package logical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+)
+
+// The VectorAggregation instruction aggregates the samples of a table
+// relation that share the same timestamp into groups. VectorAggregation
+// implements both [Instruction] and [Value].
+type VectorAggregation struct {
+	id string
+
+	Table Value // The table relation to aggregate.
+
+	Operation types.VectorAggregationType // The aggregation to perform.
+
+	// GroupBy is the set of columns by which samples are grouped. If Without
+	// is true, samples are instead grouped by all columns except the ones in
+	// GroupBy.
+	GroupBy []ColumnRef
+	Without bool
+}
+
+var (
+	_ Value       = (*VectorAggregation)(nil)
+	_ Instruction = (*VectorAggregation)(nil)
+)
+
+// Name returns an identifier for the VectorAggregation operation.
+func (v *VectorAggregation) Name() string {
+	if v.id != "" {
+		return v.id
+	}
+	return fmt.Sprintf("%p", v)
+}
+
+// String returns the disassembled SSA form of the VectorAggregation
+// instruction.
+func (v *VectorAggregation) String() string {
+	grouping := "group_by"
+	if v.Without {
+		grouping = "group_without"
+	}
+	return fmt.Sprintf(
+		"VECTOR_AGGREGATION %s [operation=%s, %s=(%s)]",
+		v.Table.Name(), v.Operation, grouping, columnRefsString(v.GroupBy),
+	)
+}
+
+// Schema returns the schema of the vector aggregation.
+func (v *VectorAggregation) Schema() *schema.Schema {
+	// TODO: The schema depends on the labels of the aggregated samples, which
+	// are not known at planning time.
+	return nil
+}
+
+func (v *VectorAggregation) isInstruction() {}
+func (v *VectorAggregation) isValue()       {}
@@ -18,44 +18,21 @@ var errUnimplemented = errors.New("query contains unimplemented features")
 // BuildPlan converts a LogQL query represented as [logql.Params] into a logical [Plan].
 // It may return an error as second argument in case the traversal of the AST of the query fails.
 func BuildPlan(query logql.Params) (*Plan, error) {
-	var selector Value
-	var predicates []Value
-
-	// TODO(chaudum): Implement a Walk function that can return an error
-	var err error
-
-	expr := query.GetExpression()
-	expr.Walk(func(e syntax.Expr) bool {
-		switch e := e.(type) {
-		case syntax.SampleExpr:
-			err = errUnimplemented
-			return false // do not traverse children
-		case *syntax.LineParserExpr, *syntax.LogfmtParserExpr, *syntax.LogfmtExpressionParserExpr, *syntax.JSONExpressionParserExpr:
-			err = errUnimplemented
-			return false // do not traverse children
-		case *syntax.LineFmtExpr, *syntax.LabelFmtExpr:
-			err = errUnimplemented
-			return false // do not traverse children
-		case *syntax.KeepLabelsExpr, *syntax.DropLabelsExpr:
-			err = errUnimplemented
-			return false // do not traverse children
-		case *syntax.MatchersExpr:
-			selector = convertLabelMatchers(e.Matchers())
-		case *syntax.LineFilterExpr:
-			predicates = append(predicates, convertLineFilterExpr(e))
-			// We do not want to traverse the AST further down, because line filter expressions can be nested,
-			// which would lead to multiple predicates of the same expression.
-			return false
-		case *syntax.LabelFilterExpr:
-			if val, innerErr := convertLabelFilter(e.LabelFilterer); innerErr != nil {
-				err = innerErr
-			} else {
-				predicates = append(predicates, val)
-			}
-		}
-		return true
-	})
+	switch expr := query.GetExpression().(type) {
+	case syntax.LogSelectorExpr:
+		return buildPlanForLogQuery(expr, query)
+	case syntax.SampleExpr:
+		return buildPlanForSampleQuery(expr, query)
+	default:
+		return nil, fmt.Errorf("failed to convert AST into logical plan: %w", errUnimplemented)
+	}
+}
 
+// buildPlanForLogQuery builds the plan of a log query, which returns the log
+// lines matching the query, sorted by timestamp in the direction of the
+// query.
+func buildPlanForLogQuery(expr syntax.LogSelectorExpr, query logql.Params) (*Plan, error) {
+	selector, stages, err := convertLogSelector(expr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert AST into logical plan: %w", err)
 	}
@@ -79,8 +56,9 @@ func BuildPlan(query logql.Params) (*Plan, error) {
 		builder = builder.Select(value)
 	}
 
-	for _, value := range predicates {
-		builder = builder.Select(value)
+	// SELECT -> Filter, PARSE -> Parse, TRANSFORM -> Transform
+	for _, stage := range stages {
+		builder = stage(builder)
 	}
 
 	// LIMIT -> Limit
@@ -91,6 +69,243 @@ func BuildPlan(query logql.Params) (*Plan, error) {
 	return plan, err
 }
 
+// buildPlanForSampleQuery builds the plan of a metric query. Supported are
+// range aggregations over log lines, optionally wrapped in a single vector
+// aggregation.
+func buildPlanForSampleQuery(expr syntax.SampleExpr, query logql.Params) (*Plan, error) {
+	var vectorAgg *syntax.VectorAggregationExpr
+	if e, ok := expr.(*syntax.VectorAggregationExpr); ok {
+		vectorAgg = e
+		expr = e.Left
+	}
+
+	rangeAgg, ok := expr.(*syntax.RangeAggregationExpr)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert AST into logical plan: %w", errUnimplemented)
+	}
+	rangeOp, err := convertRangeAggregationType(rangeAgg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert AST into logical plan: %w", err)
+	}
+
+	var vectorOp types.VectorAggregationType
+	if vectorAgg != nil {
+		if vectorOp, err = convertVectorAggregationType(vectorAgg); err != nil {
+			return nil, fmt.Errorf("failed to convert AST into logical plan: %w", err)
+		}
+	}
+
+	logRange := rangeAgg.Left
+	selector, stages, err := convertLogSelector(logRange.Left)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert AST into logical plan: %w", err)
+	}
+
+	// MAKETABLE -> DataObjScan
+	builder := NewBuilder(
+		&MakeTable{
+			Selector: selector,
+		},
+	)
+
+	// SELECT -> Filter
+	// The first step covers the range (start-interval, start], the last step
+	// the range (end-interval, end].
+	start := query.Start().Add(-logRange.Interval).UnixNano()
+	end := query.End().UnixNano()
+	for _, value := range convertSampleRangeToPredicates(start, end) {
+		builder = builder.Select(value)
+	}
+
+	// SELECT -> Filter, PARSE -> Parse, TRANSFORM -> Transform
+	for _, stage := range stages {
+		builder = stage(builder)
+	}
+
+	// RANGE_AGGREGATION -> RangeAggregation
+	builder = builder.RangeAggregation(rangeOp, nil, query.Start(), query.End(), query.Step(), logRange.Interval)
+
+	// VECTOR_AGGREGATION -> VectorAggregation
+	if vectorAgg != nil {
+		var groupBy []ColumnRef
+		var without bool
+		if vectorAgg.Grouping != nil {
+			without = vectorAgg.Grouping.Without
+			for _, name := range vectorAgg.Grouping.Groups {
+				groupBy = append(groupBy, *NewColumnRef(name, types.ColumnTypeAmbiguous))
+			}
+		}
+		builder = builder.VectorAggregation(vectorOp, groupBy, without)
+	}
+
+	return builder.ToPlan()
+}
+
+// logStage applies a single stage of a log pipeline onto a [Builder].
+type logStage func(*Builder) *Builder
+
+// convertLogSelector converts a log selector expression into the selector of
+// a [MakeTable] and the stages of its pipeline, which are applied in order on
+// the resulting table.
+func convertLogSelector(expr syntax.LogSelectorExpr) (Value, []logStage, error) {
+	var selector Value
+	var stages []logStage
+
+	selectStage := func(predicate Value) logStage {
+		return func(b *Builder) *Builder { return b.Select(predicate) }
+	}
+	parseStage := func(parse Parse) logStage {
+		return func(b *Builder) *Builder { return b.Parse(parse) }
+	}
+	transformStage := func(transform Transform) logStage {
+		return func(b *Builder) *Builder { return b.Transform(transform) }
+	}
+
+	// TODO(chaudum): Implement a Walk function that can return an error
+	var err error
+
+	expr.Walk(func(e syntax.Expr) bool {
+		switch e := e.(type) {
+		case syntax.SampleExpr:
+			err = errUnimplemented
+			return false // do not traverse children
+		case *syntax.LineParserExpr:
+			parse, innerErr := convertLineParser(e)
+			if innerErr != nil {
+				err = innerErr
+				return false
+			}
+			stages = append(stages, parseStage(parse))
+		case *syntax.LogfmtParserExpr:
+			stages = append(stages, parseStage(Parse{
+				Kind:      types.ParserTypeLogfmt,
+				Strict:    e.Strict,
+				KeepEmpty: e.KeepEmpty,
+			}))
+		case *syntax.LogfmtExpressionParserExpr:
+			stages = append(stages, parseStage(Parse{
+				Kind:        types.ParserTypeLogfmt,
+				Extractions: e.Expressions,
+				Strict:      e.Strict,
+				KeepEmpty:   e.KeepEmpty,
+			}))
+		case *syntax.JSONExpressionParserExpr:
+			stages = append(stages, parseStage(Parse{
+				Kind:        types.ParserTypeJSON,
+				Extractions: e.Expressions,
+			}))
+		case *syntax.LineFmtExpr:
+			stages = append(stages, transformStage(Transform{
+				Kind:     types.TransformTypeLineFormat,
+				Template: e.Value,
+			}))
+		case *syntax.LabelFmtExpr:
+			stages = append(stages, transformStage(Transform{
+				Kind:         types.TransformTypeLabelFormat,
+				LabelFormats: e.Formats,
+			}))
+		case *syntax.KeepLabelsExpr:
+			stages = append(stages, transformStage(Transform{
+				Kind:   types.TransformTypeKeep,
+				Labels: e.Labels(),
+			}))
+		case *syntax.DropLabelsExpr:
+			stages = append(stages, transformStage(Transform{
+				Kind:   types.TransformTypeDrop,
+				Labels: e.Labels(),
+			}))
+		case *syntax.MatchersExpr:
+			selector = convertLabelMatchers(e.Matchers())
+		case *syntax.LineFilterExpr:
+			stages = append(stages, selectStage(convertLineFilterExpr(e)))
+			// We do not want to traverse the AST further down, because line filter expressions can be nested,
+			// which would lead to multiple predicates of the same expression.
+			return false
+		case *syntax.LabelFilterExpr:
+			if val, innerErr := convertLabelFilter(e.LabelFilterer); innerErr != nil {
+				err = innerErr
+			} else {
+				stages = append(stages, selectStage(val))
+			}
+		case *syntax.PipelineExpr:
+			// The selector and the stages of the pipeline are visited as
+			// its children.
+		default:
+			// Stages which can't be planned, such as decolorize, must not
+			// be dropped from the pipeline, as the query would return
+			// different results.
+			err = fmt.Errorf("unsupported pipeline stage %q: %w", e.String(), errUnimplemented)
+			return false
+		}
+		return true
+	})
+
+	return selector, stages, err
+}
+
+// convertLineParser converts a json, regexp, pattern, or unpack parser
+// expression into a [Parse] operation.
+func convertLineParser(expr *syntax.LineParserExpr) (Parse, error) {
+	switch expr.Op {
+	case syntax.OpParserTypeJSON:
+		return Parse{Kind: types.ParserTypeJSON}, nil
+	case syntax.OpParserTypeRegexp:
+		return Parse{Kind: types.ParserTypeRegexp, Expression: expr.Param}, nil
+	case syntax.OpParserTypePattern:
+		return Parse{Kind: types.ParserTypePattern, Expression: expr.Param}, nil
+	case syntax.OpParserTypeUnpack:
+		return Parse{Kind: types.ParserTypeUnpack}, nil
+	default:
+		return Parse{}, fmt.Errorf("unknown parser %s: %w", expr.Op, errUnimplemented)
+	}
+}
+
+// convertRangeAggregationType returns the type of the range aggregation
+// expression. Range aggregations over unwrapped labels, with grouping, or
+// with an offset are not supported.
+func convertRangeAggregationType(expr *syntax.RangeAggregationExpr) (types.RangeAggregationType, error) {
+	if expr.Left.Unwrap != nil || expr.Left.Offset != 0 || expr.Grouping != nil || expr.Params != nil {
+		return types.RangeAggregationTypeInvalid, errUnimplemented
+	}
+
+	switch expr.Operation {
+	case syntax.OpRangeTypeCount:
+		return types.RangeAggregationTypeCount, nil
+	case syntax.OpRangeTypeRate:
+		return types.RangeAggregationTypeRate, nil
+	case syntax.OpRangeTypeBytes:
+		return types.RangeAggregationTypeBytes, nil
+	case syntax.OpRangeTypeBytesRate:
+		return types.RangeAggregationTypeBytesRate, nil
+	default:
+		return types.RangeAggregationTypeInvalid, errUnimplemented
+	}
+}
+
+// convertVectorAggregationType returns the type of the vector aggregation
+// expression. Vector aggregations with parameters, such as topk, are not
+// supported.
+func convertVectorAggregationType(expr *syntax.VectorAggregationExpr) (types.VectorAggregationType, error) {
+	if expr.Params != 0 {
+		return types.VectorAggregationTypeInvalid, errUnimplemented
+	}
+
+	switch expr.Operation {
+	case syntax.OpTypeSum:
+		return types.VectorAggregationTypeSum, nil
+	case syntax.OpTypeMin:
+		return types.VectorAggregationTypeMin, nil
+	case syntax.OpTypeMax:
+		return types.VectorAggregationTypeMax, nil
+	case syntax.OpTypeCount:
+		return types.VectorAggregationTypeCount, nil
+	case syntax.OpTypeAvg:
+		return types.VectorAggregationTypeAvg, nil
+	default:
+		return types.VectorAggregationTypeInvalid, errUnimplemented
+	}
+}
+
 func convertLabelMatchers(matchers []*labels.Matcher) Value {
 	var value *BinOp
 
@@ -181,9 +396,9 @@ func logColumnRef() *ColumnRef {
 func convertLabelMatchType(op labels.MatchType) types.BinaryOp {
 	switch op {
 	case labels.MatchEqual:
-		return types.BinaryOpMatchSubstr
+		return types.BinaryOpEq
 	case labels.MatchNotEqual:
-		return types.BinaryOpNotMatchSubstr
+		return types.BinaryOpNeq
 	case labels.MatchRegexp:
 		return types.BinaryOpMatchRe
 	case labels.MatchNotRegexp:
@@ -249,3 +464,21 @@ func convertQueryRangeToPredicates(start, end int64) []*BinOp {
 		},
 	}
 }
+
+// convertSampleRangeToPredicates returns the predicates for selecting the
+// rows of a metric query. Contrary to log queries, the range is left-open and
+// right-closed, matching the ranges of the range aggregation.
+func convertSampleRangeToPredicates(start, end int64) []*BinOp {
+	return []*BinOp{
+		{
+			Left:  timestampColumnRef(),
+			Right: NewLiteral(uint64(start)),
+			Op:    types.BinaryOpGt,
+		},
+		{
+			Left:  timestampColumnRef(),
+			Right: NewLiteral(uint64(end)),
+			Op:    types.BinaryOpLte,
+		},
+	}
+}
@@ -16,6 +16,7 @@ import (
 type query struct {
 	statement  string
 	start, end int64
+	step       time.Duration
 	direction  logproto.Direction
 	limit      uint32
 }
@@ -72,7 +73,7 @@ func (q *query) Shards() []string {
 
 // Step implements logql.Params.
 func (q *query) Step() time.Duration {
-	panic("unimplemented")
+	return q.step
 }
 
 var _ logql.Params = (*query)(nil)
@@ -98,8 +99,8 @@ func TestConvertAST_Success(t *testing.T) {
 %7 = SELECT %5 [predicate=%6]
 %8 = LT builtin.timestamp 2000
 %9 = SELECT %7 [predicate=%8]
-%10 = MATCH_STR ambiguous.foo "bar"
-%11 = MATCH_STR ambiguous.bar "baz"
+%10 = EQ ambiguous.foo "bar"
+%11 = EQ ambiguous.bar "baz"
 %12 = OR %10 %11
 %13 = SELECT %9 [predicate=%12]
 %14 = MATCH_STR builtin.log "metric.go"
@@ -120,6 +121,111 @@ RETURN %20
 	t.Logf("\n%s\n", sb.String())
 }
 
+func TestConvertAST_SampleQuery(t *testing.T) {
+	q := &query{
+		statement: `sum by (app) (rate({cluster="prod"} |= "error" [5m]))`,
+		start:     3_600_000_000_000,
+		end:       7_200_000_000_000,
+		step:      time.Minute,
+		direction: logproto.FORWARD,
+		limit:     1000,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1]
+%3 = GT builtin.timestamp 3300000000000
+%4 = SELECT %2 [predicate=%3]
+%5 = LTE builtin.timestamp 7200000000000
+%6 = SELECT %4 [predicate=%5]
+%7 = MATCH_STR builtin.log "error"
+%8 = SELECT %6 [predicate=%7]
+%9 = RANGE_AGGREGATION %8 [operation=rate, start_ts=3600000000000, end_ts=7200000000000, step=1m0s, range=5m0s]
+%10 = VECTOR_AGGREGATION %9 [operation=sum, group_by=(ambiguous.app)]
+RETURN %10
+`
+	require.Equal(t, expected, logicalPlan.String())
+
+	var sb strings.Builder
+	PrintTree(&sb, logicalPlan.Value())
+
+	t.Logf("\n%s\n", sb.String())
+}
+
+func TestConvertAST_Parse(t *testing.T) {
+	q := &query{
+		statement: `{cluster="prod"} |= "error" | logfmt --strict | level="error" | json status="response.status" | regexp "took (?P<duration>\\S+)"`,
+		start:     1000,
+		end:       2000,
+		direction: logproto.BACKWARD,
+		limit:     100,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1]
+%3 = SORT %2 [column=builtin.timestamp, asc=false, nulls_first=false]
+%4 = GTE builtin.timestamp 1000
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 2000
+%7 = SELECT %5 [predicate=%6]
+%8 = MATCH_STR builtin.log "error"
+%9 = SELECT %7 [predicate=%8]
+%10 = PARSE %9 [kind=logfmt, strict=true]
+%11 = EQ ambiguous.level "error"
+%12 = SELECT %10 [predicate=%11]
+%13 = PARSE %12 [kind=json, extractions=(status="response.status")]
+%14 = PARSE %13 [kind=regexp, expression="took (?P<duration>\\S+)"]
+%15 = LIMIT %14 [skip=0, fetch=100]
+RETURN %15
+`
+	require.Equal(t, expected, logicalPlan.String())
+
+	var sb strings.Builder
+	PrintTree(&sb, logicalPlan.Value())
+
+	t.Logf("\n%s\n", sb.String())
+}
+
+func TestConvertAST_Transform(t *testing.T) {
+	q := &query{
+		statement: `{cluster="prod"} | logfmt | line_format "{{.msg}}" | label_format dst=src, env="{{.cluster}}" | drop pod | keep level="error", env`,
+		start:     1000,
+		end:       2000,
+		direction: logproto.FORWARD,
+		limit:     100,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1]
+%3 = SORT %2 [column=builtin.timestamp, asc=true, nulls_first=false]
+%4 = GTE builtin.timestamp 1000
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 2000
+%7 = SELECT %5 [predicate=%6]
+%8 = PARSE %7 [kind=logfmt]
+%9 = TRANSFORM %8 [kind=line_format, template="{{.msg}}"]
+%10 = TRANSFORM %9 [kind=label_format, formats=(dst=src, env="{{.cluster}}")]
+%11 = TRANSFORM %10 [kind=drop, labels=(pod)]
+%12 = TRANSFORM %11 [kind=keep, labels=(level="error", env)]
+%13 = LIMIT %12 [skip=0, fetch=100]
+RETURN %13
+`
+	require.Equal(t, expected, logicalPlan.String())
+
+	var sb strings.Builder
+	PrintTree(&sb, logicalPlan.Value())
+
+	t.Logf("\n%s\n", sb.String())
+}
+
 func TestCanExecuteQuery(t *testing.T) {
 	for _, tt := range []struct {
 		statement string
@@ -147,39 +253,93 @@ func TestCanExecuteQuery(t *testing.T) {
 		},
 		{
 			statement: `{env="prod"} | json`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | json foo="bar"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | logfmt`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | logfmt foo="bar"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | pattern "<_> foo=<foo> <_>"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | regexp ".* foo=(?P<foo>.+) .*"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | unpack`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} |= "metrics.go" | logfmt`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | line_format "{.cluster}"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | label_format cluster="us"`,
+			expected:  true,
+		},
+		{
+			statement: `{env="prod"} | keep cluster, level="error"`,
+			expected:  true,
+		},
+		{
+			statement: `{env="prod"} | drop cluster`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} |= "metric.go" | retry > 2`,
 		},
+		{
+			// Unsupported stages fail the conversion rather than being
+			// dropped from the pipeline.
+			statement: `{env="prod"} | decolorize`,
+		},
+		{
+			statement: `count_over_time({env="prod"} | decolorize [5m])`,
+		},
 		{
 			statement: `sum(rate({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `count_over_time({env="prod"} |= "error" [5m])`,
+			expected:  true,
+		},
+		{
+			statement: `sum without (pod) (bytes_rate({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `topk(10, rate({env="prod"}[1m]))`,
+		},
+		{
+			statement: `rate({env="prod"}[1m] offset 5m)`,
+		},
+		{
+			statement: `sum(rate({env="prod"} | json [1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum_over_time({env="prod"} | unwrap bytes [1m])`,
+		},
+		{
+			statement: `sum(sum by (app) (rate({env="prod"}[1m])))`,
+		},
+		{
+			statement: `rate({env="prod"}[1m]) > 1`,
 		},
 	} {
 		t.Run(tt.statement, func(t *testing.T) {
@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/thanos-io/objstore"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/grafana/loki/v3/pkg/dataobj"
 	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
 
 var (
@@ -21,26 +26,38 @@ var (
 	}
 )
 
+// statisticsParallelism is the maximum number of data objects whose
+// statistics are read concurrently.
+const statisticsParallelism = 64
+
 // Catalog is an interface that provides methods for interacting with
 // storage metadata. In traditional database systems there are system tables
 // providing this information (e.g. pg_catalog, ...) whereas in Loki there
 // is the Metastore.
 type Catalog interface {
 	ResolveDataObj(Expression) ([]DataObjLocation, [][]int64, error)
+	// ResolveDataObjStatistics resolves the statistics of the logs sections
+	// of the data objects at the given locations. Data objects without
+	// known statistics are omitted from the result.
+	ResolveDataObjStatistics([]DataObjLocation) (Statistics, error)
 }
 
 // Context is the default implementation of [Catalog].
 type Context struct {
 	ctx           context.Context
 	metastore     metastore.Metastore
+	bucket        objstore.Bucket
 	from, through time.Time
 }
 
-// NewContext creates a new instance of [Context] for query planning.
-func NewContext(ctx context.Context, ms metastore.Metastore, from, through time.Time) *Context {
+// NewContext creates a new instance of [Context] for query planning. The
+// bucket is used to read the statistics of data objects and may be nil, in
+// which case no statistics are resolved.
+func NewContext(ctx context.Context, ms metastore.Metastore, bucket objstore.Bucket, from, through time.Time) *Context {
 	return &Context{
 		ctx:       ctx,
 		metastore: ms,
+		bucket:    bucket,
 		from:      from,
 		through:   through,
 	}
@@ -71,6 +88,45 @@ func (c *Context) ResolveDataObj(selector Expression) ([]DataObjLocation, [][]in
 	return locations, streamIDs, err
 }
 
+// ResolveDataObjStatistics resolves the statistics of the logs sections of
+// the data objects at the given locations by reading their metadata. The
+// metadata is read through the bucket of the context, which caches it if the
+// bucket is wrapped by the data object cache.
+//
+// Statistics are an optimization only, so data objects whose metadata
+// cannot be read are logged and omitted from the result, and the query
+// reads them without pruning.
+func (c *Context) ResolveDataObjStatistics(locations []DataObjLocation) (Statistics, error) {
+	if c.bucket == nil || len(locations) == 0 {
+		return nil, nil
+	}
+
+	sections := make([][]dataobj.LogsSectionStatistics, len(locations))
+	errs := make([]error, len(locations))
+
+	var g errgroup.Group
+	g.SetLimit(statisticsParallelism)
+
+	for i, loc := range locations {
+		g.Go(func() error {
+			sections[i], errs[i] = dataobj.FromBucket(c.bucket, string(loc)).LogsStatistics(c.ctx)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	logger := util_log.WithContext(c.ctx, util_log.Logger)
+	statistics := make(Statistics, len(locations))
+	for i, loc := range locations {
+		if errs[i] != nil {
+			level.Warn(logger).Log("msg", "failed to read statistics of data object", "location", loc, "err", errs[i])
+			continue
+		}
+		statistics[loc] = sections[i]
+	}
+	return statistics, nil
+}
+
 func expressionToMatchers(selector Expression) ([]*labels.Matcher, error) {
 	if selector == nil {
 		return nil, nil
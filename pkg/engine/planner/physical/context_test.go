@@ -1,10 +1,14 @@
 package physical
 
 import (
+	"bytes"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
 
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 )
@@ -160,3 +164,16 @@ func TestContext_ExpressionToMatchers(t *testing.T) {
 		})
 	}
 }
+
+func TestContext_ResolveDataObjStatistics_Unreadable(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	require.NoError(t, bucket.Upload(ctx, "objects/corrupt", bytes.NewReader([]byte("not a data object"))))
+
+	// Objects whose statistics cannot be read are omitted instead of failing
+	// the query.
+	c := NewContext(ctx, nil, bucket, time.Unix(0, 0), time.Unix(3600, 0))
+	statistics, err := c.ResolveDataObjStatistics([]DataObjLocation{"objects/corrupt", "objects/missing"})
+	require.NoError(t, err)
+	require.Empty(t, statistics)
+}
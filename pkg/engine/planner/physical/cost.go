@@ -0,0 +1,278 @@
+package physical
+
+import (
+	"slices"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// defaultSelectivity is the estimated fraction of rows that match a
+// predicate whose selectivity cannot be estimated from statistics.
+const defaultSelectivity = 0.5
+
+// Statistics holds the statistics of the logs sections of data objects by
+// their location. The optimizer uses them to estimate the cost of reading
+// data objects.
+type Statistics map[DataObjLocation][]dataobj.LogsSectionStatistics
+
+// sections returns the indexes and the statistics of the logs sections of
+// the data object read by scan. It returns false if the statistics of the
+// data object are unknown.
+func (s Statistics) sections(scan *DataObjScan) ([]int, []dataobj.LogsSectionStatistics, bool) {
+	stats, ok := s[scan.Location]
+	if !ok {
+		return nil, nil, false
+	}
+
+	if scan.Sections == nil {
+		indexes := make([]int, len(stats))
+		for i := range stats {
+			indexes[i] = i
+		}
+		return indexes, stats, true
+	}
+
+	indexes := make([]int, 0, len(scan.Sections))
+	sections := make([]dataobj.LogsSectionStatistics, 0, len(scan.Sections))
+	for _, i := range scan.Sections {
+		if i < 0 || i >= len(stats) {
+			// The statistics do not match the sections of the scan.
+			return nil, nil, false
+		}
+		indexes = append(indexes, i)
+		sections = append(sections, stats[i])
+	}
+	return indexes, sections, true
+}
+
+// canMatch returns false if no row of the logs section with statistics s can
+// match the stream IDs and the predicates of a scan. It returns true if the
+// statistics are not sufficient to rule out a match.
+func canMatch(s *dataobj.LogsSectionStatistics, streamIDs []int64, predicates []Expression) bool {
+	if s.Rows == 0 {
+		return false
+	}
+	if len(streamIDs) > 0 && !slices.ContainsFunc(streamIDs, func(id int64) bool {
+		return id >= s.MinStreamID && id <= s.MaxStreamID
+	}) {
+		return false
+	}
+	for _, predicate := range predicates {
+		if !predicateCanMatch(s, predicate) {
+			return false
+		}
+	}
+	return true
+}
+
+// predicateCanMatch returns false if no row of the logs section with
+// statistics s can match predicate.
+func predicateCanMatch(s *dataobj.LogsSectionStatistics, predicate Expression) bool {
+	expr, ok := predicate.(*BinaryExpr)
+	if !ok {
+		return true
+	}
+
+	switch expr.Op {
+	case types.BinaryOpAnd:
+		return predicateCanMatch(s, expr.Left) && predicateCanMatch(s, expr.Right)
+	case types.BinaryOpOr:
+		return predicateCanMatch(s, expr.Left) || predicateCanMatch(s, expr.Right)
+	}
+
+	col, lit, ok := comparison(expr)
+	if !ok {
+		return true
+	}
+	switch {
+	case isTimestampColumn(col) && lit.ValueType() == types.ValueTypeTimestamp:
+		return timestampSelectivity(s, expr.Op, int64(lit.Value.Timestamp())) > 0
+	case col.Ref.Type == types.ColumnTypeMetadata && lit.ValueType() == types.ValueTypeStr:
+		return metadataCanMatch(s, expr.Op, col.Ref.Column, lit.Value.Str())
+	}
+	return true
+}
+
+func metadataCanMatch(s *dataobj.LogsSectionStatistics, op types.BinaryOp, name, value string) bool {
+	// Rows without a value for a metadata key have an empty value.
+	column, ok := s.Metadata[name]
+	allSet := ok && column.Values >= s.Rows
+	noneSet := !ok || column.Values == 0
+
+	switch op {
+	case types.BinaryOpEq:
+		if value == "" {
+			return !allSet
+		}
+		if noneSet {
+			return false
+		}
+		return !column.HasRange || (value >= column.Min && value <= column.Max)
+	case types.BinaryOpNeq:
+		if value == "" {
+			return !noneSet
+		}
+		return !allSet || !column.HasRange || column.Min != value || column.Max != value
+	}
+	return true
+}
+
+// selectivity estimates the fraction of rows of the logs sections with
+// statistics sections that match predicate.
+func selectivity(sections []dataobj.LogsSectionStatistics, predicate Expression) float64 {
+	var rows, matched float64
+	for i := range sections {
+		rows += float64(sections[i].Rows)
+		matched += float64(sections[i].Rows) * sectionSelectivity(&sections[i], predicate)
+	}
+	if rows == 0 {
+		return 0
+	}
+	return matched / rows
+}
+
+// sectionSelectivity estimates the fraction of rows of the logs section with
+// statistics s that match predicate.
+func sectionSelectivity(s *dataobj.LogsSectionStatistics, predicate Expression) float64 {
+	if s.Rows == 0 || !predicateCanMatch(s, predicate) {
+		return 0
+	}
+
+	switch expr := predicate.(type) {
+	case *UnaryExpr:
+		if expr.Op == types.UnaryOpNot {
+			return 1 - sectionSelectivity(s, expr.Left)
+		}
+	case *BinaryExpr:
+		switch expr.Op {
+		case types.BinaryOpAnd:
+			// Predicates are assumed to be independent.
+			return sectionSelectivity(s, expr.Left) * sectionSelectivity(s, expr.Right)
+		case types.BinaryOpOr:
+			left, right := sectionSelectivity(s, expr.Left), sectionSelectivity(s, expr.Right)
+			return left + right - left*right
+		}
+
+		col, lit, ok := comparison(expr)
+		if !ok {
+			break
+		}
+		switch {
+		case isTimestampColumn(col) && lit.ValueType() == types.ValueTypeTimestamp:
+			return timestampSelectivity(s, expr.Op, int64(lit.Value.Timestamp()))
+		case col.Ref.Type == types.ColumnTypeMetadata && lit.ValueType() == types.ValueTypeStr:
+			return metadataSelectivity(s, expr.Op, col.Ref.Column, lit.Value.Str())
+		}
+	}
+	return defaultSelectivity
+}
+
+// timestampSelectivity estimates the fraction of rows of the logs section
+// with statistics s whose timestamp compares to ts with op. Timestamps are
+// assumed to be uniformly distributed between the oldest and the newest
+// log record of the section.
+func timestampSelectivity(s *dataobj.LogsSectionStatistics, op types.BinaryOp, ts int64) float64 {
+	if s.MinTime.IsZero() && s.MaxTime.IsZero() {
+		return defaultSelectivity
+	}
+	from, through := s.MinTime.UnixNano(), s.MaxTime.UnixNano()
+
+	// before is the estimated fraction of rows with a timestamp before ts,
+	// and at is the estimated fraction of rows with a timestamp equal to ts.
+	var before, at float64
+	switch {
+	case ts < from:
+		before, at = 0, 0
+	case ts > through:
+		before, at = 1, 0
+	case from == through:
+		before, at = 0, 1
+	default:
+		at = 1 / float64(s.Rows)
+		before = min(float64(ts-from)/float64(through-from), 1-at)
+	}
+
+	switch op {
+	case types.BinaryOpEq:
+		return at
+	case types.BinaryOpNeq:
+		return 1 - at
+	case types.BinaryOpLt:
+		return before
+	case types.BinaryOpLte:
+		return before + at
+	case types.BinaryOpGt:
+		return 1 - before - at
+	case types.BinaryOpGte:
+		return 1 - before
+	}
+	return defaultSelectivity
+}
+
+// metadataSelectivity estimates the fraction of rows of the logs section
+// with statistics s whose value for the metadata key name compares to value
+// with op. The values of a metadata column are assumed to be uniformly
+// distributed.
+func metadataSelectivity(s *dataobj.LogsSectionStatistics, op types.BinaryOp, name, value string) float64 {
+	var set, cardinality float64
+	if column, ok := s.Metadata[name]; ok {
+		set = min(float64(column.Values)/float64(s.Rows), 1)
+		cardinality = float64(max(column.Cardinality, 1))
+	}
+
+	var eq float64
+	if value == "" {
+		eq = 1 - set
+	} else if cardinality > 0 {
+		eq = set / cardinality
+	}
+
+	switch op {
+	case types.BinaryOpEq:
+		return eq
+	case types.BinaryOpNeq:
+		return 1 - eq
+	}
+	return defaultSelectivity
+}
+
+// comparison returns the column and the literal of a binary expression
+// comparing a column to a literal.
+func comparison(expr *BinaryExpr) (*ColumnExpr, *LiteralExpr, bool) {
+	col, ok := expr.Left.(*ColumnExpr)
+	if !ok {
+		return nil, nil, false
+	}
+	lit, ok := expr.Right.(*LiteralExpr)
+	if !ok {
+		return nil, nil, false
+	}
+	return col, lit, true
+}
+
+func isTimestampColumn(col *ColumnExpr) bool {
+	return col.Ref.Type == types.ColumnTypeBuiltin && col.Ref.Column == types.ColumnNameBuiltinTimestamp
+}
+
+// sectionsTimeRange returns the time range of the rows of the logs sections
+// with statistics sections. It returns a zero time range if there are no
+// sections or their time range is unknown.
+func sectionsTimeRange(sections []dataobj.LogsSectionStatistics) TimeRange {
+	var r TimeRange
+	for _, s := range sections {
+		if s.Rows == 0 {
+			continue
+		}
+		if s.MinTime.IsZero() && s.MaxTime.IsZero() {
+			return TimeRange{}
+		}
+		if r.IsZero() || s.MinTime.Before(r.Start) {
+			r.Start = s.MinTime
+		}
+		if r.IsZero() || s.MaxTime.After(r.End) {
+			r.End = s.MaxTime
+		}
+	}
+	return r
+}
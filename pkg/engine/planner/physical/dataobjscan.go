@@ -1,6 +1,9 @@
 package physical
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // DataObjLocation is a string that uniquely indentifies a data object location in
 // object storage.
@@ -40,6 +43,30 @@ type DataObjScan struct {
 	Direction Direction
 	// Limit is used to stop scanning the data object once it is reached.
 	Limit uint32
+	// Sections is the set of logs sections of the data object that are read.
+	// All sections are read if Sections is nil. The optimizer sets Sections
+	// if the statistics of the data object show that the other sections
+	// cannot contain matching rows.
+	Sections []int
+	// TimeRange is the time range of the rows of the sections that are read.
+	// It is set by the optimizer from the statistics of the data object and
+	// is zero if unknown.
+	TimeRange TimeRange
+}
+
+// TimeRange is an inclusive range of timestamps.
+type TimeRange struct {
+	Start, End time.Time
+}
+
+// IsZero returns true if the time range is unknown.
+func (r TimeRange) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero()
+}
+
+// String returns the string representation of the time range.
+func (r TimeRange) String() string {
+	return fmt.Sprintf("[%s, %s]", r.Start.UTC().Format(time.RFC3339Nano), r.End.UTC().Format(time.RFC3339Nano))
 }
 
 // ID implements the [Node] interface.
@@ -0,0 +1,478 @@
+package physical
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// The types below are the JSON representation of a [Fragment]. Enumerations,
+// such as node types and operators, are encoded by their numeric value, so
+// fragments can only be exchanged between processes of the same version.
+
+// encodedFragment is the JSON representation of a [Fragment]. Nodes refer
+// to their children by their index in Nodes.
+type encodedFragment struct {
+	ID    int           `json:"id"`
+	Nodes []encodedNode `json:"nodes"`
+}
+
+// encodedNode is the JSON representation of a [Node]. Only the fields of
+// the type of the node are set.
+type encodedNode struct {
+	Type     NodeType `json:"type"`
+	Children []int    `json:"children,omitempty"`
+
+	// DataObjScan
+	Location    DataObjLocation `json:"location,omitempty"`
+	StreamIDs   []int64         `json:"stream_ids,omitempty"`
+	Projections []*encodedExpr  `json:"projections,omitempty"`
+	Direction   Direction       `json:"direction,omitempty"`
+	Limit       uint32          `json:"limit,omitempty"`
+	// Sections is a pointer to tell a nil set of sections, which reads all
+	// sections of the object, from an empty set, which reads none.
+	Sections  *[]int     `json:"sections,omitempty"`
+	TimeRange *TimeRange `json:"time_range,omitempty"`
+
+	// DataObjScan and Filter
+	Predicates []*encodedExpr `json:"predicates,omitempty"`
+
+	// Projection
+	Columns []*encodedExpr `json:"columns,omitempty"`
+
+	// Parse
+	ParserKind  types.ParserType          `json:"parser_kind,omitempty"`
+	Expression  string                    `json:"expression,omitempty"`
+	Extractions []log.LabelExtractionExpr `json:"extractions,omitempty"`
+	Strict      bool                      `json:"strict,omitempty"`
+	KeepEmpty   bool                      `json:"keep_empty,omitempty"`
+
+	// Transform
+	TransformKind types.TransformType `json:"transform_kind,omitempty"`
+	Template      string              `json:"template,omitempty"`
+	LabelFormats  []log.LabelFmt      `json:"label_formats,omitempty"`
+	Labels        []encodedLabel      `json:"labels,omitempty"`
+
+	// Limit
+	Skip  uint32 `json:"skip,omitempty"`
+	Fetch uint32 `json:"fetch,omitempty"`
+}
+
+// encodedLabel is the JSON representation of a [log.NamedLabelMatcher].
+type encodedLabel struct {
+	Name    string          `json:"name,omitempty"`
+	Matcher *encodedMatcher `json:"matcher,omitempty"`
+}
+
+// encodedMatcher is the JSON representation of a [labels.Matcher].
+type encodedMatcher struct {
+	Type  labels.MatchType `json:"type"`
+	Name  string           `json:"name"`
+	Value string           `json:"value"`
+}
+
+// encodedExpr is the JSON representation of an [Expression]. Only the
+// fields of the type of the expression are set.
+type encodedExpr struct {
+	Type ExpressionType `json:"type"`
+
+	// UnaryExpr and BinaryExpr
+	Op    uint32       `json:"op,omitempty"`
+	Left  *encodedExpr `json:"left,omitempty"`
+	Right *encodedExpr `json:"right,omitempty"`
+
+	// ColumnExpr
+	Column *types.ColumnRef `json:"column,omitempty"`
+
+	// LiteralExpr
+	ValueType types.ValueType `json:"value_type,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (f *Fragment) MarshalJSON() ([]byte, error) {
+	if f.Plan == nil {
+		return nil, fmt.Errorf("fragment %d has no plan", f.ID)
+	}
+	roots := f.Plan.Roots()
+	if len(roots) != 1 {
+		return nil, fmt.Errorf("fragment %d must have exactly one root node, got %d", f.ID, len(roots))
+	}
+
+	encoded := encodedFragment{ID: f.ID}
+	index := make(map[Node]int)
+
+	// Nodes are encoded in pre-order, so the root node is always the first
+	// node of the fragment.
+	var encode func(Node) (int, error)
+	encode = func(n Node) (int, error) {
+		if i, ok := index[n]; ok {
+			return i, nil
+		}
+		node, err := encodeNode(n)
+		if err != nil {
+			return 0, err
+		}
+		i := len(encoded.Nodes)
+		index[n] = i
+		encoded.Nodes = append(encoded.Nodes, node)
+
+		for _, child := range f.Plan.Children(n) {
+			c, err := encode(child)
+			if err != nil {
+				return 0, err
+			}
+			encoded.Nodes[i].Children = append(encoded.Nodes[i].Children, c)
+		}
+		return i, nil
+	}
+	if _, err := encode(roots[0]); err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (f *Fragment) UnmarshalJSON(data []byte) error {
+	var encoded encodedFragment
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	if len(encoded.Nodes) == 0 {
+		return fmt.Errorf("fragment %d has no nodes", encoded.ID)
+	}
+
+	plan := &Plan{}
+	nodes := make([]Node, len(encoded.Nodes))
+	for i := range encoded.Nodes {
+		node, err := decodeNode(&encoded.Nodes[i])
+		if err != nil {
+			return err
+		}
+		nodes[i] = plan.addNode(node)
+	}
+	for i := range encoded.Nodes {
+		for _, c := range encoded.Nodes[i].Children {
+			if c < 0 || c >= len(nodes) {
+				return fmt.Errorf("invalid child index %d of node %d", c, i)
+			}
+			if err := plan.addEdge(Edge{Parent: nodes[i], Child: nodes[c]}); err != nil {
+				return err
+			}
+		}
+	}
+
+	f.ID = encoded.ID
+	f.Plan = plan
+	return nil
+}
+
+func encodeNode(n Node) (encodedNode, error) {
+	var err error
+	node := encodedNode{Type: n.Type()}
+
+	switch n := n.(type) {
+	case *DataObjScan:
+		node.Location = n.Location
+		node.StreamIDs = n.StreamIDs
+		node.Direction = n.Direction
+		node.Limit = n.Limit
+		if n.Sections != nil {
+			node.Sections = &n.Sections
+		}
+		if !n.TimeRange.IsZero() {
+			node.TimeRange = &n.TimeRange
+		}
+		if node.Projections, err = encodeColumnExprs(n.Projections); err != nil {
+			return node, err
+		}
+		if node.Predicates, err = encodeExprs(n.Predicates); err != nil {
+			return node, err
+		}
+	case *Filter:
+		if node.Predicates, err = encodeExprs(n.Predicates); err != nil {
+			return node, err
+		}
+	case *Projection:
+		if node.Columns, err = encodeColumnExprs(n.Columns); err != nil {
+			return node, err
+		}
+	case *Parse:
+		node.ParserKind = n.Kind
+		node.Expression = n.Expression
+		node.Extractions = n.Extractions
+		node.Strict = n.Strict
+		node.KeepEmpty = n.KeepEmpty
+	case *Transform:
+		node.TransformKind = n.Kind
+		node.Template = n.Template
+		node.LabelFormats = n.LabelFormats
+		for _, l := range n.Labels {
+			label := encodedLabel{Name: l.Name}
+			if l.Matcher != nil {
+				label.Matcher = &encodedMatcher{Type: l.Matcher.Type, Name: l.Matcher.Name, Value: l.Matcher.Value}
+			}
+			node.Labels = append(node.Labels, label)
+		}
+	case *Limit:
+		node.Skip = n.Skip
+		node.Fetch = n.Fetch
+	default:
+		return node, fmt.Errorf("cannot encode node of type %s", n.Type())
+	}
+	return node, nil
+}
+
+func decodeNode(node *encodedNode) (Node, error) {
+	switch node.Type {
+	case NodeTypeDataObjScan:
+		projections, err := decodeColumnExprs(node.Projections)
+		if err != nil {
+			return nil, err
+		}
+		predicates, err := decodeExprs(node.Predicates)
+		if err != nil {
+			return nil, err
+		}
+		scan := &DataObjScan{
+			Location:    node.Location,
+			StreamIDs:   node.StreamIDs,
+			Projections: projections,
+			Predicates:  predicates,
+			Direction:   node.Direction,
+			Limit:       node.Limit,
+		}
+		if node.Sections != nil {
+			scan.Sections = *node.Sections
+		}
+		if node.TimeRange != nil {
+			scan.TimeRange = *node.TimeRange
+		}
+		return scan, nil
+	case NodeTypeLimit:
+		return &Limit{Skip: node.Skip, Fetch: node.Fetch}, nil
+	case NodeTypeFilter:
+		predicates, err := decodeExprs(node.Predicates)
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{Predicates: predicates}, nil
+	case NodeTypeProjection:
+		columns, err := decodeColumnExprs(node.Columns)
+		if err != nil {
+			return nil, err
+		}
+		return &Projection{Columns: columns}, nil
+	case NodeTypeParse:
+		return &Parse{
+			Kind:        node.ParserKind,
+			Expression:  node.Expression,
+			Extractions: node.Extractions,
+			Strict:      node.Strict,
+			KeepEmpty:   node.KeepEmpty,
+		}, nil
+	case NodeTypeTransform:
+		var labelMatchers []log.NamedLabelMatcher
+		for _, l := range node.Labels {
+			var matcher *labels.Matcher
+			if l.Matcher != nil {
+				var err error
+				matcher, err = labels.NewMatcher(l.Matcher.Type, l.Matcher.Name, l.Matcher.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+			labelMatchers = append(labelMatchers, log.NewNamedLabelMatcher(matcher, l.Name))
+		}
+		return &Transform{
+			Kind:         node.TransformKind,
+			Template:     node.Template,
+			LabelFormats: node.LabelFormats,
+			Labels:       labelMatchers,
+		}, nil
+	default:
+		return nil, fmt.Errorf("cannot decode node of type %s", node.Type)
+	}
+}
+
+func encodeExprs(exprs []Expression) ([]*encodedExpr, error) {
+	encoded := make([]*encodedExpr, 0, len(exprs))
+	for _, expr := range exprs {
+		e, err := encodeExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, e)
+	}
+	return encoded, nil
+}
+
+func encodeColumnExprs(exprs []ColumnExpression) ([]*encodedExpr, error) {
+	encoded := make([]*encodedExpr, 0, len(exprs))
+	for _, expr := range exprs {
+		e, err := encodeExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, e)
+	}
+	return encoded, nil
+}
+
+func encodeExpr(expr Expression) (*encodedExpr, error) {
+	switch expr := expr.(type) {
+	case *UnaryExpr:
+		left, err := encodeExpr(expr.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &encodedExpr{Type: ExprTypeUnary, Op: uint32(expr.Op), Left: left}, nil
+	case *BinaryExpr:
+		left, err := encodeExpr(expr.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := encodeExpr(expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &encodedExpr{Type: ExprTypeBinary, Op: uint32(expr.Op), Left: left, Right: right}, nil
+	case *ColumnExpr:
+		ref := expr.Ref
+		return &encodedExpr{Type: ExprTypeColumn, Column: &ref}, nil
+	case *LiteralExpr:
+		value, err := encodeLiteral(expr.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &encodedExpr{Type: ExprTypeLiteral, ValueType: expr.ValueType(), Value: value}, nil
+	default:
+		return nil, fmt.Errorf("cannot encode expression of type %T", expr)
+	}
+}
+
+// encodeLiteral returns the JSON representation of the value of the
+// literal. Floats are encoded as strings, because JSON numbers cannot
+// represent NaN and infinite values.
+func encodeLiteral(lit types.Literal) (json.RawMessage, error) {
+	switch v := lit.Value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return json.Marshal(strconv.FormatFloat(v, 'g', -1, 64))
+	case bool, string, int64, uint64, []byte:
+		return json.Marshal(v)
+	default:
+		return nil, fmt.Errorf("cannot encode literal of type %s", lit.ValueType())
+	}
+}
+
+func decodeExprs(encoded []*encodedExpr) ([]Expression, error) {
+	if len(encoded) == 0 {
+		return nil, nil
+	}
+	exprs := make([]Expression, 0, len(encoded))
+	for _, e := range encoded {
+		expr, err := decodeExpr(e)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+func decodeColumnExprs(encoded []*encodedExpr) ([]ColumnExpression, error) {
+	if len(encoded) == 0 {
+		return nil, nil
+	}
+	exprs := make([]ColumnExpression, 0, len(encoded))
+	for _, e := range encoded {
+		expr, err := decodeExpr(e)
+		if err != nil {
+			return nil, err
+		}
+		col, ok := expr.(ColumnExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected column expression, got %s", expr.Type())
+		}
+		exprs = append(exprs, col)
+	}
+	return exprs, nil
+}
+
+func decodeExpr(e *encodedExpr) (Expression, error) {
+	if e == nil {
+		return nil, fmt.Errorf("missing expression")
+	}
+
+	switch e.Type {
+	case ExprTypeUnary:
+		left, err := decodeExpr(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Left: left, Op: types.UnaryOp(e.Op)}, nil
+	case ExprTypeBinary:
+		left, err := decodeExpr(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := decodeExpr(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryExpr{Left: left, Right: right, Op: types.BinaryOp(e.Op)}, nil
+	case ExprTypeColumn:
+		if e.Column == nil {
+			return nil, fmt.Errorf("missing column of column expression")
+		}
+		return &ColumnExpr{Ref: *e.Column}, nil
+	case ExprTypeLiteral:
+		value, err := decodeLiteral(e.ValueType, e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return NewLiteral(value), nil
+	default:
+		return nil, fmt.Errorf("cannot decode expression of type %d", e.Type)
+	}
+}
+
+func decodeLiteral(ty types.ValueType, data json.RawMessage) (any, error) {
+	switch ty {
+	case types.ValueTypeNull:
+		return nil, nil
+	case types.ValueTypeBool:
+		return unmarshalValue[bool](data)
+	case types.ValueTypeStr:
+		return unmarshalValue[string](data)
+	case types.ValueTypeInt:
+		return unmarshalValue[int64](data)
+	case types.ValueTypeTimestamp:
+		return unmarshalValue[uint64](data)
+	case types.ValueTypeByteArray:
+		return unmarshalValue[[]byte](data)
+	case types.ValueTypeFloat:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(s, 64)
+	default:
+		return nil, fmt.Errorf("cannot decode literal of type %s", ty)
+	}
+}
+
+func unmarshalValue[T any](data json.RawMessage) (any, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,37 @@
+package physical
+
+import "fmt"
+
+// Exchange represents the boundary between a plan and a [Fragment] of the
+// plan that is executed independently, possibly by a different process. The
+// rows produced by the fragment are the output of the Exchange node.
+//
+// Exchange nodes are leaf nodes: the nodes of the fragment are not part of
+// the plan that contains the Exchange node.
+type Exchange struct {
+	id string
+
+	// Fragment is the part of the plan that produces the rows of the node.
+	Fragment *Fragment
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (e *Exchange) ID() string {
+	if e.id == "" {
+		return fmt.Sprintf("%p", e)
+	}
+	return e.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*Exchange) Type() NodeType {
+	return NodeTypeExchange
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (e *Exchange) Accept(v Visitor) error {
+	return v.VisitExchange(e)
+}
@@ -0,0 +1,136 @@
+package physical
+
+import "fmt"
+
+// Fragment is a part of a physical plan that is executed independently of
+// the rest of the plan, e.g. by a different querier. The results of a
+// fragment are consumed by the [Exchange] node that replaced the fragment in
+// the plan it was split from.
+type Fragment struct {
+	// ID identifies the fragment within the plan it was split from.
+	ID int
+	// Plan is the plan of the fragment. It has exactly one root node.
+	Plan *Plan
+}
+
+// Locations returns the distinct locations of the data objects read by the
+// fragment.
+func (f *Fragment) Locations() []DataObjLocation {
+	return dataObjLocations(f.Plan)
+}
+
+// SplitFragments splits the plan into fragments that can be executed
+// independently of each other. Each fragment consists of a [DataObjScan]
+// node and the chain of row-level nodes above it ([Filter], [Parse],
+// [Transform], and [Projection]), up to the first node that merges or
+// aggregates multiple inputs. Every fragment is replaced by an [Exchange]
+// node in the plan, so that the merging nodes become the merge operators of
+// the results of the fragments.
+//
+// If the rows of a fragment only pass through [SortMerge] nodes before
+// reaching a [Limit] node, the limit is pushed down into the fragment, so
+// that each fragment returns at most as many rows as the limit requires.
+// The scan of such a fragment reads in the order of the [SortMerge] nodes,
+// so the rows that are kept are the first ones in the order of the query.
+//
+// The plan is modified in place. The returned fragments are ordered by
+// their ID.
+func SplitFragments(plan *Plan) ([]*Fragment, error) {
+	leaves := make(nodeSet)
+	for _, leaf := range plan.Leaves() {
+		leaves.add(leaf)
+	}
+
+	var fragments []*Fragment
+	for _, leaf := range leaves.sorted() {
+		if _, ok := leaf.(*DataObjScan); !ok {
+			continue
+		}
+
+		chain := []Node{leaf}
+		for {
+			parents := plan.Parents(chain[len(chain)-1])
+			if len(parents) != 1 || !isRowLevelNode(parents[0]) || len(plan.Children(parents[0])) != 1 {
+				break
+			}
+			chain = append(chain, parents[0])
+		}
+
+		fragment := &Fragment{ID: len(fragments), Plan: &Plan{}}
+		for i, node := range chain {
+			fragment.Plan.addNode(node)
+			if i == 0 {
+				continue
+			}
+			if err := fragment.Plan.addEdge(Edge{Parent: node, Child: chain[i-1]}); err != nil {
+				return nil, err
+			}
+		}
+		if limit, order, ok := fragmentLimit(plan, chain[len(chain)-1]); ok {
+			if order != nil {
+				leaf.(*DataObjScan).Direction = directionForOrder(*order)
+			}
+			node := fragment.Plan.addNode(&Limit{Fetch: limit})
+			if err := fragment.Plan.addEdge(Edge{Parent: node, Child: chain[len(chain)-1]}); err != nil {
+				return nil, err
+			}
+		}
+
+		exchange := plan.addNode(&Exchange{Fragment: fragment})
+		for _, parent := range plan.Parents(chain[len(chain)-1]) {
+			if err := plan.addEdge(Edge{Parent: parent, Child: exchange}); err != nil {
+				return nil, err
+			}
+		}
+		// Nodes are eliminated from the bottom up, so that no edges are
+		// added between the remaining nodes of the chain.
+		for _, node := range chain {
+			plan.eliminateNode(node)
+		}
+		fragments = append(fragments, fragment)
+	}
+
+	if len(fragments) > 0 && len(plan.Roots()) != 1 {
+		return nil, fmt.Errorf("plan must have exactly one root node after splitting, got %d", len(plan.Roots()))
+	}
+	return fragments, nil
+}
+
+// fragmentLimit returns the number of rows that the output of node must
+// provide to the first [Limit] node above it, if node is only consumed by
+// [SortMerge] nodes on its way to the Limit node. The order of the closest
+// SortMerge node is returned as well, if there is one.
+func fragmentLimit(plan *Plan, node Node) (uint32, *SortOrder, bool) {
+	var order *SortOrder
+	for {
+		parents := plan.Parents(node)
+		if len(parents) != 1 {
+			return 0, nil, false
+		}
+		switch parent := parents[0].(type) {
+		case *SortMerge:
+			if order == nil {
+				order = &parent.Order
+			}
+		case *Limit:
+			if parent.Fetch == 0 {
+				return 0, nil, false
+			}
+			return parent.Skip + parent.Fetch, order, true
+		default:
+			return 0, nil, false
+		}
+		node = parents[0]
+	}
+}
+
+// isRowLevelNode returns true if the node processes each row of its input
+// independently of all other rows.
+func isRowLevelNode(node Node) bool {
+	switch node.(type) {
+	case *Filter, *Parse, *Transform, *Projection:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,222 @@
+package physical
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+func TestSplitFragments(t *testing.T) {
+	plan := &Plan{}
+	scan1 := plan.addNode(&DataObjScan{id: "scan1", Location: "obj1"})
+	scan2 := plan.addNode(&DataObjScan{id: "scan2", Location: "obj2"})
+	parse1 := plan.addNode(&Parse{id: "parse1", Kind: types.ParserTypeLogfmt})
+	parse2 := plan.addNode(&Parse{id: "parse2", Kind: types.ParserTypeLogfmt})
+	filter1 := plan.addNode(&Filter{id: "filter1"})
+	filter2 := plan.addNode(&Filter{id: "filter2"})
+	merge := plan.addNode(&SortMerge{id: "merge", Order: ASC})
+	limit := plan.addNode(&Limit{id: "limit", Skip: 10, Fetch: 100})
+
+	_ = plan.addEdge(Edge{Parent: parse1, Child: scan1})
+	_ = plan.addEdge(Edge{Parent: parse2, Child: scan2})
+	_ = plan.addEdge(Edge{Parent: filter1, Child: parse1})
+	_ = plan.addEdge(Edge{Parent: filter2, Child: parse2})
+	_ = plan.addEdge(Edge{Parent: merge, Child: filter1})
+	_ = plan.addEdge(Edge{Parent: merge, Child: filter2})
+	_ = plan.addEdge(Edge{Parent: limit, Child: merge})
+
+	fragments, err := SplitFragments(plan)
+	require.NoError(t, err)
+	require.Len(t, fragments, 2)
+
+	// The plan only retains the merging nodes, which consume one Exchange
+	// node per fragment.
+	require.Equal(t, 4, plan.Len())
+	require.Equal(t, []Node{limit}, plan.Roots())
+	require.Equal(t, []Node{merge}, plan.Children(limit))
+
+	exchanges := plan.Children(merge)
+	require.Len(t, exchanges, 2)
+	for _, exchange := range exchanges {
+		require.IsType(t, &Exchange{}, exchange)
+		require.Empty(t, plan.Children(exchange))
+	}
+
+	for i, expected := range [][]Node{
+		{filter1, parse1, scan1},
+		{filter2, parse2, scan2},
+	} {
+		fragment := fragments[i]
+		require.Equal(t, i, fragment.ID)
+		require.Equal(t, 4, fragment.Plan.Len())
+
+		// The limit is pushed down into the fragment, and the scan reads
+		// in the order of the merge.
+		roots := fragment.Plan.Roots()
+		require.Len(t, roots, 1)
+		require.Equal(t, &Limit{Fetch: 110}, roots[0])
+		require.Equal(t, []Node{expected[0]}, fragment.Plan.Children(roots[0]))
+		require.Equal(t, []Node{expected[1]}, fragment.Plan.Children(expected[0]))
+		require.Equal(t, []Node{expected[2]}, fragment.Plan.Children(expected[1]))
+		require.Equal(t, Forward, expected[2].(*DataObjScan).Direction)
+	}
+}
+
+func TestSplitFragments_NoLimitPushdown(t *testing.T) {
+	plan := &Plan{}
+	scan := plan.addNode(&DataObjScan{id: "scan"})
+	filter := plan.addNode(&Filter{id: "filter"})
+	aggregation := plan.addNode(&RangeAggregation{id: "aggregation"})
+	limit := plan.addNode(&Limit{id: "limit", Fetch: 100})
+	_ = plan.addEdge(Edge{Parent: filter, Child: scan})
+	_ = plan.addEdge(Edge{Parent: aggregation, Child: filter})
+	_ = plan.addEdge(Edge{Parent: limit, Child: aggregation})
+
+	fragments, err := SplitFragments(plan)
+	require.NoError(t, err)
+	require.Len(t, fragments, 1)
+
+	// Rows that are aggregated do not count towards the limit.
+	require.Equal(t, []Node{filter}, fragments[0].Plan.Roots())
+}
+
+func TestSplitFragments_RootFragment(t *testing.T) {
+	plan := &Plan{}
+	scan := plan.addNode(&DataObjScan{id: "scan"})
+	filter := plan.addNode(&Filter{id: "filter"})
+	_ = plan.addEdge(Edge{Parent: filter, Child: scan})
+
+	fragments, err := SplitFragments(plan)
+	require.NoError(t, err)
+	require.Len(t, fragments, 1)
+
+	// A plan without merging nodes is replaced by a single Exchange node.
+	roots := plan.Roots()
+	require.Len(t, roots, 1)
+	require.Equal(t, &Exchange{Fragment: fragments[0]}, roots[0])
+	require.Equal(t, []Node{filter}, fragments[0].Plan.Roots())
+}
+
+func TestFragment_JSON(t *testing.T) {
+	plan := &Plan{}
+	scan := plan.addNode(&DataObjScan{
+		id:          "scan",
+		Location:    "objects/00/0000",
+		StreamIDs:   []int64{1, 2, 3},
+		Projections: []ColumnExpression{newColumnExpr("level", types.ColumnTypeAmbiguous)},
+		Predicates: []Expression{
+			&BinaryExpr{
+				Left:  newColumnExpr(types.ColumnNameBuiltinTimestamp, types.ColumnTypeBuiltin),
+				Right: NewLiteral(uint64(1742826126000000000)),
+				Op:    types.BinaryOpGte,
+			},
+			&UnaryExpr{
+				Left: &BinaryExpr{
+					Left:  newColumnExpr(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin),
+					Right: NewLiteral("debug"),
+					Op:    types.BinaryOpMatchSubstr,
+				},
+				Op: types.UnaryOpNot,
+			},
+		},
+		Direction: Backwards,
+		Limit:     1000,
+		// An empty set of sections must not be decoded as nil, which would
+		// read all sections.
+		Sections: []int{},
+		TimeRange: TimeRange{
+			Start: time.Unix(0, 1742826126000000000).UTC(),
+			End:   time.Unix(0, 1742826127000000000).UTC(),
+		},
+	})
+	parse := plan.addNode(&Parse{
+		id:          "parse",
+		Kind:        types.ParserTypeJSON,
+		Extractions: []log.LabelExtractionExpr{log.NewLabelExtractionExpr("status", "response.status")},
+	})
+	filter := plan.addNode(&Filter{
+		id: "filter",
+		Predicates: []Expression{
+			&BinaryExpr{
+				Left:  newColumnExpr("status", types.ColumnTypeParsed),
+				Right: NewLiteral(int64(200)),
+				Op:    types.BinaryOpGt,
+			},
+			&BinaryExpr{
+				Left:  newColumnExpr("latency", types.ColumnTypeParsed),
+				Right: NewLiteral(math.Inf(1)),
+				Op:    types.BinaryOpLt,
+			},
+		},
+	})
+	transform := plan.addNode(&Transform{
+		id:   "transform",
+		Kind: types.TransformTypeKeep,
+		Labels: []log.NamedLabelMatcher{
+			{Name: "app"},
+			{Matcher: labels.MustNewMatcher(labels.MatchRegexp, "level", "err.*")},
+		},
+	})
+	_ = plan.addEdge(Edge{Parent: parse, Child: scan})
+	_ = plan.addEdge(Edge{Parent: filter, Child: parse})
+	limit := plan.addNode(&Limit{id: "limit", Fetch: 100})
+	_ = plan.addEdge(Edge{Parent: transform, Child: filter})
+	_ = plan.addEdge(Edge{Parent: limit, Child: transform})
+
+	fragment := &Fragment{ID: 7, Plan: plan}
+	data, err := json.Marshal(fragment)
+	require.NoError(t, err)
+
+	var decoded Fragment
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, 7, decoded.ID)
+	require.Equal(t, 5, decoded.Plan.Len())
+
+	// Node IDs are not part of the encoding, so the nodes are compared by
+	// their properties.
+	var nodes []Node
+	for n := decoded.Plan.Roots()[0]; n != nil; {
+		nodes = append(nodes, n)
+		children := decoded.Plan.Children(n)
+		require.LessOrEqual(t, len(children), 1)
+		n = nil
+		if len(children) == 1 {
+			n = children[0]
+		}
+	}
+	require.Len(t, nodes, 5)
+
+	require.Equal(t, &Limit{Fetch: 100}, nodes[0])
+
+	decodedTransform := nodes[1].(*Transform)
+	require.Equal(t, transform.(*Transform).Kind, decodedTransform.Kind)
+	require.Equal(t, "app", decodedTransform.Labels[0].Name)
+	require.True(t, decodedTransform.Labels[1].Matcher.Matches("error"))
+	require.False(t, decodedTransform.Labels[1].Matcher.Matches("warn"))
+
+	decodedFilter := nodes[2].(*Filter)
+	require.Equal(t, filter.(*Filter).Predicates, decodedFilter.Predicates)
+
+	decodedParse := nodes[3].(*Parse)
+	decodedParse.id = parse.ID()
+	require.Equal(t, parse, decodedParse)
+
+	decodedScan := nodes[4].(*DataObjScan)
+	decodedScan.id = scan.ID()
+	require.Equal(t, scan, decodedScan)
+}
+
+func TestFragment_JSONUnsupportedNode(t *testing.T) {
+	plan := &Plan{}
+	plan.addNode(&SortMerge{id: "merge"})
+
+	_, err := json.Marshal(&Fragment{Plan: plan})
+	require.ErrorContains(t, err, "cannot encode node of type SortMerge")
+}
@@ -1,8 +1,10 @@
 package physical
 
 import (
+	"cmp"
 	"slices"
 
+	"github.com/grafana/loki/v3/pkg/dataobj"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 )
 
@@ -63,6 +65,16 @@ func (r *predicatePushdown) applyPredicatePushdown(node Node, predicate Expressi
 			return true
 		}
 		return false
+	case *Parse:
+		// The unpack parser replaces the log line, so predicates on the log
+		// line above it cannot be evaluated on the original log line.
+		if node.Kind == types.ParserTypeUnpack {
+			return false
+		}
+	case *Transform:
+		if node.Kind == types.TransformTypeLineFormat {
+			return false
+		}
 	}
 	for _, child := range r.plan.Children(node) {
 		if ok := r.applyPredicatePushdown(child, predicate); !ok {
@@ -107,6 +119,11 @@ func (r *limitPushdown) applyLimitPushdown(node Node, limit uint32) bool {
 		// In case the scan node is reachable from multiple different limit nodes, we need to take the largest limit.
 		node.Limit = max(node.Limit, limit)
 		return true
+	case *Filter, *RangeAggregation, *VectorAggregation:
+		// Rows that are removed or aggregated by these nodes would count
+		// towards the limit of the scan, so the limit cannot be applied
+		// below them.
+		return false
 	}
 	for _, child := range r.plan.Children(node) {
 		if ok := r.applyLimitPushdown(child, limit); !ok {
@@ -118,6 +135,227 @@ func (r *limitPushdown) applyLimitPushdown(node Node, limit uint32) bool {
 
 var _ rule = (*limitPushdown)(nil)
 
+// projectionPushdown is a rule that limits the columns read by the scan nodes
+// to the columns kept by a keep transformation and the columns referenced
+// by the nodes between the transformation and the scans. Builtin columns are
+// always read by the scan nodes.
+type projectionPushdown struct {
+	plan *Plan
+}
+
+// apply implements rule.
+func (r *projectionPushdown) apply(node Node) bool {
+	switch node := node.(type) {
+	case *Transform:
+		if node.Kind != types.TransformTypeKeep || len(node.Labels) == 0 {
+			return false
+		}
+		projections := make([]ColumnExpression, 0, len(node.Labels))
+		for _, name := range node.labelNames() {
+			projections = append(projections, newColumnExpr(name, types.ColumnTypeAmbiguous))
+		}
+
+		changed := false
+		for _, child := range r.plan.Children(node) {
+			if r.applyProjectionPushdown(child, projections) {
+				changed = true
+			}
+		}
+		return changed
+	}
+	return false
+}
+
+func (r *projectionPushdown) applyProjectionPushdown(node Node, projections []ColumnExpression) bool {
+	switch node := node.(type) {
+	case *DataObjScan:
+		changed := false
+		for _, projection := range projections {
+			if !slices.ContainsFunc(node.Projections, func(e ColumnExpression) bool {
+				return e.String() == projection.String()
+			}) {
+				node.Projections = append(node.Projections, projection)
+				changed = true
+			}
+		}
+		return changed
+	case *Filter:
+		projections = slices.Clip(projections)
+		for _, predicate := range node.Predicates {
+			projections = appendColumnRefs(projections, predicate)
+		}
+	case *Transform:
+		// Templates may reference any label, so all columns are required
+		// below line_format and label_format.
+		if node.Kind != types.TransformTypeKeep && node.Kind != types.TransformTypeDrop {
+			return false
+		}
+	case *SortMerge, *Limit:
+	default:
+		// Parsers may rename extracted labels that collide with existing
+		// labels, so all columns are required below them and any other node.
+		return false
+	}
+
+	changed := false
+	for _, child := range r.plan.Children(node) {
+		if r.applyProjectionPushdown(child, projections) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// appendColumnRefs appends the non-builtin columns referenced by expr to
+// columns.
+func appendColumnRefs(columns []ColumnExpression, expr Expression) []ColumnExpression {
+	switch expr := expr.(type) {
+	case *UnaryExpr:
+		return appendColumnRefs(columns, expr.Left)
+	case *BinaryExpr:
+		columns = appendColumnRefs(columns, expr.Left)
+		return appendColumnRefs(columns, expr.Right)
+	case *ColumnExpr:
+		if expr.Ref.Type != types.ColumnTypeBuiltin {
+			columns = append(columns, expr)
+		}
+	}
+	return columns
+}
+
+var _ rule = (*projectionPushdown)(nil)
+
+// sectionPruning is a rule that limits the sections read by the scan nodes
+// to the sections whose statistics show that they can contain rows matching
+// the stream IDs and predicates of the scan. It also sets the time range of
+// the scan nodes to the time range of the remaining sections.
+type sectionPruning struct {
+	plan       *Plan
+	statistics Statistics
+}
+
+// apply implements rule.
+func (r *sectionPruning) apply(node Node) bool {
+	scan, ok := node.(*DataObjScan)
+	if !ok {
+		return false
+	}
+	indexes, sections, ok := r.statistics.sections(scan)
+	if !ok {
+		return false
+	}
+
+	kept := make([]int, 0, len(indexes))
+	keptSections := make([]dataobj.LogsSectionStatistics, 0, len(sections))
+	for i := range sections {
+		if canMatch(&sections[i], scan.StreamIDs, scan.Predicates) {
+			kept = append(kept, indexes[i])
+			keptSections = append(keptSections, sections[i])
+		}
+	}
+
+	changed := false
+	if len(kept) < len(indexes) {
+		scan.Sections = kept
+		changed = true
+	}
+	if timeRange := sectionsTimeRange(keptSections); !timeRange.Start.Equal(scan.TimeRange.Start) || !timeRange.End.Equal(scan.TimeRange.End) {
+		scan.TimeRange = timeRange
+		changed = true
+	}
+	return changed
+}
+
+var _ rule = (*sectionPruning)(nil)
+
+// predicateOrdering is a rule that orders the predicates of Filter and
+// DataObjScan nodes by their estimated selectivity, so that the predicates
+// that remove the most rows are evaluated first.
+type predicateOrdering struct {
+	plan       *Plan
+	statistics Statistics
+}
+
+// apply implements rule.
+func (r *predicateOrdering) apply(node Node) bool {
+	switch node := node.(type) {
+	case *Filter:
+		sections, ok := r.inputSections(node)
+		if !ok {
+			return false
+		}
+		return orderPredicates(node.Predicates, sections)
+	case *DataObjScan:
+		_, sections, ok := r.statistics.sections(node)
+		if !ok {
+			return false
+		}
+		return orderPredicates(node.Predicates, sections)
+	}
+	return false
+}
+
+// inputSections returns the statistics of the sections read by the scan
+// nodes below node. It returns false if the statistics of a scan node are
+// unknown, or if node has an input that changes the rows of the scan nodes
+// in a way that invalidates their statistics.
+func (r *predicateOrdering) inputSections(node Node) ([]dataobj.LogsSectionStatistics, bool) {
+	var res []dataobj.LogsSectionStatistics
+	for _, child := range r.plan.Children(node) {
+		switch child := child.(type) {
+		case *DataObjScan:
+			_, sections, ok := r.statistics.sections(child)
+			if !ok {
+				return nil, false
+			}
+			res = append(res, sections...)
+			continue
+		case *Filter, *Parse, *Transform, *SortMerge, *Limit:
+		default:
+			return nil, false
+		}
+
+		sections, ok := r.inputSections(child)
+		if !ok {
+			return nil, false
+		}
+		res = append(res, sections...)
+	}
+	return res, len(res) > 0
+}
+
+// orderPredicates sorts predicates in place by their estimated selectivity
+// on sections. Predicates with the same selectivity keep their order. It
+// returns whether the order of the predicates changed.
+func orderPredicates(predicates []Expression, sections []dataobj.LogsSectionStatistics) bool {
+	if len(predicates) < 2 {
+		return false
+	}
+
+	type estimate struct {
+		predicate   Expression
+		selectivity float64
+	}
+	estimates := make([]estimate, len(predicates))
+	for i, predicate := range predicates {
+		estimates[i] = estimate{predicate, selectivity(sections, predicate)}
+	}
+	slices.SortStableFunc(estimates, func(a, b estimate) int {
+		return cmp.Compare(a.selectivity, b.selectivity)
+	})
+
+	changed := false
+	for i := range estimates {
+		if estimates[i].predicate != predicates[i] {
+			predicates[i] = estimates[i].predicate
+			changed = true
+		}
+	}
+	return changed
+}
+
+var _ rule = (*predicateOrdering)(nil)
+
 // optimization represents a single optimization pass and can hold multiple rules.
 type optimization struct {
 	plan  *Plan
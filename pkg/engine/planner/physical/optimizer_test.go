@@ -2,10 +2,14 @@ package physical
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/dataobj"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 )
 
 func TestCanApplyPredicate(t *testing.T) {
@@ -186,4 +190,174 @@ func TestOptimizer(t *testing.T) {
 		expected := PrintAsTree(optimized)
 		require.Equal(t, expected, actual)
 	})
+
+	t.Run("projection pushdown", func(t *testing.T) {
+		keep := []log.NamedLabelMatcher{
+			{Name: "app"},
+			{Matcher: labels.MustNewMatcher(labels.MatchEqual, "level", "error")},
+		}
+
+		plan := &Plan{}
+		scan1 := plan.addNode(&DataObjScan{id: "scan1"})
+		scan2 := plan.addNode(&DataObjScan{id: "scan2"})
+		merge := plan.addNode(&SortMerge{id: "merge"})
+		filter := plan.addNode(&Filter{id: "filter", Predicates: []Expression{
+			&BinaryExpr{
+				Left:  newColumnExpr("pod", types.ColumnTypeAmbiguous),
+				Right: NewLiteral("foo"),
+				Op:    types.BinaryOpEq,
+			},
+		}})
+		transform := plan.addNode(&Transform{id: "keep", Kind: types.TransformTypeKeep, Labels: keep})
+
+		_ = plan.addEdge(Edge{Parent: transform, Child: filter})
+		_ = plan.addEdge(Edge{Parent: filter, Child: merge})
+		_ = plan.addEdge(Edge{Parent: merge, Child: scan1})
+		_ = plan.addEdge(Edge{Parent: merge, Child: scan2})
+
+		optimizations := []*optimization{
+			newOptimization("projection pushdown", plan).withRules(
+				&projectionPushdown{plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		expected := []ColumnExpression{
+			newColumnExpr("app", types.ColumnTypeAmbiguous),
+			newColumnExpr("level", types.ColumnTypeAmbiguous),
+			newColumnExpr("pod", types.ColumnTypeAmbiguous),
+		}
+		require.Equal(t, expected, scan1.(*DataObjScan).Projections)
+		require.Equal(t, expected, scan2.(*DataObjScan).Projections)
+	})
+
+	t.Run("projection pushdown below template", func(t *testing.T) {
+		plan := &Plan{}
+		scan := plan.addNode(&DataObjScan{id: "scan"})
+		lineFormat := plan.addNode(&Transform{id: "line_format", Kind: types.TransformTypeLineFormat, Template: "{{.pod}}"})
+		transform := plan.addNode(&Transform{id: "keep", Kind: types.TransformTypeKeep, Labels: []log.NamedLabelMatcher{{Name: "app"}}})
+
+		_ = plan.addEdge(Edge{Parent: transform, Child: lineFormat})
+		_ = plan.addEdge(Edge{Parent: lineFormat, Child: scan})
+
+		optimizations := []*optimization{
+			newOptimization("projection pushdown", plan).withRules(
+				&projectionPushdown{plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		// The template references the pod label, which is not kept, so no
+		// columns can be pruned.
+		require.Empty(t, scan.(*DataObjScan).Projections)
+	})
+
+	t.Run("section pruning", func(t *testing.T) {
+		statistics := Statistics{
+			"obj": {
+				// Pruned by the timestamp predicate.
+				{Rows: 10, MinTime: time.Unix(0, 0), MaxTime: time.Unix(50, 0), MinStreamID: 1, MaxStreamID: 1},
+				// Pruned by the stream IDs.
+				{Rows: 10, MinTime: time.Unix(60, 0), MaxTime: time.Unix(200, 0), MinStreamID: 2, MaxStreamID: 3},
+				// Pruned by the metadata predicate.
+				{Rows: 10, MinTime: time.Unix(60, 0), MaxTime: time.Unix(200, 0), MinStreamID: 1, MaxStreamID: 2},
+				{
+					Rows: 10, MinTime: time.Unix(150, 0), MaxTime: time.Unix(300, 0), MinStreamID: 1, MaxStreamID: 1,
+					Metadata: map[string]dataobj.ColumnStatistics{
+						"trace": {Values: 10, Cardinality: 10, Min: "a", Max: "z", HasRange: true},
+					},
+				},
+			},
+		}
+		newPlan := func() (*Plan, *DataObjScan) {
+			plan := &Plan{}
+			scan := plan.addNode(&DataObjScan{
+				id:        "scan",
+				Location:  "obj",
+				StreamIDs: []int64{1},
+				Predicates: []Expression{
+					&BinaryExpr{
+						Left:  newColumnExpr("timestamp", types.ColumnTypeBuiltin),
+						Right: NewLiteral(uint64(time.Unix(100, 0).UnixNano())),
+						Op:    types.BinaryOpGt,
+					},
+					&BinaryExpr{
+						Left:  newColumnExpr("trace", types.ColumnTypeMetadata),
+						Right: NewLiteral("abc"),
+						Op:    types.BinaryOpEq,
+					},
+				},
+			})
+			return plan, scan.(*DataObjScan)
+		}
+
+		plan, scan := newPlan()
+		o := newOptimizer(plan, []*optimization{
+			newOptimization("section pruning", plan).withRules(&sectionPruning{plan, statistics}),
+		})
+		o.optimize(plan.Roots()[0])
+		require.Equal(t, []int{3}, scan.Sections)
+		require.Equal(t, TimeRange{Start: time.Unix(150, 0), End: time.Unix(300, 0)}, scan.TimeRange)
+
+		// Without statistics all sections are read.
+		plan, scan = newPlan()
+		o = newOptimizer(plan, []*optimization{
+			newOptimization("section pruning", plan).withRules(&sectionPruning{plan, nil}),
+		})
+		o.optimize(plan.Roots()[0])
+		require.Nil(t, scan.Sections)
+		require.True(t, scan.TimeRange.IsZero())
+	})
+
+	t.Run("predicate ordering", func(t *testing.T) {
+		statistics := Statistics{
+			"obj": {
+				{
+					Rows: 100, MinTime: time.Unix(0, 0), MaxTime: time.Unix(100, 0),
+					Metadata: map[string]dataobj.ColumnStatistics{
+						"level": {Values: 100, Cardinality: 5, Min: "debug", Max: "warn", HasRange: true},
+					},
+				},
+			},
+		}
+		contains := &BinaryExpr{
+			Left:  newColumnExpr(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin),
+			Right: NewLiteral("foo"),
+			Op:    types.BinaryOpMatchSubstr,
+		}
+		level := &BinaryExpr{
+			Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+			Right: NewLiteral("error"),
+			Op:    types.BinaryOpEq,
+		}
+		recent := &BinaryExpr{
+			Left:  newColumnExpr("timestamp", types.ColumnTypeBuiltin),
+			Right: NewLiteral(uint64(time.Unix(90, 0).UnixNano())),
+			Op:    types.BinaryOpGte,
+		}
+		newPlan := func() (*Plan, *Filter) {
+			plan := &Plan{}
+			scan := plan.addNode(&DataObjScan{id: "scan", Location: "obj"})
+			filter := plan.addNode(&Filter{id: "filter", Predicates: []Expression{contains, level, recent}})
+			_ = plan.addEdge(Edge{Parent: filter, Child: scan})
+			return plan, filter.(*Filter)
+		}
+
+		plan, filter := newPlan()
+		o := newOptimizer(plan, []*optimization{
+			newOptimization("predicate ordering", plan).withRules(&predicateOrdering{plan, statistics}),
+		})
+		o.optimize(plan.Roots()[0])
+		require.Equal(t, []Expression{recent, level, contains}, filter.Predicates)
+
+		// Without statistics the order of the predicates is kept.
+		plan, filter = newPlan()
+		o = newOptimizer(plan, []*optimization{
+			newOptimization("predicate ordering", plan).withRules(&predicateOrdering{plan, nil}),
+		})
+		o.optimize(plan.Roots()[0])
+		require.Equal(t, []Expression{contains, level, recent}, filter.Predicates)
+	})
 }
@@ -0,0 +1,47 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// Parse represents the extraction of labels from log lines in the physical
+// plan. Extracted labels are added to the rows as parsed columns.
+type Parse struct {
+	id string
+
+	// Kind is the parser used to extract labels from log lines.
+	Kind types.ParserType
+	// Expression is the regular expression of the regexp parser or the
+	// pattern of the pattern parser.
+	Expression string
+	// Extractions restricts the json and logfmt parsers to extract only the
+	// given labels. If empty, all labels are extracted.
+	Extractions []log.LabelExtractionExpr
+
+	Strict    bool // Stop parsing logfmt lines on the first error.
+	KeepEmpty bool // Keep logfmt keys with empty values.
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (p *Parse) ID() string {
+	if p.id == "" {
+		return fmt.Sprintf("%p", p)
+	}
+	return p.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*Parse) Type() NodeType {
+	return NodeTypeParse
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (p *Parse) Accept(v Visitor) error {
+	return v.VisitParse(p)
+}
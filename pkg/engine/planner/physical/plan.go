@@ -14,6 +14,11 @@ const (
 	NodeTypeProjection
 	NodeTypeFilter
 	NodeTypeLimit
+	NodeTypeRangeAggregation
+	NodeTypeVectorAggregation
+	NodeTypeParse
+	NodeTypeTransform
+	NodeTypeExchange
 )
 
 func (t NodeType) String() string {
@@ -28,6 +33,16 @@ func (t NodeType) String() string {
 		return "Filter"
 	case NodeTypeLimit:
 		return "Limit"
+	case NodeTypeRangeAggregation:
+		return "RangeAggregation"
+	case NodeTypeVectorAggregation:
+		return "VectorAggregation"
+	case NodeTypeParse:
+		return "Parse"
+	case NodeTypeTransform:
+		return "Transform"
+	case NodeTypeExchange:
+		return "Exchange"
 	default:
 		return "Undefined"
 	}
@@ -58,12 +73,22 @@ var _ Node = (*SortMerge)(nil)
 var _ Node = (*Projection)(nil)
 var _ Node = (*Limit)(nil)
 var _ Node = (*Filter)(nil)
-
-func (*DataObjScan) isNode() {}
-func (*SortMerge) isNode()   {}
-func (*Projection) isNode()  {}
-func (*Limit) isNode()       {}
-func (*Filter) isNode()      {}
+var _ Node = (*RangeAggregation)(nil)
+var _ Node = (*VectorAggregation)(nil)
+var _ Node = (*Parse)(nil)
+var _ Node = (*Transform)(nil)
+var _ Node = (*Exchange)(nil)
+
+func (*DataObjScan) isNode()       {}
+func (*SortMerge) isNode()         {}
+func (*Projection) isNode()        {}
+func (*Limit) isNode()             {}
+func (*Filter) isNode()            {}
+func (*RangeAggregation) isNode()  {}
+func (*VectorAggregation) isNode() {}
+func (*Parse) isNode()             {}
+func (*Transform) isNode()         {}
+func (*Exchange) isNode()          {}
 
 // Edge is a directed connection (parent-child relation) between a two nodes.
 type Edge struct {
@@ -3,6 +3,7 @@ package physical
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/grafana/loki/v3/pkg/engine/planner/logical"
 )
@@ -15,6 +16,10 @@ import (
 //  2. Pushdown
 //     a) Push down the limit of the Limit node to the DataObjScan nodes.
 //     b) Push down the predicate from the Filter node to the DataObjScan nodes.
+//     c) Push down the columns kept by a keep Transform node to the DataObjScan nodes.
+//  3. Cost-based optimization
+//     a) Skip the sections of data objects that cannot contain matching rows, based on their statistics.
+//     b) Order the predicates of Filter and DataObjScan nodes by their estimated selectivity.
 type Planner struct {
 	catalog Catalog
 	plan    *Plan
@@ -79,6 +84,14 @@ func (p *Planner) process(inst logical.Value) ([]Node, error) {
 		return p.processSort(inst)
 	case *logical.Limit:
 		return p.processLimit(inst)
+	case *logical.Parse:
+		return p.processParse(inst)
+	case *logical.Transform:
+		return p.processTransform(inst)
+	case *logical.RangeAggregation:
+		return p.processRangeAggregation(inst)
+	case *logical.VectorAggregation:
+		return p.processVectorAggregation(inst)
 	}
 	return nil, nil
 }
@@ -101,22 +114,75 @@ func (p *Planner) processMakeTable(lp *logical.MakeTable) ([]Node, error) {
 	return nodes, nil
 }
 
-// Convert [logical.Select] into one [Filter] node.
+// Convert [logical.Select] into one [Filter] node per input node. Filtering
+// is applied per row, so inputs that are not merged beforehand, such as
+// multiple [DataObjScan] nodes, are filtered independently.
 func (p *Planner) processSelect(lp *logical.Select) ([]Node, error) {
-	node := &Filter{
-		Predicates: []Expression{p.convertPredicate(lp.Predicate)},
+	children, err := p.process(lp.Table)
+	if err != nil {
+		return nil, err
 	}
-	p.plan.addNode(node)
+	nodes := make([]Node, 0, len(children))
+	for i := range children {
+		node := &Filter{
+			Predicates: []Expression{p.convertPredicate(lp.Predicate)},
+		}
+		p.plan.addNode(node)
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// Convert [logical.Parse] into one [Parse] node per input node. Like
+// filtering, parsing is applied per row.
+func (p *Planner) processParse(lp *logical.Parse) ([]Node, error) {
 	children, err := p.process(lp.Table)
 	if err != nil {
 		return nil, err
 	}
+	nodes := make([]Node, 0, len(children))
 	for i := range children {
+		node := &Parse{
+			Kind:        lp.Kind,
+			Expression:  lp.Expression,
+			Extractions: lp.Extractions,
+			Strict:      lp.Strict,
+			KeepEmpty:   lp.KeepEmpty,
+		}
+		p.plan.addNode(node)
 		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
 			return nil, err
 		}
+		nodes = append(nodes, node)
 	}
-	return []Node{node}, nil
+	return nodes, nil
+}
+
+// Convert [logical.Transform] into one [Transform] node per input node. Like
+// parsing, transformations are applied per row.
+func (p *Planner) processTransform(lp *logical.Transform) ([]Node, error) {
+	children, err := p.process(lp.Table)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, 0, len(children))
+	for i := range children {
+		node := &Transform{
+			Kind:         lp.Kind,
+			Template:     lp.Template,
+			LabelFormats: lp.LabelFormats,
+			Labels:       lp.Labels,
+		}
+		p.plan.addNode(node)
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
 }
 
 // Convert [logical.Sort] into one [SortMerge] node.
@@ -135,6 +201,11 @@ func (p *Planner) processSort(lp *logical.Sort) ([]Node, error) {
 		return nil, err
 	}
 	for i := range children {
+		// The read direction of the DataObjScan must match the order of the
+		// SortMerge so its inputs are already sorted.
+		if scan, ok := children[i].(*DataObjScan); ok {
+			scan.Direction = directionForOrder(order)
+		}
 		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
 			return nil, err
 		}
@@ -142,6 +213,13 @@ func (p *Planner) processSort(lp *logical.Sort) ([]Node, error) {
 	return []Node{node}, nil
 }
 
+func directionForOrder(order SortOrder) Direction {
+	if order == DESC {
+		return Backwards
+	}
+	return Forward
+}
+
 // Convert [logical.Limit] into one [Limit] node.
 func (p *Planner) processLimit(lp *logical.Limit) ([]Node, error) {
 	node := &Limit{
@@ -161,9 +239,69 @@ func (p *Planner) processLimit(lp *logical.Limit) ([]Node, error) {
 	return []Node{node}, nil
 }
 
-// Optimize tries to optimize the plan by pushing down filter predicates and limits
-// to the scan nodes.
+// Convert [logical.RangeAggregation] into one [RangeAggregation] node.
+func (p *Planner) processRangeAggregation(lp *logical.RangeAggregation) ([]Node, error) {
+	node := &RangeAggregation{
+		Operation:   lp.Operation,
+		PartitionBy: convertColumnRefs(lp.PartitionBy),
+		Start:       lp.Start,
+		End:         lp.End,
+		Step:        lp.Step,
+		Range:       lp.RangeInterval,
+	}
+	p.plan.addNode(node)
+	children, err := p.process(lp.Table)
+	if err != nil {
+		return nil, err
+	}
+	for i := range children {
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+	}
+	return []Node{node}, nil
+}
+
+// Convert [logical.VectorAggregation] into one [VectorAggregation] node.
+func (p *Planner) processVectorAggregation(lp *logical.VectorAggregation) ([]Node, error) {
+	node := &VectorAggregation{
+		Operation: lp.Operation,
+		GroupBy:   convertColumnRefs(lp.GroupBy),
+		Without:   lp.Without,
+	}
+	p.plan.addNode(node)
+	children, err := p.process(lp.Table)
+	if err != nil {
+		return nil, err
+	}
+	for i := range children {
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+	}
+	return []Node{node}, nil
+}
+
+func convertColumnRefs(refs []logical.ColumnRef) []ColumnExpression {
+	if len(refs) == 0 {
+		return nil
+	}
+	columns := make([]ColumnExpression, len(refs))
+	for i := range refs {
+		columns[i] = &ColumnExpr{Ref: refs[i].Ref}
+	}
+	return columns
+}
+
+// Optimize tries to optimize the plan by pushing down filter predicates, limits,
+// and projections to the scan nodes. Afterwards, the statistics of the data
+// objects read by the plan are used to skip sections and order predicates.
 func (p *Planner) Optimize(plan *Plan) (*Plan, error) {
+	statistics, err := p.catalog.ResolveDataObjStatistics(dataObjLocations(plan))
+	if err != nil {
+		return nil, err
+	}
+
 	for i, root := range plan.Roots() {
 
 		optimizations := []*optimization{
@@ -174,6 +312,13 @@ func (p *Planner) Optimize(plan *Plan) (*Plan, error) {
 			newOptimization("LimitPushdown", plan).withRules(
 				&limitPushdown{plan: plan},
 			),
+			newOptimization("ProjectionPushdown", plan).withRules(
+				&projectionPushdown{plan: plan},
+			),
+			newOptimization("CostBasedOptimization", plan).withRules(
+				&sectionPruning{plan: plan, statistics: statistics},
+				&predicateOrdering{plan: plan, statistics: statistics},
+			),
 		}
 		optimizer := newOptimizer(plan, optimizations)
 		optimizer.optimize(root)
@@ -183,3 +328,15 @@ func (p *Planner) Optimize(plan *Plan) (*Plan, error) {
 	}
 	return plan, nil
 }
+
+// dataObjLocations returns the distinct locations of the data objects read by
+// the DataObjScan nodes of plan.
+func dataObjLocations(plan *Plan) []DataObjLocation {
+	var locations []DataObjLocation
+	for _, node := range plan.Leaves() {
+		if scan, ok := node.(*DataObjScan); ok && !slices.Contains(locations, scan.Location) {
+			locations = append(locations, scan.Location)
+		}
+	}
+	return locations
+}
@@ -2,15 +2,19 @@ package physical
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/dataobj"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/logical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 )
 
 type catalog struct {
 	streamsByObject map[string][]int64
+	statistics      Statistics
 }
 
 // ResolveDataObj implements Catalog.
@@ -24,6 +28,11 @@ func (t *catalog) ResolveDataObj(Expression) ([]DataObjLocation, [][]int64, erro
 	return objects, streams, nil
 }
 
+// ResolveDataObjStatistics implements Catalog.
+func (t *catalog) ResolveDataObjStatistics([]DataObjLocation) (Statistics, error) {
+	return t.statistics, nil
+}
+
 var _ Catalog = (*catalog)(nil)
 
 func TestPlanner_Convert(t *testing.T) {
@@ -74,3 +83,327 @@ func TestPlanner_Convert(t *testing.T) {
 	require.NoError(t, err)
 	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
 }
+
+func TestPlanner_ConvertMetricQuery(t *testing.T) {
+	// Build a metric query plan:
+	// sum by (app) (count_over_time({ env="prod" } |= "error" [5m]))
+	start, end := time.Unix(3600, 0), time.Unix(7200, 0)
+	b := logical.NewBuilder(
+		&logical.MakeTable{
+			Selector: &logical.BinOp{
+				Left:  logical.NewColumnRef("env", types.ColumnTypeLabel),
+				Right: logical.NewLiteral("prod"),
+				Op:    types.BinaryOpEq,
+			},
+		},
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin),
+			Right: logical.NewLiteral("error"),
+			Op:    types.BinaryOpMatchSubstr,
+		},
+	).RangeAggregation(
+		types.RangeAggregationTypeCount, nil, start, end, time.Minute, 5*time.Minute,
+	).VectorAggregation(
+		types.VectorAggregationTypeSum,
+		[]logical.ColumnRef{*logical.NewColumnRef("app", types.ColumnTypeAmbiguous)},
+		false,
+	)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	catalog := &catalog{
+		streamsByObject: map[string][]int64{
+			"obj1": {1, 2},
+			"obj2": {3, 4},
+		},
+	}
+	planner := NewPlanner(catalog)
+
+	physicalPlan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+	physicalPlan, err = planner.Optimize(physicalPlan)
+	require.NoError(t, err)
+	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+	roots := physicalPlan.Roots()
+	require.Len(t, roots, 1)
+
+	vectorAgg, ok := roots[0].(*VectorAggregation)
+	require.True(t, ok, "expected root to be VectorAggregation, got %T", roots[0])
+	require.Equal(t, types.VectorAggregationTypeSum, vectorAgg.Operation)
+	require.Equal(t, []ColumnExpression{&ColumnExpr{Ref: types.ColumnRef{Column: "app", Type: types.ColumnTypeAmbiguous}}}, vectorAgg.GroupBy)
+
+	children := physicalPlan.Children(vectorAgg)
+	require.Len(t, children, 1)
+	rangeAgg, ok := children[0].(*RangeAggregation)
+	require.True(t, ok, "expected child to be RangeAggregation, got %T", children[0])
+	require.Equal(t, types.RangeAggregationTypeCount, rangeAgg.Operation)
+	require.Equal(t, start, rangeAgg.Start)
+	require.Equal(t, end, rangeAgg.End)
+	require.Equal(t, time.Minute, rangeAgg.Step)
+	require.Equal(t, 5*time.Minute, rangeAgg.Range)
+
+	// The line filter is pushed down into the scans, which leaves the filter
+	// without predicates.
+	for _, node := range physicalPlan.Leaves() {
+		scan, ok := node.(*DataObjScan)
+		require.True(t, ok, "expected leaf to be DataObjScan, got %T", node)
+		require.Len(t, scan.Predicates, 1)
+	}
+}
+
+func TestPlanner_ConvertParse(t *testing.T) {
+	// Build a log query plan with a parser:
+	// { env="prod" } | json | level="error" |= "timeout"
+	b := logical.NewBuilder(
+		&logical.MakeTable{
+			Selector: &logical.BinOp{
+				Left:  logical.NewColumnRef("env", types.ColumnTypeLabel),
+				Right: logical.NewLiteral("prod"),
+				Op:    types.BinaryOpEq,
+			},
+		},
+	).Sort(
+		*logical.NewColumnRef(types.ColumnNameBuiltinTimestamp, types.ColumnTypeBuiltin), true, false,
+	).Parse(
+		logical.Parse{Kind: types.ParserTypeJSON},
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef("level", types.ColumnTypeAmbiguous),
+			Right: logical.NewLiteral("error"),
+			Op:    types.BinaryOpEq,
+		},
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin),
+			Right: logical.NewLiteral("timeout"),
+			Op:    types.BinaryOpMatchSubstr,
+		},
+	).Limit(0, 100)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	catalog := &catalog{
+		streamsByObject: map[string][]int64{
+			"obj1": {1, 2},
+			"obj2": {3, 4},
+		},
+	}
+	planner := NewPlanner(catalog)
+
+	physicalPlan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+	physicalPlan, err = planner.Optimize(physicalPlan)
+	require.NoError(t, err)
+	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+	var parse *Parse
+	visitor := &nodeCollectVisitor{
+		onVisitParse: func(n *Parse) error {
+			parse = n
+			return nil
+		},
+	}
+	require.NoError(t, physicalPlan.DFSWalk(physicalPlan.Roots()[0], visitor, PreOrderWalk))
+	require.NotNil(t, parse, "expected plan to contain a Parse node")
+	require.Equal(t, types.ParserTypeJSON, parse.Kind)
+
+	// The label filter references columns that may be extracted by the
+	// parser, so it must remain above the Parse node.
+	parents := physicalPlan.Parents(parse)
+	require.Len(t, parents, 1)
+	filter, ok := parents[0].(*Filter)
+	require.True(t, ok, "expected parent of Parse to be Filter, got %T", parents[0])
+	require.Len(t, filter.Predicates, 1)
+
+	// The line filter does not depend on the parser and is pushed down into
+	// the scans. The limit is not pushed down, because the label filter may
+	// remove rows.
+	for _, node := range physicalPlan.Leaves() {
+		scan, ok := node.(*DataObjScan)
+		require.True(t, ok, "expected leaf to be DataObjScan, got %T", node)
+		require.Len(t, scan.Predicates, 1)
+		require.Zero(t, scan.Limit)
+	}
+}
+
+func TestPlanner_ConvertTransform(t *testing.T) {
+	// Build a log query plan with transformations:
+	// { env="prod" } | line_format "{{.app}}" |= "foo" | keep app
+	b := logical.NewBuilder(
+		&logical.MakeTable{
+			Selector: &logical.BinOp{
+				Left:  logical.NewColumnRef("env", types.ColumnTypeLabel),
+				Right: logical.NewLiteral("prod"),
+				Op:    types.BinaryOpEq,
+			},
+		},
+	).Sort(
+		*logical.NewColumnRef(types.ColumnNameBuiltinTimestamp, types.ColumnTypeBuiltin), true, false,
+	).Transform(
+		logical.Transform{Kind: types.TransformTypeLineFormat, Template: "{{.app}}"},
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef(types.ColumnNameBuiltinLog, types.ColumnTypeBuiltin),
+			Right: logical.NewLiteral("foo"),
+			Op:    types.BinaryOpMatchSubstr,
+		},
+	).Transform(
+		logical.Transform{Kind: types.TransformTypeKeep, Labels: []log.NamedLabelMatcher{{Name: "app"}}},
+	).Limit(0, 100)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	catalog := &catalog{
+		streamsByObject: map[string][]int64{
+			"obj1": {1, 2},
+		},
+	}
+	planner := NewPlanner(catalog)
+
+	physicalPlan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+	physicalPlan, err = planner.Optimize(physicalPlan)
+	require.NoError(t, err)
+	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+	var transforms []*Transform
+	visitor := &nodeCollectVisitor{
+		onVisitTransform: func(n *Transform) error {
+			transforms = append(transforms, n)
+			return nil
+		},
+	}
+	require.NoError(t, physicalPlan.DFSWalk(physicalPlan.Roots()[0], visitor, PreOrderWalk))
+	require.Len(t, transforms, 2)
+	require.Equal(t, types.TransformTypeKeep, transforms[0].Kind)
+	require.Equal(t, types.TransformTypeLineFormat, transforms[1].Kind)
+
+	// The line filter applies to the formatted log line, so it must remain
+	// between the two Transform nodes.
+	children := physicalPlan.Children(transforms[0])
+	require.Len(t, children, 1)
+	filter, ok := children[0].(*Filter)
+	require.True(t, ok, "expected child of keep to be Filter, got %T", children[0])
+	require.Len(t, filter.Predicates, 1)
+
+	// The line_format template may reference any label, so no projections
+	// are pushed down into the scans.
+	for _, node := range physicalPlan.Leaves() {
+		scan, ok := node.(*DataObjScan)
+		require.True(t, ok, "expected leaf to be DataObjScan, got %T", node)
+		require.Empty(t, scan.Predicates)
+		require.Empty(t, scan.Projections)
+	}
+}
+
+func TestPlanner_OptimizeWithStatistics(t *testing.T) {
+	// Build a log query plan:
+	// { app="users" } | level="error"
+	// for the time range [100s, 200s).
+	b := logical.NewBuilder(
+		&logical.MakeTable{
+			Selector: &logical.BinOp{
+				Left:  logical.NewColumnRef("app", types.ColumnTypeLabel),
+				Right: logical.NewLiteral("users"),
+				Op:    types.BinaryOpEq,
+			},
+		},
+	).Sort(
+		*logical.NewColumnRef("timestamp", types.ColumnTypeBuiltin),
+		false,
+		false,
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef("timestamp", types.ColumnTypeBuiltin),
+			Right: logical.NewLiteral(uint64(time.Unix(100, 0).UnixNano())),
+			Op:    types.BinaryOpGte,
+		},
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef("timestamp", types.ColumnTypeBuiltin),
+			Right: logical.NewLiteral(uint64(time.Unix(200, 0).UnixNano())),
+			Op:    types.BinaryOpLt,
+		},
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef("level", types.ColumnTypeMetadata),
+			Right: logical.NewLiteral("error"),
+			Op:    types.BinaryOpEq,
+		},
+	).Limit(0, 100)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	statistics := Statistics{
+		"obj1": {
+			{Rows: 100, MinTime: time.Unix(0, 0), MaxTime: time.Unix(90, 0), MinStreamID: 1, MaxStreamID: 2},
+			{
+				Rows: 100, MinTime: time.Unix(120, 0), MaxTime: time.Unix(180, 0), MinStreamID: 1, MaxStreamID: 2,
+				Metadata: map[string]dataobj.ColumnStatistics{
+					"level": {Values: 100, Cardinality: 4, Min: "debug", Max: "warn", HasRange: true},
+				},
+			},
+		},
+		"obj2": {
+			{
+				Rows: 100, MinTime: time.Unix(150, 0), MaxTime: time.Unix(250, 0), MinStreamID: 1, MaxStreamID: 1,
+				Metadata: map[string]dataobj.ColumnStatistics{
+					"level": {Values: 100, Cardinality: 1, Min: "info", Max: "info", HasRange: true},
+				},
+			},
+		},
+	}
+
+	optimize := func(statistics Statistics) []*DataObjScan {
+		catalog := &catalog{
+			streamsByObject: map[string][]int64{
+				"obj1": {1, 2},
+				"obj2": {1},
+			},
+			statistics: statistics,
+		}
+		planner := NewPlanner(catalog)
+
+		physicalPlan, err := planner.Build(logicalPlan)
+		require.NoError(t, err)
+		physicalPlan, err = planner.Optimize(physicalPlan)
+		require.NoError(t, err)
+		t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+		scans := make(map[DataObjLocation]*DataObjScan)
+		for _, node := range physicalPlan.Leaves() {
+			scan := node.(*DataObjScan)
+			scans[scan.Location] = scan
+		}
+		return []*DataObjScan{scans["obj1"], scans["obj2"]}
+	}
+
+	t.Run("without statistics", func(t *testing.T) {
+		scans := optimize(nil)
+		for _, scan := range scans {
+			require.Nil(t, scan.Sections)
+			require.True(t, scan.TimeRange.IsZero())
+			require.Len(t, scan.Predicates, 3)
+		}
+	})
+
+	t.Run("with statistics", func(t *testing.T) {
+		scans := optimize(statistics)
+
+		// The first section of obj1 is older than the query, and no log
+		// record of obj2 has an error level.
+		require.Equal(t, []int{1}, scans[0].Sections)
+		require.Equal(t, TimeRange{Start: time.Unix(120, 0), End: time.Unix(180, 0)}, scans[0].TimeRange)
+		require.Equal(t, []int{}, scans[1].Sections)
+		require.True(t, scans[1].TimeRange.IsZero())
+
+		// The level predicate is the most selective predicate of obj1.
+		require.Equal(t, "level", scans[0].Predicates[0].(*BinaryExpr).Left.(*ColumnExpr).Ref.Column)
+	})
+}
@@ -11,13 +11,36 @@ import (
 // BuildTree converts a physical plan node and its children into a tree structure
 // that can be used for visualization and debugging purposes.
 func BuildTree(p *Plan, n Node) *tree.Node {
-	return toTree(p, n)
+	return toTree(p, n, nil)
 }
 
-func toTree(p *Plan, n Node) *tree.Node {
+// Annotation is an additional property of a node that is printed by
+// [PrintAsTreeAnnotated], such as a runtime statistic of the node.
+type Annotation struct {
+	Key   string
+	Value any
+}
+
+// Annotator returns the annotations of node n of plan p. The plan is either
+// the printed plan or the plan of a fragment of it.
+type Annotator func(p *Plan, n Node) []Annotation
+
+func toTree(p *Plan, n Node, annotate Annotator) *tree.Node {
 	root := toTreeNode(n)
+	if annotate != nil {
+		for _, a := range annotate(p, n) {
+			root.Properties = append(root.Properties, tree.NewProperty(a.Key, false, a.Value))
+		}
+	}
+	// The nodes of a fragment are not part of the plan, so they are printed
+	// as the children of the Exchange node that consumes the fragment.
+	if exchange, ok := n.(*Exchange); ok && exchange.Fragment != nil {
+		for _, fragmentRoot := range exchange.Fragment.Plan.Roots() {
+			root.Children = append(root.Children, toTree(exchange.Fragment.Plan, fragmentRoot, annotate))
+		}
+	}
 	for _, child := range p.Children(n) {
-		if ch := toTree(p, child); ch != nil {
+		if ch := toTree(p, child, annotate); ch != nil {
 			root.Children = append(root.Children, ch)
 		}
 	}
@@ -35,6 +58,12 @@ func toTreeNode(n Node) *tree.Node {
 			tree.NewProperty("direction", false, node.Direction),
 			tree.NewProperty("limit", false, node.Limit),
 		}
+		if node.Sections != nil {
+			treeNode.Properties = append(treeNode.Properties, tree.NewProperty("sections", true, toAnySlice(node.Sections)...))
+		}
+		if !node.TimeRange.IsZero() {
+			treeNode.Properties = append(treeNode.Properties, tree.NewProperty("time_range", false, node.TimeRange))
+		}
 		for i := range node.Predicates {
 			treeNode.Properties = append(treeNode.Properties, tree.NewProperty(fmt.Sprintf("predicate[%d]", i), false, node.Predicates[i].String()))
 		}
@@ -56,6 +85,61 @@ func toTreeNode(n Node) *tree.Node {
 			tree.NewProperty("offset", false, node.Skip),
 			tree.NewProperty("limit", false, node.Fetch),
 		}
+	case *Parse:
+		extractions := make([]any, len(node.Extractions))
+		for i, e := range node.Extractions {
+			extractions[i] = e.Identifier + "=" + e.Expression
+		}
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty("kind", false, node.Kind),
+			tree.NewProperty("expression", false, node.Expression),
+			tree.NewProperty("extractions", true, extractions...),
+			tree.NewProperty("strict", false, node.Strict),
+			tree.NewProperty("keep_empty", false, node.KeepEmpty),
+		}
+	case *Transform:
+		formats := make([]any, len(node.LabelFormats))
+		for i, f := range node.LabelFormats {
+			formats[i] = f.Name + "=" + f.Value
+		}
+		matchers := make([]any, len(node.Labels))
+		for i, m := range node.Labels {
+			if m.Matcher != nil {
+				matchers[i] = m.Matcher.String()
+				continue
+			}
+			matchers[i] = m.Name
+		}
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty("kind", false, node.Kind),
+			tree.NewProperty("template", false, node.Template),
+			tree.NewProperty("formats", true, formats...),
+			tree.NewProperty("labels", true, matchers...),
+		}
+	case *RangeAggregation:
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty("operation", false, node.Operation),
+			tree.NewProperty("start", false, node.Start.UnixNano()),
+			tree.NewProperty("end", false, node.End.UnixNano()),
+			tree.NewProperty("step", false, node.Step),
+			tree.NewProperty("range", false, node.Range),
+			tree.NewProperty("partition_by", true, toAnySlice(node.PartitionBy)...),
+		}
+	case *Exchange:
+		if node.Fragment != nil {
+			treeNode.Properties = []tree.Property{
+				tree.NewProperty("fragment", false, node.Fragment.ID),
+			}
+		}
+	case *VectorAggregation:
+		grouping := "group_by"
+		if node.Without {
+			grouping = "group_without"
+		}
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty("operation", false, node.Operation),
+			tree.NewProperty(grouping, true, toAnySlice(node.GroupBy)...),
+		}
 	}
 	return treeNode
 }
@@ -72,12 +156,18 @@ func toAnySlice[T any](s []T) []any {
 // It processes each root node in the plan graph, and returns the combined
 // string output of all trees joined by newlines.
 func PrintAsTree(p *Plan) string {
+	return PrintAsTreeAnnotated(p, nil)
+}
+
+// PrintAsTreeAnnotated is like [PrintAsTree], but appends the annotations
+// returned by annotate to the properties of each node.
+func PrintAsTreeAnnotated(p *Plan, annotate Annotator) string {
 	results := make([]string, 0, len(p.Roots()))
 
 	for _, root := range p.Roots() {
 		sb := &strings.Builder{}
 		printer := tree.NewPrinter(sb)
-		node := BuildTree(p, root)
+		node := toTree(p, root, annotate)
 		printer.Print(node)
 		results = append(results, sb.String())
 	}
@@ -1,6 +1,11 @@
 package physical
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
 
 func TestPrinter(t *testing.T) {
 	t.Run("simple tree", func(t *testing.T) {
@@ -49,4 +54,18 @@ func TestPrinter(t *testing.T) {
 		repr := PrintAsTree(p)
 		t.Log("\n" + repr)
 	})
+
+	t.Run("annotated", func(t *testing.T) {
+		p := &Plan{}
+		limit := p.addNode(&Limit{id: "limit", Fetch: 10})
+		scan := p.addNode(&DataObjScan{id: "scan"})
+		_ = p.addEdge(Edge{Parent: limit, Child: scan})
+
+		repr := PrintAsTreeAnnotated(p, func(_ *Plan, n Node) []Annotation {
+			return []Annotation{{Key: "rows_out", Value: len(n.ID())}}
+		})
+		lines := strings.Split(repr, "\n")
+		require.Equal(t, "Limit <limit> offset=0 limit=10 rows_out=5", lines[0])
+		require.True(t, strings.HasSuffix(lines[1], " rows_out=4"), lines[1])
+	})
 }
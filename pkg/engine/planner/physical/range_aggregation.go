@@ -0,0 +1,49 @@
+package physical
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// RangeAggregation represents an aggregation of rows over time in the
+// physical plan. For each step between Start and End, the rows of a series
+// with a timestamp within the range (step-Range, step] are aggregated into a
+// single sample.
+type RangeAggregation struct {
+	id string
+
+	// Operation is the aggregation that is applied to the rows of a range.
+	Operation types.RangeAggregationType
+	// PartitionBy defines the columns by which rows are partitioned into
+	// series. If empty, rows are partitioned by all label and metadata
+	// columns.
+	PartitionBy []ColumnExpression
+
+	Start time.Time     // Timestamp of the first step.
+	End   time.Time     // Timestamp of the last step.
+	Step  time.Duration // Duration between two steps. A Step of 0 produces a single step at End.
+	Range time.Duration // Length of the range of each step.
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (r *RangeAggregation) ID() string {
+	if r.id == "" {
+		return fmt.Sprintf("%p", r)
+	}
+	return r.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*RangeAggregation) Type() NodeType {
+	return NodeTypeRangeAggregation
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (r *RangeAggregation) Accept(v Visitor) error {
+	return v.VisitRangeAggregation(r)
+}
@@ -0,0 +1,62 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// Transform represents the computation of the log line or labels of rows
+// from templates, or the removal of labels, in the physical plan. Labels are
+// the label, metadata, and parsed columns of a row.
+type Transform struct {
+	id string
+
+	// Kind is the kind of transformation.
+	Kind types.TransformType
+	// Template is the template of the line_format transformation used to
+	// compute the new log line.
+	Template string
+	// LabelFormats are the renames and templates of the label_format
+	// transformation.
+	LabelFormats []log.LabelFmt
+	// Labels are the labels kept by the keep transformation or removed by the
+	// drop transformation.
+	Labels []log.NamedLabelMatcher
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (t *Transform) ID() string {
+	if t.id == "" {
+		return fmt.Sprintf("%p", t)
+	}
+	return t.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*Transform) Type() NodeType {
+	return NodeTypeTransform
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (t *Transform) Accept(v Visitor) error {
+	return v.VisitTransform(t)
+}
+
+// labelNames returns the names of the labels of a keep or drop
+// transformation, including the names of labels with a matcher.
+func (t *Transform) labelNames() []string {
+	names := make([]string, 0, len(t.Labels))
+	for _, l := range t.Labels {
+		if l.Matcher != nil {
+			names = append(names, l.Matcher.Name)
+			continue
+		}
+		names = append(names, l.Name)
+	}
+	return names
+}
@@ -0,0 +1,43 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// VectorAggregation represents an aggregation of the samples that share the
+// same timestamp in the physical plan. Samples are aggregated per group,
+// where groups are defined by the GroupBy columns.
+type VectorAggregation struct {
+	id string
+
+	// Operation is the aggregation that is applied to the samples of a group.
+	Operation types.VectorAggregationType
+	// GroupBy defines the columns by which samples are grouped. If Without is
+	// true, samples are grouped by all label and metadata columns except the
+	// ones in GroupBy.
+	GroupBy []ColumnExpression
+	Without bool
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (v *VectorAggregation) ID() string {
+	if v.id == "" {
+		return fmt.Sprintf("%p", v)
+	}
+	return v.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*VectorAggregation) Type() NodeType {
+	return NodeTypeVectorAggregation
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (v *VectorAggregation) Accept(vis Visitor) error {
+	return vis.VisitVectorAggregation(v)
+}
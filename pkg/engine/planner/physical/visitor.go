@@ -10,4 +10,9 @@ type Visitor interface {
 	VisitProjection(*Projection) error
 	VisitFilter(*Filter) error
 	VisitLimit(*Limit) error
+	VisitRangeAggregation(*RangeAggregation) error
+	VisitVectorAggregation(*VectorAggregation) error
+	VisitParse(*Parse) error
+	VisitTransform(*Transform) error
+	VisitExchange(*Exchange) error
 }
@@ -14,6 +14,12 @@ type nodeCollectVisitor struct {
 	onVisitLimit       func(*Limit) error
 	onVisitSortMerge   func(*SortMerge) error
 	onVisitProjection  func(*Projection) error
+
+	onVisitRangeAggregation  func(*RangeAggregation) error
+	onVisitVectorAggregation func(*VectorAggregation) error
+	onVisitParse             func(*Parse) error
+	onVisitTransform         func(*Transform) error
+	onVisitExchange          func(*Exchange) error
 }
 
 func (v *nodeCollectVisitor) VisitDataObjScan(n *DataObjScan) error {
@@ -55,3 +61,43 @@ func (v *nodeCollectVisitor) VisitSortMerge(n *SortMerge) error {
 	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
 	return nil
 }
+
+func (v *nodeCollectVisitor) VisitRangeAggregation(n *RangeAggregation) error {
+	if v.onVisitRangeAggregation != nil {
+		return v.onVisitRangeAggregation(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitVectorAggregation(n *VectorAggregation) error {
+	if v.onVisitVectorAggregation != nil {
+		return v.onVisitVectorAggregation(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitParse(n *Parse) error {
+	if v.onVisitParse != nil {
+		return v.onVisitParse(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitTransform(n *Transform) error {
+	if v.onVisitTransform != nil {
+		return v.onVisitTransform(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitExchange(n *Exchange) error {
+	if v.onVisitExchange != nil {
+		return v.onVisitExchange(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
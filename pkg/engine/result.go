@@ -0,0 +1,270 @@
+package engine
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/loki/v3/pkg/columnar"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// resultBuilder collects the batches returned by the executor into the
+// result of a query.
+type resultBuilder interface {
+	collectBatch(batch *columnar.RecordBatch) error
+	Len() int
+	Build() parser.Value
+}
+
+var (
+	_ resultBuilder = (*streamsResultBuilder)(nil)
+	_ resultBuilder = (*samplesResultBuilder)(nil)
+)
+
+// streamsResultBuilder collects the rows of batches returned by the executor
+// into log streams. Rows are grouped by the combination of their stream
+// labels, structured metadata, and parsed labels, which matches the stream
+// labels returned by the classic engine.
+type streamsResultBuilder struct {
+	streams map[string]int
+	data    logqlmodel.Streams
+	count   int
+}
+
+func newStreamsResultBuilder() *streamsResultBuilder {
+	return &streamsResultBuilder{
+		streams: make(map[string]int),
+		data:    make(logqlmodel.Streams, 0),
+	}
+}
+
+// collectBatch appends each row of the batch as entry to the stream it
+// belongs to.
+func (b *streamsResultBuilder) collectBatch(batch *columnar.RecordBatch) error {
+	var (
+		timestamps *columnar.TimestampArray
+		lines      *columnar.StringArray
+
+		labelCols, metadataCols, parsedCols    []*columnar.StringArray
+		labelNames, metadataNames, parsedNames []string
+	)
+
+	schema := batch.Schema()
+	for i := range batch.NumCols() {
+		field := schema.Field(i)
+		switch types.ColumnTypeOf(field) {
+		case types.ColumnTypeBuiltin:
+			switch field.Name {
+			case types.ColumnNameBuiltinTimestamp:
+				timestamps, _ = batch.Column(i).(*columnar.TimestampArray)
+			case types.ColumnNameBuiltinLog:
+				lines, _ = batch.Column(i).(*columnar.StringArray)
+			}
+		case types.ColumnTypeLabel:
+			if col, ok := batch.Column(i).(*columnar.StringArray); ok {
+				labelCols = append(labelCols, col)
+				labelNames = append(labelNames, field.Name)
+			}
+		case types.ColumnTypeMetadata:
+			if col, ok := batch.Column(i).(*columnar.StringArray); ok {
+				metadataCols = append(metadataCols, col)
+				metadataNames = append(metadataNames, field.Name)
+			}
+		case types.ColumnTypeParsed:
+			if col, ok := batch.Column(i).(*columnar.StringArray); ok {
+				parsedCols = append(parsedCols, col)
+				parsedNames = append(parsedNames, field.Name)
+			}
+		}
+	}
+	if timestamps == nil || lines == nil {
+		return errors.New("batch is missing the timestamp or log column")
+	}
+
+	var (
+		lbsBuilder    = labels.NewScratchBuilder(len(labelCols))
+		mdBuilder     = labels.NewScratchBuilder(len(metadataCols))
+		parsedBuilder = labels.NewScratchBuilder(len(parsedCols))
+	)
+	for i := range batch.NumRows() {
+		lbsBuilder.Reset()
+		mdBuilder.Reset()
+		parsedBuilder.Reset()
+
+		for c, col := range labelCols {
+			if !col.IsNull(i) && col.Value(i) != "" {
+				lbsBuilder.Add(labelNames[c], col.Value(i))
+			}
+		}
+		for c, col := range metadataCols {
+			if !col.IsNull(i) && col.Value(i) != "" {
+				mdBuilder.Add(metadataNames[c], col.Value(i))
+			}
+		}
+		for c, col := range parsedCols {
+			if !col.IsNull(i) && col.Value(i) != "" {
+				parsedBuilder.Add(parsedNames[c], col.Value(i))
+			}
+		}
+		lbsBuilder.Sort()
+		mdBuilder.Sort()
+		parsedBuilder.Sort()
+		metadata := mdBuilder.Labels()
+		parsed := parsedBuilder.Labels()
+
+		// Structured metadata overrides stream labels with the same name,
+		// and parsed labels override both.
+		builder := labels.NewBuilder(lbsBuilder.Labels())
+		metadata.Range(func(l labels.Label) { builder.Set(l.Name, l.Value) })
+		parsed.Range(func(l labels.Label) { builder.Set(l.Name, l.Value) })
+		key := builder.Labels().String()
+
+		idx, ok := b.streams[key]
+		if !ok {
+			idx = len(b.data)
+			b.streams[key] = idx
+			b.data = append(b.data, logproto.Stream{Labels: key})
+		}
+
+		entry := logproto.Entry{
+			Timestamp: time.Unix(0, timestamps.Value(i)).UTC(),
+			Line:      string(lines.Bytes(i)),
+		}
+		if !metadata.IsEmpty() {
+			entry.StructuredMetadata = logproto.FromLabelsToLabelAdapters(metadata)
+		}
+		if !parsed.IsEmpty() {
+			entry.Parsed = logproto.FromLabelsToLabelAdapters(parsed)
+		}
+		b.data[idx].Entries = append(b.data[idx].Entries, entry)
+		b.count++
+	}
+	return nil
+}
+
+// Len returns the total number of entries collected.
+func (b *streamsResultBuilder) Len() int {
+	return b.count
+}
+
+// Build returns the collected streams sorted by their labels.
+func (b *streamsResultBuilder) Build() parser.Value {
+	sort.Sort(b.data)
+	return b.data
+}
+
+// samplesResultBuilder collects the rows of batches returned by the executor
+// into series of samples. Each row is required to have a builtin timestamp
+// and value column. The label, metadata, and parsed columns of a row make up the
+// labels of its series.
+type samplesResultBuilder struct {
+	instant bool
+	series  map[string]*promql.Series
+	count   int
+}
+
+// newSamplesResultBuilder returns a builder for the results of metric
+// queries. If instant is true, the builder returns a [promql.Vector],
+// otherwise a [promql.Matrix].
+func newSamplesResultBuilder(instant bool) *samplesResultBuilder {
+	return &samplesResultBuilder{
+		instant: instant,
+		series:  make(map[string]*promql.Series),
+	}
+}
+
+// collectBatch appends each row of the batch as a sample to the series it
+// belongs to.
+func (b *samplesResultBuilder) collectBatch(batch *columnar.RecordBatch) error {
+	var (
+		timestamps *columnar.TimestampArray
+		values     *columnar.Float64Array
+
+		labelCols  []*columnar.StringArray
+		labelNames []string
+	)
+
+	schema := batch.Schema()
+	for i := range batch.NumCols() {
+		field := schema.Field(i)
+		switch types.ColumnTypeOf(field) {
+		case types.ColumnTypeBuiltin:
+			switch field.Name {
+			case types.ColumnNameBuiltinTimestamp:
+				timestamps, _ = batch.Column(i).(*columnar.TimestampArray)
+			case types.ColumnNameBuiltinValue:
+				values, _ = batch.Column(i).(*columnar.Float64Array)
+			}
+		case types.ColumnTypeLabel, types.ColumnTypeMetadata, types.ColumnTypeParsed:
+			if col, ok := batch.Column(i).(*columnar.StringArray); ok {
+				labelCols = append(labelCols, col)
+				labelNames = append(labelNames, field.Name)
+			}
+		}
+	}
+	if timestamps == nil || values == nil {
+		return errors.New("batch is missing the timestamp or value column")
+	}
+
+	lbsBuilder := labels.NewBuilder(labels.EmptyLabels())
+	for i := range batch.NumRows() {
+		if values.IsNull(i) {
+			continue
+		}
+
+		lbsBuilder.Reset(labels.EmptyLabels())
+		for c, col := range labelCols {
+			if !col.IsNull(i) && col.Value(i) != "" {
+				lbsBuilder.Set(labelNames[c], col.Value(i))
+			}
+		}
+		lbs := lbsBuilder.Labels()
+		key := lbs.String()
+
+		series, ok := b.series[key]
+		if !ok {
+			series = &promql.Series{Metric: lbs}
+			b.series[key] = series
+		}
+		series.Floats = append(series.Floats, promql.FPoint{
+			T: time.Unix(0, timestamps.Value(i)).UnixMilli(),
+			F: values.Value(i),
+		})
+		b.count++
+	}
+	return nil
+}
+
+// Len returns the total number of samples collected.
+func (b *samplesResultBuilder) Len() int {
+	return b.count
+}
+
+// Build returns the collected series sorted by their labels. Instant queries
+// return the last sample of each series as a vector.
+func (b *samplesResultBuilder) Build() parser.Value {
+	matrix := make(promql.Matrix, 0, len(b.series))
+	for _, series := range b.series {
+		sort.Slice(series.Floats, func(i, j int) bool { return series.Floats[i].T < series.Floats[j].T })
+		matrix = append(matrix, *series)
+	}
+	sort.Sort(matrix)
+
+	if !b.instant {
+		return matrix
+	}
+
+	vector := make(promql.Vector, 0, len(matrix))
+	for _, series := range matrix {
+		last := series.Floats[len(series.Floats)-1]
+		vector = append(vector, promql.Sample{Metric: series.Metric, T: last.T, F: last.F})
+	}
+	return vector
+}
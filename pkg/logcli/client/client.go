@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -39,6 +40,7 @@ const (
 	volumeRangePath         = "/loki/api/v1/index/volume_range"
 	detectedFieldsPath      = "/loki/api/v1/detected_fields"
 	detectedFieldValuesPath = "/loki/api/v1/detected_field/%s/values"
+	explainPath             = "/loki/api/v1/explain"
 	defaultAuthHeader       = "Authorization"
 
 	// HTTP header keys
@@ -63,6 +65,7 @@ type Client interface {
 	GetVolume(query *volume.Query) (*loghttp.QueryResponse, error)
 	GetVolumeRange(query *volume.Query) (*loghttp.QueryResponse, error)
 	GetDetectedFields(queryStr, fieldName string, fieldLimit, lineLimit int, start, end time.Time, step time.Duration, quiet bool) (*loghttp.DetectedFieldsResponse, error)
+	Explain(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, analyze, quiet bool) (*loghttp.ExplainResponse, error)
 }
 
 // Tripperware can wrap a roundtripper.
@@ -267,6 +270,34 @@ func (c *DefaultClient) GetDetectedFields(
 	return &r, nil
 }
 
+// Explain uses the /api/v1/explain endpoint to return the plans of a query of the next generation query engine.
+// If analyze is true, the query is executed and the plans contain the runtime statistics of the query.
+// excluding interfacer b/c it suggests taking the interface promql.Node instead of logproto.Direction b/c it happens to have a String() method
+// nolint:interfacer
+func (c *DefaultClient) Explain(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, analyze, quiet bool) (*loghttp.ExplainResponse, error) {
+	params := util.NewQueryStringBuilder()
+	params.SetString("query", queryStr)
+	params.SetInt32("limit", limit)
+	params.SetInt("start", start.UnixNano())
+	params.SetInt("end", end.UnixNano())
+	params.SetString("direction", direction.String())
+	params.SetString("analyze", strconv.FormatBool(analyze))
+
+	if step != 0 {
+		params.SetFloat("step", step.Seconds())
+	}
+
+	if interval != 0 {
+		params.SetFloat("interval", interval.Seconds())
+	}
+
+	var r loghttp.ExplainResponse
+	if err := c.doRequest(explainPath, params.Encode(), quiet, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
 func (c *DefaultClient) doQuery(
 	path string,
 	query string,
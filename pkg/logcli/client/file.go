@@ -217,6 +217,10 @@ func (f *FileClient) GetDetectedFields(
 	return nil, ErrNotSupported
 }
 
+func (f *FileClient) Explain(_ string, _ int, _, _ time.Time, _ logproto.Direction, _, _ time.Duration, _, _ bool) (*loghttp.ExplainResponse, error) {
+	return nil, ErrNotSupported
+}
+
 type limiter struct {
 	n int
 }
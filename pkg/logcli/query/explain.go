@@ -0,0 +1,28 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/logcli/print"
+)
+
+// DoExplain prints the logical and the physical plan of the query of the next
+// generation query engine. If Analyze is set, the query is executed and the
+// physical plan contains the runtime statistics of each of its nodes.
+func (q *Query) DoExplain(c client.Client, w io.Writer, statistics bool) {
+	resp, err := c.Explain(q.QueryString, q.Limit, q.Start, q.End, q.resultsDirection(), q.Step, q.Interval, q.Analyze, q.Quiet)
+	if err != nil {
+		log.Fatalf("Explain failed: %+v", err)
+	}
+
+	fmt.Fprintf(w, "Logical plan:\n%s\n", resp.Data.LogicalPlan)
+	fmt.Fprintf(w, "Physical plan:\n%s\n", resp.Data.PhysicalPlan)
+
+	if statistics && resp.Data.Statistics != nil {
+		result := print.NewQueryResultPrinter(q.ShowLabelsKey, q.IgnoreLabelsKey, q.Quiet, q.FixedLabelsLen, q.Forward, q.IncludeCommonLabels)
+		result.PrintStats(*resp.Data.Statistics)
+	}
+}
@@ -0,0 +1,48 @@
+package query
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type explainClient struct {
+	client.Client
+	analyze bool
+}
+
+func (c *explainClient) Explain(queryStr string, _ int, _, _ time.Time, _ logproto.Direction, _, _ time.Duration, analyze, _ bool) (*loghttp.ExplainResponse, error) {
+	c.analyze = analyze
+	return &loghttp.ExplainResponse{
+		Status: "success",
+		Data: loghttp.ExplainResponseData{
+			LogicalPlan:  "%1 = MAKETABLE [selector=" + queryStr + "]",
+			PhysicalPlan: "DataObjScan <scan> rows_out=3",
+			Analyzed:     analyze,
+		},
+	}, nil
+}
+
+func TestQuery_DoExplain(t *testing.T) {
+	q := &Query{
+		QueryString: `{app="foo"}`,
+		Start:       time.Unix(0, 0),
+		End:         time.Unix(3600, 0),
+		Limit:       30,
+		Quiet:       true,
+		Explain:     true,
+		Analyze:     true,
+	}
+	c := &explainClient{}
+
+	var buf bytes.Buffer
+	q.DoExplain(c, &buf, false)
+	require.True(t, c.analyze)
+	require.Equal(t, "Logical plan:\n%1 = MAKETABLE [selector={app=\"foo\"}]\nPhysical plan:\nDataObjScan <scan> rows_out=3\n", buf.String())
+}
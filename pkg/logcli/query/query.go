@@ -57,6 +57,11 @@ type Query struct {
 	FetchSchemaFromStorage bool
 	SchemaStore            string
 
+	// Explain prints the plans of the query of the next generation query engine instead of its results.
+	Explain bool
+	// Analyze executes the query to print the runtime statistics of its plan. It implies Explain.
+	Analyze bool
+
 	// Parallelization parameters.
 
 	// The duration of each part/job.
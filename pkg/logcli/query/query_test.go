@@ -495,6 +495,10 @@ func (t *testQueryClient) GetDetectedFields(
 	panic("not implemented")
 }
 
+func (t *testQueryClient) Explain(_ string, _ int, _, _ time.Time, _ logproto.Direction, _, _ time.Duration, _, _ bool) (*loghttp.ExplainResponse, error) {
+	panic("not implemented")
+}
+
 var legacySchemaConfigContents = `schema_config:
   configs:
   - from: 2020-05-15
@@ -0,0 +1,38 @@
+package loghttp
+
+import (
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+// ExplainResponse represents the http json response to an explain query of
+// the next generation query engine.
+type ExplainResponse struct {
+	Status string              `json:"status"`
+	Data   ExplainResponseData `json:"data"`
+}
+
+// ExplainResponseData represents the plans of an explained query, and their
+// runtime statistics if the query was analyzed.
+type ExplainResponseData struct {
+	LogicalPlan  string        `json:"logicalPlan"`
+	PhysicalPlan string        `json:"physicalPlan"`
+	Analyzed     bool          `json:"analyzed"`
+	Nodes        []ExplainNode `json:"nodes,omitempty"`
+	Statistics   *stats.Result `json:"statistics,omitempty"`
+}
+
+// ExplainNode represents the runtime statistics of a node of the physical
+// plan of an analyzed query.
+type ExplainNode struct {
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	RowsIn       int64         `json:"rowsIn"`
+	RowsOut      int64         `json:"rowsOut"`
+	Batches      int64         `json:"batches"`
+	WallTime     time.Duration `json:"wallTime"`
+	BytesRead    int64         `json:"bytesRead,omitempty"`
+	PagesScanned int64         `json:"pagesScanned,omitempty"`
+	PagesPruned  int64         `json:"pagesPruned,omitempty"`
+}
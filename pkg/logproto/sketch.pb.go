@@ -749,6 +749,152 @@ func (m *TopKMatrix_Vector) GetTimestampMs() int64 {
 	return 0
 }
 
+type HyperLogLogMatrix struct {
+	Values []*HyperLogLogVector `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (m *HyperLogLogMatrix) Reset()      { *m = HyperLogLogMatrix{} }
+func (*HyperLogLogMatrix) ProtoMessage() {}
+func (*HyperLogLogMatrix) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7f9fd40e59b87ff3, []int{10}
+}
+func (m *HyperLogLogMatrix) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HyperLogLogMatrix) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_HyperLogLogMatrix.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *HyperLogLogMatrix) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HyperLogLogMatrix.Merge(m, src)
+}
+func (m *HyperLogLogMatrix) XXX_Size() int {
+	return m.Size()
+}
+func (m *HyperLogLogMatrix) XXX_DiscardUnknown() {
+	xxx_messageInfo_HyperLogLogMatrix.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HyperLogLogMatrix proto.InternalMessageInfo
+
+func (m *HyperLogLogMatrix) GetValues() []*HyperLogLogVector {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type HyperLogLogVector struct {
+	Samples []*HyperLogLogSample `protobuf:"bytes,1,rep,name=samples,proto3" json:"samples,omitempty"`
+}
+
+func (m *HyperLogLogVector) Reset()      { *m = HyperLogLogVector{} }
+func (*HyperLogLogVector) ProtoMessage() {}
+func (*HyperLogLogVector) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7f9fd40e59b87ff3, []int{11}
+}
+func (m *HyperLogLogVector) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HyperLogLogVector) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_HyperLogLogVector.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *HyperLogLogVector) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HyperLogLogVector.Merge(m, src)
+}
+func (m *HyperLogLogVector) XXX_Size() int {
+	return m.Size()
+}
+func (m *HyperLogLogVector) XXX_DiscardUnknown() {
+	xxx_messageInfo_HyperLogLogVector.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HyperLogLogVector proto.InternalMessageInfo
+
+func (m *HyperLogLogVector) GetSamples() []*HyperLogLogSample {
+	if m != nil {
+		return m.Samples
+	}
+	return nil
+}
+
+type HyperLogLogSample struct {
+	// Binary encoding of the HyperLogLog sketch.
+	Sketch      []byte       `protobuf:"bytes,1,opt,name=sketch,proto3" json:"sketch,omitempty"`
+	TimestampMs int64        `protobuf:"varint,2,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	Metric      []*LabelPair `protobuf:"bytes,3,rep,name=metric,proto3" json:"metric,omitempty"`
+}
+
+func (m *HyperLogLogSample) Reset()      { *m = HyperLogLogSample{} }
+func (*HyperLogLogSample) ProtoMessage() {}
+func (*HyperLogLogSample) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7f9fd40e59b87ff3, []int{12}
+}
+func (m *HyperLogLogSample) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HyperLogLogSample) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_HyperLogLogSample.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *HyperLogLogSample) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HyperLogLogSample.Merge(m, src)
+}
+func (m *HyperLogLogSample) XXX_Size() int {
+	return m.Size()
+}
+func (m *HyperLogLogSample) XXX_DiscardUnknown() {
+	xxx_messageInfo_HyperLogLogSample.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HyperLogLogSample proto.InternalMessageInfo
+
+func (m *HyperLogLogSample) GetSketch() []byte {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *HyperLogLogSample) GetTimestampMs() int64 {
+	if m != nil {
+		return m.TimestampMs
+	}
+	return 0
+}
+
+func (m *HyperLogLogSample) GetMetric() []*LabelPair {
+	if m != nil {
+		return m.Metric
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*QuantileSketchMatrix)(nil), "logproto.QuantileSketchMatrix")
 	proto.RegisterType((*QuantileSketchVector)(nil), "logproto.QuantileSketchVector")
@@ -763,55 +909,61 @@ func init() {
 	proto.RegisterType((*TopK_Pair)(nil), "logproto.TopK.Pair")
 	proto.RegisterType((*TopKMatrix)(nil), "logproto.TopKMatrix")
 	proto.RegisterType((*TopKMatrix_Vector)(nil), "logproto.TopKMatrix.Vector")
+	proto.RegisterType((*HyperLogLogMatrix)(nil), "logproto.HyperLogLogMatrix")
+	proto.RegisterType((*HyperLogLogVector)(nil), "logproto.HyperLogLogVector")
+	proto.RegisterType((*HyperLogLogSample)(nil), "logproto.HyperLogLogSample")
 }
 
 func init() { proto.RegisterFile("pkg/logproto/sketch.proto", fileDescriptor_7f9fd40e59b87ff3) }
 
 var fileDescriptor_7f9fd40e59b87ff3 = []byte{
-	// 681 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x41, 0x4f, 0x13, 0x41,
-	0x18, 0xdd, 0xb1, 0xb5, 0x2d, 0x5f, 0x81, 0xe0, 0x48, 0xcc, 0x5a, 0xcc, 0xa6, 0xee, 0x41, 0x08,
-	0xc6, 0xd6, 0x40, 0x24, 0x9c, 0xc1, 0x03, 0x89, 0xa2, 0x38, 0x10, 0x0f, 0x26, 0xc6, 0x2c, 0xdb,
-	0x61, 0x3b, 0xe9, 0xee, 0xce, 0x66, 0x67, 0x0a, 0xe8, 0xc9, 0x5f, 0x60, 0x8c, 0x17, 0xff, 0x82,
-	0x57, 0x7f, 0x82, 0x37, 0x8f, 0x1c, 0x39, 0x4a, 0xb9, 0x78, 0xe4, 0x27, 0x98, 0x9d, 0x99, 0x2d,
-	0xdd, 0x25, 0x8a, 0xa7, 0xce, 0xf7, 0xe6, 0x7d, 0x6f, 0xdf, 0xcc, 0xf7, 0x3a, 0x70, 0x37, 0x19,
-	0x04, 0xdd, 0x90, 0x07, 0x49, 0xca, 0x25, 0xef, 0x8a, 0x01, 0x95, 0x7e, 0xbf, 0xa3, 0x0a, 0xdc,
-	0xc8, 0xe1, 0xd6, 0x42, 0x81, 0x94, 0x2f, 0x34, 0xcd, 0x7d, 0x01, 0xf3, 0xaf, 0x86, 0x5e, 0x2c,
-	0x59, 0x48, 0x77, 0x55, 0xfb, 0xb6, 0x27, 0x53, 0x76, 0x8c, 0xd7, 0xa0, 0x76, 0xe8, 0x85, 0x43,
-	0x2a, 0x6c, 0xd4, 0xae, 0x2c, 0x35, 0x57, 0x9c, 0xce, 0xb8, 0xb1, 0xc8, 0x7f, 0x4d, 0x7d, 0xc9,
-	0x53, 0x62, 0xd8, 0xee, 0x4e, 0x59, 0x4f, 0xef, 0xe3, 0x75, 0xa8, 0x0b, 0x2f, 0x4a, 0xc2, 0xeb,
-	0x05, 0x77, 0x15, 0x8d, 0xe4, 0x74, 0xf7, 0x13, 0x2a, 0x4b, 0x6a, 0x06, 0x7e, 0x00, 0xe8, 0xc0,
-	0x46, 0x6d, 0xb4, 0xd4, 0x5c, 0xb1, 0xff, 0x26, 0x46, 0xd0, 0x01, 0xbe, 0x0f, 0xd3, 0x92, 0x45,
-	0x54, 0x48, 0x2f, 0x4a, 0xde, 0x45, 0xc2, 0xbe, 0xd1, 0x46, 0x4b, 0x15, 0xd2, 0x1c, 0x63, 0xdb,
-	0x02, 0x3f, 0x84, 0x5a, 0x44, 0x65, 0xca, 0x7c, 0xbb, 0xa2, 0xcc, 0xdd, 0xbe, 0xd4, 0x7b, 0xee,
-	0xed, 0xd3, 0x70, 0xc7, 0x63, 0x29, 0x31, 0x14, 0x37, 0x80, 0xd9, 0xe2, 0x47, 0xf0, 0x23, 0xa8,
-	0xcb, 0x1e, 0x0b, 0xa8, 0x90, 0xc6, 0xcf, 0xad, 0xcb, 0xfe, 0xbd, 0xa7, 0x6a, 0x63, 0xcb, 0x22,
-	0x39, 0x07, 0xdf, 0x83, 0x46, 0xaf, 0xa7, 0x87, 0xa5, 0xcc, 0x4c, 0x6f, 0x59, 0x64, 0x8c, 0x6c,
-	0x34, 0xa0, 0xa6, 0x57, 0xee, 0x0f, 0x04, 0x75, 0xd3, 0x8e, 0xe7, 0xa0, 0x12, 0xb1, 0x58, 0xc9,
-	0x23, 0x92, 0x2d, 0x15, 0xe2, 0x1d, 0x2b, 0x81, 0x0c, 0xf1, 0x8e, 0x71, 0x1b, 0x9a, 0x3e, 0x8f,
-	0x92, 0x94, 0x0a, 0xc1, 0x78, 0x6c, 0x57, 0xd4, 0xce, 0x24, 0x84, 0xd7, 0x61, 0x2a, 0x49, 0xb9,
-	0x4f, 0x85, 0xa0, 0x3d, 0xbb, 0xaa, 0x8e, 0xda, 0xba, 0x62, 0xb5, 0xb3, 0x49, 0x63, 0x99, 0x72,
-	0xd6, 0x23, 0x97, 0xe4, 0xd6, 0x1a, 0x34, 0x72, 0x18, 0x63, 0xa8, 0x46, 0xd4, 0xcb, 0xcd, 0xa8,
-	0x35, 0xbe, 0x03, 0xb5, 0x23, 0xca, 0x82, 0xbe, 0x34, 0x86, 0x4c, 0xe5, 0x7e, 0x80, 0xd9, 0x4d,
-	0x3e, 0x8c, 0xe5, 0x36, 0x8b, 0xcd, 0x65, 0xcd, 0xc3, 0xcd, 0x1e, 0x4d, 0x64, 0x5f, 0xb5, 0xcf,
-	0x10, 0x5d, 0x64, 0xe8, 0x11, 0xeb, 0x49, 0x7d, 0x21, 0x33, 0x44, 0x17, 0xb8, 0x05, 0x0d, 0x3f,
-	0xeb, 0xa6, 0xa9, 0x50, 0x93, 0x41, 0x64, 0x5c, 0x67, 0xa7, 0xed, 0xbf, 0x4f, 0x68, 0x1a, 0xf2,
-	0x20, 0xe4, 0x81, 0x5d, 0xcd, 0x2e, 0x92, 0x4c, 0x42, 0xee, 0x57, 0x04, 0xf3, 0xc5, 0x8f, 0x9b,
-	0x30, 0x96, 0x13, 0x81, 0xae, 0x26, 0xe2, 0x71, 0x3e, 0x05, 0x65, 0xa8, 0x90, 0xb0, 0xa2, 0x24,
-	0x31, 0x3c, 0xbc, 0x0c, 0x75, 0x1d, 0x10, 0x61, 0x42, 0x34, 0x57, 0x0a, 0x91, 0x20, 0x39, 0xc1,
-	0x7d, 0x02, 0x35, 0x0d, 0x4d, 0x24, 0x0f, 0x5d, 0x9f, 0xbc, 0xef, 0x08, 0xaa, 0x7b, 0x3c, 0x79,
-	0x86, 0x97, 0xa1, 0xe2, 0x1b, 0xdf, 0xff, 0xb2, 0x96, 0x91, 0xf0, 0x22, 0x54, 0x43, 0x26, 0xb2,
-	0xb9, 0x94, 0xf4, 0x33, 0xa5, 0x8e, 0xd2, 0x57, 0x84, 0xf2, 0x85, 0x56, 0xae, 0x5c, 0x68, 0x6b,
-	0x05, 0xaa, 0x19, 0x3f, 0x1b, 0x16, 0x3d, 0xa4, 0xb1, 0x4e, 0xfb, 0x14, 0xd1, 0x45, 0x86, 0xaa,
-	0xe1, 0x98, 0x04, 0xe8, 0xc2, 0xfd, 0x82, 0x00, 0xb2, 0x2f, 0x99, 0x77, 0x65, 0xb5, 0xf4, 0xae,
-	0x2c, 0x14, 0xfd, 0x68, 0x56, 0xa7, 0xf8, 0xa8, 0xb4, 0x5e, 0x42, 0xcd, 0x4c, 0xce, 0x85, 0xaa,
-	0xe4, 0xc9, 0xc0, 0x9c, 0x7c, 0xb6, 0xd8, 0x4c, 0xd4, 0xde, 0x7f, 0xfc, 0xdf, 0x37, 0xde, 0x9e,
-	0x9c, 0x39, 0xd6, 0xe9, 0x99, 0x63, 0x5d, 0x9c, 0x39, 0xe8, 0xe3, 0xc8, 0x41, 0xdf, 0x46, 0x0e,
-	0xfa, 0x39, 0x72, 0xd0, 0xc9, 0xc8, 0x41, 0xbf, 0x46, 0x0e, 0xfa, 0x3d, 0x72, 0xac, 0x8b, 0x91,
-	0x83, 0x3e, 0x9f, 0x3b, 0xd6, 0xc9, 0xb9, 0x63, 0x9d, 0x9e, 0x3b, 0xd6, 0x9b, 0xc5, 0x80, 0xc9,
-	0xfe, 0x70, 0xbf, 0xe3, 0xf3, 0xa8, 0x1b, 0xa4, 0xde, 0x81, 0x17, 0x7b, 0xdd, 0x90, 0x0f, 0x58,
-	0xf7, 0x70, 0xb5, 0x3b, 0xf9, 0xc6, 0xee, 0xd7, 0xd4, 0xcf, 0xea, 0x9f, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0xab, 0x2f, 0x32, 0x74, 0x9f, 0x05, 0x00, 0x00,
+	// 731 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0xc1, 0x6e, 0xd3, 0x4c,
+	0x10, 0xf6, 0x36, 0xf9, 0x93, 0x74, 0xd2, 0x56, 0xcd, 0xfe, 0x11, 0x32, 0x29, 0xb2, 0x82, 0x0f,
+	0xb4, 0x2a, 0x22, 0x41, 0xad, 0x5a, 0xf5, 0xdc, 0x72, 0x88, 0xa0, 0x85, 0xb2, 0xad, 0x38, 0x20,
+	0x21, 0xe4, 0x3a, 0x5b, 0xc7, 0x8a, 0xed, 0xb5, 0xbc, 0x9b, 0xb6, 0xc0, 0x85, 0x27, 0x40, 0x88,
+	0x0b, 0xaf, 0xc0, 0x95, 0x47, 0xe0, 0xc6, 0xb1, 0xc7, 0x1e, 0x69, 0x7a, 0xe1, 0xd8, 0x47, 0x40,
+	0x5e, 0xaf, 0xd3, 0xd8, 0x29, 0x94, 0x03, 0xa7, 0xec, 0x7c, 0xf3, 0xcd, 0xec, 0xec, 0xcc, 0xe7,
+	0x09, 0xdc, 0x0e, 0xfb, 0x4e, 0xdb, 0x63, 0x4e, 0x18, 0x31, 0xc1, 0xda, 0xbc, 0x4f, 0x85, 0xdd,
+	0x6b, 0x49, 0x03, 0x57, 0x52, 0xb8, 0xb1, 0x90, 0x21, 0xa5, 0x87, 0x84, 0x66, 0x3e, 0x85, 0xfa,
+	0xf3, 0x81, 0x15, 0x08, 0xd7, 0xa3, 0x7b, 0x32, 0x7c, 0xc7, 0x12, 0x91, 0x7b, 0x82, 0xd7, 0xa1,
+	0x74, 0x64, 0x79, 0x03, 0xca, 0x75, 0xd4, 0x2c, 0x2c, 0x55, 0x57, 0x8c, 0xd6, 0x28, 0x30, 0xcb,
+	0x7f, 0x41, 0x6d, 0xc1, 0x22, 0xa2, 0xd8, 0xe6, 0x2e, 0xd4, 0xaf, 0xf3, 0xe3, 0x0d, 0x28, 0x73,
+	0xcb, 0x0f, 0xbd, 0x9b, 0x13, 0xee, 0x49, 0x1a, 0x49, 0xe9, 0xe6, 0x07, 0x04, 0xf5, 0xeb, 0x18,
+	0xf8, 0x1e, 0xa0, 0x43, 0x1d, 0x35, 0xd1, 0x52, 0x75, 0x45, 0xff, 0x5d, 0x32, 0x82, 0x0e, 0xf1,
+	0x5d, 0x98, 0x11, 0xae, 0x4f, 0xb9, 0xb0, 0xfc, 0xf0, 0xb5, 0xcf, 0xf5, 0xa9, 0x26, 0x5a, 0x2a,
+	0x90, 0xea, 0x08, 0xdb, 0xe1, 0xf8, 0x3e, 0x94, 0x7c, 0x2a, 0x22, 0xd7, 0xd6, 0x0b, 0xb2, 0xb8,
+	0xff, 0xaf, 0xf2, 0x6d, 0x5b, 0x07, 0xd4, 0xdb, 0xb5, 0xdc, 0x88, 0x28, 0x8a, 0xe9, 0xc0, 0x5c,
+	0xf6, 0x12, 0xfc, 0x00, 0xca, 0xa2, 0xeb, 0x3a, 0x94, 0x0b, 0x55, 0x4f, 0xed, 0x2a, 0x7e, 0xff,
+	0x91, 0x74, 0x74, 0x34, 0x92, 0x72, 0xf0, 0x1d, 0xa8, 0x74, 0xbb, 0xc9, 0xb0, 0x64, 0x31, 0x33,
+	0x1d, 0x8d, 0x8c, 0x90, 0xcd, 0x0a, 0x94, 0x92, 0x93, 0xf9, 0x0d, 0x41, 0x59, 0x85, 0xe3, 0x79,
+	0x28, 0xf8, 0x6e, 0x20, 0xd3, 0x23, 0x12, 0x1f, 0x25, 0x62, 0x9d, 0xe8, 0x53, 0x0a, 0xb1, 0x4e,
+	0x70, 0x13, 0xaa, 0x36, 0xf3, 0xc3, 0x88, 0x72, 0xee, 0xb2, 0x40, 0x2f, 0x48, 0xcf, 0x38, 0x84,
+	0x37, 0x60, 0x3a, 0x8c, 0x98, 0x4d, 0x39, 0xa7, 0x5d, 0xbd, 0x28, 0x9f, 0xda, 0x98, 0x28, 0xb5,
+	0xb5, 0x45, 0x03, 0x11, 0x31, 0xb7, 0x4b, 0xae, 0xc8, 0x8d, 0x75, 0xa8, 0xa4, 0x30, 0xc6, 0x50,
+	0xf4, 0xa9, 0x95, 0x16, 0x23, 0xcf, 0xf8, 0x16, 0x94, 0x8e, 0xa9, 0xeb, 0xf4, 0x84, 0x2a, 0x48,
+	0x59, 0xe6, 0x5b, 0x98, 0xdb, 0x62, 0x83, 0x40, 0xec, 0xb8, 0x81, 0x6a, 0x56, 0x1d, 0xfe, 0xeb,
+	0xd2, 0x50, 0xf4, 0x64, 0xf8, 0x2c, 0x49, 0x8c, 0x18, 0x3d, 0x76, 0xbb, 0x22, 0x69, 0xc8, 0x2c,
+	0x49, 0x0c, 0xdc, 0x80, 0x8a, 0x1d, 0x47, 0xd3, 0x88, 0xcb, 0xc9, 0x20, 0x32, 0xb2, 0xe3, 0xd7,
+	0xf6, 0xde, 0x84, 0x34, 0xf2, 0x98, 0xe3, 0x31, 0x47, 0x2f, 0xc6, 0x8d, 0x24, 0xe3, 0x90, 0xf9,
+	0x19, 0x41, 0x3d, 0x7b, 0xb9, 0x12, 0x63, 0x5e, 0x11, 0x68, 0x52, 0x11, 0x0f, 0xd3, 0x29, 0xe8,
+	0x53, 0x79, 0x85, 0x65, 0x53, 0x12, 0xc5, 0xc3, 0xcb, 0x50, 0x4e, 0x04, 0xc2, 0x95, 0x88, 0xe6,
+	0x73, 0x22, 0xe2, 0x24, 0x25, 0x98, 0x6b, 0x50, 0x4a, 0xa0, 0x31, 0xe5, 0xa1, 0x9b, 0x95, 0xf7,
+	0x15, 0x41, 0x71, 0x9f, 0x85, 0x4f, 0xf0, 0x32, 0x14, 0x6c, 0x55, 0xf7, 0x9f, 0x4a, 0x8b, 0x49,
+	0x78, 0x11, 0x8a, 0x9e, 0xcb, 0xe3, 0xb9, 0xe4, 0xf2, 0xc7, 0x99, 0x5a, 0x32, 0xbf, 0x24, 0xe4,
+	0x1b, 0x5a, 0x98, 0x68, 0x68, 0x63, 0x05, 0x8a, 0x31, 0x3f, 0x1e, 0x16, 0x3d, 0xa2, 0x41, 0xa2,
+	0xf6, 0x69, 0x92, 0x18, 0x31, 0x2a, 0x87, 0xa3, 0x14, 0x90, 0x18, 0xe6, 0x27, 0x04, 0x10, 0xdf,
+	0xa4, 0xf6, 0xca, 0x6a, 0x6e, 0xaf, 0x2c, 0x64, 0xeb, 0x49, 0x58, 0xad, 0xec, 0x52, 0x69, 0x3c,
+	0x83, 0x92, 0x9a, 0x9c, 0x09, 0x45, 0xc1, 0xc2, 0xbe, 0x7a, 0xf9, 0x5c, 0x36, 0x98, 0x48, 0xdf,
+	0x5f, 0x7c, 0xef, 0x66, 0x07, 0x6a, 0x9d, 0xf8, 0x5d, 0xdb, 0xcc, 0xd9, 0x66, 0xce, 0xcd, 0xa5,
+	0x8d, 0x91, 0x73, 0xfb, 0xee, 0x31, 0xd4, 0x26, 0x9c, 0x78, 0x2d, 0xbf, 0xec, 0xae, 0x4f, 0x95,
+	0xdf, 0x74, 0xef, 0xa0, 0x36, 0xe1, 0x8d, 0x3f, 0x2c, 0x25, 0x44, 0x24, 0x07, 0xa2, 0xac, 0x7f,
+	0xbd, 0xd5, 0x36, 0x5f, 0x9d, 0x9e, 0x1b, 0xda, 0xd9, 0xb9, 0xa1, 0x5d, 0x9e, 0x1b, 0xe8, 0xfd,
+	0xd0, 0x40, 0x5f, 0x86, 0x06, 0xfa, 0x3e, 0x34, 0xd0, 0xe9, 0xd0, 0x40, 0x3f, 0x86, 0x06, 0xfa,
+	0x39, 0x34, 0xb4, 0xcb, 0xa1, 0x81, 0x3e, 0x5e, 0x18, 0xda, 0xe9, 0x85, 0xa1, 0x9d, 0x5d, 0x18,
+	0xda, 0xcb, 0x45, 0xc7, 0x15, 0xbd, 0xc1, 0x41, 0xcb, 0x66, 0x7e, 0xdb, 0x89, 0xac, 0x43, 0x2b,
+	0xb0, 0xda, 0x1e, 0xeb, 0xbb, 0xed, 0xa3, 0xd5, 0xf6, 0xf8, 0xdf, 0xce, 0x41, 0x49, 0xfe, 0xac,
+	0xfe, 0x1a, 0x00, 0xdb, 0x46, 0xf9, 0x6a, 0xb2, 0x06, 0x00, 0x00,
 }
 
 func (this *QuantileSketchMatrix) Equal(that interface{}) bool {
@@ -1270,6 +1422,99 @@ func (this *TopKMatrix_Vector) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *HyperLogLogMatrix) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*HyperLogLogMatrix)
+	if !ok {
+		that2, ok := that.(HyperLogLogMatrix)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Values) != len(that1.Values) {
+		return false
+	}
+	for i := range this.Values {
+		if !this.Values[i].Equal(that1.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *HyperLogLogVector) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*HyperLogLogVector)
+	if !ok {
+		that2, ok := that.(HyperLogLogVector)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Samples) != len(that1.Samples) {
+		return false
+	}
+	for i := range this.Samples {
+		if !this.Samples[i].Equal(that1.Samples[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *HyperLogLogSample) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*HyperLogLogSample)
+	if !ok {
+		that2, ok := that.(HyperLogLogSample)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.Sketch, that1.Sketch) {
+		return false
+	}
+	if this.TimestampMs != that1.TimestampMs {
+		return false
+	}
+	if len(this.Metric) != len(that1.Metric) {
+		return false
+	}
+	for i := range this.Metric {
+		if !this.Metric[i].Equal(that1.Metric[i]) {
+			return false
+		}
+	}
+	return true
+}
 func (this *QuantileSketchMatrix) GoString() string {
 	if this == nil {
 		return "nil"
@@ -1457,6 +1702,44 @@ func (this *TopKMatrix_Vector) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *HyperLogLogMatrix) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&logproto.HyperLogLogMatrix{")
+	if this.Values != nil {
+		s = append(s, "Values: "+fmt.Sprintf("%#v", this.Values)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *HyperLogLogVector) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&logproto.HyperLogLogVector{")
+	if this.Samples != nil {
+		s = append(s, "Samples: "+fmt.Sprintf("%#v", this.Samples)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *HyperLogLogSample) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&logproto.HyperLogLogSample{")
+	s = append(s, "Sketch: "+fmt.Sprintf("%#v", this.Sketch)+",\n")
+	s = append(s, "TimestampMs: "+fmt.Sprintf("%#v", this.TimestampMs)+",\n")
+	if this.Metric != nil {
+		s = append(s, "Metric: "+fmt.Sprintf("%#v", this.Metric)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringSketch(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -2060,39 +2343,162 @@ func (m *TopKMatrix_Vector) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintSketch(dAtA []byte, offset int, v uint64) int {
-	offset -= sovSketch(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *HyperLogLogMatrix) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *QuantileSketchMatrix) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *HyperLogLogMatrix) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HyperLogLogMatrix) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
 	if len(m.Values) > 0 {
-		for _, e := range m.Values {
-			l = e.Size()
-			n += 1 + l + sovSketch(uint64(l))
+		for iNdEx := len(m.Values) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Values[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintSketch(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
 		}
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *QuantileSketchVector) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if len(m.Samples) > 0 {
+func (m *HyperLogLogVector) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HyperLogLogVector) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HyperLogLogVector) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Samples) > 0 {
+		for iNdEx := len(m.Samples) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Samples[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintSketch(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *HyperLogLogSample) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HyperLogLogSample) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HyperLogLogSample) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Metric) > 0 {
+		for iNdEx := len(m.Metric) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metric[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintSketch(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.TimestampMs != 0 {
+		i = encodeVarintSketch(dAtA, i, uint64(m.TimestampMs))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Sketch) > 0 {
+		i -= len(m.Sketch)
+		copy(dAtA[i:], m.Sketch)
+		i = encodeVarintSketch(dAtA, i, uint64(len(m.Sketch)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintSketch(dAtA []byte, offset int, v uint64) int {
+	offset -= sovSketch(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *QuantileSketchMatrix) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, e := range m.Values {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *QuantileSketchVector) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Samples) > 0 {
 		for _, e := range m.Samples {
 			l = e.Size()
 			n += 1 + l + sovSketch(uint64(l))
@@ -2327,6 +2733,58 @@ func (m *TopKMatrix_Vector) Size() (n int) {
 	return n
 }
 
+func (m *HyperLogLogMatrix) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, e := range m.Values {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *HyperLogLogVector) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Samples) > 0 {
+		for _, e := range m.Samples {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *HyperLogLogSample) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Sketch)
+	if l > 0 {
+		n += 1 + l + sovSketch(uint64(l))
+	}
+	if m.TimestampMs != 0 {
+		n += 1 + sovSketch(uint64(m.TimestampMs))
+	}
+	if len(m.Metric) > 0 {
+		for _, e := range m.Metric {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
 func sovSketch(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -2538,6 +2996,53 @@ func (this *TopKMatrix_Vector) String() string {
 	}, "")
 	return s
 }
+func (this *HyperLogLogMatrix) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForValues := "[]*HyperLogLogVector{"
+	for _, f := range this.Values {
+		repeatedStringForValues += strings.Replace(f.String(), "HyperLogLogVector", "HyperLogLogVector", 1) + ","
+	}
+	repeatedStringForValues += "}"
+	s := strings.Join([]string{`&HyperLogLogMatrix{`,
+		`Values:` + repeatedStringForValues + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *HyperLogLogVector) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForSamples := "[]*HyperLogLogSample{"
+	for _, f := range this.Samples {
+		repeatedStringForSamples += strings.Replace(f.String(), "HyperLogLogSample", "HyperLogLogSample", 1) + ","
+	}
+	repeatedStringForSamples += "}"
+	s := strings.Join([]string{`&HyperLogLogVector{`,
+		`Samples:` + repeatedStringForSamples + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *HyperLogLogSample) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMetric := "[]*LabelPair{"
+	for _, f := range this.Metric {
+		repeatedStringForMetric += strings.Replace(fmt.Sprintf("%v", f), "LabelPair", "LabelPair", 1) + ","
+	}
+	repeatedStringForMetric += "}"
+	s := strings.Join([]string{`&HyperLogLogSample{`,
+		`Sketch:` + fmt.Sprintf("%v", this.Sketch) + `,`,
+		`TimestampMs:` + fmt.Sprintf("%v", this.TimestampMs) + `,`,
+		`Metric:` + repeatedStringForMetric + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringSketch(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -4010,6 +4515,311 @@ func (m *TopKMatrix_Vector) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSketch(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HyperLogLogMatrix) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSketch
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HyperLogLogMatrix: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HyperLogLogMatrix: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, &HyperLogLogVector{})
+			if err := m.Values[len(m.Values)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSketch(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HyperLogLogVector) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSketch
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HyperLogLogVector: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HyperLogLogVector: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Samples", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Samples = append(m.Samples, &HyperLogLogSample{})
+			if err := m.Samples[len(m.Samples)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSketch(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HyperLogLogSample) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSketch
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HyperLogLogSample: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HyperLogLogSample: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sketch", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sketch = append(m.Sketch[:0], dAtA[iNdEx:postIndex]...)
+			if m.Sketch == nil {
+				m.Sketch = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TimestampMs", wireType)
+			}
+			m.TimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metric", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metric = append(m.Metric, &LabelPair{})
+			if err := m.Metric[len(m.Metric)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSketch(dAtA[iNdEx:])
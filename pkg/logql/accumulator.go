@@ -113,6 +113,76 @@ func (a *QuantileSketchAccumulator) Result() []logqlmodel.Result {
 	}
 }
 
+type HyperLogLogAccumulator struct {
+	matrix HyperLogLogMatrix
+
+	stats    stats.Result        // for accumulating statistics from downstream requests
+	headers  map[string][]string // for accumulating headers from downstream requests
+	warnings map[string]struct{} // for accumulating warnings from downstream requests
+}
+
+// newHyperLogLogAccumulator returns an accumulator for sharded
+// count_distinct_over_time queries that merges the sketches as they come in.
+func newHyperLogLogAccumulator() *HyperLogLogAccumulator {
+	return &HyperLogLogAccumulator{
+		headers:  make(map[string][]string),
+		warnings: make(map[string]struct{}),
+	}
+}
+
+func (a *HyperLogLogAccumulator) Accumulate(_ context.Context, res logqlmodel.Result, _ int) error {
+	if res.Data.Type() != HyperLogLogMatrixType {
+		return fmt.Errorf("unexpected matrix data type: got (%s), want (%s)", res.Data.Type(), HyperLogLogMatrixType)
+	}
+	data, ok := res.Data.(HyperLogLogMatrix)
+	if !ok {
+		return fmt.Errorf("unexpected matrix type: got (%T), want (HyperLogLogMatrix)", res.Data)
+	}
+
+	if res.Statistics.Summary.Shards == 0 {
+		res.Statistics.Summary.Shards = 1
+	}
+	a.stats.Merge(res.Statistics)
+	metadata.ExtendHeaders(a.headers, res.Headers)
+
+	for _, w := range res.Warnings {
+		a.warnings[w] = struct{}{}
+	}
+
+	if a.matrix == nil {
+		a.matrix = data
+		return nil
+	}
+
+	var err error
+	a.matrix, err = a.matrix.Merge(data)
+	return err
+}
+
+func (a *HyperLogLogAccumulator) Result() []logqlmodel.Result {
+	headers := make([]*definitions.PrometheusResponseHeader, 0, len(a.headers))
+	for name, vals := range a.headers {
+		headers = append(
+			headers,
+			&definitions.PrometheusResponseHeader{
+				Name:   name,
+				Values: vals,
+			},
+		)
+	}
+
+	warnings := slices.Sorted(maps.Keys(a.warnings))
+
+	return []logqlmodel.Result{
+		{
+			Data:       a.matrix,
+			Headers:    headers,
+			Warnings:   warnings,
+			Statistics: a.stats,
+		},
+	}
+}
+
 type CountMinSketchAccumulator struct {
 	vec *CountMinSketchVector
 
@@ -0,0 +1,359 @@
+package logql
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	promql_parser "github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/sketch"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+const (
+	HyperLogLogMatrixType = "HyperLogLogMatrix"
+)
+
+type (
+	HyperLogLogVector []HyperLogLogSample
+	HyperLogLogMatrix []HyperLogLogVector
+)
+
+// HyperLogLogSample is the sketch of the distinct values of a series at a
+// given step.
+type HyperLogLogSample struct {
+	T int64
+	F *sketch.HyperLogLog
+
+	Metric labels.Labels
+}
+
+func (s HyperLogLogSample) ToProto() (*logproto.HyperLogLogSample, error) {
+	metric := make([]*logproto.LabelPair, len(s.Metric))
+	for i, m := range s.Metric {
+		metric[i] = &logproto.LabelPair{Name: m.Name, Value: m.Value}
+	}
+
+	buf, err := s.F.ToProto()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logproto.HyperLogLogSample{
+		Sketch:      buf,
+		TimestampMs: s.T,
+		Metric:      metric,
+	}, nil
+}
+
+func hyperLogLogSampleFromProto(proto *logproto.HyperLogLogSample) (HyperLogLogSample, error) {
+	s, err := sketch.HyperLogLogFromProto(proto.Sketch)
+	if err != nil {
+		return HyperLogLogSample{}, err
+	}
+	out := HyperLogLogSample{
+		T:      proto.TimestampMs,
+		F:      s,
+		Metric: make(labels.Labels, len(proto.Metric)),
+	}
+
+	for i, p := range proto.Metric {
+		out.Metric[i] = labels.Label{Name: p.Name, Value: p.Value}
+	}
+
+	return out, nil
+}
+
+func (v HyperLogLogVector) Merge(right HyperLogLogVector) (HyperLogLogVector, error) {
+	// labels hash to vector index map
+	groups := streamHashPool.Get().(map[uint64]int)
+	defer func() {
+		clear(groups)
+		streamHashPool.Put(groups)
+	}()
+	for i, sample := range v {
+		groups[sample.Metric.Hash()] = i
+	}
+
+	for _, sample := range right {
+		i, ok := groups[sample.Metric.Hash()]
+		if !ok {
+			v = append(v, sample)
+			continue
+		}
+
+		if err := v[i].F.Merge(sample.F); err != nil {
+			return v, err
+		}
+	}
+
+	return v, nil
+}
+
+func (HyperLogLogVector) SampleVector() promql.Vector {
+	return promql.Vector{}
+}
+
+func (HyperLogLogVector) QuantileSketchVec() ProbabilisticQuantileVector {
+	return ProbabilisticQuantileVector{}
+}
+
+func (HyperLogLogVector) CountMinSketchVec() CountMinSketchVector {
+	return CountMinSketchVector{}
+}
+
+func (v HyperLogLogVector) HyperLogLogVec() HyperLogLogVector {
+	return v
+}
+
+func (v HyperLogLogVector) ToProto() (*logproto.HyperLogLogVector, error) {
+	samples := make([]*logproto.HyperLogLogSample, len(v))
+	for i, sample := range v {
+		s, err := sample.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		samples[i] = s
+	}
+	return &logproto.HyperLogLogVector{Samples: samples}, nil
+}
+
+func HyperLogLogVectorFromProto(proto *logproto.HyperLogLogVector) (HyperLogLogVector, error) {
+	out := make(HyperLogLogVector, len(proto.Samples))
+	for i, sample := range proto.Samples {
+		s, err := hyperLogLogSampleFromProto(sample)
+		if err != nil {
+			return HyperLogLogVector{}, err
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+func (HyperLogLogMatrix) String() string {
+	return "HyperLogLogMatrix()"
+}
+
+func (m HyperLogLogMatrix) Merge(right HyperLogLogMatrix) (HyperLogLogMatrix, error) {
+	if len(m) != len(right) {
+		return nil, fmt.Errorf("failed to merge hyperloglog matrix: lengths differ %d!=%d", len(m), len(right))
+	}
+	var err error
+	for i, vec := range m {
+		m[i], err = vec.Merge(right[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge hyperloglog matrix: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+func (HyperLogLogMatrix) Type() promql_parser.ValueType { return HyperLogLogMatrixType }
+
+func (m HyperLogLogMatrix) ToProto() (*logproto.HyperLogLogMatrix, error) {
+	values := make([]*logproto.HyperLogLogVector, len(m))
+	for i, vec := range m {
+		v, err := vec.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return &logproto.HyperLogLogMatrix{Values: values}, nil
+}
+
+func HyperLogLogMatrixFromProto(proto *logproto.HyperLogLogMatrix) (HyperLogLogMatrix, error) {
+	out := make(HyperLogLogMatrix, len(proto.Values))
+	for i, v := range proto.Values {
+		vec, err := HyperLogLogVectorFromProto(v)
+		if err != nil {
+			return HyperLogLogMatrix{}, err
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+// HyperLogLogStepEvaluator evaluates `__count_distinct_sketch_over_time__`
+// into a HyperLogLog sketch per series and step.
+type HyperLogLogStepEvaluator struct {
+	iter RangeVectorIterator
+
+	err error
+}
+
+func (e *HyperLogLogStepEvaluator) Next() (bool, int64, StepResult) {
+	next := e.iter.Next()
+	if !next {
+		return false, 0, HyperLogLogVector{}
+	}
+	ts, r := e.iter.At()
+	vec := r.HyperLogLogVec()
+	for _, s := range vec {
+		// Errors are not allowed in metrics unless they've been specifically requested.
+		if s.Metric.Has(logqlmodel.ErrorLabel) && s.Metric.Get(logqlmodel.PreserveErrorLabel) != "true" {
+			e.err = logqlmodel.NewPipelineErr(s.Metric)
+			return false, 0, HyperLogLogVector{}
+		}
+	}
+	return true, ts, vec
+}
+
+func (e *HyperLogLogStepEvaluator) Close() error { return e.iter.Close() }
+
+func (e *HyperLogLogStepEvaluator) Error() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.iter.Error()
+}
+
+func (e *HyperLogLogStepEvaluator) Explain(parent Node) {
+	parent.Child("HyperLogLog")
+}
+
+func newHyperLogLogIterator(
+	it iter.PeekingSampleIterator,
+	selRange, step, start, end, offset int64,
+) RangeVectorIterator {
+	// forces at least one step.
+	if step == 0 {
+		step = 1
+	}
+	if offset != 0 {
+		start = start - offset
+		end = end - offset
+	}
+
+	inner := &batchRangeVectorIterator{
+		iter:     it,
+		step:     step,
+		end:      end,
+		selRange: selRange,
+		metrics:  map[string]labels.Labels{},
+		window:   map[string]*promql.Series{},
+		agg:      nil,
+		current:  start - step, // first loop iteration will set it to start
+		offset:   offset,
+	}
+	return &hyperLogLogBatchRangeVectorIterator{
+		batchRangeVectorIterator: inner,
+	}
+}
+
+type hyperLogLogBatchRangeVectorIterator struct {
+	*batchRangeVectorIterator
+}
+
+func (r *hyperLogLogBatchRangeVectorIterator) At() (int64, StepResult) {
+	at := make([]HyperLogLogSample, 0, len(r.window))
+	// convert ts from nano to milli seconds as the iterator work with nanoseconds
+	ts := r.current/1e+6 + r.offset/1e+6
+	for _, series := range r.window {
+		hll := sketch.NewHyperLogLog()
+		for _, v := range series.Floats {
+			hll.Add(v.F)
+		}
+		at = append(at, HyperLogLogSample{
+			F:      hll,
+			T:      ts,
+			Metric: series.Metric,
+		})
+	}
+	return ts, HyperLogLogVector(at)
+}
+
+// JoinHyperLogLogVector joins the results from stepEvaluator into a HyperLogLogMatrix.
+func JoinHyperLogLogVector(next bool, r StepResult, stepEvaluator StepEvaluator, params Params) (promql_parser.Value, error) {
+	vec := r.HyperLogLogVec()
+	if stepEvaluator.Error() != nil {
+		return nil, stepEvaluator.Error()
+	}
+
+	if GetRangeType(params) == InstantType {
+		return HyperLogLogMatrix{vec}, nil
+	}
+
+	stepCount := int(math.Ceil(float64(params.End().Sub(params.Start()).Nanoseconds()) / float64(params.Step().Nanoseconds())))
+	if stepCount <= 0 {
+		stepCount = 1
+	}
+
+	result := make(HyperLogLogMatrix, 0, stepCount)
+
+	for next {
+		result = append(result, vec)
+		next, _, r = stepEvaluator.Next()
+		vec = r.HyperLogLogVec()
+		if stepEvaluator.Error() != nil {
+			return nil, stepEvaluator.Error()
+		}
+	}
+
+	return result, stepEvaluator.Error()
+}
+
+// HyperLogLogMatrixStepEvaluator steps through a matrix of HyperLogLog
+// sketches and evaluates them into the estimated number of distinct values.
+type HyperLogLogMatrixStepEvaluator struct {
+	end, ts time.Time
+	step    time.Duration
+	m       HyperLogLogMatrix
+}
+
+var _ StepEvaluator = &HyperLogLogMatrixStepEvaluator{}
+
+func NewHyperLogLogMatrixStepEvaluator(m HyperLogLogMatrix, params Params) *HyperLogLogMatrixStepEvaluator {
+	var (
+		step = params.Step()
+	)
+	return &HyperLogLogMatrixStepEvaluator{
+		end:  params.End(),
+		ts:   params.Start().Add(-step), // will be corrected on first Next() call
+		step: step,
+		m:    m,
+	}
+}
+
+func (e *HyperLogLogMatrixStepEvaluator) Next() (bool, int64, StepResult) {
+	e.ts = e.ts.Add(e.step)
+	if e.ts.After(e.end) {
+		return false, 0, nil
+	}
+
+	ts := e.ts.UnixNano() / int64(time.Millisecond)
+
+	if len(e.m) == 0 {
+		return false, 0, nil
+	}
+
+	hllVec := e.m[0]
+
+	// Reset for next step
+	e.m = e.m[1:]
+
+	vec := make(promql.Vector, len(hllVec))
+	for i, s := range hllVec {
+		vec[i] = promql.Sample{
+			T:      s.T,
+			F:      float64(s.F.Estimate()),
+			Metric: s.Metric,
+		}
+	}
+
+	return true, ts, SampleVector(vec)
+}
+
+func (*HyperLogLogMatrixStepEvaluator) Close() error { return nil }
+
+func (*HyperLogLogMatrixStepEvaluator) Error() error { return nil }
+
+func (*HyperLogLogMatrixStepEvaluator) Explain(parent Node) {
+	parent.Child("HyperLogLogMatrix")
+}
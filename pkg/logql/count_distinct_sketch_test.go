@@ -0,0 +1,94 @@
+package logql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/sketch"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+func TestHyperLogLogMatrixSerialization(t *testing.T) {
+	hll := sketch.NewHyperLogLog()
+	hll.Add(42)
+	hllBytes, err := hll.ToProto()
+	require.NoError(t, err)
+
+	matrix := HyperLogLogMatrix([]HyperLogLogVector{
+		[]HyperLogLogSample{
+			{T: 0, F: hll, Metric: []labels.Label{{Name: "foo", Value: "bar"}}},
+		},
+	})
+
+	proto := &logproto.HyperLogLogMatrix{
+		Values: []*logproto.HyperLogLogVector{
+			{
+				Samples: []*logproto.HyperLogLogSample{
+					{
+						TimestampMs: 0,
+						Sketch:      hllBytes,
+						Metric:      []*logproto.LabelPair{{Name: "foo", Value: "bar"}},
+					},
+				},
+			},
+		},
+	}
+
+	actual, err := matrix.ToProto()
+	require.NoError(t, err)
+	require.Equal(t, proto, actual)
+
+	m, err := HyperLogLogMatrixFromProto(actual)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), m[0][0].F.Estimate())
+}
+
+func TestHyperLogLogVectorMerge(t *testing.T) {
+	newSample := func(lbs labels.Labels, values ...float64) HyperLogLogSample {
+		hll := sketch.NewHyperLogLog()
+		for _, v := range values {
+			hll.Add(v)
+		}
+		return HyperLogLogSample{T: 1, F: hll, Metric: lbs}
+	}
+	foo := labels.FromStrings("app", "foo")
+	bar := labels.FromStrings("app", "bar")
+
+	left := HyperLogLogVector{newSample(foo, 1, 2, 3)}
+	right := HyperLogLogVector{newSample(foo, 3, 4), newSample(bar, 1)}
+
+	merged, err := left.Merge(right)
+	require.NoError(t, err)
+	require.Len(t, merged, 2)
+	require.Equal(t, uint64(4), merged[0].F.Estimate())
+	require.Equal(t, uint64(1), merged[1].F.Estimate())
+}
+
+func TestHyperLogLogStepEvaluatorError(t *testing.T) {
+	iter := errorRangeVectorIterator{
+		result: HyperLogLogVector([]HyperLogLogSample{
+			{T: 43, F: nil, Metric: labels.Labels{{Name: logqlmodel.ErrorLabel, Value: "my error"}}},
+		}),
+	}
+	ev := HyperLogLogStepEvaluator{
+		iter: iter,
+	}
+	ok, _, _ := ev.Next()
+	require.False(t, ok)
+
+	err := ev.Error()
+	require.ErrorContains(t, err, "my error")
+}
+
+func TestJoinHyperLogLogVectorError(t *testing.T) {
+	result := HyperLogLogVector{}
+	ev := errorStepEvaluator{
+		err: errors.New("could not evaluate"),
+	}
+	_, err := JoinHyperLogLogVector(true, result, ev, LiteralParams{})
+	require.ErrorContains(t, err, "could not evaluate")
+}
@@ -429,6 +429,76 @@ func (e *CountMinSketchEvalExpr) Walk(f syntax.WalkFn) {
 	}
 }
 
+// HyperLogLogEvalExpr merges the HyperLogLog sketches of its downstreams
+// and evaluates them into the estimated number of distinct values.
+type HyperLogLogEvalExpr struct {
+	syntax.SampleExpr
+	downstreams []DownstreamSampleExpr
+}
+
+func (e HyperLogLogEvalExpr) String() string {
+	var sb strings.Builder
+	for i, d := range e.downstreams {
+		if i >= defaultMaxDepth {
+			break
+		}
+
+		if i > 0 {
+			sb.WriteString(" ++ ")
+		}
+
+		sb.WriteString(d.String())
+	}
+	return fmt.Sprintf("HyperLogLogEval<%s>", sb.String())
+}
+
+func (e *HyperLogLogEvalExpr) Walk(f syntax.WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.SampleExpr != nil {
+		e.SampleExpr.Walk(f)
+	}
+	for _, d := range e.downstreams {
+		d.Walk(f)
+	}
+}
+
+// HistogramMergeExpr sums the buckets of its downstreams and makes them
+// cumulative, see `HistogramStepEvaluator`.
+type HistogramMergeExpr struct {
+	syntax.SampleExpr
+	downstreams []DownstreamSampleExpr
+}
+
+func (e HistogramMergeExpr) String() string {
+	var sb strings.Builder
+	for i, d := range e.downstreams {
+		if i >= defaultMaxDepth {
+			break
+		}
+
+		if i > 0 {
+			sb.WriteString(" ++ ")
+		}
+
+		sb.WriteString(d.String())
+	}
+	return fmt.Sprintf("HistogramMerge<%s>", sb.String())
+}
+
+func (e *HistogramMergeExpr) Walk(f syntax.WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.SampleExpr != nil {
+		e.SampleExpr.Walk(f)
+	}
+	for _, d := range e.downstreams {
+		d.Walk(f)
+	}
+}
+
 type Downstreamable interface {
 	Downstreamer(context.Context) Downstreamer
 }
@@ -704,6 +774,66 @@ func (ev *DownstreamEvaluator) NewStepEvaluator(
 			return nil, fmt.Errorf("unexpected matrix type: got (%T), want (CountMinSketchVector)", results[0].Data)
 		}
 		return NewCountMinSketchVectorStepEvaluator(vector), nil
+	case *HyperLogLogEvalExpr:
+		queries := make([]DownstreamQuery, len(e.downstreams))
+
+		for i, d := range e.downstreams {
+			queries[i] = DownstreamQuery{
+				Params: ParamsWithExpressionOverride{
+					Params:             ParamOverridesFromShard(params, d.shard),
+					ExpressionOverride: d.SampleExpr,
+				},
+			}
+		}
+
+		acc := newHyperLogLogAccumulator()
+		results, err := ev.Downstream(ctx, queries, acc)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(results) != 1 {
+			return nil, fmt.Errorf("unexpected results length for sharded count distinct: got (%d), want (1)", len(results))
+		}
+
+		matrix, ok := results[0].Data.(HyperLogLogMatrix)
+		if !ok {
+			return nil, fmt.Errorf("unexpected matrix type: got (%T), want (HyperLogLogMatrix)", results[0].Data)
+		}
+		return NewHyperLogLogMatrixStepEvaluator(matrix, params), nil
+	case *HistogramMergeExpr:
+		queries := make([]DownstreamQuery, len(e.downstreams))
+
+		for i, d := range e.downstreams {
+			queries[i] = DownstreamQuery{
+				Params: ParamsWithExpressionOverride{
+					Params:             ParamOverridesFromShard(params, d.shard),
+					ExpressionOverride: d.SampleExpr,
+				},
+			}
+		}
+
+		acc := NewBufferedAccumulator(len(queries))
+		results, err := ev.Downstream(ctx, queries, acc)
+		if err != nil {
+			return nil, err
+		}
+
+		xs := make([]StepEvaluator, 0, len(queries))
+		for i, res := range results {
+			stepper, err := NewResultStepEvaluator(res, params)
+			if err != nil {
+				level.Warn(util_log.Logger).Log(
+					"msg", "could not extract StepEvaluator",
+					"err", err,
+					"expr", queries[i].Params.GetExpression().String(),
+				)
+				return nil, err
+			}
+			xs = append(xs, stepper)
+		}
+
+		return newHistogramStepEvaluator(NewConcatStepEvaluator(xs)), nil
 	default:
 		return ev.defaultEvaluator.NewStepEvaluator(ctx, nextEvFactory, e, params)
 	}
@@ -74,6 +74,9 @@ func TestMappingEquivalence(t *testing.T) {
 			false,
 			nil,
 		},
+		{`sum(sqrt(rate({a=~".+"}[1s])))`, false, nil},
+		{`clamp_max(sum by (a) (rate({a=~".+"}[1s])), 0.5) - time()`, false, nil},
+		{`label_join(sum by (a, b) (rate({a=~".+"}[1s])), "c", "-", "a", "b")`, false, nil},
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s])`, false, []string{ShardFirstOverTime}},
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, []string{ShardFirstOverTime}},
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s] offset 2s) by (a)`, false, []string{ShardFirstOverTime}},
@@ -82,6 +85,12 @@ func TestMappingEquivalence(t *testing.T) {
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, []string{ShardLastOverTime}},
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s] offset 2s) by (a)`, false, []string{ShardLastOverTime}},
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s] offset -2s) by (a)`, false, []string{ShardLastOverTime}},
+		{`histogram_over_time({a=~".+"} | logfmt | unwrap value [1s])`, false, nil},
+		{`histogram_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, nil},
+		{`histogram_over_time({a=~".+"} | logfmt | unwrap value [1s] offset 2s) without (a)`, false, nil},
+		{`sum by (le) (histogram_over_time({a=~".+"} | logfmt | unwrap value [1s]))`, false, nil},
+		{`histogram_quantile(0.9, sum by (le) (histogram_over_time({a=~".+"} | logfmt | unwrap value [1s])))`, false, nil},
+		{`histogram_quantile(0.9, histogram_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a))`, false, nil},
 		// topk prefers already-seen values in tiebreakers. Since the test data generates
 		// the same log lines for each series & the resulting promql.Vectors aren't deterministically
 		// sorted by labels, we don't expect this to pass.
@@ -196,6 +205,8 @@ func TestMappingEquivalenceSketches(t *testing.T) {
 		{`quantile_over_time(0.70, {a=~".+"} | logfmt | unwrap value [1s]) by (a)`, 0.05},
 		{`quantile_over_time(0.99, {a=~".+"} | logfmt | unwrap value [1s]) by (a)`, 0.02},
 		{`quantile_over_time(0.99, {a=~".+"} | logfmt | unwrap value [1s] offset 2s) by (a)`, 0.02},
+		{`count_distinct_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, 0.02},
+		{`count_distinct_over_time({a=~".+"} | logfmt | unwrap value [3s] offset 2s) by (a)`, 0.02},
 	} {
 		q := NewMockQuerier(
 			shards,
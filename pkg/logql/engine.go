@@ -159,12 +159,24 @@ type EngineOpts struct {
 
 	// Enable the next generation Loki Query Engine for supported queries.
 	EnableV2Engine bool `yaml:"enable_v2_engine" category:"experimental"`
+
+	// Split queries of the next generation Loki Query Engine into fragments that are executed by queriers.
+	EnableV2EngineDistributedExecution bool `yaml:"enable_v2_engine_distributed_execution" category:"experimental"`
+
+	// Execute a sample of the queries supported by the next generation Loki Query Engine on both engines and compare their results.
+	EnableV2EngineShadowMode bool `yaml:"enable_v2_engine_shadow_mode" category:"experimental"`
+
+	// The maximum number of queries executed in the background in shadow mode at the same time.
+	V2EngineShadowMaxConcurrency int `yaml:"v2_engine_shadow_max_concurrency" category:"experimental"`
 }
 
 func (opts *EngineOpts) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.DurationVar(&opts.MaxLookBackPeriod, prefix+"max-lookback-period", 30*time.Second, "The maximum amount of time to look back for log lines. Used only for instant log queries.")
 	f.IntVar(&opts.MaxCountMinSketchHeapSize, prefix+"max-count-min-sketch-heap-size", 10_000, "The maximum number of labels the heap of a topk query using a count min sketch can track.")
 	f.BoolVar(&opts.EnableV2Engine, prefix+"enable-v2-engine", false, "Experimental: Enable next generation query engine for supported queries.")
+	f.BoolVar(&opts.EnableV2EngineDistributedExecution, prefix+"enable-v2-engine-distributed-execution", false, "Experimental: Execute queries of the next generation query engine in the query-frontend by splitting them into fragments that are scheduled to queriers. Requires the v2 query-frontend and enable_v2_engine.")
+	f.BoolVar(&opts.EnableV2EngineShadowMode, prefix+"enable-v2-engine-shadow-mode", false, "Experimental: Execute a sample of the queries supported by the next generation query engine on both query engines and compare their results. Mismatches are logged and counted in metrics. The query is executed on the engine that does not serve the request in the background, after the response has been returned. The results of the next generation query engine are only returned if enable_v2_engine is set. The sample rate is configured per tenant with v2_engine_shadow_sample_rate.")
+	f.IntVar(&opts.V2EngineShadowMaxConcurrency, prefix+"v2-engine-shadow-max-concurrency", 4, "Experimental: Maximum number of queries executed in the background in shadow mode at the same time. Sampled queries exceeding it are not compared. Shadow queries time out after the query timeout of the tenant.")
 	// Log executing query by default
 	opts.LogExecutingQuery = true
 }
@@ -234,6 +246,8 @@ func (q *query) resultLength(res promql_parser.Value) int {
 		return int(r.Lines())
 	case ProbabilisticQuantileMatrix:
 		return len(r)
+	case HyperLogLogMatrix:
+		return len(r)
 	default:
 		// for `scalar` or `string` or any other return type, we just return `0` as result length.
 		return 0
@@ -419,6 +433,8 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 			return q.JoinSampleVector(next, vec, stepEvaluator, maxSeries, mfl)
 		case ProbabilisticQuantileVector:
 			return JoinQuantileSketchVector(next, vec, stepEvaluator, q.params)
+		case HyperLogLogVector:
+			return JoinHyperLogLogVector(next, vec, stepEvaluator, q.params)
 		case CountMinSketchVector:
 			return JoinCountMinSketchVector(next, vec, stepEvaluator, q.params)
 		case HeapCountMinSketchVector:
@@ -641,6 +657,12 @@ func filterVariantVector(vec *promql.Vector, skipped map[string]struct{}) {
 }
 
 func (q *query) checkIntervalLimit(expr syntax.SampleExpr, limit time.Duration) error {
+	return CheckIntervalLimit(expr, limit)
+}
+
+// CheckIntervalLimit returns an error if a range interval or subquery range
+// of the expression exceeds the limit.
+func CheckIntervalLimit(expr syntax.SampleExpr, limit time.Duration) error {
 	var err error
 	expr.Walk(func(e syntax.Expr) bool {
 		switch e := e.(type) {
@@ -648,6 +670,10 @@ func (q *query) checkIntervalLimit(expr syntax.SampleExpr, limit time.Duration)
 			if e.Interval > limit {
 				err = fmt.Errorf("%w: [%s] > [%s]", logqlmodel.ErrIntervalLimit, model.Duration(e.Interval), model.Duration(limit))
 			}
+		case *syntax.SubqueryExpr:
+			if e.Range > limit {
+				err = fmt.Errorf("%w: [%s] > [%s]", logqlmodel.ErrIntervalLimit, model.Duration(e.Range), model.Duration(limit))
+			}
 		}
 		return true
 	})
@@ -54,6 +54,8 @@ func TestEngine_checkIntervalLimit(t *testing.T) {
 		{query: `sum_over_time({app="foo"} |= "foo" | json | unwrap bar [1h])`, expErr: "[1h] > [10m]"},
 		{query: `variants(rate({app="foo"}[5m])) of ({app="foo"}[5m])`, expErr: ""},
 		{query: `variants(rate({app="foo"}[1h])) of ({app="foo"}[1h])`, expErr: "[1h] > [10m]"},
+		{query: `max_over_time(rate({app="foo"}[5m])[10m:1m])`, expErr: ""},
+		{query: `max_over_time(rate({app="foo"}[5m])[1h:1m])`, expErr: "[1h] > [10m]"},
 	} {
 		for _, downstream := range []bool{true, false} {
 			t.Run(fmt.Sprintf("%v/downstream=%v", tc.query, downstream), func(t *testing.T) {
@@ -1935,6 +1937,181 @@ func TestEngine_RangeQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			`clamp_max(sum by (app) (count_over_time({app=~"foo|bar"} |~".+bar" [1m])), 10)`,
+			time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(5, identity), `{app="foo"}`),
+					newSeries(testSize, factor(10, identity), `{app="bar"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(120, 0), Selector: `sum by (app) (count_over_time({app=~"foo|bar"} |~".+bar" [1m]))`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "bar"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 6}, {T: 90 * 1000, F: 6}, {T: 120 * 1000, F: 6}},
+				},
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 10}, {T: 90 * 1000, F: 10}, {T: 120 * 1000, F: 10}},
+				},
+			},
+		},
+		{
+			`label_join(time() - timestamp(sum by (app) (count_over_time({app="foo"}[1m]))), "dst", "-", "app", "app")`,
+			time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(5, identity), `{app="foo"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(120, 0), Selector: `sum by (app) (count_over_time({app="foo"}[1m]))`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo", "dst", "foo-foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 0}, {T: 90 * 1000, F: 0}, {T: 120 * 1000, F: 0}},
+				},
+			},
+		},
+		{
+			`increase({app="foo"} | unwrap bytes [1m])`,
+			time.Unix(60, 0), time.Unix(120, 0), time.Minute, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(10, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(20, 0).UnixNano(), Hash: 2, Value: 2.},
+							{Timestamp: time.Unix(30, 0).UnixNano(), Hash: 3, Value: 3.},
+							{Timestamp: time.Unix(40, 0).UnixNano(), Hash: 4, Value: 4.},
+							{Timestamp: time.Unix(50, 0).UnixNano(), Hash: 5, Value: 5.},
+							{Timestamp: time.Unix(60, 0).UnixNano(), Hash: 6, Value: 6.},
+							{Timestamp: time.Unix(70, 0).UnixNano(), Hash: 7, Value: 7.},
+							{Timestamp: time.Unix(80, 0).UnixNano(), Hash: 8, Value: 8.},
+							{Timestamp: time.Unix(90, 0).UnixNano(), Hash: 9, Value: 9.},
+							{Timestamp: time.Unix(100, 0).UnixNano(), Hash: 10, Value: 10.},
+							{Timestamp: time.Unix(110, 0).UnixNano(), Hash: 11, Value: 11.},
+							{Timestamp: time.Unix(120, 0).UnixNano(), Hash: 12, Value: 12.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(120, 0), Selector: `increase({app="foo"} | unwrap bytes [1m])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 6}, {T: 120 * 1000, F: 6}},
+				},
+			},
+		},
+		{
+			`histogram_over_time({app="foo"} | unwrap bytes [1m])`,
+			time.Unix(60, 0), time.Unix(120, 0), time.Minute, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(10, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(20, 0).UnixNano(), Hash: 2, Value: 2.},
+							{Timestamp: time.Unix(30, 0).UnixNano(), Hash: 3, Value: 3.},
+							{Timestamp: time.Unix(40, 0).UnixNano(), Hash: 4, Value: 4.},
+							{Timestamp: time.Unix(50, 0).UnixNano(), Hash: 5, Value: 5.},
+							{Timestamp: time.Unix(60, 0).UnixNano(), Hash: 6, Value: 6.},
+							{Timestamp: time.Unix(70, 0).UnixNano(), Hash: 7, Value: 7.},
+							{Timestamp: time.Unix(80, 0).UnixNano(), Hash: 8, Value: 8.},
+							{Timestamp: time.Unix(90, 0).UnixNano(), Hash: 9, Value: 9.},
+							{Timestamp: time.Unix(100, 0).UnixNano(), Hash: 10, Value: 10.},
+							{Timestamp: time.Unix(110, 0).UnixNano(), Hash: 11, Value: 11.},
+							{Timestamp: time.Unix(120, 0).UnixNano(), Hash: 12, Value: 12.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(120, 0), Selector: `histogram_over_time({app="foo"} | unwrap bytes [1m])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo", "le", "+Inf"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 6}, {T: 120 * 1000, F: 6}},
+				},
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo", "le", "1"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 1}, {T: 120 * 1000, F: 0}},
+				},
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo", "le", "16"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 6}, {T: 120 * 1000, F: 6}},
+				},
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo", "le", "2"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 2}, {T: 120 * 1000, F: 0}},
+				},
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo", "le", "4"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 4}, {T: 120 * 1000, F: 0}},
+				},
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo", "le", "8"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 6}, {T: 120 * 1000, F: 2}},
+				},
+			},
+		},
+		{
+			`histogram_quantile(0.5, histogram_over_time({app="foo"} | unwrap bytes [1m]))`,
+			time.Unix(60, 0), time.Unix(120, 0), time.Minute, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(10, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(20, 0).UnixNano(), Hash: 2, Value: 2.},
+							{Timestamp: time.Unix(30, 0).UnixNano(), Hash: 3, Value: 3.},
+							{Timestamp: time.Unix(40, 0).UnixNano(), Hash: 4, Value: 4.},
+							{Timestamp: time.Unix(50, 0).UnixNano(), Hash: 5, Value: 5.},
+							{Timestamp: time.Unix(60, 0).UnixNano(), Hash: 6, Value: 6.},
+							{Timestamp: time.Unix(70, 0).UnixNano(), Hash: 7, Value: 7.},
+							{Timestamp: time.Unix(80, 0).UnixNano(), Hash: 8, Value: 8.},
+							{Timestamp: time.Unix(90, 0).UnixNano(), Hash: 9, Value: 9.},
+							{Timestamp: time.Unix(100, 0).UnixNano(), Hash: 10, Value: 10.},
+							{Timestamp: time.Unix(110, 0).UnixNano(), Hash: 11, Value: 11.},
+							{Timestamp: time.Unix(120, 0).UnixNano(), Hash: 12, Value: 12.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(120, 0), Selector: `histogram_over_time({app="foo"} | unwrap bytes [1m])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 3}, {T: 120 * 1000, F: 10}},
+				},
+			},
+		},
+		{
+			`time()`,
+			time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{},
+			[]SelectSampleParams{},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.EmptyLabels(),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 60}, {T: 90 * 1000, F: 90}, {T: 120 * 1000, F: 120}},
+				},
+			},
+		},
 		{
 			` sum (
 					sum by (app) (rate({app=~"foo|bar"} |~".+bar" [1m])) +
@@ -2294,6 +2471,67 @@ func TestEngine_RangeQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			// inner steps are aligned to the subquery step: 40s, 50s, 60s, ...
+			`max_over_time(sum(count_over_time({app="foo"}[10s]))[30s:10s])`, time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(35, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(45, 0).UnixNano(), Hash: 2, Value: 1.},
+							{Timestamp: time.Unix(46, 0).UnixNano(), Hash: 3, Value: 1.},
+							{Timestamp: time.Unix(55, 0).UnixNano(), Hash: 4, Value: 1.},
+							{Timestamp: time.Unix(56, 0).UnixNano(), Hash: 5, Value: 1.},
+							{Timestamp: time.Unix(57, 0).UnixNano(), Hash: 6, Value: 1.},
+							{Timestamp: time.Unix(95, 0).UnixNano(), Hash: 7, Value: 1.},
+							{Timestamp: time.Unix(105, 0).UnixNano(), Hash: 8, Value: 1.},
+							{Timestamp: time.Unix(106, 0).UnixNano(), Hash: 9, Value: 1.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(20, 0), End: time.Unix(120, 0), Selector: `sum(count_over_time({app="foo"}[10s]))`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.EmptyLabels(),
+					Floats: []promql.FPoint{{T: 60000, F: 3}, {T: 120000, F: 2}},
+				},
+			},
+		},
+		{
+			`avg_over_time(count_over_time({app="foo"}[10s])[30s:10s])`, time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{
+					{
+						Labels: `{app="foo"}`,
+						Samples: []logproto.Sample{
+							{Timestamp: time.Unix(35, 0).UnixNano(), Hash: 1, Value: 1.},
+							{Timestamp: time.Unix(45, 0).UnixNano(), Hash: 2, Value: 1.},
+							{Timestamp: time.Unix(46, 0).UnixNano(), Hash: 3, Value: 1.},
+							{Timestamp: time.Unix(55, 0).UnixNano(), Hash: 4, Value: 1.},
+							{Timestamp: time.Unix(56, 0).UnixNano(), Hash: 5, Value: 1.},
+							{Timestamp: time.Unix(57, 0).UnixNano(), Hash: 6, Value: 1.},
+							{Timestamp: time.Unix(95, 0).UnixNano(), Hash: 7, Value: 1.},
+							{Timestamp: time.Unix(105, 0).UnixNano(), Hash: 8, Value: 1.},
+							{Timestamp: time.Unix(106, 0).UnixNano(), Hash: 9, Value: 1.},
+						},
+					},
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(20, 0), End: time.Unix(120, 0), Selector: `count_over_time({app="foo"}[10s])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60000, F: 2}, {T: 120000, F: 1.5}},
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
 			t.Parallel()
@@ -2745,6 +2983,10 @@ func (s *storeSampleResult) CountMinSketchVec() CountMinSketchVector {
 	return CountMinSketchVector{}
 }
 
+func (s *storeSampleResult) HyperLogLogVec() HyperLogLogVector {
+	return HyperLogLogVector{}
+}
+
 func TestEngine_Variants_RangeQuery(t *testing.T) {
 	t.Parallel()
 
@@ -7,6 +7,7 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -379,6 +380,8 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 			return nil, err
 		}
 		return newRangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, e.Left.Offset)
+	case *syntax.SubqueryAggregationExpr:
+		return newSubqueryAggEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.BinOpExpr:
 		return newBinOpStepEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.LabelReplaceExpr:
@@ -389,6 +392,15 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 			return nil, err
 		}
 		return newVectorIterator(val, q.Step().Milliseconds(), q.Start().UnixMilli(), q.End().UnixMilli()), nil
+	case *syntax.TimeExpr:
+		return newTimeIterator(q.Step().Milliseconds(), q.Start().UnixMilli(), q.End().UnixMilli()), nil
+	case *syntax.FunctionExpr:
+		if e.Operation == syntax.OpFuncHistogramQuantile {
+			return newHistogramQuantileEvaluator(ctx, nextEvFactory, e, q)
+		}
+		return newFunctionEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.LabelJoinExpr:
+		return newLabelJoinEvaluator(ctx, nextEvFactory, e, q)
 	default:
 		return nil, EvaluatorUnsupportedType(e, ev)
 	}
@@ -670,6 +682,17 @@ func newRangeAggEvaluator(
 		return &QuantileSketchStepEvaluator{
 			iter: iter,
 		}, nil
+	case syntax.OpRangeTypeCountDistinctSketch:
+		iter := newHyperLogLogIterator(
+			it,
+			expr.Left.Interval.Nanoseconds(),
+			q.Step().Nanoseconds(),
+			q.Start().UnixNano(), q.End().UnixNano(), o.Nanoseconds(),
+		)
+
+		return &HyperLogLogStepEvaluator{
+			iter: iter,
+		}, nil
 	case syntax.OpRangeTypeFirstWithTimestamp:
 		iter := newFirstWithTimestampIterator(
 			it,
@@ -692,6 +715,24 @@ func newRangeAggEvaluator(
 		return &RangeVectorEvaluator{
 			iter: iter,
 		}, nil
+	case syntax.OpRangeTypeHistogram, syntax.OpRangeTypeHistogramBuckets:
+		iter := newHistogramIterator(
+			it,
+			expr.Left.Interval.Nanoseconds(),
+			q.Step().Nanoseconds(),
+			q.Start().UnixNano(), q.End().UnixNano(), o.Nanoseconds(),
+		)
+
+		// The buckets of shards are only made cumulative after they have
+		// been merged, see `HistogramMergeExpr`.
+		if expr.Operation == syntax.OpRangeTypeHistogramBuckets {
+			return &RangeVectorEvaluator{
+				iter: iter,
+			}, nil
+		}
+		return newHistogramStepEvaluator(&RangeVectorEvaluator{
+			iter: iter,
+		}), nil
 	default:
 		iter, err := newRangeVectorIterator(
 			it, expr,
@@ -790,9 +831,8 @@ func newBinOpStepEvaluator(
 	expr *syntax.BinOpExpr,
 	q Params,
 ) (StepEvaluator, error) {
-	// first check if either side is a literal
-	leftLit, lOk := expr.SampleExpr.(*syntax.LiteralExpr)
-	rightLit, rOk := expr.RHS.(*syntax.LiteralExpr)
+	// first check if either side is a literal or time()
+	lOk, rOk := isScalar(expr.SampleExpr), isScalar(expr.RHS)
 
 	// match a literal expr with all labels in the other leg
 	if lOk {
@@ -802,7 +842,7 @@ func newBinOpStepEvaluator(
 		}
 		return newLiteralStepEvaluator(
 			expr.Op,
-			leftLit,
+			expr.SampleExpr,
 			rhs,
 			false,
 			expr.Opts.ReturnBool,
@@ -815,7 +855,7 @@ func newBinOpStepEvaluator(
 		}
 		return newLiteralStepEvaluator(
 			expr.Op,
-			rightLit,
+			expr.RHS,
 			lhs,
 			true,
 			expr.Opts.ReturnBool,
@@ -1122,33 +1162,54 @@ func resultMetric(lhs, rhs labels.Labels, opts *syntax.BinOpOptions) labels.Labe
 	return lb.Labels()
 }
 
-// newLiteralStepEvaluator merges a literal with a StepEvaluator. Since order matters in
+// isScalar returns true for the expressions merged with all labels of the
+// other leg of a binary operation.
+func isScalar(e syntax.SampleExpr) bool {
+	switch e.(type) {
+	case *syntax.LiteralExpr, *syntax.TimeExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+// newLiteralStepEvaluator merges a literal or time() with a StepEvaluator. Since order matters in
 // non-commutative operations, inverted should be true when the literalExpr is not the left argument.
 func newLiteralStepEvaluator(
 	op string,
-	lit *syntax.LiteralExpr,
+	lit syntax.SampleExpr,
 	nextEv StepEvaluator,
 	inverted bool,
 	returnBool bool,
 ) (*LiteralStepEvaluator, error) {
-	val, err := lit.Value()
-	if err != nil {
-		return nil, err
-	}
-
-	return &LiteralStepEvaluator{
+	ev := &LiteralStepEvaluator{
 		nextEv:     nextEv,
-		val:        val,
 		inverted:   inverted,
 		op:         op,
 		returnBool: returnBool,
-	}, nil
+	}
+
+	switch e := lit.(type) {
+	case *syntax.LiteralExpr:
+		val, err := e.Value()
+		if err != nil {
+			return nil, err
+		}
+		ev.val = val
+	case *syntax.TimeExpr:
+		ev.time = true
+	default:
+		return nil, fmt.Errorf("unexpected scalar expression %T", lit)
+	}
+
+	return ev, nil
 }
 
 type LiteralStepEvaluator struct {
 	nextEv     StepEvaluator
 	mergeErr   error
 	val        float64
+	time       bool // use the step timestamp in seconds as value
 	inverted   bool
 	op         string
 	returnBool bool
@@ -1159,6 +1220,10 @@ func (e *LiteralStepEvaluator) Next() (bool, int64, StepResult) {
 	if !ok {
 		return ok, ts, r
 	}
+	val := e.val
+	if e.time {
+		val = float64(ts) / 1000
+	}
 	vec := r.SampleVector()
 	results := make(promql.Vector, 0, len(vec))
 	for _, sample := range vec {
@@ -1166,7 +1231,7 @@ func (e *LiteralStepEvaluator) Next() (bool, int64, StepResult) {
 		literalPoint := promql.Sample{
 			Metric: sample.Metric,
 			T:      ts,
-			F:      e.val,
+			F:      val,
 		}
 
 		left, right := &literalPoint, &sample
@@ -1243,6 +1308,41 @@ func (r *VectorIterator) Error() error {
 	return nil
 }
 
+// TimeIterator returns the timestamp of each step in seconds, like time().
+type TimeIterator struct {
+	stepMs, endMs, currentMs int64
+}
+
+func newTimeIterator(stepMs, startMs, endMs int64) *TimeIterator {
+	if stepMs == 0 {
+		stepMs = 1
+	}
+	return &TimeIterator{
+		stepMs:    stepMs,
+		endMs:     endMs,
+		currentMs: startMs - stepMs,
+	}
+}
+
+func (r *TimeIterator) Next() (bool, int64, StepResult) {
+	r.currentMs = r.currentMs + r.stepMs
+	if r.currentMs > r.endMs {
+		return false, 0, nil
+	}
+	results := promql.Vector{
+		promql.Sample{T: r.currentMs, F: float64(r.currentMs) / 1000, Metric: labels.EmptyLabels()},
+	}
+	return true, r.currentMs, SampleVector(results)
+}
+
+func (r *TimeIterator) Close() error {
+	return nil
+}
+
+func (r *TimeIterator) Error() error {
+	return nil
+}
+
 // newLabelReplaceEvaluator
 func newLabelReplaceEvaluator(
 	ctx context.Context,
@@ -1313,6 +1413,159 @@ func (e *LabelReplaceEvaluator) Error() error {
 	return e.nextEvaluator.Error()
 }
 
+func newFunctionEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.FunctionExpr,
+	q Params,
+) (*FunctionEvaluator, error) {
+	fn, err := sampleFunction(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	nextEvaluator, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FunctionEvaluator{
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+		fn:            fn,
+	}, nil
+}
+
+// FunctionEvaluator applies a function to the value of each sample.
+type FunctionEvaluator struct {
+	nextEvaluator StepEvaluator
+	expr          *syntax.FunctionExpr
+	fn            func(s promql.Sample) float64
+}
+
+func (e *FunctionEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+	vec := r.SampleVector()
+	for i, s := range vec {
+		vec[i].F = e.fn(s)
+	}
+	return next, ts, SampleVector(vec)
+}
+
+func (e *FunctionEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *FunctionEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
+func sampleFunction(expr *syntax.FunctionExpr) (func(s promql.Sample) float64, error) {
+	switch expr.Operation {
+	case syntax.OpFuncAbs:
+		return func(s promql.Sample) float64 { return math.Abs(s.F) }, nil
+	case syntax.OpFuncCeil:
+		return func(s promql.Sample) float64 { return math.Ceil(s.F) }, nil
+	case syntax.OpFuncFloor:
+		return func(s promql.Sample) float64 { return math.Floor(s.F) }, nil
+	case syntax.OpFuncRound:
+		toNearest := 1.
+		if expr.Params != nil {
+			toNearest = *expr.Params
+		}
+		// Invert as it seems to cause fewer floating point accuracy issues.
+		toNearestInverse := 1.0 / toNearest
+		return func(s promql.Sample) float64 {
+			return math.Floor(s.F*toNearestInverse+0.5) / toNearestInverse
+		}, nil
+	case syntax.OpFuncClampMin:
+		limit := *expr.Params
+		return func(s promql.Sample) float64 { return math.Max(limit, s.F) }, nil
+	case syntax.OpFuncClampMax:
+		limit := *expr.Params
+		return func(s promql.Sample) float64 { return math.Min(limit, s.F) }, nil
+	case syntax.OpFuncLn:
+		return func(s promql.Sample) float64 { return math.Log(s.F) }, nil
+	case syntax.OpFuncExp:
+		return func(s promql.Sample) float64 { return math.Exp(s.F) }, nil
+	case syntax.OpFuncSqrt:
+		return func(s promql.Sample) float64 { return math.Sqrt(s.F) }, nil
+	case syntax.OpFuncTimestamp:
+		return func(s promql.Sample) float64 { return float64(s.T) / 1000 }, nil
+	default:
+		return nil, fmt.Errorf(syntax.UnsupportedErr, expr.Operation)
+	}
+}
+
+func newLabelJoinEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.LabelJoinExpr,
+	q Params,
+) (*LabelJoinEvaluator, error) {
+	nextEvaluator, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LabelJoinEvaluator{
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+		buf:           make([]byte, 0, 1024),
+	}, nil
+}
+
+type LabelJoinEvaluator struct {
+	nextEvaluator StepEvaluator
+	labelCache    map[uint64]labels.Labels
+	expr          *syntax.LabelJoinExpr
+	buf           []byte
+}
+
+func (e *LabelJoinEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+	vec := r.SampleVector()
+	if e.labelCache == nil {
+		e.labelCache = make(map[uint64]labels.Labels, len(vec))
+	}
+	var hash uint64
+	values := make([]string, len(e.expr.Src))
+	for i, s := range vec {
+		hash, e.buf = s.Metric.HashWithoutLabels(e.buf)
+		if labels, ok := e.labelCache[hash]; ok {
+			vec[i].Metric = labels
+			continue
+		}
+		for j, src := range e.expr.Src {
+			values[j] = s.Metric.Get(src)
+		}
+		res := strings.Join(values, e.expr.Separator)
+
+		lb := labels.NewBuilder(s.Metric).Del(e.expr.Dst)
+		if len(res) > 0 {
+			lb.Set(e.expr.Dst, res)
+		}
+		outLbs := lb.Labels()
+		e.labelCache[hash] = outLbs
+		vec[i].Metric = outLbs
+	}
+	return next, ts, SampleVector(vec)
+}
+
+func (e *LabelJoinEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *LabelJoinEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
 // This is to replace missing timeseries during absent_over_time aggregation.
 func absentLabels(expr syntax.SampleExpr) (labels.Labels, error) {
 	m := labels.Labels{}
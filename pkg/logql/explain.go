@@ -14,6 +14,16 @@ func (e *LabelReplaceEvaluator) Explain(parent Node) {
 	e.nextEvaluator.Explain(b)
 }
 
+func (e *LabelJoinEvaluator) Explain(parent Node) {
+	b := parent.Childf("%s LabelJoin", e.expr.Dst)
+	e.nextEvaluator.Explain(b)
+}
+
+func (e *FunctionEvaluator) Explain(parent Node) {
+	b := parent.Childf("%s Function", e.expr.Operation)
+	e.nextEvaluator.Explain(b)
+}
+
 func (e *VectorAggEvaluator) Explain(parent Node) {
 	b := parent.Childf("[%s, %s] VectorAgg", e.expr.Operation, e.expr.Grouping)
 	e.nextEvaluator.Explain(b)
@@ -48,6 +58,11 @@ func (e *AbsentRangeVectorEvaluator) Explain(parent Node) {
 	parent.Child("Absent RangeVectorAgg")
 }
 
+func (e *SubqueryEvaluator) Explain(parent Node) {
+	b := parent.Childf("[%s, %s] Subquery", e.expr.Operation, e.expr.Left.Range)
+	e.inner.Explain(b)
+}
+
 func (e *BinOpStepEvaluator) Explain(parent Node) {
 	b := parent.Childf("%s BinOp", e.expr.Op)
 	e.lse.Explain(b)
@@ -58,6 +73,10 @@ func (i *VectorIterator) Explain(parent Node) {
 	parent.Childf("%f vectorIterator", i.val)
 }
 
+func (i *TimeIterator) Explain(parent Node) {
+	parent.Child("timeIterator")
+}
+
 func (e *QuantileSketchVectorStepEvaluator) Explain(parent Node) {
 	b := parent.Child("QuantileSketchVector")
 	e.inner.Explain(b)
@@ -0,0 +1,445 @@
+package logql
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// histogramBucket returns the upper bound of the bucket the value falls into.
+// Like the buckets of Prometheus native histograms with schema 0, the bounds
+// are the powers of two, mirrored for negative values, and zero.
+// NaN values are counted in the +Inf bucket.
+func histogramBucket(v float64) float64 {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 1):
+		return math.Inf(1)
+	case math.IsInf(v, -1):
+		return math.Inf(-1)
+	case v == 0:
+		return 0
+	}
+
+	frac, exp := math.Frexp(v)
+	if v > 0 {
+		if frac == 0.5 {
+			// v is a power of two and therefore the upper bound itself.
+			return v
+		}
+		return math.Ldexp(1, exp)
+	}
+	return -math.Ldexp(1, exp-1)
+}
+
+// histogramBucketLowerBound returns the lower bound of the bucket with the
+// given upper bound.
+func histogramBucketLowerBound(le float64) float64 {
+	if math.IsInf(le, 0) {
+		return le
+	}
+	switch {
+	case le > 0:
+		return le / 2
+	case le < 0:
+		return le * 2
+	default:
+		return le
+	}
+}
+
+// newHistogramIterator returns an iterator that counts the values of each
+// series of the window into buckets. Each bucket is returned as a separate
+// series with the `le` label set to its upper bound. The buckets are not
+// cumulative, see [HistogramStepEvaluator].
+func newHistogramIterator(
+	it iter.PeekingSampleIterator,
+	selRange, step, start, end, offset int64,
+) RangeVectorIterator {
+	// forces at least one step.
+	if step == 0 {
+		step = 1
+	}
+	if offset != 0 {
+		start = start - offset
+		end = end - offset
+	}
+
+	inner := &batchRangeVectorIterator{
+		iter:     it,
+		step:     step,
+		end:      end,
+		selRange: selRange,
+		metrics:  map[string]labels.Labels{},
+		window:   map[string]*promql.Series{},
+		agg:      nil,
+		current:  start - step, // first loop iteration will set it to start
+		offset:   offset,
+	}
+	return &histogramBatchRangeVectorIterator{
+		batchRangeVectorIterator: inner,
+		buckets:                  map[string]map[float64]labels.Labels{},
+		counts:                   map[float64]float64{},
+	}
+}
+
+type histogramBatchRangeVectorIterator struct {
+	*batchRangeVectorIterator
+	at []promql.Sample
+
+	// buckets caches the labels of the bucket series by series and upper bound.
+	buckets map[string]map[float64]labels.Labels
+	counts  map[float64]float64
+}
+
+// At counts the values of the underlying window into buckets. Only the
+// buckets with values are returned and each bucket only counts the values
+// between the next lower bound, exclusive, and its upper bound, inclusive.
+// Unlike cumulative buckets, these can be summed across shards.
+func (r *histogramBatchRangeVectorIterator) At() (int64, StepResult) {
+	if r.at == nil {
+		r.at = make([]promql.Sample, 0, len(r.window))
+	}
+	r.at = r.at[:0]
+	// convert ts from nano to milli seconds as the iterator work with nanoseconds
+	ts := r.current/1e+6 + r.offset/1e+6
+	for lbs, series := range r.window {
+		clear(r.counts)
+		for _, p := range series.Floats {
+			r.counts[histogramBucket(p.F)]++
+		}
+		for le, count := range r.counts {
+			r.at = append(r.at, promql.Sample{
+				F:      count,
+				T:      ts,
+				Metric: r.bucketLabels(lbs, series.Metric, le),
+			})
+		}
+	}
+	return ts, SampleVector(r.at)
+}
+
+func (r *histogramBatchRangeVectorIterator) bucketLabels(lbs string, metric labels.Labels, le float64) labels.Labels {
+	buckets, ok := r.buckets[lbs]
+	if !ok {
+		buckets = map[float64]labels.Labels{}
+		r.buckets[lbs] = buckets
+	}
+	bucket, ok := buckets[le]
+	if !ok {
+		bucket = labels.NewBuilder(metric).Set(labels.BucketLabel, formatBucket(le)).Labels()
+		buckets[le] = bucket
+	}
+	return bucket
+}
+
+func formatBucket(le float64) string {
+	return strconv.FormatFloat(le, 'g', -1, 64)
+}
+
+// newHistogramStepEvaluator returns a step evaluator which turns the buckets
+// of next into cumulative buckets.
+func newHistogramStepEvaluator(next StepEvaluator) *HistogramStepEvaluator {
+	return &HistogramStepEvaluator{
+		nextEvaluator: next,
+		lb:            labels.NewBuilder(nil),
+		buckets:       map[uint64]map[float64]labels.Labels{},
+	}
+}
+
+// HistogramStepEvaluator turns the buckets of `histogram_over_time`, which
+// only count the values of their own bucket, into cumulative buckets like the
+// ones of Prometheus classic histograms, including the `+Inf` bucket.
+//
+// Cumulative buckets can only be summed if the histograms have the same
+// bounds. Therefore all steps of next are read first, and every histogram
+// returns a bucket for each bound seen by any histogram at any step. Buckets
+// of next with the same labels are summed, which merges the buckets of the
+// same histogram from different shards.
+type HistogramStepEvaluator struct {
+	nextEvaluator StepEvaluator
+	lb            *labels.Builder
+
+	loaded bool
+	steps  []histogramStep
+	bounds []float64
+	// buckets caches the labels of the bucket series by histogram and upper
+	// bound.
+	buckets map[uint64]map[float64]labels.Labels
+}
+
+type histogramStep struct {
+	ts         int64
+	histograms []*histogramCounts
+}
+
+type histogramCounts struct {
+	hash   uint64
+	metric labels.Labels
+	counts map[float64]float64
+}
+
+func (e *HistogramStepEvaluator) Next() (bool, int64, StepResult) {
+	if !e.loaded {
+		e.load()
+		e.loaded = true
+	}
+	if len(e.steps) == 0 {
+		return false, 0, SampleVector{}
+	}
+	step := e.steps[0]
+	e.steps = e.steps[1:]
+
+	vec := make(promql.Vector, 0, len(step.histograms)*(len(e.bounds)+1))
+	for _, h := range step.histograms {
+		var cumulative float64
+		for _, le := range e.bounds {
+			cumulative += h.counts[le]
+			vec = append(vec, promql.Sample{
+				T:      step.ts,
+				F:      cumulative,
+				Metric: e.bucketLabels(h, le),
+			})
+		}
+		vec = append(vec, promql.Sample{
+			T:      step.ts,
+			F:      cumulative + h.counts[math.Inf(1)],
+			Metric: e.bucketLabels(h, math.Inf(1)),
+		})
+	}
+	return true, step.ts, SampleVector(vec)
+}
+
+// load reads all steps of the next evaluator and collects the bounds of all
+// buckets.
+func (e *HistogramStepEvaluator) load() {
+	bounds := map[float64]struct{}{}
+	for {
+		next, ts, r := e.nextEvaluator.Next()
+		if !next {
+			break
+		}
+
+		step := histogramStep{ts: ts}
+		histograms := map[uint64]*histogramCounts{}
+		for _, s := range r.SampleVector() {
+			le, err := strconv.ParseFloat(s.Metric.Get(labels.BucketLabel), 64)
+			if err != nil {
+				continue
+			}
+			e.lb.Reset(s.Metric)
+			e.lb.Del(labels.BucketLabel)
+			metric := e.lb.Labels()
+
+			hash := metric.Hash()
+			h, ok := histograms[hash]
+			if !ok {
+				h = &histogramCounts{
+					hash:   hash,
+					metric: metric,
+					counts: map[float64]float64{},
+				}
+				histograms[hash] = h
+				step.histograms = append(step.histograms, h)
+			}
+			h.counts[le] += s.F
+			if !math.IsInf(le, 1) {
+				bounds[le] = struct{}{}
+			}
+		}
+		e.steps = append(e.steps, step)
+	}
+
+	e.bounds = make([]float64, 0, len(bounds))
+	for le := range bounds {
+		e.bounds = append(e.bounds, le)
+	}
+	sort.Float64s(e.bounds)
+}
+
+func (e *HistogramStepEvaluator) bucketLabels(h *histogramCounts, le float64) labels.Labels {
+	buckets, ok := e.buckets[h.hash]
+	if !ok {
+		buckets = map[float64]labels.Labels{}
+		e.buckets[h.hash] = buckets
+	}
+	bucket, ok := buckets[le]
+	if !ok {
+		bucket = labels.NewBuilder(h.metric).Set(labels.BucketLabel, formatBucket(le)).Labels()
+		buckets[le] = bucket
+	}
+	return bucket
+}
+
+func (e *HistogramStepEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *HistogramStepEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
+func (e *HistogramStepEvaluator) Explain(parent Node) {
+	b := parent.Child("Histogram")
+	e.nextEvaluator.Explain(b)
+}
+
+type histogramBucketCount struct {
+	le    float64
+	count float64
+}
+
+// histogramQuantile calculates the φ-quantile from the cumulative buckets of a
+// histogram. The value is interpolated linearly within the bucket the quantile
+// falls into, see Prometheus' `histogram_quantile`. Histograms without a `+Inf`
+// bucket are invalid and their quantile is NaN.
+func histogramQuantile(q float64, buckets []histogramBucketCount) float64 {
+	if math.IsNaN(q) {
+		return math.NaN()
+	}
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(1)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].le < buckets[j].le })
+	if len(buckets) == 0 || !math.IsInf(buckets[len(buckets)-1].le, 1) {
+		return math.NaN()
+	}
+
+	// The counts of the buckets may decrease because of floating point
+	// inaccuracies when they are summed, which would break the search below.
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].count < buckets[i-1].count {
+			buckets[i].count = buckets[i-1].count
+		}
+	}
+
+	total := buckets[len(buckets)-1].count
+	if total == 0 {
+		return math.NaN()
+	}
+
+	rank := q * total
+	i := sort.Search(len(buckets), func(i int) bool {
+		return buckets[i].count > 0 && buckets[i].count >= rank
+	})
+	if i == len(buckets)-1 {
+		// The quantile falls into the +Inf bucket, return the upper bound of
+		// the highest finite bucket instead.
+		if i == 0 {
+			return math.NaN()
+		}
+		return buckets[i-1].le
+	}
+
+	b := buckets[i]
+	lower, below := histogramBucketLowerBound(b.le), 0.0
+	if i > 0 {
+		below = buckets[i-1].count
+		lower = max(lower, buckets[i-1].le)
+	}
+	if math.IsInf(lower, -1) {
+		return lower
+	}
+	return lower + (b.le-lower)*((rank-below)/(b.count-below))
+}
+
+func newHistogramQuantileEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.FunctionExpr,
+	q Params,
+) (*HistogramQuantileEvaluator, error) {
+	nextEvaluator, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistogramQuantileEvaluator{
+		nextEvaluator: nextEvaluator,
+		quantile:      *expr.Params,
+		lb:            labels.NewBuilder(nil),
+	}, nil
+}
+
+// HistogramQuantileEvaluator calculates the quantile of each histogram from
+// its cumulative bucket series, ie the series that only differ by the `le`
+// label. Series without a valid `le` label are ignored.
+type HistogramQuantileEvaluator struct {
+	nextEvaluator StepEvaluator
+	quantile      float64
+	lb            *labels.Builder
+}
+
+type histogramQuantileGroup struct {
+	metric  labels.Labels
+	buckets map[float64]float64
+}
+
+func (e *HistogramQuantileEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+
+	groups := map[uint64]*histogramQuantileGroup{}
+	order := make([]uint64, 0)
+	for _, s := range r.SampleVector() {
+		le, err := strconv.ParseFloat(s.Metric.Get(labels.BucketLabel), 64)
+		if err != nil {
+			continue
+		}
+		e.lb.Reset(s.Metric)
+		e.lb.Del(labels.BucketLabel)
+		metric := e.lb.Labels()
+
+		hash := metric.Hash()
+		group, ok := groups[hash]
+		if !ok {
+			group = &histogramQuantileGroup{
+				metric:  metric,
+				buckets: map[float64]float64{},
+			}
+			groups[hash] = group
+			order = append(order, hash)
+		}
+		group.buckets[le] += s.F
+	}
+
+	vec := make(promql.Vector, 0, len(order))
+	for _, hash := range order {
+		group := groups[hash]
+		buckets := make([]histogramBucketCount, 0, len(group.buckets))
+		for le, count := range group.buckets {
+			buckets = append(buckets, histogramBucketCount{le: le, count: count})
+		}
+		vec = append(vec, promql.Sample{
+			T:      ts,
+			F:      histogramQuantile(e.quantile, buckets),
+			Metric: group.metric,
+		})
+	}
+	return next, ts, SampleVector(vec)
+}
+
+func (e *HistogramQuantileEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *HistogramQuantileEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
+func (e *HistogramQuantileEvaluator) Explain(parent Node) {
+	b := parent.Childf("%s HistogramQuantile", strconv.FormatFloat(e.quantile, 'f', -1, 64))
+	e.nextEvaluator.Explain(b)
+}
@@ -0,0 +1,149 @@
+package logql
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramBucket(t *testing.T) {
+	for _, tc := range []struct {
+		value    float64
+		expected float64
+	}{
+		{0, 0},
+		{0.3, 0.5},
+		{0.5, 0.5},
+		{1, 1},
+		{1.5, 2},
+		{3, 4},
+		{4, 4},
+		{1000, 1024},
+		{-0.75, -0.5},
+		{-1, -1},
+		{-3, -2},
+		{math.NaN(), math.Inf(1)},
+		{math.Inf(1), math.Inf(1)},
+		{math.Inf(-1), math.Inf(-1)},
+	} {
+		require.Equal(t, tc.expected, histogramBucket(tc.value), "value %f", tc.value)
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	buckets := func() []histogramBucketCount {
+		return []histogramBucketCount{
+			{le: 8, count: 6},
+			{le: 2, count: 2},
+			{le: math.Inf(1), count: 6},
+			{le: 1, count: 1},
+			{le: 4, count: 4},
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		q        float64
+		buckets  []histogramBucketCount
+		expected float64
+	}{
+		{"median", 0.5, buckets(), 3},
+		{"min", 0, buckets(), 0.5},
+		{"max", 1, buckets(), 8},
+		{"below 0", -1, buckets(), math.Inf(-1)},
+		{"above 1", 2, buckets(), math.Inf(1)},
+		{"empty", 0.5, nil, math.NaN()},
+		{"without inf bucket", 0.5, []histogramBucketCount{{le: 1, count: 1}, {le: 2, count: 2}}, math.NaN()},
+		{"inf bucket", 0.99, []histogramBucketCount{{le: 4, count: 1}, {le: math.Inf(1), count: 2}}, 4},
+		{"empty buckets", 0.5, []histogramBucketCount{{le: 1, count: 0}, {le: 2, count: 2}, {le: 4, count: 2}, {le: math.Inf(1), count: 2}}, 1.5},
+		{"negative values", 0.25, []histogramBucketCount{{le: -2, count: 2}, {le: 0, count: 4}, {le: math.Inf(1), count: 4}}, -3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := histogramQuantile(tc.q, tc.buckets)
+			if math.IsNaN(tc.expected) {
+				require.True(t, math.IsNaN(actual))
+				return
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestHistogramQuantileEvaluator(t *testing.T) {
+	bucket := func(app, le string, f float64) promql.Sample {
+		return promql.Sample{T: 1, F: f, Metric: labels.FromStrings("app", app, "le", le)}
+	}
+	ev := &HistogramQuantileEvaluator{
+		nextEvaluator: &mockStepEvaluator{
+			results: []StepResult{SampleVector{
+				bucket("foo", "2", 2),
+				bucket("bar", "8", 1),
+				bucket("foo", "4", 3),
+				// buckets with the same bound are summed.
+				bucket("foo", "4.0", 1),
+				bucket("foo", "+Inf", 4),
+				bucket("bar", "+Inf", 1),
+				// series without buckets are ignored.
+				{T: 1, F: 1, Metric: labels.FromStrings("app", "baz")},
+			}},
+		},
+		quantile: 0.5,
+		lb:       labels.NewBuilder(nil),
+	}
+
+	ok, _, r := ev.Next()
+	require.True(t, ok)
+	require.Equal(t, promql.Vector{
+		{T: 0, F: 2, Metric: labels.FromStrings("app", "foo")},
+		{T: 0, F: 6, Metric: labels.FromStrings("app", "bar")},
+	}, r.SampleVector())
+}
+
+func TestHistogramStepEvaluator(t *testing.T) {
+	bucket := func(app, le string, f float64) promql.Sample {
+		return promql.Sample{F: f, Metric: labels.FromStrings("app", app, "le", le)}
+	}
+	ev := newHistogramStepEvaluator(&mockStepEvaluator{
+		results: []StepResult{
+			SampleVector{
+				bucket("foo", "1", 1),
+				// buckets of the same histogram from different shards are summed.
+				bucket("foo", "1", 1),
+				bucket("foo", "4", 1),
+			},
+			SampleVector{
+				bucket("foo", "8", 2),
+				bucket("bar", "+Inf", 1),
+			},
+		},
+	})
+
+	// every histogram returns the buckets of all bounds seen at any step.
+	ok, _, r := ev.Next()
+	require.True(t, ok)
+	require.Equal(t, promql.Vector{
+		bucket("foo", "1", 2),
+		bucket("foo", "4", 3),
+		bucket("foo", "8", 3),
+		bucket("foo", "+Inf", 3),
+	}, r.SampleVector())
+
+	ok, _, r = ev.Next()
+	require.True(t, ok)
+	require.Equal(t, promql.Vector{
+		bucket("foo", "1", 0),
+		bucket("foo", "4", 0),
+		bucket("foo", "8", 2),
+		bucket("foo", "+Inf", 2),
+		bucket("bar", "1", 0),
+		bucket("bar", "4", 0),
+		bucket("bar", "8", 0),
+		bucket("bar", "+Inf", 1),
+	}, r.SampleVector())
+
+	ok, _, _ = ev.Next()
+	require.False(t, ok)
+}
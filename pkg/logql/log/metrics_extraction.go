@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/pkg/errors"
 	"github.com/prometheus/prometheus/model/labels"
 
@@ -18,6 +19,7 @@ const (
 	ConvertBytes    = "bytes"
 	ConvertDuration = "duration"
 	ConvertFloat    = "float"
+	ConvertHash     = "hash"
 )
 
 // LineExtractor extracts a float64 from a log line.
@@ -146,6 +148,8 @@ func LabelExtractorWithStages(
 		convFn = convertDuration
 	case ConvertFloat:
 		convFn = convertFloat
+	case ConvertHash:
+		convFn = convertHash
 	default:
 		return nil, errors.Errorf("unsupported conversion operation %s", conversion)
 	}
@@ -307,6 +311,12 @@ func convertFloat(v string) (float64, error) {
 	return strconv.ParseFloat(v, 64)
 }
 
+// convertHash fingerprints the value so it can be counted as a distinct value.
+// The fingerprint is kept below 2^53 to be represented exactly as a float64.
+func convertHash(v string) (float64, error) {
+	return float64(xxhash.Sum64String(v) >> 11), nil
+}
+
 func convertDuration(v string) (float64, error) {
 	d, err := time.ParseDuration(v)
 	if err != nil {
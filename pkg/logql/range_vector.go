@@ -12,6 +12,7 @@ import (
 	promql_parser "github.com/prometheus/prometheus/promql/parser"
 
 	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logql/sketch"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logql/vector"
 )
@@ -21,6 +22,11 @@ import (
 // the range.
 type BatchRangeVectorAggregator func([]promql.FPoint) float64
 
+// rangeBoundedAggregator aggregates the samples of the range (start, end], in
+// nanoseconds. It is used by aggregations which extrapolate to the boundaries
+// of the range or depend on the evaluation timestamp, like `increase`.
+type rangeBoundedAggregator func(start, end int64, samples []promql.FPoint) float64
+
 // RangeStreamingAgg streaming aggregates sample for each sample
 type RangeStreamingAgg interface {
 	// agg func works inside the Next func of RangeVectorIterator, agg used to agg each sample.
@@ -51,6 +57,19 @@ func newRangeVectorIterator(
 		start = start - offset
 		end = end - offset
 	}
+	if bounded := boundedAggregator(expr.Operation, expr.Params); bounded != nil {
+		return &batchRangeVectorIterator{
+			iter:     it,
+			step:     step,
+			end:      end,
+			selRange: selRange,
+			metrics:  map[string]labels.Labels{},
+			window:   map[string]*promql.Series{},
+			bounded:  bounded,
+			current:  start - step, // first loop iteration will set it to start
+			offset:   offset,
+		}, nil
+	}
 	var overlap bool
 	if selRange >= step && start != end {
 		overlap = true
@@ -97,6 +116,7 @@ type batchRangeVectorIterator struct {
 	metrics                              map[string]labels.Labels
 	at                                   []promql.Sample
 	agg                                  BatchRangeVectorAggregator
+	bounded                              rangeBoundedAggregator
 }
 
 func (r *batchRangeVectorIterator) Next() bool {
@@ -195,7 +215,7 @@ func (r *batchRangeVectorIterator) At() (int64, StepResult) {
 	ts := r.current/1e+6 + r.offset/1e+6
 	for _, series := range r.window {
 		r.at = append(r.at, promql.Sample{
-			F:      r.agg(series.Floats),
+			F:      r.aggregate(series.Floats),
 			T:      ts,
 			Metric: series.Metric,
 		})
@@ -203,6 +223,13 @@ func (r *batchRangeVectorIterator) At() (int64, StepResult) {
 	return ts, SampleVector(r.at)
 }
 
+func (r *batchRangeVectorIterator) aggregate(samples []promql.FPoint) float64 {
+	if r.bounded != nil {
+		return r.bounded(r.current-r.selRange, r.current, samples)
+	}
+	return r.agg(samples)
+}
+
 var seriesPool sync.Pool
 
 func getSeries() *promql.Series {
@@ -250,11 +277,45 @@ func aggregator(r *syntax.RangeAggregationExpr) (BatchRangeVectorAggregator, err
 		return last, nil
 	case syntax.OpRangeTypeAbsent:
 		return one, nil
+	case syntax.OpRangeTypeDeriv:
+		return deriv, nil
+	case syntax.OpRangeTypeChanges:
+		return changes, nil
+	case syntax.OpRangeTypeResets:
+		return resets, nil
+	case syntax.OpRangeTypeCountDistinct:
+		return countDistinctOverTime, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
 }
 
+// boundedAggregator returns the aggregator of operations depending on the
+// boundaries of the range, or nil for all other operations.
+func boundedAggregator(op string, params *float64) rangeBoundedAggregator {
+	switch op {
+	case syntax.OpRangeTypeIncrease:
+		return func(start, end int64, samples []promql.FPoint) float64 {
+			return extrapolatedDelta(start, end, samples, true)
+		}
+	case syntax.OpRangeTypeDelta:
+		return func(start, end int64, samples []promql.FPoint) float64 {
+			return extrapolatedDelta(start, end, samples, false)
+		}
+	case syntax.OpRangeTypePredictLinear:
+		duration := *params
+		return func(_, end int64, samples []promql.FPoint) float64 {
+			if len(samples) < 2 {
+				return 0
+			}
+			slope, intercept := linearRegression(samples, end)
+			return slope*duration + intercept
+		}
+	default:
+		return nil
+	}
+}
+
 // rateLogs calculates the per-second rate of log lines or values extracted
 // from log lines
 func rateLogs(selRange time.Duration, computeValues bool) func(samples []promql.FPoint) float64 {
@@ -356,6 +417,128 @@ func durationMilliseconds(d time.Duration) int64 {
 	return int64(d / (time.Millisecond / time.Nanosecond))
 }
 
+// extrapolatedDelta calculates the difference between the first and last value
+// of the range (start, end], in nanoseconds, allowing for counter resets if
+// isCounter is true. Like in Prometheus, the result is extrapolated to the
+// boundaries of the range if the first/last samples are close to them.
+func extrapolatedDelta(start, end int64, samples []promql.FPoint, isCounter bool) float64 {
+	// No sense in trying to compute a delta without at least two points.
+	if len(samples) < 2 {
+		return 0
+	}
+	first, last := samples[0], samples[len(samples)-1]
+
+	resultValue := last.F - first.F
+	if isCounter {
+		prev := first.F
+		for _, sample := range samples[1:] {
+			if sample.F < prev {
+				resultValue += prev
+			}
+			prev = sample.F
+		}
+	}
+
+	durationToStart := float64(first.T-start) / 1e9
+	durationToEnd := float64(end-last.T) / 1e9
+	sampledInterval := float64(last.T-first.T) / 1e9
+	if sampledInterval == 0 {
+		return resultValue
+	}
+	averageDurationBetweenSamples := sampledInterval / float64(len(samples)-1)
+
+	// If the first/last samples are close to the boundaries of the range,
+	// extrapolate the result. This is as we expect that another sample
+	// will exist given the spacing between samples we've seen thus far,
+	// with an allowance for noise.
+	extrapolationThreshold := averageDurationBetweenSamples * 1.1
+	if durationToStart >= extrapolationThreshold {
+		durationToStart = averageDurationBetweenSamples / 2
+	}
+	if isCounter && resultValue > 0 && first.F >= 0 {
+		// Counters cannot be negative. If the duration to the zero point
+		// of the counter is shorter than the durationToStart, we take the
+		// zero point as the start of the series.
+		durationToZero := sampledInterval * (first.F / resultValue)
+		if durationToZero < durationToStart {
+			durationToStart = durationToZero
+		}
+	}
+	if durationToEnd >= extrapolationThreshold {
+		durationToEnd = averageDurationBetweenSamples / 2
+	}
+
+	extrapolateToInterval := sampledInterval + durationToStart + durationToEnd
+	return resultValue * (extrapolateToInterval / sampledInterval)
+}
+
+// linearRegression calculates the slope per second and the intercept at
+// interceptTime, in nanoseconds, of the simple linear regression of the samples.
+func linearRegression(samples []promql.FPoint, interceptTime int64) (slope, intercept float64) {
+	var n, sumX, sumY, sumXY, sumX2 float64
+	initY := samples[0].F
+	constY := true
+	for i, sample := range samples {
+		// Set constY to false if any new y values are encountered.
+		if constY && i > 0 && sample.F != initY {
+			constY = false
+		}
+		n++
+		x := float64(sample.T-interceptTime) / 1e9
+		sumX += x
+		sumY += sample.F
+		sumXY += x * sample.F
+		sumX2 += x * x
+	}
+	if constY {
+		if math.IsInf(initY, 0) {
+			return math.NaN(), math.NaN()
+		}
+		return 0, initY
+	}
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+
+	slope = covXY / varX
+	intercept = sumY/n - slope*sumX/n
+	return slope, intercept
+}
+
+// deriv calculates the per-second derivative of the samples using a simple
+// linear regression.
+func deriv(samples []promql.FPoint) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	// Intercept at the first sample to keep the x values small.
+	slope, _ := linearRegression(samples, samples[0].T)
+	return slope
+}
+
+// changes counts the number of times the value of the samples changed.
+func changes(samples []promql.FPoint) float64 {
+	var count float64
+	for i := 1; i < len(samples); i++ {
+		prev, cur := samples[i-1].F, samples[i].F
+		if cur != prev && !(math.IsNaN(cur) && math.IsNaN(prev)) {
+			count++
+		}
+	}
+	return count
+}
+
+// resets counts the number of counter resets, any decrease of the value
+// between two consecutive samples.
+func resets(samples []promql.FPoint) float64 {
+	var count float64
+	for i := 1; i < len(samples); i++ {
+		if samples[i].F < samples[i-1].F {
+			count++
+		}
+	}
+	return count
+}
+
 // rateLogBytes calculates the per-second rate of log bytes.
 func rateLogBytes(selRange time.Duration) func(samples []promql.FPoint) float64 {
 	return func(samples []promql.FPoint) float64 {
@@ -368,6 +551,16 @@ func countOverTime(samples []promql.FPoint) float64 {
 	return float64(len(samples))
 }
 
+// countDistinctOverTime estimates the number of distinct values using a
+// HyperLogLog sketch, so results match the ones of sharded queries.
+func countDistinctOverTime(samples []promql.FPoint) float64 {
+	hll := sketch.NewHyperLogLog()
+	for _, v := range samples {
+		hll.Add(v.F)
+	}
+	return float64(hll.Estimate())
+}
+
 func sumOverTime(samples []promql.FPoint) float64 {
 	var sum float64
 	for _, v := range samples {
@@ -627,6 +820,14 @@ func streamingAggregator(r *syntax.RangeAggregationExpr) (RangeStreamingAgg, err
 		return &LastOverTime{}, nil
 	case syntax.OpRangeTypeAbsent:
 		return &OneOverTime{}, nil
+	case syntax.OpRangeTypeDeriv:
+		return &DerivOverTime{}, nil
+	case syntax.OpRangeTypeChanges:
+		return &ChangesOverTime{}, nil
+	case syntax.OpRangeTypeResets:
+		return &ResetsOverTime{}, nil
+	case syntax.OpRangeTypeCountDistinct:
+		return &CountDistinctOverTime{hll: sketch.NewHyperLogLog()}, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -675,6 +876,54 @@ func (a *RateCounterOverTime) at() float64 {
 	return extrapolatedRate(a.samples, a.selRange, true, true)
 }
 
+// DerivOverTime calculates the per-second derivative of values extracted
+// from log lines.
+type DerivOverTime struct {
+	samples []promql.FPoint
+}
+
+func (a *DerivOverTime) agg(sample promql.FPoint) {
+	a.samples = append(a.samples, sample)
+}
+
+func (a *DerivOverTime) at() float64 {
+	return deriv(a.samples)
+}
+
+type ChangesOverTime struct {
+	count, last float64
+	seen        bool
+}
+
+func (a *ChangesOverTime) agg(sample promql.FPoint) {
+	if a.seen && sample.F != a.last && !(math.IsNaN(sample.F) && math.IsNaN(a.last)) {
+		a.count++
+	}
+	a.last = sample.F
+	a.seen = true
+}
+
+func (a *ChangesOverTime) at() float64 {
+	return a.count
+}
+
+type ResetsOverTime struct {
+	count, last float64
+	seen        bool
+}
+
+func (a *ResetsOverTime) agg(sample promql.FPoint) {
+	if a.seen && sample.F < a.last {
+		a.count++
+	}
+	a.last = sample.F
+	a.seen = true
+}
+
+func (a *ResetsOverTime) at() float64 {
+	return a.count
+}
+
 // rateLogBytes calculates the per-second rate of log bytes.
 type RateLogBytesOverTime struct {
 	sum      float64
@@ -701,6 +950,18 @@ func (a *CountOverTime) at() float64 {
 	return a.count
 }
 
+type CountDistinctOverTime struct {
+	hll *sketch.HyperLogLog
+}
+
+func (a *CountDistinctOverTime) agg(sample promql.FPoint) {
+	a.hll.Add(sample.F)
+}
+
+func (a *CountDistinctOverTime) at() float64 {
+	return float64(a.hll.Estimate())
+}
+
 type SumOverTime struct {
 	sum float64
 }
@@ -535,6 +535,12 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 		{"first", 1., syntax.OpRangeTypeFirst, false},
 		{"last", 3., syntax.OpRangeTypeLast, false},
 		{"absent", 1., syntax.OpRangeTypeAbsent, false},
+		{"increase", 3., syntax.OpRangeTypeIncrease, false},
+		{"delta", -3., syntax.OpRangeTypeDelta, true},
+		{"deriv", 1.0000000000000001e+09, syntax.OpRangeTypeDeriv, false},
+		{"changes", 2., syntax.OpRangeTypeChanges, false},
+		{"resets", 2., syntax.OpRangeTypeResets, true},
+		{"predict linear", 9.900000039999998e+08, syntax.OpRangeTypePredictLinear, false},
 	}
 
 	var start, end int64 = 4, 4 // Instant query
@@ -554,6 +560,39 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 	}
 }
 
+func Test_CounterRangeAggregations(t *testing.T) {
+	// samples every 10s in the range (0s, 60s], with a counter reset at 40s.
+	points := func(values ...float64) []promql.FPoint {
+		res := make([]promql.FPoint, 0, len(values))
+		for i, v := range values {
+			res = append(res, promql.FPoint{T: time.Unix(int64(i+1)*10, 0).UnixNano(), F: v})
+		}
+		return res
+	}
+	start, end := time.Unix(0, 0).UnixNano(), time.Unix(60, 0).UnixNano()
+	predictLinear := boundedAggregator(syntax.OpRangeTypePredictLinear, proto.Float64(60))
+
+	for _, tc := range []struct {
+		name     string
+		samples  []promql.FPoint
+		agg      func([]promql.FPoint) float64
+		expected float64
+	}{
+		{"increase", points(1, 2, 3, 4, 5, 6), func(s []promql.FPoint) float64 { return extrapolatedDelta(start, end, s, true) }, 6},
+		{"increase with reset", points(1, 2, 3, 1, 2, 3), func(s []promql.FPoint) float64 { return extrapolatedDelta(start, end, s, true) }, 6},
+		{"delta", points(6, 5, 4, 3, 2, 1), func(s []promql.FPoint) float64 { return extrapolatedDelta(start, end, s, false) }, -6},
+		{"single sample", points(1), func(s []promql.FPoint) float64 { return extrapolatedDelta(start, end, s, true) }, 0},
+		{"deriv", points(1, 2, 3, 4, 5, 6), deriv, 0.1},
+		{"predict_linear", points(1, 2, 3, 4, 5, 6), func(s []promql.FPoint) float64 { return predictLinear(start, end, s) }, 12},
+		{"changes", points(1, 2, 2, 1, 1, 3), changes, 3},
+		{"resets", points(1, 2, 3, 1, 2, 1), resets, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.InDelta(t, tc.expected, tc.agg(tc.samples), 1e-9)
+		})
+	}
+}
+
 func sampleIter(negative bool) iter.PeekingSampleIterator {
 	return iter.NewPeekingSampleIterator(
 		iter.NewSortSampleIterator([]iter.SampleIterator{
@@ -24,6 +24,10 @@ var splittableVectorOp = map[string]struct{}{
 	syntax.OpTypeSortDesc: {},
 }
 
+// splittableRangeVectorOp lists the range aggregations whose result can be
+// merged from the results of sub-ranges. Operations like increase, delta, deriv,
+// changes, resets and predict_linear are not listed: they depend on consecutive
+// samples or on the boundaries of the whole range, which are lost when splitting.
 var splittableRangeVectorOp = map[string]struct{}{
 	syntax.OpRangeTypeRate:      {},
 	syntax.OpRangeTypeBytesRate: {},
@@ -175,9 +179,28 @@ func (m RangeMapper) Map(expr syntax.SampleExpr, vectorAggrPushdown *syntax.Vect
 		}
 		e.Left = lhsMapped
 		return e, nil
+	case *syntax.LabelJoinExpr:
+		lhsMapped, err := m.Map(e.Left, vectorAggrPushdown, recorder)
+		if err != nil {
+			return nil, err
+		}
+		e.Left = lhsMapped
+		return e, nil
+	case *syntax.FunctionExpr:
+		// Functions are not distributive over the downstream results, so the
+		// outer vector aggregation is not pushed down through them.
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		e.Left = lhsMapped
+		return e, nil
 	case *syntax.LiteralExpr:
 		return e, nil
-	case *syntax.VectorExpr:
+	case *syntax.VectorExpr, *syntax.TimeExpr:
+		return e, nil
+	case *syntax.SubqueryAggregationExpr:
+		// subqueries are evaluated over their whole range and are not split.
 		return e, nil
 	case *syntax.MultiVariantExpr:
 		// TODO(twhitney): we should be able to handle multi-variant expressions but creating
@@ -579,7 +602,11 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		return isSplittableByRange(e.SampleExpr) || literalLHS && isSplittableByRange(e.RHS) || literalRHS
 	case *syntax.LabelReplaceExpr:
 		return isSplittableByRange(e.Left)
-	case *syntax.VectorExpr:
+	case *syntax.LabelJoinExpr:
+		return isSplittableByRange(e.Left)
+	case *syntax.FunctionExpr:
+		return isSplittableByRange(e.Left)
+	case *syntax.VectorExpr, *syntax.TimeExpr:
 		return false
 	default:
 		return false
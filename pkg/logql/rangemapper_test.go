@@ -1809,6 +1809,51 @@ func Test_SplitRangeVectorMapping(t *testing.T) {
 			)`,
 			3,
 		},
+
+		// label_join
+		{
+			`label_join(sum by (baz) (count_over_time({app="foo"}[3m])), "x", "-", "a", "b")`,
+			`label_join(
+				sum by (baz) (
+					sum without () (
+						downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 2m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 1m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m])), shard=<nil>>
+					)
+				),
+				"x", "-", "a", "b"
+			)`,
+			3,
+		},
+
+		// functions
+		{
+			`sum(abs(count_over_time({app="foo"}[3m])))`,
+			`sum(
+				abs(
+					sum without () (
+						downstream<count_over_time({app="foo"} [1m] offset 2m0s), shard=<nil>>
+						++ downstream<count_over_time({app="foo"} [1m] offset 1m0s), shard=<nil>>
+						++ downstream<count_over_time({app="foo"} [1m]), shard=<nil>>
+					)
+				)
+			)`,
+			3,
+		},
+		{
+			`clamp_min(sum by (baz) (count_over_time({app="foo"}[3m])), 1)`,
+			`clamp_min(
+				sum by (baz) (
+					sum without () (
+						downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 2m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 1m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m])), shard=<nil>>
+					)
+				),
+				1
+			)`,
+			3,
+		},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			t.Parallel()
@@ -1841,6 +1886,18 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 			`sum(avg_over_time({app="foo"} | unwrap bar[3m]))`,
 			`sum(avg_over_time({app="foo"} | unwrap bar[3m]))`,
 		},
+		{
+			`sum(increase({app="foo"} | unwrap bar[3m]))`,
+			`sum(increase({app="foo"} | unwrap bar[3m]))`,
+		},
+		{
+			`changes({app="foo"} | unwrap bar[3m])`,
+			`changes({app="foo"} | unwrap bar[3m])`,
+		},
+		{
+			`predict_linear(3600, {app="foo"} | unwrap bar[3m])`,
+			`predict_linear(3600, {app="foo"} | unwrap bar[3m])`,
+		},
 
 		// should be noop if range interval is lower or equal to split interval (1m)
 		{
@@ -1999,6 +2056,19 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 			`vector(0)`,
 			`vector(0.000000)`,
 		},
+		{
+			`time()`,
+			`time()`,
+		},
+		// should be noop if subquery
+		{
+			`max_over_time(sum(rate({app="foo"}[3m]))[1h:1m])`,
+			`max_over_time(sum(rate({app="foo"}[3m]))[1h:1m])`,
+		},
+		{
+			`sum(avg_over_time(count_over_time({app="foo"}[3m])[1h:5m]))`,
+			`sum(avg_over_time(count_over_time({app="foo"}[3m])[1h:5m]))`,
+		},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			t.Parallel()
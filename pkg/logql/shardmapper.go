@@ -88,7 +88,7 @@ func (m ShardMapper) Map(expr syntax.Expr, r *downstreamRecorder, topLevel bool)
 	switch e := expr.(type) {
 	case *syntax.LiteralExpr:
 		return e, 0, nil
-	case *syntax.VectorExpr:
+	case *syntax.VectorExpr, *syntax.TimeExpr:
 		return e, 0, nil
 	case *syntax.MultiVariantExpr:
 		// TODO(twhitney): this should be possible to support but hasn't been implemented yet
@@ -99,10 +99,17 @@ func (m ShardMapper) Map(expr syntax.Expr, r *downstreamRecorder, topLevel bool)
 		return m.mapVectorAggregationExpr(e, r, topLevel)
 	case *syntax.LabelReplaceExpr:
 		return m.mapLabelReplaceExpr(e, r, topLevel)
+	case *syntax.LabelJoinExpr:
+		return m.mapLabelJoinExpr(e, r, topLevel)
+	case *syntax.FunctionExpr:
+		return m.mapFunctionExpr(e, r, topLevel)
 	case *syntax.RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, r, topLevel)
 	case *syntax.BinOpExpr:
 		return m.mapBinOpExpr(e, r, topLevel)
+	case *syntax.SubqueryAggregationExpr:
+		// subqueries need all inner samples of a step and are not sharded.
+		return noOp(e, m.shards.Resolver())
 	default:
 		return nil, 0, errors.Errorf("unexpected expr type (%T) for ASTMapper type (%T) ", expr, m)
 	}
@@ -415,6 +422,29 @@ func (m ShardMapper) mapLabelReplaceExpr(expr *syntax.LabelReplaceExpr, r *downs
 	return &cpy, bytesPerShard, nil
 }
 
+func (m ShardMapper) mapLabelJoinExpr(expr *syntax.LabelJoinExpr, r *downstreamRecorder, topLevel bool) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r, topLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
+// mapFunctionExpr applies the function on the frontend to the merged results
+// of the sharded inner expression. Functions nested in shardable vector
+// aggregations are pushed down to the shards instead, see `FunctionExpr.Shardable()`.
+func (m ShardMapper) mapFunctionExpr(expr *syntax.FunctionExpr, r *downstreamRecorder, topLevel bool) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r, topLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
 // These functions require a different merge strategy than the default
 // concatenation.
 // This is because the same label sets may exist on multiple shards when label-reducing parsing is applied or when
@@ -580,6 +610,66 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 			quantile: expr.Params,
 		}, bytesPerShard, nil
 
+	case syntax.OpRangeTypeHistogram:
+		shards, bytesPerShard, err := m.shards.Shards(expr)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(shards) == 0 {
+			return noOp(expr, m.shards.Resolver())
+		}
+
+		// Cumulative buckets of different shards may have different bounds
+		// and can't be summed. Instead the shards return the buckets of
+		// __histogram_buckets_over_time__, which only count the values of
+		// their own bucket. These are summed before they are made cumulative:
+		// histogram_over_time() by (foo) ->
+		// histogram_merge(__histogram_buckets_over_time__() by (foo) ++ ...)
+		downstreams := make([]DownstreamSampleExpr, 0, len(shards))
+		expr.Operation = syntax.OpRangeTypeHistogramBuckets
+		for i := range shards {
+			downstreams = append(downstreams, DownstreamSampleExpr{
+				shard:      &shards[i],
+				SampleExpr: expr,
+			})
+		}
+
+		return &HistogramMergeExpr{
+			downstreams: downstreams,
+		}, bytesPerShard, nil
+
+	case syntax.OpRangeTypeCountDistinct:
+		potentialConflict := syntax.ReducesLabels(expr)
+		if !potentialConflict && (expr.Grouping == nil || expr.Grouping.Noop()) {
+			return m.mapSampleExpr(expr, r)
+		}
+
+		shards, bytesPerShard, err := m.shards.Shards(expr)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(shards) == 0 {
+			return noOp(expr, m.shards.Resolver())
+		}
+
+		// The same value may be seen on several shards, so the distinct values
+		// cannot be summed. Instead the shards return their HyperLogLog sketches
+		// which are merged before the estimation:
+		// count_distinct_over_time() by (foo) ->
+		// hyperloglog_eval(__count_distinct_sketch_over_time__() by (foo) ++ ...)
+		downstreams := make([]DownstreamSampleExpr, 0, len(shards))
+		expr.Operation = syntax.OpRangeTypeCountDistinctSketch
+		for i := len(shards) - 1; i >= 0; i-- {
+			downstreams = append(downstreams, DownstreamSampleExpr{
+				shard:      &shards[i],
+				SampleExpr: expr,
+			})
+		}
+
+		return &HyperLogLogEvalExpr{
+			downstreams: downstreams,
+		}, bytesPerShard, nil
+
 	case syntax.OpRangeTypeFirst:
 		if !m.firstOverTimeSharding {
 			return noOp(expr, m.shards.Resolver())
@@ -664,7 +754,7 @@ func isNoOp(left syntax.Expr, right syntax.Expr) bool {
 
 func isLiteralOrVector(e syntax.Expr) bool {
 	switch e.(type) {
-	case *syntax.VectorExpr, *syntax.LiteralExpr:
+	case *syntax.VectorExpr, *syntax.LiteralExpr, *syntax.TimeExpr:
 		return true
 	default:
 		return false
@@ -176,6 +176,53 @@ func TestMappingStrings(t *testing.T) {
 			        >
 			)`,
 		},
+		{
+			in: `histogram_over_time({foo="bar"} | unwrap latency [5m])`,
+			out: `HistogramMerge<
+				downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]), shard=0_of_2>
+				++ downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]), shard=1_of_2>
+			>`,
+		},
+		{
+			in: `histogram_over_time({foo="bar"} | unwrap latency [5m]) by (app)`,
+			out: `HistogramMerge<
+				downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]) by (app), shard=0_of_2>
+				++ downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]) by (app), shard=1_of_2>
+			>`,
+		},
+		{
+			in: `histogram_over_time({foo="bar"} | unwrap latency [5m]) without (app)`,
+			out: `HistogramMerge<
+				downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]) without (app), shard=0_of_2>
+				++ downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]) without (app), shard=1_of_2>
+			>`,
+		},
+		{
+			// aggregations over histograms are not pushed down to the shards.
+			in: `histogram_quantile(0.99, sum by (le) (histogram_over_time({foo="bar"} | unwrap latency [5m])))`,
+			out: `histogram_quantile(0.99, sum by (le) (
+				HistogramMerge<
+					downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]), shard=0_of_2>
+					++ downstream<__histogram_buckets_over_time__({foo="bar"} | unwrap latency [5m]), shard=1_of_2>
+				>
+			))`,
+		},
+		{
+			in: `count_distinct_over_time({foo="bar"} | unwrap ip [5m])`,
+			out: `downstream<count_distinct_over_time({foo="bar"} | unwrap ip [5m]), shard=0_of_2>
+				++ downstream<count_distinct_over_time({foo="bar"} | unwrap ip [5m]), shard=1_of_2>`,
+		},
+		{
+			in: `count_distinct_over_time({foo="bar"} | json | unwrap ip [5m]) by (app)`,
+			out: `HyperLogLogEval<
+				downstream<__count_distinct_sketch_over_time__({foo="bar"} | json | unwrap ip [5m]) by (app), shard=1_of_2>
+				++ downstream<__count_distinct_sketch_over_time__({foo="bar"} | json | unwrap ip [5m]) by (app), shard=0_of_2>
+			>`,
+		},
+		{
+			in:  `max by (app)(count_distinct_over_time({foo="bar"} | json | unwrap ip [5m]) by (app))`,
+			out: `maxby(app)(count_distinct_over_time({foo="bar"}|json|unwrapip[5m])by(app))`,
+		},
 		{
 			in: `sum(max(rate({foo="bar"}[5m])))`,
 			out: `sum(max(
@@ -473,6 +520,38 @@ func TestMappingStrings(t *testing.T) {
 			in:  `count by (foo) (sum by (foo, bar) (rate({job="bar"}[1m])))`,
 			out: `countby(foo)(sumby(foo,bar)(downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=1_of_2>))`,
 		},
+		{
+			// functions are pushed down into shardable vector aggregations
+			in:  `sum(abs(rate({job="bar"}[1m])))`,
+			out: `sum(downstream<sum(abs(rate({job="bar"}[1m]))),shard=0_of_2>++downstream<sum(abs(rate({job="bar"}[1m]))),shard=1_of_2>)`,
+		},
+		{
+			// but applied after the merge of aggregations
+			in:  `abs(sum(rate({job="bar"}[1m])))`,
+			out: `abs(sum(downstream<sum(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sum(rate({job="bar"}[1m])),shard=1_of_2>))`,
+		},
+		{
+			in:  `sum(clamp_max(sum by (foo) (rate({job="bar"}[1m])), 10))`,
+			out: `sum(clamp_max(sumby(foo)(downstream<sumby(foo)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo)(rate({job="bar"}[1m])),shard=1_of_2>),10))`,
+		},
+		{
+			in:  `label_join(sum by (foo) (rate({job="bar"}[1m])), "dst", ",", "foo")`,
+			out: `label_join(sumby(foo)(downstream<sumby(foo)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo)(rate({job="bar"}[1m])),shard=1_of_2>),"dst",",","foo")`,
+		},
+		{
+			// time() is evaluated on the frontend
+			in:  `time() - timestamp(sum(rate({job="bar"}[1m])))`,
+			out: `(time()-timestamp(sum(downstream<sum(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sum(rate({job="bar"}[1m])),shard=1_of_2>)))`,
+		},
+		{
+			// subqueries aren't shardable
+			in:  `sum(max_over_time(sum(rate({job="bar"}[1m]))[1h:1m]))`,
+			out: `sum(max_over_time(sum(rate({job="bar"}[1m]))[1h:1m]))`,
+		},
+		{
+			in:  `max_over_time(rate({job="bar"}[1m])[1h:1m]) / 2`,
+			out: `(downstream<max_over_time(rate({job="bar"}[1m])[1h:1m]),shard=<nil>>/2)`,
+		},
 	} {
 		t.Run(tc.in, func(t *testing.T) {
 			ast, err := syntax.ParseExpr(tc.in)
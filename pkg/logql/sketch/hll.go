@@ -0,0 +1,56 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/axiomhq/hyperloglog"
+)
+
+// HyperLogLog estimates the number of distinct values it has seen. Sketches
+// built from disjoint sets of values can be merged losslessly, which allows
+// the counting to be distributed across shards.
+type HyperLogLog struct {
+	hll *hyperloglog.Sketch
+}
+
+// NewHyperLogLog creates a sketch with a precision of 14, ie a standard error
+// of ~0.8%. The sketch starts in sparse mode to keep small cardinalities cheap.
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{hll: hyperloglog.New14()}
+}
+
+// Insert adds the value to the sketch.
+func (h *HyperLogLog) Insert(value []byte) {
+	h.hll.Insert(value)
+}
+
+// Add adds the float value to the sketch. Values are distinguished by their
+// bit representation.
+func (h *HyperLogLog) Add(value float64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(value))
+	h.hll.Insert(buf[:])
+}
+
+// Estimate returns the estimated number of distinct values.
+func (h *HyperLogLog) Estimate() uint64 {
+	return h.hll.Estimate()
+}
+
+// Merge merges the other sketch into this one.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	return h.hll.Merge(other.hll)
+}
+
+func (h *HyperLogLog) ToProto() ([]byte, error) {
+	return h.hll.MarshalBinary()
+}
+
+func HyperLogLogFromProto(buf []byte) (*HyperLogLog, error) {
+	hll := hyperloglog.New14()
+	if err := hll.UnmarshalBinary(buf); err != nil {
+		return nil, err
+	}
+	return &HyperLogLog{hll: hll}, nil
+}
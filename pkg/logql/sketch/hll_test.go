@@ -0,0 +1,51 @@
+package sketch
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	left, right := NewHyperLogLog(), NewHyperLogLog()
+	for i := 0; i < 10000; i++ {
+		left.Add(float64(i))
+		// Values seen multiple times are only counted once.
+		left.Add(float64(i))
+		right.Insert([]byte(strconv.Itoa(i)))
+	}
+	require.InEpsilon(t, 10000, float64(left.Estimate()), 0.02)
+	require.InEpsilon(t, 10000, float64(right.Estimate()), 0.02)
+
+	require.NoError(t, left.Merge(right))
+	require.InEpsilon(t, 20000, float64(left.Estimate()), 0.02)
+}
+
+func TestHyperLogLogMergeOverlapping(t *testing.T) {
+	left, right := NewHyperLogLog(), NewHyperLogLog()
+	for i := 0; i < 1000; i++ {
+		left.Add(float64(i))
+		right.Add(float64(i + 500))
+	}
+
+	require.NoError(t, left.Merge(right))
+	require.InEpsilon(t, 1500, float64(left.Estimate()), 0.02)
+}
+
+func TestHyperLogLogSerialization(t *testing.T) {
+	hll := NewHyperLogLog()
+	for i := 0; i < 100; i++ {
+		hll.Add(float64(i))
+	}
+
+	buf, err := hll.ToProto()
+	require.NoError(t, err)
+
+	actual, err := HyperLogLogFromProto(buf)
+	require.NoError(t, err)
+	require.Equal(t, hll.Estimate(), actual.Estimate())
+
+	_, err = HyperLogLogFromProto([]byte{0x1})
+	require.Error(t, err)
+}
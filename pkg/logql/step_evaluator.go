@@ -8,6 +8,7 @@ type StepResult interface {
 	SampleVector() promql.Vector
 	QuantileSketchVec() ProbabilisticQuantileVector
 	CountMinSketchVec() CountMinSketchVector
+	HyperLogLogVec() HyperLogLogVector
 }
 
 type SampleVector promql.Vector
@@ -26,6 +27,10 @@ func (SampleVector) CountMinSketchVec() CountMinSketchVector {
 	return CountMinSketchVector{}
 }
 
+func (SampleVector) HyperLogLogVec() HyperLogLogVector {
+	return HyperLogLogVector{}
+}
+
 // StepEvaluator evaluate a single step of a query.
 type StepEvaluator interface {
 	// while Next returns a promql.Value, the only acceptable types are Scalar and Vector.
@@ -0,0 +1,199 @@
+package logql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// subqueryParams overrides the time range and step of the parent query so the
+// inner expression of a subquery is evaluated at the subquery resolution.
+type subqueryParams struct {
+	Params
+	start, end time.Time
+	step       time.Duration
+}
+
+func (p subqueryParams) Start() time.Time    { return p.start }
+func (p subqueryParams) End() time.Time      { return p.end }
+func (p subqueryParams) Step() time.Duration { return p.step }
+
+// newSubqueryParams returns the params used to evaluate the inner expression
+// of a subquery. As in PromQL, the inner steps are aligned to multiples of the
+// subquery step so results don't depend on the start of the outer query.
+func newSubqueryParams(q Params, expr *syntax.SubqueryExpr) subqueryParams {
+	step := expr.Step.Nanoseconds()
+	start := q.Start().Add(-expr.Offset).Add(-expr.Range).UnixNano()
+	if mod := start % step; mod != 0 {
+		if mod < 0 {
+			mod += step
+		}
+		start += step - mod
+	}
+	return subqueryParams{
+		Params: q,
+		start:  time.Unix(0, start),
+		end:    q.End().Add(-expr.Offset),
+		step:   expr.Step,
+	}
+}
+
+func newSubqueryAggEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.SubqueryAggregationExpr,
+	q Params,
+) (StepEvaluator, error) {
+	agg, err := subqueryAggregator(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left.Left, newSubqueryParams(q, expr.Left))
+	if err != nil {
+		return nil, err
+	}
+
+	step := q.Step().Nanoseconds()
+	// forces at least one step.
+	if step == 0 {
+		step = 1
+	}
+	offset := expr.Left.Offset.Nanoseconds()
+	start := q.Start().UnixNano() - offset
+	it := &batchRangeVectorIterator{
+		iter:     iter.NewPeekingSampleIterator(newStepSampleIterator(inner)),
+		step:     step,
+		end:      q.End().UnixNano() - offset,
+		selRange: expr.Left.Range.Nanoseconds(),
+		metrics:  map[string]labels.Labels{},
+		window:   map[string]*promql.Series{},
+		agg:      agg,
+		bounded:  boundedAggregator(expr.Operation, expr.Params),
+		current:  start - step, // first loop iteration will set it to start
+		offset:   offset,
+	}
+
+	var ev StepEvaluator = &RangeVectorEvaluator{iter: it}
+	if expr.Operation == syntax.OpRangeTypeAbsent {
+		absentLabels, err := absentLabels(expr)
+		if err != nil {
+			return nil, err
+		}
+		ev = &AbsentRangeVectorEvaluator{
+			iter: it,
+			lbs:  absentLabels,
+		}
+	}
+
+	return &SubqueryEvaluator{
+		StepEvaluator: ev,
+		expr:          expr,
+		inner:         inner,
+	}, nil
+}
+
+// SubqueryEvaluator aggregates the results of the inner expression of a
+// subquery over the range of each step.
+type SubqueryEvaluator struct {
+	StepEvaluator
+	expr  *syntax.SubqueryAggregationExpr
+	inner StepEvaluator
+}
+
+func subqueryAggregator(expr *syntax.SubqueryAggregationExpr) (BatchRangeVectorAggregator, error) {
+	switch expr.Operation {
+	case syntax.OpRangeTypeCount:
+		return countOverTime, nil
+	case syntax.OpRangeTypeSum:
+		return sumOverTime, nil
+	case syntax.OpRangeTypeAvg:
+		return avgOverTime, nil
+	case syntax.OpRangeTypeMax:
+		return maxOverTime, nil
+	case syntax.OpRangeTypeMin:
+		return minOverTime, nil
+	case syntax.OpRangeTypeStddev:
+		return stddevOverTime, nil
+	case syntax.OpRangeTypeStdvar:
+		return stdvarOverTime, nil
+	case syntax.OpRangeTypeQuantile:
+		return quantileOverTime(*expr.Params), nil
+	case syntax.OpRangeTypeFirst:
+		return first, nil
+	case syntax.OpRangeTypeLast:
+		return last, nil
+	case syntax.OpRangeTypeAbsent:
+		return one, nil
+	case syntax.OpRangeTypeDeriv:
+		return deriv, nil
+	case syntax.OpRangeTypeChanges:
+		return changes, nil
+	case syntax.OpRangeTypeResets:
+		return resets, nil
+	case syntax.OpRangeTypeIncrease, syntax.OpRangeTypeDelta, syntax.OpRangeTypePredictLinear:
+		// aggregated by the bounded aggregator of the operation.
+		return nil, nil
+	default:
+		return nil, fmt.Errorf(syntax.UnsupportedErr, expr.Operation)
+	}
+}
+
+// stepSampleIterator turns the vectors produced by a step evaluator into a
+// sample iterator ordered by timestamp, so they can be consumed by range
+// vector iterators.
+type stepSampleIterator struct {
+	ev     StepEvaluator
+	vec    promql.Vector
+	cur    promql.Sample
+	labels map[uint64]string
+}
+
+func newStepSampleIterator(ev StepEvaluator) *stepSampleIterator {
+	return &stepSampleIterator{
+		ev:     ev,
+		labels: map[uint64]string{},
+	}
+}
+
+func (it *stepSampleIterator) Next() bool {
+	for len(it.vec) == 0 {
+		next, _, r := it.ev.Next()
+		if !next {
+			return false
+		}
+		it.vec = r.SampleVector()
+	}
+	it.cur, it.vec = it.vec[0], it.vec[1:]
+	return true
+}
+
+func (it *stepSampleIterator) At() logproto.Sample {
+	return logproto.Sample{
+		// step evaluators work with milliseconds, sample iterators with nanoseconds.
+		Timestamp: it.cur.T * int64(time.Millisecond),
+		Value:     it.cur.F,
+		Hash:      it.StreamHash(),
+	}
+}
+
+func (it *stepSampleIterator) Labels() string {
+	hash := it.StreamHash()
+	if lbs, ok := it.labels[hash]; ok {
+		return lbs
+	}
+	lbs := it.cur.Metric.String()
+	it.labels[hash] = lbs
+	return lbs
+}
+
+func (it *stepSampleIterator) StreamHash() uint64 { return it.cur.Metric.Hash() }
+func (it *stepSampleIterator) Err() error         { return it.ev.Error() }
+func (it *stepSampleIterator) Close() error       { return it.ev.Close() }
@@ -52,6 +52,9 @@ func (VectorAggregationExpr) isExpr()      {}
 func (LiteralExpr) isExpr()                {}
 func (VectorExpr) isExpr()                 {}
 func (LabelReplaceExpr) isExpr()           {}
+func (LabelJoinExpr) isExpr()              {}
+func (FunctionExpr) isExpr()               {}
+func (TimeExpr) isExpr()                   {}
 func (LineParserExpr) isExpr()             {}
 func (LogfmtParserExpr) isExpr()           {}
 func (LineFilterExpr) isExpr()             {}
@@ -67,6 +70,8 @@ func (LogRangeExpr) isExpr()               {}
 func (OffsetExpr) isExpr()                 {}
 func (UnwrapExpr) isExpr()                 {}
 func (MultiVariantExpr) isExpr()           {}
+func (SubqueryExpr) isExpr()               {}
+func (SubqueryAggregationExpr) isExpr()    {}
 
 // LogSelectorExpr is a expression filtering and returning logs.
 type LogSelectorExpr interface {
@@ -84,6 +89,7 @@ func (PipelineExpr) isLogSelectorExpr()   {}
 func (MultiStageExpr) isLogSelectorExpr() {}
 func (LiteralExpr) isLogSelectorExpr()    {}
 func (VectorExpr) isLogSelectorExpr()     {}
+func (TimeExpr) isLogSelectorExpr()       {}
 
 // SampleExpr is a LogQL expression filtering logs and returning metric samples
 type SampleExpr interface {
@@ -96,12 +102,16 @@ type SampleExpr interface {
 	isSampleExpr()
 }
 
-func (RangeAggregationExpr) isSampleExpr()  {}
-func (VectorAggregationExpr) isSampleExpr() {}
-func (LiteralExpr) isSampleExpr()           {}
-func (VectorExpr) isSampleExpr()            {}
-func (LabelReplaceExpr) isSampleExpr()      {}
-func (MultiVariantExpr) isSampleExpr()      {}
+func (RangeAggregationExpr) isSampleExpr()    {}
+func (VectorAggregationExpr) isSampleExpr()   {}
+func (LiteralExpr) isSampleExpr()             {}
+func (VectorExpr) isSampleExpr()              {}
+func (LabelReplaceExpr) isSampleExpr()        {}
+func (LabelJoinExpr) isSampleExpr()           {}
+func (FunctionExpr) isSampleExpr()            {}
+func (TimeExpr) isSampleExpr()                {}
+func (MultiVariantExpr) isSampleExpr()        {}
+func (SubqueryAggregationExpr) isSampleExpr() {}
 
 // StageExpr is an expression defining a single step into a log pipeline
 type StageExpr interface {
@@ -852,6 +862,9 @@ func newDropLabelsExpr(dropLabels []log.NamedLabelMatcher) *DropLabelsExpr {
 
 func (e *DropLabelsExpr) Shardable(_ bool) bool { return true }
 
+// Labels returns the names and matchers of the labels to drop.
+func (e *DropLabelsExpr) Labels() []log.NamedLabelMatcher { return e.dropLabels }
+
 func (e *DropLabelsExpr) Stage() (log.Stage, error) {
 	return log.NewDropLabels(e.dropLabels), nil
 }
@@ -892,6 +905,9 @@ func newKeepLabelsExpr(keepLabels []log.NamedLabelMatcher) *KeepLabelsExpr {
 
 func (e *KeepLabelsExpr) Shardable(_ bool) bool { return true }
 
+// Labels returns the names and matchers of the labels to keep.
+func (e *KeepLabelsExpr) Labels() []log.NamedLabelMatcher { return e.keepLabels }
+
 func (e *KeepLabelsExpr) Stage() (log.Stage, error) {
 	return log.NewKeepLabels(e.keepLabels), nil
 }
@@ -1248,6 +1264,15 @@ const (
 	OpRangeTypeFirst       = "first_over_time"
 	OpRangeTypeLast        = "last_over_time"
 	OpRangeTypeAbsent      = "absent_over_time"
+	OpRangeTypeHistogram   = "histogram_over_time"
+
+	// range vector ops over counters and gauges
+	OpRangeTypeIncrease      = "increase"
+	OpRangeTypeDelta         = "delta"
+	OpRangeTypeDeriv         = "deriv"
+	OpRangeTypeChanges       = "changes"
+	OpRangeTypeResets        = "resets"
+	OpRangeTypePredictLinear = "predict_linear"
 
 	// vector
 	OpTypeVector = "vector"
@@ -1300,6 +1325,22 @@ const (
 	OpConvDurationSeconds = "duration_seconds"
 
 	OpLabelReplace = "label_replace"
+	OpLabelJoin    = "label_join"
+
+	// vector functions
+	OpFuncAbs       = "abs"
+	OpFuncCeil      = "ceil"
+	OpFuncFloor     = "floor"
+	OpFuncRound     = "round"
+	OpFuncClampMin  = "clamp_min"
+	OpFuncClampMax  = "clamp_max"
+	OpFuncLn        = "ln"
+	OpFuncExp       = "exp"
+	OpFuncSqrt      = "sqrt"
+	OpFuncTimestamp = "timestamp"
+	OpFuncTime      = "time"
+
+	OpFuncHistogramQuantile = "histogram_quantile"
 
 	// function filters
 	OpFilterIP = "ip"
@@ -1317,14 +1358,17 @@ const (
 	// internal expressions not represented in LogQL. These are used to
 	// evaluate expressions differently resulting in intermediate formats
 	// that are not consumable by LogQL clients but are used for sharding.
-	OpRangeTypeQuantileSketch     = "__quantile_sketch_over_time__"
-	OpRangeTypeFirstWithTimestamp = "__first_over_time_ts__"
-	OpRangeTypeLastWithTimestamp  = "__last_over_time_ts__"
+	OpRangeTypeQuantileSketch      = "__quantile_sketch_over_time__"
+	OpRangeTypeFirstWithTimestamp  = "__first_over_time_ts__"
+	OpRangeTypeLastWithTimestamp   = "__last_over_time_ts__"
+	OpRangeTypeCountDistinctSketch = "__count_distinct_sketch_over_time__"
+	OpRangeTypeHistogramBuckets    = "__histogram_buckets_over_time__"
 
 	OpTypeCountMinSketch = "__count_min_sketch__"
 
 	// probabilistic aggregations
-	OpTypeApproxTopK = "approx_topk"
+	OpTypeApproxTopK         = "approx_topk"
+	OpRangeTypeCountDistinct = "count_distinct_over_time"
 
 	// variants
 	OpVariants = "variants"
@@ -1364,7 +1408,7 @@ type RangeAggregationExpr struct {
 func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping, stringParams *string) SampleExpr {
 	var params *float64
 	if stringParams != nil {
-		if operation != OpRangeTypeQuantile && operation != OpRangeTypeQuantileSketch {
+		if operation != OpRangeTypeQuantile && operation != OpRangeTypeQuantileSketch && operation != OpRangeTypePredictLinear {
 			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
 		}
 		var err error
@@ -1375,7 +1419,7 @@ func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping,
 		}
 
 	} else {
-		if operation == OpRangeTypeQuantile {
+		if operation == OpRangeTypeQuantile || operation == OpRangeTypePredictLinear {
 			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
 		}
 	}
@@ -1420,17 +1464,27 @@ func (e RangeAggregationExpr) validate() error {
 		switch e.Operation {
 		case OpRangeTypeAvg, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile,
 			OpRangeTypeQuantileSketch, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeFirst,
-			OpRangeTypeLast, OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeLast, OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp,
+			OpRangeTypeCountDistinct, OpRangeTypeCountDistinctSketch, OpRangeTypeHistogram,
+			OpRangeTypeHistogramBuckets:
 		default:
 			return fmt.Errorf("grouping not allowed for %s aggregation", e.Operation)
 		}
 	}
 	if e.Left.Unwrap != nil {
 		switch e.Operation {
+		case OpRangeTypeCountDistinct, OpRangeTypeCountDistinctSketch:
+			// distinct label values are counted as is, there is nothing to convert.
+			if e.Left.Unwrap.Operation != "" {
+				return fmt.Errorf("conversion function %s not supported for %s", e.Left.Unwrap.Operation, e.Operation)
+			}
+			return nil
 		case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev,
 			OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeRateCounter,
 			OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast, OpRangeTypeQuantileSketch,
-			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp, OpRangeTypeIncrease,
+			OpRangeTypeDelta, OpRangeTypeDeriv, OpRangeTypeChanges, OpRangeTypeResets,
+			OpRangeTypePredictLinear, OpRangeTypeHistogram, OpRangeTypeHistogramBuckets:
 			return nil
 		default:
 			return fmt.Errorf("invalid aggregation %s with unwrap", e.Operation)
@@ -1471,7 +1525,9 @@ func (e *RangeAggregationExpr) Shardable(topLevel bool) bool {
 	// the top level aggregation in a query, such as max(quantile_over_time(...)).
 	// The sharding here will be blocked even if the feature flag in the shardmapper
 	// to enable sharding of quantile queries is enabled.
-	if e.Operation == OpRangeTypeQuantile && !topLevel {
+	// The same applies to count_distinct_over_time, whose sketches are only
+	// merged at the top level.
+	if (e.Operation == OpRangeTypeQuantile || e.Operation == OpRangeTypeCountDistinct) && !topLevel {
 		return false
 	}
 	return shardableOps[e.Operation] && e.Left.Shardable(topLevel)
@@ -1488,6 +1544,166 @@ func (e *RangeAggregationExpr) Walk(f WalkFn) {
 
 func (e *RangeAggregationExpr) Accept(v RootVisitor) { v.VisitRangeAggregation(e) }
 
+// subqueryRange holds the range and resolution of a subquery, e.g. `[1h:1m]`.
+type subqueryRange struct {
+	Range time.Duration
+	Step  time.Duration
+}
+
+// SubqueryExpr evaluates a sample expression over a range at a fixed
+// resolution and produces a range vector, e.g. `sum(rate({app="foo"}[1m]))[1h:1m]`.
+type SubqueryExpr struct {
+	Left   SampleExpr
+	Range  time.Duration
+	Step   time.Duration
+	Offset time.Duration
+}
+
+func newSubqueryExpr(left SampleExpr, r subqueryRange, o *OffsetExpr) *SubqueryExpr {
+	var offset time.Duration
+	if o != nil {
+		offset = o.Offset
+	}
+	return &SubqueryExpr{
+		Left:   left,
+		Range:  r.Range,
+		Step:   r.Step,
+		Offset: offset,
+	}
+}
+
+// impls Stringer
+func (e SubqueryExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Left.String())
+	sb.WriteString(fmt.Sprintf("[%v:%v]", model.Duration(e.Range), model.Duration(e.Step)))
+	if e.Offset != 0 {
+		offsetExpr := OffsetExpr{Offset: e.Offset}
+		sb.WriteString(offsetExpr.String())
+	}
+	return sb.String()
+}
+
+// Subqueries are evaluated as a whole, since every step of the outer query
+// needs all the inner samples within its range.
+func (e *SubqueryExpr) Shardable(_ bool) bool { return false }
+
+func (e *SubqueryExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *SubqueryExpr) Accept(v RootVisitor) { v.VisitSubquery(e) }
+
+// SubqueryAggregationExpr applies a range vector aggregation to the result of
+// a subquery, e.g. `max_over_time(sum(rate({app="foo"}[1m]))[1h:1m])`.
+type SubqueryAggregationExpr struct {
+	Left      *SubqueryExpr
+	Operation string
+
+	Params *float64
+	err    error
+}
+
+func newSubqueryAggregationExpr(left *SubqueryExpr, operation string, stringParams *string) SampleExpr {
+	var params *float64
+	if stringParams != nil {
+		if operation != OpRangeTypeQuantile && operation != OpRangeTypePredictLinear {
+			return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
+		}
+		var err error
+		params = new(float64)
+		*params, err = strconv.ParseFloat(*stringParams, 64)
+		if err != nil {
+			return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0)}
+		}
+	} else if operation == OpRangeTypeQuantile || operation == OpRangeTypePredictLinear {
+		return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
+	}
+	e := &SubqueryAggregationExpr{
+		Left:      left,
+		Operation: operation,
+		Params:    params,
+	}
+	if err := e.validate(); err != nil {
+		return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e SubqueryAggregationExpr) validate() error {
+	switch e.Operation {
+	case OpRangeTypeCount, OpRangeTypeSum, OpRangeTypeAvg, OpRangeTypeMax, OpRangeTypeMin,
+		OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeFirst,
+		OpRangeTypeLast, OpRangeTypeAbsent, OpRangeTypeIncrease, OpRangeTypeDelta,
+		OpRangeTypeDeriv, OpRangeTypeChanges, OpRangeTypeResets, OpRangeTypePredictLinear:
+		return nil
+	default:
+		return fmt.Errorf("invalid aggregation %s over subquery", e.Operation)
+	}
+}
+
+func (e *SubqueryAggregationExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Left.Selector()
+}
+
+func (e *SubqueryAggregationExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Left.Extractors()
+}
+
+func (e *SubqueryAggregationExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	groups, err := e.Left.Left.MatcherGroups()
+	if err != nil {
+		return nil, err
+	}
+	// The inner expression is evaluated over the whole range of the subquery.
+	for i := range groups {
+		groups[i].Interval += e.Left.Range
+		groups[i].Offset += e.Left.Offset
+	}
+	return groups, nil
+}
+
+// impls Stringer
+func (e *SubqueryAggregationExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Params != nil {
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+		sb.WriteString(",")
+	}
+	sb.WriteString(e.Left.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func (e *SubqueryAggregationExpr) Shardable(topLevel bool) bool { return e.Left.Shardable(topLevel) }
+
+func (e *SubqueryAggregationExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *SubqueryAggregationExpr) Accept(v RootVisitor) { v.VisitSubqueryAggregation(e) }
+
 // Grouping struct represents the grouping by/without label(s) for vector aggregators and range vector aggregators.
 // The representation is as follows:
 //   - No Grouping (labels dismissed): <operation> (<expr>) => Grouping{Without: false, Groups: nil}
@@ -1642,6 +1858,13 @@ func (e *VectorAggregationExpr) Shardable(topLevel bool) bool {
 		return false
 	}
 
+	// The buckets of histograms are merged across shards before they are
+	// made cumulative, so aggregations over them can't be pushed down to
+	// the shards.
+	if containsHistogram(e.Left) {
+		return false
+	}
+
 	switch e.Operation {
 
 	case OpTypeCount, OpTypeAvg:
@@ -1689,6 +1912,19 @@ func (e *VectorAggregationExpr) Shardable(topLevel bool) bool {
 	return true
 }
 
+// containsHistogram returns true if expr contains a `histogram_over_time`
+// range aggregation.
+func containsHistogram(expr Expr) bool {
+	found := false
+	expr.Walk(func(e Expr) bool {
+		if r, ok := e.(*RangeAggregationExpr); ok && r.Operation == OpRangeTypeHistogram {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
 func (e *VectorAggregationExpr) Walk(f WalkFn) {
 	if !f(e) {
 		return
@@ -2270,6 +2506,218 @@ func (e *LabelReplaceExpr) String() string {
 	return sb.String()
 }
 
+type LabelJoinExpr struct {
+	Left      SampleExpr
+	Dst       string
+	Separator string
+	Src       []string
+	err       error
+}
+
+func mustNewLabelJoinExpr(left SampleExpr, dst, separator string, src []string) *LabelJoinExpr {
+	if !model.LabelName(dst).IsValid() {
+		return &LabelJoinExpr{
+			err: logqlmodel.NewParseError(fmt.Sprintf("invalid destination label name in label_join: %s", dst), 0, 0),
+		}
+	}
+	for _, name := range src {
+		if !model.LabelName(name).IsValid() {
+			return &LabelJoinExpr{
+				err: logqlmodel.NewParseError(fmt.Sprintf("invalid source label name in label_join: %s", name), 0, 0),
+			}
+		}
+	}
+	return &LabelJoinExpr{
+		Left:      left,
+		Dst:       dst,
+		Separator: separator,
+		Src:       src,
+	}
+}
+
+func (e *LabelJoinExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+func (e *LabelJoinExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.MatcherGroups()
+}
+
+func (e *LabelJoinExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Extractors()
+}
+
+func (e *LabelJoinExpr) Shardable(_ bool) bool {
+	return false
+}
+
+func (e *LabelJoinExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *LabelJoinExpr) Accept(v RootVisitor) { v.VisitLabelJoin(e) }
+
+func (e *LabelJoinExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(OpLabelJoin)
+	sb.WriteString("(")
+	sb.WriteString(e.Left.String())
+	sb.WriteString(",")
+	sb.WriteString(strconv.Quote(e.Dst))
+	sb.WriteString(",")
+	sb.WriteString(strconv.Quote(e.Separator))
+	for _, src := range e.Src {
+		sb.WriteString(",")
+		sb.WriteString(strconv.Quote(src))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// FunctionExpr applies a function to the value of each sample of a vector,
+// e.g. `abs(...)`, `round(..., 0.5)` or `timestamp(...)`.
+// `histogram_quantile(φ, ...)` is the exception: it calculates the quantile
+// over the bucket series of each histogram.
+type FunctionExpr struct {
+	Left      SampleExpr
+	Operation string
+	Params    *float64
+	err       error
+}
+
+func newFunctionExpr(left SampleExpr, operation string, param *LiteralExpr) *FunctionExpr {
+	var params *float64
+	if param != nil {
+		val, err := param.Value()
+		if err != nil {
+			return &FunctionExpr{err: err}
+		}
+		params = &val
+	}
+
+	switch operation {
+	case OpFuncClampMin, OpFuncClampMax, OpFuncHistogramQuantile:
+		if params == nil {
+			return &FunctionExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
+		}
+	case OpFuncRound:
+		if params != nil && *params == 0 {
+			return &FunctionExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: must not be zero", operation), 0, 0)}
+		}
+	default:
+		if params != nil {
+			return &FunctionExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", param, operation), 0, 0)}
+		}
+	}
+
+	return &FunctionExpr{
+		Left:      left,
+		Operation: operation,
+		Params:    params,
+	}
+}
+
+func (e *FunctionExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+func (e *FunctionExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.MatcherGroups()
+}
+
+func (e *FunctionExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Extractors()
+}
+
+// Functions are applied to each series independently, so they can be pushed
+// down to the shards as long as no series is spread across multiple shards,
+// e.g. `sum(abs(rate(...)))` but not `sum(abs(sum(rate(...))))`.
+func (e *FunctionExpr) Shardable(topLevel bool) bool {
+	if !e.Left.Shardable(topLevel) || ReducesLabels(e.Left) {
+		return false
+	}
+	aggregated := false
+	e.Left.Walk(func(e Expr) bool {
+		if _, ok := e.(*VectorAggregationExpr); ok {
+			aggregated = true
+		}
+		return !aggregated
+	})
+	return !aggregated
+}
+
+func (e *FunctionExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *FunctionExpr) Accept(v RootVisitor) { v.VisitFunction(e) }
+
+func (e *FunctionExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Operation == OpFuncHistogramQuantile {
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+		sb.WriteString(",")
+		sb.WriteString(e.Left.String())
+		sb.WriteString(")")
+		return sb.String()
+	}
+	sb.WriteString(e.Left.String())
+	if e.Params != nil {
+		sb.WriteString(",")
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// TimeExpr returns the evaluation timestamp of each step in seconds, `time()`.
+// Like literals it is a scalar when used in binary operations.
+type TimeExpr struct{}
+
+func (e *TimeExpr) String() string { return OpFuncTime + "()" }
+
+func (e *TimeExpr) Selector() (LogSelectorExpr, error) { return e, nil }
+func (e *TimeExpr) HasFilter() bool                    { return false }
+func (e *TimeExpr) Shardable(_ bool) bool              { return false }
+func (e *TimeExpr) Walk(f WalkFn)                      { f(e) }
+func (e *TimeExpr) Accept(v RootVisitor)               { v.VisitTime(e) }
+
+func (e *TimeExpr) Pipeline() (log.Pipeline, error)        { return log.NewNoopPipeline(), nil }
+func (e *TimeExpr) Matchers() []*labels.Matcher            { return nil }
+func (e *TimeExpr) MatcherGroups() ([]MatcherRange, error) { return nil, nil }
+
+func (e *TimeExpr) Extractors() ([]log.SampleExtractor, error) { return []log.SampleExtractor{}, nil }
+
 // shardableOps lists the operations which may be sharded, but are not
 // guaranteed to be. See the `Shardable()` implementations
 // on the respective expr types for more details.
@@ -2312,6 +2760,9 @@ var shardableOps = map[string]bool{
 	OpRangeTypeMax:       true,
 	OpRangeTypeMin:       true,
 	OpRangeTypeQuantile:  true,
+	OpRangeTypeHistogram: true,
+
+	OpRangeTypeCountDistinct: true,
 
 	// binops - arith
 	OpTypeAdd: true,
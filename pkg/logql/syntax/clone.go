@@ -82,11 +82,53 @@ func (v *cloneVisitor) VisitRangeAggregation(e *RangeAggregationExpr) {
 	v.cloned = copied
 }
 
+func (v *cloneVisitor) VisitSubqueryAggregation(e *SubqueryAggregationExpr) {
+	copied := &SubqueryAggregationExpr{
+		Left:      MustClone[*SubqueryExpr](e.Left),
+		Operation: e.Operation,
+	}
+
+	if e.Params != nil {
+		tmp := *e.Params
+		copied.Params = &tmp
+	}
+
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitSubquery(e *SubqueryExpr) {
+	v.cloned = &SubqueryExpr{
+		Left:   MustClone[SampleExpr](e.Left),
+		Range:  e.Range,
+		Step:   e.Step,
+		Offset: e.Offset,
+	}
+}
+
 func (v *cloneVisitor) VisitLabelReplace(e *LabelReplaceExpr) {
 	left := MustClone[SampleExpr](e.Left)
 	v.cloned = mustNewLabelReplaceExpr(left, e.Dst, e.Replacement, e.Src, e.Regex)
 }
 
+func (v *cloneVisitor) VisitLabelJoin(e *LabelJoinExpr) {
+	left := MustClone[SampleExpr](e.Left)
+	src := make([]string, len(e.Src))
+	copy(src, e.Src)
+	v.cloned = mustNewLabelJoinExpr(left, e.Dst, e.Separator, src)
+}
+
+func (v *cloneVisitor) VisitFunction(e *FunctionExpr) {
+	copied := &FunctionExpr{
+		Left:      MustClone[SampleExpr](e.Left),
+		Operation: e.Operation,
+	}
+	if e.Params != nil {
+		tmp := *e.Params
+		copied.Params = &tmp
+	}
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitLiteral(e *LiteralExpr) {
 	v.cloned = &LiteralExpr{Val: e.Val}
 }
@@ -95,6 +137,10 @@ func (v *cloneVisitor) VisitVector(e *VectorExpr) {
 	v.cloned = &VectorExpr{Val: e.Val}
 }
 
+func (v *cloneVisitor) VisitTime(_ *TimeExpr) {
+	v.cloned = &TimeExpr{}
+}
+
 func (v *cloneVisitor) VisitLogRange(e *LogRangeExpr) {
 	copied := &LogRangeExpr{
 		Left:     MustClone[LogSelectorExpr](e.Left),
@@ -69,6 +69,11 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 		default:
 			convOp = log.ConvertFloat
 		}
+		// distinct values are counted on the label value itself, which
+		// may not be numeric.
+		if r.Operation == OpRangeTypeCountDistinct || r.Operation == OpRangeTypeCountDistinctSketch {
+			convOp = log.ConvertHash
+		}
 
 		return log.LabelExtractorWithStages(
 			r.Left.Unwrap.Identifier,
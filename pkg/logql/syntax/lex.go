@@ -109,8 +109,33 @@ var functionTokens = map[string]int{
 	OpRangeTypeFirst:       FIRST_OVER_TIME,
 	OpRangeTypeLast:        LAST_OVER_TIME,
 	OpRangeTypeAbsent:      ABSENT_OVER_TIME,
+	OpRangeTypeHistogram:   HISTOGRAM_OVER_TIME,
 	OpTypeVector:           VECTOR,
 
+	// range vec ops over counters and gauges
+	OpRangeTypeIncrease:      INCREASE,
+	OpRangeTypeDelta:         DELTA,
+	OpRangeTypeDeriv:         DERIV,
+	OpRangeTypeChanges:       CHANGES,
+	OpRangeTypeResets:        RESETS,
+	OpRangeTypePredictLinear: PREDICT_LINEAR,
+
+	// vector functions
+	OpFuncAbs:       ABS,
+	OpFuncCeil:      CEIL,
+	OpFuncFloor:     FLOOR,
+	OpFuncRound:     ROUND,
+	OpFuncClampMin:  CLAMP_MIN,
+	OpFuncClampMax:  CLAMP_MAX,
+	OpFuncLn:        LN,
+	OpFuncExp:       EXP,
+	OpFuncSqrt:      SQRT,
+	OpFuncTimestamp: TIMESTAMP,
+	OpFuncTime:      TIME,
+	OpLabelJoin:     LABEL_JOIN,
+
+	OpFuncHistogramQuantile: HISTOGRAM_QUANTILE,
+
 	// vec ops
 	OpTypeSum:      SUM,
 	OpTypeAvg:      AVG,
@@ -125,7 +150,8 @@ var functionTokens = map[string]int{
 	OpTypeSortDesc: SORT_DESC,
 	OpLabelReplace: LABEL_REPLACE,
 
-	OpTypeApproxTopK: APPROX_TOPK,
+	OpTypeApproxTopK:         APPROX_TOPK,
+	OpRangeTypeCountDistinct: COUNT_DISTINCT_OVER_TIME,
 
 	// conversion Op
 	OpConvBytes:           BYTES_CONV,
@@ -208,6 +234,9 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 		l.builder.Reset()
 		for r := l.Next(); r != scanner.EOF; r = l.Next() {
 			if r == ']' {
+				if rng, step, ok := strings.Cut(l.builder.String(), ":"); ok {
+					return l.subqueryRange(rng, step, lval)
+				}
 				i, err := model.ParseDuration(l.builder.String())
 				if err != nil {
 					l.Error(err.Error())
@@ -257,6 +286,30 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 	return IDENTIFIER
 }
 
+// subqueryRange parses the `<range>:<step>` part of a subquery.
+func (l *lexer) subqueryRange(rng, step string, lval *syntaxSymType) int {
+	r, err := model.ParseDuration(rng)
+	if err != nil {
+		l.Error(err.Error())
+		return 0
+	}
+	if step == "" {
+		l.Error("missing step in subquery range")
+		return 0
+	}
+	s, err := model.ParseDuration(step)
+	if err != nil {
+		l.Error(err.Error())
+		return 0
+	}
+	if s == 0 {
+		l.Error("subquery step must be greater than zero")
+		return 0
+	}
+	lval.subqueryRange = subqueryRange{Range: time.Duration(r), Step: time.Duration(s)}
+	return SUBQUERY_RANGE
+}
+
 func (l *lexer) Error(msg string) {
 	l.errs = append(l.errs, logqlmodel.NewParseError(msg, l.Line, l.Column))
 }
@@ -213,6 +213,18 @@ func validateSampleExpr(expr SampleExpr) error {
 			return e.err
 		}
 		return nil
+	case *TimeExpr:
+		return nil
+	case *FunctionExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left)
+	case *LabelJoinExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left)
 	case *VectorAggregationExpr:
 		if e.err != nil {
 			return e.err
@@ -223,6 +235,11 @@ func validateSampleExpr(expr SampleExpr) error {
 			}
 		}
 		return validateSampleExpr(e.Left)
+	case *SubqueryAggregationExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left.Left)
 	default:
 		selector, err := e.Selector()
 		if err != nil {
@@ -234,7 +251,7 @@ func validateSampleExpr(expr SampleExpr) error {
 
 func validateLogSelectorExpression(expr LogSelectorExpr) error {
 	switch e := expr.(type) {
-	case *VectorExpr:
+	case *VectorExpr, *TimeExpr:
 		return nil
 	default:
 		return validateMatchers(e.Matchers())
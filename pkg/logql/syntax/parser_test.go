@@ -1831,6 +1831,27 @@ var ParseTestCases = []struct {
 			OpRangeTypeMin, nil, nil,
 		),
 	},
+	{
+		in: `count_distinct_over_time({app="foo"} | unwrap user [5m]) by (namespace)`,
+		exp: newRangeAggregationExpr(
+			newLogRange(
+				newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+				5*time.Minute,
+				newUnwrapExpr("user", ""),
+				nil),
+			OpRangeTypeCountDistinct, &Grouping{Groups: []string{"namespace"}}, nil,
+		),
+	},
+	{
+		in:  `count_distinct_over_time({app="foo"} | unwrap bytes(user) [5m])`,
+		exp: nil,
+		err: logqlmodel.NewParseError("conversion function bytes not supported for count_distinct_over_time", 0, 0),
+	},
+	{
+		in:  `count_distinct_over_time({app="foo"} [5m])`,
+		exp: nil,
+		err: logqlmodel.NewParseError("invalid aggregation count_distinct_over_time without unwrap", 0, 0),
+	},
 	{
 		in: `min_over_time({app="foo"} | unwrap bar [5m]) by ()`,
 		exp: newRangeAggregationExpr(
@@ -2893,7 +2914,7 @@ var ParseTestCases = []struct {
 	},
 	{
 		in:  `quantile_over_time(foo,{namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap latency [5m])`,
-		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or { or (", 1, 20),
+		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 20),
 	},
 	{
 		in:  `vector(abc)`,
@@ -3340,6 +3361,257 @@ var ParseTestCases = []struct {
 		},
 		err: nil,
 	},
+	{
+		in: `max_over_time(sum(rate({foo="bar"}[5m]))[1h:1m])`,
+		exp: newSubqueryAggregationExpr(
+			newSubqueryExpr(
+				mustNewVectorAggregationExpr(
+					newRangeAggregationExpr(
+						&LogRangeExpr{
+							Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+							Interval: 5 * time.Minute,
+						}, OpRangeTypeRate, nil, nil),
+					OpTypeSum, nil, nil,
+				),
+				subqueryRange{Range: time.Hour, Step: time.Minute},
+				nil,
+			), OpRangeTypeMax, nil),
+	},
+	{
+		in: `quantile_over_time(0.99, rate({foo="bar"}[5m])[1h:5m] offset 10m)`,
+		exp: newSubqueryAggregationExpr(
+			newSubqueryExpr(
+				newRangeAggregationExpr(
+					&LogRangeExpr{
+						Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+						Interval: 5 * time.Minute,
+					}, OpRangeTypeRate, nil, nil),
+				subqueryRange{Range: time.Hour, Step: 5 * time.Minute},
+				newOffsetExpr(10*time.Minute),
+			), OpRangeTypeQuantile, NewStringLabelFilter("0.99")),
+	},
+	{
+		in: `avg_over_time((rate({foo="bar"}[5m]) / rate({foo="baz"}[5m]))[1h:1m])`,
+		exp: newSubqueryAggregationExpr(
+			newSubqueryExpr(
+				mustNewBinOpExpr(OpTypeDiv, &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}},
+					newRangeAggregationExpr(
+						&LogRangeExpr{
+							Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+							Interval: 5 * time.Minute,
+						}, OpRangeTypeRate, nil, nil),
+					newRangeAggregationExpr(
+						&LogRangeExpr{
+							Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "baz")}),
+							Interval: 5 * time.Minute,
+						}, OpRangeTypeRate, nil, nil),
+				),
+				subqueryRange{Range: time.Hour, Step: time.Minute},
+				nil,
+			), OpRangeTypeAvg, nil),
+	},
+	{
+		in:  `rate(rate({foo="bar"}[5m])[1h:1m])`,
+		err: logqlmodel.NewParseError("invalid aggregation rate over subquery", 0, 0),
+	},
+	{
+		in:  `quantile_over_time(rate({foo="bar"}[5m])[1h:1m])`,
+		err: logqlmodel.NewParseError("parameter required for operation quantile_over_time", 0, 0),
+	},
+	{
+		in:  `max_over_time(rate({foo="bar"}[5m])[1h:])`,
+		err: logqlmodel.NewParseError("missing step in subquery range", 0, 36),
+	},
+	{
+		in:  `max_over_time(rate({foo="bar"}[5m])[1h:0s])`,
+		err: logqlmodel.NewParseError("subquery step must be greater than zero", 0, 36),
+	},
+	{
+		in:  `rate({foo="bar"}[5m])[1h:1m]`,
+		err: logqlmodel.NewParseError("syntax error: unexpected SUBQUERY_RANGE", 0, 22),
+	},
+	{
+		in: `abs(rate({foo="bar"}[5m]))`,
+		exp: newFunctionExpr(
+			newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+				}, OpRangeTypeRate, nil, nil),
+			OpFuncAbs, nil),
+	},
+	{
+		in: `clamp_min(sum(rate({foo="bar"}[5m])), -1)`,
+		exp: newFunctionExpr(
+			mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					&LogRangeExpr{
+						Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+						Interval: 5 * time.Minute,
+					}, OpRangeTypeRate, nil, nil),
+				OpTypeSum, nil, nil,
+			),
+			OpFuncClampMin, mustNewLiteralExpr("1", true)),
+	},
+	{
+		in: `round(rate({foo="bar"}[5m]), 0.5)`,
+		exp: newFunctionExpr(
+			newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+				}, OpRangeTypeRate, nil, nil),
+			OpFuncRound, mustNewLiteralExpr("0.5", false)),
+	},
+	{
+		in: `timestamp(rate({foo="bar"}[5m]))`,
+		exp: newFunctionExpr(
+			newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+				}, OpRangeTypeRate, nil, nil),
+			OpFuncTimestamp, nil),
+	},
+	{
+		in:  `clamp_max(rate({foo="bar"}[5m]))`,
+		err: logqlmodel.NewParseError("parameter required for operation clamp_max", 0, 0),
+	},
+	{
+		in:  `sqrt(rate({foo="bar"}[5m]), 2)`,
+		err: logqlmodel.NewParseError("parameter 2 not supported for operation sqrt", 0, 0),
+	},
+	{
+		in:  `round(rate({foo="bar"}[5m]), 0)`,
+		err: logqlmodel.NewParseError("invalid parameter for operation round: must not be zero", 0, 0),
+	},
+	{
+		in: `label_join(rate({foo="bar"}[5m]), "foo", ",", "src1", "src2")`,
+		exp: mustNewLabelJoinExpr(
+			newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+				}, OpRangeTypeRate, nil, nil),
+			"foo", ",", []string{"src1", "src2"}),
+	},
+	{
+		in: `label_join(rate({foo="bar"}[5m]), "foo", ",")`,
+		exp: mustNewLabelJoinExpr(
+			newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+				}, OpRangeTypeRate, nil, nil),
+			"foo", ",", nil),
+	},
+	{
+		in:  `label_join(rate({foo="bar"}[5m]), "", ",", "src")`,
+		err: logqlmodel.NewParseError("invalid destination label name in label_join: ", 0, 0),
+	},
+	{
+		in: `increase({app="foo"} | logfmt | unwrap bytes_total [5m])`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				MultiStageExpr{newLogfmtParserExpr(nil)},
+			),
+				5*time.Minute,
+				newUnwrapExpr("bytes_total", ""),
+				nil),
+			OpRangeTypeIncrease, nil, nil,
+		),
+	},
+	{
+		in: `predict_linear(3600, {app="foo"} | unwrap free_bytes [1h])`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				time.Hour,
+				newUnwrapExpr("free_bytes", ""),
+				nil),
+			OpRangeTypePredictLinear, nil, NewStringLabelFilter("3600"),
+		),
+	},
+	{
+		in: `deriv(sum(rate({foo="bar"}[5m]))[1h:1m])`,
+		exp: newSubqueryAggregationExpr(
+			newSubqueryExpr(
+				mustNewVectorAggregationExpr(
+					newRangeAggregationExpr(
+						&LogRangeExpr{
+							Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+							Interval: 5 * time.Minute,
+						}, OpRangeTypeRate, nil, nil),
+					OpTypeSum, nil, nil,
+				),
+				subqueryRange{Range: time.Hour, Step: time.Minute},
+				nil,
+			), OpRangeTypeDeriv, nil),
+	},
+	{
+		in: `histogram_over_time({app="foo"} | json | unwrap duration(latency) [5m]) by (namespace)`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				MultiStageExpr{newLabelParserExpr(OpParserTypeJSON, "")},
+			),
+				5*time.Minute,
+				newUnwrapExpr("latency", OpConvDuration),
+				nil),
+			OpRangeTypeHistogram, &Grouping{Groups: []string{"namespace"}}, nil,
+		),
+	},
+	{
+		in: `histogram_quantile(0.99, sum by (le) (histogram_over_time({app="foo"} | unwrap latency [5m])))`,
+		exp: newFunctionExpr(
+			mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+						5*time.Minute,
+						newUnwrapExpr("latency", ""),
+						nil),
+					OpRangeTypeHistogram, nil, nil),
+				OpTypeSum, &Grouping{Groups: []string{"le"}}, nil,
+			),
+			OpFuncHistogramQuantile, mustNewLiteralExpr("0.99", false)),
+	},
+	{
+		in:  `histogram_over_time({app="foo"}[5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation histogram_over_time without unwrap", 0, 0),
+	},
+	{
+		in:  `changes({app="foo"}[5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation changes without unwrap", 0, 0),
+	},
+	{
+		in:  `resets({app="foo"} | unwrap restarts [5m]) by (app)`,
+		err: logqlmodel.NewParseError("grouping not allowed for resets aggregation", 0, 0),
+	},
+	{
+		in:  `predict_linear({app="foo"} | unwrap free_bytes [1h])`,
+		err: logqlmodel.NewParseError("parameter required for operation predict_linear", 0, 0),
+	},
+	{
+		in:  `delta(0.5, {app="foo"} | unwrap temperature [1h])`,
+		err: logqlmodel.NewParseError("parameter 0.5 not supported for operation delta", 0, 0),
+	},
+	{
+		in:  `time()`,
+		exp: &TimeExpr{},
+	},
+	{
+		in: `time() - timestamp(rate({foo="bar"}[5m]))`,
+		exp: mustNewBinOpExpr(OpTypeSub, &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}},
+			&TimeExpr{},
+			newFunctionExpr(
+				newRangeAggregationExpr(
+					&LogRangeExpr{
+						Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+						Interval: 5 * time.Minute,
+					}, OpRangeTypeRate, nil, nil),
+				OpFuncTimestamp, nil),
+		),
+	},
 }
 
 func TestParse(t *testing.T) {
@@ -226,6 +226,51 @@ func (e *RangeAggregationExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: sum(rate({foo="bar"}[5m]))[1h:1m]
+func (e *SubqueryExpr) Pretty(level int) string {
+	s := Indent(level)
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	// binary operations bind weaker than the subquery range.
+	if _, ok := e.Left.(*BinOpExpr); ok {
+		s += "(\n" + e.Left.Pretty(level+1) + "\n" + Indent(level) + ")"
+	} else {
+		s = e.Left.Pretty(level)
+	}
+
+	s = fmt.Sprintf("%s[%s:%s]", s, model.Duration(e.Range), model.Duration(e.Step))
+
+	if e.Offset != 0 {
+		oe := OffsetExpr{Offset: e.Offset}
+		s += oe.Pretty(level)
+	}
+
+	return s
+}
+
+// e.g: max_over_time(sum(rate({foo="bar"}[5m]))[1h:1m])
+func (e *SubqueryAggregationExpr) Pretty(level int) string {
+	s := Indent(level)
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation + "(\n"
+
+	if e.Params != nil {
+		s = fmt.Sprintf("%s%s%s,", s, Indent(level+1), fmt.Sprint(*e.Params))
+		s += "\n"
+	}
+
+	s += e.Left.Pretty(level + 1)
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
 // e.g:
 // sum(count_over_time({foo="bar"}[5m])) by (container)
 // topk(10, count_over_time({foo="bar"}[5m])) by (container)
@@ -338,11 +383,80 @@ func (e *LabelReplaceExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: label_join(rate({job="api-server"}[5m]), "foo", ",", "job", "service")
+func (e *LabelJoinExpr) Pretty(level int) string {
+	s := Indent(level)
+
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += OpLabelJoin
+
+	s += "(\n"
+
+	params := []string{
+		e.Left.Pretty(level + 1),
+		Indent(level+1) + strconv.Quote(e.Dst),
+		Indent(level+1) + strconv.Quote(e.Separator),
+	}
+	for _, src := range e.Src {
+		params = append(params, Indent(level+1)+strconv.Quote(src))
+	}
+
+	for i, v := range params {
+		s += v
+		// LogQL doesn't allow `,` at the end of last argument.
+		if i < len(params)-1 {
+			s += ","
+		}
+		s += "\n"
+	}
+
+	s += Indent(level) + ")"
+
+	return s
+}
+
+// e.g: clamp_min(rate({job="api-server"}[5m]), 1)
+// e.g: histogram_quantile(0.99, histogram_over_time({job="api-server"} | unwrap latency [5m]))
+func (e *FunctionExpr) Pretty(level int) string {
+	s := Indent(level)
+
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation + "(\n"
+
+	if e.Operation == OpFuncHistogramQuantile {
+		s += fmt.Sprintf("%s%s,\n", Indent(level+1), fmt.Sprint(*e.Params))
+		s += e.Left.Pretty(level + 1)
+		s += "\n" + Indent(level) + ")"
+		return s
+	}
+
+	s += e.Left.Pretty(level + 1)
+
+	if e.Params != nil {
+		s = fmt.Sprintf("%s,\n%s%s", s, Indent(level+1), fmt.Sprint(*e.Params))
+	}
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
 // e.g: vector(5)
 func (e *VectorExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
 
+// e.g: time()
+func (e *TimeExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
 // Grouping is technically not expression type. But used in both range and vector aggregations (`by` and `without` clause)
 // So by implenting `Pretty` for Grouping, we can re use it for both.
 // NOTE: indent is ignored for `Grouping`, because grouping always stays in the same line of it's parent expression.
@@ -86,6 +86,59 @@ func TestFormat(t *testing.T) {
     != "memcached"
     |= ip("192.168.0.1")
     | logfmt [1m]
+)`,
+		},
+		{
+			name: "function",
+			in:   `clamp_max(sum(rate({job="loki"}|logfmt[1m])), 100)`,
+			exp: `clamp_max(
+  sum(
+    rate(
+      {job="loki"}
+        | logfmt [1m]
+    )
+  ),
+  100
+)`,
+		},
+		{
+			name: "histogram_quantile",
+			in:   `histogram_quantile(0.99, sum by (le) (histogram_over_time({job="loki"}|logfmt|unwrap latency[1m])))`,
+			exp: `histogram_quantile(
+  0.99,
+  sum by (le)(
+    histogram_over_time(
+      {job="loki"}
+        | logfmt
+        | unwrap latency [1m]
+    )
+  )
+)`,
+		},
+		{
+			name: "label_join",
+			in:   `label_join(rate({job="loki"}|logfmt[1m]), "dst", "-", "job", "instance")`,
+			exp: `label_join(
+  rate(
+    {job="loki"}
+      | logfmt [1m]
+  ),
+  "dst",
+  "-",
+  "job",
+  "instance"
+)`,
+		},
+		{
+			name: "subquery",
+			in:   `max_over_time(sum(rate({job="loki"}|logfmt[1m]))[1h:1m] offset 5m)`,
+			exp: `max_over_time(
+  sum(
+    rate(
+      {job="loki"}
+        | logfmt [1m]
+    )
+  )[1h:1m] offset 5m
 )`,
 		},
 		{
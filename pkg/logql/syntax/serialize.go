@@ -38,6 +38,7 @@ const (
 	Card                = "cardinality"
 	Dst                 = "dst"
 	Duration            = "duration"
+	Function            = "function"
 	Groups              = "groups"
 	GroupingField       = "grouping"
 	Include             = "include"
@@ -46,6 +47,7 @@ const (
 	IntervalNanos       = "interval_nanos"
 	IPField             = "ip"
 	Label               = "label"
+	LabelJoin           = "label_join"
 	LabelReplace        = "label_replace"
 	LHS                 = "lhs"
 	Literal             = "literal"
@@ -67,9 +69,14 @@ const (
 	Replacement         = "replacement"
 	ReturnBool          = "return_bool"
 	RHS                 = "rhs"
+	Separator           = "separator"
 	Src                 = "src"
+	StepNanos           = "step_nanos"
 	StringField         = "string"
+	Subquery            = "subquery"
+	SubqueryAgg         = "subquery_agg"
 	NoopField           = "noop"
+	Time                = "time"
 	Type                = "type"
 	Unwrap              = "unwrap"
 	Value               = "value"
@@ -92,12 +99,20 @@ func DecodeJSON(raw string) (Expr, error) {
 		return decodeVectorAgg(iter)
 	case RangeAgg:
 		return decodeRangeAgg(iter)
+	case SubqueryAgg:
+		return decodeSubqueryAgg(iter)
 	case Literal:
 		return decodeLiteral(iter)
 	case Vector:
 		return decodeVector(iter)
 	case LabelReplace:
 		return decodeLabelReplace(iter)
+	case LabelJoin:
+		return decodeLabelJoin(iter)
+	case Function:
+		return decodeFunction(iter)
+	case Time:
+		return decodeTime(iter)
 	case LogSelector:
 		return decodeLogSelector(iter)
 	case Variants:
@@ -232,6 +247,50 @@ func (v *JSONSerializer) VisitLogRange(e *LogRangeExpr) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitSubqueryAggregation(e *SubqueryAggregationExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(SubqueryAgg)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Op)
+	v.WriteString(e.Operation)
+
+	if e.Params != nil {
+		v.WriteMore()
+		v.WriteObjectField(Params)
+		v.WriteFloat64(*e.Params)
+	}
+
+	v.WriteMore()
+	v.WriteObjectField(Subquery)
+	v.VisitSubquery(e.Left)
+	v.WriteObjectEnd()
+
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
+func (v *JSONSerializer) VisitSubquery(e *SubqueryExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(IntervalNanos)
+	v.WriteInt64(int64(e.Range))
+	v.WriteMore()
+	v.WriteObjectField(StepNanos)
+	v.WriteInt64(int64(e.Step))
+	v.WriteMore()
+	v.WriteObjectField(OffsetNanos)
+	v.WriteInt64(int64(e.Offset))
+
+	v.WriteMore()
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 func (v *JSONSerializer) VisitLabelReplace(e *LabelReplaceExpr) {
 	v.WriteObjectStart()
 
@@ -262,6 +321,74 @@ func (v *JSONSerializer) VisitLabelReplace(e *LabelReplaceExpr) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitLabelJoin(e *LabelJoinExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(LabelJoin)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteMore()
+	v.WriteObjectField(Dst)
+	v.WriteString(e.Dst)
+
+	v.WriteMore()
+	v.WriteObjectField(Separator)
+	v.WriteString(e.Separator)
+
+	v.WriteMore()
+	v.WriteObjectField(Src)
+	v.WriteArrayStart()
+	for i, src := range e.Src {
+		if i > 0 {
+			v.WriteMore()
+		}
+		v.WriteString(src)
+	}
+	v.WriteArrayEnd()
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
+func (v *JSONSerializer) VisitFunction(e *FunctionExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Function)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Op)
+	v.WriteString(e.Operation)
+
+	if e.Params != nil {
+		v.WriteMore()
+		v.WriteObjectField(Params)
+		v.WriteFloat64(*e.Params)
+	}
+
+	v.WriteMore()
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
+func (v *JSONSerializer) VisitTime(_ *TimeExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Time)
+	v.WriteObjectStart()
+	v.WriteObjectEnd()
+
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 func (v *JSONSerializer) VisitLiteral(e *LiteralExpr) {
 	v.WriteObjectStart()
 
@@ -742,12 +869,20 @@ func decodeSample(iter *jsoniter.Iterator) (SampleExpr, error) {
 			expr, err = decodeVectorAgg(iter)
 		case RangeAgg:
 			expr, err = decodeRangeAgg(iter)
+		case SubqueryAgg:
+			expr, err = decodeSubqueryAgg(iter)
 		case Literal:
 			expr, err = decodeLiteral(iter)
 		case Vector:
 			expr, err = decodeVector(iter)
 		case LabelReplace:
 			expr, err = decodeLabelReplace(iter)
+		case LabelJoin:
+			expr, err = decodeLabelJoin(iter)
+		case Function:
+			expr, err = decodeFunction(iter)
+		case Time:
+			expr, err = decodeTime(iter)
 		default:
 			return nil, fmt.Errorf("unknown sample expression type: %s", key)
 		}
@@ -909,6 +1044,45 @@ func decodeLogRange(iter *jsoniter.Iterator) (*LogRangeExpr, error) {
 	return expr, err
 }
 
+func decodeSubqueryAgg(iter *jsoniter.Iterator) (*SubqueryAggregationExpr, error) {
+	expr := &SubqueryAggregationExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Op:
+			expr.Operation = iter.ReadString()
+		case Params:
+			tmp := iter.ReadFloat64()
+			expr.Params = &tmp
+		case Subquery:
+			expr.Left, err = decodeSubquery(iter)
+		}
+	}
+
+	return expr, err
+}
+
+func decodeSubquery(iter *jsoniter.Iterator) (*SubqueryExpr, error) {
+	expr := &SubqueryExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case IntervalNanos:
+			expr.Range = time.Duration(iter.ReadInt64())
+		case StepNanos:
+			expr.Step = time.Duration(iter.ReadInt64())
+		case OffsetNanos:
+			expr.Offset = time.Duration(iter.ReadInt64())
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+		}
+	}
+
+	return expr, err
+}
+
 func decodeLabelReplace(iter *jsoniter.Iterator) (*LabelReplaceExpr, error) {
 	var err error
 	var left SampleExpr
@@ -935,6 +1109,60 @@ func decodeLabelReplace(iter *jsoniter.Iterator) (*LabelReplaceExpr, error) {
 	return mustNewLabelReplaceExpr(left, dst, replacement, src, regex), nil
 }
 
+func decodeLabelJoin(iter *jsoniter.Iterator) (*LabelJoinExpr, error) {
+	var err error
+	var left SampleExpr
+	var dst, separator string
+	var src []string
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Inner:
+			left, err = decodeSample(iter)
+			if err != nil {
+				return nil, err
+			}
+		case Dst:
+			dst = iter.ReadString()
+		case Separator:
+			separator = iter.ReadString()
+		case Src:
+			iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+				src = append(src, iter.ReadString())
+				return true
+			})
+		}
+	}
+
+	return mustNewLabelJoinExpr(left, dst, separator, src), nil
+}
+
+func decodeFunction(iter *jsoniter.Iterator) (*FunctionExpr, error) {
+	expr := &FunctionExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Op:
+			expr.Operation = iter.ReadString()
+		case Params:
+			tmp := iter.ReadFloat64()
+			expr.Params = &tmp
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+		}
+	}
+
+	return expr, err
+}
+
+func decodeTime(iter *jsoniter.Iterator) (*TimeExpr, error) {
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		iter.Skip()
+	}
+	return &TimeExpr{}, nil
+}
+
 func decodeLiteral(iter *jsoniter.Iterator) (*LiteralExpr, error) {
 	expr := &LiteralExpr{}
 
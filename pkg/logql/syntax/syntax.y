@@ -43,24 +43,26 @@ import (
   labelExtractionExpressionList []log.LabelExtractionExpr
   unwrapExpr *UnwrapExpr
   offsetExpr *OffsetExpr
+  subqueryRange subqueryRange
+  subqueryExpr *SubqueryExpr
 }
 
 %start root
 
 %type <expr> expr
 %type <logExpr> logExpr
-%type <metricExpr> metricExpr rangeAggregationExpr vectorAggregationExpr binOpExpr labelReplaceExpr vectorExpr
+%type <metricExpr> metricExpr rangeAggregationExpr vectorAggregationExpr binOpExpr labelReplaceExpr vectorExpr functionExpr labelJoinExpr timeExpr
 %type <variantsExpr> variantsExpr
 %type <stage> pipelineStage logfmtParser labelParser jsonExpressionParser logfmtExpressionParser lineFormatExpr decolorizeExpr labelFormatExpr dropLabelsExpr keepLabelsExpr
 %type <stages> pipelineExpr
 %type <lineFilterExpr> lineFilter lineFilters orFilter
-%type <op> rangeOp convOp vectorOp filterOp
+%type <op> rangeOp convOp vectorOp filterOp functionOp
 %type <filterer> bytesFilter numberFilter durationFilter labelFilter unitFilter ipLabelFilter
 %type <filter> filter
 %type <matcher> matcher
 %type <matchers> matchers selector
 %type <str> vector
-%type <strs> labels parserFlags
+%type <strs> labels parserFlags stringList
 %type <binOpts> binOpModifier boolModifier onOrIgnoringModifier
 %type <namedMatcher> namedMatcher
 %type <namedMatchers> namedMatchers
@@ -73,18 +75,22 @@ import (
 %type <labelExtractionExpressionList> labelExtractionExpressionList
 %type <unwrapExpr> unwrapExpr
 %type <offsetExpr> offsetExpr
+%type <subqueryExpr> subqueryExpr
 %type <metricExprs> metricExprs
 
 %token <bytes> BYTES
 %token <str> IDENTIFIER STRING NUMBER FUNCTION_FLAG
 %token <dur> DURATION RANGE
+%token <subqueryRange> SUBQUERY_RANGE
 %token <val> MATCHERS LABELS EQ RE NRE NPA OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT PIPE_PATTERN
              OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM SORT SORT_DESC AVG
              MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK APPROX_TOPK
              BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
              MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
              FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
-             DECOLORIZE DROP KEEP VARIANTS OF
+             DECOLORIZE DROP KEEP VARIANTS OF ABS CEIL FLOOR ROUND CLAMP_MIN CLAMP_MAX LN EXP SQRT TIMESTAMP TIME LABEL_JOIN
+             INCREASE DELTA DERIV CHANGES RESETS PREDICT_LINEAR COUNT_DISTINCT_OVER_TIME
+             HISTOGRAM_OVER_TIME HISTOGRAM_QUANTILE
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -118,6 +124,9 @@ metricExpr:
     | literalExpr                                   { $$ = $1 }
     | labelReplaceExpr                              { $$ = $1 }
     | vectorExpr                                    { $$ = $1 }
+    | functionExpr                                  { $$ = $1 }
+    | labelJoinExpr                                 { $$ = $1 }
+    | timeExpr                                      { $$ = $1 }
     | OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS { $$ = $2 }
     ;
 
@@ -171,6 +180,14 @@ rangeAggregationExpr:
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS           { $$ = newRangeAggregationExpr($5, $1, nil, &$3) }
     | rangeOp OPEN_PARENTHESIS logRangeExpr CLOSE_PARENTHESIS grouping               { $$ = newRangeAggregationExpr($3, $1, $5, nil) }
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($5, $1, $7, &$3) }
+    | rangeOp OPEN_PARENTHESIS subqueryExpr CLOSE_PARENTHESIS                        { $$ = newSubqueryAggregationExpr($3, $1, nil) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA subqueryExpr CLOSE_PARENTHESIS           { $$ = newSubqueryAggregationExpr($5, $1, &$3) }
+    ;
+
+subqueryExpr:
+      metricExpr SUBQUERY_RANGE                                                      { $$ = newSubqueryExpr($1, $2, nil) }
+    | metricExpr SUBQUERY_RANGE offsetExpr                                           { $$ = newSubqueryExpr($1, $2, $3) }
+    | OPEN_PARENTHESIS subqueryExpr CLOSE_PARENTHESIS                                { $$ = $2 }
     ;
 
 vectorAggregationExpr:
@@ -189,6 +206,26 @@ labelReplaceExpr:
       { $$ = mustNewLabelReplaceExpr($3, $5, $7, $9, $11)}
     ;
 
+labelJoinExpr:
+      LABEL_JOIN OPEN_PARENTHESIS metricExpr COMMA STRING COMMA STRING CLOSE_PARENTHESIS                   { $$ = mustNewLabelJoinExpr($3, $5, $7, nil) }
+    | LABEL_JOIN OPEN_PARENTHESIS metricExpr COMMA STRING COMMA STRING COMMA stringList CLOSE_PARENTHESIS  { $$ = mustNewLabelJoinExpr($3, $5, $7, $9) }
+    ;
+
+stringList:
+      STRING                    { $$ = []string{ $1 } }
+    | stringList COMMA STRING   { $$ = append($1, $3) }
+    ;
+
+functionExpr:
+      functionOp OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS                    { $$ = newFunctionExpr($3, $1, nil) }
+    | functionOp OPEN_PARENTHESIS metricExpr COMMA literalExpr CLOSE_PARENTHESIS  { $$ = newFunctionExpr($3, $1, $5) }
+    | HISTOGRAM_QUANTILE OPEN_PARENTHESIS literalExpr COMMA metricExpr CLOSE_PARENTHESIS  { $$ = newFunctionExpr($5, OpFuncHistogramQuantile, $3) }
+    ;
+
+timeExpr:
+    TIME OPEN_PARENTHESIS CLOSE_PARENTHESIS  { $$ = &TimeExpr{} }
+    ;
+
 selector:
       OPEN_BRACE matchers CLOSE_BRACE  { $$ = $2 }
     | OPEN_BRACE matchers error        { $$ = $2 }
@@ -474,6 +511,19 @@ vector:
     VECTOR  { $$ = OpTypeVector }
     ;
 
+functionOp:
+      ABS        { $$ = OpFuncAbs }
+    | CEIL       { $$ = OpFuncCeil }
+    | FLOOR      { $$ = OpFuncFloor }
+    | ROUND      { $$ = OpFuncRound }
+    | CLAMP_MIN  { $$ = OpFuncClampMin }
+    | CLAMP_MAX  { $$ = OpFuncClampMax }
+    | LN         { $$ = OpFuncLn }
+    | EXP        { $$ = OpFuncExp }
+    | SQRT       { $$ = OpFuncSqrt }
+    | TIMESTAMP  { $$ = OpFuncTimestamp }
+    ;
+
 vectorOp:
         SUM     { $$ = OpTypeSum }
       | AVG     { $$ = OpTypeAvg }
@@ -505,6 +555,14 @@ rangeOp:
     | FIRST_OVER_TIME    { $$ = OpRangeTypeFirst }
     | LAST_OVER_TIME     { $$ = OpRangeTypeLast }
     | ABSENT_OVER_TIME   { $$ = OpRangeTypeAbsent }
+    | INCREASE           { $$ = OpRangeTypeIncrease }
+    | DELTA              { $$ = OpRangeTypeDelta }
+    | DERIV              { $$ = OpRangeTypeDeriv }
+    | CHANGES            { $$ = OpRangeTypeChanges }
+    | RESETS             { $$ = OpRangeTypeResets }
+    | PREDICT_LINEAR     { $$ = OpRangeTypePredictLinear }
+    | COUNT_DISTINCT_OVER_TIME { $$ = OpRangeTypeCountDistinct }
+    | HISTOGRAM_OVER_TIME      { $$ = OpRangeTypeHistogram }
     ;
 
 offsetExpr:
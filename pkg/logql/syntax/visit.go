@@ -11,15 +11,20 @@ type RootVisitor interface {
 	VariantsExprVisitor
 
 	VisitLogRange(*LogRangeExpr)
+	VisitSubquery(*SubqueryExpr)
 }
 
 type SampleExprVisitor interface {
 	VisitBinOp(*BinOpExpr)
 	VisitVectorAggregation(*VectorAggregationExpr)
 	VisitRangeAggregation(*RangeAggregationExpr)
+	VisitSubqueryAggregation(*SubqueryAggregationExpr)
 	VisitLabelReplace(*LabelReplaceExpr)
+	VisitLabelJoin(*LabelJoinExpr)
+	VisitFunction(*FunctionExpr)
 	VisitLiteral(*LiteralExpr)
 	VisitVector(*VectorExpr)
+	VisitTime(*TimeExpr)
 }
 
 type LogSelectorExprVisitor interface {
@@ -27,6 +32,7 @@ type LogSelectorExprVisitor interface {
 	VisitPipeline(*PipelineExpr)
 	VisitLiteral(*LiteralExpr)
 	VisitVector(*VectorExpr)
+	VisitTime(*TimeExpr)
 }
 
 type StageExprVisitor interface {
@@ -53,10 +59,12 @@ type DepthFirstTraversal struct {
 	VisitBinOpFn                  func(v RootVisitor, e *BinOpExpr)
 	VisitDecolorizeFn             func(v RootVisitor, e *DecolorizeExpr)
 	VisitDropLabelsFn             func(v RootVisitor, e *DropLabelsExpr)
+	VisitFunctionFn               func(v RootVisitor, e *FunctionExpr)
 	VisitJSONExpressionParserFn   func(v RootVisitor, e *JSONExpressionParserExpr)
 	VisitKeepLabelFn              func(v RootVisitor, e *KeepLabelsExpr)
 	VisitLabelFilterFn            func(v RootVisitor, e *LabelFilterExpr)
 	VisitLabelFmtFn               func(v RootVisitor, e *LabelFmtExpr)
+	VisitLabelJoinFn              func(v RootVisitor, e *LabelJoinExpr)
 	VisitLabelParserFn            func(v RootVisitor, e *LineParserExpr)
 	VisitLabelReplaceFn           func(v RootVisitor, e *LabelReplaceExpr)
 	VisitLineFilterFn             func(v RootVisitor, e *LineFilterExpr)
@@ -68,6 +76,9 @@ type DepthFirstTraversal struct {
 	VisitMatchersFn               func(v RootVisitor, e *MatchersExpr)
 	VisitPipelineFn               func(v RootVisitor, e *PipelineExpr)
 	VisitRangeAggregationFn       func(v RootVisitor, e *RangeAggregationExpr)
+	VisitSubqueryFn               func(v RootVisitor, e *SubqueryExpr)
+	VisitSubqueryAggregationFn    func(v RootVisitor, e *SubqueryAggregationExpr)
+	VisitTimeFn                   func(v RootVisitor, e *TimeExpr)
 	VisitVectorFn                 func(v RootVisitor, e *VectorExpr)
 	VisitVectorAggregationFn      func(v RootVisitor, e *VectorAggregationExpr)
 	VisitVariantsFn               func(v RootVisitor, e *MultiVariantExpr)
@@ -166,6 +177,30 @@ func (v *DepthFirstTraversal) VisitLabelReplace(e *LabelReplaceExpr) {
 	}
 }
 
+// VisitLabelJoin implements RootVisitor.
+func (v *DepthFirstTraversal) VisitLabelJoin(e *LabelJoinExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitLabelJoinFn != nil {
+		v.VisitLabelJoinFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
+// VisitFunction implements RootVisitor.
+func (v *DepthFirstTraversal) VisitFunction(e *FunctionExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitFunctionFn != nil {
+		v.VisitFunctionFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
 // VisitLineFilter implements RootVisitor.
 func (v *DepthFirstTraversal) VisitLineFilter(e *LineFilterExpr) {
 	if e == nil {
@@ -272,6 +307,30 @@ func (v *DepthFirstTraversal) VisitRangeAggregation(e *RangeAggregationExpr) {
 	}
 }
 
+// VisitSubquery implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSubquery(e *SubqueryExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSubqueryFn != nil {
+		v.VisitSubqueryFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
+// VisitSubqueryAggregation implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSubqueryAggregation(e *SubqueryAggregationExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSubqueryAggregationFn != nil {
+		v.VisitSubqueryAggregationFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
 // VisitVector implements RootVisitor.
 func (v *DepthFirstTraversal) VisitVector(e *VectorExpr) {
 	if e == nil {
@@ -282,6 +341,16 @@ func (v *DepthFirstTraversal) VisitVector(e *VectorExpr) {
 	}
 }
 
+// VisitTime implements RootVisitor.
+func (v *DepthFirstTraversal) VisitTime(e *TimeExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitTimeFn != nil {
+		v.VisitTimeFn(v, e)
+	}
+}
+
 // VisitVectorAggregation implements RootVisitor.
 func (v *DepthFirstTraversal) VisitVectorAggregation(e *VectorAggregationExpr) {
 	if e == nil {
@@ -51,6 +51,11 @@ type Context struct {
 	// result accumulates results for JoinResult.
 	result Result
 
+	// pagesPruned is the number of data object pages skipped because of
+	// predicates. It is not part of the Result and only reported by the
+	// EXPLAIN ANALYZE of the next generation query engine.
+	pagesPruned int64
+
 	mtx sync.Mutex
 }
 
@@ -140,6 +145,7 @@ func (c *Context) Reset() {
 	c.result.Reset()
 	c.caches.Reset()
 	c.index.Reset()
+	atomic.StoreInt64(&c.pagesPruned, 0)
 }
 
 // Result calculates the summary based on store and ingester data.
@@ -565,6 +571,15 @@ func (c *Context) AddPagesDownloadedBytes(i int64) {
 	atomic.AddInt64(&c.store.Dataobj.PagesDownloadedBytes, i)
 }
 
+func (c *Context) AddPagesPruned(i int64) {
+	atomic.AddInt64(&c.pagesPruned, i)
+}
+
+// PagesPruned returns the number of data object pages pruned so far.
+func (c *Context) PagesPruned() int64 {
+	return atomic.LoadInt64(&c.pagesPruned)
+}
+
 func (c *Context) AddPageBatches(i int64) {
 	atomic.AddInt64(&c.store.Dataobj.PageBatches, i)
 }
@@ -55,11 +55,14 @@ import (
 	"github.com/grafana/loki/v3/pkg/compactor/client/grpc"
 	"github.com/grafana/loki/v3/pkg/compactor/deletion"
 	"github.com/grafana/loki/v3/pkg/compactor/generationnumber"
+	dataobjcache "github.com/grafana/loki/v3/pkg/dataobj/cache"
+	dataobjcompactor "github.com/grafana/loki/v3/pkg/dataobj/compactor"
 	"github.com/grafana/loki/v3/pkg/dataobj/consumer"
 	"github.com/grafana/loki/v3/pkg/dataobj/explorer"
 	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
 	dataobjquerier "github.com/grafana/loki/v3/pkg/dataobj/querier"
 	"github.com/grafana/loki/v3/pkg/distributor"
+	"github.com/grafana/loki/v3/pkg/engine"
 	"github.com/grafana/loki/v3/pkg/indexgateway"
 	"github.com/grafana/loki/v3/pkg/ingester"
 	"github.com/grafana/loki/v3/pkg/kafka/partition"
@@ -159,6 +162,7 @@ const (
 	BlockScheduler           = "block-scheduler"
 	DataObjExplorer          = "dataobj-explorer"
 	DataObjConsumer          = "dataobj-consumer"
+	DataObjCompactor         = "dataobj-compactor"
 	UI                       = "ui"
 	All                      = "all"
 	Read                     = "read"
@@ -548,10 +552,14 @@ func (t *Loki) getQuerierStore() (querier.Store, error) {
 	if err != nil {
 		return nil, err
 	}
+	cachedStore, err := t.withDataObjCache(store)
+	if err != nil {
+		return nil, err
+	}
 
 	storeCombiner := querier.NewStoreCombiner([]querier.StoreConfig{
 		{
-			Store: dataobjquerier.NewStore(store, log.With(util_log.Logger, "component", "dataobj-querier"), metastore.NewObjectMetastore(store)),
+			Store: dataobjquerier.NewStore(cachedStore, log.With(util_log.Logger, "component", "dataobj-querier"), metastore.NewObjectMetastore(store)),
 			From:  t.Cfg.DataObj.Querier.From.Time,
 		},
 		{
@@ -617,16 +625,24 @@ func (t *Loki) initQuerier() (services.Service, error) {
 		serverutil.ResponseJSONMiddleware(),
 	}
 
-	var ms metastore.Metastore
-	if t.Cfg.Querier.Engine.EnableV2Engine {
-		store, err := t.createDataObjBucket("dataobj-querier")
+	var (
+		ms    metastore.Metastore
+		store objstore.Bucket
+	)
+	if t.Cfg.Querier.Engine.EnableV2Engine || t.Cfg.Querier.Engine.EnableV2EngineShadowMode {
+		var err error
+		store, err = t.createDataObjBucket("dataobj-querier")
 		if err != nil {
 			return nil, err
 		}
 		ms = metastore.NewObjectMetastore(store)
+
+		if store, err = t.withDataObjCache(store); err != nil {
+			return nil, err
+		}
 	}
 
-	t.querierAPI = querier.NewQuerierAPI(t.Cfg.Querier, t.Querier, t.Overrides, ms, logger)
+	t.querierAPI = querier.NewQuerierAPI(t.Cfg.Querier, t.Querier, t.Overrides, ms, store, logger)
 
 	indexStatsHTTPMiddleware := querier.WrapQuerySpanAndTimeout("query.IndexStats", t.Overrides)
 	indexShardsHTTPMiddleware := querier.WrapQuerySpanAndTimeout("query.IndexShards", t.Overrides)
@@ -742,6 +758,16 @@ func (t *Loki) initQuerier() (services.Service, error) {
 	t.Server.HTTP.Path("/loki/api/v1/tail").Methods("GET", "POST").Handler(httpMiddleware.Wrap(http.HandlerFunc(tailQuerier.TailHandler)))
 	t.Server.HTTP.Path("/api/prom/tail").Methods("GET", "POST").Handler(httpMiddleware.Wrap(http.HandlerFunc(tailQuerier.TailHandler)))
 
+	// The explain endpoint executes queries with the next generation query engine directly on the querier, without
+	// splitting or sharding them, and like the tail routes it is always registered externally.
+	if t.Cfg.Querier.Engine.EnableV2Engine {
+		explainHandler := middleware.Merge(
+			httpMiddleware,
+			querier.WrapQuerySpanAndTimeout("query.Explain", t.Overrides),
+		).Wrap(http.HandlerFunc(t.querierAPI.ExplainHandler))
+		t.Server.HTTP.Path("/loki/api/v1/explain").Methods("GET", "POST").Handler(explainHandler)
+	}
+
 	internalMiddlewares := []queryrangebase.Middleware{
 		serverutil.RecoveryMiddleware,
 		queryrange.Instrument{Metrics: t.Metrics},
@@ -754,6 +780,10 @@ func (t *Loki) initQuerier() (services.Service, error) {
 		)
 	}
 	internalHandler := queryrangebase.MergeMiddlewares(internalMiddlewares...).Wrap(handler)
+	if t.Cfg.Querier.Engine.EnableV2Engine {
+		// Plan fragments of the v2 engine are sent to queriers by query-frontends with distributed execution enabled.
+		internalHandler = querier.NewFragmentHandler(internalHandler, t.querierAPI)
+	}
 
 	svc, err := querier.InitWorkerService(
 		logger,
@@ -1312,7 +1342,30 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		level.Debug(util_log.Logger).Log("msg", "no query frontend configured")
 	}
 
-	roundTripper := queryrange.NewSerializeRoundTripper(t.QueryFrontEndMiddleware.Wrap(frontendTripper), queryrange.DefaultCodec, t.Cfg.Frontend.SupportParquetEncoding)
+	var downstreamHandler queryrangebase.Handler = frontendTripper
+	if t.Cfg.Querier.Engine.EnableV2Engine && t.Cfg.Querier.Engine.EnableV2EngineDistributedExecution {
+		if frontendV2 == nil {
+			return nil, errors.New("distributed execution of the v2 engine requires the v2 query frontend")
+		}
+		store, err := t.createDataObjBucket("dataobj-query-frontend")
+		if err != nil {
+			return nil, err
+		}
+		ms := metastore.NewObjectMetastore(store)
+		// The statistics of data objects are read while planning queries.
+		if store, err = t.withDataObjCache(store); err != nil {
+			return nil, err
+		}
+		engineV2 := engine.New(t.Cfg.Querier.Engine, ms, store, t.Overrides, util_log.Logger)
+		engineV2.EnableDistributedExecution(frontendV2)
+		// The v2 engine takes the place of the queriers at the end of the
+		// tripperware, so that queries it executes are still subject to the
+		// limits, validation, splitting and caching of the query-frontend.
+		downstreamHandler = queryrange.NewEngineV2Middleware(engineV2, util_log.Logger).Wrap(downstreamHandler)
+	}
+	queryHandler := t.QueryFrontEndMiddleware.Wrap(downstreamHandler)
+
+	roundTripper := queryrange.NewSerializeRoundTripper(queryHandler, queryrange.DefaultCodec, t.Cfg.Frontend.SupportParquetEncoding)
 
 	frontendHandler := transport.NewHandler(t.Cfg.Frontend.Handler, roundTripper, util_log.Logger, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
 	if t.Cfg.Frontend.CompressResponses {
@@ -1395,6 +1448,10 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		// defer tail endpoints to the default handler
 		t.Server.HTTP.Path("/loki/api/v1/tail").Methods("GET", "POST").Handler(defaultHandler)
 		t.Server.HTTP.Path("/api/prom/tail").Methods("GET", "POST").Handler(defaultHandler)
+		// defer explain requests of the next generation query engine to the default handler
+		if t.Cfg.Querier.Engine.EnableV2Engine {
+			t.Server.HTTP.Path("/loki/api/v1/explain").Methods("GET", "POST").Handler(defaultHandler)
+		}
 	}
 
 	if t.frontend == nil {
@@ -2128,6 +2185,21 @@ func (t *Loki) initDataObjConsumer() (services.Service, error) {
 	return t.dataObjConsumer, nil
 }
 
+func (t *Loki) initDataObjCompactor() (services.Service, error) {
+	store, err := t.createDataObjBucket("dataobj-compactor")
+	if err != nil {
+		return nil, err
+	}
+
+	deleteRequestsClient, err := t.deleteRequestsClient("dataobj-compactor", t.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	level.Info(util_log.Logger).Log("msg", "initializing dataobj compactor")
+	return dataobjcompactor.New(t.Cfg.DataObj.Compactor, store, t.Cfg.Ingester.LifecyclerConfig.ID, t.Overrides, deleteRequestsClient, prometheus.DefaultRegisterer, util_log.Logger)
+}
+
 func (t *Loki) createDataObjBucket(clientName string) (objstore.Bucket, error) {
 	schema, err := t.Cfg.SchemaConfig.SchemaForTime(model.Now())
 	if err != nil {
@@ -2158,6 +2230,24 @@ func (t *Loki) createDataObjBucket(clientName string) (objstore.Bucket, error) {
 	return objstoreBucket, nil
 }
 
+// withDataObjCache wraps store to cache reads of data objects, if the dataobj
+// querier cache is enabled. The cache is shared by all buckets wrapped by
+// withDataObjCache. Metastore objects must not be read from the returned
+// bucket, as they're updated in place.
+func (t *Loki) withDataObjCache(store objstore.Bucket) (objstore.Bucket, error) {
+	if t.dataObjCache == nil {
+		c, err := dataobjcache.New(t.Cfg.DataObj.Querier.Cache, prometheus.DefaultRegisterer, util_log.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dataobj cache: %w", err)
+		}
+		if c == nil {
+			return store, nil
+		}
+		t.dataObjCache = c
+	}
+	return dataobjcache.NewBucket(store, t.dataObjCache, util_log.Logger), nil
+}
+
 func (t *Loki) deleteRequestsClient(clientType string, limits limiter.CombinedLimits) (deletion.DeleteRequestsClient, error) {
 	if !t.supportIndexDeleteRequest() || !t.Cfg.CompactorConfig.RetentionEnabled {
 		return deletion.NewNoOpDeleteRequestsClient(), nil
@@ -364,6 +364,9 @@ func minimalWorkingConfig(t *testing.T, dir, target string, cfgTransformers ...f
 	cfg.Server.GRPCListenPort = 0
 	cfg.Target = []string{target}
 
+	// Keep the ingester WAL out of the working directory.
+	cfg.Ingester.WAL.Dir = filepath.Join(dir, "wal")
+
 	// This would be overwritten by the default values setting.
 	cfg.StorageConfig = storage.Config{
 		FSConfig: local.FSConfig{Directory: dir},
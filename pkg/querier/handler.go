@@ -4,7 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 
+	"github.com/grafana/dskit/httpgrpc"
+	httpgrpc_server "github.com/grafana/dskit/httpgrpc/server"
+
+	"github.com/grafana/loki/v3/pkg/engine"
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange"
@@ -139,3 +144,41 @@ func (h *Handler) Do(ctx context.Context, req queryrangebase.Request) (queryrang
 func NewQuerierHTTPHandler(h *Handler) http.Handler {
 	return queryrange.NewSerializeHTTPHandler(h, queryrange.DefaultCodec)
 }
+
+// FragmentHandler is a queryrangebase.Handler that also executes the plan fragments of the next generation query
+// engine, which are sent to queriers by query-frontends with distributed execution enabled. It implements
+// worker.HTTPGrpcHandler, as fragments cannot be decoded by the codec of the querier worker. Fragments are only
+// executed if all data objects they read are stored under the prefix of the tenant of the request.
+type FragmentHandler struct {
+	queryrangebase.Handler
+	fragments *httpgrpc_server.Server
+}
+
+// NewFragmentHandler wraps next with a handler for the plan fragments of the next generation query engine of api.
+func NewFragmentHandler(next queryrangebase.Handler, api *QuerierAPI) *FragmentHandler {
+	return &FragmentHandler{
+		Handler:   next,
+		fragments: httpgrpc_server.NewServer(api.engineV2.FragmentHandler()),
+	}
+}
+
+// HandleHTTPGrpc executes the request if it is a plan fragment, and returns false otherwise.
+func (h *FragmentHandler) HandleHTTPGrpc(ctx context.Context, req *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, bool) {
+	u, err := url.Parse(req.Url)
+	if err != nil || u.Path != engine.FragmentPath {
+		return nil, false
+	}
+
+	resp, err := h.fragments.Handle(ctx, req)
+	if err != nil {
+		// Responses with a 5xx status code are returned as errors.
+		if errResp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+			return errResp, true
+		}
+		return &httpgrpc.HTTPResponse{
+			Code: http.StatusInternalServerError,
+			Body: []byte(err.Error()),
+		}, true
+	}
+	return resp, true
+}
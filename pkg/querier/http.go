@@ -2,6 +2,7 @@ package querier
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/thanos-io/objstore"
 
 	"github.com/grafana/dskit/tenant"
 
@@ -45,52 +47,65 @@ type QuerierAPI struct {
 	querier  Querier
 	cfg      Config
 	limits   querier_limits.Limits
-	engineV1 logql.Engine // Loki's current query engine
-	engineV2 logql.Engine // Loki's next generation query engine
+	engineV1 logql.Engine        // Loki's current query engine
+	engineV2 *engine.QueryEngine // Loki's next generation query engine
 	logger   log.Logger
+
+	// shadowQueries limits the number of queries executed in the background in shadow mode.
+	shadowQueries chan struct{}
 }
 
 // NewQuerierAPI returns an instance of the QuerierAPI.
-func NewQuerierAPI(cfg Config, querier Querier, limits querier_limits.Limits, metastore metastore.Metastore, logger log.Logger) *QuerierAPI {
+func NewQuerierAPI(cfg Config, querier Querier, limits querier_limits.Limits, metastore metastore.Metastore, bucket objstore.Bucket, logger log.Logger) *QuerierAPI {
 	return &QuerierAPI{
 		cfg:      cfg,
 		limits:   limits,
 		querier:  querier,
 		engineV1: logql.NewEngine(cfg.Engine, querier, limits, logger),
-		engineV2: engine.New(cfg.Engine, metastore, limits, logger),
+		engineV2: engine.New(cfg.Engine, metastore, bucket, limits, logger),
 		logger:   logger,
+
+		shadowQueries: make(chan struct{}, max(cfg.Engine.V2EngineShadowMaxConcurrency, 1)),
 	}
 }
 
 // RangeQueryHandler is a http.HandlerFunc for range queries and legacy log queries
 func (q *QuerierAPI) RangeQueryHandler(ctx context.Context, req *queryrange.LokiRequest) (logqlmodel.Result, error) {
-	var result logqlmodel.Result
-	logger := utillog.WithContext(ctx, q.logger)
-
 	if err := q.validateMaxEntriesLimits(ctx, req.Plan.AST, req.Limit); err != nil {
-		return result, err
+		return logqlmodel.Result{}, err
 	}
 
 	params, err := queryrange.ParamsFromRequest(req)
 	if err != nil {
-		return result, err
+		return logqlmodel.Result{}, err
 	}
 
+	result, servedByV2, err := q.execRangeQuery(ctx, params)
+	q.shadowQuery(ctx, params, servedByV2, result, err)
+	return result, err
+}
+
+// execRangeQuery executes the query with the next generation query engine if it is enabled and supports the query,
+// otherwise with the classic engine. It returns whether the result was produced by the next generation engine.
+func (q *QuerierAPI) execRangeQuery(ctx context.Context, params logql.Params) (logqlmodel.Result, bool, error) {
+	logger := utillog.WithContext(ctx, q.logger)
+
 	if q.cfg.Engine.EnableV2Engine {
 		query := q.engineV2.Query(params)
-		result, err = query.Exec(ctx)
+		result, err := query.Exec(ctx)
 		if err == nil {
-			return result, err
+			return result, true, err
 		}
 		if !errors.Is(err, engine.ErrNotSupported) {
 			level.Error(logger).Log("msg", "query execution failed with new query engine", "err", err)
-			return result, errors.Wrap(err, "failed with new execution engine")
+			return result, true, errors.Wrap(err, "failed with new execution engine")
 		}
 		level.Warn(logger).Log("msg", "falling back to legacy query engine", "err", err)
 	}
 
 	query := q.engineV1.Query(params)
-	return query.Exec(ctx)
+	result, err := query.Exec(ctx)
+	return result, false, err
 }
 
 // InstantQueryHandler is a http.HandlerFunc for instant queries.
@@ -109,7 +124,9 @@ func (q *QuerierAPI) InstantQueryHandler(ctx context.Context, req *queryrange.Lo
 		return logqlmodel.Result{}, err
 	}
 	query := q.engineV1.Query(params)
-	return query.Exec(ctx)
+	result, err := query.Exec(ctx)
+	q.shadowQuery(ctx, params, false, result, err)
+	return result, err
 }
 
 // LabelHandler is a http.HandlerFunc for handling label queries.
@@ -313,6 +330,106 @@ func (q *QuerierAPI) DetectedLabelsHandler(ctx context.Context, req *logproto.De
 	return resp, nil
 }
 
+// ExplainHandler is a http.HandlerFunc that returns the logical and the physical plan of a query of the next generation
+// query engine. It accepts the parameters of range queries. If the analyze parameter is true, the query is executed
+// and the physical plan is annotated with the runtime statistics of each node.
+func (q *QuerierAPI) ExplainHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error()), w)
+		return
+	}
+	req, err := loghttp.ParseRangeQuery(r)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error()), w)
+		return
+	}
+	var analyze bool
+	if v := r.Form.Get("analyze"); v != "" {
+		if analyze, err = strconv.ParseBool(v); err != nil {
+			serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "invalid analyze parameter: %s", err.Error()), w)
+			return
+		}
+	}
+
+	params, err := logql.NewLiteralParams(req.Query, req.Start, req.End, req.Step, req.Interval, req.Direction, req.Limit, req.Shards, nil)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	if err := q.validateMaxEntriesLimits(r.Context(), params.GetExpression(), req.Limit); err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+	if analyze {
+		// The query is executed directly on the querier, so the limits that
+		// are otherwise enforced by the query-frontend are applied here.
+		if err := q.validateExplainLimits(r.Context(), req, params.GetExpression()); err != nil {
+			serverutil.WriteError(err, w)
+			return
+		}
+		params, err = logql.NewLiteralParams(req.Query, req.Start, req.End, req.Step, req.Interval, req.Direction, req.Limit, req.Shards, nil)
+		if err != nil {
+			serverutil.WriteError(err, w)
+			return
+		}
+	}
+
+	explanation, err := q.engineV2.Explain(r.Context(), params, analyze)
+	if errors.Is(err, engine.ErrNotSupported) {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error()), w)
+		return
+	} else if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	_ = json.NewEncoder(w).Encode(loghttp.ExplainResponse{
+		Status: "success",
+		Data:   explainResponseData(explanation),
+	})
+}
+
+// explainResponseData converts the explanation of a query into the data of its response.
+func explainResponseData(explanation *engine.Explanation) loghttp.ExplainResponseData {
+	data := loghttp.ExplainResponseData{
+		LogicalPlan:  explanation.LogicalPlan,
+		PhysicalPlan: explanation.PhysicalPlan,
+		Analyzed:     explanation.Analyzed,
+		Statistics:   explanation.Statistics,
+	}
+	for _, node := range explanation.Nodes {
+		data.Nodes = append(data.Nodes, loghttp.ExplainNode(node))
+	}
+	return data
+}
+
+// validateExplainLimits applies the max query lookback, the max query length and the max query range limits of the
+// tenants to the query. The start of the query is clamped by the max query lookback.
+func (q *QuerierAPI) validateExplainLimits(ctx context.Context, req *loghttp.RangeQuery, expr syntax.Expr) error {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	for _, id := range tenantIDs {
+		if req.Start, req.End, err = querier_limits.ValidateQueryTimeRangeLimits(ctx, id, q.limits, req.Start, req.End); err != nil {
+			return err
+		}
+	}
+
+	sampleExpr, ok := expr.(syntax.SampleExpr)
+	if !ok {
+		return nil
+	}
+	maxIntervalCapture := func(id string) time.Duration { return q.limits.MaxQueryRange(ctx, id) }
+	if maxQueryInterval := util_validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, maxIntervalCapture); maxQueryInterval != 0 {
+		if err := logql.CheckIntervalLimit(sampleExpr, maxQueryInterval); err != nil {
+			return httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+		}
+	}
+	return nil
+}
+
 // WrapQuerySpanAndTimeout applies a context deadline and a span logger to a query call.
 //
 // The timeout is based on the per-tenant query timeout configuration.
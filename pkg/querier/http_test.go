@@ -11,9 +11,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/grafana/loki/v3/pkg/engine"
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	querier_testutil "github.com/grafana/loki/v3/pkg/querier/testutil"
 	"github.com/grafana/loki/v3/pkg/validation"
 
 	"github.com/go-kit/log"
@@ -28,7 +30,7 @@ func TestInstantQueryHandler(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("log selector expression not allowed for instant queries", func(t *testing.T) {
-		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, nil, log.NewNopLogger())
+		api := NewQuerierAPI(mockQuerierConfig(), nil, limits, nil, nil, log.NewNopLogger())
 
 		ctx := user.InjectOrgID(context.Background(), "user")
 		req, err := http.NewRequestWithContext(ctx, "GET", `/api/v1/query`, nil)
@@ -51,6 +53,73 @@ func TestInstantQueryHandler(t *testing.T) {
 	})
 }
 
+func TestExplainHandler_Limits(t *testing.T) {
+	limits := &querier_testutil.MockLimits{
+		MaxQueryLengthVal: time.Hour,
+		MaxQueryRangeVal:  5 * time.Minute,
+	}
+	api := NewQuerierAPI(mockQuerierConfig(), nil, limits, nil, nil, log.NewNopLogger())
+
+	for _, tc := range []struct {
+		name, query, start string
+		expected           string
+	}{
+		{
+			name:     "max query length",
+			query:    `{app="loki"}`,
+			start:    "2h",
+			expected: "the query time range exceeds the limit",
+		},
+		{
+			name:     "max query range",
+			query:    `count_over_time({app="loki"}[10m])`,
+			start:    "30m",
+			expected: "[10m] > [5m]",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), "user")
+			req, err := http.NewRequestWithContext(ctx, "GET", `/loki/api/v1/explain`, nil)
+			require.NoError(t, err)
+
+			now := time.Now()
+			start, err := time.ParseDuration(tc.start)
+			require.NoError(t, err)
+			q := req.URL.Query()
+			q.Add("query", tc.query)
+			q.Add("analyze", "true")
+			q.Add("start", fmt.Sprint(now.Add(-start).UnixNano()))
+			q.Add("end", fmt.Sprint(now.UnixNano()))
+			req.URL.RawQuery = q.Encode()
+
+			rr := httptest.NewRecorder()
+			api.ExplainHandler(rr, req)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Contains(t, rr.Body.String(), tc.expected)
+		})
+	}
+}
+
+func TestExplainResponseData(t *testing.T) {
+	explanation := &engine.Explanation{
+		LogicalPlan:  "logical",
+		PhysicalPlan: "physical",
+		Analyzed:     true,
+		Nodes: []engine.NodeStats{
+			{ID: "1", Type: "DataObjScan", RowsOut: 10, Batches: 1, WallTime: time.Second, PagesScanned: 2, PagesPruned: 1},
+		},
+	}
+
+	require.Equal(t, loghttp.ExplainResponseData{
+		LogicalPlan:  "logical",
+		PhysicalPlan: "physical",
+		Analyzed:     true,
+		Nodes: []loghttp.ExplainNode{
+			{ID: "1", Type: "DataObjScan", RowsOut: 10, Batches: 1, WallTime: time.Second, PagesScanned: 2, PagesPruned: 1},
+		},
+	}, explainResponseData(explanation))
+}
+
 type slowConnectionSimulator struct {
 	sleepFor   time.Duration
 	deadline   time.Duration
@@ -268,6 +337,6 @@ func makeRequest(t *testing.T, handler http.Handler, req *http.Request) *httptes
 }
 
 func setupAPI(querier *querierMock) *QuerierAPI {
-	api := NewQuerierAPI(Config{}, querier, nil, nil, log.NewNopLogger())
+	api := NewQuerierAPI(Config{}, querier, nil, nil, nil, log.NewNopLogger())
 	return api
 }
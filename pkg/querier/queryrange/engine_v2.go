@@ -0,0 +1,70 @@
+package queryrange
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/loki/v3/pkg/engine"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// NewEngineV2Middleware returns a middleware that executes log and metric queries with the next generation query
+// engine in the query-frontend. The engine is expected to distribute the execution of the queries to queriers.
+// The middleware is meant to wrap the handler at the end of the query-frontend's tripperware, so that the requests it
+// receives have already been validated, limited, split and checked against the results cache.
+// Requests with queries that are not supported by the engine, as well as sharded requests, are passed to the next
+// handler.
+func NewEngineV2Middleware(engine logql.Engine, logger log.Logger) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return &engineV2Handler{
+			next:   next,
+			engine: engine,
+			logger: logger,
+		}
+	})
+}
+
+type engineV2Handler struct {
+	next   queryrangebase.Handler
+	engine logql.Engine
+	logger log.Logger
+}
+
+func (h *engineV2Handler) Do(ctx context.Context, req queryrangebase.Request) (queryrangebase.Response, error) {
+	switch r := req.(type) {
+	case *LokiRequest:
+		// The engine does not evaluate shard annotations.
+		if len(r.Shards) > 0 {
+			return h.next.Do(ctx, req)
+		}
+	case *LokiInstantRequest:
+		// Log queries are not allowed as instant queries, which is validated by the querier.
+		if _, ok := r.Plan.AST.(syntax.SampleExpr); !ok || len(r.Shards) > 0 {
+			return h.next.Do(ctx, req)
+		}
+	default:
+		return h.next.Do(ctx, req)
+	}
+
+	params, err := ParamsFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := util_log.WithContext(ctx, h.logger)
+	result, err := h.engine.Query(params).Exec(ctx)
+	if errors.Is(err, engine.ErrNotSupported) {
+		level.Warn(logger).Log("msg", "falling back to queriers", "err", err)
+		return h.next.Do(ctx, req)
+	} else if err != nil {
+		level.Error(logger).Log("msg", "query execution failed with new query engine", "err", err)
+		return nil, err
+	}
+	return ResultToResponse(result, params)
+}
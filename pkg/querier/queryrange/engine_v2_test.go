@@ -0,0 +1,96 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+type engineFunc func(context.Context, logql.Params) (logqlmodel.Result, error)
+
+func (f engineFunc) Query(params logql.Params) logql.Query {
+	return queryFunc(func(ctx context.Context) (logqlmodel.Result, error) { return f(ctx, params) })
+}
+
+type queryFunc func(context.Context) (logqlmodel.Result, error)
+
+func (f queryFunc) Exec(ctx context.Context) (logqlmodel.Result, error) { return f(ctx) }
+
+func TestEngineV2Middleware(t *testing.T) {
+	now := time.Unix(0, 0).Add(time.Hour)
+
+	newRequest := func(query string) *LokiRequest {
+		return &LokiRequest{
+			Query:     query,
+			Limit:     100,
+			Step:      10000,
+			Direction: logproto.FORWARD,
+			Path:      "/loki/api/v1/query_range",
+			StartTs:   now.Add(-time.Minute),
+			EndTs:     now,
+			Plan:      &plan.QueryPlan{AST: syntax.MustParseExpr(query)},
+		}
+	}
+
+	var nextCalls int
+	next := queryrangebase.HandlerFunc(func(context.Context, queryrangebase.Request) (queryrangebase.Response, error) {
+		nextCalls++
+		return &LokiPromResponse{}, nil
+	})
+
+	eng := engineFunc(func(_ context.Context, params logql.Params) (logqlmodel.Result, error) {
+		if params.QueryString() == `{app="unsupported"}` {
+			return logqlmodel.Result{}, engine.ErrNotSupported
+		}
+		return logqlmodel.Result{
+			Data: promql.Matrix{{
+				Floats: []promql.FPoint{{T: now.UnixMilli(), F: 1}},
+			}},
+		}, nil
+	})
+	handler := NewEngineV2Middleware(eng, log.NewNopLogger()).Wrap(next)
+
+	t.Run("executed by engine", func(t *testing.T) {
+		nextCalls = 0
+		resp, err := handler.Do(context.Background(), newRequest(`count_over_time({app="foo"}[1m])`))
+		require.NoError(t, err)
+		require.IsType(t, &LokiPromResponse{}, resp)
+		require.Len(t, resp.(*LokiPromResponse).Response.Data.Result, 1)
+		require.Zero(t, nextCalls)
+	})
+
+	t.Run("not supported by engine", func(t *testing.T) {
+		nextCalls = 0
+		_, err := handler.Do(context.Background(), newRequest(`{app="unsupported"}`))
+		require.NoError(t, err)
+		require.Equal(t, 1, nextCalls)
+	})
+
+	t.Run("sharded requests", func(t *testing.T) {
+		nextCalls = 0
+		req := newRequest(`count_over_time({app="foo"}[1m])`)
+		req.Shards = []string{"0_of_2"}
+		_, err := handler.Do(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, 1, nextCalls)
+	})
+
+	t.Run("other requests", func(t *testing.T) {
+		nextCalls = 0
+		_, err := handler.Do(context.Background(), &LokiSeriesRequest{Match: []string{`{app="foo"}`}})
+		require.NoError(t, err)
+		require.Equal(t, 1, nextCalls)
+	})
+}
@@ -238,6 +238,23 @@ func (m *CountMinSketchResponse) WithHeaders(h []queryrangebase.PrometheusRespon
 	return m
 }
 
+// GetHeaders returns the HTTP headers in the response.
+func (m *HyperLogLogResponse) GetHeaders() []*queryrangebase.PrometheusResponseHeader {
+	if m != nil {
+		return convertPrometheusResponseHeadersToPointers(m.Headers)
+	}
+	return nil
+}
+
+func (m *HyperLogLogResponse) SetHeader(name, value string) {
+	m.Headers = setHeader(m.Headers, name, value)
+}
+
+func (m *HyperLogLogResponse) WithHeaders(h []queryrangebase.PrometheusResponseHeader) queryrangebase.Response {
+	m.Headers = h
+	return m
+}
+
 func (m *ShardsResponse) GetHeaders() []*queryrangebase.PrometheusResponseHeader {
 	if m != nil {
 		return convertPrometheusResponseHeadersToPointers(m.Headers)
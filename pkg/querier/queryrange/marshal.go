@@ -144,6 +144,13 @@ func ResultToResponse(result logqlmodel.Result, params logql.Params) (queryrange
 			Warnings:   result.Warnings,
 			Statistics: result.Statistics,
 		}, err
+	case logql.HyperLogLogMatrix:
+		r, err := data.ToProto()
+		return &HyperLogLogResponse{
+			Response:   r,
+			Warnings:   result.Warnings,
+			Statistics: result.Statistics,
+		}, err
 	}
 
 	return nil, fmt.Errorf("unsupported data type: %T", result.Data)
@@ -221,6 +228,17 @@ func ResponseToResult(resp queryrangebase.Response) (logqlmodel.Result, error) {
 			Warnings:   r.Warnings,
 			Statistics: r.Statistics,
 		}, nil
+	case *HyperLogLogResponse:
+		matrix, err := logql.HyperLogLogMatrixFromProto(r.Response)
+		if err != nil {
+			return logqlmodel.Result{}, fmt.Errorf("cannot decode hyperloglog sketch: %w", err)
+		}
+		return logqlmodel.Result{
+			Data:       matrix,
+			Headers:    resp.GetHeaders(),
+			Warnings:   r.Warnings,
+			Statistics: r.Statistics,
+		}, nil
 	default:
 		return logqlmodel.Result{}, fmt.Errorf("cannot decode (%T)", resp)
 	}
@@ -258,6 +276,8 @@ func QueryResponseUnwrap(res *QueryResponse) (queryrangebase.Response, error) {
 		return concrete.DetectedFields, nil
 	case *QueryResponse_CountMinSketches:
 		return concrete.CountMinSketches, nil
+	case *QueryResponse_HyperLogLogSketches:
+		return concrete.HyperLogLogSketches, nil
 	default:
 		return nil, fmt.Errorf("unsupported QueryResponse response type, got (%T)", res.Response)
 	}
@@ -301,6 +321,8 @@ func QueryResponseWrap(res queryrangebase.Response) (*QueryResponse, error) {
 		p.Response = &QueryResponse_DetectedFields{response}
 	case *CountMinSketchResponse:
 		p.Response = &QueryResponse_CountMinSketches{response}
+	case *HyperLogLogResponse:
+		p.Response = &QueryResponse_HyperLogLogSketches{response}
 	default:
 		return nil, fmt.Errorf("invalid response format, got (%T)", res)
 	}
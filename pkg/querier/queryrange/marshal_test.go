@@ -73,6 +73,7 @@ func TestResponseWrap(t *testing.T) {
 		{"streams", &LokiResponse{}, &QueryResponse_Streams{}},
 		{"topk", &TopKSketchesResponse{}, &QueryResponse_TopkSketches{}},
 		{"quantile", &QuantileSketchResponse{}, &QueryResponse_QuantileSketches{}},
+		{"hyperloglog", &HyperLogLogResponse{}, &QueryResponse_HyperLogLogSketches{}},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			actual, err := QueryResponseWrap(tt.response)
@@ -961,6 +961,59 @@ func (m *CountMinSketchResponse) GetStatistics() stats.Result {
 	return stats.Result{}
 }
 
+type HyperLogLogResponse struct {
+	Response   *github_com_grafana_loki_v3_pkg_logproto.HyperLogLogMatrix                                              `protobuf:"bytes,1,opt,name=response,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.HyperLogLogMatrix" json:"response,omitempty"`
+	Headers    []github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,2,rep,name=Headers,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
+	Warnings   []string                                                                                                `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Statistics stats.Result                                                                                            `protobuf:"bytes,4,opt,name=statistics,proto3" json:"statistics"`
+}
+
+func (m *HyperLogLogResponse) Reset()      { *m = HyperLogLogResponse{} }
+func (*HyperLogLogResponse) ProtoMessage() {}
+func (*HyperLogLogResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_51b9d53b40d11902, []int{14}
+}
+func (m *HyperLogLogResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HyperLogLogResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_HyperLogLogResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *HyperLogLogResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HyperLogLogResponse.Merge(m, src)
+}
+func (m *HyperLogLogResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *HyperLogLogResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HyperLogLogResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HyperLogLogResponse proto.InternalMessageInfo
+
+func (m *HyperLogLogResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *HyperLogLogResponse) GetStatistics() stats.Result {
+	if m != nil {
+		return m.Statistics
+	}
+	return stats.Result{}
+}
+
 type ShardsResponse struct {
 	Response *github_com_grafana_loki_v3_pkg_logproto.ShardsResponse                                                 `protobuf:"bytes,1,opt,name=response,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.ShardsResponse" json:"response,omitempty"`
 	Headers  []github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,2,rep,name=Headers,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
@@ -969,7 +1022,7 @@ type ShardsResponse struct {
 func (m *ShardsResponse) Reset()      { *m = ShardsResponse{} }
 func (*ShardsResponse) ProtoMessage() {}
 func (*ShardsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{14}
+	return fileDescriptor_51b9d53b40d11902, []int{15}
 }
 func (m *ShardsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1006,7 +1059,7 @@ type DetectedFieldsResponse struct {
 func (m *DetectedFieldsResponse) Reset()      { *m = DetectedFieldsResponse{} }
 func (*DetectedFieldsResponse) ProtoMessage() {}
 func (*DetectedFieldsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{15}
+	return fileDescriptor_51b9d53b40d11902, []int{16}
 }
 func (m *DetectedFieldsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1043,7 +1096,7 @@ type QueryPatternsResponse struct {
 func (m *QueryPatternsResponse) Reset()      { *m = QueryPatternsResponse{} }
 func (*QueryPatternsResponse) ProtoMessage() {}
 func (*QueryPatternsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{16}
+	return fileDescriptor_51b9d53b40d11902, []int{17}
 }
 func (m *QueryPatternsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1080,7 +1133,7 @@ type DetectedLabelsResponse struct {
 func (m *DetectedLabelsResponse) Reset()      { *m = DetectedLabelsResponse{} }
 func (*DetectedLabelsResponse) ProtoMessage() {}
 func (*DetectedLabelsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{17}
+	return fileDescriptor_51b9d53b40d11902, []int{18}
 }
 func (m *DetectedLabelsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1125,13 +1178,14 @@ type QueryResponse struct {
 	//	*QueryResponse_PatternsResponse
 	//	*QueryResponse_DetectedLabels
 	//	*QueryResponse_CountMinSketches
+	//	*QueryResponse_HyperLogLogSketches
 	Response isQueryResponse_Response `protobuf_oneof:"response"`
 }
 
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
 func (*QueryResponse) ProtoMessage() {}
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{18}
+	return fileDescriptor_51b9d53b40d11902, []int{19}
 }
 func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1206,20 +1260,24 @@ type QueryResponse_DetectedLabels struct {
 type QueryResponse_CountMinSketches struct {
 	CountMinSketches *CountMinSketchResponse `protobuf:"bytes,14,opt,name=countMinSketches,proto3,oneof"`
 }
-
-func (*QueryResponse_Series) isQueryResponse_Response()           {}
-func (*QueryResponse_Labels) isQueryResponse_Response()           {}
-func (*QueryResponse_Stats) isQueryResponse_Response()            {}
-func (*QueryResponse_Prom) isQueryResponse_Response()             {}
-func (*QueryResponse_Streams) isQueryResponse_Response()          {}
-func (*QueryResponse_Volume) isQueryResponse_Response()           {}
-func (*QueryResponse_TopkSketches) isQueryResponse_Response()     {}
-func (*QueryResponse_QuantileSketches) isQueryResponse_Response() {}
-func (*QueryResponse_ShardsResponse) isQueryResponse_Response()   {}
-func (*QueryResponse_DetectedFields) isQueryResponse_Response()   {}
-func (*QueryResponse_PatternsResponse) isQueryResponse_Response() {}
-func (*QueryResponse_DetectedLabels) isQueryResponse_Response()   {}
-func (*QueryResponse_CountMinSketches) isQueryResponse_Response() {}
+type QueryResponse_HyperLogLogSketches struct {
+	HyperLogLogSketches *HyperLogLogResponse `protobuf:"bytes,15,opt,name=hyperLogLogSketches,proto3,oneof"`
+}
+
+func (*QueryResponse_Series) isQueryResponse_Response()              {}
+func (*QueryResponse_Labels) isQueryResponse_Response()              {}
+func (*QueryResponse_Stats) isQueryResponse_Response()               {}
+func (*QueryResponse_Prom) isQueryResponse_Response()                {}
+func (*QueryResponse_Streams) isQueryResponse_Response()             {}
+func (*QueryResponse_Volume) isQueryResponse_Response()              {}
+func (*QueryResponse_TopkSketches) isQueryResponse_Response()        {}
+func (*QueryResponse_QuantileSketches) isQueryResponse_Response()    {}
+func (*QueryResponse_ShardsResponse) isQueryResponse_Response()      {}
+func (*QueryResponse_DetectedFields) isQueryResponse_Response()      {}
+func (*QueryResponse_PatternsResponse) isQueryResponse_Response()    {}
+func (*QueryResponse_DetectedLabels) isQueryResponse_Response()      {}
+func (*QueryResponse_CountMinSketches) isQueryResponse_Response()    {}
+func (*QueryResponse_HyperLogLogSketches) isQueryResponse_Response() {}
 
 func (m *QueryResponse) GetResponse() isQueryResponse_Response {
 	if m != nil {
@@ -1326,6 +1384,13 @@ func (m *QueryResponse) GetCountMinSketches() *CountMinSketchResponse {
 	return nil
 }
 
+func (m *QueryResponse) GetHyperLogLogSketches() *HyperLogLogResponse {
+	if x, ok := m.GetResponse().(*QueryResponse_HyperLogLogSketches); ok {
+		return x.HyperLogLogSketches
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*QueryResponse) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -1342,6 +1407,7 @@ func (*QueryResponse) XXX_OneofWrappers() []interface{} {
 		(*QueryResponse_PatternsResponse)(nil),
 		(*QueryResponse_DetectedLabels)(nil),
 		(*QueryResponse_CountMinSketches)(nil),
+		(*QueryResponse_HyperLogLogSketches)(nil),
 	}
 }
 
@@ -1364,7 +1430,7 @@ type QueryRequest struct {
 func (m *QueryRequest) Reset()      { *m = QueryRequest{} }
 func (*QueryRequest) ProtoMessage() {}
 func (*QueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{19}
+	return fileDescriptor_51b9d53b40d11902, []int{20}
 }
 func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1557,6 +1623,7 @@ func init() {
 	proto.RegisterType((*TopKSketchesResponse)(nil), "queryrange.TopKSketchesResponse")
 	proto.RegisterType((*QuantileSketchResponse)(nil), "queryrange.QuantileSketchResponse")
 	proto.RegisterType((*CountMinSketchResponse)(nil), "queryrange.CountMinSketchResponse")
+	proto.RegisterType((*HyperLogLogResponse)(nil), "queryrange.HyperLogLogResponse")
 	proto.RegisterType((*ShardsResponse)(nil), "queryrange.ShardsResponse")
 	proto.RegisterType((*DetectedFieldsResponse)(nil), "queryrange.DetectedFieldsResponse")
 	proto.RegisterType((*QueryPatternsResponse)(nil), "queryrange.QueryPatternsResponse")
@@ -1571,132 +1638,135 @@ func init() {
 }
 
 var fileDescriptor_51b9d53b40d11902 = []byte{
-	// 1991 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcf, 0x6f, 0x1b, 0xc7,
-	0x15, 0xe6, 0xf2, 0xa7, 0x38, 0x92, 0x68, 0x75, 0xac, 0x2a, 0x5b, 0xc5, 0xe1, 0xb2, 0x04, 0x9a,
-	0xa8, 0x45, 0x4b, 0xc6, 0x54, 0xe2, 0x26, 0xaa, 0x6b, 0xc4, 0x6b, 0xd9, 0x95, 0x5d, 0xbb, 0x71,
-	0x56, 0x42, 0x0e, 0xbd, 0x14, 0x23, 0x72, 0x44, 0x6e, 0x45, 0xee, 0xae, 0x77, 0x86, 0xb2, 0x05,
-	0x14, 0x45, 0xfe, 0x81, 0xa0, 0xb9, 0xf7, 0x5e, 0xf4, 0x56, 0x14, 0x28, 0x7a, 0xe8, 0xa9, 0xc7,
-	0xe4, 0x50, 0xc0, 0xc7, 0x80, 0x40, 0xd9, 0x9a, 0xbe, 0x14, 0x3a, 0x05, 0xe8, 0x3f, 0x50, 0xcc,
-	0x8f, 0x5d, 0xce, 0x70, 0x57, 0x35, 0xe9, 0x16, 0x05, 0x54, 0xf8, 0x42, 0xee, 0xcc, 0xbc, 0x6f,
-	0x76, 0xe6, 0x7b, 0xdf, 0x9b, 0x37, 0x33, 0x0b, 0xde, 0x0a, 0x8e, 0xbb, 0xcd, 0x47, 0x43, 0x1c,
-	0xba, 0x38, 0xe4, 0xff, 0xa7, 0x21, 0xf2, 0xba, 0x58, 0x79, 0x6c, 0x04, 0xa1, 0x4f, 0x7d, 0x08,
-	0xa6, 0x35, 0x9b, 0xad, 0xae, 0x4b, 0x7b, 0xc3, 0xc3, 0x46, 0xdb, 0x1f, 0x34, 0xbb, 0x7e, 0xd7,
-	0x6f, 0x76, 0x7d, 0xbf, 0xdb, 0xc7, 0x28, 0x70, 0x89, 0x7c, 0x6c, 0x86, 0x41, 0xbb, 0x49, 0x28,
-	0xa2, 0x43, 0x22, 0xf0, 0x9b, 0xeb, 0xcc, 0x90, 0x3f, 0x72, 0x88, 0xac, 0xb5, 0xa4, 0x39, 0x2f,
-	0x1d, 0x0e, 0x8f, 0x9a, 0xd4, 0x1d, 0x60, 0x42, 0xd1, 0x20, 0x88, 0x0c, 0xd8, 0xf8, 0xfa, 0x7e,
-	0x57, 0x20, 0x5d, 0xaf, 0x83, 0x9f, 0x74, 0x11, 0xc5, 0x8f, 0xd1, 0xa9, 0x34, 0x78, 0x5d, 0x33,
-	0x88, 0x1e, 0x64, 0xe3, 0xa6, 0xd6, 0x18, 0x20, 0x4a, 0x71, 0xe8, 0xc9, 0xb6, 0x6f, 0x68, 0x6d,
-	0xe4, 0x18, 0xd3, 0x76, 0x4f, 0x36, 0xd5, 0x64, 0xd3, 0xa3, 0xfe, 0xc0, 0xef, 0xe0, 0x3e, 0x9f,
-	0x08, 0x11, 0xbf, 0xd2, 0xe2, 0x32, 0xb3, 0x08, 0x86, 0xa4, 0xc7, 0x7f, 0x64, 0xe5, 0xad, 0x17,
-	0x72, 0x79, 0x88, 0x08, 0x6e, 0x76, 0xf0, 0x91, 0xeb, 0xb9, 0xd4, 0xf5, 0x3d, 0xa2, 0x3e, 0xcb,
-	0x4e, 0xae, 0xcd, 0xd7, 0xc9, 0xac, 0x7f, 0x36, 0xdf, 0x66, 0x38, 0x42, 0xfd, 0x10, 0x75, 0x71,
-	0xb3, 0xdd, 0x1b, 0x7a, 0xc7, 0xcd, 0x36, 0x6a, 0xf7, 0x70, 0x33, 0xc4, 0x64, 0xd8, 0xa7, 0x44,
-	0x14, 0xe8, 0x69, 0x80, 0xe5, 0x9b, 0xea, 0x5f, 0xe4, 0xc1, 0xf2, 0x7d, 0xff, 0xd8, 0x75, 0xf0,
-	0xa3, 0x21, 0x26, 0x14, 0xae, 0x83, 0x02, 0xef, 0xd5, 0x34, 0x6a, 0xc6, 0x56, 0xd9, 0x11, 0x05,
-	0x56, 0xdb, 0x77, 0x07, 0x2e, 0x35, 0xb3, 0x35, 0x63, 0x6b, 0xd5, 0x11, 0x05, 0x08, 0x41, 0x9e,
-	0x50, 0x1c, 0x98, 0xb9, 0x9a, 0xb1, 0x95, 0x73, 0xf8, 0x33, 0xdc, 0x04, 0x4b, 0xae, 0x47, 0x71,
-	0x78, 0x82, 0xfa, 0x66, 0x99, 0xd7, 0xc7, 0x65, 0x78, 0x03, 0x94, 0x08, 0x45, 0x21, 0x3d, 0x20,
-	0x66, 0xbe, 0x66, 0x6c, 0x2d, 0xb7, 0x36, 0x1b, 0xc2, 0xf3, 0x8d, 0xc8, 0xf3, 0x8d, 0x83, 0xc8,
-	0xf3, 0xf6, 0xd2, 0xe7, 0x63, 0x2b, 0xf3, 0xd9, 0xdf, 0x2c, 0xc3, 0x89, 0x40, 0x70, 0x07, 0x14,
-	0xb0, 0xd7, 0x39, 0x20, 0x66, 0x61, 0x01, 0xb4, 0x80, 0xc0, 0xab, 0xa0, 0xdc, 0x71, 0x43, 0xdc,
-	0x66, 0x2c, 0x9b, 0xc5, 0x9a, 0xb1, 0x55, 0x69, 0x5d, 0x6e, 0xc4, 0x42, 0xd9, 0x8d, 0x9a, 0x9c,
-	0xa9, 0x15, 0x9b, 0x5e, 0x80, 0x68, 0xcf, 0x2c, 0x71, 0x26, 0xf8, 0x33, 0xac, 0x83, 0x22, 0xe9,
-	0xa1, 0xb0, 0x43, 0xcc, 0xa5, 0x5a, 0x6e, 0xab, 0x6c, 0x83, 0xb3, 0xb1, 0x25, 0x6b, 0x1c, 0xf9,
-	0x0f, 0x7f, 0x06, 0xf2, 0x41, 0x1f, 0x79, 0x26, 0xe0, 0xa3, 0x5c, 0x6b, 0x28, 0x5e, 0x7a, 0xd8,
-	0x47, 0x9e, 0xfd, 0xfe, 0x68, 0x6c, 0xbd, 0xab, 0x06, 0x4f, 0x88, 0x8e, 0x90, 0x87, 0x9a, 0x7d,
-	0xff, 0xd8, 0x6d, 0x9e, 0x6c, 0x37, 0x55, 0xdf, 0xb3, 0x8e, 0x1a, 0x1f, 0xb1, 0x0e, 0x18, 0xd4,
-	0xe1, 0x1d, 0xc3, 0x7b, 0x60, 0x99, 0xf9, 0x18, 0xdf, 0x62, 0x0e, 0x26, 0xe6, 0x32, 0x7f, 0xcf,
-	0x6b, 0xd3, 0xd9, 0xf0, 0x7a, 0x07, 0x1f, 0xfd, 0x28, 0xf4, 0x87, 0x81, 0x7d, 0xe9, 0x6c, 0x6c,
-	0xa9, 0xf6, 0x8e, 0x5a, 0x80, 0xf7, 0x40, 0x85, 0x89, 0xc2, 0xf5, 0xba, 0x1f, 0x06, 0x5c, 0x81,
-	0xe6, 0x0a, 0xef, 0xee, 0x4a, 0x43, 0x95, 0x4c, 0xe3, 0x96, 0x66, 0x63, 0xe7, 0x19, 0xbd, 0xce,
-	0x0c, 0xb2, 0x3e, 0xc9, 0x01, 0xc8, 0xb4, 0x74, 0xd7, 0x23, 0x14, 0x79, 0xf4, 0x65, 0x24, 0x75,
-	0x1d, 0x14, 0x59, 0xf0, 0x1f, 0x10, 0x2e, 0xaa, 0x79, 0x7d, 0x2c, 0x31, 0xba, 0x93, 0xf3, 0x0b,
-	0x39, 0xb9, 0x90, 0xea, 0xe4, 0xe2, 0x0b, 0x9d, 0x5c, 0xfa, 0x1f, 0x39, 0x79, 0xe9, 0xbf, 0xeb,
-	0xe4, 0xf2, 0x4b, 0x3b, 0xd9, 0x04, 0x79, 0x36, 0x4a, 0xb8, 0x06, 0x72, 0x21, 0x7a, 0xcc, 0x7d,
-	0xba, 0xe2, 0xb0, 0xc7, 0xfa, 0x24, 0x0f, 0x56, 0xc4, 0x52, 0x42, 0x02, 0xdf, 0x23, 0x98, 0xf1,
-	0xb8, 0xcf, 0x57, 0x7f, 0xe1, 0x79, 0xc9, 0x23, 0xaf, 0x71, 0x64, 0x0b, 0xfc, 0x00, 0xe4, 0x77,
-	0x11, 0x45, 0x5c, 0x05, 0xcb, 0xad, 0x75, 0x95, 0x47, 0xd6, 0x17, 0x6b, 0xb3, 0x37, 0xd8, 0x40,
-	0xce, 0xc6, 0x56, 0xa5, 0x83, 0x28, 0xfa, 0xae, 0x3f, 0x70, 0x29, 0x1e, 0x04, 0xf4, 0xd4, 0xe1,
-	0x48, 0xf8, 0x2e, 0x28, 0xdf, 0x0e, 0x43, 0x3f, 0x3c, 0x38, 0x0d, 0x30, 0x57, 0x4d, 0xd9, 0x7e,
-	0xed, 0x6c, 0x6c, 0x5d, 0xc6, 0x51, 0xa5, 0x82, 0x98, 0x5a, 0xc2, 0x6f, 0x83, 0x02, 0x2f, 0x70,
-	0x9d, 0x94, 0xed, 0xcb, 0x67, 0x63, 0xeb, 0x12, 0x87, 0x28, 0xe6, 0xc2, 0x42, 0x97, 0x55, 0x61,
-	0x2e, 0x59, 0xc5, 0xea, 0x2e, 0xaa, 0xea, 0x36, 0x41, 0xe9, 0x04, 0x87, 0x84, 0x75, 0x53, 0xe2,
-	0xf5, 0x51, 0x11, 0xde, 0x04, 0x80, 0x11, 0xe3, 0x12, 0xea, 0xb6, 0x23, 0x67, 0xaf, 0x36, 0x44,
-	0xb2, 0x71, 0xb8, 0x8f, 0x6c, 0x28, 0x59, 0x50, 0x0c, 0x1d, 0xe5, 0x19, 0xfe, 0xce, 0x00, 0xa5,
-	0x3d, 0x8c, 0x3a, 0x38, 0x64, 0xee, 0xcd, 0x6d, 0x2d, 0xb7, 0xbe, 0xd5, 0x50, 0x33, 0xcb, 0xc3,
-	0xd0, 0x1f, 0x60, 0xda, 0xc3, 0x43, 0x12, 0x39, 0x48, 0x58, 0xdb, 0xde, 0x68, 0x6c, 0xe1, 0x39,
-	0xa5, 0x3a, 0x57, 0x42, 0x3b, 0xf7, 0x55, 0x67, 0x63, 0xcb, 0xf8, 0x9e, 0x13, 0x8d, 0x12, 0xb6,
-	0xc0, 0xd2, 0x63, 0x14, 0x7a, 0xae, 0xd7, 0x25, 0x26, 0xe0, 0x91, 0xb6, 0x71, 0x36, 0xb6, 0x60,
-	0x54, 0xa7, 0x38, 0x22, 0xb6, 0xab, 0xff, 0xd5, 0x00, 0x5f, 0x63, 0xc2, 0xd8, 0x67, 0xe3, 0x21,
-	0xca, 0x12, 0x33, 0x40, 0xb4, 0xdd, 0x33, 0x0d, 0xd6, 0x8d, 0x23, 0x0a, 0x6a, 0xbe, 0xc9, 0xfe,
-	0x47, 0xf9, 0x26, 0xb7, 0x78, 0xbe, 0x89, 0xd6, 0x95, 0x7c, 0xea, 0xba, 0x52, 0x38, 0x6f, 0x5d,
-	0xa9, 0xff, 0x4a, 0xae, 0xa1, 0xd1, 0xfc, 0x16, 0x08, 0xa5, 0x3b, 0x71, 0x28, 0xe5, 0xf8, 0x68,
-	0x63, 0x85, 0x8a, 0xbe, 0xee, 0x76, 0xb0, 0x47, 0xdd, 0x23, 0x17, 0x87, 0x2f, 0x08, 0x28, 0x45,
-	0xa5, 0x39, 0x5d, 0xa5, 0xaa, 0xc4, 0xf2, 0x17, 0x42, 0x62, 0x7a, 0x5c, 0x15, 0x5e, 0x22, 0xae,
-	0xea, 0xff, 0xcc, 0x82, 0x0d, 0xe6, 0x91, 0xfb, 0xe8, 0x10, 0xf7, 0x7f, 0x82, 0x06, 0x0b, 0x7a,
-	0xe5, 0x4d, 0xc5, 0x2b, 0x65, 0x1b, 0xbe, 0x62, 0x7d, 0x3e, 0xd6, 0x7f, 0x63, 0x80, 0xa5, 0x28,
-	0x01, 0xc0, 0x06, 0x00, 0x02, 0xc6, 0xd7, 0x78, 0xc1, 0x75, 0x85, 0x81, 0xc3, 0xb8, 0xd6, 0x51,
-	0x2c, 0xe0, 0xcf, 0x41, 0x51, 0x94, 0x64, 0x2c, 0x28, 0x69, 0x73, 0x9f, 0x86, 0x18, 0x0d, 0x6e,
-	0x76, 0x50, 0x40, 0x71, 0x68, 0xbf, 0xcf, 0x46, 0x31, 0x1a, 0x5b, 0x6f, 0x9d, 0xc7, 0x52, 0xb4,
-	0xc3, 0x97, 0x38, 0xe6, 0x5f, 0xf1, 0x4e, 0x47, 0xbe, 0xa1, 0xfe, 0xa9, 0x01, 0xd6, 0xd8, 0x40,
-	0x19, 0x35, 0xb1, 0x30, 0x76, 0xc1, 0x52, 0x28, 0x9f, 0xf9, 0x70, 0x97, 0x5b, 0xf5, 0x86, 0x4e,
-	0x6b, 0x0a, 0x95, 0x3c, 0xe1, 0x1a, 0x4e, 0x8c, 0x84, 0xdb, 0x1a, 0x8d, 0xd9, 0x34, 0x1a, 0x45,
-	0x8e, 0x56, 0x89, 0xfb, 0x73, 0x16, 0xc0, 0xbb, 0xec, 0x84, 0xc4, 0xf4, 0x37, 0x95, 0xea, 0x93,
-	0xc4, 0x88, 0xae, 0x4c, 0x49, 0x49, 0xda, 0xdb, 0x37, 0x46, 0x63, 0x6b, 0xe7, 0x05, 0xda, 0xf9,
-	0x37, 0x78, 0x65, 0x16, 0xaa, 0x7c, 0xb3, 0x17, 0x41, 0xbe, 0xf5, 0x3f, 0x64, 0x41, 0xe5, 0x63,
-	0xbf, 0x3f, 0x1c, 0xe0, 0x98, 0xbe, 0x20, 0x41, 0x9f, 0x39, 0xa5, 0x4f, 0xb7, 0xb5, 0x77, 0x46,
-	0x63, 0xeb, 0xda, 0xbc, 0xd4, 0xe9, 0xd8, 0x0b, 0x4d, 0xdb, 0xaf, 0x73, 0x60, 0xfd, 0xc0, 0x0f,
-	0x7e, 0xbc, 0xcf, 0x4f, 0xd1, 0xca, 0x32, 0xd9, 0x4b, 0x90, 0xb7, 0x3e, 0x25, 0x8f, 0x21, 0x1e,
-	0x20, 0x1a, 0xba, 0x4f, 0xec, 0x6b, 0xa3, 0xb1, 0xd5, 0x9a, 0x97, 0xb8, 0x29, 0xee, 0x22, 0x93,
-	0xa6, 0xed, 0x81, 0x72, 0xf3, 0xed, 0x81, 0x66, 0xd6, 0x85, 0xfc, 0x7c, 0xeb, 0xc2, 0xef, 0x73,
-	0x60, 0xe3, 0xa3, 0x21, 0xf2, 0xa8, 0xdb, 0xc7, 0xc2, 0x43, 0xb1, 0x7f, 0x7e, 0x91, 0xf0, 0x4f,
-	0x75, 0xea, 0x1f, 0x1d, 0x23, 0x3d, 0xf5, 0xc1, 0x68, 0x6c, 0x5d, 0x9f, 0xd7, 0x53, 0x69, 0x3d,
-	0xbc, 0xf2, 0xd9, 0xbc, 0x3e, 0xbb, 0xe5, 0x0f, 0x3d, 0xfa, 0xc0, 0xf5, 0x16, 0xf1, 0x99, 0x8e,
-	0xf9, 0x18, 0xb7, 0xa9, 0x1f, 0x2e, 0xe6, 0xb3, 0xb4, 0x1e, 0x5e, 0xf9, 0x6c, 0x1e, 0x9f, 0xfd,
-	0x29, 0x0b, 0x2a, 0xfb, 0x62, 0x4f, 0x1f, 0xb1, 0x75, 0x92, 0xe2, 0x2b, 0xf5, 0x12, 0x33, 0x38,
-	0x6c, 0xe8, 0x88, 0xc5, 0x52, 0x88, 0x8e, 0xbd, 0xd0, 0x29, 0xe4, 0x2f, 0x59, 0xb0, 0xb1, 0x8b,
-	0x29, 0x6e, 0x53, 0xdc, 0xb9, 0xe3, 0xe2, 0xbe, 0x42, 0xe2, 0x27, 0x46, 0x82, 0xc5, 0x9a, 0x72,
-	0x08, 0x4f, 0x05, 0xd9, 0xf6, 0x68, 0x6c, 0xdd, 0x98, 0x97, 0xc7, 0xf4, 0x3e, 0x2e, 0x34, 0x9f,
-	0x5f, 0x64, 0xc1, 0xd7, 0xc5, 0xc5, 0x92, 0xb8, 0xf5, 0x9e, 0xd2, 0xf9, 0xcb, 0x04, 0x9b, 0x96,
-	0xba, 0xe6, 0xa7, 0x40, 0xec, 0x9b, 0xa3, 0xb1, 0xf5, 0xc3, 0xf9, 0x17, 0xfd, 0x94, 0x2e, 0xfe,
-	0x6f, 0xb4, 0xc9, 0xcf, 0x82, 0x8b, 0x6a, 0x53, 0x07, 0xbd, 0x9c, 0x36, 0xf5, 0x3e, 0x2e, 0x34,
-	0x9f, 0x7f, 0x2c, 0x81, 0x55, 0xae, 0x92, 0x98, 0xc6, 0xef, 0x00, 0x79, 0x78, 0x96, 0x1c, 0xc2,
-	0xe8, 0xc2, 0x25, 0x0c, 0xda, 0x8d, 0x7d, 0x79, 0xac, 0x16, 0x16, 0xf0, 0x3d, 0x50, 0x24, 0xfc,
-	0x5a, 0x43, 0x9e, 0x8b, 0xaa, 0xb3, 0x37, 0x87, 0xfa, 0x05, 0xca, 0x5e, 0xc6, 0x91, 0xf6, 0xf0,
-	0x3a, 0x28, 0xf6, 0x39, 0x8b, 0xf2, 0x5a, 0xa7, 0x3e, 0x8b, 0x4c, 0x1e, 0xf4, 0x19, 0x5a, 0x60,
-	0xe0, 0x35, 0x50, 0xe0, 0x09, 0x40, 0xa6, 0x03, 0xed, 0xb5, 0xc9, 0x63, 0xd0, 0x5e, 0xc6, 0x11,
-	0xe6, 0xb0, 0x05, 0xf2, 0x41, 0xe8, 0x0f, 0xe4, 0x61, 0xf8, 0xca, 0xec, 0x3b, 0xd5, 0xd3, 0xe3,
-	0x5e, 0xc6, 0xe1, 0xb6, 0xf0, 0x1d, 0x50, 0x22, 0xfc, 0xd8, 0x49, 0xf8, 0x35, 0x22, 0x3b, 0x73,
-	0xcc, 0xc0, 0x14, 0x48, 0x64, 0x0a, 0xdf, 0x01, 0xc5, 0x13, 0x7e, 0xa8, 0x90, 0x77, 0xd3, 0x9b,
-	0x2a, 0x48, 0x3f, 0x6e, 0xb0, 0x79, 0x09, 0x5b, 0x78, 0x07, 0xac, 0x50, 0x3f, 0x38, 0x8e, 0xf6,
-	0xee, 0xf2, 0x0a, 0xb2, 0xa6, 0x62, 0xd3, 0xf6, 0xf6, 0x7b, 0x19, 0x47, 0xc3, 0xc1, 0x87, 0x60,
-	0xed, 0x91, 0xb6, 0xdf, 0xc3, 0xd1, 0x65, 0xb3, 0xc6, 0x73, 0xfa, 0x4e, 0x74, 0x2f, 0xe3, 0x24,
-	0xd0, 0x70, 0x17, 0x54, 0x88, 0x96, 0xe1, 0xe4, 0x87, 0x15, 0x6d, 0x5e, 0x7a, 0x0e, 0xdc, 0xcb,
-	0x38, 0x33, 0x18, 0x78, 0x1f, 0x54, 0x3a, 0xda, 0xfa, 0x2e, 0x3f, 0x9b, 0x68, 0xa3, 0x4a, 0xcf,
-	0x00, 0xac, 0x37, 0x1d, 0x0b, 0x3f, 0x04, 0x6b, 0xc1, 0xcc, 0xda, 0x26, 0xbf, 0x9b, 0x7c, 0x53,
-	0x9f, 0x65, 0xca, 0x22, 0xc8, 0x26, 0x39, 0x0b, 0x56, 0x87, 0x27, 0x42, 0xdc, 0x5c, 0x3d, 0x7f,
-	0x78, 0xfa, 0x22, 0xa0, 0x0e, 0x4f, 0xb4, 0x30, 0x27, 0xb4, 0xb5, 0x0d, 0x1c, 0x26, 0x66, 0x25,
-	0xd9, 0x5f, 0xfa, 0xd6, 0x92, 0x8d, 0x6f, 0x16, 0x6d, 0x83, 0xe9, 0x02, 0x57, 0xff, 0xb4, 0x08,
-	0x56, 0x64, 0xe0, 0x8a, 0xdb, 0xd7, 0xef, 0xc7, 0xb1, 0x28, 0xe2, 0xf6, 0x8d, 0xf3, 0x62, 0x91,
-	0x9b, 0x2b, 0xa1, 0xf8, 0x76, 0x1c, 0x8a, 0x22, 0x88, 0x37, 0xa6, 0x8b, 0x26, 0x9f, 0x89, 0x82,
-	0x90, 0xe1, 0xb7, 0x1d, 0x85, 0x9f, 0x88, 0xdd, 0xd7, 0xd3, 0xef, 0x30, 0x22, 0x94, 0x8c, 0xbd,
-	0x1d, 0x50, 0x72, 0xc5, 0x27, 0xa9, 0xb4, 0xa8, 0x4d, 0x7e, 0xb1, 0x62, 0xd1, 0x24, 0x01, 0x70,
-	0x7b, 0x1a, 0x83, 0x05, 0xf9, 0x09, 0x26, 0x11, 0x83, 0x31, 0x28, 0x0a, 0xc1, 0xab, 0x71, 0x08,
-	0x16, 0x67, 0x3f, 0xdb, 0x44, 0x01, 0x18, 0x4f, 0x4c, 0xc6, 0xdf, 0x6d, 0xb0, 0x1a, 0x29, 0x96,
-	0x37, 0xc9, 0x00, 0x7c, 0xe3, 0xbc, 0x8d, 0x62, 0x84, 0xd7, 0x51, 0xf0, 0x6e, 0x42, 0xe6, 0xe5,
-	0xd9, 0xe4, 0x3e, 0x2b, 0xf2, 0xa8, 0xa7, 0x59, 0x8d, 0xdf, 0x03, 0x97, 0xa6, 0x32, 0x15, 0x63,
-	0x02, 0xc9, 0xc3, 0xa1, 0x26, 0xf0, 0xa8, 0xab, 0x59, 0xa0, 0x3a, 0x2c, 0x29, 0xef, 0xe5, 0xf3,
-	0x86, 0x15, 0x89, 0x3b, 0x31, 0x2c, 0xa9, 0xed, 0x3d, 0xb0, 0x34, 0xc0, 0x14, 0x75, 0x10, 0x45,
-	0x66, 0x89, 0x27, 0xba, 0x37, 0x13, 0x21, 0x27, 0xd1, 0x8d, 0x07, 0xd2, 0xf0, 0xb6, 0x47, 0xc3,
-	0x53, 0xb9, 0x57, 0x8f, 0xd1, 0x9b, 0x3f, 0x00, 0xab, 0x9a, 0x01, 0x5c, 0x03, 0xb9, 0x63, 0x1c,
-	0x7d, 0xa6, 0x64, 0x8f, 0x70, 0x1d, 0x14, 0x4e, 0x50, 0x7f, 0x88, 0xb9, 0x3e, 0xcb, 0x8e, 0x28,
-	0xec, 0x64, 0xdf, 0x33, 0xec, 0x32, 0x28, 0x85, 0xe2, 0x2d, 0x76, 0xf7, 0xe9, 0xb3, 0x6a, 0xe6,
-	0xcb, 0x67, 0xd5, 0xcc, 0x57, 0xcf, 0xaa, 0xc6, 0x27, 0x93, 0xaa, 0xf1, 0xdb, 0x49, 0xd5, 0xf8,
-	0x7c, 0x52, 0x35, 0x9e, 0x4e, 0xaa, 0xc6, 0xdf, 0x27, 0x55, 0xe3, 0x1f, 0x93, 0x6a, 0xe6, 0xab,
-	0x49, 0xd5, 0xf8, 0xec, 0x79, 0x35, 0xf3, 0xf4, 0x79, 0x35, 0xf3, 0xe5, 0xf3, 0x6a, 0xe6, 0xa7,
-	0x57, 0x17, 0xce, 0xb9, 0x87, 0x45, 0xce, 0xd4, 0xf6, 0xbf, 0x02, 0x00, 0x00, 0xff, 0xff, 0xaa,
-	0x37, 0x05, 0xb8, 0xaf, 0x21, 0x00, 0x00,
+	// 2044 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcd, 0x6f, 0x1b, 0xc7,
+	0x15, 0xe7, 0xf2, 0x4b, 0xe4, 0x50, 0xa2, 0xd5, 0x91, 0xaa, 0x6c, 0x15, 0x87, 0xcb, 0x12, 0x68,
+	0xa2, 0x16, 0xed, 0x32, 0xa6, 0x12, 0x37, 0x56, 0x13, 0x23, 0x5e, 0xcb, 0x2e, 0xed, 0xca, 0x8d,
+	0xb3, 0x12, 0x72, 0xe8, 0xa5, 0x18, 0x91, 0x23, 0x72, 0x2b, 0x72, 0x77, 0xbd, 0x3b, 0x94, 0x2d,
+	0xa0, 0x28, 0x72, 0x2f, 0x82, 0xe6, 0xde, 0x7b, 0x91, 0x5b, 0x51, 0xa0, 0xa7, 0x9e, 0x7a, 0x4c,
+	0x0e, 0x05, 0x7c, 0x0c, 0x08, 0x74, 0x5b, 0xd3, 0x97, 0x42, 0x27, 0x03, 0xfd, 0x07, 0x8a, 0xf9,
+	0xd8, 0xe5, 0x2c, 0x77, 0x55, 0x93, 0x6e, 0x51, 0x40, 0x85, 0x2e, 0xe4, 0x7c, 0xbc, 0xdf, 0xec,
+	0xec, 0xef, 0xf7, 0xde, 0xbc, 0x9d, 0x19, 0xf0, 0x96, 0x7b, 0xdc, 0x6b, 0x3e, 0x1a, 0x61, 0xcf,
+	0xc2, 0x1e, 0xfb, 0x3f, 0xf5, 0x90, 0xdd, 0xc3, 0x52, 0x51, 0x77, 0x3d, 0x87, 0x38, 0x10, 0x4c,
+	0x5b, 0x36, 0x5b, 0x3d, 0x8b, 0xf4, 0x47, 0x87, 0x7a, 0xc7, 0x19, 0x36, 0x7b, 0x4e, 0xcf, 0x69,
+	0xf6, 0x1c, 0xa7, 0x37, 0xc0, 0xc8, 0xb5, 0x7c, 0x51, 0x6c, 0x7a, 0x6e, 0xa7, 0xe9, 0x13, 0x44,
+	0x46, 0x3e, 0xc7, 0x6f, 0xae, 0x53, 0x43, 0x56, 0x64, 0x10, 0xd1, 0xaa, 0x09, 0x73, 0x56, 0x3b,
+	0x1c, 0x1d, 0x35, 0x89, 0x35, 0xc4, 0x3e, 0x41, 0x43, 0x37, 0x34, 0xa0, 0xf3, 0x1b, 0x38, 0x3d,
+	0x8e, 0xb4, 0xec, 0x2e, 0x7e, 0xd2, 0x43, 0x04, 0x3f, 0x46, 0xa7, 0xc2, 0xe0, 0xf5, 0x98, 0x41,
+	0x58, 0x10, 0x9d, 0x9b, 0xb1, 0x4e, 0x17, 0x11, 0x82, 0x3d, 0x5b, 0xf4, 0x7d, 0x2b, 0xd6, 0xe7,
+	0x1f, 0x63, 0xd2, 0xe9, 0x8b, 0xae, 0xba, 0xe8, 0x7a, 0x34, 0x18, 0x3a, 0x5d, 0x3c, 0x60, 0x2f,
+	0xe2, 0xf3, 0x5f, 0x61, 0xb1, 0x46, 0x2d, 0xdc, 0x91, 0xdf, 0x67, 0x3f, 0xa2, 0xf1, 0xf6, 0x4b,
+	0xb9, 0x3c, 0x44, 0x3e, 0x6e, 0x76, 0xf1, 0x91, 0x65, 0x5b, 0xc4, 0x72, 0x6c, 0x5f, 0x2e, 0x8b,
+	0x41, 0xae, 0xcf, 0x37, 0xc8, 0xac, 0x3e, 0x9b, 0x6f, 0x53, 0x9c, 0x4f, 0x1c, 0x0f, 0xf5, 0x70,
+	0xb3, 0xd3, 0x1f, 0xd9, 0xc7, 0xcd, 0x0e, 0xea, 0xf4, 0x71, 0xd3, 0xc3, 0xfe, 0x68, 0x40, 0x7c,
+	0x5e, 0x21, 0xa7, 0x2e, 0x16, 0x4f, 0x6a, 0x7c, 0x95, 0x07, 0x95, 0x3d, 0xe7, 0xd8, 0x32, 0xf1,
+	0xa3, 0x11, 0xf6, 0x09, 0x5c, 0x07, 0x05, 0x36, 0xaa, 0xaa, 0xd4, 0x95, 0xad, 0xb2, 0xc9, 0x2b,
+	0xb4, 0x75, 0x60, 0x0d, 0x2d, 0xa2, 0x66, 0xeb, 0xca, 0xd6, 0x8a, 0xc9, 0x2b, 0x10, 0x82, 0xbc,
+	0x4f, 0xb0, 0xab, 0xe6, 0xea, 0xca, 0x56, 0xce, 0x64, 0x65, 0xb8, 0x09, 0x4a, 0x96, 0x4d, 0xb0,
+	0x77, 0x82, 0x06, 0x6a, 0x99, 0xb5, 0x47, 0x75, 0x78, 0x13, 0x2c, 0xf9, 0x04, 0x79, 0xe4, 0xc0,
+	0x57, 0xf3, 0x75, 0x65, 0xab, 0xd2, 0xda, 0xd4, 0xb9, 0xf2, 0x7a, 0xa8, 0xbc, 0x7e, 0x10, 0x2a,
+	0x6f, 0x94, 0xbe, 0x0c, 0xb4, 0xcc, 0xe7, 0x7f, 0xd3, 0x14, 0x33, 0x04, 0xc1, 0x1d, 0x50, 0xc0,
+	0x76, 0xf7, 0xc0, 0x57, 0x0b, 0x0b, 0xa0, 0x39, 0x04, 0x5e, 0x03, 0xe5, 0xae, 0xe5, 0xe1, 0x0e,
+	0x65, 0x59, 0x2d, 0xd6, 0x95, 0xad, 0x6a, 0x6b, 0x4d, 0x8f, 0x1c, 0x65, 0x37, 0xec, 0x32, 0xa7,
+	0x56, 0xf4, 0xf5, 0x5c, 0x44, 0xfa, 0xea, 0x12, 0x63, 0x82, 0x95, 0x61, 0x03, 0x14, 0xfd, 0x3e,
+	0xf2, 0xba, 0xbe, 0x5a, 0xaa, 0xe7, 0xb6, 0xca, 0x06, 0x38, 0x0b, 0x34, 0xd1, 0x62, 0x8a, 0x7f,
+	0xf8, 0x73, 0x90, 0x77, 0x07, 0xc8, 0x56, 0x01, 0x9b, 0xe5, 0xaa, 0x2e, 0xa9, 0xf4, 0x70, 0x80,
+	0x6c, 0xe3, 0xc6, 0x38, 0xd0, 0xde, 0x95, 0x83, 0xc7, 0x43, 0x47, 0xc8, 0x46, 0xcd, 0x81, 0x73,
+	0x6c, 0x35, 0x4f, 0xb6, 0x9b, 0xb2, 0xf6, 0x74, 0x20, 0xfd, 0x63, 0x3a, 0x00, 0x85, 0x9a, 0x6c,
+	0x60, 0x78, 0x1f, 0x54, 0xa8, 0xc6, 0xf8, 0x36, 0x15, 0xd8, 0x57, 0x2b, 0xec, 0x39, 0xaf, 0x4d,
+	0xdf, 0x86, 0xb5, 0x9b, 0xf8, 0xe8, 0xc7, 0x9e, 0x33, 0x72, 0x8d, 0x2b, 0x67, 0x81, 0x26, 0xdb,
+	0x9b, 0x72, 0x05, 0xde, 0x07, 0x55, 0xea, 0x14, 0x96, 0xdd, 0xfb, 0xc8, 0x65, 0x1e, 0xa8, 0x2e,
+	0xb3, 0xe1, 0xae, 0xea, 0xb2, 0xcb, 0xe8, 0xb7, 0x63, 0x36, 0x46, 0x9e, 0xd2, 0x6b, 0xce, 0x20,
+	0x1b, 0x93, 0x1c, 0x80, 0xd4, 0x97, 0xee, 0xd9, 0x3e, 0x41, 0x36, 0x79, 0x15, 0x97, 0x7a, 0x1f,
+	0x14, 0x69, 0xf0, 0x1f, 0xf8, 0x6a, 0x6e, 0x01, 0x8d, 0x05, 0x26, 0x2e, 0x72, 0x7e, 0x21, 0x91,
+	0x0b, 0xa9, 0x22, 0x17, 0x5f, 0x2a, 0xf2, 0xd2, 0xff, 0x48, 0xe4, 0xd2, 0x7f, 0x57, 0xe4, 0xf2,
+	0x2b, 0x8b, 0xac, 0x82, 0x3c, 0x9d, 0x25, 0x5c, 0x05, 0x39, 0x0f, 0x3d, 0x66, 0x9a, 0x2e, 0x9b,
+	0xb4, 0xd8, 0x98, 0xe4, 0xc1, 0x32, 0x5f, 0x4a, 0x7c, 0xd7, 0xb1, 0x7d, 0x4c, 0x79, 0xdc, 0x67,
+	0xab, 0x3f, 0x57, 0x5e, 0xf0, 0xc8, 0x5a, 0x4c, 0xd1, 0x03, 0x3f, 0x04, 0xf9, 0x5d, 0x44, 0x10,
+	0xf3, 0x82, 0x4a, 0x6b, 0x5d, 0xe6, 0x91, 0x8e, 0x45, 0xfb, 0x8c, 0x0d, 0x3a, 0x91, 0xb3, 0x40,
+	0xab, 0x76, 0x11, 0x41, 0xdf, 0x77, 0x86, 0x16, 0xc1, 0x43, 0x97, 0x9c, 0x9a, 0x0c, 0x09, 0xdf,
+	0x05, 0xe5, 0x3b, 0x9e, 0xe7, 0x78, 0x07, 0xa7, 0x2e, 0x66, 0x5e, 0x53, 0x36, 0x5e, 0x3b, 0x0b,
+	0xb4, 0x35, 0x1c, 0x36, 0x4a, 0x88, 0xa9, 0x25, 0xfc, 0x2e, 0x28, 0xb0, 0x0a, 0xf3, 0x93, 0xb2,
+	0xb1, 0x76, 0x16, 0x68, 0x57, 0x18, 0x44, 0x32, 0xe7, 0x16, 0x71, 0xb7, 0x2a, 0xcc, 0xe5, 0x56,
+	0x91, 0x77, 0x17, 0x65, 0xef, 0x56, 0xc1, 0xd2, 0x09, 0xf6, 0x7c, 0xcb, 0xe1, 0x7e, 0xb3, 0x62,
+	0x86, 0x55, 0x78, 0x0b, 0x00, 0x4a, 0x8c, 0xe5, 0x13, 0xab, 0x13, 0x8a, 0xbd, 0xa2, 0xf3, 0x64,
+	0x63, 0x32, 0x8d, 0x0c, 0x28, 0x58, 0x90, 0x0c, 0x4d, 0xa9, 0x0c, 0x7f, 0xaf, 0x80, 0xa5, 0x36,
+	0x46, 0x5d, 0xec, 0x51, 0x79, 0x73, 0x5b, 0x95, 0xd6, 0x77, 0x74, 0x39, 0xb3, 0x3c, 0xf4, 0x9c,
+	0x21, 0x26, 0x7d, 0x3c, 0xf2, 0x43, 0x81, 0xb8, 0xb5, 0x61, 0x8f, 0x03, 0x0d, 0xcf, 0xe9, 0xaa,
+	0x73, 0x25, 0xb4, 0x73, 0x1f, 0x75, 0x16, 0x68, 0xca, 0x0f, 0xcc, 0x70, 0x96, 0xb0, 0x05, 0x4a,
+	0x8f, 0x91, 0x67, 0x5b, 0x76, 0xcf, 0x57, 0x01, 0x8b, 0xb4, 0x8d, 0xb3, 0x40, 0x83, 0x61, 0x9b,
+	0x24, 0x44, 0x64, 0xd7, 0xf8, 0xab, 0x02, 0xbe, 0x41, 0x1d, 0x63, 0x9f, 0xce, 0xc7, 0x97, 0x96,
+	0x98, 0x21, 0x22, 0x9d, 0xbe, 0xaa, 0xd0, 0x61, 0x4c, 0x5e, 0x91, 0xf3, 0x4d, 0xf6, 0x3f, 0xca,
+	0x37, 0xb9, 0xc5, 0xf3, 0x4d, 0xb8, 0xae, 0xe4, 0x53, 0xd7, 0x95, 0xc2, 0x79, 0xeb, 0x4a, 0xe3,
+	0x37, 0x62, 0x0d, 0x0d, 0xdf, 0x6f, 0x81, 0x50, 0xba, 0x1b, 0x85, 0x52, 0x8e, 0xcd, 0x36, 0xf2,
+	0x50, 0x3e, 0xd6, 0xbd, 0x2e, 0xb6, 0x89, 0x75, 0x64, 0x61, 0xef, 0x25, 0x01, 0x25, 0x79, 0x69,
+	0x2e, 0xee, 0xa5, 0xb2, 0x8b, 0xe5, 0x2f, 0x84, 0x8b, 0xc5, 0xe3, 0xaa, 0xf0, 0x0a, 0x71, 0xd5,
+	0xf8, 0x67, 0x16, 0x6c, 0x50, 0x45, 0xf6, 0xd0, 0x21, 0x1e, 0xfc, 0x14, 0x0d, 0x17, 0x54, 0xe5,
+	0x4d, 0x49, 0x95, 0xb2, 0x01, 0x2f, 0x59, 0x9f, 0x8f, 0xf5, 0xdf, 0x29, 0xa0, 0x14, 0x26, 0x00,
+	0xa8, 0x03, 0xc0, 0x61, 0x6c, 0x8d, 0xe7, 0x5c, 0x57, 0x29, 0xd8, 0x8b, 0x5a, 0x4d, 0xc9, 0x02,
+	0xfe, 0x02, 0x14, 0x79, 0x4d, 0xc4, 0x82, 0x94, 0x36, 0xf7, 0x89, 0x87, 0xd1, 0xf0, 0x56, 0x17,
+	0xb9, 0x04, 0x7b, 0xc6, 0x0d, 0x3a, 0x8b, 0x71, 0xa0, 0xbd, 0x75, 0x1e, 0x4b, 0xe1, 0x17, 0xbe,
+	0xc0, 0x51, 0x7d, 0xf9, 0x33, 0x4d, 0xf1, 0x84, 0xc6, 0x67, 0x0a, 0x58, 0xa5, 0x13, 0xa5, 0xd4,
+	0x44, 0x8e, 0xb1, 0x0b, 0x4a, 0x9e, 0x28, 0xb3, 0xe9, 0x56, 0x5a, 0x0d, 0x3d, 0x4e, 0x6b, 0x0a,
+	0x95, 0x2c, 0xe1, 0x2a, 0x66, 0x84, 0x84, 0xdb, 0x31, 0x1a, 0xb3, 0x69, 0x34, 0xf2, 0x1c, 0x2d,
+	0x13, 0xf7, 0xe7, 0x2c, 0x80, 0xf7, 0xe8, 0x0e, 0x89, 0xfa, 0xdf, 0xd4, 0x55, 0x9f, 0x24, 0x66,
+	0x74, 0x75, 0x4a, 0x4a, 0xd2, 0xde, 0xb8, 0x39, 0x0e, 0xb4, 0x9d, 0x97, 0xf8, 0xce, 0xbf, 0xc1,
+	0x4b, 0x6f, 0x21, 0xbb, 0x6f, 0xf6, 0x22, 0xb8, 0x6f, 0xe3, 0x8f, 0x59, 0x50, 0xfd, 0xc4, 0x19,
+	0x8c, 0x86, 0x38, 0xa2, 0xcf, 0x4d, 0xd0, 0xa7, 0x4e, 0xe9, 0x8b, 0xdb, 0x1a, 0x3b, 0xe3, 0x40,
+	0xbb, 0x3e, 0x2f, 0x75, 0x71, 0xec, 0x85, 0xa6, 0xed, 0xb7, 0x39, 0xb0, 0x7e, 0xe0, 0xb8, 0x3f,
+	0xd9, 0x67, 0xbb, 0x68, 0x69, 0x99, 0xec, 0x27, 0xc8, 0x5b, 0x9f, 0x92, 0x47, 0x11, 0x0f, 0x10,
+	0xf1, 0xac, 0x27, 0xc6, 0xf5, 0x71, 0xa0, 0xb5, 0xe6, 0x25, 0x6e, 0x8a, 0xbb, 0xc8, 0xa4, 0xc5,
+	0xbe, 0x81, 0x72, 0xf3, 0x7d, 0x03, 0xcd, 0xac, 0x0b, 0xf9, 0xf9, 0xd6, 0x85, 0x3f, 0xe4, 0xc0,
+	0xc6, 0xc7, 0x23, 0x64, 0x13, 0x6b, 0x80, 0xb9, 0x42, 0x91, 0x3e, 0xbf, 0x4c, 0xe8, 0x53, 0x9b,
+	0xea, 0x13, 0xc7, 0x08, 0xa5, 0x3e, 0x1c, 0x07, 0xda, 0xfb, 0xf3, 0x2a, 0x95, 0x36, 0xc2, 0xa5,
+	0x66, 0xf3, 0x6a, 0x76, 0xdb, 0x19, 0xd9, 0xe4, 0x81, 0x65, 0x2f, 0xa2, 0x59, 0x1c, 0xf3, 0x09,
+	0xee, 0x10, 0xc7, 0x5b, 0x4c, 0xb3, 0xb4, 0x11, 0x2e, 0x35, 0x9b, 0x47, 0xb3, 0x2f, 0x72, 0x60,
+	0xad, 0x7d, 0xea, 0x62, 0x6f, 0xcf, 0xe9, 0xed, 0x39, 0xbd, 0x48, 0xb0, 0x93, 0x84, 0x60, 0xaf,
+	0x4f, 0x05, 0x93, 0x00, 0x22, 0xc2, 0x3e, 0x18, 0x07, 0xda, 0x8d, 0x79, 0xd5, 0x4a, 0xc0, 0x2f,
+	0xa5, 0x9a, 0x47, 0xaa, 0x3f, 0x65, 0x41, 0x75, 0x9f, 0x6f, 0xbf, 0xce, 0x57, 0xa9, 0xa6, 0xcb,
+	0xe7, 0xcd, 0xee, 0xa1, 0x1e, 0x47, 0x2c, 0x96, 0xed, 0xe3, 0xd8, 0x0b, 0x9d, 0xed, 0xff, 0x92,
+	0x05, 0x1b, 0xbb, 0x98, 0xe0, 0x0e, 0xc1, 0xdd, 0xbb, 0x16, 0x1e, 0x48, 0x24, 0x7e, 0xaa, 0x24,
+	0x58, 0xac, 0x4b, 0xe7, 0x25, 0xa9, 0x20, 0xc3, 0x18, 0x07, 0xda, 0xcd, 0x79, 0x79, 0x4c, 0x1f,
+	0xe3, 0x42, 0xf3, 0xf9, 0x55, 0x16, 0x7c, 0x93, 0x9f, 0x01, 0xf2, 0x0b, 0x8a, 0x29, 0x9d, 0xbf,
+	0x4a, 0xb0, 0xa9, 0xc9, 0xe9, 0x39, 0x05, 0x62, 0xdc, 0x1a, 0x07, 0xda, 0x07, 0xf3, 0xe7, 0xe7,
+	0x94, 0x21, 0xfe, 0x6f, 0x7c, 0x93, 0x6d, 0xdb, 0x17, 0xf5, 0xcd, 0x38, 0xe8, 0xd5, 0x7c, 0x33,
+	0x3e, 0xc6, 0x85, 0xe6, 0xf3, 0xd7, 0x25, 0xb0, 0xc2, 0xbc, 0x24, 0xa2, 0xf1, 0x7b, 0x40, 0x9c,
+	0x73, 0x08, 0x0e, 0x61, 0x78, 0x36, 0xe6, 0xb9, 0x1d, 0x7d, 0x5f, 0x9c, 0x80, 0x70, 0x0b, 0xf8,
+	0x1e, 0x28, 0xfa, 0x74, 0x52, 0xe1, 0x16, 0xb6, 0x36, 0x7b, 0xc8, 0x1b, 0x3f, 0xeb, 0x6a, 0x67,
+	0x4c, 0x61, 0x4f, 0x6f, 0x03, 0x06, 0x8c, 0x45, 0x35, 0x97, 0xd8, 0x44, 0xeb, 0xe9, 0x67, 0x32,
+	0x14, 0xcd, 0x31, 0xf0, 0x3a, 0x28, 0xb0, 0x04, 0xa0, 0xe6, 0x93, 0x8f, 0x4d, 0xee, 0x58, 0xdb,
+	0x19, 0x93, 0x9b, 0xc3, 0x16, 0xc8, 0xbb, 0x9e, 0x33, 0x14, 0xe7, 0x16, 0x57, 0x67, 0x9f, 0x29,
+	0x6f, 0xf4, 0xdb, 0x19, 0x93, 0xd9, 0xc2, 0x77, 0xe8, 0x51, 0xa3, 0x87, 0xd1, 0xd0, 0x57, 0x8b,
+	0x62, 0x7b, 0x38, 0x03, 0x93, 0x20, 0xa1, 0x29, 0x7c, 0x07, 0x14, 0x4f, 0xd8, 0xfe, 0x4f, 0x5c,
+	0x23, 0x6c, 0xca, 0xa0, 0xf8, 0xce, 0x90, 0xbe, 0x17, 0xb7, 0x85, 0x77, 0xc1, 0x32, 0x71, 0xdc,
+	0xe3, 0x70, 0x9b, 0x25, 0x4e, 0x8b, 0xeb, 0x32, 0x36, 0x6d, 0x1b, 0xd6, 0xce, 0x98, 0x31, 0x1c,
+	0x7c, 0x08, 0x56, 0x1f, 0xc5, 0x3e, 0xcd, 0x71, 0x78, 0x2f, 0x10, 0xe3, 0x39, 0x7d, 0xd3, 0xd0,
+	0xce, 0x98, 0x09, 0x34, 0xdc, 0x05, 0x55, 0x3f, 0x96, 0xe1, 0x54, 0x90, 0x7c, 0xaf, 0x78, 0x0e,
+	0x6c, 0x67, 0xcc, 0x19, 0x0c, 0xdc, 0x03, 0xd5, 0x6e, 0x6c, 0x7d, 0x57, 0x2b, 0xc9, 0x59, 0xa5,
+	0x67, 0x00, 0x3a, 0x5a, 0x1c, 0x0b, 0x3f, 0x02, 0xab, 0xee, 0xcc, 0xda, 0x26, 0xae, 0xb8, 0xbe,
+	0x1d, 0x7f, 0xcb, 0x94, 0x45, 0x90, 0xbe, 0xe4, 0x2c, 0x58, 0x9e, 0x1e, 0x0f, 0x71, 0x75, 0xe5,
+	0xfc, 0xe9, 0xc5, 0x17, 0x01, 0x79, 0x7a, 0xbc, 0x87, 0x8a, 0xd0, 0x89, 0x7d, 0x6b, 0x63, 0x5f,
+	0xad, 0x26, 0xc7, 0x4b, 0xdf, 0x05, 0xd0, 0xf9, 0xcd, 0xa2, 0xe1, 0x3e, 0x58, 0xeb, 0x4f, 0xbf,
+	0x07, 0xa3, 0x41, 0xaf, 0x88, 0xcc, 0x21, 0x0d, 0x9a, 0xf2, 0x99, 0xda, 0xce, 0x98, 0x69, 0x68,
+	0x03, 0x4c, 0x57, 0xcd, 0xc6, 0x67, 0x45, 0xb0, 0x2c, 0x56, 0x03, 0x7e, 0xfa, 0xfe, 0xc3, 0x28,
+	0xc0, 0xf9, 0x62, 0xf0, 0xc6, 0x79, 0x01, 0xce, 0xcc, 0xa5, 0xf8, 0x7e, 0x3b, 0x8a, 0x6f, 0xbe,
+	0x32, 0x6c, 0x4c, 0x57, 0x62, 0x46, 0x8f, 0x84, 0x10, 0x31, 0xbd, 0x1d, 0xc6, 0x74, 0x6e, 0xf6,
+	0x13, 0x5a, 0x8e, 0xe8, 0x10, 0x25, 0x02, 0x7a, 0x07, 0x2c, 0x59, 0xfc, 0x4a, 0x32, 0x6d, 0x29,
+	0x48, 0xde, 0x58, 0xd2, 0x10, 0x15, 0x00, 0xb8, 0x3d, 0x0d, 0xec, 0x82, 0xb8, 0x82, 0x4b, 0x04,
+	0x76, 0x04, 0x0a, 0xe3, 0xfa, 0x5a, 0x14, 0xd7, 0xc5, 0xd9, 0x6b, 0xbb, 0x30, 0xaa, 0xa3, 0x17,
+	0x13, 0x41, 0x7d, 0x07, 0xac, 0x84, 0x61, 0xc0, 0xba, 0x44, 0x54, 0xbf, 0x71, 0xde, 0xd7, 0x67,
+	0x88, 0x8f, 0xa3, 0xe0, 0xbd, 0x44, 0xec, 0x94, 0x67, 0xbf, 0x18, 0x66, 0x23, 0x27, 0x1c, 0x69,
+	0x36, 0x70, 0xee, 0x83, 0x2b, 0x53, 0xdf, 0xe7, 0x73, 0x02, 0xc9, 0xc3, 0x81, 0x58, 0xd4, 0x84,
+	0x43, 0xcd, 0x02, 0xe5, 0x69, 0x89, 0x98, 0xa9, 0x9c, 0x37, 0xad, 0x30, 0x62, 0x12, 0xd3, 0x12,
+	0x01, 0xd3, 0x06, 0xa5, 0x21, 0x26, 0x88, 0x9e, 0xa1, 0xab, 0x4b, 0x2c, 0x7b, 0xbe, 0x99, 0x88,
+	0x63, 0x81, 0xd6, 0x1f, 0x08, 0xc3, 0x3b, 0x36, 0xf1, 0x4e, 0xc5, 0x06, 0x20, 0x42, 0x6f, 0xfe,
+	0x08, 0xac, 0xc4, 0x0c, 0xe8, 0x95, 0xe6, 0x31, 0x0e, 0xaf, 0xa9, 0x69, 0x91, 0xde, 0x2b, 0x9d,
+	0xa0, 0xc1, 0x08, 0x33, 0xff, 0x2c, 0x9b, 0xbc, 0xb2, 0x93, 0x7d, 0x4f, 0x31, 0xca, 0x60, 0xc9,
+	0xe3, 0x4f, 0x31, 0x7a, 0x4f, 0x9f, 0xd5, 0x32, 0x5f, 0x3f, 0xab, 0x65, 0x5e, 0x3c, 0xab, 0x29,
+	0x9f, 0x4e, 0x6a, 0xca, 0x17, 0x93, 0x9a, 0xf2, 0xe5, 0xa4, 0xa6, 0x3c, 0x9d, 0xd4, 0x94, 0xbf,
+	0x4f, 0x6a, 0xca, 0x3f, 0x26, 0xb5, 0xcc, 0x8b, 0x49, 0x4d, 0xf9, 0xfc, 0x79, 0x2d, 0xf3, 0xf4,
+	0x79, 0x2d, 0xf3, 0xf5, 0xf3, 0x5a, 0xe6, 0x67, 0xd7, 0x16, 0x4e, 0xe4, 0x87, 0x45, 0xc6, 0xd4,
+	0xf6, 0xbf, 0x06, 0x00, 0x22, 0x48, 0xc8, 0x6f, 0xaf, 0x23, 0x00, 0x00,
 }
 
 func (this *LokiRequest) Equal(that interface{}) bool {
@@ -2317,6 +2387,53 @@ func (this *CountMinSketchResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *HyperLogLogResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*HyperLogLogResponse)
+	if !ok {
+		that2, ok := that.(HyperLogLogResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if that1.Response == nil {
+		if this.Response != nil {
+			return false
+		}
+	} else if !this.Response.Equal(*that1.Response) {
+		return false
+	}
+	if len(this.Headers) != len(that1.Headers) {
+		return false
+	}
+	for i := range this.Headers {
+		if !this.Headers[i].Equal(that1.Headers[i]) {
+			return false
+		}
+	}
+	if len(this.Warnings) != len(that1.Warnings) {
+		return false
+	}
+	for i := range this.Warnings {
+		if this.Warnings[i] != that1.Warnings[i] {
+			return false
+		}
+	}
+	if !this.Statistics.Equal(&that1.Statistics) {
+		return false
+	}
+	return true
+}
 func (this *ShardsResponse) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -2806,6 +2923,30 @@ func (this *QueryResponse_CountMinSketches) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *QueryResponse_HyperLogLogSketches) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueryResponse_HyperLogLogSketches)
+	if !ok {
+		that2, ok := that.(QueryResponse_HyperLogLogSketches)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.HyperLogLogSketches.Equal(that1.HyperLogLogSketches) {
+		return false
+	}
+	return true
+}
 func (this *QueryRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -3289,6 +3430,19 @@ func (this *CountMinSketchResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *HyperLogLogResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&queryrange.HyperLogLogResponse{")
+	s = append(s, "Response: "+fmt.Sprintf("%#v", this.Response)+",\n")
+	s = append(s, "Headers: "+fmt.Sprintf("%#v", this.Headers)+",\n")
+	s = append(s, "Warnings: "+fmt.Sprintf("%#v", this.Warnings)+",\n")
+	s = append(s, "Statistics: "+strings.Replace(this.Statistics.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *ShardsResponse) GoString() string {
 	if this == nil {
 		return "nil"
@@ -3337,7 +3491,7 @@ func (this *QueryResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 18)
+	s := make([]string, 0, 19)
 	s = append(s, "&queryrange.QueryResponse{")
 	if this.Status != nil {
 		s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
@@ -3452,6 +3606,14 @@ func (this *QueryResponse_CountMinSketches) GoString() string {
 		`CountMinSketches:` + fmt.Sprintf("%#v", this.CountMinSketches) + `}`}, ", ")
 	return s
 }
+func (this *QueryResponse_HyperLogLogSketches) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&queryrange.QueryResponse_HyperLogLogSketches{` +
+		`HyperLogLogSketches:` + fmt.Sprintf("%#v", this.HyperLogLogSketches) + `}`}, ", ")
+	return s
+}
 func (this *QueryRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -4507,6 +4669,74 @@ func (m *CountMinSketchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	return len(dAtA) - i, nil
 }
 
+func (m *HyperLogLogResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HyperLogLogResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HyperLogLogResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	{
+		size, err := m.Statistics.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintQueryrange(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x22
+	if len(m.Warnings) > 0 {
+		for iNdEx := len(m.Warnings) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Warnings[iNdEx])
+			copy(dAtA[i:], m.Warnings[iNdEx])
+			i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Warnings[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Headers) > 0 {
+		for iNdEx := len(m.Headers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Headers[iNdEx].Size()
+				i -= size
+				if _, err := m.Headers[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintQueryrange(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Response != nil {
+		{
+			size := m.Response.Size()
+			i -= size
+			if _, err := m.Response.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *ShardsResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -5007,6 +5237,27 @@ func (m *QueryResponse_CountMinSketches) MarshalToSizedBuffer(dAtA []byte) (int,
 	}
 	return len(dAtA) - i, nil
 }
+func (m *QueryResponse_HyperLogLogSketches) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QueryResponse_HyperLogLogSketches) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.HyperLogLogSketches != nil {
+		{
+			size, err := m.HyperLogLogSketches.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x7a
+	}
+	return len(dAtA) - i, nil
+}
 func (m *QueryRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -5660,6 +5911,33 @@ func (m *CountMinSketchResponse) Size() (n int) {
 	return n
 }
 
+func (m *HyperLogLogResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Response != nil {
+		l = m.Response.Size()
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
+	if len(m.Headers) > 0 {
+		for _, e := range m.Headers {
+			l = e.Size()
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	l = m.Statistics.Size()
+	n += 1 + l + sovQueryrange(uint64(l))
+	return n
+}
+
 func (m *ShardsResponse) Size() (n int) {
 	if m == nil {
 		return 0
@@ -5908,6 +6186,18 @@ func (m *QueryResponse_CountMinSketches) Size() (n int) {
 	}
 	return n
 }
+func (m *QueryResponse_HyperLogLogSketches) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.HyperLogLogSketches != nil {
+		l = m.HyperLogLogSketches.Size()
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
+	return n
+}
 func (m *QueryRequest) Size() (n int) {
 	if m == nil {
 		return 0
@@ -6253,6 +6543,19 @@ func (this *CountMinSketchResponse) String() string {
 	}, "")
 	return s
 }
+func (this *HyperLogLogResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HyperLogLogResponse{`,
+		`Response:` + fmt.Sprintf("%v", this.Response) + `,`,
+		`Headers:` + fmt.Sprintf("%v", this.Headers) + `,`,
+		`Warnings:` + fmt.Sprintf("%v", this.Warnings) + `,`,
+		`Statistics:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Statistics), "Result", "stats.Result", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *ShardsResponse) String() string {
 	if this == nil {
 		return "nil"
@@ -6438,6 +6741,16 @@ func (this *QueryResponse_CountMinSketches) String() string {
 	}, "")
 	return s
 }
+func (this *QueryResponse_HyperLogLogSketches) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueryResponse_HyperLogLogSketches{`,
+		`HyperLogLogSketches:` + strings.Replace(fmt.Sprintf("%v", this.HyperLogLogSketches), "HyperLogLogResponse", "HyperLogLogResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *QueryRequest) String() string {
 	if this == nil {
 		return "nil"
@@ -9387,6 +9700,191 @@ func (m *CountMinSketchResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *HyperLogLogResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryrange
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HyperLogLogResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HyperLogLogResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Response", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Response == nil {
+				m.Response = &github_com_grafana_loki_v3_pkg_logproto.HyperLogLogMatrix{}
+			}
+			if err := m.Response.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Headers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Headers = append(m.Headers, github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader{})
+			if err := m.Headers[len(m.Headers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Statistics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Statistics.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryrange(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *ShardsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -10399,6 +10897,41 @@ func (m *QueryResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.Response = &QueryResponse_CountMinSketches{v}
 			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HyperLogLogSketches", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &HyperLogLogResponse{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Response = &QueryResponse_HyperLogLogSketches{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -266,13 +266,25 @@ func maxRangeVectorAndOffsetDuration(expr syntax.Expr) (time.Duration, time.Dura
 
 	var maxRVDuration, maxOffset time.Duration
 	expr.Walk(func(e syntax.Expr) bool {
-		if r, ok := e.(*syntax.LogRangeExpr); ok {
+		switch r := e.(type) {
+		case *syntax.LogRangeExpr:
 			if r.Interval > maxRVDuration {
 				maxRVDuration = r.Interval
 			}
 			if r.Offset > maxOffset {
 				maxOffset = r.Offset
 			}
+		case *syntax.SubqueryExpr:
+			// the inner expression of a subquery is evaluated over the whole
+			// subquery range, so their ranges and offsets add up.
+			innerRVDuration, innerOffset := maxRangeVectorAndOffsetDuration(r.Left)
+			if r.Range+innerRVDuration > maxRVDuration {
+				maxRVDuration = r.Range + innerRVDuration
+			}
+			if r.Offset+innerOffset > maxOffset {
+				maxOffset = r.Offset + innerOffset
+			}
+			return false
 		}
 		return true
 	})
@@ -750,6 +750,7 @@ func Test_splitMetricQuery(t *testing.T) {
 
 	const shortRange = `rate({app="foo"}[1m])`
 	const longRange = `rate({app="foo"}[7d])`
+	const longSubquery = `max_over_time(rate({app="foo"}[1m])[7d:1h])`
 
 	for i, tc := range []struct {
 		input         *LokiRequest
@@ -1124,6 +1125,24 @@ func Test_splitMetricQuery(t *testing.T) {
 			},
 			splitInterval: 15 * time.Minute,
 		},
+		// subquery range too large we don't want to split it
+		{
+			input: &LokiRequest{
+				StartTs: time.Unix(2*3600, 0),
+				EndTs:   time.Unix(3*3*3600, 0),
+				Step:    15 * seconds,
+				Query:   longSubquery,
+			},
+			expected: []queryrangebase.Request{
+				&LokiRequest{
+					StartTs: time.Unix(2*3600, 0),
+					EndTs:   time.Unix(3*3*3600, 0),
+					Step:    15 * seconds,
+					Query:   longSubquery,
+				},
+			},
+			splitInterval: 15 * time.Minute,
+		},
 		// query is wholly within ingester query window
 		{
 			input: &LokiRequest{
@@ -0,0 +1,315 @@
+package querier
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/loki/v3/pkg/engine"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	utillog "github.com/grafana/loki/v3/pkg/util/log"
+	util_validation "github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+const (
+	shadowResultMatch    = "match"
+	shadowResultMismatch = "mismatch"
+	shadowResultError    = "error"
+	shadowResultSkipped  = "skipped"
+
+	// shadowSampleTolerance is the relative tolerance of the comparison of
+	// sample values, which may differ slightly because the engines aggregate
+	// samples in a different order.
+	shadowSampleTolerance = 1e-6
+
+	// defaultShadowTimeout is the timeout of shadow queries if the tenants
+	// of the request have no query timeout.
+	defaultShadowTimeout = 5 * time.Minute
+)
+
+var shadowQueriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: constants.Loki,
+	Name:      "querier_v2_engine_shadow_queries_total",
+	Help:      "Total number of queries executed on both query engines in shadow mode, by query type and comparison result. Queries that were not compared because too many shadow queries were running are counted as skipped.",
+}, []string{"type", "result"})
+
+// shadowSampled returns whether the query is executed on both query engines
+// to compare their results, according to the shadow sample rate of the
+// tenants of the request.
+func (q *QuerierAPI) shadowSampled(ctx context.Context) bool {
+	if !q.cfg.Engine.EnableV2EngineShadowMode || q.limits == nil {
+		return false
+	}
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return false
+	}
+
+	rate := 1.0
+	for _, tenantID := range tenantIDs {
+		rate = min(rate, q.limits.V2EngineShadowSampleRate(ctx, tenantID))
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// shadowQuery executes the query on the query engine that did not serve the
+// request and compares its result with the result of the engine that did,
+// if the query is sampled for shadow mode. The query is executed in the
+// background, detached from the request, so that neither the latency nor
+// the availability of the request depend on it. At most
+// V2EngineShadowMaxConcurrency shadow queries are executed at the same
+// time; queries exceeding it are skipped.
+func (q *QuerierAPI) shadowQuery(ctx context.Context, params logql.Params, servedByV2 bool, result logqlmodel.Result, err error) {
+	if !q.shadowSampled(ctx) {
+		return
+	}
+
+	queryType, _ := logql.QueryType(params.GetExpression())
+	select {
+	case q.shadowQueries <- struct{}{}:
+	default:
+		shadowQueriesTotal.WithLabelValues(queryType, shadowResultSkipped).Inc()
+		return
+	}
+
+	// The result is shared with the response of the request, so the
+	// top-level data is copied before the request returns.
+	result.Data = shallowCopyResultData(result.Data)
+
+	go func() {
+		defer func() { <-q.shadowQueries }()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.shadowTimeout(ctx))
+		defer cancel()
+		logger := utillog.WithContext(ctx, q.logger)
+
+		if servedByV2 {
+			v1Result, v1Err := q.engineV1.Query(params).Exec(ctx)
+			compareShadowResults(logger, params, v1Result, v1Err, result, err)
+			return
+		}
+		v2Result, v2Err := q.engineV2.Query(params).Exec(ctx)
+		if errors.Is(v2Err, engine.ErrNotSupported) {
+			return
+		}
+		compareShadowResults(logger, params, result, err, v2Result, v2Err)
+	}()
+}
+
+// shadowTimeout returns the timeout of shadow queries, which is the query
+// timeout of the tenants of the request.
+func (q *QuerierAPI) shadowTimeout(ctx context.Context) time.Duration {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return defaultShadowTimeout
+	}
+	timeoutCapture := func(id string) time.Duration { return q.limits.QueryTimeout(ctx, id) }
+	if timeout := util_validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, timeoutCapture); timeout > 0 {
+		return timeout
+	}
+	return defaultShadowTimeout
+}
+
+// shallowCopyResultData returns a copy of the top-level slice of the result
+// data, so that it can be compared while the original is encoded.
+func shallowCopyResultData(data parser.Value) parser.Value {
+	switch data := data.(type) {
+	case logqlmodel.Streams:
+		return slices.Clone(data)
+	case promql.Matrix:
+		return slices.Clone(data)
+	case promql.Vector:
+		return slices.Clone(data)
+	default:
+		return data
+	}
+}
+
+// compareShadowResults compares the results of the classic and the next
+// generation query engine. Mismatches are logged together with the query, and
+// the outcome of the comparison is counted in metrics.
+func compareShadowResults(logger log.Logger, params logql.Params, v1Result logqlmodel.Result, v1Err error, v2Result logqlmodel.Result, v2Err error) {
+	queryType, _ := logql.QueryType(params.GetExpression())
+
+	result, diff := shadowResultMatch, error(nil)
+	switch {
+	case v1Err != nil && v2Err != nil:
+		result = shadowResultError
+	case v1Err != nil:
+		result, diff = shadowResultMismatch, fmt.Errorf("classic engine failed: %w", v1Err)
+	case v2Err != nil:
+		result, diff = shadowResultMismatch, fmt.Errorf("new engine failed: %w", v2Err)
+	default:
+		if diff = compareResultData(v1Result.Data, v2Result.Data); diff != nil {
+			result = shadowResultMismatch
+		}
+	}
+	shadowQueriesTotal.WithLabelValues(queryType, result).Inc()
+
+	switch result {
+	case shadowResultMismatch:
+		level.Warn(logger).Log(
+			"msg", "query results of the query engines differ",
+			"query", params.QueryString(),
+			"query_type", queryType,
+			"start", params.Start(),
+			"end", params.End(),
+			"step", params.Step(),
+			"direction", params.Direction(),
+			"limit", params.Limit(),
+			"diff", diff,
+		)
+	case shadowResultError:
+		level.Debug(logger).Log("msg", "query failed with both query engines", "query", params.QueryString(), "err", v1Err)
+	}
+}
+
+// compareResultData compares the result data of the classic engine
+// (expected) to the result data of the next generation engine (actual). It
+// returns an error describing the first difference.
+func compareResultData(expected, actual parser.Value) error {
+	switch expected := expected.(type) {
+	case logqlmodel.Streams:
+		got, ok := actual.(logqlmodel.Streams)
+		if !ok {
+			return fmt.Errorf("expected streams, got %T", actual)
+		}
+		return compareStreams(expected, got)
+	case promql.Matrix:
+		got, ok := actual.(promql.Matrix)
+		if !ok {
+			return fmt.Errorf("expected matrix, got %T", actual)
+		}
+		return compareMatrix(expected, got)
+	case promql.Vector:
+		got, ok := actual.(promql.Vector)
+		if !ok {
+			return fmt.Errorf("expected vector, got %T", actual)
+		}
+		return compareVector(expected, got)
+	case promql.Scalar:
+		got, ok := actual.(promql.Scalar)
+		if !ok {
+			return fmt.Errorf("expected scalar, got %T", actual)
+		}
+		if expected.T != got.T || !sampleValuesEqual(expected.V, got.V) {
+			return fmt.Errorf("expected scalar %v at %d, got %v at %d", expected.V, expected.T, got.V, got.T)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported result type %T", expected)
+	}
+}
+
+func compareStreams(expected, actual logqlmodel.Streams) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("expected %d streams, got %d", len(expected), len(actual))
+	}
+
+	expected, actual = slices.Clone(expected), slices.Clone(actual)
+	for _, streams := range []logqlmodel.Streams{expected, actual} {
+		slices.SortFunc(streams, func(a, b logproto.Stream) int {
+			return strings.Compare(a.Labels, b.Labels)
+		})
+	}
+
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		if e.Labels != a.Labels {
+			return fmt.Errorf("expected stream %s, got %s", e.Labels, a.Labels)
+		}
+		if len(e.Entries) != len(a.Entries) {
+			return fmt.Errorf("expected %d entries for stream %s, got %d", len(e.Entries), e.Labels, len(a.Entries))
+		}
+		for j := range e.Entries {
+			if !e.Entries[j].Timestamp.Equal(a.Entries[j].Timestamp) || e.Entries[j].Line != a.Entries[j].Line {
+				return fmt.Errorf("expected entry %d of stream %s to be %q at %s, got %q at %s",
+					j, e.Labels, e.Entries[j].Line, e.Entries[j].Timestamp, a.Entries[j].Line, a.Entries[j].Timestamp)
+			}
+		}
+	}
+	return nil
+}
+
+func compareMatrix(expected, actual promql.Matrix) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("expected %d series, got %d", len(expected), len(actual))
+	}
+
+	expected, actual = slices.Clone(expected), slices.Clone(actual)
+	for _, matrix := range []promql.Matrix{expected, actual} {
+		slices.SortFunc(matrix, func(a, b promql.Series) int {
+			return labels.Compare(a.Metric, b.Metric)
+		})
+	}
+
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		if !labels.Equal(e.Metric, a.Metric) {
+			return fmt.Errorf("expected series %s, got %s", e.Metric, a.Metric)
+		}
+		if len(e.Floats) != len(a.Floats) {
+			return fmt.Errorf("expected %d samples for series %s, got %d", len(e.Floats), e.Metric, len(a.Floats))
+		}
+		for j := range e.Floats {
+			if e.Floats[j].T != a.Floats[j].T || !sampleValuesEqual(e.Floats[j].F, a.Floats[j].F) {
+				return fmt.Errorf("expected sample %d of series %s to be %v at %d, got %v at %d",
+					j, e.Metric, e.Floats[j].F, e.Floats[j].T, a.Floats[j].F, a.Floats[j].T)
+			}
+		}
+	}
+	return nil
+}
+
+func compareVector(expected, actual promql.Vector) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("expected %d samples, got %d", len(expected), len(actual))
+	}
+
+	expected, actual = slices.Clone(expected), slices.Clone(actual)
+	for _, vector := range []promql.Vector{expected, actual} {
+		slices.SortFunc(vector, func(a, b promql.Sample) int {
+			return labels.Compare(a.Metric, b.Metric)
+		})
+	}
+
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		if !labels.Equal(e.Metric, a.Metric) {
+			return fmt.Errorf("expected sample for series %s, got %s", e.Metric, a.Metric)
+		}
+		if e.T != a.T || !sampleValuesEqual(e.F, a.F) {
+			return fmt.Errorf("expected sample of series %s to be %v at %d, got %v at %d", e.Metric, e.F, e.T, a.F, a.T)
+		}
+	}
+	return nil
+}
+
+// sampleValuesEqual returns whether two sample values are equal within
+// [shadowSampleTolerance].
+func sampleValuesEqual(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= shadowSampleTolerance*max(math.Abs(a), math.Abs(b))
+}
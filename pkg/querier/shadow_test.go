@@ -0,0 +1,204 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	querier_testutil "github.com/grafana/loki/v3/pkg/querier/testutil"
+)
+
+func TestCompareResultData(t *testing.T) {
+	now := time.Unix(100, 0)
+	streams := func(entries ...logproto.Entry) logqlmodel.Streams {
+		return logqlmodel.Streams{
+			{Labels: `{app="bar"}`, Entries: []logproto.Entry{{Timestamp: now, Line: "bar"}}},
+			{Labels: `{app="foo"}`, Entries: entries},
+		}
+	}
+	matrix := func(v float64) promql.Matrix {
+		return promql.Matrix{
+			{Metric: labels.FromStrings("app", "foo"), Floats: []promql.FPoint{{T: 1000, F: 1}, {T: 2000, F: v}}},
+			{Metric: labels.FromStrings("app", "bar"), Floats: []promql.FPoint{{T: 1000, F: 3}}},
+		}
+	}
+
+	for _, tt := range []struct {
+		name             string
+		expected, actual parser.Value
+		wantErr          string
+	}{
+		{
+			name:     "equal streams in different order",
+			expected: streams(logproto.Entry{Timestamp: now, Line: "foo"}),
+			actual:   logqlmodel.Streams{streams(logproto.Entry{Timestamp: now, Line: "foo"})[1], streams()[0]},
+		},
+		{
+			name:     "different entry",
+			expected: streams(logproto.Entry{Timestamp: now, Line: "foo"}),
+			actual:   streams(logproto.Entry{Timestamp: now, Line: "baz"}),
+			wantErr:  `expected entry 0 of stream {app="foo"} to be "foo"`,
+		},
+		{
+			name:     "missing entry",
+			expected: streams(logproto.Entry{Timestamp: now, Line: "foo"}),
+			actual:   streams(),
+			wantErr:  `expected 1 entries for stream {app="foo"}, got 0`,
+		},
+		{
+			name:     "equal matrix within tolerance",
+			expected: matrix(0.1 + 0.2),
+			actual:   matrix(0.3),
+		},
+		{
+			name:     "NaN samples",
+			expected: matrix(math.NaN()),
+			actual:   matrix(math.NaN()),
+		},
+		{
+			name:     "different sample",
+			expected: matrix(2),
+			actual:   matrix(2.5),
+			wantErr:  `expected sample 1 of series {app="foo"} to be 2 at 2000, got 2.5 at 2000`,
+		},
+		{
+			name:     "different vector",
+			expected: promql.Vector{{Metric: labels.FromStrings("app", "foo"), T: 1000, F: 1}},
+			actual:   promql.Vector{{Metric: labels.FromStrings("app", "bar"), T: 1000, F: 1}},
+			wantErr:  `expected sample for series {app="foo"}, got {app="bar"}`,
+		},
+		{
+			name:     "different result types",
+			expected: matrix(1),
+			actual:   promql.Vector{},
+			wantErr:  "expected matrix, got promql.Vector",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := compareResultData(tt.expected, tt.actual)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCompareShadowResults(t *testing.T) {
+	params, err := logql.NewLiteralParams(`{app="foo"}`, time.Unix(0, 0), time.Unix(100, 0), 0, 0, logproto.BACKWARD, 100, nil, nil)
+	require.NoError(t, err)
+
+	result := func(line string) logqlmodel.Result {
+		return logqlmodel.Result{Data: logqlmodel.Streams{
+			{Labels: `{app="foo"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0), Line: line}}},
+		}}
+	}
+	counter := func(result string) float64 {
+		return testutil.ToFloat64(shadowQueriesTotal.WithLabelValues(logql.QueryTypeLimited, result))
+	}
+
+	matches, mismatches, errs := counter(shadowResultMatch), counter(shadowResultMismatch), counter(shadowResultError)
+
+	compareShadowResults(log.NewNopLogger(), params, result("foo"), nil, result("foo"), nil)
+	require.Equal(t, matches+1, counter(shadowResultMatch))
+
+	compareShadowResults(log.NewNopLogger(), params, result("foo"), nil, result("bar"), nil)
+	require.Equal(t, mismatches+1, counter(shadowResultMismatch))
+
+	compareShadowResults(log.NewNopLogger(), params, result("foo"), nil, logqlmodel.Result{}, errors.New("failed"))
+	require.Equal(t, mismatches+2, counter(shadowResultMismatch))
+
+	compareShadowResults(log.NewNopLogger(), params, logqlmodel.Result{}, errors.New("failed"), logqlmodel.Result{}, errors.New("failed"))
+	require.Equal(t, errs+1, counter(shadowResultError))
+}
+
+func TestQuerierAPI_ShadowSampled(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	for _, tt := range []struct {
+		name       string
+		shadowMode bool
+		rate       float64
+		want       bool
+	}{
+		{name: "shadow mode disabled", shadowMode: false, rate: 1, want: false},
+		{name: "zero sample rate", shadowMode: true, rate: 0, want: false},
+		{name: "all queries sampled", shadowMode: true, rate: 1, want: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := mockQuerierConfig()
+			cfg.Engine.EnableV2EngineShadowMode = tt.shadowMode
+			limits := &querier_testutil.MockLimits{V2EngineShadowSampleRateVal: tt.rate}
+
+			api := NewQuerierAPI(cfg, nil, limits, nil, nil, log.NewNopLogger())
+			require.Equal(t, tt.want, api.shadowSampled(ctx))
+		})
+	}
+}
+
+// blockingEngine is a [logql.Engine] whose queries return the result once
+// release is closed, unless their context is canceled before.
+type blockingEngine struct {
+	release chan struct{}
+	result  logqlmodel.Result
+}
+
+func (e *blockingEngine) Query(logql.Params) logql.Query { return e }
+
+func (e *blockingEngine) Exec(ctx context.Context) (logqlmodel.Result, error) {
+	select {
+	case <-e.release:
+		return e.result, nil
+	case <-ctx.Done():
+		return logqlmodel.Result{}, ctx.Err()
+	}
+}
+
+func TestQuerierAPI_ShadowQuery(t *testing.T) {
+	params, err := logql.NewLiteralParams(`{app="foo"}`, time.Unix(0, 0), time.Unix(100, 0), 0, 0, logproto.BACKWARD, 100, nil, nil)
+	require.NoError(t, err)
+	result := logqlmodel.Result{Data: logqlmodel.Streams{
+		{Labels: `{app="foo"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0), Line: "foo"}}},
+	}}
+	counter := func(result string) float64 {
+		return testutil.ToFloat64(shadowQueriesTotal.WithLabelValues(logql.QueryTypeLimited, result))
+	}
+
+	cfg := mockQuerierConfig()
+	cfg.Engine.EnableV2EngineShadowMode = true
+	cfg.Engine.V2EngineShadowMaxConcurrency = 1
+	limits := &querier_testutil.MockLimits{V2EngineShadowSampleRateVal: 1}
+	api := NewQuerierAPI(cfg, nil, limits, nil, nil, log.NewNopLogger())
+	v1 := &blockingEngine{release: make(chan struct{}), result: result}
+	api.engineV1 = v1
+
+	matches, skipped := counter(shadowResultMatch), counter(shadowResultSkipped)
+
+	// The shadow query runs in the background and outlives the request.
+	ctx, cancel := context.WithCancel(user.InjectOrgID(context.Background(), "user"))
+	api.shadowQuery(ctx, params, true, result, nil)
+	cancel()
+
+	// Queries exceeding the maximum number of shadow queries are skipped.
+	api.shadowQuery(user.InjectOrgID(context.Background(), "user"), params, true, result, nil)
+	require.Equal(t, skipped+1, counter(shadowResultSkipped))
+
+	close(v1.release)
+	require.Eventually(t, func() bool {
+		return counter(shadowResultMatch) == matches+1
+	}, time.Second, 10*time.Millisecond)
+}
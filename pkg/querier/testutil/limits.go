@@ -18,6 +18,7 @@ type MockLimits struct {
 	MaxEntriesLimitPerQueryVal    int
 	MaxStreamsMatchersPerQueryVal int
 	EnableMultiVariantQueriesVal  bool
+	V2EngineShadowSampleRateVal   float64
 }
 
 func (m *MockLimits) EnableMultiVariantQueries(_ string) bool {
@@ -56,6 +57,10 @@ func (m *MockLimits) MaxStreamsMatchersPerQuery(_ context.Context, _ string) int
 	return m.MaxStreamsMatchersPerQueryVal
 }
 
+func (m *MockLimits) V2EngineShadowSampleRate(_ context.Context, _ string) float64 {
+	return m.V2EngineShadowSampleRateVal
+}
+
 func (m *MockLimits) BlockedQueries(_ context.Context, _ string) []*validation.BlockedQuery {
 	return nil
 }
@@ -88,6 +88,12 @@ func newExecutionContext(workerCtx context.Context, logger log.Logger) (execCtx
 
 // handleHTTPRequest converts the request and applies it to the handler.
 func handleHTTPRequest(ctx context.Context, request *httpgrpc.HTTPRequest, handler RequestHandler, codec RequestCodec) *httpgrpc.HTTPResponse {
+	if h, ok := handler.(HTTPGrpcHandler); ok {
+		if response, ok := h.HandleHTTPGrpc(ctx, request); ok {
+			return response
+		}
+	}
+
 	req, ctx, err := codec.DecodeHTTPGrpcRequest(ctx, request)
 	if err != nil {
 		response, ok := httpgrpc.HTTPResponseFromError(err)
@@ -80,3 +80,41 @@ func TestHandleQueryRequest(t *testing.T) {
 		})
 	}
 }
+
+type httpGrpcHandler struct {
+	HandlerFunc
+	path string
+}
+
+func (h httpGrpcHandler) HandleHTTPGrpc(_ context.Context, req *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, bool) {
+	if req.Url != h.path {
+		return nil, false
+	}
+	return &httpgrpc.HTTPResponse{Code: http.StatusOK, Body: []byte("handled")}, true
+}
+
+func TestHandleHTTPRequest_HTTPGrpcHandler(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	var calls int
+	handler := httpGrpcHandler{
+		HandlerFunc: func(context.Context, queryrangebase.Request) (queryrangebase.Response, error) {
+			calls++
+			return nil, httpgrpc.Errorf(http.StatusTeapot, "not handled")
+		},
+		path: "/custom",
+	}
+
+	response := handleHTTPRequest(ctx, &httpgrpc.HTTPRequest{Method: http.MethodPost, Url: "/custom"}, handler, queryrange.DefaultCodec)
+	require.Equal(t, int32(http.StatusOK), response.Code)
+	require.Equal(t, "handled", string(response.Body))
+	require.Zero(t, calls)
+
+	httpReq, err := queryrange.DefaultCodec.EncodeRequest(ctx, &queryrange.LokiRequest{Query: `{app="foo"}`, Limit: 100, Path: "/loki/api/v1/query_range"})
+	require.NoError(t, err)
+	request, err := httpgrpc.FromHTTPRequest(httpReq)
+	require.NoError(t, err)
+	response = handleHTTPRequest(ctx, request, handler, queryrange.DefaultCodec)
+	require.Equal(t, int32(http.StatusTeapot), response.Code)
+	require.Equal(t, 1, calls)
+}
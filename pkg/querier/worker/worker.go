@@ -69,6 +69,13 @@ type RequestHandler interface {
 	Do(context.Context, queryrangebase.Request) (queryrangebase.Response, error)
 }
 
+// HTTPGrpcHandler is an optional interface of a RequestHandler for HTTP requests that cannot be decoded by the
+// RequestCodec, such as the plan fragments of the v2 query engine. Requests for which it returns false are decoded
+// and passed to the RequestHandler as usual.
+type HTTPGrpcHandler interface {
+	HandleHTTPGrpc(context.Context, *httpgrpc.HTTPRequest) (*httpgrpc.HTTPResponse, bool)
+}
+
 // Decodes httpgrpc.HTTPRequests or QueryRequests to queryrangebase.Requests. This is used by the
 // frontend and scheduler processor.
 type RequestCodec interface {
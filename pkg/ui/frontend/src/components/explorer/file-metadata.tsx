@@ -111,6 +111,7 @@ export function FileMetadataView({
           logCount={logCount}
         />
         <SectionsList
+          filename={filename}
           sections={metadata.sections}
           expandedSectionIndex={expandedSectionIndex}
           expandedColumns={expandedColumns}
@@ -219,6 +220,7 @@ function HeadlineStats({
 }
 
 interface SectionsListProps {
+  filename: string;
   sections: FileMetadataResponse["sections"];
   expandedSectionIndex: number | null;
   expandedColumns: Record<string, boolean>;
@@ -227,6 +229,7 @@ interface SectionsListProps {
 }
 
 function SectionsList({
+  filename,
   sections,
   expandedSectionIndex,
   expandedColumns,
@@ -238,6 +241,7 @@ function SectionsList({
       {sections.map((section, sectionIndex) => (
         <Section
           key={sectionIndex}
+          filename={filename}
           section={section}
           sectionIndex={sectionIndex}
           isExpanded={expandedSectionIndex === sectionIndex}
@@ -253,6 +257,7 @@ function SectionsList({
 }
 
 interface SectionProps {
+  filename: string;
   section: FileMetadataResponse["sections"][0];
   sectionIndex: number;
   isExpanded: boolean;
@@ -262,6 +267,7 @@ interface SectionProps {
 }
 
 function Section({
+  filename,
   section,
   sectionIndex,
   isExpanded,
@@ -296,6 +302,17 @@ function Section({
 
       {isExpanded && (
         <div className="mt-6 px-6">
+          <div className="flex justify-end mb-4">
+            <Button variant="outline" size="sm" asChild>
+              <Link
+                to={`/storage/dataobj/rows?path=${encodeURIComponent(
+                  filename
+                )}&section=${sectionIndex}`}
+              >
+                Browse rows
+              </Link>
+            </Button>
+          </div>
           <SectionStats section={section} />
           <ColumnsList
             columns={section.columns}
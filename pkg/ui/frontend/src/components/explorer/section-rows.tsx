@@ -0,0 +1,202 @@
+import { useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Badge } from "@/components/ui/badge";
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from "@/components/ui/card";
+import {
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+} from "@/components/ui/table";
+import { RowsColumn, SectionRowsResponse } from "@/types/explorer";
+
+interface PredicateFormProps {
+  selector: string;
+  metadata: string;
+  isLogsSection: boolean;
+  onApply: (selector: string, metadata: string) => void;
+}
+
+export function PredicateForm({
+  selector,
+  metadata,
+  isLogsSection,
+  onApply,
+}: PredicateFormProps) {
+  const [selectorInput, setSelectorInput] = useState(selector);
+  const [metadataInput, setMetadataInput] = useState(metadata);
+
+  return (
+    <form
+      className="grid grid-cols-1 md:grid-cols-[1fr_1fr_auto] gap-4 items-end"
+      onSubmit={(e) => {
+        e.preventDefault();
+        onApply(selectorInput.trim(), metadataInput.trim());
+      }}
+    >
+      <div className="space-y-2">
+        <Label htmlFor="selector">Stream selector</Label>
+        <Input
+          id="selector"
+          className="font-mono"
+          placeholder='{app="foo"}'
+          value={selectorInput}
+          onChange={(e) => setSelectorInput(e.target.value)}
+        />
+      </div>
+      <div className="space-y-2">
+        <Label htmlFor="metadata">Metadata predicate</Label>
+        <Input
+          id="metadata"
+          className="font-mono"
+          placeholder={
+            isLogsSection ? '{trace_id="abc"}' : "Only supported for logs"
+          }
+          disabled={!isLogsSection}
+          value={metadataInput}
+          onChange={(e) => setMetadataInput(e.target.value)}
+        />
+      </div>
+      <Button type="submit">Apply</Button>
+    </form>
+  );
+}
+
+interface SectionRowsViewProps {
+  data: SectionRowsResponse;
+  onPrevious: () => void;
+  onNext: () => void;
+}
+
+export function SectionRowsView({
+  data,
+  onPrevious,
+  onNext,
+}: SectionRowsViewProps) {
+  return (
+    <div className="space-y-6">
+      <PruningSummary data={data} />
+
+      <Card>
+        <CardHeader>
+          <CardTitle>Rows</CardTitle>
+          <CardDescription>
+            {data.rows.length > 0
+              ? `Showing ${data.rows.length} matching rows from row ${data.start}`
+              : "No rows match the predicate"}
+          </CardDescription>
+        </CardHeader>
+        <CardContent className="space-y-4">
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead className="w-20">Row</TableHead>
+                {data.columns.map((column, i) => (
+                  <TableHead key={i} className="whitespace-nowrap">
+                    {columnLabel(column)}
+                  </TableHead>
+                ))}
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {data.rows.map((row) => (
+                <TableRow key={row.index}>
+                  <TableCell className="font-mono text-muted-foreground">
+                    {row.index}
+                  </TableCell>
+                  {row.values.map((value, i) => (
+                    <TableCell
+                      key={i}
+                      className="font-mono text-xs max-w-md truncate"
+                      title={value ?? undefined}
+                    >
+                      {value ?? (
+                        <span className="text-muted-foreground">null</span>
+                      )}
+                    </TableCell>
+                  ))}
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+
+          <div className="flex justify-end gap-2">
+            <Button
+              variant="outline"
+              size="sm"
+              disabled={data.start === 0}
+              onClick={onPrevious}
+            >
+              Previous
+            </Button>
+            <Button
+              variant="outline"
+              size="sm"
+              disabled={!data.hasMore}
+              onClick={onNext}
+            >
+              Next
+            </Button>
+          </div>
+        </CardContent>
+      </Card>
+    </div>
+  );
+}
+
+function PruningSummary({ data }: { data: SectionRowsResponse }) {
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle>Page pruning</CardTitle>
+        <CardDescription>
+          {data.pagesPruned} of {data.pagesTotal} pages pruned across{" "}
+          {data.totalRows} rows
+        </CardDescription>
+      </CardHeader>
+      <CardContent className="space-y-3">
+        {data.columns.map((column, i) => {
+          const pruned = column.pages.filter((p) => p.pruned).length;
+          return (
+            <div key={i} className="flex items-center gap-4">
+              <div className="w-64 shrink-0 truncate text-sm font-mono">
+                {columnLabel(column)}
+              </div>
+              <div className="flex flex-1 gap-px">
+                {column.pages.map((page, j) => (
+                  <div
+                    key={j}
+                    className={`h-4 flex-1 rounded-sm ${
+                      page.pruned ? "bg-muted" : "bg-primary"
+                    }`}
+                    title={`Rows ${page.first_row}-${page.last_row}${
+                      page.pruned ? " (pruned)" : ""
+                    }`}
+                  />
+                ))}
+              </div>
+              <Badge variant="outline" className="shrink-0 font-mono">
+                {pruned}/{column.pages.length}
+              </Badge>
+            </div>
+          );
+        })}
+      </CardContent>
+    </Card>
+  );
+}
+
+function columnLabel(column: RowsColumn): string {
+  const type = column.type.replace(/^COLUMN_TYPE_/, "");
+  return column.name ? `${column.name} (${type})` : type;
+}
@@ -7,6 +7,7 @@ import Ring from "@/pages/ring";
 import { RouteObject } from "react-router-dom";
 import { DataObjectsPage } from "@/pages/data-objects";
 import { FileMetadataPage } from "@/pages/file-metadata";
+import { SectionRowsPage } from "@/pages/section-rows";
 import Nodes from "@/pages/nodes";
 import { BreadcrumbComponentType } from "use-react-router-breadcrumbs";
 import NodeDetails from "@/pages/node-details";
@@ -71,6 +72,11 @@ export const routes: RouteObjectWithBreadcrumb[] = [
     breadcrumb: "File Metadata",
     element: <FileMetadataPage />,
   },
+  {
+    path: "/storage/dataobj/rows",
+    breadcrumb: "Rows",
+    element: <SectionRowsPage />,
+  },
   {
     path: "/tenants",
     breadcrumb: "Tenants",
@@ -0,0 +1,67 @@
+import { useQuery } from "@tanstack/react-query";
+import { findNodeName } from "@/lib/utils";
+import { useCluster } from "@/contexts/use-cluster";
+import { useMemo } from "react";
+import { SectionRowsResponse } from "@/types/explorer";
+import { absolutePath } from "@/util";
+
+export interface SectionRowsParams {
+  path: string | undefined;
+  section: number;
+  start: number;
+  limit: number;
+  selector: string;
+  metadata: string;
+}
+
+export function useSectionRows({
+  path,
+  section,
+  start,
+  limit,
+  selector,
+  metadata,
+}: SectionRowsParams) {
+  const { cluster } = useCluster();
+  const nodeName = useMemo(() => {
+    return findNodeName(cluster?.members, "dataobj-explorer");
+  }, [cluster?.members]);
+
+  return useQuery<SectionRowsResponse>({
+    queryKey: [
+      "section-rows",
+      path,
+      section,
+      start,
+      limit,
+      selector,
+      metadata,
+      nodeName,
+    ],
+    queryFn: async () => {
+      if (!path) throw new Error("No file path provided");
+      if (!nodeName) throw new Error("Node name not found");
+
+      const params = new URLSearchParams({
+        file: path,
+        section: section.toString(),
+        start: start.toString(),
+        limit: limit.toString(),
+      });
+      if (selector) params.set("selector", selector);
+      if (metadata) params.set("metadata", metadata);
+
+      const response = await fetch(
+        absolutePath(
+          `/api/v1/proxy/${nodeName}/dataobj/api/v1/rows?${params.toString()}`
+        )
+      );
+      if (!response.ok) {
+        const text = await response.text();
+        throw new Error(text || "Failed to fetch section rows");
+      }
+      return response.json();
+    },
+    enabled: !!path && !!nodeName,
+  });
+}
@@ -0,0 +1,83 @@
+import { useNavigate, useSearchParams } from "react-router-dom";
+import {
+  PredicateForm,
+  SectionRowsView,
+} from "@/components/explorer/section-rows";
+import { useSectionRows } from "@/hooks/use-section-rows";
+import { ScrollArea } from "@/components/ui/scroll-area";
+import { ExplorerBreadcrumb } from "@/components/explorer/breadcrumb";
+import { Loader2 } from "lucide-react";
+import { Alert, AlertDescription, AlertTitle } from "@/components/ui/alert";
+import { PageContainer } from "@/layout/page-container";
+
+const pageSize = 100;
+
+export function SectionRowsPage() {
+  const [searchParams, setSearchParams] = useSearchParams();
+  const navigate = useNavigate();
+  const path = searchParams.get("path") || "";
+  const section = parseInt(searchParams.get("section") || "0", 10);
+  const start = parseInt(searchParams.get("start") || "0", 10);
+  const selector = searchParams.get("selector") || "";
+  const metadata = searchParams.get("metadata") || "";
+
+  const { data, isLoading, error } = useSectionRows({
+    path,
+    section,
+    start,
+    limit: pageSize,
+    selector,
+    metadata,
+  });
+
+  const updateParams = (updates: Record<string, string>) => {
+    const next = new URLSearchParams(searchParams);
+    Object.entries(updates).forEach(([key, value]) => {
+      if (value) {
+        next.set(key, value);
+      } else {
+        next.delete(key);
+      }
+    });
+    setSearchParams(next);
+  };
+
+  return (
+    <PageContainer>
+      <div className="flex h-full flex-col space-y-6">
+        <ExplorerBreadcrumb />
+        <ScrollArea className="h-full">
+          <div className="space-y-6">
+            <PredicateForm
+              key={`${selector}|${metadata}`}
+              selector={selector}
+              metadata={metadata}
+              isLogsSection={data?.type !== "SECTION_TYPE_STREAMS"}
+              onApply={(selector, metadata) =>
+                updateParams({ selector, metadata, start: "" })
+              }
+            />
+            {isLoading ? (
+              <div className="flex items-center justify-center p-8">
+                <Loader2 className="h-16 w-16 animate-spin" />
+              </div>
+            ) : error ? (
+              <Alert variant="destructive">
+                <AlertTitle>Error</AlertTitle>
+                <AlertDescription>{error.message}</AlertDescription>
+              </Alert>
+            ) : data ? (
+              <SectionRowsView
+                data={data}
+                // Pages are requested by the row to start from, so previous
+                // pages are only known from the history.
+                onPrevious={() => navigate(-1)}
+                onNext={() => updateParams({ start: String(data.next ?? 0) })}
+              />
+            ) : null}
+          </div>
+        </ScrollArea>
+      </div>
+    </PageContainer>
+  );
+}
@@ -60,3 +60,35 @@ export interface FileMetadataResponse {
 interface ColumnStatistics {
   cardinality_count?: number;
 }
+
+export interface PagePruneInfo {
+  first_row: number;
+  last_row: number;
+  pruned: boolean;
+}
+
+export interface RowsColumn {
+  name?: string;
+  type: string;
+  value_type: string;
+  pages: PagePruneInfo[];
+}
+
+export interface SectionRow {
+  index: number;
+  values: (string | null)[];
+}
+
+export interface SectionRowsResponse {
+  type: string;
+  columns: RowsColumn[];
+  rows: SectionRow[];
+  start: number;
+  next?: number;
+  limit: number;
+  hasMore: boolean;
+  ranges: { start: number; end: number }[];
+  totalRows: number;
+  pagesTotal: number;
+  pagesPruned: number;
+}
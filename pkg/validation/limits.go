@@ -126,6 +126,7 @@ type Limits struct {
 	MaxQueryCapacity           float64          `yaml:"max_query_capacity" json:"max_query_capacity"`
 	QueryReadyIndexNumDays     int              `yaml:"query_ready_index_num_days" json:"query_ready_index_num_days"`
 	QueryTimeout               model.Duration   `yaml:"query_timeout" json:"query_timeout"`
+	V2EngineShadowSampleRate   float64          `yaml:"v2_engine_shadow_sample_rate" json:"v2_engine_shadow_sample_rate" category:"experimental"`
 
 	// Query frontend enforced limits. The default is actually parameterized by the queryrange config.
 	QuerySplitDuration               model.Duration   `yaml:"split_queries_by_interval" json:"split_queries_by_interval"`
@@ -355,6 +356,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.CardinalityLimit, "store.cardinality-limit", 1e5, "Cardinality limit for index queries.")
 	f.IntVar(&l.MaxStreamsMatchersPerQuery, "querier.max-streams-matcher-per-query", 1000, "Maximum number of stream matchers per query.")
 	f.IntVar(&l.MaxConcurrentTailRequests, "querier.max-concurrent-tail-requests", 10, "Maximum number of concurrent tail requests.")
+	f.Float64Var(&l.V2EngineShadowSampleRate, "querier.v2-engine-shadow-sample-rate", 0, "Experimental: Fraction of the queries supported by the next generation query engine that are executed on both query engines to compare their results, if shadow mode is enabled with -querier.engine.enable-v2-engine-shadow-mode. Allowed values are 0.0 to 1.0.")
 
 	_ = l.MinShardingLookback.Set("0s")
 	f.Var(&l.MinShardingLookback, "frontend.min-sharding-lookback", "Limit queries that can be sharded. Queries within the time range of now and now minus this sharding lookback are not sharded. The default value of 0s disables the lookback, causing sharding of all queries at all times.")
@@ -553,6 +555,10 @@ func (l *Limits) Validate() error {
 		l.MaxQueryCapacity = 1
 	}
 
+	if l.V2EngineShadowSampleRate < 0 || l.V2EngineShadowSampleRate > 1 {
+		return fmt.Errorf("invalid v2_engine_shadow_sample_rate %v: must be between 0 and 1", l.V2EngineShadowSampleRate)
+	}
+
 	if err := l.OTLPConfig.Validate(); err != nil {
 		return err
 	}
@@ -823,6 +829,12 @@ func (o *Overrides) MaxEntriesLimitPerQuery(_ context.Context, userID string) in
 	return o.getOverridesForUser(userID).MaxEntriesLimitPerQuery
 }
 
+// V2EngineShadowSampleRate returns the fraction of queries that are executed
+// on both query engines to compare their results.
+func (o *Overrides) V2EngineShadowSampleRate(_ context.Context, userID string) float64 {
+	return o.getOverridesForUser(userID).V2EngineShadowSampleRate
+}
+
 func (o *Overrides) QueryTimeout(_ context.Context, userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).QueryTimeout)
 }